@@ -0,0 +1,105 @@
+package acl
+
+// ManagementACL is the ACL of management tokens, which allows everything.
+var ManagementACL = &ACL{management: true}
+
+// ACL is the compiled form of the policies of a token and is used to check
+// whether the token is allowed to access resources. A resource without a
+// policy can't be accessed.
+type ACL struct {
+	// management allows everything, including managing ACLs.
+	management bool
+
+	job      string
+	node     string
+	agent    string
+	operator string
+}
+
+// NewACL compiles the policies into an ACL. A deny on a resource takes
+// precedence over the grants of the other policies, otherwise the most
+// permissive policy wins.
+func NewACL(management bool, policies []*Policy) *ACL {
+	if management {
+		return ManagementACL
+	}
+
+	a := &ACL{}
+	for _, p := range policies {
+		a.job = mergePolicy(a.job, p.Job)
+		a.node = mergePolicy(a.node, p.Node)
+		a.agent = mergePolicy(a.agent, p.Agent)
+		a.operator = mergePolicy(a.operator, p.Operator)
+	}
+	return a
+}
+
+// mergePolicy merges the rule into the policy compiled so far.
+func mergePolicy(current string, rule *PolicyRule) string {
+	if rule == nil {
+		return current
+	}
+	switch {
+	case current == PolicyDeny || rule.Policy == PolicyDeny:
+		return PolicyDeny
+	case current == PolicyWrite || rule.Policy == PolicyWrite:
+		return PolicyWrite
+	default:
+		return rule.Policy
+	}
+}
+
+// allowRead returns whether the policy allows reading.
+func allowRead(policy string) bool {
+	return policy == PolicyRead || policy == PolicyWrite
+}
+
+// IsManagement returns whether the ACL allows managing ACLs.
+func (a *ACL) IsManagement() bool {
+	return a.management
+}
+
+// AllowJobRead returns whether jobs, and their evaluations and allocations,
+// can be read.
+func (a *ACL) AllowJobRead() bool {
+	return a.management || allowRead(a.job)
+}
+
+// AllowJobWrite returns whether jobs can be registered, evaluated and
+// deregistered.
+func (a *ACL) AllowJobWrite() bool {
+	return a.management || a.job == PolicyWrite
+}
+
+// AllowNodeRead returns whether nodes can be read.
+func (a *ACL) AllowNodeRead() bool {
+	return a.management || allowRead(a.node)
+}
+
+// AllowNodeWrite returns whether nodes can be drained and evaluated.
+func (a *ACL) AllowNodeWrite() bool {
+	return a.management || a.node == PolicyWrite
+}
+
+// AllowAgentRead returns whether the configuration and members of agents can
+// be read.
+func (a *ACL) AllowAgentRead() bool {
+	return a.management || allowRead(a.agent)
+}
+
+// AllowAgentWrite returns whether agents can join, force leave members and
+// update their servers.
+func (a *ACL) AllowAgentWrite() bool {
+	return a.management || a.agent == PolicyWrite
+}
+
+// AllowOperatorRead returns whether the state of the cluster, such as its
+// Raft peers, can be read.
+func (a *ACL) AllowOperatorRead() bool {
+	return a.management || allowRead(a.operator)
+}
+
+// AllowOperatorWrite returns whether the cluster can be operated on.
+func (a *ACL) AllowOperatorWrite() bool {
+	return a.management || a.operator == PolicyWrite
+}
@@ -0,0 +1,70 @@
+package acl
+
+import "testing"
+
+func TestACL_Management(t *testing.T) {
+	a := NewACL(true, nil)
+	if !a.IsManagement() {
+		t.Fatalf("expected management ACL")
+	}
+	if !a.AllowJobWrite() || !a.AllowNodeWrite() || !a.AllowAgentWrite() || !a.AllowOperatorWrite() {
+		t.Fatalf("management ACL should allow everything")
+	}
+}
+
+func TestACL_NoPolicies(t *testing.T) {
+	a := NewACL(false, nil)
+	if a.IsManagement() {
+		t.Fatalf("unexpected management ACL")
+	}
+	if a.AllowJobRead() || a.AllowNodeRead() || a.AllowAgentRead() || a.AllowOperatorRead() {
+		t.Fatalf("ACL without policies should deny everything")
+	}
+}
+
+func TestACL_Merge(t *testing.T) {
+	p1, err := Parse(`
+job {
+	policy = "read"
+}
+node {
+	policy = "write"
+}
+agent {
+	policy = "read"
+}
+`)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	p2, err := Parse(`
+job {
+	policy = "write"
+}
+node {
+	policy = "deny"
+}
+`)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	a := NewACL(false, []*Policy{p1, p2})
+
+	// The most permissive policy wins
+	if !a.AllowJobRead() || !a.AllowJobWrite() {
+		t.Fatalf("expected job write")
+	}
+
+	// Deny takes precedence
+	if a.AllowNodeRead() || a.AllowNodeWrite() {
+		t.Fatalf("expected node deny")
+	}
+
+	if !a.AllowAgentRead() || a.AllowAgentWrite() {
+		t.Fatalf("expected agent read")
+	}
+	if a.AllowOperatorRead() {
+		t.Fatalf("expected operator to be denied without a policy")
+	}
+}
@@ -0,0 +1,79 @@
+package acl
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl"
+)
+
+const (
+	// PolicyDeny denies any access to a resource. It takes precedence over
+	// the grants of other policies.
+	PolicyDeny = "deny"
+
+	// PolicyRead allows reading a resource.
+	PolicyRead = "read"
+
+	// PolicyWrite allows reading and modifying a resource.
+	PolicyWrite = "write"
+)
+
+// Policy is the parsed form of the HCL rules of an ACL policy. Each resource
+// is granted a policy, such as:
+//
+//	job {
+//	  policy = "write"
+//	}
+//
+//	node {
+//	  policy = "read"
+//	}
+type Policy struct {
+	Job      *PolicyRule `hcl:"job"`
+	Node     *PolicyRule `hcl:"node"`
+	Agent    *PolicyRule `hcl:"agent"`
+	Operator *PolicyRule `hcl:"operator"`
+
+	// Raw is the HCL the policy was parsed from.
+	Raw string `hcl:"-"`
+}
+
+// PolicyRule grants a policy on a resource.
+type PolicyRule struct {
+	Policy string `hcl:"policy"`
+}
+
+// isPolicyValid returns whether the policy is known.
+func isPolicyValid(policy string) bool {
+	switch policy {
+	case PolicyDeny, PolicyRead, PolicyWrite:
+		return true
+	default:
+		return false
+	}
+}
+
+// Parse parses and validates the HCL rules of a policy.
+func Parse(rules string) (*Policy, error) {
+	p := &Policy{Raw: rules}
+	if rules == "" {
+		return p, nil
+	}
+
+	if err := hcl.Decode(p, rules); err != nil {
+		return nil, fmt.Errorf("Failed to parse ACL policy: %v", err)
+	}
+
+	resources := map[string]*PolicyRule{
+		"job":      p.Job,
+		"node":     p.Node,
+		"agent":    p.Agent,
+		"operator": p.Operator,
+	}
+	for resource, rule := range resources {
+		if rule != nil && !isPolicyValid(rule.Policy) {
+			return nil, fmt.Errorf("Invalid %s policy: %q", resource, rule.Policy)
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,57 @@
+package acl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	rules := `
+job {
+	policy = "write"
+}
+node {
+	policy = "read"
+}
+operator {
+	policy = "deny"
+}
+`
+	p, err := Parse(rules)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	expected := &Policy{
+		Job:      &PolicyRule{Policy: PolicyWrite},
+		Node:     &PolicyRule{Policy: PolicyRead},
+		Operator: &PolicyRule{Policy: PolicyDeny},
+		Raw:      rules,
+	}
+	if !reflect.DeepEqual(p, expected) {
+		t.Fatalf("got %#v; want %#v", p, expected)
+	}
+}
+
+func TestParse_Empty(t *testing.T) {
+	p, err := Parse("")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if p.Job != nil || p.Node != nil || p.Agent != nil || p.Operator != nil {
+		t.Fatalf("bad: %#v", p)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	cases := []string{
+		`job { policy = "admin" }`,
+		`agent { policy = "" }`,
+		`job {`,
+	}
+	for _, rules := range cases {
+		if _, err := Parse(rules); err == nil {
+			t.Fatalf("expected error parsing %q", rules)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// ACLClientToken is the type of tokens granted the policies they list.
+	ACLClientToken = "client"
+
+	// ACLManagementToken is the type of tokens allowed to do anything.
+	ACLManagementToken = "management"
+)
+
+// ACLPolicies is used to query the ACL policy endpoints.
+type ACLPolicies struct {
+	client *Client
+}
+
+// ACLPolicies returns a handle on the ACL policy endpoints.
+func (c *Client) ACLPolicies() *ACLPolicies {
+	return &ACLPolicies{client: c}
+}
+
+// List is used to list all of the existing policies.
+func (a *ACLPolicies) List(q *QueryOptions) ([]*ACLPolicyListStub, *QueryMeta, error) {
+	var resp []*ACLPolicyListStub
+	qm, err := a.client.query("/v1/acl/policies", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(ACLPolicyNameSort(resp))
+	return resp, qm, nil
+}
+
+// Upsert is used to create or update a policy.
+func (a *ACLPolicies) Upsert(policy *ACLPolicy, q *WriteOptions) (*WriteMeta, error) {
+	if policy == nil || policy.Name == "" {
+		return nil, fmt.Errorf("missing policy name")
+	}
+	return a.client.write("/v1/acl/policy/"+policy.Name, policy, nil, q)
+}
+
+// Delete is used to delete a policy.
+func (a *ACLPolicies) Delete(name string, q *WriteOptions) (*WriteMeta, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing policy name")
+	}
+	return a.client.delete("/v1/acl/policy/"+name, nil, q)
+}
+
+// Info is used to query a specific policy.
+func (a *ACLPolicies) Info(name string, q *QueryOptions) (*ACLPolicy, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing policy name")
+	}
+	var resp ACLPolicy
+	qm, err := a.client.query("/v1/acl/policy/"+name, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// ACLTokens is used to query the ACL token endpoints.
+type ACLTokens struct {
+	client *Client
+}
+
+// ACLTokens returns a handle on the ACL token endpoints.
+func (c *Client) ACLTokens() *ACLTokens {
+	return &ACLTokens{client: c}
+}
+
+// Bootstrap is used to create the initial management token. It can only be
+// done once.
+func (a *ACLTokens) Bootstrap(q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	var resp ACLToken
+	wm, err := a.client.write("/v1/acl/bootstrap", nil, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// List is used to list all of the existing tokens.
+func (a *ACLTokens) List(q *QueryOptions) ([]*ACLTokenListStub, *QueryMeta, error) {
+	var resp []*ACLTokenListStub
+	qm, err := a.client.query("/v1/acl/tokens", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(ACLTokenCreateSort(resp))
+	return resp, qm, nil
+}
+
+// Create is used to create a token. The returned token holds the generated
+// accessor and secret IDs.
+func (a *ACLTokens) Create(token *ACLToken, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if token.AccessorID != "" {
+		return nil, nil, fmt.Errorf("cannot specify accessor ID")
+	}
+	var resp ACLToken
+	wm, err := a.client.write("/v1/acl/token", token, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Update is used to update an existing token.
+func (a *ACLTokens) Update(token *ACLToken, q *WriteOptions) (*ACLToken, *WriteMeta, error) {
+	if token.AccessorID == "" {
+		return nil, nil, fmt.Errorf("missing accessor ID")
+	}
+	var resp ACLToken
+	wm, err := a.client.write("/v1/acl/token/"+token.AccessorID, token, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, wm, nil
+}
+
+// Delete is used to delete a token.
+func (a *ACLTokens) Delete(accessorID string, q *WriteOptions) (*WriteMeta, error) {
+	if accessorID == "" {
+		return nil, fmt.Errorf("missing accessor ID")
+	}
+	return a.client.delete("/v1/acl/token/"+accessorID, nil, q)
+}
+
+// Info is used to query a specific token.
+func (a *ACLTokens) Info(accessorID string, q *QueryOptions) (*ACLToken, *QueryMeta, error) {
+	if accessorID == "" {
+		return nil, nil, fmt.Errorf("missing accessor ID")
+	}
+	var resp ACLToken
+	qm, err := a.client.query("/v1/acl/token/"+accessorID, &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Self is used to query the token used for the request.
+func (a *ACLTokens) Self(q *QueryOptions) (*ACLToken, *QueryMeta, error) {
+	var resp ACLToken
+	qm, err := a.client.query("/v1/acl/token/self", &resp, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// ACLPolicy is a named set of rules granting access to resources.
+type ACLPolicy struct {
+	Name        string
+	Description string
+	Rules       string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLPolicyListStub is used to list policies without their rules.
+type ACLPolicyListStub struct {
+	Name        string
+	Description string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLPolicyNameSort is used to sort policies by name.
+type ACLPolicyNameSort []*ACLPolicyListStub
+
+func (a ACLPolicyNameSort) Len() int {
+	return len(a)
+}
+
+func (a ACLPolicyNameSort) Less(i, j int) bool {
+	return a[i].Name < a[j].Name
+}
+
+func (a ACLPolicyNameSort) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
+
+// ACLToken is a token granting access to the APIs.
+type ACLToken struct {
+	AccessorID  string
+	SecretID    string
+	Name        string
+	Type        string
+	Policies    []string
+	CreateTime  time.Time
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLTokenListStub is used to list tokens without their secret IDs.
+type ACLTokenListStub struct {
+	AccessorID  string
+	Name        string
+	Type        string
+	Policies    []string
+	CreateTime  time.Time
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLTokenCreateSort is used to sort tokens by their creation index.
+type ACLTokenCreateSort []*ACLTokenListStub
+
+func (a ACLTokenCreateSort) Len() int {
+	return len(a)
+}
+
+func (a ACLTokenCreateSort) Less(i, j int) bool {
+	return a[i].CreateIndex < a[j].CreateIndex
+}
+
+func (a ACLTokenCreateSort) Swap(i, j int) {
+	a[i], a[j] = a[j], a[i]
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+)
+
+// makeACLClient returns a client using the management token of a server
+// with ACLs enabled.
+func makeACLClient(t *testing.T) (*Client, *testutil.TestServer, *ACLToken) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.ACL = &testutil.ACLConfig{Enabled: true}
+	})
+
+	root, wm, err := c.ACLTokens().Bootstrap(nil)
+	if err != nil {
+		s.Stop()
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	if root.Type != ACLManagementToken || root.SecretID == "" {
+		s.Stop()
+		t.Fatalf("bad: %#v", root)
+	}
+	c.config.SecretID = root.SecretID
+	return c, s, root
+}
+
+func TestACLPolicies_CRUD(t *testing.T) {
+	c, s, _ := makeACLClient(t)
+	defer s.Stop()
+	policies := c.ACLPolicies()
+
+	// Create a policy
+	policy := &ACLPolicy{
+		Name:        "readonly",
+		Description: "Read jobs and nodes",
+		Rules: `
+job {
+	policy = "read"
+}
+node {
+	policy = "read"
+}`,
+	}
+	wm, err := policies.Upsert(policy, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// List the policies
+	resp, qm, err := policies.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if len(resp) != 1 || resp[0].Name != policy.Name {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Query the policy
+	out, qm, err := policies.Info(policy.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if out.Rules != policy.Rules {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Delete the policy
+	wm, err = policies.Delete(policy.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	_, _, err = policies.Info(policy.Name, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
+
+func TestACLTokens_CRUD(t *testing.T) {
+	c, s, root := makeACLClient(t)
+	defer s.Stop()
+	tokens := c.ACLTokens()
+
+	// Create a client token
+	token, wm, err := tokens.Create(&ACLToken{
+		Name:     "test",
+		Type:     ACLClientToken,
+		Policies: []string{"readonly"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	if token.AccessorID == "" || token.SecretID == "" {
+		t.Fatalf("bad: %#v", token)
+	}
+
+	// The token can query itself
+	self, _, err := tokens.Self(&QueryOptions{AuthToken: token.SecretID})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if self.AccessorID != token.AccessorID {
+		t.Fatalf("bad: %#v", self)
+	}
+
+	// The token cannot manage tokens
+	if _, _, err := tokens.List(&QueryOptions{AuthToken: token.SecretID}); err == nil ||
+		!strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected permission denied, got: %#v", err)
+	}
+
+	// Update the token
+	token.Name = "updated"
+	updated, wm, err := tokens.Update(token, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+	if updated.Name != "updated" || updated.SecretID != token.SecretID {
+		t.Fatalf("bad: %#v", updated)
+	}
+
+	// List the tokens
+	resp, qm, err := tokens.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if len(resp) != 2 || resp[0].AccessorID != root.AccessorID {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Delete the token
+	wm, err = tokens.Delete(token.AccessorID, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	_, _, err = tokens.Info(token.AccessorID, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
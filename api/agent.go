@@ -157,6 +157,44 @@ func (a *Agent) SetServers(addrs []string) error {
 	return err
 }
 
+// ListKeys returns the gossip encryption keys installed on the servers and
+// the number of servers each is installed on.
+func (a *Agent) ListKeys() (*KeyringResponse, error) {
+	var resp KeyringResponse
+	_, err := a.client.query("/v1/agent/keyring/list", &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// InstallKey installs a new gossip encryption key on all of the servers.
+func (a *Agent) InstallKey(key string) (*KeyringResponse, error) {
+	return a.keyringOp("install", key)
+}
+
+// UseKey changes the key used to encrypt the gossip. The key must have been
+// installed on all of the servers.
+func (a *Agent) UseKey(key string) (*KeyringResponse, error) {
+	return a.keyringOp("use", key)
+}
+
+// RemoveKey removes a gossip encryption key from all of the servers. The
+// key used to encrypt the gossip cannot be removed.
+func (a *Agent) RemoveKey(key string) (*KeyringResponse, error) {
+	return a.keyringOp("remove", key)
+}
+
+// keyringOp runs an operation changing the keyring of the servers.
+func (a *Agent) keyringOp(op, key string) (*KeyringResponse, error) {
+	var resp KeyringResponse
+	_, err := a.client.write("/v1/agent/keyring/"+op, &KeyringRequest{Key: key}, &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // joinResponse is used to decode the response we get while
 // sending a member join request.
 type joinResponse struct {
@@ -164,6 +202,20 @@ type joinResponse struct {
 	Error     string `json:"error"`
 }
 
+// KeyringRequest is used to install, use or remove a gossip encryption key.
+type KeyringRequest struct {
+	Key string
+}
+
+// KeyringResponse is the result of a keyring operation. Keys maps the keys
+// to the number of servers they are installed on and Messages holds the
+// errors reported by servers.
+type KeyringResponse struct {
+	Messages map[string]string
+	Keys     map[string]int
+	NumNodes int
+}
+
 // AgentMember represents a cluster member known to the agent
 type AgentMember struct {
 	Name        string
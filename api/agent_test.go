@@ -124,6 +124,43 @@ func TestAgent_ForceLeave(t *testing.T) {
 	// TODO: test force-leave on an existing node
 }
 
+func TestAgent_Keyring(t *testing.T) {
+	key1 := "tbLJg26ZJyJ9pK3qhc9jig=="
+	key2 := "4leC33rgtXKIVUr9Nr0snQ=="
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.Server.EncryptKey = key1
+	})
+	defer s.Stop()
+	a := c.Agent()
+
+	resp, err := a.ListKeys()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(resp.Keys) != 1 || resp.Keys[key1] != 1 {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Rotate to the second key
+	if _, err := a.InstallKey(key2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := a.UseKey(key2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := a.RemoveKey(key1); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	resp, err = a.ListKeys()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(resp.Keys) != 1 || resp.Keys[key2] != 1 {
+		t.Fatalf("bad: %#v", resp)
+	}
+}
+
 func TestAgent_SetServers(t *testing.T) {
 	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
 		c.Client.Enabled = true
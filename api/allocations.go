@@ -1,7 +1,17 @@
 package api
 
 import (
+	"bufio"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
 	"sort"
+	"sync"
 	"time"
 )
 
@@ -36,9 +46,188 @@ func (a *Allocations) Info(allocID string, q *QueryOptions) (*Allocation, *Query
 	return &resp, qm, nil
 }
 
+// Stats returns the latest resource usage of the allocation. The request is
+// sent directly to the client agent running the allocation.
+func (a *Allocations) Stats(alloc *Allocation, q *QueryOptions) (*AllocResourceUsage, error) {
+	nodeClient, err := a.client.getNodeClient(alloc.NodeID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp AllocResourceUsage
+	if _, err := nodeClient.query("/v1/client/allocation/"+alloc.ID+"/stats", &resp, nil); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Exec runs a command inside a task of the allocation and streams its input
+// and output. The request is sent directly to the client agent running the
+// allocation. If tty is set the command is attached to a pseudo-terminal and
+// all output is written to stdout; terminal size changes may then be sent on
+// sizeCh. Exec returns the exit code of the command once it has finished.
+func (a *Allocations) Exec(alloc *Allocation, task string, cmd []string, tty bool,
+	stdin io.Reader, stdout, stderr io.Writer, sizeCh <-chan TerminalSize,
+	q *QueryOptions) (int, error) {
+	node, _, err := a.client.Nodes().Info(alloc.NodeID, q)
+	if err != nil {
+		return 0, err
+	}
+	if node.HTTPAddr == "" {
+		return 0, fmt.Errorf("http addr of node %q (%s) is not advertised", node.Name, node.ID)
+	}
+
+	params := url.Values{}
+	params.Set("task", task)
+	for _, arg := range cmd {
+		params.Add("cmd", arg)
+	}
+	if tty {
+		params.Set("tty", "true")
+	}
+	u := &url.URL{
+		Scheme:   a.client.nodeScheme(),
+		Host:     node.HTTPAddr,
+		Path:     fmt.Sprintf("/v1/client/allocation/%s/exec", alloc.ID),
+		RawQuery: params.Encode(),
+	}
+	req, err := http.NewRequest("PUT", u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	if q != nil && q.AuthToken != "" {
+		req.Header.Set("X-Nomad-Token", q.AuthToken)
+	} else if a.client.config.SecretID != "" {
+		req.Header.Set("X-Nomad-Token", a.client.config.SecretID)
+	}
+
+	var conn net.Conn
+	if u.Scheme == "https" {
+		tlsConf, err := a.client.config.tlsConfig()
+		if err != nil {
+			return 0, err
+		}
+		conn, err = tls.Dial("tcp", node.HTTPAddr, tlsConf)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		conn, err = net.Dial("tcp", node.HTTPAddr)
+		if err != nil {
+			return 0, err
+		}
+	}
+	defer conn.Close()
+
+	if err := req.Write(conn); err != nil {
+		return 0, err
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != 200 {
+		buf := make([]byte, 4096)
+		n, _ := io.ReadFull(resp.Body, buf)
+		resp.Body.Close()
+		return 0, fmt.Errorf("Unexpected response code: %d (%s)", resp.StatusCode, buf[:n])
+	}
+
+	var l sync.Mutex
+	enc := json.NewEncoder(conn)
+	send := func(frame *execFrame) error {
+		l.Lock()
+		defer l.Unlock()
+		return enc.Encode(frame)
+	}
+
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	if stdin != nil {
+		go func() {
+			buf := make([]byte, 4096)
+			for {
+				n, err := stdin.Read(buf)
+				if n > 0 {
+					data := make([]byte, n)
+					copy(data, buf[:n])
+					if send(&execFrame{Stdin: data}) != nil {
+						return
+					}
+				}
+				if err != nil {
+					send(&execFrame{StdinClosed: true})
+					return
+				}
+			}
+		}()
+	}
+
+	if tty && sizeCh != nil {
+		go func() {
+			for {
+				select {
+				case size := <-sizeCh:
+					if send(&execFrame{TtySize: &size}) != nil {
+						return
+					}
+				case <-doneCh:
+					return
+				}
+			}
+		}()
+	}
+
+	dec := json.NewDecoder(reader)
+	for {
+		var frame execFrame
+		if err := dec.Decode(&frame); err != nil {
+			return 0, fmt.Errorf("failed to read exec output: %v", err)
+		}
+		if len(frame.Stdout) != 0 {
+			if _, err := stdout.Write(frame.Stdout); err != nil {
+				return 0, err
+			}
+		}
+		if len(frame.Stderr) != 0 {
+			if _, err := stderr.Write(frame.Stderr); err != nil {
+				return 0, err
+			}
+		}
+		if frame.Exited {
+			if frame.Error != "" {
+				return frame.ExitCode, errors.New(frame.Error)
+			}
+			return frame.ExitCode, nil
+		}
+	}
+}
+
+// TerminalSize is the size of a terminal in rows and columns.
+type TerminalSize struct {
+	Height uint16
+	Width  uint16
+}
+
+// execFrame is a single message exchanged with a client agent while a
+// command is being executed.
+type execFrame struct {
+	Stdin       []byte        `json:",omitempty"`
+	StdinClosed bool          `json:",omitempty"`
+	TtySize     *TerminalSize `json:",omitempty"`
+	Stdout      []byte        `json:",omitempty"`
+	Stderr      []byte        `json:",omitempty"`
+	Exited      bool          `json:",omitempty"`
+	ExitCode    int           `json:",omitempty"`
+	Error       string        `json:",omitempty"`
+}
+
 // Allocation is used for serialization of allocations.
 type Allocation struct {
 	ID                 string
+	Namespace          string
 	EvalID             string
 	Name               string
 	NodeID             string
@@ -54,6 +243,7 @@ type Allocation struct {
 	ClientStatus       string
 	ClientDescription  string
 	TaskStates         map[string]*TaskState
+	PreviousAllocation string
 	CreateIndex        uint64
 	ModifyIndex        uint64
 }
@@ -70,12 +260,14 @@ type AllocationMetric struct {
 	Scores             map[string]float64
 	AllocationTime     time.Duration
 	CoalescedFailures  int
+	QuotaExhausted     []string
 }
 
 // AllocationListStub is used to return a subset of an allocation
 // during list operations.
 type AllocationListStub struct {
 	ID                 string
+	Namespace          string
 	EvalID             string
 	Name               string
 	NodeID             string
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
 )
 
 func TestAllocations_List(t *testing.T) {
@@ -69,3 +72,41 @@ func TestAllocations_CreateIndexSort(t *testing.T) {
 		t.Fatalf("\n\n%#v\n\n%#v", allocs, expect)
 	}
 }
+
+func TestAllocations_MockDriver(t *testing.T) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+		c.Client.Options = map[string]string{
+			"driver.mock_driver.enable": "1",
+		}
+	})
+	defer s.Stop()
+
+	// Register a batch job whose task exits shortly after starting
+	task := NewTask("task1", "mock_driver").
+		SetConfig("run_for", "100ms").
+		Require(&Resources{CPU: 100, MemoryMB: 16})
+	job := NewBatchJob("job1", "job1", "global", 50).
+		AddDatacenter("dc1").
+		AddTaskGroup(NewTaskGroup("group1", 1).AddTask(task))
+	if _, _, err := c.Jobs().Register(job, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The allocation is placed, run and completes
+	testutil.WaitForResult(func() (bool, error) {
+		allocs, _, err := c.Jobs().Allocations(job.ID, nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(allocs); n != 1 {
+			return false, fmt.Errorf("expected 1 alloc, got: %d", n)
+		}
+		if status := allocs[0].ClientStatus; status != "dead" {
+			return false, fmt.Errorf("got client status %q", status)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+}
@@ -2,9 +2,12 @@ package api
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -20,6 +23,10 @@ type QueryOptions struct {
 	// by the Config
 	Region string
 
+	// Namespace is the namespace the query is made in. Defaults to that of
+	// the Config.
+	Namespace string
+
 	// AllowStale allows any Nomad server (non-leader) to service
 	// a read. This allows for lower latency and higher throughput
 	AllowStale bool
@@ -31,6 +38,10 @@ type QueryOptions struct {
 	// WaitTime is used to bound the duration of a wait.
 	// Defaults to that of the Config, but can be overriden.
 	WaitTime time.Duration
+
+	// AuthToken is the secret ID of the ACL token used for the request.
+	// Defaults to that of the Config.
+	AuthToken string
 }
 
 // WriteOptions are used to parameterize a write
@@ -38,6 +49,14 @@ type WriteOptions struct {
 	// Providing a datacenter overwrites the region provided
 	// by the Config
 	Region string
+
+	// Namespace is the namespace the write is made in. Defaults to that of
+	// the Config.
+	Namespace string
+
+	// AuthToken is the secret ID of the ACL token used for the request.
+	// Defaults to that of the Config.
+	AuthToken string
 }
 
 // QueryMeta is used to return meta data about a query
@@ -75,6 +94,9 @@ type Config struct {
 	// Region to use. If not provided, the default agent region is used.
 	Region string
 
+	// Namespace to use. If not provided, the default namespace is used.
+	Namespace string
+
 	// HttpClient is the client to use. Default will be
 	// used if not provided.
 	HttpClient *http.Client
@@ -82,6 +104,65 @@ type Config struct {
 	// WaitTime limits how long a Watch will block. If not provided,
 	// the agent default values will be used.
 	WaitTime time.Duration
+
+	// SecretID is the secret ID of the ACL token sent with requests.
+	SecretID string
+
+	// TLSConfig configures TLS for agents serving their HTTP API over
+	// HTTPS. The system roots are used if it is nil.
+	TLSConfig *TLSConfig
+}
+
+// TLSConfig contains the parameters needed to configure TLS on the HTTP
+// client used to communicate with Nomad.
+type TLSConfig struct {
+	// CACert is the path to a PEM-encoded CA certificate file used to
+	// verify the certificates of the agents.
+	CACert string
+
+	// ClientCert and ClientKey are the paths to the PEM-encoded certificate
+	// and key presented to agents requiring client certificates.
+	ClientCert string
+	ClientKey  string
+
+	// TLSServerName, if set, is the name the certificates of the agents are
+	// verified against instead of the host of the address.
+	TLSServerName string
+
+	// Insecure disables the verification of the certificates of the agents.
+	Insecure bool
+}
+
+// tlsConfig builds the crypto/tls configuration of the client. It returns
+// nil if TLS is not configured.
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	t := c.TLSConfig
+	if t == nil {
+		return nil, nil
+	}
+
+	tlsConf := &tls.Config{
+		ServerName:         t.TLSServerName,
+		InsecureSkipVerify: t.Insecure,
+	}
+	if t.CACert != "" {
+		data, err := ioutil.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert: %v", err)
+		}
+		tlsConf.RootCAs = x509.NewCertPool()
+		if !tlsConf.RootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("failed to parse CA cert %q", t.CACert)
+		}
+	}
+	if t.ClientCert != "" || t.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key pair: %v", err)
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConf, nil
 }
 
 // DefaultConfig returns a default configuration for the client
@@ -93,6 +174,28 @@ func DefaultConfig() *Config {
 	if addr := os.Getenv("NOMAD_ADDR"); addr != "" {
 		config.Address = addr
 	}
+	if token := os.Getenv("NOMAD_TOKEN"); token != "" {
+		config.SecretID = token
+	}
+	if namespace := os.Getenv("NOMAD_NAMESPACE"); namespace != "" {
+		config.Namespace = namespace
+	}
+
+	// Read the TLS settings
+	tlsConfig := &TLSConfig{
+		CACert:        os.Getenv("NOMAD_CA_CERT"),
+		ClientCert:    os.Getenv("NOMAD_CLIENT_CERT"),
+		ClientKey:     os.Getenv("NOMAD_CLIENT_KEY"),
+		TLSServerName: os.Getenv("NOMAD_TLS_SERVER_NAME"),
+	}
+	if v := os.Getenv("NOMAD_SKIP_VERIFY"); v != "" {
+		if insecure, err := strconv.ParseBool(v); err == nil {
+			tlsConfig.Insecure = insecure
+		}
+	}
+	if *tlsConfig != (TLSConfig{}) {
+		config.TLSConfig = tlsConfig
+	}
 	return config
 }
 
@@ -116,18 +219,60 @@ func NewClient(config *Config) (*Client, error) {
 		config.HttpClient = defConfig.HttpClient
 	}
 
+	// Configure TLS on the transport of the HTTP client
+	tlsConf, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConf != nil {
+		if config.HttpClient.Transport == nil {
+			config.HttpClient.Transport = cleanhttp.DefaultTransport()
+		}
+		transport, ok := config.HttpClient.Transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("cannot configure TLS on a %T transport", config.HttpClient.Transport)
+		}
+		transport.TLSClientConfig = tlsConf
+	}
+
 	client := &Client{
 		config: *config,
 	}
 	return client, nil
 }
 
+// getNodeClient returns a Client that talks directly to the HTTP API of the
+// client agent running on the given node.
+func (c *Client) getNodeClient(nodeID string, q *QueryOptions) (*Client, error) {
+	node, _, err := c.Nodes().Info(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+	if node.HTTPAddr == "" {
+		return nil, fmt.Errorf("http addr of node %q (%s) is not advertised", node.Name, node.ID)
+	}
+
+	conf := c.config
+	conf.Address = fmt.Sprintf("%s://%s", c.nodeScheme(), node.HTTPAddr)
+	return NewClient(&conf)
+}
+
+// nodeScheme returns the scheme of the HTTP API of client agents. Agents of
+// a cluster are expected to all serve HTTPS if the configured agent does.
+func (c *Client) nodeScheme() string {
+	if u, err := url.Parse(c.config.Address); err == nil && u.Scheme == "https" {
+		return "https"
+	}
+	return "http"
+}
+
 // request is used to help build up a request
 type request struct {
 	config *Config
 	method string
 	url    *url.URL
 	params url.Values
+	token  string
 	body   io.Reader
 	obj    interface{}
 }
@@ -141,6 +286,9 @@ func (r *request) setQueryOptions(q *QueryOptions) {
 	if q.Region != "" {
 		r.params.Set("region", q.Region)
 	}
+	if q.Namespace != "" {
+		r.params.Set("namespace", q.Namespace)
+	}
 	if q.AllowStale {
 		r.params.Set("stale", "")
 	}
@@ -150,6 +298,9 @@ func (r *request) setQueryOptions(q *QueryOptions) {
 	if q.WaitTime != 0 {
 		r.params.Set("wait", durToMsec(q.WaitTime))
 	}
+	if q.AuthToken != "" {
+		r.token = q.AuthToken
+	}
 }
 
 // durToMsec converts a duration to a millisecond specified string
@@ -166,6 +317,12 @@ func (r *request) setWriteOptions(q *WriteOptions) {
 	if q.Region != "" {
 		r.params.Set("region", q.Region)
 	}
+	if q.Namespace != "" {
+		r.params.Set("namespace", q.Namespace)
+	}
+	if q.AuthToken != "" {
+		r.token = q.AuthToken
+	}
 }
 
 // toHTTP converts the request to an HTTP request
@@ -191,6 +348,9 @@ func (r *request) toHTTP() (*http.Request, error) {
 	req.URL.Host = r.url.Host
 	req.URL.Scheme = r.url.Scheme
 	req.Host = r.url.Host
+	if r.token != "" {
+		req.Header.Set("X-Nomad-Token", r.token)
+	}
 	return req, nil
 }
 
@@ -211,9 +371,15 @@ func (c *Client) newRequest(method, path string) *request {
 	if c.config.Region != "" {
 		r.params.Set("region", c.config.Region)
 	}
+	if c.config.Namespace != "" {
+		r.params.Set("namespace", c.config.Namespace)
+	}
 	if c.config.WaitTime != 0 {
 		r.params.Set("wait", durToMsec(r.config.WaitTime))
 	}
+	if c.config.SecretID != "" {
+		r.token = c.config.SecretID
+	}
 
 	// Add in the query parameters, if any
 	for key, values := range u.Query() {
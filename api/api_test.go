@@ -1,7 +1,10 @@
 package api
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -110,6 +113,125 @@ func TestDefaultConfig_env(t *testing.T) {
 	}
 }
 
+func TestDefaultConfig_envToken(t *testing.T) {
+	t.Parallel()
+	token := "8a2a1ad2-6fb4-4e46-a2fc-6a03a3a7c2b6"
+
+	os.Setenv("NOMAD_TOKEN", token)
+	defer os.Setenv("NOMAD_TOKEN", "")
+
+	config := DefaultConfig()
+
+	if config.SecretID != token {
+		t.Errorf("expected %q to be %q", config.SecretID, token)
+	}
+}
+
+func TestDefaultConfig_envNamespace(t *testing.T) {
+	os.Setenv("NOMAD_NAMESPACE", "team-a")
+	defer os.Setenv("NOMAD_NAMESPACE", "")
+
+	config := DefaultConfig()
+
+	if config.Namespace != "team-a" {
+		t.Errorf("expected %q to be %q", config.Namespace, "team-a")
+	}
+}
+
+func TestDefaultConfig_envTLS(t *testing.T) {
+	// Not parallel so other tests do not pick up the TLS settings
+	os.Setenv("NOMAD_CA_CERT", "ca.pem")
+	defer os.Setenv("NOMAD_CA_CERT", "")
+	os.Setenv("NOMAD_CLIENT_CERT", "cert.pem")
+	defer os.Setenv("NOMAD_CLIENT_CERT", "")
+	os.Setenv("NOMAD_CLIENT_KEY", "key.pem")
+	defer os.Setenv("NOMAD_CLIENT_KEY", "")
+	os.Setenv("NOMAD_SKIP_VERIFY", "true")
+	defer os.Setenv("NOMAD_SKIP_VERIFY", "")
+
+	config := DefaultConfig()
+
+	expected := &TLSConfig{
+		CACert:     "ca.pem",
+		ClientCert: "cert.pem",
+		ClientKey:  "key.pem",
+		Insecure:   true,
+	}
+	if config.TLSConfig == nil || *config.TLSConfig != *expected {
+		t.Errorf("expected %#v to be %#v", config.TLSConfig, expected)
+	}
+}
+
+func TestClient_TLS(t *testing.T) {
+	t.Parallel()
+	const (
+		caFile     = "../helper/tlsutil/test-resources/ca.pem"
+		serverCert = "../helper/tlsutil/test-resources/server.pem"
+		serverKey  = "../helper/tlsutil/test-resources/server-key.pem"
+		clientCert = "../helper/tlsutil/test-resources/client.pem"
+		clientKey  = "../helper/tlsutil/test-resources/client-key.pem"
+	)
+
+	// Start a server requiring client certificates
+	cert, err := tls.LoadX509KeyPair(serverCert, serverKey)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// Requests without a client certificate fail
+	conf := DefaultConfig()
+	conf.Address = srv.URL
+	conf.TLSConfig = &TLSConfig{CACert: caFile}
+	client, err := NewClient(conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var out interface{}
+	if _, err := client.query("/", &out, nil); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	conf = DefaultConfig()
+	conf.Address = srv.URL
+	conf.TLSConfig = &TLSConfig{
+		CACert:     caFile,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+	}
+	client, err = NewClient(conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := client.query("/", &out, nil); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if scheme := client.nodeScheme(); scheme != "https" {
+		t.Fatalf("bad scheme: %s", scheme)
+	}
+
+	// Missing files are reported
+	conf.TLSConfig.CACert = "nope.pem"
+	if _, err := NewClient(conf); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
 func TestSetQueryOptions(t *testing.T) {
 	c, s := makeClient(t, nil, nil)
 	defer s.Stop()
@@ -117,15 +239,20 @@ func TestSetQueryOptions(t *testing.T) {
 	r := c.newRequest("GET", "/v1/jobs")
 	q := &QueryOptions{
 		Region:     "foo",
+		Namespace:  "bar",
 		AllowStale: true,
 		WaitIndex:  1000,
 		WaitTime:   100 * time.Second,
+		AuthToken:  "foobar",
 	}
 	r.setQueryOptions(q)
 
 	if r.params.Get("region") != "foo" {
 		t.Fatalf("bad: %v", r.params)
 	}
+	if r.params.Get("namespace") != "bar" {
+		t.Fatalf("bad: %v", r.params)
+	}
 	if _, ok := r.params["stale"]; !ok {
 		t.Fatalf("bad: %v", r.params)
 	}
@@ -135,6 +262,9 @@ func TestSetQueryOptions(t *testing.T) {
 	if r.params.Get("wait") != "100000ms" {
 		t.Fatalf("bad: %v", r.params)
 	}
+	if r.token != "foobar" {
+		t.Fatalf("bad: %v", r.token)
+	}
 }
 
 func TestSetWriteOptions(t *testing.T) {
@@ -143,13 +273,21 @@ func TestSetWriteOptions(t *testing.T) {
 
 	r := c.newRequest("GET", "/v1/jobs")
 	q := &WriteOptions{
-		Region: "foo",
+		Region:    "foo",
+		Namespace: "bar",
+		AuthToken: "foobar",
 	}
 	r.setWriteOptions(q)
 
 	if r.params.Get("region") != "foo" {
 		t.Fatalf("bad: %v", r.params)
 	}
+	if r.params.Get("namespace") != "bar" {
+		t.Fatalf("bad: %v", r.params)
+	}
+	if r.token != "foobar" {
+		t.Fatalf("bad: %v", r.token)
+	}
 }
 
 func TestRequestToHTTP(t *testing.T) {
@@ -158,7 +296,8 @@ func TestRequestToHTTP(t *testing.T) {
 
 	r := c.newRequest("DELETE", "/v1/jobs/foo")
 	q := &QueryOptions{
-		Region: "foo",
+		Region:    "foo",
+		AuthToken: "foobar",
 	}
 	r.setQueryOptions(q)
 	req, err := r.toHTTP()
@@ -172,6 +311,9 @@ func TestRequestToHTTP(t *testing.T) {
 	if req.URL.RequestURI() != "/v1/jobs/foo?region=foo" {
 		t.Fatalf("bad: %v", req)
 	}
+	if req.Header.Get("X-Nomad-Token") != "foobar" {
+		t.Fatalf("bad: %v", req)
+	}
 }
 
 func TestParseQueryMeta(t *testing.T) {
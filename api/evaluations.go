@@ -51,6 +51,7 @@ func (e *Evaluations) Allocations(evalID string, q *QueryOptions) ([]*Allocation
 // Evaluation is used to serialize an evaluation.
 type Evaluation struct {
 	ID                string
+	Namespace         string
 	Priority          int
 	Type              string
 	TriggeredBy       string
@@ -26,14 +26,25 @@ func (c *Client) Jobs() *Jobs {
 // Register is used to register a new job. It returns the ID
 // of the evaluation, along with any errors encountered.
 func (j *Jobs) Register(job *Job, q *WriteOptions) (string, *WriteMeta, error) {
-	var resp registerJobResponse
+	resp, wm, err := j.RegisterWithWarnings(job, q)
+	if err != nil {
+		return "", nil, err
+	}
+	return resp.EvalID, wm, nil
+}
+
+// RegisterWithWarnings is used to register a new job. It returns the
+// response of the registration, which includes the warnings of the
+// admission controllers of the servers.
+func (j *Jobs) RegisterWithWarnings(job *Job, q *WriteOptions) (*JobRegisterResponse, *WriteMeta, error) {
+	var resp JobRegisterResponse
 
 	req := &registerJobRequest{job}
 	wm, err := j.client.write("/v1/jobs", req, &resp, q)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return resp.EvalID, wm, nil
+	return &resp, wm, nil
 }
 
 // List is used to list all of the existing jobs.
@@ -110,6 +121,7 @@ type UpdateStrategy struct {
 // Job is used to serialize a job.
 type Job struct {
 	Region            string
+	Namespace         string
 	ID                string
 	Name              string
 	Type              string
@@ -130,6 +142,7 @@ type Job struct {
 // jobs during list operations.
 type JobListStub struct {
 	ID                string
+	Namespace         string
 	Name              string
 	Type              string
 	Priority          int
@@ -216,6 +229,15 @@ type registerJobResponse struct {
 	EvalID string
 }
 
+// JobRegisterResponse is the response of a job registration
+type JobRegisterResponse struct {
+	EvalID string
+
+	// Warnings are the newline separated warnings of the admission
+	// controllers that admitted the job.
+	Warnings string
+}
+
 // deregisterJobResponse is used to decode a deregister response
 type deregisterJobResponse struct {
 	EvalID string
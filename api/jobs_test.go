@@ -48,6 +48,28 @@ func TestJobs_Register(t *testing.T) {
 	}
 }
 
+func TestJobs_RegisterWithWarnings(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	jobs := c.Jobs()
+
+	// Register a job
+	job := testJob()
+	resp, wm, err := jobs.RegisterWithWarnings(job, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// No admission controller warns about the job
+	if resp.EvalID == "" {
+		t.Fatalf("missing eval id")
+	}
+	if resp.Warnings != "" {
+		t.Fatalf("bad: %#v", resp.Warnings)
+	}
+}
+
 func TestJobs_Info(t *testing.T) {
 	c, s := makeClient(t, nil, nil)
 	defer s.Stop()
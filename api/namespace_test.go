@@ -0,0 +1,95 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNamespaces_CRUD(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	namespaces := c.Namespaces()
+
+	// Only the default namespace exists initially
+	resp, _, err := namespaces.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(resp) != 1 || resp[0].Name != "default" {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Create a namespace
+	ns := &Namespace{
+		Name:        "team-a",
+		Description: "Jobs of team A",
+	}
+	wm, err := namespaces.Register(ns, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// List the namespaces
+	resp, qm, err := namespaces.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if len(resp) != 2 || resp[1].Name != ns.Name {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Query the namespace
+	out, qm, err := namespaces.Info(ns.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if out.Description != ns.Description {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Register a job in the namespace
+	job := testJob()
+	q := &WriteOptions{Namespace: ns.Name}
+	if _, _, err := c.Jobs().Register(job, q); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The job isn't visible from the default namespace
+	jobs, _, err := c.Jobs().List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(jobs) != 0 {
+		t.Fatalf("bad: %#v", jobs)
+	}
+	jobs, _, err = c.Jobs().List(&QueryOptions{Namespace: ns.Name})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(jobs) != 1 || jobs[0].Namespace != ns.Name {
+		t.Fatalf("bad: %#v", jobs)
+	}
+
+	// Namespaces with jobs can't be deleted
+	if _, err := namespaces.Delete(ns.Name, nil); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, _, err := c.Jobs().Deregister(job.ID, q); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Delete the namespace
+	wm, err = namespaces.Delete(ns.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	_, _, err = namespaces.Info(ns.Name, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
@@ -67,11 +67,40 @@ func (n *Nodes) ForceEvaluate(nodeID string, q *WriteOptions) (string, *WriteMet
 	return resp.EvalID, wm, nil
 }
 
+// Stats returns the latest resource usage of the node's host. The request is
+// sent directly to the client agent running on the node.
+func (n *Nodes) Stats(nodeID string, q *QueryOptions) (*HostStats, error) {
+	nodeClient, err := n.client.getNodeClient(nodeID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp HostStats
+	if _, err := nodeClient.query("/v1/client/stats", &resp, nil); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GC garbage collects the terminal allocations on the node, destroying their
+// allocation directories. The request is sent directly to the client agent
+// running on the node.
+func (n *Nodes) GC(nodeID string, q *QueryOptions) error {
+	nodeClient, err := n.client.getNodeClient(nodeID, q)
+	if err != nil {
+		return err
+	}
+
+	_, err = nodeClient.write("/v1/client/gc", nil, nil, nil)
+	return err
+}
+
 // Node is used to deserialize a node entry.
 type Node struct {
 	ID                string
 	Datacenter        string
 	Name              string
+	HTTPAddr          string
 	Attributes        map[string]string
 	Resources         *Resources
 	Reserved          *Resources
@@ -85,6 +114,43 @@ type Node struct {
 	ModifyIndex       uint64
 }
 
+// HostStats represents the resource usage of the host running a client.
+type HostStats struct {
+	Memory    *HostMemoryStats
+	CPU       []*HostCPUStats
+	DiskStats []*HostDiskStats
+	Uptime    uint64
+	Timestamp int64
+}
+
+// HostMemoryStats is the memory usage of the host in bytes.
+type HostMemoryStats struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+	Free      uint64
+}
+
+// HostCPUStats is the percentage of time a core spent in each mode.
+type HostCPUStats struct {
+	CPU    string
+	User   float64
+	System float64
+	Idle   float64
+	Total  float64
+}
+
+// HostDiskStats is the usage of a mounted disk of the host.
+type HostDiskStats struct {
+	Device            string
+	Mountpoint        string
+	Size              uint64
+	Used              uint64
+	Available         uint64
+	UsedPercent       float64
+	InodesUsedPercent float64
+}
+
 // NodeListStub is a subset of information returned during
 // node list operations.
 type NodeListStub struct {
@@ -221,3 +221,64 @@ func TestNodes_Sort(t *testing.T) {
 		t.Fatalf("\n\n%#v\n\n%#v", nodes, expect)
 	}
 }
+
+func TestNodes_Stats(t *testing.T) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	nodes := c.Nodes()
+
+	// Retrieving stats of a non-existent node returns error
+	if _, err := nodes.Stats("nope", nil); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Wait for the node and its first host stats sample
+	var stats *HostStats
+	testutil.WaitForResult(func() (bool, error) {
+		out, _, err := nodes.List(nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(out); n != 1 {
+			return false, fmt.Errorf("expected 1 node, got: %d", n)
+		}
+		stats, err = nodes.Stats(out[0].ID, nil)
+		return err == nil, err
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+
+	if stats.Timestamp == 0 {
+		t.Fatalf("bad: %#v", stats)
+	}
+}
+
+func TestNodes_GC(t *testing.T) {
+	c, s := makeClient(t, nil, func(c *testutil.TestServerConfig) {
+		c.DevMode = true
+	})
+	defer s.Stop()
+	nodes := c.Nodes()
+
+	// Garbage collecting a non-existent node returns error
+	if err := nodes.GC("nope", nil); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Wait for the node and garbage collect it
+	testutil.WaitForResult(func() (bool, error) {
+		out, _, err := nodes.List(nil)
+		if err != nil {
+			return false, err
+		}
+		if n := len(out); n != 1 {
+			return false, fmt.Errorf("expected 1 node, got: %d", n)
+		}
+		err = nodes.GC(out[0].ID, nil)
+		return err == nil, err
+	}, func(err error) {
+		t.Fatalf("err: %s", err)
+	})
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Quotas is used to query the quota endpoints.
+type Quotas struct {
+	client *Client
+}
+
+// Quotas returns a handle on the quota endpoints.
+func (c *Client) Quotas() *Quotas {
+	return &Quotas{client: c}
+}
+
+// List is used to list all of the quota specifications.
+func (q *Quotas) List(qo *QueryOptions) ([]*QuotaSpec, *QueryMeta, error) {
+	var resp []*QuotaSpec
+	qm, err := q.client.query("/v1/quotas", &resp, qo)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Sort(QuotaSpecNameSort(resp))
+	return resp, qm, nil
+}
+
+// Info is used to query a specific quota specification.
+func (q *Quotas) Info(name string, qo *QueryOptions) (*QuotaSpec, *QueryMeta, error) {
+	if name == "" {
+		return nil, nil, fmt.Errorf("missing quota name")
+	}
+	var resp QuotaSpec
+	qm, err := q.client.query("/v1/quota/"+name, &resp, qo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &resp, qm, nil
+}
+
+// Register is used to create or update a quota specification.
+func (q *Quotas) Register(spec *QuotaSpec, qo *WriteOptions) (*WriteMeta, error) {
+	if spec == nil || spec.Name == "" {
+		return nil, fmt.Errorf("missing quota name")
+	}
+	return q.client.write("/v1/quota/"+spec.Name, spec, nil, qo)
+}
+
+// Delete is used to delete a quota specification. Quotas attached to
+// namespaces can't be deleted.
+func (q *Quotas) Delete(name string, qo *WriteOptions) (*WriteMeta, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing quota name")
+	}
+	return q.client.delete("/v1/quota/"+name, nil, qo)
+}
+
+// Usages is used to query the resources used by the allocations of each
+// namespace in the region.
+func (q *Quotas) Usages(qo *QueryOptions) ([]*QuotaUsage, *QueryMeta, error) {
+	var resp []*QuotaUsage
+	qm, err := q.client.query("/v1/quota-usages", &resp, qo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, qm, nil
+}
+
+// QuotaSpec limits the resources used by the allocations of the namespaces
+// it is attached to.
+type QuotaSpec struct {
+	Name        string
+	Description string
+	Limits      []*QuotaLimit
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// QuotaLimit limits the resources of a region. Zero values are unlimited.
+type QuotaLimit struct {
+	Region   string
+	CPU      int
+	MemoryMB int
+	DiskMB   int
+	Allocs   int
+}
+
+// QuotaUsage is the resources used by the non-terminal allocations of a
+// namespace.
+type QuotaUsage struct {
+	Namespace   string
+	CPU         int
+	MemoryMB    int
+	DiskMB      int
+	Allocs      int
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// QuotaSpecNameSort is used to sort quota specifications by name.
+type QuotaSpecNameSort []*QuotaSpec
+
+func (q QuotaSpecNameSort) Len() int {
+	return len(q)
+}
+
+func (q QuotaSpecNameSort) Less(i, j int) bool {
+	return q[i].Name < q[j].Name
+}
+
+func (q QuotaSpecNameSort) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQuotas_CRUD(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+	quotas := c.Quotas()
+
+	// Create a quota
+	spec := &QuotaSpec{
+		Name:        "batch",
+		Description: "Limits of batch jobs",
+		Limits: []*QuotaLimit{
+			&QuotaLimit{
+				Region:   "global",
+				CPU:      2000,
+				MemoryMB: 1024,
+			},
+		},
+	}
+	wm, err := quotas.Register(spec, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	// List the quotas
+	resp, qm, err := quotas.List(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if len(resp) != 1 || resp[0].Name != spec.Name {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// Query the quota
+	out, qm, err := quotas.Info(spec.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertQueryMeta(t, qm)
+	if len(out.Limits) != 1 || out.Limits[0].MemoryMB != 1024 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Attach the quota to a namespace
+	ns := &Namespace{Name: "batch", Quota: spec.Name}
+	if _, err := c.Namespaces().Register(ns, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Attached quotas can't be deleted
+	if _, err := quotas.Delete(spec.Name, nil); err == nil {
+		t.Fatalf("expected error")
+	}
+	if _, err := c.Namespaces().Delete(ns.Name, nil); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Delete the quota
+	wm, err = quotas.Delete(spec.Name, nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	assertWriteMeta(t, wm)
+
+	_, _, err = quotas.Info(spec.Name, nil)
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %#v", err)
+	}
+}
+
+func TestQuotas_Usages(t *testing.T) {
+	c, s := makeClient(t, nil, nil)
+	defer s.Stop()
+
+	// No allocations were placed yet
+	usages, _, err := c.Quotas().Usages(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(usages) != 0 {
+		t.Fatalf("bad: %#v", usages)
+	}
+}
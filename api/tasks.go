@@ -36,6 +36,29 @@ type Service struct {
 	Checks    []ServiceCheck
 }
 
+// EphemeralDisk is the disk shared by the tasks of a task group. A sticky or
+// migrated disk is carried over to the allocation replacing an allocation.
+type EphemeralDisk struct {
+	Sticky  bool
+	Migrate bool
+	SizeMB  int `mapstructure:"size"`
+}
+
+// VolumeRequest is a volume of the client requested by a task group.
+type VolumeRequest struct {
+	Name     string
+	Type     string
+	Source   string
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// VolumeMount mounts a volume of the task group into a task.
+type VolumeMount struct {
+	Volume      string
+	Destination string
+	ReadOnly    bool `mapstructure:"read_only"`
+}
+
 // TaskGroup is the unit of scheduling.
 type TaskGroup struct {
 	Name          string
@@ -43,6 +66,8 @@ type TaskGroup struct {
 	Constraints   []*Constraint
 	Tasks         []*Task
 	RestartPolicy *RestartPolicy
+	EphemeralDisk *EphemeralDisk
+	Volumes       map[string]*VolumeRequest
 	Meta          map[string]string
 }
 
@@ -69,6 +94,21 @@ func (g *TaskGroup) SetMeta(key, val string) *TaskGroup {
 	return g
 }
 
+// RequireDisk is used to set the ephemeral disk of a task group.
+func (g *TaskGroup) RequireDisk(disk *EphemeralDisk) *TaskGroup {
+	g.EphemeralDisk = disk
+	return g
+}
+
+// AddVolume is used to request a volume for the tasks of the task group.
+func (g *TaskGroup) AddVolume(v *VolumeRequest) *TaskGroup {
+	if g.Volumes == nil {
+		g.Volumes = make(map[string]*VolumeRequest)
+	}
+	g.Volumes[v.Name] = v
+	return g
+}
+
 // AddTask is used to add a new task to a task group.
 func (g *TaskGroup) AddTask(t *Task) *TaskGroup {
 	g.Tasks = append(g.Tasks, t)
@@ -77,14 +117,55 @@ func (g *TaskGroup) AddTask(t *Task) *TaskGroup {
 
 // Task is a single process in a task group.
 type Task struct {
-	Name        string
-	Driver      string
-	Config      map[string]interface{}
-	Constraints []*Constraint
-	Env         map[string]string
-	Services    []Service
-	Resources   *Resources
-	Meta        map[string]string
+	Name         string
+	Driver       string
+	Config       map[string]interface{}
+	Constraints  []*Constraint
+	Env          map[string]string
+	Services     []Service
+	Resources    *Resources
+	Meta         map[string]string
+	Lifecycle    *TaskLifecycle
+	Leader       bool
+	Artifacts    []*TaskArtifact
+	Templates    []*Template
+	Secrets      []*Secret
+	VolumeMounts []*VolumeMount
+}
+
+// TaskArtifact is an artifact to download before running the task.
+type TaskArtifact struct {
+	GetterSource  string            `mapstructure:"source"`
+	GetterOptions map[string]string `mapstructure:"options"`
+	RelativeDest  string            `mapstructure:"destination"`
+	Mode          string
+}
+
+// Template is a Go template rendered into the task's local directory.
+type Template struct {
+	SourcePath   string `mapstructure:"source"`
+	EmbeddedTmpl string `mapstructure:"data"`
+	DestPath     string `mapstructure:"destination"`
+	ChangeMode   string `mapstructure:"change_mode"`
+	ChangeSignal string `mapstructure:"change_signal"`
+}
+
+// Secret is a key of a secret injected into the task's secrets directory or
+// environment.
+type Secret struct {
+	Path         string
+	Key          string
+	DestPath     string `mapstructure:"destination"`
+	EnvVar       string `mapstructure:"env"`
+	ChangeMode   string `mapstructure:"change_mode"`
+	ChangeSignal string `mapstructure:"change_signal"`
+}
+
+// TaskLifecycle describes when a task is run relative to the main tasks of
+// its task group.
+type TaskLifecycle struct {
+	Hook    string
+	Sidecar bool
 }
 
 // NewTask creates and initializes a new Task.
@@ -126,6 +207,36 @@ func (t *Task) Constrain(c *Constraint) *Task {
 	return t
 }
 
+// AddArtifact adds an artifact to download before running the task.
+func (t *Task) AddArtifact(a *TaskArtifact) *Task {
+	t.Artifacts = append(t.Artifacts, a)
+	return t
+}
+
+// AddTemplate adds a template to render before running the task.
+func (t *Task) AddTemplate(tmpl *Template) *Task {
+	t.Templates = append(t.Templates, tmpl)
+	return t
+}
+
+// AddSecret adds a secret to inject before running the task.
+func (t *Task) AddSecret(s *Secret) *Task {
+	t.Secrets = append(t.Secrets, s)
+	return t
+}
+
+// AddVolumeMount mounts a volume of the task group into the task.
+func (t *Task) AddVolumeMount(m *VolumeMount) *Task {
+	t.VolumeMounts = append(t.VolumeMounts, m)
+	return t
+}
+
+// SetLifecycle is used to run the task as a lifecycle hook of the main tasks.
+func (t *Task) SetLifecycle(l *TaskLifecycle) *Task {
+	t.Lifecycle = l
+	return t
+}
+
 // TaskState tracks the current state of a task and events that caused state
 // transistions.
 type TaskState struct {
@@ -134,20 +245,73 @@ type TaskState struct {
 }
 
 const (
-	TaskDriverFailure = "Driver Failure"
-	TaskStarted       = "Started"
-	TaskTerminated    = "Terminated"
-	TaskKilled        = "Killed"
+	TaskDriverFailure          = "Driver Failure"
+	TaskStarted                = "Started"
+	TaskTerminated             = "Terminated"
+	TaskKilled                 = "Killed"
+	TaskLifecycleFailure       = "Lifecycle Failure"
+	TaskLeaderDead             = "Leader Task Dead"
+	TaskArtifactDownloadFailed = "Failed Artifact Download"
+	TaskTemplateFailure        = "Template Failure"
+	TaskRestartSignal          = "Restart Signaled"
+	TaskSignaling              = "Signaling"
+	TaskSecretFailure          = "Secret Failure"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
 // appropriate to the events type.
 type TaskEvent struct {
-	Type        string
-	Time        int64
-	DriverError string
-	ExitCode    int
-	Signal      int
-	Message     string
-	KillError   string
+	Type           string
+	Time           int64
+	DriverError    string
+	ExitCode       int
+	Signal         int
+	Message        string
+	KillError      string
+	LifecycleError string
+	DownloadError  string
+	TemplateError  string
+	Reason         string
+	SecretError    string
+}
+
+// MemoryStats holds the memory usage of a task.
+type MemoryStats struct {
+	RSS      uint64
+	Cache    uint64
+	Swap     uint64
+	MaxUsage uint64
+	Measured []string
+}
+
+// CpuStats holds the CPU usage of a task.
+type CpuStats struct {
+	SystemMode       float64
+	UserMode         float64
+	Percent          float64
+	ThrottledPeriods uint64
+	ThrottledTime    uint64
+	TotalTicks       float64
+	ReservedPercent  float64
+	Measured         []string
+}
+
+// ResourceUsage holds the memory and CPU usage of a task or allocation.
+type ResourceUsage struct {
+	MemoryStats *MemoryStats
+	CpuStats    *CpuStats
+}
+
+// TaskResourceUsage is a sample of the resource usage of a task.
+type TaskResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Timestamp     int64
+}
+
+// AllocResourceUsage is the resource usage of an allocation and of each of
+// its tasks.
+type AllocResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Tasks         map[string]*TaskResourceUsage
+	Timestamp     int64
 }
@@ -71,6 +71,38 @@ func TestTaskGroup_SetMeta(t *testing.T) {
 	}
 }
 
+func TestTaskGroup_RequireDisk(t *testing.T) {
+	grp := NewTaskGroup("grp1", 1)
+
+	// Sets the ephemeral disk
+	disk := &EphemeralDisk{SizeMB: 150, Sticky: true}
+	out := grp.RequireDisk(disk)
+	if grp.EphemeralDisk != disk {
+		t.Fatalf("expect: %#v, got: %#v", disk, grp.EphemeralDisk)
+	}
+
+	// Check that we returned the group
+	if out != grp {
+		t.Fatalf("expect: %#v, got: %#v", grp, out)
+	}
+}
+
+func TestTaskGroup_AddVolume(t *testing.T) {
+	grp := NewTaskGroup("grp1", 1)
+
+	// Requests the volume
+	vol := &VolumeRequest{Name: "certs", Type: "host", Source: "ca-certificates"}
+	out := grp.AddVolume(vol)
+	if grp.Volumes["certs"] != vol {
+		t.Fatalf("expect: %#v, got: %#v", vol, grp.Volumes)
+	}
+
+	// Check that we returned the group
+	if out != grp {
+		t.Fatalf("expect: %#v, got: %#v", grp, out)
+	}
+}
+
 func TestTaskGroup_AddTask(t *testing.T) {
 	grp := NewTaskGroup("grp1", 1)
 
@@ -217,3 +249,83 @@ func TestTask_Constrain(t *testing.T) {
 		t.Fatalf("expect: %#v, got: %#v", expect, task.Constraints)
 	}
 }
+
+func TestTask_SetLifecycle(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Set the lifecycle on the task
+	lifecycle := &TaskLifecycle{Hook: "prestart", Sidecar: true}
+	out := task.SetLifecycle(lifecycle)
+	if !reflect.DeepEqual(task.Lifecycle, lifecycle) {
+		t.Fatalf("expected: %#v, got: %#v", lifecycle, task.Lifecycle)
+	}
+
+	// Check that the task was returned
+	if out != task {
+		t.Fatalf("expected: %#v, got: %#v", task, out)
+	}
+}
+
+func TestTask_AddArtifact(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Add an artifact to the task
+	artifact := &TaskArtifact{GetterSource: "http://foo.com/bar.tgz"}
+	out := task.AddArtifact(artifact)
+	if n := len(task.Artifacts); n != 1 {
+		t.Fatalf("expected 1 artifact, got: %d", n)
+	}
+
+	// Check that the task was returned
+	if out != task {
+		t.Fatalf("expected: %#v, got: %#v", task, out)
+	}
+}
+
+func TestTask_AddVolumeMount(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Mount a volume into the task
+	mount := &VolumeMount{Volume: "certs", Destination: "/etc/ssl/certs"}
+	out := task.AddVolumeMount(mount)
+	if n := len(task.VolumeMounts); n != 1 {
+		t.Fatalf("expected 1 volume mount, got: %d", n)
+	}
+
+	// Check that the task was returned
+	if out != task {
+		t.Fatalf("expected: %#v, got: %#v", task, out)
+	}
+}
+
+func TestTask_AddSecret(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Add a secret to the task
+	secret := &Secret{Path: "secret/db", Key: "password", EnvVar: "DB_PASSWORD"}
+	out := task.AddSecret(secret)
+	if n := len(task.Secrets); n != 1 {
+		t.Fatalf("expected 1 secret, got: %d", n)
+	}
+
+	// Check that the task was returned
+	if out != task {
+		t.Fatalf("expected: %#v, got: %#v", task, out)
+	}
+}
+
+func TestTask_AddTemplate(t *testing.T) {
+	task := NewTask("task1", "exec")
+
+	// Add a template to the task
+	tmpl := &Template{EmbeddedTmpl: "{{.Env.NOMAD_IP}}", DestPath: "ip"}
+	out := task.AddTemplate(tmpl)
+	if n := len(task.Templates); n != 1 {
+		t.Fatalf("expected 1 template, got: %d", n)
+	}
+
+	// Check that the task was returned
+	if out != task {
+		t.Fatalf("expected: %#v, got: %#v", task, out)
+	}
+}
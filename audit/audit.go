@@ -0,0 +1,245 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TypeHTTP and TypeRPC are the types of the audited requests
+	TypeHTTP = "http"
+	TypeRPC  = "rpc"
+
+	// DefaultMaxBytes is the size the audit log is rotated at by default
+	DefaultMaxBytes = 64 * 1024 * 1024
+
+	// DefaultMaxFiles is the number of rotated audit logs kept by default
+	DefaultMaxFiles = 5
+)
+
+// Config configures the audit log.
+type Config struct {
+	// Path is the file the audit log is written to
+	Path string
+
+	// MaxBytes is the size the file is rotated at
+	MaxBytes int64
+
+	// MaxFiles is the number of rotated files kept besides the current one
+	MaxFiles int
+
+	// IncludeReads records the read requests along with the writes
+	IncludeReads bool
+
+	// Endpoints restricts the audit log to the requests whose path starts
+	// with one of the prefixes, such as "/v1/job" or "Job.". All requests
+	// are recorded if empty.
+	Endpoints []string
+}
+
+// Entry is a request recorded in the audit log.
+type Entry struct {
+	// Time is when the request was handled
+	Time time.Time
+
+	// Type is either TypeHTTP or TypeRPC
+	Type string
+
+	// RemoteAddr is the address the request came from
+	RemoteAddr string
+
+	// Identity is the accessor ID of the ACL token or the common name of
+	// the client certificate of the request, if any.
+	Identity string `json:",omitempty"`
+
+	// Method is the HTTP method of the request
+	Method string `json:",omitempty"`
+
+	// Path is the URL path of HTTP requests or the method of RPCs
+	Path string
+
+	// Status is the status code of HTTP requests
+	Status int `json:",omitempty"`
+
+	// Error is the error the request failed with
+	Error string `json:",omitempty"`
+
+	// PayloadHash is the hex encoded SHA-256 of the request payload
+	PayloadHash string `json:",omitempty"`
+}
+
+// Logger writes entries to the audit log as JSON lines. The file is
+// rotated once it reaches the configured size. A nil Logger records
+// nothing.
+type Logger struct {
+	config *Config
+
+	file *os.File
+	size int64
+	l    sync.Mutex
+}
+
+// NewLogger opens the audit log of the configuration.
+func NewLogger(config *Config) (*Logger, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("audit log path must be set")
+	}
+	conf := *config
+	if conf.MaxBytes <= 0 {
+		conf.MaxBytes = DefaultMaxBytes
+	}
+	if conf.MaxFiles <= 0 {
+		conf.MaxFiles = DefaultMaxFiles
+	}
+
+	l := &Logger{config: &conf}
+	if err := os.MkdirAll(filepath.Dir(conf.Path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Enabled returns whether requests to the path are recorded.
+func (l *Logger) Enabled(path string, read bool) bool {
+	if l == nil {
+		return false
+	}
+	if read && !l.config.IncludeReads {
+		return false
+	}
+	if len(l.config.Endpoints) == 0 {
+		return true
+	}
+	for _, prefix := range l.config.Endpoints {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Log writes the entry to the audit log, rotating the file if needed.
+func (l *Logger) Log(e *Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	buf, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	buf = append(buf, '\n')
+
+	l.l.Lock()
+	defer l.l.Unlock()
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(buf)) > l.config.MaxBytes {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(buf)
+	l.size += int64(n)
+	return err
+}
+
+// Close closes the audit log.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.l.Lock()
+	defer l.l.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// open opens the audit log for appending.
+func (l *Logger) open() error {
+	f, err := os.OpenFile(l.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat audit log: %v", err)
+	}
+	l.file = f
+	l.size = fi.Size()
+	return nil
+}
+
+// rotate shifts the rotated files, dropping the oldest one, and moves the
+// current file to "<path>.1" before opening a new one. The lock must be
+// held.
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+	l.file = nil
+
+	path := l.config.Path
+	os.Remove(fmt.Sprintf("%s.%d", path, l.config.MaxFiles))
+	for i := l.config.MaxFiles - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+1))
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %v", err)
+	}
+	return l.open()
+}
+
+// HashPayload returns the hex encoded SHA-256 of the payload.
+func HashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// PayloadHasher wraps a request body to hash the payload as it is read, so
+// that it doesn't need to be buffered.
+type PayloadHasher struct {
+	io.ReadCloser
+	hash hash.Hash
+	read bool
+}
+
+// NewPayloadHasher returns a hasher reading from the body.
+func NewPayloadHasher(body io.ReadCloser) *PayloadHasher {
+	return &PayloadHasher{ReadCloser: body, hash: sha256.New()}
+}
+
+func (p *PayloadHasher) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if n > 0 {
+		p.read = true
+		p.hash.Write(b[:n])
+	}
+	return n, err
+}
+
+// Sum returns the hash of the payload read so far, as HashPayload does, or
+// an empty string if nothing was read.
+func (p *PayloadHasher) Sum() string {
+	if !p.read {
+		return ""
+	}
+	return hex.EncodeToString(p.hash.Sum(nil))
+}
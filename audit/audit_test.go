@@ -0,0 +1,169 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testLogger(t *testing.T, config *Config) (*Logger, string) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	config.Path = filepath.Join(dir, "audit", "audit.log")
+	l, err := NewLogger(config)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+	return l, dir
+}
+
+func readEntries(t *testing.T, path string) []*Entry {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries
+}
+
+func TestLogger_Log(t *testing.T) {
+	l, dir := testLogger(t, &Config{})
+	defer os.RemoveAll(dir)
+	defer l.Close()
+
+	e := &Entry{
+		Type:        TypeHTTP,
+		RemoteAddr:  "127.0.0.1:1234",
+		Method:      "PUT",
+		Path:        "/v1/jobs",
+		Status:      200,
+		PayloadHash: HashPayload([]byte("{}")),
+	}
+	if err := l.Log(e); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := l.Log(&Entry{Type: TypeRPC, Path: "Job.Register", Error: "Permission denied"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	entries := readEntries(t, l.config.Path)
+	if len(entries) != 2 {
+		t.Fatalf("bad: %#v", entries)
+	}
+	if out := entries[0]; out.Path != "/v1/jobs" || out.Status != 200 || out.Time.IsZero() ||
+		out.PayloadHash != "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a" {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out := entries[1]; out.Path != "Job.Register" || out.Error != "Permission denied" {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Fails once closed
+	l.Close()
+	if err := l.Log(e); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestLogger_Rotate(t *testing.T) {
+	l, dir := testLogger(t, &Config{MaxBytes: 100, MaxFiles: 2})
+	defer os.RemoveAll(dir)
+	defer l.Close()
+
+	// Each entry exceeds the size so every write rotates the file
+	for _, path := range []string{"Job.Register", "Job.Deregister", "Node.UpdateDrain", "Job.Evaluate"} {
+		e := &Entry{Type: TypeRPC, RemoteAddr: "127.0.0.1:1234", Path: path}
+		if err := l.Log(e); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	path := l.config.Path
+	for file, expect := range map[string]string{
+		path:        "Job.Evaluate",
+		path + ".1": "Node.UpdateDrain",
+		path + ".2": "Job.Deregister",
+	} {
+		entries := readEntries(t, file)
+		if len(entries) != 1 || entries[0].Path != expect {
+			t.Fatalf("bad %s: %#v", file, entries)
+		}
+	}
+
+	// The oldest file was dropped
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third rotated file, got: %v", err)
+	}
+}
+
+func TestLogger_Enabled(t *testing.T) {
+	// Nothing is recorded without a logger
+	var l *Logger
+	if l.Enabled("/v1/jobs", false) {
+		t.Fatalf("nil logger should be disabled")
+	}
+
+	// Writes are recorded by default
+	l = &Logger{config: &Config{}}
+	if !l.Enabled("/v1/jobs", false) || l.Enabled("/v1/jobs", true) {
+		t.Fatalf("bad")
+	}
+
+	// Reads are opt-in
+	l.config.IncludeReads = true
+	if !l.Enabled("/v1/jobs", true) {
+		t.Fatalf("bad")
+	}
+
+	// Endpoints filter the requests
+	l.config.Endpoints = []string{"/v1/job", "Job."}
+	for path, expect := range map[string]bool{
+		"/v1/jobs":         true,
+		"/v1/job/foo":      true,
+		"Job.Register":     true,
+		"/v1/node/foo":     false,
+		"Node.UpdateDrain": false,
+	} {
+		if out := l.Enabled(path, false); out != expect {
+			t.Fatalf("%s: expected %v, got %v", path, expect, out)
+		}
+	}
+}
+
+func TestPayloadHasher(t *testing.T) {
+	payload := []byte(`{"Job":{"ID":"example"}}`)
+	p := NewPayloadHasher(ioutil.NopCloser(bytes.NewReader(payload)))
+
+	// Nothing is hashed until the payload is read
+	if sum := p.Sum(); sum != "" {
+		t.Fatalf("bad: %#v", sum)
+	}
+
+	out, err := ioutil.ReadAll(p)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !bytes.Equal(out, payload) {
+		t.Fatalf("bad: %s", out)
+	}
+	if sum := p.Sum(); sum != HashPayload(payload) {
+		t.Fatalf("bad: %#v", sum)
+	}
+}
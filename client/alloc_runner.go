@@ -3,6 +3,7 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -14,6 +15,8 @@ import (
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
 	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
 const (
@@ -45,6 +48,7 @@ type AllocRunner struct {
 	ctx           *driver.ExecContext
 	tasks         map[string]*TaskRunner
 	restored      map[string]struct{}
+	started       map[string]struct{}
 	RestartPolicy *structs.RestartPolicy
 	taskLock      sync.RWMutex
 
@@ -52,6 +56,13 @@ type AllocRunner struct {
 
 	updateCh chan *structs.Allocation
 
+	// prevAlloc carries the ephemeral disk of the allocation this one
+	// replaces over to this allocation
+	prevAlloc prevAllocDir
+
+	// stoppedCh is closed once the tasks of the allocation have stopped
+	stoppedCh chan struct{}
+
 	destroy     bool
 	destroyCh   chan struct{}
 	destroyLock sync.Mutex
@@ -78,7 +89,9 @@ func NewAllocRunner(logger *log.Logger, config *config.Config, updater AllocStat
 		dirtyCh:       make(chan struct{}, 1),
 		tasks:         make(map[string]*TaskRunner),
 		restored:      make(map[string]struct{}),
+		started:       make(map[string]struct{}),
 		updateCh:      make(chan *structs.Allocation, 8),
+		stoppedCh:     make(chan struct{}),
 		destroyCh:     make(chan struct{}),
 		waitCh:        make(chan struct{}),
 	}
@@ -102,6 +115,10 @@ func (r *AllocRunner) RestoreState() error {
 	r.alloc = snap.Alloc
 	r.RestartPolicy = snap.RestartPolicy
 	r.ctx = snap.Context
+	if r.ctx != nil {
+		r.ctx.Alloc = r.alloc
+		r.ctx.Node = r.config.Node
+	}
 
 	// Restore the task runners
 	var mErr multierror.Error
@@ -125,6 +142,7 @@ func (r *AllocRunner) RestoreState() error {
 			r.logger.Printf("[ERR] client: failed to restore state for alloc %s task '%s': %v", r.alloc.ID, name, err)
 			mErr.Errors = append(mErr.Errors, err)
 		} else {
+			r.started[name] = struct{}{}
 			go tr.Run()
 		}
 	}
@@ -136,6 +154,11 @@ func (r *AllocRunner) RestoreState() error {
 // is snapshotted. If fullSync is marked as true, we snapshot
 // all the Task Runners associated with the Alloc
 func (r *AllocRunner) SaveState() error {
+	// The state of a destroyed allocation is removed rather than saved
+	if r.IsDestroyed() {
+		return nil
+	}
+
 	if err := r.saveAllocRunnerState(); err != nil {
 		return err
 	}
@@ -179,6 +202,9 @@ func (r *AllocRunner) DestroyState() error {
 
 // DestroyContext is used to destroy the context
 func (r *AllocRunner) DestroyContext() error {
+	if r.ctx == nil || r.ctx.AllocDir == nil {
+		return nil
+	}
 	return r.ctx.AllocDir.Destroy()
 }
 
@@ -221,7 +247,12 @@ func (r *AllocRunner) syncStatus() error {
 	// Scan the task states to determine the status of the alloc
 	var pending, running, dead, failed bool
 	r.taskStatusLock.RLock()
-	for _, state := range r.alloc.TaskStates {
+	for name, state := range r.alloc.TaskStates {
+		// The outcome of a dead leader decides the outcome of the alloc
+		if r.isLeader(name) && state.State == structs.TaskStateDead && leaderFailed(state) {
+			failed = true
+		}
+
 		switch state.State {
 		case structs.TaskStateRunning:
 			running = true
@@ -229,9 +260,11 @@ func (r *AllocRunner) syncStatus() error {
 			pending = true
 		case structs.TaskStateDead:
 			last := len(state.Events) - 1
-			if state.Events[last].Type == structs.TaskDriverFailure {
+			switch state.Events[last].Type {
+			case structs.TaskDriverFailure, structs.TaskLifecycleFailure, structs.TaskArtifactDownloadFailed,
+				structs.TaskTemplateFailure:
 				failed = true
-			} else {
+			default:
 				dead = true
 			}
 		}
@@ -260,6 +293,35 @@ func (r *AllocRunner) syncStatus() error {
 	return nil
 }
 
+// isLeader returns whether the named task is the leader of its task group.
+func (r *AllocRunner) isLeader(taskName string) bool {
+	if r.alloc.Job == nil {
+		return false
+	}
+	tg := r.alloc.Job.LookupTaskGroup(r.alloc.TaskGroup)
+	if tg == nil {
+		return false
+	}
+	task := tg.LookupTask(taskName)
+	return task != nil && task.Leader
+}
+
+// leaderFailed returns whether the dead leader task exited unsuccessfully.
+func leaderFailed(state *structs.TaskState) bool {
+	if len(state.Events) == 0 {
+		return false
+	}
+	last := state.Events[len(state.Events)-1]
+	switch last.Type {
+	case structs.TaskTerminated:
+		return last.ExitCode != 0 || last.Signal != 0 || last.Message != ""
+	case structs.TaskKilled, structs.TaskLeaderDead:
+		return false
+	default:
+		return true
+	}
+}
+
 // setStatus is used to update the allocation status
 func (r *AllocRunner) setStatus(status, desc string) {
 	r.alloc.ClientStatus = status
@@ -287,6 +349,12 @@ func (r *AllocRunner) Run() {
 	alloc := r.alloc
 	if alloc.TerminalStatus() {
 		r.logger.Printf("[DEBUG] client: aborting runner for alloc '%s', terminal status", r.alloc.ID)
+
+		// A restored terminal allocation keeps its context until it is
+		// destroyed, either by the server or by the garbage collector.
+		close(r.stoppedCh)
+		<-r.destroyCh
+		r.destroyContextAndState()
 		return
 	}
 	r.logger.Printf("[DEBUG] client: starting runner for alloc '%s'", r.alloc.ID)
@@ -296,6 +364,7 @@ func (r *AllocRunner) Run() {
 	if tg == nil {
 		r.logger.Printf("[ERR] client: alloc '%s' for missing task group '%s'", alloc.ID, alloc.TaskGroup)
 		r.setStatus(structs.AllocClientStatusFailed, fmt.Sprintf("missing task group '%s'", alloc.TaskGroup))
+		close(r.stoppedCh)
 		return
 	}
 
@@ -308,12 +377,23 @@ func (r *AllocRunner) Run() {
 		if err := allocDir.Build(tg.Tasks); err != nil {
 			r.logger.Printf("[WARN] client: failed to build task directories: %v", err)
 			r.setStatus(structs.AllocClientStatusFailed, fmt.Sprintf("failed to build task dirs for '%s'", alloc.TaskGroup))
+			close(r.stoppedCh)
 			return
 		}
-		r.ctx = driver.NewExecContext(allocDir, r.alloc.ID)
+
+		// Carry over the ephemeral disk of the allocation being replaced.
+		// The allocation still runs with an empty disk if that fails.
+		if r.prevAlloc != nil {
+			r.logger.Printf("[DEBUG] client: migrating data of alloc '%s' to alloc '%s'", alloc.PreviousAllocation, alloc.ID)
+			if err := r.prevAlloc.Migrate(allocDir, r.destroyCh); err != nil {
+				r.logger.Printf("[WARN] client: failed to migrate data of alloc '%s' to alloc '%s': %v",
+					alloc.PreviousAllocation, alloc.ID, err)
+			}
+		}
+		r.ctx = driver.NewExecContext(allocDir, r.alloc, r.config.Node)
 	}
 
-	// Start the task runners
+	// Create the task runners
 	r.taskLock.Lock()
 	for _, task := range tg.Tasks {
 		if _, ok := r.restored[task.Name]; ok {
@@ -327,10 +407,17 @@ func (r *AllocRunner) Run() {
 			r.alloc, task, r.alloc.TaskStates[task.Name], restartTracker,
 			r.consulService)
 		r.tasks[task.Name] = tr
-		go tr.Run()
 	}
 	r.taskLock.Unlock()
 
+	// Start the task runners in lifecycle order
+	stopCh := make(chan struct{})
+	tasksDoneCh := make(chan struct{})
+	go func() {
+		defer close(tasksDoneCh)
+		r.runTasks(tg, stopCh)
+	}()
+
 OUTER:
 	// Wait for updates
 	for {
@@ -370,33 +457,283 @@ OUTER:
 		}
 	}
 
-	// Destroy each sub-task
+	// Stop starting tasks
+	close(stopCh)
+	<-tasksDoneCh
+
+	// Destroy each started sub-task
 	r.taskLock.RLock()
 	defer r.taskLock.RUnlock()
-	for _, tr := range r.tasks {
-		tr.Destroy()
+	for name := range r.started {
+		r.tasks[name].Destroy()
 	}
 
 	// Wait for termination of the task runners
-	for _, tr := range r.tasks {
-		<-tr.WaitCh()
+	for name := range r.started {
+		<-r.tasks[name].WaitCh()
 	}
 
+	close(r.stoppedCh)
+
 	// Final state sync
 	r.retrySyncState(nil)
 
-	// Check if we should destroy our state
-	if r.destroy {
-		if err := r.DestroyContext(); err != nil {
-			r.logger.Printf("[ERR] client: failed to destroy context for alloc '%s': %v",
-				r.alloc.ID, err)
+	// The context of a terminal allocation is kept until it is destroyed
+	<-r.destroyCh
+	r.destroyContextAndState()
+	r.logger.Printf("[DEBUG] client: terminating runner for alloc '%s'", r.alloc.ID)
+}
+
+// destroyContextAndState removes the allocation directory and the persisted
+// state of the allocation.
+func (r *AllocRunner) destroyContextAndState() {
+	if err := r.DestroyContext(); err != nil {
+		r.logger.Printf("[ERR] client: failed to destroy context for alloc '%s': %v",
+			r.alloc.ID, err)
+	}
+	if err := r.DestroyState(); err != nil {
+		r.logger.Printf("[ERR] client: failed to destroy state for alloc '%s': %v",
+			r.alloc.ID, err)
+	}
+}
+
+// runTasks starts the tasks of the task group in lifecycle order. Prestart
+// tasks are started first and the main tasks only once the ephemeral prestart
+// tasks have completed successfully. Poststart tasks are started after the
+// main tasks, and once the main tasks have exited the remaining hooks are
+// stopped and the poststop tasks are run. If the task group has a leader, the
+// other tasks are killed as soon as the leader exits. runTasks returns early
+// if stopCh is closed.
+func (r *AllocRunner) runTasks(tg *structs.TaskGroup, stopCh <-chan struct{}) {
+	var prestart, main, poststart, poststop, ephemeral []*structs.Task
+	var leader *structs.Task
+	for _, task := range tg.Tasks {
+		if task.IsMain() {
+			main = append(main, task)
+			if task.Leader {
+				leader = task
+			}
+			continue
 		}
-		if err := r.DestroyState(); err != nil {
-			r.logger.Printf("[ERR] client: failed to destroy state for alloc '%s': %v",
-				r.alloc.ID, err)
+
+		switch task.Lifecycle.Hook {
+		case structs.TaskLifecycleHookPrestart:
+			prestart = append(prestart, task)
+			if task.IsEphemeral() {
+				ephemeral = append(ephemeral, task)
+			}
+		case structs.TaskLifecycleHookPoststart:
+			poststart = append(poststart, task)
+		case structs.TaskLifecycleHookPoststop:
+			poststop = append(poststop, task)
 		}
 	}
-	r.logger.Printf("[DEBUG] client: terminating runner for alloc '%s'", r.alloc.ID)
+
+	// Run the prestart tasks and wait for the ephemeral ones to complete
+	r.startTasks(prestart, stopCh)
+	if !r.waitTasks(ephemeral, stopCh) {
+		return
+	}
+	for _, task := range ephemeral {
+		if r.taskSucceeded(task.Name) {
+			continue
+		}
+
+		err := fmt.Errorf("prestart task '%s' failed", task.Name)
+		r.logger.Printf("[ERR] client: %v for alloc '%s'", err, r.alloc.ID)
+		r.failTasks(main, err)
+		r.failTasks(poststart, err)
+		r.failTasks(poststop, err)
+		r.stopTasks(prestart, structs.TaskKilled)
+		return
+	}
+
+	// Start the main tasks followed by the poststart tasks
+	r.startTasks(main, stopCh)
+	r.startTasks(poststart, stopCh)
+
+	// Wait for the main tasks to exit. If there is a leader, its exit kills
+	// the remaining main tasks.
+	stopEvent := structs.TaskKilled
+	if leader != nil {
+		if !r.waitTasks([]*structs.Task{leader}, stopCh) {
+			return
+		}
+		r.logger.Printf("[DEBUG] client: leader task '%s' of alloc '%s' is dead, killing remaining tasks",
+			leader.Name, r.alloc.ID)
+		stopEvent = structs.TaskLeaderDead
+		r.stopTasks(main, stopEvent)
+	} else if !r.waitTasks(main, stopCh) {
+		return
+	}
+
+	// The main tasks have exited so stop the hooks that were running
+	// alongside them and run the poststop tasks
+	r.stopTasks(prestart, stopEvent)
+	r.stopTasks(poststart, stopEvent)
+	r.startTasks(poststop, stopCh)
+	r.waitTasks(poststop, stopCh)
+}
+
+// startTasks starts the task runners of the given tasks unless they have
+// already been started or are dead.
+func (r *AllocRunner) startTasks(tasks []*structs.Task, stopCh <-chan struct{}) {
+	r.taskLock.Lock()
+	defer r.taskLock.Unlock()
+	for _, task := range tasks {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		if _, ok := r.started[task.Name]; ok {
+			continue
+		}
+		if r.taskDead(task.Name) {
+			continue
+		}
+
+		r.started[task.Name] = struct{}{}
+		go r.tasks[task.Name].Run()
+	}
+}
+
+// waitTasks blocks until the started task runners of the given tasks have
+// exited. It returns false if stopCh is closed first.
+func (r *AllocRunner) waitTasks(tasks []*structs.Task, stopCh <-chan struct{}) bool {
+	for _, task := range tasks {
+		r.taskLock.RLock()
+		tr := r.tasks[task.Name]
+		_, started := r.started[task.Name]
+		r.taskLock.RUnlock()
+		if !started {
+			continue
+		}
+
+		select {
+		case <-tr.WaitCh():
+		case <-stopCh:
+			return false
+		}
+	}
+	return true
+}
+
+// stopTasks destroys the started task runners of the given tasks and waits
+// for them to exit. Tasks that are still running are marked with an event of
+// the given type.
+func (r *AllocRunner) stopTasks(tasks []*structs.Task, eventType string) {
+	var runners []*TaskRunner
+	r.taskLock.RLock()
+	for _, task := range tasks {
+		if _, ok := r.started[task.Name]; ok {
+			runners = append(runners, r.tasks[task.Name])
+		}
+	}
+	r.taskLock.RUnlock()
+
+	for _, tr := range runners {
+		tr.DestroyWithEvent(structs.NewTaskEvent(eventType))
+	}
+	for _, tr := range runners {
+		<-tr.WaitCh()
+	}
+}
+
+// failTasks marks the given tasks that haven't been started as dead because
+// of a failed lifecycle hook.
+func (r *AllocRunner) failTasks(tasks []*structs.Task, err error) {
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+	for _, task := range tasks {
+		if _, ok := r.started[task.Name]; ok {
+			continue
+		}
+		e := structs.NewTaskEvent(structs.TaskLifecycleFailure).SetLifecycleError(err)
+		r.tasks[task.Name].setState(structs.TaskStateDead, e)
+	}
+}
+
+// taskDead returns whether the task is dead.
+func (r *AllocRunner) taskDead(taskName string) bool {
+	r.taskStatusLock.RLock()
+	defer r.taskStatusLock.RUnlock()
+	state := r.alloc.TaskStates[taskName]
+	return state != nil && state.State == structs.TaskStateDead
+}
+
+// taskSucceeded returns whether the task has run to completion successfully.
+func (r *AllocRunner) taskSucceeded(taskName string) bool {
+	r.taskStatusLock.RLock()
+	defer r.taskStatusLock.RUnlock()
+	state := r.alloc.TaskStates[taskName]
+	if state == nil || state.State != structs.TaskStateDead || len(state.Events) == 0 {
+		return false
+	}
+	last := state.Events[len(state.Events)-1]
+	return last.Type == structs.TaskTerminated && last.ExitCode == 0 &&
+		last.Signal == 0 && last.Message == ""
+}
+
+// Exec runs a command inside the named task of the allocation.
+func (r *AllocRunner) Exec(taskName string, req *cstructs.ExecRequest) (int, error) {
+	r.taskLock.RLock()
+	tr, ok := r.tasks[taskName]
+	r.taskLock.RUnlock()
+	if !ok {
+		return -1, fmt.Errorf("unknown task '%s' in alloc '%s'", taskName, r.alloc.ID)
+	}
+	return tr.Exec(req)
+}
+
+// LatestAllocStats returns the latest resource usage of the allocation,
+// which is the sum of the latest samples of its tasks. If taskFilter is set
+// only the usage of that task is returned.
+func (r *AllocRunner) LatestAllocStats(taskFilter string) (*cstructs.AllocResourceUsage, error) {
+	r.taskLock.RLock()
+	defer r.taskLock.RUnlock()
+
+	var runners []*TaskRunner
+	if taskFilter != "" {
+		tr, ok := r.tasks[taskFilter]
+		if !ok {
+			return nil, fmt.Errorf("unknown task '%s' in alloc '%s'", taskFilter, r.alloc.ID)
+		}
+		runners = append(runners, tr)
+	} else {
+		for _, tr := range r.tasks {
+			runners = append(runners, tr)
+		}
+	}
+
+	usage := &cstructs.AllocResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{},
+		Tasks:         make(map[string]*cstructs.TaskResourceUsage, len(runners)),
+	}
+	var reservedCPU int
+	for _, tr := range runners {
+		ru := tr.LatestResourceUsage()
+		if ru == nil {
+			continue
+		}
+		usage.Tasks[tr.task.Name] = ru
+		if ru.ResourceUsage != nil {
+			usage.ResourceUsage.Add(ru.ResourceUsage)
+		}
+		if tr.task.Resources != nil {
+			reservedCPU += tr.task.Resources.CPU
+		}
+
+		// The timestamp is that of the most recent sample
+		if ru.Timestamp > usage.Timestamp {
+			usage.Timestamp = ru.Timestamp
+		}
+	}
+	if cs := usage.ResourceUsage.CpuStats; cs != nil && reservedCPU > 0 {
+		cs.ReservedPercent = cs.TotalTicks / float64(reservedCPU) * 100
+	}
+	return usage, nil
 }
 
 // Update is used to update the allocation of the context
@@ -420,6 +757,46 @@ func (r *AllocRunner) Destroy() {
 	close(r.destroyCh)
 }
 
+// SetPreviousAlloc sets how the ephemeral disk of the allocation this one
+// replaces is carried over. It must be called before Run.
+func (r *AllocRunner) SetPreviousAlloc(prev prevAllocDir) {
+	r.prevAlloc = prev
+}
+
+// StoppedCh returns a channel that is closed once the tasks of the
+// allocation have stopped
+func (r *AllocRunner) StoppedCh() <-chan struct{} {
+	return r.stoppedCh
+}
+
+// allocDir returns the alloc dir of the allocation, or nil if it hasn't been
+// built
+func (r *AllocRunner) allocDir() *allocdir.AllocDir {
+	if r.ctx == nil {
+		return nil
+	}
+	return r.ctx.AllocDir
+}
+
+// Snapshot writes a tar archive of the ephemeral disk of the allocation to w
+func (r *AllocRunner) Snapshot(w io.Writer) error {
+	allocDir := r.allocDir()
+	if allocDir == nil || r.IsDestroyed() {
+		return fmt.Errorf("alloc '%s' has no alloc dir", r.alloc.ID)
+	}
+	if _, err := os.Stat(allocDir.AllocDir); err != nil {
+		return fmt.Errorf("alloc '%s' has no alloc dir: %v", r.alloc.ID, err)
+	}
+	return allocDir.Snapshot(w)
+}
+
+// IsDestroyed returns whether the allocation has been destroyed
+func (r *AllocRunner) IsDestroyed() bool {
+	r.destroyLock.Lock()
+	defer r.destroyLock.Unlock()
+	return r.destroy
+}
+
 // WaitCh returns a channel to wait for termination
 func (r *AllocRunner) WaitCh() <-chan struct{} {
 	return r.waitCh
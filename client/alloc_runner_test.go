@@ -1,7 +1,9 @@
 package client
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -170,3 +172,178 @@ func TestAllocRunner_SaveRestoreState(t *testing.T) {
 		t.Fatalf("took too long to terminate")
 	}
 }
+
+// testLifecycleAllocRunner returns an alloc runner whose task group runs the
+// given prestart command before the main task.
+func testLifecycleAllocRunner(prestartCmd string) (*MockAllocStateUpdater, *AllocRunner) {
+	upd, ar := testAllocRunner(false)
+	tg := ar.alloc.Job.LookupTaskGroup(ar.alloc.TaskGroup)
+	tg.RestartPolicy.Mode = structs.RestartPolicyModeFail
+	tg.RestartPolicy.Interval = 10 * time.Minute
+
+	mainTask := tg.Tasks[0]
+	prestart := &structs.Task{
+		Name:   "init",
+		Driver: mainTask.Driver,
+		Config: map[string]interface{}{
+			"command": prestartCmd,
+		},
+		Lifecycle: &structs.TaskLifecycleConfig{
+			Hook: structs.TaskLifecycleHookPrestart,
+		},
+	}
+	tg.Tasks = append(tg.Tasks, prestart)
+	ar.alloc.TaskResources[prestart.Name] = ar.alloc.TaskResources[mainTask.Name]
+	ar.alloc.TaskStates[prestart.Name] = &structs.TaskState{State: structs.TaskStatePending}
+	return upd, ar
+}
+
+func TestAllocRunner_Lifecycle_Prestart(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	upd, ar := testLifecycleAllocRunner("/bin/true")
+	go ar.Run()
+	defer ar.Destroy()
+
+	testutil.WaitForResult(func() (bool, error) {
+		if upd.Count == 0 {
+			return false, nil
+		}
+		last := upd.Allocs[upd.Count-1]
+		if last.ClientStatus != structs.AllocClientStatusDead {
+			return false, fmt.Errorf("got client status %v; want %v", last.ClientStatus, structs.AllocClientStatusDead)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+
+	// The main task must only have been started after the prestart task
+	// completed
+	initEvents := ar.alloc.TaskStates["init"].Events
+	webEvents := ar.alloc.TaskStates["web"].Events
+	if webEvents[0].Type != structs.TaskStarted {
+		t.Fatalf("main task not started: %#v", webEvents)
+	}
+	if webEvents[0].Time < initEvents[len(initEvents)-1].Time {
+		t.Fatalf("main task started before prestart task completed")
+	}
+}
+
+func TestAllocRunner_Lifecycle_PrestartFailed(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	upd, ar := testLifecycleAllocRunner("/bin/false")
+	go ar.Run()
+	defer ar.Destroy()
+
+	testutil.WaitForResult(func() (bool, error) {
+		if upd.Count == 0 {
+			return false, nil
+		}
+		last := upd.Allocs[upd.Count-1]
+		if last.ClientStatus != structs.AllocClientStatusFailed {
+			return false, fmt.Errorf("got client status %v; want %v", last.ClientStatus, structs.AllocClientStatusFailed)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+
+	// The main task must not have been run
+	state := ar.alloc.TaskStates["web"]
+	if len(state.Events) != 1 {
+		t.Fatalf("bad: %#v", state.Events)
+	}
+	if e := state.Events[0]; e.Type != structs.TaskLifecycleFailure || !strings.Contains(e.LifecycleError, "init") {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
+func TestAllocRunner_Leader(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	upd, ar := testAllocRunner(false)
+	tg := ar.alloc.Job.LookupTaskGroup(ar.alloc.TaskGroup)
+	tg.RestartPolicy.Mode = structs.RestartPolicyModeFail
+	tg.RestartPolicy.Interval = 10 * time.Minute
+
+	// The leader exits quickly while its sibling would run for a while
+	leader := tg.Tasks[0]
+	leader.Leader = true
+	sibling := &structs.Task{
+		Name:   "logs",
+		Driver: leader.Driver,
+		Config: map[string]interface{}{
+			"command": "/bin/sleep",
+			"args":    []string{"10"},
+		},
+	}
+	tg.Tasks = append(tg.Tasks, sibling)
+	ar.alloc.TaskResources[sibling.Name] = ar.alloc.TaskResources[leader.Name]
+	ar.alloc.TaskStates[sibling.Name] = &structs.TaskState{State: structs.TaskStatePending}
+
+	go ar.Run()
+	defer ar.Destroy()
+	start := time.Now()
+
+	testutil.WaitForResult(func() (bool, error) {
+		if upd.Count == 0 {
+			return false, nil
+		}
+		last := upd.Allocs[upd.Count-1]
+		if last.ClientStatus != structs.AllocClientStatusDead {
+			return false, fmt.Errorf("got client status %v; want %v", last.ClientStatus, structs.AllocClientStatusDead)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+
+	if time.Since(start) > 8*time.Second {
+		t.Fatalf("took too long to terminate")
+	}
+
+	state := ar.alloc.TaskStates[sibling.Name]
+	if last := state.Events[len(state.Events)-1]; last.Type != structs.TaskLeaderDead {
+		t.Fatalf("bad: %#v", state.Events)
+	}
+}
+
+func TestAllocRunner_MockDriver_Destroy(t *testing.T) {
+	upd, ar := testAllocRunner(false)
+
+	// The task runs until it is killed and takes a while to exit
+	task := ar.alloc.Job.TaskGroups[0].Tasks[0]
+	task.Driver = "mock_driver"
+	task.Config = map[string]interface{}{
+		"run_for":    "10s",
+		"kill_after": "100ms",
+	}
+
+	go ar.Run()
+	start := time.Now()
+
+	// Destroy once the task is running
+	testutil.WaitForResult(func() (bool, error) {
+		if upd.Count == 0 {
+			return false, nil
+		}
+		last := upd.Allocs[upd.Count-1]
+		return last.ClientStatus == structs.AllocClientStatusRunning, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+	ar.Destroy()
+
+	testutil.WaitForResult(func() (bool, error) {
+		last := upd.Allocs[upd.Count-1]
+		if last.ClientStatus != structs.AllocClientStatusDead {
+			return false, fmt.Errorf("got client status %v; want %v", last.ClientStatus, structs.AllocClientStatusDead)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v %#v", err, ar.alloc.TaskStates)
+	})
+
+	if time.Since(start) > 8*time.Second {
+		t.Fatalf("task was not killed")
+	}
+}
@@ -1,11 +1,13 @@
 package allocdir
 
 import (
+	"archive/tar"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -20,6 +22,24 @@ var (
 	// The name of the directory that exists inside each task directory
 	// regardless of driver.
 	TaskLocal = "local"
+
+	// The name of the directory inside each task directory holding the
+	// task's secrets. It is backed by tmpfs where supported so secrets are
+	// never written to disk, and it is not part of the data carried over
+	// to replacing allocations.
+	TaskSecrets = "secrets"
+
+	// The name of the shared directory holding the data that is carried over
+	// to the allocation replacing this one.
+	SharedDataDir = "data"
+)
+
+const (
+	// SecretDirPerm and SecretFilePerm are the permissions of the secrets
+	// directories and of the secrets written into them. Only their owner,
+	// the user tasks run as, can access them.
+	SecretDirPerm  = 0700
+	SecretFilePerm = 0600
 )
 
 type AllocDir struct {
@@ -53,6 +73,14 @@ func (d *AllocDir) Destroy() error {
 		}
 	}
 
+	// Unmount the secrets directories. They are looked up from the task
+	// directories as the alloc dir may have been restored from state.
+	for _, dir := range d.TaskDirs {
+		if err := d.unmountSecretsDir(filepath.Join(dir, TaskSecrets)); err != nil {
+			return fmt.Errorf("Failed to unmount secrets directory: %v", err)
+		}
+	}
+
 	return os.RemoveAll(d.AllocDir)
 }
 
@@ -102,6 +130,20 @@ func (d *AllocDir) Build(tasks []*structs.Task) error {
 			return err
 		}
 
+		// Create the secrets directory, backed by memory where supported.
+		secrets := filepath.Join(taskDir, TaskSecrets)
+		if err := os.Mkdir(secrets, SecretDirPerm); err != nil {
+			return err
+		}
+
+		if err := d.mountSecretsDir(secrets); err != nil {
+			return fmt.Errorf("Failed to mount secrets directory for task %v: %v", t.Name, err)
+		}
+
+		if err := DropSecretPermissions(secrets, SecretDirPerm); err != nil {
+			return err
+		}
+
 		d.TaskDirs[t.Name] = taskDir
 	}
 
@@ -199,6 +241,180 @@ func (d *AllocDir) Embed(task string, entries map[string]string) error {
 	return nil
 }
 
+// dataDirs returns the directories of the ephemeral disk that are carried
+// over to a replacing allocation, relative to the alloc dir: the shared data
+// directory and the local directory of each task. The secrets directories
+// are never included.
+func (d *AllocDir) dataDirs() []string {
+	dirs := []string{filepath.Join(SharedAllocName, SharedDataDir)}
+	for task := range d.TaskDirs {
+		dirs = append(dirs, filepath.Join(task, TaskLocal))
+	}
+	return dirs
+}
+
+// Move moves the data directories of a previous allocation on this host into
+// the built alloc dir, replacing the empty directories. Directories missing
+// from the previous alloc dir or from this one are skipped.
+func (d *AllocDir) Move(prev *AllocDir) error {
+	for _, dir := range d.dataDirs() {
+		src := filepath.Join(prev.AllocDir, dir)
+		dst := filepath.Join(d.AllocDir, dir)
+		if _, err := os.Stat(src); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return fmt.Errorf("Couldn't remove directory %v: %v", dst, err)
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return fmt.Errorf("Couldn't move %v to %v: %v", src, dst, err)
+		}
+	}
+	return nil
+}
+
+// Snapshot writes a tar archive of the data directories of the alloc dir to
+// w. It can be restored into the alloc dir of a replacing allocation on
+// another host with Restore.
+func (d *AllocDir) Snapshot(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	for _, dir := range d.dataDirs() {
+		root := filepath.Join(d.AllocDir, dir)
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) && path == root {
+					return nil
+				}
+				return err
+			}
+
+			link := ""
+			if info.Mode()&os.ModeSymlink != 0 {
+				if link, err = os.Readlink(path); err != nil {
+					return fmt.Errorf("Couldn't resolve symlink %v: %v", path, err)
+				}
+			} else if !info.IsDir() && !info.Mode().IsRegular() {
+				// Skip pipes, sockets and devices
+				return nil
+			}
+
+			hdr, err := tar.FileInfoHeader(info, link)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(d.AllocDir, path)
+			if err != nil {
+				return err
+			}
+			hdr.Name = filepath.ToSlash(rel)
+			if err := tw.WriteHeader(hdr); err != nil {
+				return err
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(tw, f)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to snapshot %v: %v", root, err)
+		}
+	}
+	return tw.Close()
+}
+
+// Restore extracts a tar archive written by Snapshot into the built alloc
+// dir. Entries outside of the data directories of this alloc dir, symlinks
+// pointing outside of them and entries written through symlinks are
+// rejected.
+func (d *AllocDir) Restore(r io.Reader) error {
+	dirs := d.dataDirs()
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to read snapshot: %v", err)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if !inDirs(name, dirs) {
+			return fmt.Errorf("Snapshot entry %q is outside of the data directories", hdr.Name)
+		}
+		if err := checkNoSymlinks(d.AllocDir, name); err != nil {
+			return fmt.Errorf("Snapshot entry %q is invalid: %v", hdr.Name, err)
+		}
+
+		path := filepath.Join(d.AllocDir, name)
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || !inDirs(filepath.Join(filepath.Dir(name), link), dirs) {
+				return fmt.Errorf("Snapshot symlink %q points outside of the data directories", hdr.Name)
+			}
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return fmt.Errorf("Couldn't create symlink: %v", err)
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+			if err != nil {
+				return fmt.Errorf("Couldn't create file %v: %v", path, err)
+			}
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("Couldn't write file %v: %v", path, err)
+			}
+		}
+	}
+}
+
+// inDirs returns whether the relative path is one of the directories or is
+// inside of one of them.
+func inDirs(path string, dirs []string) bool {
+	path = filepath.Clean(path)
+	for _, dir := range dirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNoSymlinks returns an error if the relative path or one of its parents
+// under root is an existing symlink, so that nothing is written through a
+// symlink to outside of root.
+func checkNoSymlinks(root, path string) error {
+	cur := root
+	for _, part := range strings.Split(path, string(filepath.Separator)) {
+		cur = filepath.Join(cur, part)
+		info, err := os.Lstat(cur)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%v is a symlink", cur)
+		}
+	}
+	return nil
+}
+
 // MountSharedDir mounts the shared directory into the specified task's
 // directory. Mount is documented at an OS level in their respective
 // implementation files.
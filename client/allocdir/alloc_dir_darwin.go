@@ -13,3 +13,13 @@ func (d *AllocDir) mountSharedDir(dir string) error {
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return syscall.Unlink(dir)
 }
+
+// The darwin version does nothing currently.
+func (d *AllocDir) mountSecretsDir(dir string) error {
+	return nil
+}
+
+// The darwin version does nothing currently.
+func (d *AllocDir) unmountSecretsDir(dir string) error {
+	return nil
+}
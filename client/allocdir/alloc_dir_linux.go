@@ -18,3 +18,27 @@ func (d *AllocDir) mountSharedDir(taskDir string) error {
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return syscall.Unmount(dir, 0)
 }
+
+// Mounts a tmpfs on the secrets directory so secrets are only held in memory.
+// The directory is left on disk if not running as root.
+func (d *AllocDir) mountSecretsDir(dir string) error {
+	if syscall.Geteuid() != 0 {
+		return nil
+	}
+
+	flags := uintptr(syscall.MS_NOEXEC | syscall.MS_NOSUID | syscall.MS_NODEV)
+	return syscall.Mount("tmpfs", dir, "tmpfs", flags, "size=1m,mode=0700")
+}
+
+func (d *AllocDir) unmountSecretsDir(dir string) error {
+	if syscall.Geteuid() != 0 {
+		return nil
+	}
+
+	// The directory isn't a mount point if the task failed to build
+	err := syscall.Unmount(dir, 0)
+	if err == syscall.EINVAL || os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
@@ -21,6 +21,18 @@ func (d *AllocDir) linkOrCopy(src, dst string, perm os.FileMode) error {
 }
 
 func (d *AllocDir) dropDirPermissions(path string) error {
+	return dropPermissions(path, 0777)
+}
+
+// DropSecretPermissions gives a secret, or a directory holding secrets, to
+// the user tasks run as so that only that user can access it.
+func DropSecretPermissions(path string, perm os.FileMode) error {
+	return dropPermissions(path, perm)
+}
+
+// dropPermissions gives the path to the nobody user with the given
+// permissions.
+func dropPermissions(path string, perm os.FileMode) error {
 	// Can't do anything if not root.
 	if syscall.Geteuid() != 0 {
 		return nil
@@ -45,7 +57,7 @@ func (d *AllocDir) dropDirPermissions(path string) error {
 		return fmt.Errorf("Couldn't change owner/group of %v to (uid: %v, gid: %v): %v", path, uid, gid, err)
 	}
 
-	if err := os.Chmod(path, 0777); err != nil {
+	if err := os.Chmod(path, perm); err != nil {
 		return fmt.Errorf("Chmod(%v) failed: %v", path, err)
 	}
 
@@ -1,10 +1,13 @@
 package allocdir
 
 import (
+	"archive/tar"
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/nomad/client/testutil"
@@ -47,6 +50,7 @@ func TestAllocDir_BuildAlloc(t *testing.T) {
 	defer os.RemoveAll(tmp)
 
 	d := NewAllocDir(tmp)
+	defer d.Destroy()
 	tasks := []*structs.Task{t1, t2}
 	if err := d.Build(tasks); err != nil {
 		t.Fatalf("Build(%v) failed: %v", tasks, err)
@@ -66,6 +70,10 @@ func TestAllocDir_BuildAlloc(t *testing.T) {
 		if _, err := os.Stat(tDir); os.IsNotExist(err) {
 			t.Fatalf("Build(%v) didn't create TaskDir %v", tasks, tDir)
 		}
+
+		if _, err := os.Stat(filepath.Join(tDir, TaskSecrets)); os.IsNotExist(err) {
+			t.Fatalf("Build(%v) didn't create secrets dir in %v", tasks, tDir)
+		}
 	}
 }
 
@@ -192,3 +200,197 @@ func TestAllocDir_MountSharedAlloc(t *testing.T) {
 		}
 	}
 }
+
+// buildWithData builds an alloc dir for the tasks and writes a file to its
+// shared data directory and to the local directory of each task.
+func buildWithData(t *testing.T, tasks []*structs.Task) *AllocDir {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+
+	d := NewAllocDir(tmp)
+	if err := d.Build(tasks); err != nil {
+		t.Fatalf("Build(%v) failed: %v", tasks, err)
+	}
+
+	data := filepath.Join(d.SharedDir, SharedDataDir, "db")
+	if err := ioutil.WriteFile(data, []byte("data"), 0666); err != nil {
+		t.Fatalf("Couldn't write file: %v", err)
+	}
+	for task, dir := range d.TaskDirs {
+		local := filepath.Join(dir, TaskLocal, "cache")
+		if err := ioutil.WriteFile(local, []byte(task), 0666); err != nil {
+			t.Fatalf("Couldn't write file: %v", err)
+		}
+	}
+	return d
+}
+
+// checkData checks that the alloc dir holds the data written by
+// buildWithData.
+func checkData(t *testing.T, d *AllocDir) {
+	data, err := ioutil.ReadFile(filepath.Join(d.SharedDir, SharedDataDir, "db"))
+	if err != nil {
+		t.Fatalf("Couldn't read shared data: %v", err)
+	}
+	if string(data) != "data" {
+		t.Fatalf("bad shared data: %q", data)
+	}
+
+	for task, dir := range d.TaskDirs {
+		data, err := ioutil.ReadFile(filepath.Join(dir, TaskLocal, "cache"))
+		if err != nil {
+			t.Fatalf("Couldn't read local data of %v: %v", task, err)
+		}
+		if string(data) != task {
+			t.Fatalf("bad local data of %v: %q", task, data)
+		}
+	}
+}
+
+func TestAllocDir_Move(t *testing.T) {
+	tasks := []*structs.Task{t1, t2}
+	prev := buildWithData(t, tasks)
+	defer os.RemoveAll(prev.AllocDir)
+
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	if err := d.Build(tasks); err != nil {
+		t.Fatalf("Build(%v) failed: %v", tasks, err)
+	}
+	if err := d.Move(prev); err != nil {
+		t.Fatalf("Move() failed: %v", err)
+	}
+	checkData(t, d)
+}
+
+func TestAllocDir_SnapshotRestore(t *testing.T) {
+	tasks := []*structs.Task{t1, t2}
+	prev := buildWithData(t, tasks)
+	defer os.RemoveAll(prev.AllocDir)
+
+	var buf bytes.Buffer
+	if err := prev.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	if err := d.Build(tasks); err != nil {
+		t.Fatalf("Build(%v) failed: %v", tasks, err)
+	}
+	if err := d.Restore(&buf); err != nil {
+		t.Fatalf("Restore() failed: %v", err)
+	}
+	checkData(t, d)
+}
+
+func TestAllocDir_Snapshot_Secrets(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	defer d.Destroy()
+
+	secret := filepath.Join(d.TaskDirs[t1.Name], TaskSecrets, "db.pass")
+	if err := ioutil.WriteFile(secret, []byte("hunter2"), 0644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Secrets are never part of the snapshot
+	var buf bytes.Buffer
+	if err := d.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot() failed: %v", err)
+	}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if strings.Contains(hdr.Name, TaskSecrets) {
+			t.Fatalf("snapshot contains secret %q", hdr.Name)
+		}
+	}
+}
+
+func TestAllocDir_Restore_Escape(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape", Mode: 0666, Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	tw.Close()
+
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	if err := d.Restore(&buf); err == nil {
+		t.Fatalf("Restore() should reject entries outside of the data directories")
+	}
+}
+
+func TestAllocDir_Restore_Symlinks(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "AllocDir")
+	if err != nil {
+		t.Fatalf("Couldn't create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	d := NewAllocDir(tmp)
+	if err := d.Build([]*structs.Task{t1}); err != nil {
+		t.Fatalf("Build() failed: %v", err)
+	}
+	local := filepath.Join(t1.Name, TaskLocal)
+
+	cases := [][]*tar.Header{
+		// Symlinks pointing outside of the data directories
+		{{Name: local + "/abs", Linkname: "/etc/passwd", Typeflag: tar.TypeSymlink}},
+		{{Name: local + "/rel", Linkname: "../../../etc", Typeflag: tar.TypeSymlink}},
+
+		// Entries written through a symlink
+		{
+			{Name: local + "/link", Linkname: ".", Typeflag: tar.TypeSymlink},
+			{Name: local + "/link/file", Mode: 0666, Typeflag: tar.TypeReg},
+		},
+	}
+	for i, hdrs := range cases {
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, hdr := range hdrs {
+			if err := tw.WriteHeader(hdr); err != nil {
+				t.Fatalf("err: %v", err)
+			}
+		}
+		tw.Close()
+
+		if err := d.Restore(&buf); err == nil {
+			t.Fatalf("case %d: Restore() should reject the snapshot", i)
+		}
+		os.RemoveAll(filepath.Join(d.AllocDir, local, "link"))
+	}
+}
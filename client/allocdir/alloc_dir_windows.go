@@ -19,7 +19,22 @@ func (d *AllocDir) dropDirPermissions(path string) error {
 	return nil
 }
 
+// The windows version does nothing currently.
+func DropSecretPermissions(path string, perm os.FileMode) error {
+	return nil
+}
+
 // The windows version does nothing currently.
 func (d *AllocDir) unmountSharedDir(dir string) error {
 	return nil
 }
+
+// The windows version does nothing currently.
+func (d *AllocDir) mountSecretsDir(dir string) error {
+	return nil
+}
+
+// The windows version does nothing currently.
+func (d *AllocDir) unmountSecretsDir(dir string) error {
+	return nil
+}
@@ -2,21 +2,28 @@ package client
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/go-multierror"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
 	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/client/stats"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 )
 
 const (
@@ -51,13 +58,23 @@ const (
 	// starting and the intial heartbeat. After the intial heartbeat,
 	// we switch to using the TTL specified by the servers.
 	initialHeartbeatStagger = 10 * time.Second
+
+	// secretsRenewRetry is the interval at which failed renewals of the
+	// token of the secrets provider are retried
+	secretsRenewRetry = 30 * time.Second
 )
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *config.Config {
 	return &config.Config{
-		LogOutput: os.Stderr,
-		Region:    "global",
+		LogOutput:               os.Stderr,
+		Region:                  "global",
+		StatsCollectionInterval: 1 * time.Second,
+		GCInterval:              1 * time.Minute,
+		GCDiskUsageThreshold:    80,
+		GCInodeUsageThreshold:   70,
+		GCMaxAllocs:             50,
+		SecretsRefreshInterval:  5 * time.Minute,
 	}
 }
 
@@ -81,6 +98,11 @@ type Client struct {
 
 	connPool *nomad.ConnPool
 
+	// httpClient and httpScheme are used to reach the HTTP API of other
+	// clients
+	httpClient *http.Client
+	httpScheme string
+
 	lastHeartbeat time.Time
 	heartbeatTTL  time.Duration
 
@@ -88,6 +110,20 @@ type Client struct {
 	allocs    map[string]*AllocRunner
 	allocLock sync.RWMutex
 
+	// hostStatsCollector samples the resource usage of the host and
+	// hostStats is its latest sample
+	hostStatsCollector *stats.HostStatsCollector
+	hostStats          *stats.HostStats
+	hostStatsLock      sync.RWMutex
+
+	// garbageCollector destroys terminal allocations to free up disk space
+	garbageCollector *AllocGarbageCollector
+
+	// migrating counts the migrations of the data of each allocation that
+	// are in progress. These allocations aren't garbage collected.
+	migrating     map[string]int
+	migratingLock sync.Mutex
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -98,14 +134,45 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	// Create a logger
 	logger := log.New(cfg.LogOutput, "", log.LstdFlags)
 
+	// Configure TLS for the RPCs to the servers
+	var tlsWrap tlsutil.RegionWrapper
+	if cfg.TLSConfig != nil {
+		var err error
+		tlsWrap, err = cfg.TLSConfig.OutgoingTLSWrapper()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure TLS: %v", err)
+		}
+	}
+
+	// Configure TLS for the HTTP API of other clients
+	httpClient, httpScheme := http.DefaultClient, "http"
+	if cfg.HTTPTLSConfig != nil {
+		tlsConf, err := cfg.HTTPTLSConfig.OutgoingTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure HTTPS: %v", err)
+		}
+
+		// Other clients are dialed by their advertised address, so verify
+		// their certificates against the name clients of the region have.
+		if tlsConf != nil && cfg.HTTPTLSConfig.VerifyServerHostname {
+			tlsConf.ServerName = tlsutil.ClientName(cfg.Region)
+		}
+		httpClient = &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+		httpScheme = "https"
+	}
+
 	// Create the client
 	c := &Client{
-		config:     cfg,
-		start:      time.Now(),
-		connPool:   nomad.NewPool(cfg.LogOutput, clientRPCCache, clientMaxStreams, nil),
-		logger:     logger,
-		allocs:     make(map[string]*AllocRunner),
-		shutdownCh: make(chan struct{}),
+		config:             cfg,
+		start:              time.Now(),
+		connPool:           nomad.NewPool(cfg.LogOutput, clientRPCCache, clientMaxStreams, tlsWrap),
+		httpClient:         httpClient,
+		httpScheme:         httpScheme,
+		logger:             logger,
+		allocs:             make(map[string]*AllocRunner),
+		migrating:          make(map[string]int),
+		hostStatsCollector: stats.NewHostStatsCollector(),
+		shutdownCh:         make(chan struct{}),
 	}
 
 	// Setup the Consul Service
@@ -118,6 +185,15 @@ func NewClient(cfg *config.Config) (*Client, error) {
 		return nil, fmt.Errorf("failed intializing client: %v", err)
 	}
 
+	// Setup the garbage collector of terminal allocations
+	gcConfig := &GCConfig{
+		Interval:            cfg.GCInterval,
+		DiskUsageThreshold:  cfg.GCDiskUsageThreshold,
+		InodeUsageThreshold: cfg.GCInodeUsageThreshold,
+		MaxAllocs:           cfg.GCMaxAllocs,
+	}
+	c.garbageCollector = NewAllocGarbageCollector(logger, gcConfig, cfg.AllocDir, c.allocCollected)
+
 	// Setup the node
 	if err := c.setupNode(); err != nil {
 		return nil, fmt.Errorf("node setup failed: %v", err)
@@ -144,6 +220,17 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	// Start the client!
 	go c.run()
 
+	// Start collecting the resource usage of the host
+	go c.collectHostStats()
+
+	// Start garbage collecting terminal allocations
+	go c.garbageCollector.Run()
+
+	// Keep the token of the secrets provider alive
+	if c.config.SecretsProvider != nil {
+		go c.renewSecretsToken()
+	}
+
 	// Start the consul service
 	go c.consulService.SyncWithConsul()
 	return c, nil
@@ -237,6 +324,8 @@ func (c *Client) Shutdown() error {
 	// Stop the consul service
 	c.consulService.ShutDown()
 
+	c.garbageCollector.Stop()
+
 	c.shutdown = true
 	close(c.shutdownCh)
 	c.connPool.Shutdown()
@@ -353,6 +442,137 @@ func (c *Client) Node() *structs.Node {
 	return c.config.Node
 }
 
+// Exec runs a command inside a task of an allocation running on this client.
+func (c *Client) Exec(allocID, task string, req *cstructs.ExecRequest) (int, error) {
+	c.allocLock.RLock()
+	ar, ok := c.allocs[allocID]
+	c.allocLock.RUnlock()
+	if !ok {
+		return -1, fmt.Errorf("unknown allocation ID '%s'", allocID)
+	}
+	return ar.Exec(task, req)
+}
+
+// AllocStats returns the latest resource usage of an allocation running on
+// this client. If task is set only the usage of that task is returned.
+func (c *Client) AllocStats(allocID, task string) (*cstructs.AllocResourceUsage, error) {
+	c.allocLock.RLock()
+	ar, ok := c.allocs[allocID]
+	c.allocLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown allocation ID '%s'", allocID)
+	}
+	return ar.LatestAllocStats(task)
+}
+
+// SnapshotAlloc writes a tar archive of the ephemeral disk of an allocation
+// on this client to w, so that it can be migrated to the allocation replacing
+// it on another client.
+func (c *Client) SnapshotAlloc(allocID string, w io.Writer) error {
+	c.allocLock.RLock()
+	ar, ok := c.allocs[allocID]
+	c.allocLock.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown allocation ID '%s'", allocID)
+	}
+
+	release := c.holdForMigration(allocID)
+	defer release()
+	return ar.Snapshot(w)
+}
+
+// CollectAllAllocs destroys the allocation directories of all the terminal
+// allocations on this client.
+func (c *Client) CollectAllAllocs() {
+	c.garbageCollector.CollectAll()
+}
+
+// LatestHostStats returns the latest resource usage sample of the host, or
+// nil if none has been collected yet.
+func (c *Client) LatestHostStats() *stats.HostStats {
+	c.hostStatsLock.RLock()
+	defer c.hostStatsLock.RUnlock()
+	return c.hostStats
+}
+
+// collectHostStats periodically samples the resource usage of the host and
+// emits it as metrics until the client is shutdown.
+func (c *Client) collectHostStats() {
+	interval := c.config.StatsCollectionInterval
+	if interval == 0 {
+		interval = 1 * time.Second
+	}
+	next := time.NewTimer(0)
+	defer next.Stop()
+	for {
+		select {
+		case <-next.C:
+			hs, err := c.hostStatsCollector.Collect()
+			if err != nil {
+				c.logger.Printf("[DEBUG] client: error fetching host resource usage stats: %v", err)
+			} else {
+				c.hostStatsLock.Lock()
+				c.hostStats = hs
+				c.hostStatsLock.Unlock()
+				c.emitHostStats(hs)
+			}
+			next.Reset(interval)
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
+// renewSecretsToken renews the token of the secrets provider halfway through
+// its lifetime until the client shuts down. Failed renewals are retried.
+func (c *Client) renewSecretsToken() {
+	next := time.NewTimer(0)
+	defer next.Stop()
+	for {
+		select {
+		case <-next.C:
+			ttl, err := c.config.SecretsProvider.RenewToken()
+			if err != nil {
+				c.logger.Printf("[ERR] client: failed to renew secrets token: %v", err)
+				next.Reset(secretsRenewRetry)
+				continue
+			}
+
+			// Tokens without a TTL don't need renewing
+			if ttl == 0 {
+				c.logger.Printf("[DEBUG] client: secrets token does not expire")
+				return
+			}
+			next.Reset(ttl / 2)
+		case <-c.shutdownCh:
+			return
+		}
+	}
+}
+
+// emitHostStats emits the resource usage of the host as metrics.
+func (c *Client) emitHostStats(hs *stats.HostStats) {
+	if hs.Memory != nil {
+		metrics.SetGauge([]string{"client", "host", "memory", "total"}, float32(hs.Memory.Total))
+		metrics.SetGauge([]string{"client", "host", "memory", "available"}, float32(hs.Memory.Available))
+		metrics.SetGauge([]string{"client", "host", "memory", "used"}, float32(hs.Memory.Used))
+		metrics.SetGauge([]string{"client", "host", "memory", "free"}, float32(hs.Memory.Free))
+	}
+	for _, cpu := range hs.CPU {
+		metrics.SetGauge([]string{"client", "host", "cpu", cpu.CPU, "total"}, float32(cpu.Total))
+		metrics.SetGauge([]string{"client", "host", "cpu", cpu.CPU, "user"}, float32(cpu.User))
+		metrics.SetGauge([]string{"client", "host", "cpu", cpu.CPU, "system"}, float32(cpu.System))
+		metrics.SetGauge([]string{"client", "host", "cpu", cpu.CPU, "idle"}, float32(cpu.Idle))
+	}
+	for _, disk := range hs.DiskStats {
+		metrics.SetGauge([]string{"client", "host", "disk", disk.Device, "size"}, float32(disk.Size))
+		metrics.SetGauge([]string{"client", "host", "disk", disk.Device, "used"}, float32(disk.Used))
+		metrics.SetGauge([]string{"client", "host", "disk", disk.Device, "available"}, float32(disk.Available))
+		metrics.SetGauge([]string{"client", "host", "disk", disk.Device, "used_percent"}, float32(disk.UsedPercent))
+		metrics.SetGauge([]string{"client", "host", "disk", disk.Device, "inodes_percent"}, float32(disk.InodesUsedPercent))
+	}
+}
+
 // restoreState is used to restore our state from the data dir
 func (c *Client) restoreState() error {
 	if c.config.DevMode {
@@ -379,6 +599,7 @@ func (c *Client) restoreState() error {
 			mErr.Errors = append(mErr.Errors, err)
 		} else {
 			go ar.Run()
+			c.markForCollection(ar)
 		}
 	}
 	return mErr.ErrorOrNil()
@@ -555,6 +776,33 @@ func (c *Client) setupDrivers() error {
 		}
 	}
 
+	// Fingerprint the drivers provided by plugins. A broken plugin doesn't
+	// prevent the client from starting.
+	plugins, err := driver.DiscoverPlugins(c.config.PluginDir)
+	if err != nil {
+		return err
+	}
+	for name, path := range plugins {
+		if _, ok := driver.BuiltinDrivers[name]; ok {
+			c.logger.Printf("[WARN] client: ignoring plugin %q which provides built in driver %q", path, name)
+			continue
+		}
+		if _, ok := whitelist[name]; whitelistEnabled && !ok {
+			skipped = append(skipped, name)
+			continue
+		}
+
+		d := driver.NewPluginDriver(name, path, driverCtx)
+		applies, err := d.Fingerprint(c.config, c.config.Node)
+		if err != nil {
+			c.logger.Printf("[ERR] client: failed to fingerprint driver plugin %q: %v", path, err)
+			continue
+		}
+		if applies {
+			avail = append(avail, name)
+		}
+	}
+
 	c.logger.Printf("[DEBUG] client: available drivers %v", avail)
 
 	if len(skipped) != 0 {
@@ -633,7 +881,7 @@ func (c *Client) registerNode() error {
 	node := c.Node()
 	req := structs.NodeRegisterRequest{
 		Node:         node,
-		WriteRequest: structs.WriteRequest{Region: c.config.Region},
+		WriteRequest: structs.WriteRequest{Region: c.config.Region, AuthToken: c.config.ACLToken},
 	}
 	var resp structs.NodeUpdateResponse
 	err := c.RPC("Node.Register", &req, &resp)
@@ -658,7 +906,7 @@ func (c *Client) updateNodeStatus() error {
 	req := structs.NodeUpdateStatusRequest{
 		NodeID:       node.ID,
 		Status:       structs.NodeStatusReady,
-		WriteRequest: structs.WriteRequest{Region: c.config.Region},
+		WriteRequest: structs.WriteRequest{Region: c.config.Region, AuthToken: c.config.ACLToken},
 	}
 	var resp structs.NodeUpdateResponse
 	err := c.RPC("Node.UpdateStatus", &req, &resp)
@@ -679,9 +927,17 @@ func (c *Client) updateNodeStatus() error {
 
 // updateAllocStatus is used to update the status of an allocation
 func (c *Client) updateAllocStatus(alloc *structs.Allocation) error {
+	// Terminal allocations can be garbage collected
+	c.allocLock.RLock()
+	ar, ok := c.allocs[alloc.ID]
+	c.allocLock.RUnlock()
+	if ok {
+		c.markForCollection(ar)
+	}
+
 	args := structs.AllocUpdateRequest{
 		Alloc:        []*structs.Allocation{alloc},
-		WriteRequest: structs.WriteRequest{Region: c.config.Region},
+		WriteRequest: structs.WriteRequest{Region: c.config.Region, AuthToken: c.config.ACLToken},
 	}
 	var resp structs.GenericResponse
 	err := c.RPC("Node.UpdateAlloc", &args, &resp)
@@ -692,6 +948,55 @@ func (c *Client) updateAllocStatus(alloc *structs.Allocation) error {
 	return nil
 }
 
+// markForCollection hands the runner to the garbage collector once the tasks
+// of its allocation are no longer running and its data isn't being migrated.
+func (c *Client) markForCollection(ar *AllocRunner) {
+	c.migratingLock.Lock()
+	_, migrating := c.migrating[ar.Alloc().ID]
+	c.migratingLock.Unlock()
+	if migrating {
+		return
+	}
+
+	switch ar.Alloc().ClientStatus {
+	case structs.AllocClientStatusDead, structs.AllocClientStatusFailed:
+		c.garbageCollector.MarkForCollection(ar)
+	}
+}
+
+// holdForMigration keeps the allocation from being garbage collected while
+// its data is migrated. The returned function must be invoked once the
+// migration is done to hand the allocation back to the garbage collector.
+func (c *Client) holdForMigration(allocID string) func() {
+	c.migratingLock.Lock()
+	c.migrating[allocID]++
+	c.migratingLock.Unlock()
+	c.garbageCollector.Remove(allocID)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.migratingLock.Lock()
+			c.migrating[allocID]--
+			done := c.migrating[allocID] == 0
+			if done {
+				delete(c.migrating, allocID)
+			}
+			c.migratingLock.Unlock()
+			if !done {
+				return
+			}
+
+			c.allocLock.RLock()
+			ar, ok := c.allocs[allocID]
+			c.allocLock.RUnlock()
+			if ok {
+				c.markForCollection(ar)
+			}
+		})
+	}
+}
+
 // watchAllocations is used to scan for updates to allocations
 func (c *Client) watchAllocations(allocUpdates chan []*structs.Allocation) {
 	req := structs.NodeSpecificRequest{
@@ -699,6 +1004,7 @@ func (c *Client) watchAllocations(allocUpdates chan []*structs.Allocation) {
 		QueryOptions: structs.QueryOptions{
 			Region:     c.config.Region,
 			AllowStale: true,
+			AuthToken:  c.config.ACLToken,
 		},
 	}
 	var resp structs.NodeAllocsResponse
@@ -771,8 +1077,13 @@ func (c *Client) runAllocs(updated []*structs.Allocation) {
 		}
 	}
 
-	// Start the new allocations
+	// Start the new allocations. Allocations that already completed on this
+	// client were garbage collected and are not run again.
 	for _, add := range diff.added {
+		switch add.ClientStatus {
+		case structs.AllocClientStatusDead, structs.AllocClientStatusFailed:
+			continue
+		}
 		if err := c.addAlloc(add); err != nil {
 			c.logger.Printf("[ERR] client: failed to add alloc '%s': %v",
 				add.ID, err)
@@ -796,9 +1107,21 @@ func (c *Client) removeAlloc(alloc *structs.Allocation) error {
 	}
 	ar.Destroy()
 	delete(c.allocs, alloc.ID)
+	c.garbageCollector.Remove(alloc.ID)
 	return nil
 }
 
+// allocCollected is invoked once the garbage collector destroyed the runner
+// of a terminal allocation, which is no longer tracked.
+func (c *Client) allocCollected(ar *AllocRunner) {
+	c.allocLock.Lock()
+	defer c.allocLock.Unlock()
+	id := ar.Alloc().ID
+	if c.allocs[id] == ar {
+		delete(c.allocs, id)
+	}
+}
+
 // updateAlloc is invoked when we should update an allocation
 func (c *Client) updateAlloc(exist, update *structs.Allocation) error {
 	c.allocLock.RLock()
@@ -817,7 +1140,39 @@ func (c *Client) addAlloc(alloc *structs.Allocation) error {
 	c.allocLock.Lock()
 	defer c.allocLock.Unlock()
 	ar := NewAllocRunner(c.logger, c.config, c.updateAllocStatus, alloc, c.consulService)
+	if prev := c.previousAllocDir(alloc); prev != nil {
+		ar.SetPreviousAlloc(prev)
+	}
 	c.allocs[alloc.ID] = ar
 	go ar.Run()
+	c.markForCollection(ar)
+	return nil
+}
+
+// previousAllocDir returns how the ephemeral disk of the allocation replaced
+// by alloc is carried over, or nil if it is not. The data of a previous
+// allocation on this client is moved when the disk is sticky or migrated,
+// while the data of one on another client is only copied when it is
+// migrated. The alloc lock must be held.
+func (c *Client) previousAllocDir(alloc *structs.Allocation) prevAllocDir {
+	if alloc.PreviousAllocation == "" || alloc.Job == nil || alloc.TerminalStatus() {
+		return nil
+	}
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	if tg == nil || !tg.EphemeralDisk.KeepsData() {
+		return nil
+	}
+
+	if prev, ok := c.allocs[alloc.PreviousAllocation]; ok {
+		return &localPrevAlloc{ar: prev, release: c.holdForMigration(prev.Alloc().ID)}
+	}
+	if tg.EphemeralDisk.Migrate {
+		return &remotePrevAlloc{
+			client:    c,
+			allocID:   alloc.PreviousAllocation,
+			namespace: alloc.Namespace,
+			logger:    c.logger,
+		}
+	}
 	return nil
 }
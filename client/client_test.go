@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
@@ -107,6 +109,64 @@ func TestClient_RPC(t *testing.T) {
 	})
 }
 
+func TestClient_RPC_TLS(t *testing.T) {
+	const (
+		caFile     = "../helper/tlsutil/test-resources/ca.pem"
+		serverCert = "../helper/tlsutil/test-resources/server.pem"
+		serverKey  = "../helper/tlsutil/test-resources/server-key.pem"
+		clientCert = "../helper/tlsutil/test-resources/client.pem"
+		clientKey  = "../helper/tlsutil/test-resources/client-key.pem"
+	)
+	s1, addr := testServer(t, func(c *nomad.Config) {
+		c.RequireTLS = true
+		c.TLSConfig = tlsutil.NewConfig(caFile, serverCert, serverKey)
+		c.TLSConfig.VerifyIncoming = true
+	})
+	defer s1.Shutdown()
+
+	c1 := testClient(t, func(c *config.Config) {
+		c.Servers = []string{addr}
+		c.TLSConfig = tlsutil.NewConfig(caFile, clientCert, clientKey)
+		c.TLSConfig.VerifyOutgoing = true
+		c.TLSConfig.VerifyServerHostname = true
+	})
+	defer c1.Shutdown()
+
+	// RPC should succeed
+	testutil.WaitForResult(func() (bool, error) {
+		var out struct{}
+		err := c1.RPC("Status.Ping", struct{}{}, &out)
+		return err == nil, err
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestClient_HTTPTLS_ClientName(t *testing.T) {
+	c1 := testClient(t, func(c *config.Config) {
+		c.HTTPTLSConfig = tlsutil.NewConfig("../helper/tlsutil/test-resources/ca.pem",
+			"../helper/tlsutil/test-resources/client.pem",
+			"../helper/tlsutil/test-resources/client-key.pem")
+		c.HTTPTLSConfig.VerifyOutgoing = true
+		c.HTTPTLSConfig.VerifyServerHostname = true
+	})
+	defer c1.Shutdown()
+
+	if c1.httpScheme != "https" {
+		t.Fatalf("bad: %#v", c1.httpScheme)
+	}
+
+	// Other clients are verified against the client name of the region
+	// rather than the address they are dialed by
+	transport, ok := c1.httpClient.Transport.(*http.Transport)
+	if !ok || transport.TLSClientConfig == nil {
+		t.Fatalf("bad: %#v", c1.httpClient.Transport)
+	}
+	if name := transport.TLSClientConfig.ServerName; name != "client.global.nomad" {
+		t.Fatalf("bad: %#v", name)
+	}
+}
+
 func TestClient_RPC_Passthrough(t *testing.T) {
 	s1, _ := testServer(t, nil)
 	defer s1.Shutdown()
@@ -373,6 +433,84 @@ func TestClient_WatchAllocs(t *testing.T) {
 	})
 }
 
+func TestClient_AllocCollected(t *testing.T) {
+	c1 := testClient(t, nil)
+	defer c1.Shutdown()
+
+	// Allocations that completed on the client are not started again
+	alloc := mock.Alloc()
+	alloc.NodeID = c1.Node().ID
+	alloc.ClientStatus = structs.AllocClientStatusDead
+	c1.runAllocs([]*structs.Allocation{alloc})
+	c1.allocLock.RLock()
+	num := len(c1.allocs)
+	c1.allocLock.RUnlock()
+	if num != 0 {
+		t.Fatalf("expected no allocs, got: %d", num)
+	}
+
+	// Collected runners are no longer tracked
+	_, ar := testAllocRunner(false)
+	c1.allocLock.Lock()
+	c1.allocs[ar.Alloc().ID] = ar
+	c1.allocLock.Unlock()
+	c1.allocCollected(ar)
+	c1.allocLock.RLock()
+	_, ok := c1.allocs[ar.Alloc().ID]
+	c1.allocLock.RUnlock()
+	if ok {
+		t.Fatalf("alloc '%s' should have been removed", ar.Alloc().ID)
+	}
+}
+
+func TestClient_PreviousAlloc_NotCollected(t *testing.T) {
+	c1 := testClient(t, nil)
+	defer c1.Shutdown()
+
+	// A terminal allocation on the client is marked for collection
+	_, prev := testAllocRunner(false)
+	prev.alloc.ClientStatus = structs.AllocClientStatusDead
+	c1.allocLock.Lock()
+	c1.allocs[prev.Alloc().ID] = prev
+	c1.allocLock.Unlock()
+	c1.markForCollection(prev)
+
+	marked := func() bool {
+		c1.garbageCollector.lock.Lock()
+		defer c1.garbageCollector.lock.Unlock()
+		_, ok := c1.garbageCollector.index[prev.Alloc().ID]
+		return ok
+	}
+	if !marked() {
+		t.Fatalf("alloc '%s' should be marked for collection", prev.Alloc().ID)
+	}
+
+	// The allocation replacing it keeps it from being collected
+	alloc := mock.Alloc()
+	alloc.PreviousAllocation = prev.Alloc().ID
+	alloc.Job.LookupTaskGroup(alloc.TaskGroup).EphemeralDisk.Sticky = true
+	c1.allocLock.Lock()
+	dir := c1.previousAllocDir(alloc)
+	c1.allocLock.Unlock()
+	local, ok := dir.(*localPrevAlloc)
+	if !ok {
+		t.Fatalf("bad: %#v", dir)
+	}
+	if marked() {
+		t.Fatalf("alloc '%s' should not be collected while migrating", prev.Alloc().ID)
+	}
+	c1.markForCollection(prev)
+	if marked() {
+		t.Fatalf("alloc '%s' should not be collected while migrating", prev.Alloc().ID)
+	}
+
+	// It is collected again once its data has been migrated
+	local.release()
+	if !marked() {
+		t.Fatalf("alloc '%s' should be marked for collection", prev.Alloc().ID)
+	}
+}
+
 func TestClient_SaveRestoreState(t *testing.T) {
 	ctestutil.ExecCompatible(t)
 	s1, _ := testServer(t, nil)
@@ -5,7 +5,10 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/nomad/client/secrets"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -28,6 +31,9 @@ type Config struct {
 	// AllocDir is where we store data for allocations
 	AllocDir string
 
+	// PluginDir is the directory in which task driver plugins are discovered
+	PluginDir string
+
 	// LogOutput is the destination for logs
 	LogOutput io.Writer
 
@@ -56,6 +62,50 @@ type Config struct {
 	//
 	//	namespace.option = value
 	Options map[string]string
+
+	// StatsCollectionInterval is the interval at which the resource usage of
+	// the host and of the running tasks is sampled.
+	StatsCollectionInterval time.Duration
+
+	// GCInterval is the interval at which the disk usage of the alloc dir is
+	// checked to garbage collect terminal allocations.
+	GCInterval time.Duration
+
+	// GCDiskUsageThreshold and GCInodeUsageThreshold are the percentages of
+	// disk space and inodes used on the alloc dir's disk above which terminal
+	// allocations are garbage collected.
+	GCDiskUsageThreshold  float64
+	GCInodeUsageThreshold float64
+
+	// GCMaxAllocs is the number of terminal allocations retained before the
+	// oldest are garbage collected.
+	GCMaxAllocs int
+
+	// HostVolumes are the directories of the host exposed to tasks as named
+	// volumes, keyed by their name.
+	HostVolumes map[string]*structs.ClientHostVolumeConfig
+
+	// ACLToken is the secret ID of the ACL token the client authenticates its
+	// requests to the servers with when ACLs are enabled. It needs node write
+	// and job read permissions.
+	ACLToken string
+
+	// TLSConfig is used to dial the servers with TLS. TLS is disabled if
+	// nil.
+	TLSConfig *tlsutil.Config
+
+	// HTTPTLSConfig is used to reach the HTTP API of other clients over
+	// HTTPS, for example to download the data of previous allocations. It
+	// is nil if agents serve their HTTP API without TLS.
+	HTTPTLSConfig *tlsutil.Config
+
+	// SecretsProvider reads the secrets injected into tasks. Tasks with
+	// secrets fail to start if it is nil.
+	SecretsProvider secrets.Provider
+
+	// SecretsRefreshInterval is the interval at which the secrets of
+	// running tasks are read again to detect rotations.
+	SecretsRefreshInterval time.Duration
 }
 
 // Read returns the specified configuration value or "".
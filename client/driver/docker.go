@@ -5,18 +5,23 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	docker "github.com/fsouza/go-dockerclient"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver/environment"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/helper/args"
+	"github.com/hashicorp/nomad/client/stats"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -51,6 +56,88 @@ type DockerDriverConfig struct {
 	LabelsRaw        []map[string]string `mapstructure:"labels"`             //
 	Labels           map[string]string   `mapstructure:"-"`                  // Labels to set when the container starts up
 	Auth             []DockerDriverAuth  `mapstructure:"auth"`               // Authentication credentials for a private Docker registry
+	Volumes          []string            `mapstructure:"volumes"`            // Host paths mounted into the container as "host:container[:ro|rw]"
+	Mounts           []DockerMount       `mapstructure:"mounts"`             // Bind and tmpfs mounts of the container
+	UlimitRaw        []map[string]string `mapstructure:"ulimit"`             //
+	Ulimits          []docker.ULimit     `mapstructure:"-"`                  // Resource limits of the container as "soft[:hard]" by name
+	CapAdd           []string            `mapstructure:"cap_add"`            // Linux capabilities added to the container
+	CapDrop          []string            `mapstructure:"cap_drop"`           // Linux capabilities dropped from the container
+	ExtraHosts       []string            `mapstructure:"extra_hosts"`        // Entries added to /etc/hosts as "host:ip"
+	IpcMode          string              `mapstructure:"ipc_mode"`           // The IPC namespace of the container
+	PidMode          string              `mapstructure:"pid_mode"`           // The PID namespace of the container
+	UsernsMode       string              `mapstructure:"userns_mode"`        // The user namespace of the container
+	ShmSize          int64               `mapstructure:"shm_size"`           // Size of /dev/shm in bytes
+	WorkDir          string              `mapstructure:"work_dir"`           // The working directory of the command
+	Entrypoint       []string            `mapstructure:"entrypoint"`         // Overrides the entrypoint of the image
+	ForcePull        bool                `mapstructure:"force_pull"`         // Pulls the image even if it is present
+	LoadImage        string              `mapstructure:"load"`               // Image tarball in the task directory loaded instead of pulling
+}
+
+const (
+	// DockerMountTypeBind mounts a path of the host into the container.
+	DockerMountTypeBind = "bind"
+
+	// DockerMountTypeTmpfs mounts a tmpfs into the container.
+	DockerMountTypeTmpfs = "tmpfs"
+)
+
+// DockerMount is a mount of the container. Sources of bind mounts that aren't
+// absolute are relative to the task directory.
+type DockerMount struct {
+	Type     string `mapstructure:"type"`
+	Target   string `mapstructure:"target"`
+	Source   string `mapstructure:"source"`
+	ReadOnly bool   `mapstructure:"readonly"`
+
+	// Propagation is the bind propagation of bind mounts, such as "rslave".
+	Propagation string `mapstructure:"propagation"`
+
+	// SizeBytes and Mode are the size and permissions of tmpfs mounts.
+	SizeBytes int64  `mapstructure:"size"`
+	Mode      string `mapstructure:"mode"`
+}
+
+// validDockerPropagations are the bind propagations supported by Docker.
+var validDockerPropagations = map[string]struct{}{
+	"private": struct{}{}, "rprivate": struct{}{},
+	"shared": struct{}{}, "rshared": struct{}{},
+	"slave": struct{}{}, "rslave": struct{}{},
+}
+
+func (m *DockerMount) Validate() error {
+	if !filepath.IsAbs(m.Target) {
+		return fmt.Errorf("mount target %q must be an absolute path", m.Target)
+	}
+
+	switch m.Type {
+	case DockerMountTypeBind:
+		if m.Source == "" {
+			return fmt.Errorf("bind mount of %q needs a source", m.Target)
+		}
+		if m.Propagation != "" {
+			if _, ok := validDockerPropagations[m.Propagation]; !ok {
+				return fmt.Errorf("invalid bind propagation %q", m.Propagation)
+			}
+		}
+		if m.SizeBytes != 0 || m.Mode != "" {
+			return fmt.Errorf("bind mount of %q can't set tmpfs options", m.Target)
+		}
+	case DockerMountTypeTmpfs:
+		if m.Source != "" || m.Propagation != "" {
+			return fmt.Errorf("tmpfs mount of %q can't set a source or bind options", m.Target)
+		}
+		if m.SizeBytes < 0 {
+			return fmt.Errorf("tmpfs mount of %q has a negative size", m.Target)
+		}
+		if m.Mode != "" {
+			if _, err := strconv.ParseUint(m.Mode, 8, 32); err != nil {
+				return fmt.Errorf("tmpfs mount of %q has invalid mode %q", m.Target, m.Mode)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid mount type %q", m.Type)
+	}
+	return nil
 }
 
 func (c *DockerDriverConfig) Validate() error {
@@ -61,23 +148,125 @@ func (c *DockerDriverConfig) Validate() error {
 	c.PortMap = mapMergeStrInt(c.PortMapRaw...)
 	c.Labels = mapMergeStrStr(c.LabelsRaw...)
 
+	for _, v := range c.Volumes {
+		parts := strings.Split(v, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return fmt.Errorf("invalid volume %q; must be \"host:container[:ro|rw]\"", v)
+		}
+		if !filepath.IsAbs(parts[1]) {
+			return fmt.Errorf("container path of volume %q must be absolute", v)
+		}
+		if len(parts) == 3 && parts[2] != "ro" && parts[2] != "rw" {
+			return fmt.Errorf("invalid mode of volume %q; must be \"ro\" or \"rw\"", v)
+		}
+	}
+
+	for i := range c.Mounts {
+		m := &c.Mounts[i]
+		if m.Type == "" {
+			m.Type = DockerMountTypeBind
+		}
+		if err := m.Validate(); err != nil {
+			return err
+		}
+	}
+
+	ulimits, err := parseUlimits(mapMergeStrStr(c.UlimitRaw...))
+	if err != nil {
+		return err
+	}
+	c.Ulimits = ulimits
+
+	for _, h := range c.ExtraHosts {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || net.ParseIP(parts[1]) == nil {
+			return fmt.Errorf("invalid extra host %q; must be \"host:ip\"", h)
+		}
+	}
+
+	switch c.IpcMode {
+	case "", "host", "private", "shareable":
+	default:
+		return fmt.Errorf("invalid ipc_mode %q", c.IpcMode)
+	}
+	if c.PidMode != "" && c.PidMode != "host" {
+		return fmt.Errorf("invalid pid_mode %q", c.PidMode)
+	}
+	if c.UsernsMode != "" && c.UsernsMode != "host" {
+		return fmt.Errorf("invalid userns_mode %q", c.UsernsMode)
+	}
+
+	if c.ShmSize < 0 {
+		return fmt.Errorf("shm_size can't be negative")
+	}
+	if c.WorkDir != "" && !filepath.IsAbs(c.WorkDir) {
+		return fmt.Errorf("work_dir %q must be an absolute path", c.WorkDir)
+	}
+	if c.LoadImage != "" && c.ForcePull {
+		return fmt.Errorf("an image can't be both loaded and force pulled")
+	}
+
 	return nil
 }
 
+// parseUlimits parses resource limits given as "soft[:hard]" by name. The
+// hard limit defaults to the soft limit.
+func parseUlimits(raw map[string]string) ([]docker.ULimit, error) {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ulimits := make([]docker.ULimit, 0, len(names))
+	for _, name := range names {
+		parts := strings.Split(raw[name], ":")
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("invalid ulimit %s %q; must be \"soft[:hard]\"", name, raw[name])
+		}
+		soft, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid soft limit of ulimit %s: %v", name, err)
+		}
+		hard := soft
+		if len(parts) == 2 {
+			if hard, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid hard limit of ulimit %s: %v", name, err)
+			}
+		}
+		if soft > hard {
+			return nil, fmt.Errorf("soft limit of ulimit %s is above its hard limit", name)
+		}
+		ulimits = append(ulimits, docker.ULimit{Name: name, Soft: soft, Hard: hard})
+	}
+	return ulimits, nil
+}
+
 type dockerPID struct {
 	ImageID     string
 	ContainerID string
+
+	// ImageName is the name of the image the task was started from. It is
+	// checked against the images kept by the coordinator.
+	ImageName string `json:",omitempty"`
 }
 
 type DockerHandle struct {
 	client           *docker.Client
+	coordinator      *dockerCoordinator
 	logger           *log.Logger
 	cleanupContainer bool
-	cleanupImage     bool
+	callerID         string
 	imageID          string
+	imageName        string
 	containerID      string
 	waitCh           chan *cstructs.WaitResult
 	doneCh           chan struct{}
+
+	// CPU usage calculators used by Stats.
+	totalCpuStats  *stats.CpuStats
+	userCpuStats   *stats.CpuStats
+	systemCpuStats *stats.CpuStats
 }
 
 func NewDockerDriver(ctx *DriverContext) Driver {
@@ -121,6 +310,50 @@ func (d *DockerDriver) dockerClient() (*docker.Client, error) {
 	return client, err
 }
 
+// dockerCoordinator returns the coordinator of the images used by the Docker
+// tasks of the client. It is configured with the options of the client:
+//
+//   - docker.cleanup.image: whether unused images are removed
+//   - docker.cleanup.image.delay: how long unused images are kept
+//   - docker.cleanup.image.keep: comma separated images that are never removed
+func (d *DockerDriver) dockerCoordinator(client *docker.Client) (*dockerCoordinator, error) {
+	var err error
+	createCoordinator.Do(func() {
+		delay := defaultImageRemoveDelay
+		if raw := d.config.Read("docker.cleanup.image.delay"); raw != "" {
+			delay, err = time.ParseDuration(raw)
+			if err != nil {
+				err = fmt.Errorf("Failed to parse docker.cleanup.image.delay: %v", err)
+				return
+			}
+		}
+
+		keep := make(map[string]struct{})
+		for image := range d.config.ReadStringListToMap("docker.cleanup.image.keep") {
+			keep[image] = struct{}{}
+			keep[normalizeImageName(image)] = struct{}{}
+		}
+
+		globalCoordinator = newDockerCoordinator(&dockerCoordinatorConfig{
+			logger:      d.logger,
+			client:      client,
+			cleanup:     d.config.ReadBoolDefault("docker.cleanup.image", true),
+			removeDelay: delay,
+			keep:        keep,
+		})
+	})
+	if globalCoordinator == nil && err == nil {
+		err = fmt.Errorf("Docker image coordinator failed to initialize")
+	}
+	return globalCoordinator, err
+}
+
+// imageCallerID is the ID the task holds its reference to its image by. It
+// is derived from the task so that it can be restored from the task's handle.
+func (d *DockerDriver) imageCallerID(ctx *ExecContext) string {
+	return fmt.Sprintf("%s-%s", d.taskName, ctx.AllocID)
+}
+
 func (d *DockerDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
 	// Initialize docker API client
 	client, err := d.dockerClient()
@@ -151,19 +384,167 @@ func (d *DockerDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool
 	return true, nil
 }
 
-func (d *DockerDriver) containerBinds(alloc *allocdir.AllocDir, task *structs.Task) ([]string, error) {
-	shared := alloc.SharedDir
-	local, ok := alloc.TaskDirs[task.Name]
+func (d *DockerDriver) containerBinds(ctx *ExecContext, task *structs.Task) ([]string, error) {
+	shared := ctx.AllocDir.SharedDir
+	local, ok := ctx.AllocDir.TaskDirs[task.Name]
 	if !ok {
 		return nil, fmt.Errorf("Failed to find task local directory: %v", task.Name)
 	}
 
-	return []string{
+	binds := []string{
 		// "z" and "Z" option is to allocate directory with SELinux label.
 		fmt.Sprintf("%s:/%s:rw,z", shared, allocdir.SharedAllocName),
 		// capital "Z" will label with Multi-Category Security (MCS) labels
 		fmt.Sprintf("%s:/%s:rw,Z", local, allocdir.TaskLocal),
-	}, nil
+		fmt.Sprintf("%s:/%s:rw,Z", filepath.Join(local, allocdir.TaskSecrets), allocdir.TaskSecrets),
+	}
+
+	// Bind the host volumes mounted by the task. They aren't relabeled as
+	// they are shared with the host.
+	mounts, err := taskBindMounts(d.config, ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mounts {
+		mode := "rw"
+		if m.ReadOnly {
+			mode = "ro"
+		}
+		binds = append(binds, fmt.Sprintf("%s:%s:%s", m.HostPath, m.TaskPath, mode))
+	}
+	return binds, nil
+}
+
+// defaultDockerCapabilities are the capabilities tasks can add when the
+// client doesn't configure docker.caps.whitelist. They are the capabilities
+// Docker grants by default.
+const defaultDockerCapabilities = "CHOWN,DAC_OVERRIDE,FSETID,FOWNER,MKNOD,NET_RAW,SETGID,SETUID,SETFCAP,SETPCAP,NET_BIND_SERVICE,SYS_CHROOT,KILL,AUDIT_WRITE"
+
+// checkCapabilities returns an error if any of the capabilities isn't in the
+// whitelist of the client. A whitelist of "ALL" allows any capability.
+func (d *DockerDriver) checkCapabilities(caps []string) error {
+	if len(caps) == 0 {
+		return nil
+	}
+
+	whitelist := d.config.ReadStringListToMap("docker.caps.whitelist")
+	if len(whitelist) == 0 {
+		for _, c := range strings.Split(defaultDockerCapabilities, ",") {
+			whitelist[c] = struct{}{}
+		}
+	}
+	if _, ok := whitelist["ALL"]; ok {
+		return nil
+	}
+
+	var denied []string
+	for _, c := range caps {
+		name := strings.TrimPrefix(strings.ToUpper(c), "CAP_")
+		if _, ok := whitelist[name]; !ok {
+			denied = append(denied, c)
+		}
+	}
+	if len(denied) != 0 {
+		return fmt.Errorf("Docker capabilities %v are not in the whitelist of this Nomad agent", denied)
+	}
+	return nil
+}
+
+// containerVolumes returns the binds of the task's volumes and bind mounts
+// along with its tmpfs mounts. Host paths that aren't absolute are relative
+// to the task directory, which they can't escape. Absolute host paths must be
+// enabled with docker.volumes.enabled.
+func (d *DockerDriver) containerVolumes(ctx *ExecContext, task *structs.Task, driverConfig *DockerDriverConfig) ([]string, map[string]string, error) {
+	taskDir, ok := ctx.AllocDir.TaskDirs[task.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("Failed to find task local directory: %v", task.Name)
+	}
+	volumesEnabled := d.config.ReadBoolDefault("docker.volumes.enabled", false)
+
+	hostPath := func(path string) (string, error) {
+		if filepath.IsAbs(path) {
+			if !volumesEnabled {
+				return "", fmt.Errorf("Docker volumes with absolute host paths are disabled on this Nomad agent: %s", path)
+			}
+			return path, nil
+		}
+		resolved := filepath.Join(taskDir, path)
+		if resolved != taskDir && !strings.HasPrefix(resolved, taskDir+string(filepath.Separator)) {
+			return "", fmt.Errorf("Docker volume path %q escapes the task directory", path)
+		}
+		return resolved, nil
+	}
+
+	var binds []string
+	for _, v := range driverConfig.Volumes {
+		parts := strings.Split(v, ":")
+		host, err := hostPath(parts[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		parts[0] = host
+		binds = append(binds, strings.Join(parts, ":"))
+	}
+
+	tmpfs := make(map[string]string)
+	for _, m := range driverConfig.Mounts {
+		var opts []string
+		if m.ReadOnly {
+			opts = append(opts, "ro")
+		}
+
+		switch m.Type {
+		case DockerMountTypeBind:
+			host, err := hostPath(m.Source)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !m.ReadOnly {
+				opts = append(opts, "rw")
+			}
+			if m.Propagation != "" {
+				opts = append(opts, m.Propagation)
+			}
+			binds = append(binds, fmt.Sprintf("%s:%s:%s", host, m.Target, strings.Join(opts, ",")))
+		case DockerMountTypeTmpfs:
+			if m.SizeBytes != 0 {
+				opts = append(opts, fmt.Sprintf("size=%d", m.SizeBytes))
+			}
+			if m.Mode != "" {
+				opts = append(opts, "mode="+m.Mode)
+			}
+			tmpfs[m.Target] = strings.Join(opts, ",")
+		}
+	}
+	return binds, tmpfs, nil
+}
+
+// TaskEnvironment returns the environment of the task as seen from inside the
+// container.
+func (d *DockerDriver) TaskEnvironment(ctx *ExecContext, task *structs.Task) (environment.TaskEnvironment, error) {
+	var driverConfig DockerDriverConfig
+	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
+		return nil, err
+	}
+	return d.taskEnvironment(ctx, task, &driverConfig), nil
+}
+
+// taskEnvironment builds the environment of the task with the paths the
+// alloc and task directories are mounted at in the container and the
+// container ports of mapped ports.
+func (d *DockerDriver) taskEnvironment(ctx *ExecContext, task *structs.Task, driverConfig *DockerDriverConfig) environment.TaskEnvironment {
+	env := TaskEnvironmentVariables(ctx, task)
+	env.SetAllocDir(filepath.Join("/", allocdir.SharedAllocName))
+	env.SetTaskLocalDir(filepath.Join("/", allocdir.TaskLocal))
+	env.SetSecretsDir(filepath.Join("/", allocdir.TaskSecrets))
+
+	// TODO add support for more than one network
+	portMap := mapMergeStrInt(driverConfig.PortMapRaw...)
+	if task.Resources != nil && len(task.Resources.Networks) > 0 && len(portMap) > 0 {
+		network := task.Resources.Networks[0]
+		env.SetPorts(network.MapLabelToValues(portMap))
+	}
+	return env
 }
 
 // createContainer initializes a struct needed to call docker.client.CreateContainer()
@@ -176,15 +557,13 @@ func (d *DockerDriver) createContainer(ctx *ExecContext, task *structs.Task, dri
 		return c, fmt.Errorf("task.Resources is empty")
 	}
 
-	binds, err := d.containerBinds(ctx.AllocDir, task)
+	binds, err := d.containerBinds(ctx, task)
 	if err != nil {
 		return c, err
 	}
 
 	// Create environment variables.
-	env := TaskEnvironmentVariables(ctx, task)
-	env.SetAllocDir(filepath.Join("/", allocdir.SharedAllocName))
-	env.SetTaskLocalDir(filepath.Join("/", allocdir.TaskLocal))
+	env := d.taskEnvironment(ctx, task, driverConfig)
 
 	config := &docker.Config{
 		Image:    driverConfig.ImageName,
@@ -238,6 +617,40 @@ func (d *DockerDriver) createContainer(ctx *ExecContext, task *structs.Task, dri
 	}
 	hostConfig.Privileged = hostPrivileged
 
+	// Host namespaces weaken the isolation of the container as much as the
+	// privileged mode does.
+	for name, mode := range map[string]string{"ipc_mode": driverConfig.IpcMode, "pid_mode": driverConfig.PidMode, "userns_mode": driverConfig.UsernsMode} {
+		if mode == "host" && !hostPrivileged {
+			return c, fmt.Errorf("Docker %s host is disabled on this Nomad agent as privileged mode is disabled", name)
+		}
+	}
+	hostConfig.IpcMode = driverConfig.IpcMode
+	hostConfig.PidMode = driverConfig.PidMode
+	hostConfig.UsernsMode = driverConfig.UsernsMode
+
+	// Only capabilities in the whitelist of the client can be added
+	if err := d.checkCapabilities(driverConfig.CapAdd); err != nil {
+		return c, err
+	}
+	hostConfig.CapAdd = driverConfig.CapAdd
+	hostConfig.CapDrop = driverConfig.CapDrop
+
+	// Mount the volumes of the task
+	volumeBinds, tmpfs, err := d.containerVolumes(ctx, task, driverConfig)
+	if err != nil {
+		return c, err
+	}
+	hostConfig.Binds = append(hostConfig.Binds, volumeBinds...)
+	if len(tmpfs) != 0 {
+		hostConfig.Tmpfs = tmpfs
+	}
+
+	hostConfig.Ulimits = driverConfig.Ulimits
+	hostConfig.ExtraHosts = driverConfig.ExtraHosts
+	hostConfig.ShmSize = driverConfig.ShmSize
+	config.WorkingDir = driverConfig.WorkDir
+	config.Entrypoint = driverConfig.Entrypoint
+
 	// set DNS servers
 	for _, ip := range driverConfig.DNSServers {
 		if net.ParseIP(ip) != nil {
@@ -312,28 +725,16 @@ func (d *DockerDriver) createContainer(ctx *ExecContext, task *structs.Task, dri
 			exposedPorts[containerPort+"/udp"] = struct{}{}
 			d.logger.Printf("[DEBUG] driver.docker: exposed port %s", containerPort)
 		}
-
-		// This was set above in a call to TaskEnvironmentVariables but if we
-		// have mapped any ports we will need to override them.
-		//
-		// TODO refactor the implementation in TaskEnvironmentVariables to match
-		// the 0.2 ports world view. Docker seems to be the only place where
-		// this is actually needed, but this is kinda hacky.
-		if len(driverConfig.PortMap) > 0 {
-			env.SetPorts(network.MapLabelToValues(driverConfig.PortMap))
-		}
 		hostConfig.PortBindings = publishedPorts
 		config.ExposedPorts = exposedPorts
 	}
 
-	parsedArgs := args.ParseAndReplace(driverConfig.Args, env.Map())
-
 	// If the user specified a custom command to run as their entrypoint, we'll
 	// inject it here.
 	if driverConfig.Command != "" {
 		cmd := []string{driverConfig.Command}
 		if len(driverConfig.Args) != 0 {
-			cmd = append(cmd, parsedArgs...)
+			cmd = append(cmd, driverConfig.Args...)
 		}
 		d.logger.Printf("[DEBUG] driver.docker: setting container startup command to: %s", strings.Join(cmd, " "))
 		config.Cmd = cmd
@@ -379,13 +780,22 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 	}
 
 	cleanupContainer := d.config.ReadBoolDefault("docker.cleanup.container", true)
-	cleanupImage := d.config.ReadBoolDefault("docker.cleanup.image", true)
 
 	// Initialize docker API client
 	client, err := d.dockerClient()
 	if err != nil {
 		return nil, fmt.Errorf("Failed to connect to docker daemon: %s", err)
 	}
+	coordinator, err := d.dockerCoordinator(client)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reference the image by name before it is inspected or pulled so that a
+	// pending removal doesn't remove it before it is referenced by ID.
+	callerID := d.imageCallerID(ctx)
+	coordinator.IncrementImageNameReference(image, callerID)
+	defer coordinator.RemoveImageNameReference(callerID)
 
 	repo, tag := docker.ParseRepositoryTag(image)
 	// Make sure tag is always explicitly set. We'll default to "latest" if it
@@ -398,10 +808,21 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 	// We're going to check whether the image is already downloaded. If the tag
 	// is "latest" we have to check for a new version every time so we don't
 	// bother to check and cache the id here. We'll download first, then cache.
-	if tag != "latest" {
+	if tag != "latest" && !driverConfig.ForcePull && driverConfig.LoadImage == "" {
 		dockerImage, err = client.InspectImage(image)
 	}
 
+	// Load the image from the task directory rather than pulling it
+	if driverConfig.LoadImage != "" {
+		if err := d.loadImage(client, ctx, task, driverConfig.LoadImage); err != nil {
+			return nil, err
+		}
+		dockerImage, err = client.InspectImage(image)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to find image `%s` in the loaded tarball: %s", image, err)
+		}
+	}
+
 	// Download the image
 	if dockerImage == nil {
 		pullOptions := docker.PullImageOptions{
@@ -409,14 +830,10 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 			Tag:        tag,
 		}
 
-		authOptions := docker.AuthConfiguration{}
-		if len(driverConfig.Auth) != 0 {
-			authOptions = docker.AuthConfiguration{
-				Username:      driverConfig.Auth[0].Username,
-				Password:      driverConfig.Auth[0].Password,
-				Email:         driverConfig.Auth[0].Email,
-				ServerAddress: driverConfig.Auth[0].ServerAddress,
-			}
+		authOptions, err := d.resolveRegistryAuth(&driverConfig, repo)
+		if err != nil {
+			d.logger.Printf("[ERR] driver.docker: failed to find credentials for %s: %s", repo, err)
+			return nil, fmt.Errorf("Failed to find credentials for `%s`: %s", image, err)
 		}
 
 		err = client.PullImage(pullOptions, authOptions)
@@ -435,6 +852,16 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 	}
 	d.logger.Printf("[DEBUG] driver.docker: identified image %s as %s", image, dockerImage.ID)
 
+	// Reference the image so it isn't removed while the task uses it. The
+	// reference is dropped if the container can't be started.
+	coordinator.IncrementImageReference(dockerImage.ID, callerID)
+	started := false
+	defer func() {
+		if !started {
+			coordinator.RemoveImage(dockerImage.ID, image, callerID)
+		}
+	}()
+
 	config, err := d.createContainer(ctx, task, &driverConfig)
 	if err != nil {
 		d.logger.Printf("[ERR] driver.docker: failed to create container configuration for image %s: %s", image, err)
@@ -493,14 +920,17 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 		return nil, fmt.Errorf("Failed to start container %s: %s", container.ID, err)
 	}
 	d.logger.Printf("[INFO] driver.docker: started container %s", container.ID)
+	started = true
 
 	// Return a driver handle
 	h := &DockerHandle{
 		client:           client,
+		coordinator:      coordinator,
 		cleanupContainer: cleanupContainer,
-		cleanupImage:     cleanupImage,
 		logger:           d.logger,
+		callerID:         callerID,
 		imageID:          dockerImage.ID,
+		imageName:        image,
 		containerID:      container.ID,
 		doneCh:           make(chan struct{}),
 		waitCh:           make(chan *cstructs.WaitResult, 1),
@@ -509,9 +939,33 @@ func (d *DockerDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle
 	return h, nil
 }
 
+// loadImage loads the image tarball at the path relative to the task
+// directory, which is typically downloaded as an artifact.
+func (d *DockerDriver) loadImage(client *docker.Client, ctx *ExecContext, task *structs.Task, path string) error {
+	taskDir, ok := ctx.AllocDir.TaskDirs[task.Name]
+	if !ok {
+		return fmt.Errorf("Failed to find task local directory: %v", task.Name)
+	}
+	archive := filepath.Join(taskDir, path)
+	if !strings.HasPrefix(archive, taskDir+string(filepath.Separator)) {
+		return fmt.Errorf("Image tarball %q escapes the task directory", path)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("Failed to open image tarball: %v", err)
+	}
+	defer f.Close()
+
+	if err := client.LoadImage(docker.LoadImageOptions{InputStream: f}); err != nil {
+		return fmt.Errorf("Failed to load image tarball %s: %v", path, err)
+	}
+	d.logger.Printf("[DEBUG] driver.docker: loaded image tarball %s", archive)
+	return nil
+}
+
 func (d *DockerDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
 	cleanupContainer := d.config.ReadBoolDefault("docker.cleanup.container", true)
-	cleanupImage := d.config.ReadBoolDefault("docker.cleanup.image", true)
 
 	// Split the handle
 	pidBytes := []byte(strings.TrimPrefix(handleID, "DOCKER:"))
@@ -526,6 +980,10 @@ func (d *DockerDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, er
 	if err != nil {
 		return nil, fmt.Errorf("Failed to connect to docker daemon: %s", err)
 	}
+	coordinator, err := d.dockerCoordinator(client)
+	if err != nil {
+		return nil, err
+	}
 
 	// Look for a running container with this ID
 	containers, err := client.ListContainers(docker.ListContainersOptions{
@@ -547,13 +1005,20 @@ func (d *DockerDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, er
 		return nil, fmt.Errorf("Failed to find container %s: %v", pid.ContainerID, err)
 	}
 
+	// Restore the reference of the task to its image, which is lost when the
+	// client restarts.
+	callerID := d.imageCallerID(ctx)
+	coordinator.IncrementImageReference(pid.ImageID, callerID)
+
 	// Return a driver handle
 	h := &DockerHandle{
 		client:           client,
+		coordinator:      coordinator,
 		cleanupContainer: cleanupContainer,
-		cleanupImage:     cleanupImage,
 		logger:           d.logger,
+		callerID:         callerID,
 		imageID:          pid.ImageID,
+		imageName:        pid.ImageName,
 		containerID:      pid.ContainerID,
 		doneCh:           make(chan struct{}),
 		waitCh:           make(chan *cstructs.WaitResult, 1),
@@ -567,6 +1032,7 @@ func (h *DockerHandle) ID() string {
 	pid := dockerPID{
 		ImageID:     h.imageID,
 		ContainerID: h.containerID,
+		ImageName:   h.imageName,
 	}
 	data, err := json.Marshal(pid)
 	if err != nil {
@@ -588,6 +1054,120 @@ func (h *DockerHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+// Exec runs the command inside the container using the Docker exec API.
+func (h *DockerHandle) Exec(req *cstructs.ExecRequest) (int, error) {
+	if err := req.Validate(); err != nil {
+		return -1, err
+	}
+
+	exec, err := h.client.CreateExec(docker.CreateExecOptions{
+		Container:    h.containerID,
+		Cmd:          req.Cmd,
+		AttachStdin:  req.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          req.Tty,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("Failed to create exec in container %s: %s", h.containerID, err)
+	}
+
+	if req.Tty && req.ResizeCh != nil {
+		go func() {
+			for size := range req.ResizeCh {
+				if err := h.client.ResizeExecTTY(exec.ID, int(size.Height), int(size.Width)); err != nil {
+					h.logger.Printf("[DEBUG] driver.docker: failed to resize exec %s: %s", exec.ID, err)
+				}
+			}
+		}()
+	}
+
+	err = h.client.StartExec(exec.ID, docker.StartExecOptions{
+		InputStream:  req.Stdin,
+		OutputStream: req.Stdout,
+		ErrorStream:  req.Stderr,
+		Tty:          req.Tty,
+		RawTerminal:  req.Tty,
+	})
+	if err != nil {
+		return -1, fmt.Errorf("Failed to start exec in container %s: %s", h.containerID, err)
+	}
+
+	inspect, err := h.client.InspectExec(exec.ID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to inspect exec in container %s: %s", h.containerID, err)
+	}
+	return inspect.ExitCode, nil
+}
+
+// Stats returns the resource usage of the container from the Docker stats
+// API.
+func (h *DockerHandle) Stats() (*cstructs.TaskResourceUsage, error) {
+	statsCh := make(chan *docker.Stats, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- h.client.Stats(docker.StatsOptions{
+			ID:     h.containerID,
+			Stats:  statsCh,
+			Stream: false,
+		})
+	}()
+
+	var s *docker.Stats
+	select {
+	case s = <-statsCh:
+	case err := <-errCh:
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get stats of container %s: %v", h.containerID, err)
+		}
+		s = <-statsCh
+	}
+	if s == nil {
+		return nil, fmt.Errorf("No stats returned for container %s", h.containerID)
+	}
+
+	if h.totalCpuStats == nil {
+		h.totalCpuStats = stats.NewCpuStats()
+		h.userCpuStats = stats.NewCpuStats()
+		h.systemCpuStats = stats.NewCpuStats()
+	}
+
+	ms := &cstructs.MemoryStats{
+		RSS:      s.MemoryStats.Stats.Rss,
+		Cache:    s.MemoryStats.Stats.Cache,
+		Swap:     s.MemoryStats.Stats.Swap,
+		MaxUsage: s.MemoryStats.MaxUsage,
+		Measured: []string{"RSS", "Cache", "Swap", "Max Usage"},
+	}
+
+	// CPU usage is reported in nanoseconds
+	cpu := s.CPUStats
+	cs := &cstructs.CpuStats{
+		SystemMode:       h.systemCpuStats.Percent(float64(cpu.CPUUsage.UsageInKernelmode)),
+		UserMode:         h.userCpuStats.Percent(float64(cpu.CPUUsage.UsageInUsermode)),
+		Percent:          h.totalCpuStats.Percent(float64(cpu.CPUUsage.TotalUsage)),
+		ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+		ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+		Measured:         []string{"System Mode", "User Mode", "Percent", "Throttled Periods", "Throttled Time"},
+	}
+	return &cstructs.TaskResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{MemoryStats: ms, CpuStats: cs},
+		Timestamp:     time.Now().UTC().UnixNano(),
+	}, nil
+}
+
+// Signal sends the signal to the container's main process.
+func (h *DockerHandle) Signal(sig os.Signal) error {
+	sysSig, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("Failed to determine signal number for %v", sig)
+	}
+	return h.client.KillContainer(docker.KillContainerOptions{
+		ID:     h.containerID,
+		Signal: docker.Signal(sysSig),
+	})
+}
+
 // Kill is used to terminate the task. This uses docker stop -t 5
 func (h *DockerHandle) Kill() error {
 	// Stop the container
@@ -610,33 +1190,6 @@ func (h *DockerHandle) Kill() error {
 		}
 		h.logger.Printf("[INFO] driver.docker: removed container %s", h.containerID)
 	}
-
-	// Cleanup image. This operation may fail if the image is in use by another
-	// job. That is OK. Will we log a message but continue.
-	if h.cleanupImage {
-		err = h.client.RemoveImage(h.imageID)
-		if err != nil {
-			containers, err := h.client.ListContainers(docker.ListContainersOptions{
-				// The image might be in use by a stopped container, so check everything
-				All: true,
-				Filters: map[string][]string{
-					"image": []string{h.imageID},
-				},
-			})
-			if err != nil {
-				h.logger.Printf("[ERR] driver.docker: failed to query list of containers matching image:%s", h.imageID)
-				return fmt.Errorf("Failed to query list of containers: %s", err)
-			}
-			inUse := len(containers)
-			if inUse > 0 {
-				h.logger.Printf("[INFO] driver.docker: image %s is still in use by %d container(s)", h.imageID, inUse)
-			} else {
-				return fmt.Errorf("Failed to remove image %s", h.imageID)
-			}
-		} else {
-			h.logger.Printf("[INFO] driver.docker: removed image %s", h.imageID)
-		}
-	}
 	return nil
 }
 
@@ -651,6 +1204,12 @@ func (h *DockerHandle) run() {
 		err = fmt.Errorf("Docker container exited with non-zero exit code: %d", exitCode)
 	}
 
+	// The task no longer uses its image. It is removed once no other task
+	// uses it.
+	if h.coordinator != nil {
+		h.coordinator.RemoveImage(h.imageID, h.imageName, h.callerID)
+	}
+
 	close(h.doneCh)
 	h.waitCh <- cstructs.NewWaitResult(exitCode, 0, err)
 	close(h.waitCh)
@@ -0,0 +1,210 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// dockerHubRegistry is the registry of images without a registry host.
+	dockerHubRegistry = "index.docker.io"
+
+	// dockerHubServerAddress is the address Docker uses for Docker Hub in
+	// config files and credential helpers.
+	dockerHubServerAddress = "https://index.docker.io/v1/"
+
+	// credentialHelperPrefix prefixes the name of credential helpers to get the
+	// name of their binaries.
+	credentialHelperPrefix = "docker-credential-"
+)
+
+// authBackend looks up the credentials of a registry. It returns nil
+// credentials if it has none for the registry.
+type authBackend func(registry string) (*docker.AuthConfiguration, error)
+
+// resolveRegistryAuth returns the credentials used to pull the repository.
+// Credentials configured on the client through docker.auth.config and
+// docker.auth.helper are looked up first, falling back to the auth block of
+// the job. Anonymous credentials are returned if none are found.
+func (d *DockerDriver) resolveRegistryAuth(driverConfig *DockerDriverConfig, repo string) (docker.AuthConfiguration, error) {
+	var backends []authBackend
+	if file := d.config.Read("docker.auth.config"); file != "" {
+		backends = append(backends, authFromDockerConfig(file))
+	}
+	if helper := d.config.Read("docker.auth.helper"); helper != "" {
+		backends = append(backends, authFromHelper(helper))
+	}
+	if len(driverConfig.Auth) != 0 {
+		backends = append(backends, authFromTaskConfig(driverConfig.Auth[0]))
+	}
+
+	auth, err := firstValidAuth(registryFromRepo(repo), backends)
+	if err != nil {
+		return docker.AuthConfiguration{}, err
+	}
+	if auth == nil {
+		return docker.AuthConfiguration{}, nil
+	}
+	return *auth, nil
+}
+
+// firstValidAuth returns the credentials of the first backend that has
+// credentials for the registry.
+func firstValidAuth(registry string, backends []authBackend) (*docker.AuthConfiguration, error) {
+	for _, backend := range backends {
+		auth, err := backend(registry)
+		if err != nil {
+			return nil, err
+		}
+		if auth != nil {
+			return auth, nil
+		}
+	}
+	return nil, nil
+}
+
+// registryFromRepo returns the registry host of the repository. Repositories
+// without a registry host are on Docker Hub.
+func registryFromRepo(repo string) string {
+	parts := strings.SplitN(repo, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		return normalizeRegistry(parts[0])
+	}
+	return dockerHubRegistry
+}
+
+// normalizeRegistry strips the scheme and path of a registry address so the
+// addresses in Docker config files match registry hosts.
+func normalizeRegistry(address string) string {
+	address = strings.TrimPrefix(address, "https://")
+	address = strings.TrimPrefix(address, "http://")
+	if i := strings.Index(address, "/"); i != -1 {
+		address = address[:i]
+	}
+	if address == "docker.io" || address == "registry-1.docker.io" {
+		return dockerHubRegistry
+	}
+	return address
+}
+
+// serverAddress returns the address of the registry as used by Docker config
+// files and credential helpers.
+func serverAddress(registry string) string {
+	if registry == dockerHubRegistry {
+		return dockerHubServerAddress
+	}
+	return registry
+}
+
+// authFromTaskConfig returns the credentials of the auth block of the job,
+// which apply to any registry.
+func authFromTaskConfig(auth DockerDriverAuth) authBackend {
+	return func(string) (*docker.AuthConfiguration, error) {
+		return &docker.AuthConfiguration{
+			Username:      auth.Username,
+			Password:      auth.Password,
+			Email:         auth.Email,
+			ServerAddress: auth.ServerAddress,
+		}, nil
+	}
+}
+
+// dockerConfigFile is the part of a Docker config.json holding credentials.
+type dockerConfigFile struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+	CredsStore  string                      `json:"credsStore"`
+}
+
+type dockerConfigAuth struct {
+	Auth  string `json:"auth"`
+	Email string `json:"email"`
+}
+
+// authFromDockerConfig returns the credentials of the Docker config file.
+// Registries using a credential helper in the file are looked up with it.
+func authFromDockerConfig(file string) authBackend {
+	return func(registry string) (*docker.AuthConfiguration, error) {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open Docker config file: %v", err)
+		}
+		defer f.Close()
+
+		var cfg dockerConfigFile
+		if err := json.NewDecoder(f).Decode(&cfg); err != nil {
+			return nil, fmt.Errorf("Failed to parse Docker config file %s: %v", file, err)
+		}
+
+		for address, helper := range cfg.CredHelpers {
+			if normalizeRegistry(address) == registry {
+				return authFromHelper(helper)(registry)
+			}
+		}
+
+		for address, auth := range cfg.Auths {
+			if normalizeRegistry(address) != registry || auth.Auth == "" {
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to decode credentials of %s: %v", address, err)
+			}
+			userPass := strings.SplitN(string(decoded), ":", 2)
+			if len(userPass) != 2 {
+				return nil, fmt.Errorf("Invalid credentials of %s", address)
+			}
+			return &docker.AuthConfiguration{
+				Username:      userPass[0],
+				Password:      userPass[1],
+				Email:         auth.Email,
+				ServerAddress: address,
+			}, nil
+		}
+
+		if cfg.CredsStore != "" {
+			return authFromHelper(cfg.CredsStore)(registry)
+		}
+		return nil, nil
+	}
+}
+
+// authFromHelper returns the credentials of the Docker credential helper,
+// whose binary is found on the PATH of the client.
+func authFromHelper(helper string) authBackend {
+	return func(registry string) (*docker.AuthConfiguration, error) {
+		bin := credentialHelperPrefix + helper
+		cmd := exec.Command(bin, "get")
+		cmd.Stdin = strings.NewReader(serverAddress(registry))
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			// Helpers report unknown registries on stdout and exit non-zero
+			if strings.Contains(stdout.String()+stderr.String(), "credentials not found") {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("Docker credential helper %s failed: %v: %s", bin, err, strings.TrimSpace(stderr.String()))
+		}
+
+		var resp struct {
+			Username string
+			Secret   string
+		}
+		if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+			return nil, fmt.Errorf("Failed to parse the output of Docker credential helper %s: %v", bin, err)
+		}
+		return &docker.AuthConfiguration{
+			Username:      resp.Username,
+			Password:      resp.Secret,
+			ServerAddress: serverAddress(registry),
+		}, nil
+	}
+}
@@ -0,0 +1,188 @@
+package driver
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// testCredentialHelper installs a fake credential helper on the PATH that
+// knows the credentials of quay.io only.
+func testCredentialHelper(t *testing.T, name string) func() {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper script requires a posix shell")
+	}
+	dir, err := ioutil.TempDir("", "nomad-cred-helper")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	script := `#!/bin/sh
+read server
+if [ "$server" = "quay.io" ]; then
+  echo '{"ServerURL": "quay.io", "Username": "helper-user", "Secret": "helper-secret"}'
+  exit 0
+fi
+echo "credentials not found in native keychain"
+exit 1
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, credentialHelperPrefix+name), []byte(script), 0755); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", fmt.Sprintf("%s%c%s", dir, os.PathListSeparator, path))
+	return func() {
+		os.Setenv("PATH", path)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestRegistryFromRepo(t *testing.T) {
+	cases := map[string]string{
+		"redis":                   dockerHubRegistry,
+		"library/redis":           dockerHubRegistry,
+		"docker.io/library/redis": dockerHubRegistry,
+		"quay.io/foo/bar":         "quay.io",
+		"localhost/foo":           "localhost",
+		"localhost:5000/foo":      "localhost:5000",
+	}
+	for repo, expected := range cases {
+		if out := registryFromRepo(repo); out != expected {
+			t.Fatalf("registryFromRepo(%q) = %q; want %q", repo, out, expected)
+		}
+	}
+}
+
+func TestFirstValidAuth(t *testing.T) {
+	none := func(string) (*docker.AuthConfiguration, error) { return nil, nil }
+	found := func(registry string) (*docker.AuthConfiguration, error) {
+		return &docker.AuthConfiguration{Username: "user", ServerAddress: registry}, nil
+	}
+	failed := func(string) (*docker.AuthConfiguration, error) { return nil, fmt.Errorf("lookup failed") }
+
+	auth, err := firstValidAuth("quay.io", []authBackend{none, found, failed})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth == nil || auth.Username != "user" || auth.ServerAddress != "quay.io" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	if auth, err := firstValidAuth("quay.io", []authBackend{none}); err != nil || auth != nil {
+		t.Fatalf("expected no auth; got %#v, err: %v", auth, err)
+	}
+	if _, err := firstValidAuth("quay.io", []authBackend{failed, found}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestAuthFromDockerConfig(t *testing.T) {
+	defer testCredentialHelper(t, "fake")()
+
+	dir, err := ioutil.TempDir("", "nomad-docker-config")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.json")
+	userPass := base64.StdEncoding.EncodeToString([]byte("hub-user:hub-pass"))
+	cfg := fmt.Sprintf(`{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": %q, "email": "hub@example.com"}
+  },
+  "credHelpers": {
+    "quay.io": "fake"
+  }
+}`, userPass)
+	if err := ioutil.WriteFile(file, []byte(cfg), 0600); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	backend := authFromDockerConfig(file)
+
+	auth, err := backend(dockerHubRegistry)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth == nil || auth.Username != "hub-user" || auth.Password != "hub-pass" || auth.Email != "hub@example.com" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	auth, err = backend("quay.io")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth == nil || auth.Username != "helper-user" || auth.Password != "helper-secret" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	if auth, err := backend("gcr.io"); err != nil || auth != nil {
+		t.Fatalf("expected no auth; got %#v, err: %v", auth, err)
+	}
+}
+
+func TestAuthFromHelper(t *testing.T) {
+	defer testCredentialHelper(t, "fake")()
+	backend := authFromHelper("fake")
+
+	auth, err := backend("quay.io")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth == nil || auth.Username != "helper-user" || auth.Password != "helper-secret" || auth.ServerAddress != "quay.io" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	if auth, err := backend(dockerHubRegistry); err != nil || auth != nil {
+		t.Fatalf("expected no auth; got %#v, err: %v", auth, err)
+	}
+
+	if _, err := authFromHelper("missing")("quay.io"); err == nil {
+		t.Fatalf("expected error running a missing helper")
+	}
+}
+
+func TestDockerDriver_ResolveRegistryAuth(t *testing.T) {
+	defer testCredentialHelper(t, "fake")()
+	driverCtx := testDockerDriverContext("redis-demo")
+	d := NewDockerDriver(driverCtx).(*DockerDriver)
+	driverConfig := &DockerDriverConfig{
+		ImageName: "quay.io/foo/bar",
+		Auth:      []DockerDriverAuth{{Username: "job-user", Password: "job-pass"}},
+	}
+
+	// The auth block of the job is used when the client has no credentials
+	auth, err := d.resolveRegistryAuth(driverConfig, "quay.io/foo/bar")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth.Username != "job-user" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	// Credentials of the client take precedence
+	driverCtx.config.Options = map[string]string{"docker.auth.helper": "fake"}
+	auth, err = d.resolveRegistryAuth(driverConfig, "quay.io/foo/bar")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth.Username != "helper-user" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+
+	// Anonymous credentials are used when none are found
+	auth, err = d.resolveRegistryAuth(&DockerDriverConfig{ImageName: "redis"}, "redis")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if auth.Username != "" {
+		t.Fatalf("bad auth: %#v", auth)
+	}
+}
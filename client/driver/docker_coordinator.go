@@ -0,0 +1,197 @@
+package driver
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// defaultImageRemoveDelay is how long an image that is no longer used by
+	// any task is kept before it is removed.
+	defaultImageRemoveDelay = 3 * time.Minute
+)
+
+// We share the coordinator between all the Docker tasks of the client as
+// images are shared between them.
+var createCoordinator sync.Once
+var globalCoordinator *dockerCoordinator
+
+// dockerImageClient is the part of the Docker API the coordinator uses.
+type dockerImageClient interface {
+	RemoveImage(id string) error
+}
+
+// dockerCoordinatorConfig configures when the coordinator removes images.
+type dockerCoordinatorConfig struct {
+	logger *log.Logger
+	client dockerImageClient
+
+	// cleanup enables the removal of images.
+	cleanup bool
+
+	// removeDelay is how long an unused image is kept before it is removed.
+	removeDelay time.Duration
+
+	// keep holds the names and IDs of the images that are never removed.
+	// Names are normalized with normalizeImageName.
+	keep map[string]struct{}
+}
+
+// dockerCoordinator counts the references the Docker tasks of the client hold
+// on images and removes the images that are no longer referenced once the
+// remove delay has passed, unless they are referenced again in the meantime.
+type dockerCoordinator struct {
+	*dockerCoordinatorConfig
+
+	// imageRefs holds the IDs of the callers referencing each image ID.
+	imageRefs map[string]map[string]struct{}
+
+	// nameRefs holds the normalized names of the images referenced by callers
+	// which are pulling them and don't know their IDs yet, by caller ID.
+	nameRefs map[string]string
+
+	// deleteTimers holds the pending removals by image ID.
+	deleteTimers map[string]*time.Timer
+	imageLock    sync.Mutex
+}
+
+// newDockerCoordinator returns a coordinator with no references.
+func newDockerCoordinator(config *dockerCoordinatorConfig) *dockerCoordinator {
+	return &dockerCoordinator{
+		dockerCoordinatorConfig: config,
+		imageRefs:               make(map[string]map[string]struct{}),
+		nameRefs:                make(map[string]string),
+		deleteTimers:            make(map[string]*time.Timer),
+	}
+}
+
+// IncrementImageReference adds a reference from the caller to the image,
+// cancelling its pending removal.
+func (c *dockerCoordinator) IncrementImageReference(imageID, callerID string) {
+	c.imageLock.Lock()
+	defer c.imageLock.Unlock()
+
+	refs, ok := c.imageRefs[imageID]
+	if !ok {
+		refs = make(map[string]struct{})
+		c.imageRefs[imageID] = refs
+	}
+	refs[callerID] = struct{}{}
+
+	if timer, ok := c.deleteTimers[imageID]; ok {
+		timer.Stop()
+		delete(c.deleteTimers, imageID)
+		c.logger.Printf("[DEBUG] driver.docker: cancelled removal of image %s", imageID)
+	}
+}
+
+// IncrementImageNameReference adds a reference from the caller to the image
+// by name, before the image is pulled and its ID is known. Pending removals of
+// images of that name are skipped while the reference is held.
+func (c *dockerCoordinator) IncrementImageNameReference(imageName, callerID string) {
+	c.imageLock.Lock()
+	defer c.imageLock.Unlock()
+	c.nameRefs[callerID] = normalizeImageName(imageName)
+}
+
+// RemoveImageNameReference removes the reference of the caller to an image by
+// name.
+func (c *dockerCoordinator) RemoveImageNameReference(callerID string) {
+	c.imageLock.Lock()
+	defer c.imageLock.Unlock()
+	delete(c.nameRefs, callerID)
+}
+
+// RemoveImage removes the reference of the caller to the image. Once the
+// image is no longer referenced, it is removed after the remove delay unless
+// it is kept.
+func (c *dockerCoordinator) RemoveImage(imageID, imageName, callerID string) {
+	c.imageLock.Lock()
+	defer c.imageLock.Unlock()
+
+	if refs, ok := c.imageRefs[imageID]; ok {
+		delete(refs, callerID)
+		if len(refs) != 0 {
+			return
+		}
+		delete(c.imageRefs, imageID)
+	}
+
+	if !c.cleanup || c.isKept(imageID, imageName) {
+		return
+	}
+	if _, ok := c.deleteTimers[imageID]; ok {
+		return
+	}
+
+	c.logger.Printf("[DEBUG] driver.docker: removing unused image %s in %v", imageID, c.removeDelay)
+	c.deleteTimers[imageID] = time.AfterFunc(c.removeDelay, func() {
+		c.removeImageImpl(imageID, imageName)
+	})
+}
+
+// removeImageImpl removes the image if it wasn't referenced again while its
+// removal was pending. The lock is held while the image is removed so that
+// it can't be referenced in the meantime.
+func (c *dockerCoordinator) removeImageImpl(imageID, imageName string) {
+	c.imageLock.Lock()
+	defer c.imageLock.Unlock()
+
+	if _, ok := c.deleteTimers[imageID]; !ok {
+		// The removal was cancelled after the timer fired
+		return
+	}
+	delete(c.deleteTimers, imageID)
+
+	if len(c.imageRefs[imageID]) != 0 {
+		return
+	}
+	if imageName != "" {
+		name := normalizeImageName(imageName)
+		for _, ref := range c.nameRefs {
+			if ref == name {
+				// Retry later in case the image isn't referenced by ID once
+				// it is pulled.
+				c.logger.Printf("[DEBUG] driver.docker: postponed removal of image %s being pulled", imageID)
+				c.deleteTimers[imageID] = time.AfterFunc(c.removeDelay, func() {
+					c.removeImageImpl(imageID, imageName)
+				})
+				return
+			}
+		}
+	}
+
+	// Removing the image fails if it is still used by containers that weren't
+	// started by the client or weren't removed. That is OK.
+	if err := c.client.RemoveImage(imageID); err != nil {
+		c.logger.Printf("[INFO] driver.docker: failed to remove image %s: %v", imageID, err)
+		return
+	}
+	c.logger.Printf("[INFO] driver.docker: removed image %s", imageID)
+}
+
+// isKept returns whether the image is in the keep-list by ID or name.
+func (c *dockerCoordinator) isKept(imageID, imageName string) bool {
+	if _, ok := c.keep[imageID]; ok {
+		return true
+	}
+	if imageName == "" {
+		return false
+	}
+	_, ok := c.keep[normalizeImageName(imageName)]
+	return ok
+}
+
+// normalizeImageName returns the name of the image with its tag, which
+// defaults to "latest".
+func normalizeImageName(name string) string {
+	repo, tag := docker.ParseRepositoryTag(strings.TrimSpace(name))
+	if tag == "" {
+		tag = "latest"
+	}
+	return repo + ":" + tag
+}
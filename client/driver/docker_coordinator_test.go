@@ -0,0 +1,172 @@
+package driver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
+	dtesting "github.com/fsouza/go-dockerclient/testing"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+// testDockerImage starts a fake Docker API server holding a pulled image and
+// returns a client of the server along with the ID of the image.
+func testDockerImage(t *testing.T, image string) (*docker.Client, string, func()) {
+	server, err := dtesting.NewServer("127.0.0.1:0", nil, nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	client, err := docker.NewClient(server.URL())
+	if err != nil {
+		server.Stop()
+		t.Fatalf("err: %v", err)
+	}
+
+	repo, tag := docker.ParseRepositoryTag(image)
+	if err := client.PullImage(docker.PullImageOptions{Repository: repo, Tag: tag}, docker.AuthConfiguration{}); err != nil {
+		server.Stop()
+		t.Fatalf("err: %v", err)
+	}
+	img, err := client.InspectImage(image)
+	if err != nil {
+		server.Stop()
+		t.Fatalf("err: %v", err)
+	}
+	return client, img.ID, server.Stop
+}
+
+func testDockerCoordinator(client *docker.Client, keep ...string) *dockerCoordinator {
+	keepMap := make(map[string]struct{})
+	for _, k := range keep {
+		keepMap[normalizeImageName(k)] = struct{}{}
+	}
+	return newDockerCoordinator(&dockerCoordinatorConfig{
+		logger:      testLogger(),
+		client:      client,
+		cleanup:     true,
+		removeDelay: 10 * time.Millisecond,
+		keep:        keepMap,
+	})
+}
+
+func imageExists(client *docker.Client, id string) bool {
+	_, err := client.InspectImage(id)
+	return err == nil
+}
+
+func TestDockerCoordinator_RemoveImage(t *testing.T) {
+	client, id, stop := testDockerImage(t, "busybox:1.24")
+	defer stop()
+	c := testDockerCoordinator(client)
+
+	c.IncrementImageReference(id, "web-1")
+	c.IncrementImageReference(id, "web-2")
+
+	// The image is still used by the second task
+	c.RemoveImage(id, "busybox:1.24", "web-1")
+	time.Sleep(50 * time.Millisecond)
+	if !imageExists(client, id) {
+		t.Fatalf("image removed while referenced")
+	}
+
+	// The image is removed after the delay once it isn't used
+	c.RemoveImage(id, "busybox:1.24", "web-2")
+	testutil.WaitForResult(func() (bool, error) {
+		if imageExists(client, id) {
+			return false, fmt.Errorf("image %s not removed", id)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestDockerCoordinator_RemoveImage_Cancel(t *testing.T) {
+	client, id, stop := testDockerImage(t, "busybox:1.24")
+	defer stop()
+	c := testDockerCoordinator(client)
+	c.removeDelay = 100 * time.Millisecond
+
+	// Referencing the image again before the delay cancels its removal
+	c.IncrementImageReference(id, "web-1")
+	c.RemoveImage(id, "busybox:1.24", "web-1")
+	c.IncrementImageReference(id, "web-1")
+
+	time.Sleep(200 * time.Millisecond)
+	if !imageExists(client, id) {
+		t.Fatalf("image removed while referenced")
+	}
+}
+
+func TestDockerCoordinator_RemoveImage_Pulling(t *testing.T) {
+	client, id, stop := testDockerImage(t, "busybox:1.24")
+	defer stop()
+	c := testDockerCoordinator(client)
+
+	// The image isn't removed while another task pulls it by name
+	c.IncrementImageReference(id, "web-1")
+	c.IncrementImageNameReference("busybox:1.24", "web-2")
+	c.RemoveImage(id, "busybox:1.24", "web-1")
+
+	time.Sleep(50 * time.Millisecond)
+	if !imageExists(client, id) {
+		t.Fatalf("image removed while pulled")
+	}
+
+	// Once referenced by ID the image is removed when it is no longer used
+	c.IncrementImageReference(id, "web-2")
+	c.RemoveImageNameReference("web-2")
+	c.RemoveImage(id, "busybox:1.24", "web-2")
+	testutil.WaitForResult(func() (bool, error) {
+		if imageExists(client, id) {
+			return false, fmt.Errorf("image %s not removed", id)
+		}
+		return true, nil
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestDockerCoordinator_RemoveImage_Keep(t *testing.T) {
+	client, id, stop := testDockerImage(t, "redis:latest")
+	defer stop()
+	c := testDockerCoordinator(client, "redis")
+
+	c.IncrementImageReference(id, "web-1")
+	c.RemoveImage(id, "redis", "web-1")
+
+	time.Sleep(50 * time.Millisecond)
+	if !imageExists(client, id) {
+		t.Fatalf("kept image removed")
+	}
+}
+
+func TestDockerCoordinator_RemoveImage_NoCleanup(t *testing.T) {
+	client, id, stop := testDockerImage(t, "busybox:1.24")
+	defer stop()
+	c := testDockerCoordinator(client)
+	c.cleanup = false
+
+	c.IncrementImageReference(id, "web-1")
+	c.RemoveImage(id, "busybox:1.24", "web-1")
+
+	time.Sleep(50 * time.Millisecond)
+	if !imageExists(client, id) {
+		t.Fatalf("image removed with cleanup disabled")
+	}
+}
+
+func TestNormalizeImageName(t *testing.T) {
+	cases := map[string]string{
+		"redis":                    "redis:latest",
+		"redis:3.2":                "redis:3.2",
+		"quay.io/foo/bar":          "quay.io/foo/bar:latest",
+		"localhost:5000/foo:1.0.1": "localhost:5000/foo:1.0.1",
+	}
+	for in, expected := range cases {
+		if out := normalizeImageName(in); out != expected {
+			t.Fatalf("normalizeImageName(%q) = %q; want %q", in, out, expected)
+		}
+	}
+}
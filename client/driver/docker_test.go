@@ -618,8 +618,12 @@ func TestDockerPortsMapping(t *testing.T) {
 	}
 
 	expectedEnvironment := map[string]string{
-		"NOMAD_PORT_main":  "8080",
-		"NOMAD_PORT_REDIS": "6379",
+		"NOMAD_PORT_main":       "8080",
+		"NOMAD_PORT_REDIS":      "6379",
+		"NOMAD_HOST_PORT_main":  "11110",
+		"NOMAD_HOST_PORT_REDIS": "43330",
+		"NOMAD_ADDR_main":       "127.0.0.1:11110",
+		"NOMAD_ADDR_REDIS":      "127.0.0.1:43330",
 	}
 
 	for key, val := range expectedEnvironment {
@@ -629,3 +633,134 @@ func TestDockerPortsMapping(t *testing.T) {
 		}
 	}
 }
+
+func TestDockerDriverConfig_Validate(t *testing.T) {
+	cases := []struct {
+		config DockerDriverConfig
+		err    bool
+	}{
+		{DockerDriverConfig{ImageName: "redis"}, false},
+		{DockerDriverConfig{}, true},
+		{DockerDriverConfig{ImageName: "redis", Volumes: []string{"data:/data:ro"}}, false},
+		{DockerDriverConfig{ImageName: "redis", Volumes: []string{"data"}}, true},
+		{DockerDriverConfig{ImageName: "redis", Volumes: []string{"data:data"}}, true},
+		{DockerDriverConfig{ImageName: "redis", Volumes: []string{"data:/data:rx"}}, true},
+		{DockerDriverConfig{ImageName: "redis", Mounts: []DockerMount{{Target: "/data", Source: "data", Propagation: "rslave"}}}, false},
+		{DockerDriverConfig{ImageName: "redis", Mounts: []DockerMount{{Target: "/data", Source: "data", Propagation: "up"}}}, true},
+		{DockerDriverConfig{ImageName: "redis", Mounts: []DockerMount{{Type: "tmpfs", Target: "/tmp", SizeBytes: 1024, Mode: "1777"}}}, false},
+		{DockerDriverConfig{ImageName: "redis", Mounts: []DockerMount{{Type: "tmpfs", Target: "/tmp", Source: "data"}}}, true},
+		{DockerDriverConfig{ImageName: "redis", Mounts: []DockerMount{{Type: "volume", Target: "/data"}}}, true},
+		{DockerDriverConfig{ImageName: "redis", UlimitRaw: []map[string]string{{"nofile": "1024:2048"}}}, false},
+		{DockerDriverConfig{ImageName: "redis", UlimitRaw: []map[string]string{{"nofile": "4096:2048"}}}, true},
+		{DockerDriverConfig{ImageName: "redis", ExtraHosts: []string{"db:10.0.0.1"}}, false},
+		{DockerDriverConfig{ImageName: "redis", ExtraHosts: []string{"db"}}, true},
+		{DockerDriverConfig{ImageName: "redis", IpcMode: "host", PidMode: "host", UsernsMode: "host"}, false},
+		{DockerDriverConfig{ImageName: "redis", PidMode: "container"}, true},
+		{DockerDriverConfig{ImageName: "redis", ShmSize: -1}, true},
+		{DockerDriverConfig{ImageName: "redis", WorkDir: "data"}, true},
+		{DockerDriverConfig{ImageName: "redis", LoadImage: "redis.tar", ForcePull: true}, true},
+	}
+
+	for i, c := range cases {
+		err := c.config.Validate()
+		if c.err && err == nil {
+			t.Fatalf("case %d: expected error", i)
+		}
+		if !c.err && err != nil {
+			t.Fatalf("case %d: err: %v", i, err)
+		}
+	}
+}
+
+func TestParseUlimits(t *testing.T) {
+	ulimits, err := parseUlimits(map[string]string{"nproc": "512", "nofile": "1024:2048"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []docker.ULimit{
+		{Name: "nofile", Soft: 1024, Hard: 2048},
+		{Name: "nproc", Soft: 512, Hard: 512},
+	}
+	if !reflect.DeepEqual(ulimits, expected) {
+		t.Fatalf("got %#v; want %#v", ulimits, expected)
+	}
+
+	for _, raw := range []string{"", "a", "1:2:3", "1:b"} {
+		if _, err := parseUlimits(map[string]string{"nofile": raw}); err == nil {
+			t.Fatalf("expected error parsing %q", raw)
+		}
+	}
+}
+
+func TestDockerDriver_CheckCapabilities(t *testing.T) {
+	driverCtx := testDockerDriverContext("redis-demo")
+	d := NewDockerDriver(driverCtx).(*DockerDriver)
+
+	if err := d.checkCapabilities([]string{"CHOWN", "cap_kill"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := d.checkCapabilities([]string{"SYS_ADMIN"}); err == nil {
+		t.Fatalf("expected error adding a capability outside the default whitelist")
+	}
+
+	driverCtx.config.Options = map[string]string{"docker.caps.whitelist": "SYS_ADMIN"}
+	if err := d.checkCapabilities([]string{"SYS_ADMIN"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := d.checkCapabilities([]string{"CHOWN"}); err == nil {
+		t.Fatalf("expected error adding a capability outside the whitelist")
+	}
+
+	driverCtx.config.Options["docker.caps.whitelist"] = "ALL"
+	if err := d.checkCapabilities([]string{"SYS_ADMIN", "NET_ADMIN"}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestDockerDriver_ContainerVolumes(t *testing.T) {
+	task := dockerTask()
+	driverCtx := testDockerDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+	defer ctx.AllocDir.Destroy()
+	d := NewDockerDriver(driverCtx).(*DockerDriver)
+	taskDir := ctx.AllocDir.TaskDirs[task.Name]
+
+	driverConfig := &DockerDriverConfig{
+		ImageName: "redis",
+		Volumes:   []string{"data:/data:ro"},
+		Mounts: []DockerMount{
+			{Type: DockerMountTypeBind, Source: "conf", Target: "/etc/redis", Propagation: "rslave"},
+			{Type: DockerMountTypeTmpfs, Target: "/tmp", SizeBytes: 1024, Mode: "1777"},
+		},
+	}
+	binds, tmpfs, err := d.containerVolumes(ctx, task, driverConfig)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []string{
+		filepath.Join(taskDir, "data") + ":/data:ro",
+		filepath.Join(taskDir, "conf") + ":/etc/redis:rw,rslave",
+	}
+	if !reflect.DeepEqual(binds, expected) {
+		t.Fatalf("got binds %v; want %v", binds, expected)
+	}
+	if tmpfs["/tmp"] != "size=1024,mode=1777" {
+		t.Fatalf("bad tmpfs: %v", tmpfs)
+	}
+
+	// Relative paths can't escape the task directory
+	driverConfig = &DockerDriverConfig{ImageName: "redis", Volumes: []string{"../../etc:/data"}}
+	if _, _, err := d.containerVolumes(ctx, task, driverConfig); err == nil {
+		t.Fatalf("expected error escaping the task directory")
+	}
+
+	// Absolute paths must be enabled by the client
+	driverConfig = &DockerDriverConfig{ImageName: "redis", Volumes: []string{"/etc:/data"}}
+	if _, _, err := d.containerVolumes(ctx, task, driverConfig); err == nil {
+		t.Fatalf("expected error mounting an absolute path")
+	}
+	driverCtx.config.Options = map[string]string{"docker.volumes.enabled": "true"}
+	if binds, _, err := d.containerVolumes(ctx, task, driverConfig); err != nil || binds[0] != "/etc:/data" {
+		t.Fatalf("got binds %v; err: %v", binds, err)
+	}
+}
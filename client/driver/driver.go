@@ -3,6 +3,7 @@ package driver
 import (
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
 	"sync"
 
@@ -24,14 +25,28 @@ var BuiltinDrivers = map[string]Factory{
 	"java":     NewJavaDriver,
 	"qemu":     NewQemuDriver,
 	"rkt":      NewRktDriver,
+
+	// The mock driver is only fingerprinted when it is explicitly enabled
+	// and is used for testing.
+	"mock_driver": NewMockDriver,
 }
 
 // NewDriver is used to instantiate and return a new driver
-// given the name and a logger
+// given the name and a logger. Drivers that aren't built in are looked up in
+// the plugin directory of the client.
 func NewDriver(name string, ctx *DriverContext) (Driver, error) {
 	// Lookup the factory function
 	factory, ok := BuiltinDrivers[name]
 	if !ok {
+		if ctx.config != nil {
+			plugins, err := DiscoverPlugins(ctx.config.PluginDir)
+			if err != nil {
+				return nil, err
+			}
+			if path, ok := plugins[name]; ok {
+				return NewPluginDriver(name, path, ctx), nil
+			}
+		}
 		return nil, fmt.Errorf("unknown driver '%s'", name)
 	}
 
@@ -96,6 +111,37 @@ type DriverHandle interface {
 	Kill() error
 }
 
+// ExecHandle is implemented by DriverHandles that can run additional
+// commands inside the isolation context of the task, similar to docker exec.
+type ExecHandle interface {
+	// Exec runs the command and blocks until it exits, returning its exit
+	// code.
+	Exec(req *cstructs.ExecRequest) (int, error)
+}
+
+// SignalHandle is implemented by DriverHandles that can send signals to the
+// running task.
+type SignalHandle interface {
+	// Signal sends the signal to the task.
+	Signal(sig os.Signal) error
+}
+
+// StatsHandle is implemented by DriverHandles that can report the resource
+// usage of the running task.
+type StatsHandle interface {
+	// Stats returns the resource usage of the task. CPU percentages are
+	// relative to the previous call.
+	Stats() (*cstructs.TaskResourceUsage, error)
+}
+
+// TaskEnvironmentDriver is implemented by drivers that present the task with
+// a different environment than TaskEnvironmentVariables builds, such as
+// drivers that mount the task directories at other paths.
+type TaskEnvironmentDriver interface {
+	// TaskEnvironment returns the environment the task is run with.
+	TaskEnvironment(ctx *ExecContext, task *structs.Task) (environment.TaskEnvironment, error)
+}
+
 // ExecContext is shared between drivers within an allocation
 type ExecContext struct {
 	sync.Mutex
@@ -105,11 +151,23 @@ type ExecContext struct {
 
 	// Alloc ID
 	AllocID string
+
+	// Alloc is the allocation the tasks belong to. It is persisted separately
+	// so it must be set again when the context is restored.
+	Alloc *structs.Allocation `json:"-"`
+
+	// Node is the node running the allocation.
+	Node *structs.Node `json:"-"`
 }
 
 // NewExecContext is used to create a new execution context
-func NewExecContext(alloc *allocdir.AllocDir, allocID string) *ExecContext {
-	return &ExecContext{AllocDir: alloc, AllocID: allocID}
+func NewExecContext(allocDir *allocdir.AllocDir, alloc *structs.Allocation, node *structs.Node) *ExecContext {
+	return &ExecContext{
+		AllocDir: allocDir,
+		AllocID:  alloc.ID,
+		Alloc:    alloc,
+		Node:     node,
+	}
 }
 
 // TaskEnvironmentVariables converts exec context and task configuration into a
@@ -117,6 +175,32 @@ func NewExecContext(alloc *allocdir.AllocDir, allocID string) *ExecContext {
 func TaskEnvironmentVariables(ctx *ExecContext, task *structs.Task) environment.TaskEnvironment {
 	env := environment.NewTaskEnivornment()
 	env.SetMeta(task.Meta)
+	env.SetTaskName(task.Name)
+
+	if alloc := ctx.Alloc; alloc != nil {
+		env.SetAllocId(alloc.ID)
+		env.SetAllocName(alloc.Name)
+		env.SetAllocIndex(alloc.Index())
+		env.SetTaskGroupName(alloc.TaskGroup)
+		if alloc.Job != nil {
+			env.SetJobName(alloc.Job.Name)
+			env.SetRegion(alloc.Job.Region)
+		}
+
+		// Expose the addresses of the other tasks of the allocation so
+		// tasks such as sidecars can find each other.
+		for name, resources := range alloc.TaskResources {
+			if name == task.Name || resources == nil {
+				continue
+			}
+			for _, network := range resources.Networks {
+				env.SetAddrs(name+"_", network.IP, network.MapLabelToValues(nil))
+			}
+		}
+	}
+	if ctx.Node != nil {
+		env.SetDatacenter(ctx.Node.Datacenter)
+	}
 
 	if ctx.AllocDir != nil {
 		env.SetAllocDir(ctx.AllocDir.SharedDir)
@@ -126,6 +210,7 @@ func TaskEnvironmentVariables(ctx *ExecContext, task *structs.Task) environment.
 		}
 
 		env.SetTaskLocalDir(filepath.Join(taskdir, allocdir.TaskLocal))
+		env.SetSecretsDir(filepath.Join(taskdir, allocdir.TaskSecrets))
 	}
 
 	if task.Resources != nil {
@@ -133,9 +218,13 @@ func TaskEnvironmentVariables(ctx *ExecContext, task *structs.Task) environment.
 		env.SetCpuLimit(task.Resources.CPU)
 
 		if len(task.Resources.Networks) > 0 {
-			network := task.Resources.Networks[0]
-			env.SetTaskIp(network.IP)
-			env.SetPorts(network.MapLabelToValues(nil))
+			env.SetTaskIp(task.Resources.Networks[0].IP)
+		}
+		for _, network := range task.Resources.Networks {
+			ports := network.MapLabelToValues(nil)
+			env.SetPorts(ports)
+			env.SetHostPorts(ports)
+			env.SetAddrs("", network.IP, ports)
 		}
 	}
 
@@ -146,6 +235,15 @@ func TaskEnvironmentVariables(ctx *ExecContext, task *structs.Task) environment.
 	return env
 }
 
+// DriverTaskEnvironment returns the environment the driver runs the task
+// with.
+func DriverTaskEnvironment(d Driver, ctx *ExecContext, task *structs.Task) (environment.TaskEnvironment, error) {
+	if envDriver, ok := d.(TaskEnvironmentDriver); ok {
+		return envDriver.TaskEnvironment(ctx, task)
+	}
+	return TaskEnvironmentVariables(ctx, task), nil
+}
+
 func mapMergeStrInt(maps ...map[string]int) map[string]int {
 	out := map[string]int{}
 	for _, in := range maps {
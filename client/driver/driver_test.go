@@ -1,7 +1,6 @@
 package driver
 
 import (
-	"fmt"
 	"log"
 	"math/rand"
 	"os"
@@ -11,7 +10,9 @@ import (
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/helper/testtask"
+	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -32,6 +33,14 @@ func init() {
 }
 
 func TestMain(m *testing.M) {
+	// The test binary serves the fake driver when launched as a plugin
+	if testPluginServe() {
+		return
+	}
+	// and the executors of the tasks when launched as an executor
+	if testExecutorServe() {
+		return
+	}
 	if !testtask.Run() {
 		os.Exit(m.Run())
 	}
@@ -56,7 +65,15 @@ func testDriverContext(task string) *DriverContext {
 func testDriverExecContext(task *structs.Task, driverCtx *DriverContext) *ExecContext {
 	allocDir := allocdir.NewAllocDir(filepath.Join(driverCtx.config.AllocDir, structs.GenerateUUID()))
 	allocDir.Build([]*structs.Task{task})
-	ctx := NewExecContext(allocDir, fmt.Sprintf("alloc-id-%d", int(rand.Int31())))
+
+	// Download the task's artifacts as the task runner would
+	for _, artifact := range task.Artifacts {
+		if err := getter.GetArtifact(artifact, allocDir.TaskDirs[task.Name], driverCtx.logger); err != nil {
+			driverCtx.logger.Printf("[ERR] driver: failed to download artifact: %v", err)
+		}
+	}
+
+	ctx := NewExecContext(allocDir, mock.Alloc(), driverCtx.node)
 	return ctx
 }
 
@@ -90,12 +107,31 @@ func TestDriver_TaskEnvironmentVariables(t *testing.T) {
 		"NOMAD_CPU_LIMIT":       "1000",
 		"NOMAD_MEMORY_LIMIT":    "500",
 		"NOMAD_IP":              "1.2.3.4",
+		"NOMAD_TASK_NAME":       "",
 		"NOMAD_PORT_one":        "80",
 		"NOMAD_PORT_two":        "443",
 		"NOMAD_PORT_three":      "8080",
 		"NOMAD_PORT_four":       "12345",
 		"NOMAD_PORT_admin":      "8081",
 		"NOMAD_PORT_web":        "8086",
+		"NOMAD_HOST_PORT_one":   "80",
+		"NOMAD_HOST_PORT_two":   "443",
+		"NOMAD_HOST_PORT_three": "8080",
+		"NOMAD_HOST_PORT_four":  "12345",
+		"NOMAD_HOST_PORT_admin": "8081",
+		"NOMAD_HOST_PORT_web":   "8086",
+		"NOMAD_IP_one":          "1.2.3.4",
+		"NOMAD_IP_two":          "1.2.3.4",
+		"NOMAD_IP_three":        "1.2.3.4",
+		"NOMAD_IP_four":         "1.2.3.4",
+		"NOMAD_IP_admin":        "1.2.3.4",
+		"NOMAD_IP_web":          "1.2.3.4",
+		"NOMAD_ADDR_one":        "1.2.3.4:80",
+		"NOMAD_ADDR_two":        "1.2.3.4:443",
+		"NOMAD_ADDR_three":      "1.2.3.4:8080",
+		"NOMAD_ADDR_four":       "1.2.3.4:12345",
+		"NOMAD_ADDR_admin":      "1.2.3.4:8081",
+		"NOMAD_ADDR_web":        "1.2.3.4:8086",
 		"NOMAD_META_CHOCOLATE":  "cake",
 		"NOMAD_META_STRAWBERRY": "icecream",
 		"HELLO":                 "world",
@@ -108,6 +144,80 @@ func TestDriver_TaskEnvironmentVariables(t *testing.T) {
 	}
 }
 
+func TestDriver_TaskEnvironmentVariables_Networks(t *testing.T) {
+	t.Parallel()
+	ctx := &ExecContext{}
+	task := &structs.Task{
+		Name: "web",
+		Resources: &structs.Resources{
+			Networks: []*structs.NetworkResource{
+				&structs.NetworkResource{
+					IP:            "1.2.3.4",
+					ReservedPorts: []structs.Port{{"one", 80}},
+				},
+				&structs.NetworkResource{
+					IP:           "5.6.7.8",
+					DynamicPorts: []structs.Port{{"web", 8086}},
+				},
+			},
+		},
+	}
+
+	env := TaskEnvironmentVariables(ctx, task).Map()
+	exp := map[string]string{
+		"NOMAD_IP":            "1.2.3.4",
+		"NOMAD_TASK_NAME":     "web",
+		"NOMAD_PORT_one":      "80",
+		"NOMAD_PORT_web":      "8086",
+		"NOMAD_HOST_PORT_one": "80",
+		"NOMAD_HOST_PORT_web": "8086",
+		"NOMAD_IP_one":        "1.2.3.4",
+		"NOMAD_IP_web":        "5.6.7.8",
+		"NOMAD_ADDR_one":      "1.2.3.4:80",
+		"NOMAD_ADDR_web":      "5.6.7.8:8086",
+	}
+	for k, v := range exp {
+		if env[k] != v {
+			t.Fatalf("%s is %q; want %q: %#v", k, env[k], v, env)
+		}
+	}
+}
+
+func TestDriver_TaskEnvironmentVariables_Alloc(t *testing.T) {
+	t.Parallel()
+	alloc := mock.Alloc()
+	alloc.Name = "my-job.web[2]"
+	alloc.TaskResources["sidecar"] = &structs.Resources{
+		Networks: []*structs.NetworkResource{
+			&structs.NetworkResource{
+				IP:            "10.0.0.1",
+				ReservedPorts: []structs.Port{{"proxy", 9000}},
+			},
+		},
+	}
+	ctx := &ExecContext{AllocID: alloc.ID, Alloc: alloc, Node: mock.Node()}
+	task := alloc.Job.TaskGroups[0].Tasks[0]
+
+	env := TaskEnvironmentVariables(ctx, task).Map()
+	exp := map[string]string{
+		"NOMAD_ALLOC_ID":           alloc.ID,
+		"NOMAD_ALLOC_NAME":         "my-job.web[2]",
+		"NOMAD_ALLOC_INDEX":        "2",
+		"NOMAD_JOB_NAME":           alloc.Job.Name,
+		"NOMAD_GROUP_NAME":         "web",
+		"NOMAD_TASK_NAME":          task.Name,
+		"NOMAD_DC":                 "dc1",
+		"NOMAD_REGION":             alloc.Job.Region,
+		"NOMAD_ADDR_sidecar_proxy": "10.0.0.1:9000",
+		"NOMAD_IP_sidecar_proxy":   "10.0.0.1",
+	}
+	for k, v := range exp {
+		if env[k] != v {
+			t.Fatalf("%s is %q; want %q: %#v", k, env[k], v, env)
+		}
+	}
+}
+
 func TestMapMergeStrInt(t *testing.T) {
 	t.Parallel()
 	a := map[string]int{
@@ -2,6 +2,7 @@ package environment
 
 import (
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 )
@@ -16,6 +17,10 @@ const (
 	// persisted to the alloc is removed.
 	TaskLocalDir = "NOMAD_TASK_DIR"
 
+	// The path to the tasks secrets directory, which is held in memory
+	// where supported.
+	SecretsDir = "NOMAD_SECRETS_DIR"
+
 	// The tasks memory limit in MBs.
 	MemLimit = "NOMAD_MEMORY_LIMIT"
 
@@ -25,17 +30,59 @@ const (
 	// The IP address for the task.
 	TaskIP = "NOMAD_IP"
 
+	// The ID of the allocation.
+	AllocID = "NOMAD_ALLOC_ID"
+
+	// The name of the allocation, e.g. example.cache[0].
+	AllocName = "NOMAD_ALLOC_NAME"
+
+	// The index of the allocation within its task group.
+	AllocIndex = "NOMAD_ALLOC_INDEX"
+
+	// The name of the job of the task.
+	JobName = "NOMAD_JOB_NAME"
+
+	// The name of the task group of the task.
+	TaskGroupName = "NOMAD_GROUP_NAME"
+
+	// The name of the task.
+	TaskName = "NOMAD_TASK_NAME"
+
+	// The datacenter of the node running the task.
+	Datacenter = "NOMAD_DC"
+
+	// The region of the job.
+	Region = "NOMAD_REGION"
+
 	// Prefix for passing both dynamic and static port allocations to
 	// tasks.
 	// E.g. $NOMAD_PORT_1 or $NOMAD_PORT_http
 	PortPrefix = "NOMAD_PORT_"
 
+	// Prefix for passing the port allocated on the host, which differs from
+	// the port of the task if the driver maps ports.
+	// E.g. $NOMAD_HOST_PORT_http
+	HostPortPrefix = "NOMAD_HOST_PORT_"
+
+	// Prefix for passing the IP of each port label.
+	// E.g. $NOMAD_IP_http
+	IPPrefix = "NOMAD_IP_"
+
+	// Prefix for passing the IP:port address of each port label. Ports of
+	// the other tasks of the allocation are passed with the task name
+	// prepended to the label.
+	// E.g. $NOMAD_ADDR_http or $NOMAD_ADDR_web_http
+	AddrPrefix = "NOMAD_ADDR_"
+
 	// Prefix for passing task meta data.
 	MetaPrefix = "NOMAD_META_"
 )
 
 var (
-	nomadVars = []string{AllocDir, TaskLocalDir, MemLimit, CpuLimit, TaskIP, PortPrefix, MetaPrefix}
+	nomadVars = []string{AllocDir, TaskLocalDir, SecretsDir, MemLimit, CpuLimit, TaskIP,
+		AllocID, AllocName, AllocIndex, JobName, TaskGroupName, TaskName,
+		Datacenter, Region, PortPrefix, HostPortPrefix, IPPrefix, AddrPrefix,
+		MetaPrefix}
 )
 
 type TaskEnvironment map[string]string
@@ -95,6 +142,14 @@ func (t TaskEnvironment) ClearTaskLocalDir() {
 	delete(t, TaskLocalDir)
 }
 
+func (t TaskEnvironment) SetSecretsDir(dir string) {
+	t[SecretsDir] = dir
+}
+
+func (t TaskEnvironment) ClearSecretsDir() {
+	delete(t, SecretsDir)
+}
+
 func (t TaskEnvironment) SetMemLimit(limit int) {
 	t[MemLimit] = strconv.Itoa(limit)
 }
@@ -119,6 +174,38 @@ func (t TaskEnvironment) ClearTaskIp() {
 	delete(t, TaskIP)
 }
 
+func (t TaskEnvironment) SetAllocId(id string) {
+	t[AllocID] = id
+}
+
+func (t TaskEnvironment) SetAllocName(name string) {
+	t[AllocName] = name
+}
+
+func (t TaskEnvironment) SetAllocIndex(index uint) {
+	t[AllocIndex] = strconv.FormatUint(uint64(index), 10)
+}
+
+func (t TaskEnvironment) SetJobName(name string) {
+	t[JobName] = name
+}
+
+func (t TaskEnvironment) SetTaskGroupName(name string) {
+	t[TaskGroupName] = name
+}
+
+func (t TaskEnvironment) SetTaskName(name string) {
+	t[TaskName] = name
+}
+
+func (t TaskEnvironment) SetDatacenter(dc string) {
+	t[Datacenter] = dc
+}
+
+func (t TaskEnvironment) SetRegion(region string) {
+	t[Region] = region
+}
+
 // Takes a map of port labels to their port value.
 func (t TaskEnvironment) SetPorts(ports map[string]int) {
 	for label, port := range ports {
@@ -126,10 +213,29 @@ func (t TaskEnvironment) SetPorts(ports map[string]int) {
 	}
 }
 
+// Takes a map of port labels to the port allocated on the host.
+func (t TaskEnvironment) SetHostPorts(ports map[string]int) {
+	for label, port := range ports {
+		t[fmt.Sprintf("%s%s", HostPortPrefix, label)] = strconv.Itoa(port)
+	}
+}
+
+// Takes the IP of a network and a map of its port labels to their port value
+// and sets the IP and address of each label. The prefix is prepended to the
+// labels, such as the name of another task of the allocation.
+func (t TaskEnvironment) SetAddrs(prefix, ip string, ports map[string]int) {
+	for label, port := range ports {
+		t[fmt.Sprintf("%s%s%s", IPPrefix, prefix, label)] = ip
+		t[fmt.Sprintf("%s%s%s", AddrPrefix, prefix, label)] = net.JoinHostPort(ip, strconv.Itoa(port))
+	}
+}
+
 func (t TaskEnvironment) ClearPorts() {
 	for k, _ := range t {
-		if strings.HasPrefix(k, PortPrefix) {
-			delete(t, k)
+		for _, prefix := range []string{PortPrefix, HostPortPrefix, IPPrefix, AddrPrefix} {
+			if strings.HasPrefix(k, prefix) {
+				delete(t, k)
+			}
 		}
 	}
 }
@@ -77,3 +77,26 @@ func TestEnvironment_ClearEnvvars(t *testing.T) {
 		t.Fatalf("env.List() returned %v; want %v", act, exp)
 	}
 }
+
+func TestEnvironment_SetAddrs(t *testing.T) {
+	env := NewTaskEnivornment()
+	env.SetHostPorts(map[string]int{"http": 20000})
+	env.SetAddrs("", "10.0.0.1", map[string]int{"http": 20000})
+	env.SetAddrs("web_", "10.0.0.2", map[string]int{"db": 5432})
+
+	exp := map[string]string{
+		"NOMAD_HOST_PORT_http": "20000",
+		"NOMAD_IP_http":        "10.0.0.1",
+		"NOMAD_ADDR_http":      "10.0.0.1:20000",
+		"NOMAD_IP_web_db":      "10.0.0.2",
+		"NOMAD_ADDR_web_db":    "10.0.0.2:5432",
+	}
+	if act := env.Map(); !reflect.DeepEqual(act, exp) {
+		t.Fatalf("env.Map() returned %v; want %v", act, exp)
+	}
+
+	env.ClearPorts()
+	if len(env) != 0 {
+		t.Fatalf("ClearPorts() left %v", env)
+	}
+}
@@ -2,17 +2,14 @@ package driver
 
 import (
 	"fmt"
-	"path/filepath"
+	"os"
 	"runtime"
 	"syscall"
 	"time"
 
-	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
-	"github.com/hashicorp/nomad/client/driver/executor"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -24,15 +21,13 @@ type ExecDriver struct {
 	fingerprint.StaticFingerprinter
 }
 type ExecDriverConfig struct {
-	ArtifactSource string   `mapstructure:"artifact_source"`
-	Checksum       string   `mapstructure:"checksum"`
-	Command        string   `mapstructure:"command"`
-	Args           []string `mapstructure:"args"`
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
 }
 
 // execHandle is returned from Start/Open as a handle to the PID
 type execHandle struct {
-	cmd    executor.Executor
+	cmd    *executorClient
 	waitCh chan *cstructs.WaitResult
 	doneCh chan struct{}
 }
@@ -67,45 +62,28 @@ func (d *ExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 		return nil, fmt.Errorf("missing command for exec driver")
 	}
 
-	// Create a location to download the artifact.
-	taskDir, ok := ctx.AllocDir.TaskDirs[d.DriverContext.taskName]
-	if !ok {
-		return nil, fmt.Errorf("Could not find task directory for task: %v", d.DriverContext.taskName)
-	}
-
-	// Check if an artificat is specified and attempt to download it
-	source, ok := task.Config["artifact_source"]
-	if ok && source != "" {
-		// Proceed to download an artifact to be executed.
-		_, err := getter.GetArtifact(
-			filepath.Join(taskDir, allocdir.TaskLocal),
-			driverConfig.ArtifactSource,
-			driverConfig.Checksum,
-			d.logger,
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// Get the environment variables.
 	envVars := TaskEnvironmentVariables(ctx, task)
 
-	// Setup the command
-	cmd := executor.Command(command, driverConfig.Args...)
-	if err := cmd.Limit(task.Resources); err != nil {
-		return nil, fmt.Errorf("failed to constrain resources: %s", err)
-	}
-
-	// Populate environment variables
-	cmd.Command().Env = envVars.List()
-
-	if err := cmd.ConfigureTaskDir(d.taskName, ctx.AllocDir); err != nil {
-		return nil, fmt.Errorf("failed to configure task directory: %v", err)
+	// Resolve the host volumes to mount into the chroot
+	mounts, err := taskBindMounts(d.config, ctx, task)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start command: %v", err)
+	// Launch the command in its executor process
+	cmd, err := launchExecutor(&d.DriverContext, &ExecutorLaunchArgs{
+		Cmd:       command,
+		Args:      driverConfig.Args,
+		Env:       envVars.List(),
+		TaskName:  d.taskName,
+		AllocDir:  ctx.AllocDir,
+		Resources: task.Resources,
+		Volumes:   mounts,
+		Isolated:  true,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return a driver handle
@@ -119,8 +97,8 @@ func (d *ExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 }
 
 func (d *ExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
-	// Find the process
-	cmd, err := executor.OpenId(handleID)
+	// Reconnect to the executor of the task
+	cmd, err := openExecutor(&d.DriverContext, handleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", handleID, err)
 	}
@@ -136,8 +114,7 @@ func (d *ExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 }
 
 func (h *execHandle) ID() string {
-	id, _ := h.cmd.ID()
-	return id
+	return h.cmd.ID()
 }
 
 func (h *execHandle) WaitCh() chan *cstructs.WaitResult {
@@ -149,6 +126,18 @@ func (h *execHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+func (h *execHandle) Exec(req *cstructs.ExecRequest) (int, error) {
+	return h.cmd.Exec(req)
+}
+
+func (h *execHandle) Signal(sig os.Signal) error {
+	return h.cmd.Signal(sig)
+}
+
+func (h *execHandle) Stats() (*cstructs.TaskResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *execHandle) Kill() error {
 	h.cmd.Shutdown()
 	select {
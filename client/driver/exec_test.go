@@ -120,8 +120,15 @@ func TestExecDriver_Start_Artifact_basic(t *testing.T) {
 	task := &structs.Task{
 		Name: "sleep",
 		Config: map[string]interface{}{
-			"artifact_source": fmt.Sprintf("https://dl.dropboxusercontent.com/u/47675/jar_thing/%s?checksum=%s", file, checksum),
-			"command":         filepath.Join("$NOMAD_TASK_DIR", file),
+			"command": filepath.Join("$NOMAD_TASK_DIR", file),
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: fmt.Sprintf("https://dl.dropboxusercontent.com/u/47675/jar_thing/%s", file),
+				GetterOptions: map[string]string{
+					"checksum": checksum,
+				},
+			},
 		},
 		Resources: basicResources,
 	}
@@ -164,13 +171,17 @@ func TestExecDriver_Start_Artifact_expanded(t *testing.T) {
 	task := &structs.Task{
 		Name: "sleep",
 		Config: map[string]interface{}{
-			"artifact_source": fmt.Sprintf("https://dl.dropboxusercontent.com/u/47675/jar_thing/%s", file),
-			"command":         "/bin/bash",
+			"command": "/bin/bash",
 			"args": []string{
 				"-c",
 				fmt.Sprintf(`/bin/sleep 1 && %s`, filepath.Join("$NOMAD_TASK_DIR", file)),
 			},
 		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: fmt.Sprintf("https://dl.dropboxusercontent.com/u/47675/jar_thing/%s", file),
+			},
+		},
 		Resources: basicResources,
 	}
 
@@ -22,6 +22,7 @@ package executor
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 
@@ -46,16 +47,23 @@ type Executor interface {
 	// directory is properly configured.
 	ConfigureTaskDir(taskName string, alloc *allocdir.AllocDir) error
 
+	// MountVolumes must be called after ConfigureTaskDir and before Start and
+	// mounts the directories of the host into the task's filesystem. They
+	// are unmounted once the process exits.
+	MountVolumes(mounts []*cstructs.BindMount) error
+
 	// Start the process. This may wrap the actual process in another command,
 	// depending on the capabilities in this environment. Errors that arise from
 	// Limits or Runas may bubble through Start()
 	Start() error
 
-	// Open should be called to restore a previous execution. This might be needed if
-	// nomad is restarted.
+	// Open restores the executor of a running process from its ID so that
+	// the process can be signalled, inspected and exec'd into. The process
+	// can only be waited on by the executor that started it.
 	Open(string) error
 
-	// Wait waits till the user's command is completed.
+	// Wait waits till the user's command is completed and returns its exit
+	// code or the signal that killed it.
 	Wait() *cstructs.WaitResult
 
 	// Returns a handle that is executor specific for use in reopening.
@@ -70,9 +78,21 @@ type Executor interface {
 	// implementations must provide this.
 	ForceStop() error
 
+	// Signal sends the signal to the user process.
+	Signal(sig os.Signal) error
+
+	// Stats returns the resource usage of the user process. CPU percentages
+	// are relative to the previous call.
+	Stats() (*cstructs.TaskResourceUsage, error)
+
 	// Command provides access the underlying Cmd struct in case the Executor
 	// interface doesn't expose the functionality you need.
 	Command() *exec.Cmd
+
+	// Exec runs an additional command inside the same isolation as the
+	// running user process and blocks until it exits. The exit code of the
+	// command is returned.
+	Exec(req *cstructs.ExecRequest) (int, error)
 }
 
 // Command is a mirror of exec.Command that returns a platform-specific Executor
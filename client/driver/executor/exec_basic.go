@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/process"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/driver/environment"
-	"github.com/hashicorp/nomad/client/driver/spawn"
+	"github.com/hashicorp/nomad/client/stats"
 	"github.com/hashicorp/nomad/helper/args"
 	"github.com/hashicorp/nomad/nomad/structs"
 
@@ -23,10 +25,15 @@ import (
 // any resource restrictions or runas capabilities.
 type BasicExecutor struct {
 	cmd      exec.Cmd
-	spawn    *spawn.Spawner
+	pid      int
+	logs     *taskLogs
 	taskName string
 	taskDir  string
-	allocDir string
+
+	// CPU usage calculators used by Stats.
+	totalCpuStats  *stats.CpuStats
+	userCpuStats   *stats.CpuStats
+	systemCpuStats *stats.CpuStats
 }
 
 func NewBasicExecutor() Executor {
@@ -49,7 +56,15 @@ func (e *BasicExecutor) ConfigureTaskDir(taskName string, alloc *allocdir.AllocD
 
 	e.taskDir = taskDir
 	e.taskName = taskName
-	e.allocDir = alloc.AllocDir
+	return nil
+}
+
+// MountVolumes fails if any volumes are mounted as the basic executor doesn't
+// isolate the filesystem of the task.
+func (e *BasicExecutor) MountVolumes(mounts []*cstructs.BindMount) error {
+	if len(mounts) != 0 {
+		return fmt.Errorf("volume mounts are not supported by this executor")
+	}
 	return nil
 }
 
@@ -64,52 +79,102 @@ func (e *BasicExecutor) Start() error {
 	e.cmd.Path = args.ReplaceEnv(e.cmd.Path, envVars.Map())
 	e.cmd.Args = args.ParseAndReplace(e.cmd.Args, envVars.Map())
 
-	spawnState := filepath.Join(e.allocDir, fmt.Sprintf("%s_%s", e.taskName, "exit_status"))
-	e.spawn = spawn.NewSpawner(spawnState)
-	e.spawn.SetCommand(&e.cmd)
-	e.spawn.SetLogs(&spawn.Logs{
-		Stdout: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", e.taskName)),
-		Stderr: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stderr", e.taskName)),
-		Stdin:  os.DevNull,
-	})
+	logs, err := newTaskLogs(&e.cmd, e.taskDir, e.taskName)
+	if err != nil {
+		return err
+	}
+
+	if err := e.cmd.Start(); err != nil {
+		logs.Close()
+		return err
+	}
+	logs.Started()
+
+	e.logs = logs
+	e.pid = e.cmd.Process.Pid
+	return nil
+}
 
-	return e.spawn.Spawn(nil)
+// ExecBasicID contains the necessary information to act on a process started
+// by a BasicExecutor.
+type ExecBasicID struct {
+	UserPid int
+	Dir     string
+	Env     []string
 }
 
+// Open restores the executor of a running process. Only the executor that
+// started the process can wait on it.
 func (e *BasicExecutor) Open(id string) error {
-	var spawn spawn.Spawner
+	var execID ExecBasicID
 	dec := json.NewDecoder(strings.NewReader(id))
-	if err := dec.Decode(&spawn); err != nil {
+	if err := dec.Decode(&execID); err != nil {
 		return fmt.Errorf("Failed to parse id: %v", err)
 	}
 
+	if !processAlive(execID.UserPid) {
+		return fmt.Errorf("User process %v is not running", execID.UserPid)
+	}
+
 	// Setup the executor.
-	e.spawn = &spawn
-	return e.spawn.Valid()
+	e.pid = execID.UserPid
+	e.cmd.Dir = execID.Dir
+	e.cmd.Env = execID.Env
+	return nil
 }
 
+// Wait waits for the user process to exit and returns its exit code or the
+// signal that killed it.
 func (e *BasicExecutor) Wait() *cstructs.WaitResult {
-	return e.spawn.Wait()
+	if e.cmd.Process == nil {
+		return cstructs.NewWaitResult(-1, 0, fmt.Errorf("Process was not started by this executor"))
+	}
+
+	res := exitResult(e.cmd.Wait())
+	e.logs.Close()
+	return res
 }
 
 func (e *BasicExecutor) ID() (string, error) {
-	if e.spawn == nil {
+	if e.pid == 0 {
 		return "", fmt.Errorf("Process was never started")
 	}
 
+	id := ExecBasicID{
+		UserPid: e.pid,
+		Dir:     e.cmd.Dir,
+		Env:     e.cmd.Env,
+	}
+
 	var buffer bytes.Buffer
 	enc := json.NewEncoder(&buffer)
-	if err := enc.Encode(e.spawn); err != nil {
+	if err := enc.Encode(id); err != nil {
 		return "", fmt.Errorf("Failed to serialize id: %v", err)
 	}
 
 	return buffer.String(), nil
 }
 
+// Exec runs the command in the task directory with the environment of the
+// user process. No additional isolation is applied.
+func (e *BasicExecutor) Exec(req *cstructs.ExecRequest) (int, error) {
+	if err := req.Validate(); err != nil {
+		return -1, err
+	}
+	if e.pid == 0 {
+		return -1, fmt.Errorf("Process was never started")
+	}
+
+	cmd := exec.Command(req.Cmd[0], req.Cmd[1:]...)
+	cmd.Dir = e.cmd.Dir
+	cmd.Env = e.cmd.Env
+	return runExecCmd(cmd, req, nil)
+}
+
 func (e *BasicExecutor) Shutdown() error {
-	proc, err := os.FindProcess(e.spawn.UserPid)
+	proc, err := os.FindProcess(e.pid)
 	if err != nil {
-		return fmt.Errorf("Failed to find user processes %v: %v", e.spawn.UserPid, err)
+		return fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
 	}
 
 	if runtime.GOOS == "windows" {
@@ -120,14 +185,69 @@ func (e *BasicExecutor) Shutdown() error {
 }
 
 func (e *BasicExecutor) ForceStop() error {
-	proc, err := os.FindProcess(e.spawn.UserPid)
+	proc, err := os.FindProcess(e.pid)
 	if err != nil {
-		return fmt.Errorf("Failed to find user processes %v: %v", e.spawn.UserPid, err)
+		return fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
 	}
 
 	return proc.Kill()
 }
 
+func (e *BasicExecutor) Signal(sig os.Signal) error {
+	proc, err := os.FindProcess(e.pid)
+	if err != nil {
+		return fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
+	}
+
+	return proc.Signal(sig)
+}
+
+// Stats returns the resource usage of the user process. Processes it spawns
+// are not accounted for.
+func (e *BasicExecutor) Stats() (*cstructs.TaskResourceUsage, error) {
+	if e.pid == 0 {
+		return nil, fmt.Errorf("Process was never started")
+	}
+
+	proc, err := process.NewProcess(int32(e.pid))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
+	}
+	memInfo, err := proc.MemoryInfo()
+	if err != nil {
+		return nil, err
+	}
+	cpuTimes, err := proc.CPUTimes()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.totalCpuStats == nil {
+		e.totalCpuStats = stats.NewCpuStats()
+		e.userCpuStats = stats.NewCpuStats()
+		e.systemCpuStats = stats.NewCpuStats()
+	}
+
+	// CPU times are reported in seconds
+	user := cpuTimes.User * float64(time.Second)
+	system := cpuTimes.System * float64(time.Second)
+	ms := &cstructs.MemoryStats{
+		RSS:      memInfo.RSS,
+		Swap:     memInfo.Swap,
+		Measured: []string{"RSS", "Swap"},
+	}
+	cs := &cstructs.CpuStats{
+		SystemMode: e.systemCpuStats.Percent(system),
+		UserMode:   e.userCpuStats.Percent(user),
+		Percent:    e.totalCpuStats.Percent(user + system),
+		Measured:   []string{"System Mode", "User Mode", "Percent"},
+	}
+	return &cstructs.TaskResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{MemoryStats: ms, CpuStats: cs},
+		Timestamp:     time.Now().UTC().UnixNano(),
+	}, nil
+}
+
 func (e *BasicExecutor) Command() *exec.Cmd {
 	return &e.cmd
 }
@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// execSearchPath is the set of directories searched for a command given
+// without a path when exec'ing into a chrooted task.
+var execSearchPath = []string{"/usr/local/sbin", "/usr/local/bin", "/usr/sbin", "/usr/bin", "/sbin", "/bin"}
+
+// lookPathIn resolves name to an absolute path inside root. Names that
+// already contain a path separator are returned unchanged.
+func lookPathIn(root, name string) (string, error) {
+	if filepath.Base(name) != name {
+		return name, nil
+	}
+
+	for _, dir := range execSearchPath {
+		path := filepath.Join(dir, name)
+		fi, err := os.Stat(filepath.Join(root, path))
+		if err != nil || fi.IsDir() || fi.Mode()&0111 == 0 {
+			continue
+		}
+		return path, nil
+	}
+	return "", fmt.Errorf("executable %q not found in task", name)
+}
+
+// runExecCmd starts cmd wired to the streams of req, invokes the optional
+// started call-back with the pid of the command and waits for it to exit. If
+// a TTY is requested the command is attached to a new pseudo-terminal.
+func runExecCmd(cmd *exec.Cmd, req *cstructs.ExecRequest, started func(pid int) error) (int, error) {
+	if err := req.Validate(); err != nil {
+		return -1, err
+	}
+
+	var wg sync.WaitGroup
+	var master *os.File
+	if req.Tty {
+		m, slave, err := openPty()
+		if err != nil {
+			return -1, fmt.Errorf("failed to allocate tty: %v", err)
+		}
+		defer m.Close()
+		master = m
+
+		cmd.Stdin = slave
+		cmd.Stdout = slave
+		cmd.Stderr = slave
+		setControllingTty(cmd)
+
+		if err := cmd.Start(); err != nil {
+			slave.Close()
+			return -1, err
+		}
+		slave.Close()
+
+		if req.Stdin != nil {
+			go io.Copy(master, req.Stdin)
+		}
+		if req.ResizeCh != nil {
+			go func() {
+				for size := range req.ResizeCh {
+					setWinsize(master, size)
+				}
+			}()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			io.Copy(req.Stdout, master)
+		}()
+	} else {
+		if req.Stdin != nil {
+			stdin, err := cmd.StdinPipe()
+			if err != nil {
+				return -1, err
+			}
+			go func() {
+				io.Copy(stdin, req.Stdin)
+				stdin.Close()
+			}()
+		}
+		cmd.Stdout = req.Stdout
+		cmd.Stderr = req.Stderr
+
+		if err := cmd.Start(); err != nil {
+			return -1, err
+		}
+	}
+
+	if started != nil {
+		if err := started(cmd.Process.Pid); err != nil {
+			cmd.Process.Kill()
+			cmd.Wait()
+			return -1, err
+		}
+	}
+
+	err := cmd.Wait()
+
+	// Closing the master unblocks the output copy on platforms that don't
+	// return EOF once the last slave descriptor is closed.
+	if master != nil {
+		master.Close()
+	}
+	wg.Wait()
+
+	if err == nil {
+		return 0, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			return status.ExitStatus(), nil
+		}
+	}
+	return -1, err
+}
+
+// exitResult converts the error returned by waiting on the user process into
+// a WaitResult. A process killed by a signal reports the signal.
+func exitResult(err error) *cstructs.WaitResult {
+	if err == nil {
+		return cstructs.NewWaitResult(0, 0, nil)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok {
+			if status.Signaled() {
+				return cstructs.NewWaitResult(0, int(status.Signal()), nil)
+			}
+			return cstructs.NewWaitResult(status.ExitStatus(), 0, nil)
+		}
+	}
+	return cstructs.NewWaitResult(-1, 0, err)
+}
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/opencontainers/runc/libcontainer/cgroups"
@@ -21,8 +22,8 @@ import (
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/driver/environment"
-	"github.com/hashicorp/nomad/client/driver/spawn"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+	"github.com/hashicorp/nomad/client/stats"
 	"github.com/hashicorp/nomad/helper/args"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -58,10 +59,16 @@ type LinuxExecutor struct {
 	groups   *cgroupConfig.Cgroup
 	taskName string
 	taskDir  string
-	allocDir string
+	volumes  []string
 
-	// Spawn process.
-	spawn *spawn.Spawner
+	// User process.
+	pid  int
+	logs *taskLogs
+
+	// CPU usage calculators used by Stats.
+	totalCpuStats  *stats.CpuStats
+	userCpuStats   *stats.CpuStats
+	systemCpuStats *stats.CpuStats
 }
 
 func (e *LinuxExecutor) Command() *exec.Cmd {
@@ -76,14 +83,17 @@ func (e *LinuxExecutor) Limit(resources *structs.Resources) error {
 	return e.configureCgroups(resources)
 }
 
-// execLinuxID contains the necessary information to reattach to an executed
-// process and cleanup the created cgroups.
+// ExecLinuxID contains the necessary information to act on a process started
+// by a LinuxExecutor and cleanup the created cgroups.
 type ExecLinuxID struct {
 	Groups  *cgroupConfig.Cgroup
-	Spawn   *spawn.Spawner
+	UserPid int
 	TaskDir string
+	Env     []string
 }
 
+// Open restores the executor of a running process. Only the executor that
+// started the process can wait on it.
 func (e *LinuxExecutor) Open(id string) error {
 	// De-serialize the ID.
 	dec := json.NewDecoder(strings.NewReader(id))
@@ -92,23 +102,29 @@ func (e *LinuxExecutor) Open(id string) error {
 		return fmt.Errorf("Failed to parse id: %v", err)
 	}
 
+	if !processAlive(execID.UserPid) {
+		return fmt.Errorf("User process %v is not running", execID.UserPid)
+	}
+
 	// Setup the executor.
 	e.groups = execID.Groups
-	e.spawn = execID.Spawn
+	e.pid = execID.UserPid
 	e.taskDir = execID.TaskDir
-	return e.spawn.Valid()
+	e.cmd.Env = execID.Env
+	return nil
 }
 
 func (e *LinuxExecutor) ID() (string, error) {
-	if e.groups == nil || e.spawn == nil || e.taskDir == "" {
+	if e.groups == nil || e.pid == 0 || e.taskDir == "" {
 		return "", fmt.Errorf("LinuxExecutor not properly initialized.")
 	}
 
 	// Build the ID.
 	id := ExecLinuxID{
 		Groups:  e.groups,
-		Spawn:   e.spawn,
+		UserPid: e.pid,
 		TaskDir: e.taskDir,
+		Env:     e.cmd.Env,
 	}
 
 	var buffer bytes.Buffer
@@ -123,35 +139,48 @@ func (e *LinuxExecutor) ID() (string, error) {
 // runAs takes a user id as a string and looks up the user, and sets the command
 // to execute as that user.
 func (e *LinuxExecutor) runAs(userid string) error {
+	cred, err := lookupCredential(userid)
+	if err != nil {
+		return err
+	}
+
+	// Set the command to run as that user and group.
+	if e.cmd.SysProcAttr == nil {
+		e.cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	e.cmd.SysProcAttr.Credential = cred
+	return nil
+}
+
+// lookupCredential looks up the user by name and returns the credential
+// needed to run a process as that user.
+func lookupCredential(userid string) (*syscall.Credential, error) {
 	u, err := user.Lookup(userid)
 	if err != nil {
-		return fmt.Errorf("Failed to identify user %v: %v", userid, err)
+		return nil, fmt.Errorf("Failed to identify user %v: %v", userid, err)
 	}
 
 	// Convert the uid and gid
 	uid, err := strconv.ParseUint(u.Uid, 10, 32)
 	if err != nil {
-		return fmt.Errorf("Unable to convert userid to uint32: %s", err)
+		return nil, fmt.Errorf("Unable to convert userid to uint32: %s", err)
 	}
 	gid, err := strconv.ParseUint(u.Gid, 10, 32)
 	if err != nil {
-		return fmt.Errorf("Unable to convert groupid to uint32: %s", err)
-	}
-
-	// Set the command to run as that user and group.
-	if e.cmd.SysProcAttr == nil {
-		e.cmd.SysProcAttr = &syscall.SysProcAttr{}
-	}
-	if e.cmd.SysProcAttr.Credential == nil {
-		e.cmd.SysProcAttr.Credential = &syscall.Credential{}
+		return nil, fmt.Errorf("Unable to convert groupid to uint32: %s", err)
 	}
-	e.cmd.SysProcAttr.Credential.Uid = uint32(uid)
-	e.cmd.SysProcAttr.Credential.Gid = uint32(gid)
 
-	return nil
+	return &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
 }
 
-func (e *LinuxExecutor) Start() error {
+func (e *LinuxExecutor) Start() (err error) {
+	// The volumes would outlive the task if it can't be started.
+	defer func() {
+		if err != nil {
+			e.unmountVolumes()
+		}
+	}()
+
 	// Run as "nobody" user so we don't leak root privilege to the spawned
 	// process.
 	if err := e.runAs("nobody"); err != nil {
@@ -168,36 +197,46 @@ func (e *LinuxExecutor) Start() error {
 	e.cmd.Path = args.ReplaceEnv(e.cmd.Path, envVars.Map())
 	e.cmd.Args = args.ParseAndReplace(e.cmd.Args, envVars.Map())
 
-	spawnState := filepath.Join(e.allocDir, fmt.Sprintf("%s_%s", e.taskName, "exit_status"))
-	e.spawn = spawn.NewSpawner(spawnState)
-	e.spawn.SetCommand(&e.cmd)
-	e.spawn.SetChroot(e.taskDir)
-	e.spawn.SetLogs(&spawn.Logs{
-		Stdout: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", e.taskName)),
-		Stderr: filepath.Join(e.taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stderr", e.taskName)),
-		Stdin:  os.DevNull,
-	})
-
-	enterCgroup := func(pid int) error {
-		// Join the spawn-daemon to the cgroup.
-		manager := e.getCgroupManager(e.groups)
+	// Chroot jail the process and set its working directory.
+	e.cmd.SysProcAttr.Chroot = e.taskDir
+	e.cmd.Dir = "/"
 
-		// Apply will place the spawn dameon into the created cgroups.
-		if err := manager.Apply(pid); err != nil {
-			return fmt.Errorf("Failed to join spawn-daemon to the cgroup (%+v): %v", e.groups, err)
-		}
+	logs, err := newTaskLogs(&e.cmd, e.taskDir, e.taskName)
+	if err != nil {
+		return err
+	}
 
-		return nil
+	if err := e.cmd.Start(); err != nil {
+		logs.Close()
+		return fmt.Errorf("Error starting user command: %v", err)
+	}
+	logs.Started()
+	e.logs = logs
+	e.pid = e.cmd.Process.Pid
+
+	// Place the user process into the created cgroups.
+	manager := e.getCgroupManager(e.groups)
+	if err := manager.Apply(e.pid); err != nil {
+		e.cmd.Process.Kill()
+		e.cmd.Wait()
+		logs.Close()
+		e.pid = 0
+		return fmt.Errorf("Failed to join user process to the cgroup (%+v): %v", e.groups, err)
 	}
 
-	return e.spawn.Spawn(enterCgroup)
+	return nil
 }
 
-// Wait waits til the user process exits and returns an error on non-zero exit
-// codes. Wait also cleans up the task directory and created cgroups.
+// Wait waits til the user process exits and returns its exit code or the
+// signal that killed it. Wait also cleans up the task directory and created
+// cgroups.
 func (e *LinuxExecutor) Wait() *cstructs.WaitResult {
+	if e.cmd.Process == nil {
+		return cstructs.NewWaitResult(-1, 0, fmt.Errorf("Process was not started by this executor"))
+	}
+
 	errs := new(multierror.Error)
-	res := e.spawn.Wait()
+	res := exitResult(e.cmd.Wait())
 	if res.Err != nil {
 		errs = multierror.Append(errs, res.Err)
 	}
@@ -210,16 +249,59 @@ func (e *LinuxExecutor) Wait() *cstructs.WaitResult {
 		errs = multierror.Append(errs, err)
 	}
 
+	e.logs.Close()
 	res.Err = errs.ErrorOrNil()
 	return res
 }
 
+// Exec runs the command chrooted into the task directory as the same
+// unprivileged user and places it into the task's cgroup.
+func (e *LinuxExecutor) Exec(req *cstructs.ExecRequest) (int, error) {
+	if err := req.Validate(); err != nil {
+		return -1, err
+	}
+	if e.groups == nil || e.pid == 0 || e.taskDir == "" {
+		return -1, fmt.Errorf("LinuxExecutor not properly initialized.")
+	}
+
+	path, err := lookPathIn(e.taskDir, req.Cmd[0])
+	if err != nil {
+		return -1, err
+	}
+
+	cred, err := lookupCredential("nobody")
+	if err != nil {
+		return -1, err
+	}
+
+	cmd := &exec.Cmd{
+		Path: path,
+		Args: req.Cmd,
+		Dir:  "/",
+		SysProcAttr: &syscall.SysProcAttr{
+			Chroot:     e.taskDir,
+			Credential: cred,
+		},
+	}
+	cmd.Env = e.cmd.Env
+
+	joinCgroup := func(pid int) error {
+		manager := e.getCgroupManager(e.groups)
+		if err := manager.Apply(pid); err != nil {
+			return fmt.Errorf("Failed to join exec process to the cgroup (%+v): %v", e.groups, err)
+		}
+		return nil
+	}
+
+	return runExecCmd(cmd, req, joinCgroup)
+}
+
 // Shutdown sends the user process an interrupt signal indicating that it is
 // about to be forcefully shutdown in sometime
 func (e *LinuxExecutor) Shutdown() error {
-	proc, err := os.FindProcess(e.spawn.UserPid)
+	proc, err := os.FindProcess(e.pid)
 	if err != nil {
-		return fmt.Errorf("Failed to find user processes %v: %v", e.spawn.UserPid, err)
+		return fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
 	}
 
 	return proc.Signal(os.Interrupt)
@@ -240,13 +322,22 @@ func (e *LinuxExecutor) ForceStop() error {
 	return errs.ErrorOrNil()
 }
 
+// Signal sends the signal to the user process.
+func (e *LinuxExecutor) Signal(sig os.Signal) error {
+	proc, err := os.FindProcess(e.pid)
+	if err != nil {
+		return fmt.Errorf("Failed to find user processes %v: %v", e.pid, err)
+	}
+
+	return proc.Signal(sig)
+}
+
 // Task Directory related functions.
 
 // ConfigureTaskDir creates the necessary directory structure for a proper
 // chroot. cleanTaskDir should be called after.
 func (e *LinuxExecutor) ConfigureTaskDir(taskName string, alloc *allocdir.AllocDir) error {
 	e.taskName = taskName
-	e.allocDir = alloc.AllocDir
 
 	taskDir, ok := alloc.TaskDirs[taskName]
 	if !ok {
@@ -293,11 +384,70 @@ func (e *LinuxExecutor) ConfigureTaskDir(taskName string, alloc *allocdir.AllocD
 	}
 	env.SetAllocDir(filepath.Join("/", allocdir.SharedAllocName))
 	env.SetTaskLocalDir(filepath.Join("/", allocdir.TaskLocal))
+	env.SetSecretsDir(filepath.Join("/", allocdir.TaskSecrets))
 	e.cmd.Env = env.List()
 
 	return nil
 }
 
+// MountVolumes bind mounts the directories of the host into the task's chroot.
+func (e *LinuxExecutor) MountVolumes(mounts []*cstructs.BindMount) error {
+	if e.taskDir == "" {
+		return fmt.Errorf("task directory must be configured before mounting volumes")
+	}
+
+	for _, m := range mounts {
+		dest := filepath.Join(e.taskDir, m.TaskPath)
+		if err := os.MkdirAll(dest, 0777); err != nil {
+			return fmt.Errorf("Mkdir(%v) failed: %v", dest, err)
+		}
+
+		// Don't follow links out of the chroot.
+		resolved, err := filepath.EvalSymlinks(dest)
+		if err != nil {
+			return err
+		}
+		taskDir, err := filepath.EvalSymlinks(e.taskDir)
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(resolved, taskDir+string(filepath.Separator)) {
+			return fmt.Errorf("Volume destination %v escapes the task directory", m.TaskPath)
+		}
+
+		if err := syscall.Mount(m.HostPath, resolved, "", syscall.MS_BIND, ""); err != nil {
+			e.unmountVolumes()
+			return fmt.Errorf("Couldn't mount %v to %v: %v", m.HostPath, resolved, err)
+		}
+		e.volumes = append(e.volumes, resolved)
+
+		// The read-only flag is ignored when the bind mount is created so it
+		// is applied by remounting it.
+		if m.ReadOnly {
+			flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+			if err := syscall.Mount("", resolved, "", flags, ""); err != nil {
+				e.unmountVolumes()
+				return fmt.Errorf("Couldn't remount %v read-only: %v", resolved, err)
+			}
+		}
+	}
+	return nil
+}
+
+// unmountVolumes unmounts the volumes in reverse order as they may be nested.
+// The directories are left in place so the data of the host can't be removed
+// along with the task directory.
+func (e *LinuxExecutor) unmountVolumes() error {
+	errs := new(multierror.Error)
+	for i := len(e.volumes) - 1; i >= 0; i-- {
+		if err := syscall.Unmount(e.volumes[i], 0); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("Failed to unmount volume (%v): %v", e.volumes[i], err))
+		}
+	}
+	e.volumes = nil
+	return errs.ErrorOrNil()
+}
+
 // pathExists is a helper function to check if the path exists.
 func (e *LinuxExecutor) pathExists(path string) bool {
 	if _, err := os.Stat(path); err != nil {
@@ -311,8 +461,13 @@ func (e *LinuxExecutor) pathExists(path string) bool {
 // cleanTaskDir is an idempotent operation to clean the task directory and
 // should be called when tearing down the task.
 func (e *LinuxExecutor) cleanTaskDir() error {
-	// Unmount dev.
+	// Unmount the volumes.
 	errs := new(multierror.Error)
+	if err := e.unmountVolumes(); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	// Unmount dev.
 	dev := filepath.Join(e.taskDir, "dev")
 	if e.pathExists(dev) {
 		if err := syscall.Unmount(dev, 0); err != nil {
@@ -416,6 +571,49 @@ func (e *LinuxExecutor) destroyCgroup() error {
 	return nil
 }
 
+// Stats returns the resource usage of the task's cgroup.
+func (e *LinuxExecutor) Stats() (*cstructs.TaskResourceUsage, error) {
+	if e.groups == nil {
+		return nil, errors.New("Can't get stats: cgroup configuration empty")
+	}
+
+	manager := e.getCgroupManager(e.groups)
+	cgStats, err := manager.GetStats()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get stats of the cgroup %v: %v", e.groups.Name, err)
+	}
+
+	if e.totalCpuStats == nil {
+		e.totalCpuStats = stats.NewCpuStats()
+		e.userCpuStats = stats.NewCpuStats()
+		e.systemCpuStats = stats.NewCpuStats()
+	}
+
+	mem := cgStats.MemoryStats
+	ms := &cstructs.MemoryStats{
+		RSS:      mem.Stats["rss"],
+		Cache:    mem.Stats["cache"],
+		Swap:     mem.SwapUsage.Usage,
+		MaxUsage: mem.Usage.MaxUsage,
+		Measured: []string{"RSS", "Cache", "Swap", "Max Usage"},
+	}
+
+	// CPU usage is reported in nanoseconds
+	cpu := cgStats.CpuStats
+	cs := &cstructs.CpuStats{
+		SystemMode:       e.systemCpuStats.Percent(float64(cpu.CpuUsage.UsageInKernelmode)),
+		UserMode:         e.userCpuStats.Percent(float64(cpu.CpuUsage.UsageInUsermode)),
+		Percent:          e.totalCpuStats.Percent(float64(cpu.CpuUsage.TotalUsage)),
+		ThrottledPeriods: cpu.ThrottlingData.ThrottledPeriods,
+		ThrottledTime:    cpu.ThrottlingData.ThrottledTime,
+		Measured:         []string{"System Mode", "User Mode", "Percent", "Throttled Periods", "Throttled Time"},
+	}
+	return &cstructs.TaskResourceUsage{
+		ResourceUsage: &cstructs.ResourceUsage{MemoryStats: ms, CpuStats: cs},
+		Timestamp:     time.Now().UTC().UnixNano(),
+	}, nil
+}
+
 // getCgroupManager returns the correct libcontainer cgroup manager.
 func (e *LinuxExecutor) getCgroupManager(groups *cgroupConfig.Cgroup) cgroups.Manager {
 	var manager cgroups.Manager
@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+)
+
+const (
+	// defaultMaxLogFiles is the number of rotated log files kept per stream
+	// of the user process.
+	defaultMaxLogFiles = 10
+
+	// defaultMaxLogFileSize is the size in bytes a log file may grow to
+	// before it is rotated.
+	defaultMaxLogFileSize = 10 * 1024 * 1024
+
+	// logDrainTimeout is how long the output of the user process is drained
+	// after it exits. Processes it started may still hold the log pipes.
+	logDrainTimeout = 2 * time.Second
+)
+
+var errLogClosed = errors.New("log file closed")
+
+// logRotator is an io.WriteCloser writing to a log file that is rotated once
+// it grows past maxSize. The rotated files are kept as path.1 to
+// path.maxFiles, path.1 being the most recent.
+type logRotator struct {
+	path     string
+	maxFiles int
+	maxSize  int64
+
+	f    *os.File
+	size int64
+	lock sync.Mutex
+}
+
+// newLogRotator opens the log file at path, appending to it if it exists.
+func newLogRotator(path string, maxFiles int, maxSize int64) (*logRotator, error) {
+	r := &logRotator{path: path, maxFiles: maxFiles, maxSize: maxSize}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *logRotator) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("Error opening log file %v: %v", r.path, err)
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("Failed to stat log file %v: %v", r.path, err)
+	}
+	r.f = f
+	r.size = fi.Size()
+	return nil
+}
+
+func (r *logRotator) Write(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.f == nil {
+		return 0, errLogClosed
+	}
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts the rotated files by one, moves the current file to path.1
+// and reopens an empty file at path.
+func (r *logRotator) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("Failed to close log file %v: %v", r.path, err)
+	}
+	r.f = nil
+
+	if r.maxFiles > 0 {
+		os.Remove(r.rotatedPath(r.maxFiles))
+		for i := r.maxFiles - 1; i > 0; i-- {
+			if err := os.Rename(r.rotatedPath(i), r.rotatedPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("Failed to rotate log file %v: %v", r.rotatedPath(i), err)
+			}
+		}
+		if err := os.Rename(r.path, r.rotatedPath(1)); err != nil {
+			return fmt.Errorf("Failed to rotate log file %v: %v", r.path, err)
+		}
+	} else if err := os.Remove(r.path); err != nil {
+		return fmt.Errorf("Failed to truncate log file %v: %v", r.path, err)
+	}
+
+	return r.open()
+}
+
+func (r *logRotator) rotatedPath(i int) string {
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+func (r *logRotator) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+// taskLogs copies the stdout and stderr of a user process to rotated log
+// files in the local directory of its task.
+type taskLogs struct {
+	stdout, stderr *logRotator
+
+	// The read ends are copied to the log files and the write ends are
+	// handed to the user process.
+	readers []*os.File
+	writers []*os.File
+	wg      sync.WaitGroup
+}
+
+// newTaskLogs opens the log files of the task and redirects the output of
+// cmd to them.
+func newTaskLogs(cmd *exec.Cmd, taskDir, taskName string) (*taskLogs, error) {
+	l := &taskLogs{}
+	base := filepath.Join(taskDir, allocdir.TaskLocal, taskName)
+
+	var err error
+	if l.stdout, err = newLogRotator(base+".stdout", defaultMaxLogFiles, defaultMaxLogFileSize); err != nil {
+		return nil, err
+	}
+	if l.stderr, err = newLogRotator(base+".stderr", defaultMaxLogFiles, defaultMaxLogFileSize); err != nil {
+		l.stdout.Close()
+		return nil, err
+	}
+
+	for _, log := range []*logRotator{l.stdout, l.stderr} {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("Failed to create log pipe: %v", err)
+		}
+		l.readers = append(l.readers, pr)
+		l.writers = append(l.writers, pw)
+
+		l.wg.Add(1)
+		go func(log *logRotator, pr *os.File) {
+			defer l.wg.Done()
+			io.Copy(log, pr)
+		}(log, pr)
+	}
+
+	// Handing the process files rather than writers keeps exec from copying
+	// the output itself and waiting on it.
+	cmd.Stdout = l.writers[0]
+	cmd.Stderr = l.writers[1]
+	return l, nil
+}
+
+// Started closes the write ends of the pipes once the user process holds
+// them, so that the copies end when the process exits.
+func (l *taskLogs) Started() {
+	for _, w := range l.writers {
+		w.Close()
+	}
+	l.writers = nil
+}
+
+// Close drains the remaining output of the user process and closes the log
+// files.
+func (l *taskLogs) Close() error {
+	l.Started()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(logDrainTimeout):
+	}
+
+	for _, r := range l.readers {
+		r.Close()
+	}
+	l.stdout.Close()
+	l.stderr.Close()
+	return nil
+}
@@ -0,0 +1,86 @@
+package executor
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogRotator_Rotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "task.stdout")
+	r, err := newLogRotator(path, 2, 10)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer r.Close()
+
+	// Each write fills a file, so every write after the first rotates.
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(r, "line %04d\n", i); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "line 0003\n",
+		path + ".1": "line 0002\n",
+		path + ".2": "line 0001\n",
+	}
+	for p, exp := range expected {
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if string(data) != exp {
+			t.Fatalf("bad contents of %v: %q; want %q", p, data, exp)
+		}
+	}
+
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("only %d rotated files should be kept: %v", 2, err)
+	}
+}
+
+func TestLogRotator_Append(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "task.stdout")
+	if err := ioutil.WriteFile(path, []byte("foo"), 0666); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	r, err := newLogRotator(path, 2, 10)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := r.Write([]byte("bar")); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(data) != "foobar" {
+		t.Fatalf("bad contents: %q", data)
+	}
+
+	if _, err := r.Write([]byte("baz")); err != errLogClosed {
+		t.Fatalf("write after close should fail: %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package executor
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive returns whether the process with the given pid is running.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
+}
@@ -0,0 +1,21 @@
+package executor
+
+import "syscall"
+
+const stillActive = 259
+
+// processAlive returns whether the process with the given pid is running.
+func processAlive(pid int) bool {
+	const da = syscall.STANDARD_RIGHTS_READ | syscall.PROCESS_QUERY_INFORMATION | syscall.SYNCHRONIZE
+	h, e := syscall.OpenProcess(da, false, uint32(pid))
+	if e != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+
+	var ec uint32
+	if e := syscall.GetExitCodeProcess(h, &ec); e != nil {
+		return false
+	}
+	return ec == stillActive
+}
@@ -0,0 +1,21 @@
+// +build !linux
+
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+func openPty() (*os.File, *os.File, error) {
+	return nil, nil, fmt.Errorf("tty allocation is not supported on this platform")
+}
+
+func setWinsize(f *os.File, size cstructs.TerminalSize) error {
+	return nil
+}
+
+func setControllingTty(cmd *exec.Cmd) {}
@@ -1,10 +1,14 @@
 package executor
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -63,6 +67,8 @@ func testExecutor(t *testing.T, buildExecutor func() Executor, compatible func(*
 	Executor_Start_Kill(t, command)
 	Executor_Open(t, command, buildExecutor)
 	Executor_Open_Invalid(t, command, buildExecutor)
+	Executor_Start_Logs(t, command)
+	Executor_Start_Signal(t, command)
 }
 
 type buildExecCommand func(name string, args ...string) Executor
@@ -221,7 +227,16 @@ func Executor_Open(t *testing.T, command buildExecCommand, newExecutor func() Ex
 		log.Panicf("Open(%v) failed: %v", id, err)
 	}
 
-	if res := e2.Wait(); !res.Successful() {
+	if _, err := e2.Stats(); err != nil {
+		log.Panicf("Stats() of reopened executor failed: %v", err)
+	}
+
+	// Only the executor that started the process can wait on it.
+	if res := e2.Wait(); res.Err == nil {
+		log.Panicf("Wait() of reopened executor should have failed")
+	}
+
+	if res := e.Wait(); !res.Successful() {
 		log.Panicf("Wait() failed: %v", res)
 	}
 
@@ -266,7 +281,6 @@ func Executor_Open_Invalid(t *testing.T, command buildExecCommand, newExecutor f
 	// Wait until process is actually gone, we don't care what the result was.
 	e.Wait()
 
-	// Destroy the allocdir which removes the exit code.
 	if err := alloc.Destroy(); err != nil {
 		log.Panicf("alloc.Destroy() failed: %v", err)
 	}
@@ -276,3 +290,75 @@ func Executor_Open_Invalid(t *testing.T, command buildExecCommand, newExecutor f
 		log.Panicf("Open(%v) should have failed", id)
 	}
 }
+
+func Executor_Start_Logs(t *testing.T, command buildExecCommand) {
+	task, alloc := mockAllocDir(t)
+	defer alloc.Destroy()
+
+	taskDir, ok := alloc.TaskDirs[task]
+	if !ok {
+		log.Panicf("No task directory found for task %v", task)
+	}
+
+	expected := "hello world"
+	e := command(testtask.Path(), "echo", expected)
+
+	if err := e.Limit(constraint); err != nil {
+		log.Panicf("Limit() failed: %v", err)
+	}
+
+	if err := e.ConfigureTaskDir(task, alloc); err != nil {
+		log.Panicf("ConfigureTaskDir(%v, %v) failed: %v", task, alloc, err)
+	}
+
+	if err := e.Start(); err != nil {
+		log.Panicf("Start() failed: %v", err)
+	}
+
+	if res := e.Wait(); !res.Successful() {
+		log.Panicf("Wait() failed: %v", res)
+	}
+
+	stdout := filepath.Join(taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", task))
+	output, err := ioutil.ReadFile(stdout)
+	if err != nil {
+		log.Panicf("Couldn't read file %v", stdout)
+	}
+
+	act := strings.TrimSpace(string(output))
+	if act != expected {
+		log.Panicf("Command output incorrectly: want %v; got %v", expected, act)
+	}
+}
+
+func Executor_Start_Signal(t *testing.T, command buildExecCommand) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	task, alloc := mockAllocDir(t)
+	defer alloc.Destroy()
+
+	e := command(testtask.Path(), "sleep", "10s")
+
+	if err := e.Limit(constraint); err != nil {
+		log.Panicf("Limit() failed: %v", err)
+	}
+
+	if err := e.ConfigureTaskDir(task, alloc); err != nil {
+		log.Panicf("ConfigureTaskDir(%v, %v) failed: %v", task, alloc, err)
+	}
+
+	if err := e.Start(); err != nil {
+		log.Panicf("Start() failed: %v", err)
+	}
+
+	if err := e.Signal(syscall.SIGKILL); err != nil {
+		log.Panicf("Signal() failed: %v", err)
+	}
+
+	res := e.Wait()
+	if res.Signal != int(syscall.SIGKILL) {
+		log.Panicf("Wait() should report the signal: %v", res)
+	}
+}
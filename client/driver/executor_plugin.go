@@ -0,0 +1,396 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/driver/executor"
+	"github.com/hashicorp/nomad/helper/discover"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+const (
+	// executorPluginName is the name the executor is dispensed under.
+	executorPluginName = "executor"
+
+	// executorLogName is the name of the file in the task directory the
+	// executor process logs to.
+	executorLogName = "executor.out"
+)
+
+// ExecutorHandshake is used to verify that the client and an executor process
+// speak the same protocol.
+var ExecutorHandshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "NOMAD_EXECUTOR_MAGIC_COOKIE",
+	MagicCookieValue: "3c1a8e0f5b7d4e92a6f0c2d8b4e1a7f3",
+}
+
+// executorBinary returns the path of the nomad executable the executor
+// processes are launched from.
+var executorBinary = discover.NomadExecutable
+
+// ServeExecutor serves an executor over RPC. It is called by the executor
+// process of a task and blocks until the client stops it.
+func ServeExecutor() {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: ExecutorHandshake,
+		Plugins: map[string]plugin.Plugin{
+			executorPluginName: new(ExecutorPlugin),
+		},
+	})
+}
+
+// ExecutorPlugin is the plugin.Plugin that serves an executor over RPC.
+type ExecutorPlugin struct{}
+
+func (p *ExecutorPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return &ExecutorRPCServer{doneCh: make(chan struct{})}, nil
+}
+
+func (p *ExecutorPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &ExecutorRPC{client: c}, nil
+}
+
+// ExecutorLaunchArgs is the user command the executor process launches along
+// with the context it is launched in.
+type ExecutorLaunchArgs struct {
+	Cmd  string
+	Args []string
+	Env  []string
+
+	TaskName  string
+	AllocDir  *allocdir.AllocDir
+	Resources *structs.Resources
+
+	// Volumes are the directories of the host mounted into the task.
+	Volumes []*cstructs.BindMount
+
+	// Isolated selects the executor of the platform, which isolates the
+	// process as much as the OS allows, rather than the basic executor.
+	Isolated bool
+}
+
+// newExecutor returns the executor the command is launched with.
+func (a *ExecutorLaunchArgs) newExecutor() executor.Executor {
+	if a.Isolated {
+		return executor.NewExecutor()
+	}
+	return executor.NewBasicExecutor()
+}
+
+// ExecutorRPC is the client side of an executor process.
+type ExecutorRPC struct {
+	client *rpc.Client
+}
+
+// Launch starts the user command and returns the ID of the executor.
+func (e *ExecutorRPC) Launch(args *ExecutorLaunchArgs) (string, error) {
+	var id string
+	err := e.client.Call("Plugin.Launch", args, &id)
+	return id, err
+}
+
+func (e *ExecutorRPC) Wait() (*cstructs.WaitResult, error) {
+	var resp PluginWaitResult
+	if err := e.client.Call("Plugin.Wait", new(interface{}), &resp); err != nil {
+		return nil, err
+	}
+	return resp.WaitResult(), nil
+}
+
+func (e *ExecutorRPC) Shutdown() error {
+	return e.client.Call("Plugin.Shutdown", new(interface{}), new(interface{}))
+}
+
+func (e *ExecutorRPC) ForceStop() error {
+	return e.client.Call("Plugin.ForceStop", new(interface{}), new(interface{}))
+}
+
+func (e *ExecutorRPC) Signal(sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return fmt.Errorf("unsupported signal %v", sig)
+	}
+	return e.client.Call("Plugin.Signal", int(s), new(interface{}))
+}
+
+func (e *ExecutorRPC) Stats() (*cstructs.TaskResourceUsage, error) {
+	var resp cstructs.TaskResourceUsage
+	if err := e.client.Call("Plugin.Stats", new(interface{}), &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ExecutorRPCServer serves the executor of a single task. The result of the
+// task is kept once it exits so the client can collect it after a restart.
+type ExecutorRPCServer struct {
+	executor executor.Executor
+	doneCh   chan struct{}
+	result   *cstructs.WaitResult
+	lock     sync.Mutex
+}
+
+func (s *ExecutorRPCServer) Launch(args *ExecutorLaunchArgs, id *string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.executor != nil {
+		return fmt.Errorf("executor already launched a command")
+	}
+
+	e := args.newExecutor()
+	executor.SetCommand(e, args.Cmd, args.Args)
+	e.Command().Env = args.Env
+	if err := e.Limit(args.Resources); err != nil {
+		return fmt.Errorf("failed to constrain resources: %s", err)
+	}
+	if err := e.ConfigureTaskDir(args.TaskName, args.AllocDir); err != nil {
+		return fmt.Errorf("failed to configure task directory: %v", err)
+	}
+	if err := e.MountVolumes(args.Volumes); err != nil {
+		return fmt.Errorf("failed to mount volumes: %v", err)
+	}
+	if err := e.Start(); err != nil {
+		return fmt.Errorf("failed to start command: %v", err)
+	}
+
+	execID, err := e.ID()
+	if err != nil {
+		return err
+	}
+
+	s.executor = e
+	go func() {
+		s.result = e.Wait()
+		close(s.doneCh)
+	}()
+
+	*id = execID
+	return nil
+}
+
+func (s *ExecutorRPCServer) Wait(_ interface{}, resp *PluginWaitResult) error {
+	if _, err := s.launched(); err != nil {
+		return err
+	}
+
+	<-s.doneCh
+	resp.ExitCode = s.result.ExitCode
+	resp.Signal = s.result.Signal
+	if s.result.Err != nil {
+		resp.Err = s.result.Err.Error()
+	}
+	return nil
+}
+
+func (s *ExecutorRPCServer) Shutdown(_ interface{}, _ *interface{}) error {
+	e, err := s.launched()
+	if err != nil {
+		return err
+	}
+	return e.Shutdown()
+}
+
+func (s *ExecutorRPCServer) ForceStop(_ interface{}, _ *interface{}) error {
+	e, err := s.launched()
+	if err != nil {
+		return err
+	}
+	return e.ForceStop()
+}
+
+func (s *ExecutorRPCServer) Signal(sig int, _ *interface{}) error {
+	e, err := s.launched()
+	if err != nil {
+		return err
+	}
+	return e.Signal(syscall.Signal(sig))
+}
+
+func (s *ExecutorRPCServer) Stats(_ interface{}, resp *cstructs.TaskResourceUsage) error {
+	e, err := s.launched()
+	if err != nil {
+		return err
+	}
+	usage, err := e.Stats()
+	if err != nil {
+		return err
+	}
+	*resp = *usage
+	return nil
+}
+
+// launched returns the executor once the command has been launched.
+func (s *ExecutorRPCServer) launched() (executor.Executor, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.executor == nil {
+		return nil, fmt.Errorf("executor has not launched a command")
+	}
+	return s.executor, nil
+}
+
+// executorID is used to reconnect to the executor process of a task. It is
+// the handle ID of the drivers that run their tasks with executors.
+type executorID struct {
+	// Reattach holds the address of the RPC socket of the executor process.
+	Reattach *PluginReattachConfig
+
+	// ExecutorID is the ID of the executor running the task.
+	ExecutorID string
+	Isolated   bool
+}
+
+// executorClient is the client side of the executor process of a task. It
+// implements the parts of executor.Executor the driver handles use.
+type executorClient struct {
+	client *plugin.Client
+	rpc    *ExecutorRPC
+	id     executorID
+}
+
+// launchExecutor starts an executor process that launches the user command.
+// The process keeps running if the client exits, so the task can be
+// recovered with openExecutor.
+func launchExecutor(ctx *DriverContext, args *ExecutorLaunchArgs) (*executorClient, error) {
+	bin, err := executorBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	taskDir, ok := args.AllocDir.TaskDirs[args.TaskName]
+	if !ok {
+		return nil, fmt.Errorf("Could not find task directory for task: %v", args.TaskName)
+	}
+	cmd := exec.Command(bin, "executor", filepath.Join(taskDir, executorLogName))
+	isolateExecutor(cmd)
+
+	client := newExecutorPluginClient(ctx, &plugin.ClientConfig{Cmd: cmd})
+	executorRPC, err := dispenseExecutor(client)
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+
+	execID, err := executorRPC.Launch(args)
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+
+	e := &executorClient{client: client, rpc: executorRPC}
+	e.id = executorID{
+		Reattach:   NewPluginReattachConfig(client.ReattachConfig()),
+		ExecutorID: execID,
+		Isolated:   args.Isolated,
+	}
+	return e, nil
+}
+
+// openExecutor reconnects to the executor process of a task from the handle
+// ID returned by executorClient.ID.
+func openExecutor(ctx *DriverContext, handleID string) (*executorClient, error) {
+	var id executorID
+	if err := json.Unmarshal([]byte(handleID), &id); err != nil {
+		return nil, fmt.Errorf("Failed to parse handle '%s': %v", handleID, err)
+	}
+	if id.Reattach == nil {
+		return nil, fmt.Errorf("handle '%s' is missing the executor reattach config", handleID)
+	}
+	reattach, err := id.Reattach.PluginConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse handle '%s': %v", handleID, err)
+	}
+
+	client := newExecutorPluginClient(ctx, &plugin.ClientConfig{Reattach: reattach})
+	executorRPC, err := dispenseExecutor(client)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("Failed to reattach to executor: %v", err)
+	}
+	return &executorClient{client: client, rpc: executorRPC, id: id}, nil
+}
+
+func newExecutorPluginClient(ctx *DriverContext, clientConfig *plugin.ClientConfig) *plugin.Client {
+	clientConfig.HandshakeConfig = ExecutorHandshake
+	clientConfig.Plugins = map[string]plugin.Plugin{
+		executorPluginName: new(ExecutorPlugin),
+	}
+	if ctx.config != nil && ctx.config.LogOutput != nil {
+		clientConfig.Stderr = ctx.config.LogOutput
+	}
+	return plugin.NewClient(clientConfig)
+}
+
+func dispenseExecutor(client *plugin.Client) (*ExecutorRPC, error) {
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to launch executor: %v", err)
+	}
+	raw, err := rpcClient.Dispense(executorPluginName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dispense executor: %v", err)
+	}
+	return raw.(*ExecutorRPC), nil
+}
+
+// ID returns the handle ID used to reconnect to the executor process.
+func (e *executorClient) ID() string {
+	data, err := json.Marshal(e.id)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// Wait waits for the task to exit and then stops the executor process.
+func (e *executorClient) Wait() *cstructs.WaitResult {
+	res, err := e.rpc.Wait()
+	if err != nil {
+		res = cstructs.NewWaitResult(-1, 0, fmt.Errorf("lost connection to executor: %v", err))
+	}
+	e.client.Kill()
+	return res
+}
+
+func (e *executorClient) Shutdown() error {
+	return e.rpc.Shutdown()
+}
+
+func (e *executorClient) ForceStop() error {
+	return e.rpc.ForceStop()
+}
+
+func (e *executorClient) Signal(sig os.Signal) error {
+	return e.rpc.Signal(sig)
+}
+
+func (e *executorClient) Stats() (*cstructs.TaskResourceUsage, error) {
+	return e.rpc.Stats()
+}
+
+// Exec runs the command in the client with the isolation of the task, which
+// the executor restored from the ID of the executor process provides.
+func (e *executorClient) Exec(req *cstructs.ExecRequest) (int, error) {
+	var local executor.Executor
+	if e.id.Isolated {
+		local = executor.NewExecutor()
+	} else {
+		local = executor.NewBasicExecutor()
+	}
+	if err := local.Open(e.id.ExecutorID); err != nil {
+		return -1, err
+	}
+	return local.Exec(req)
+}
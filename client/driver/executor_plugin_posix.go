@@ -0,0 +1,17 @@
+// +build !windows
+
+package driver
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolateExecutor starts the executor process in its own session so that it
+// isn't stopped along with the client's process group.
+func isolateExecutor(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+}
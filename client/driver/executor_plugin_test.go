@@ -0,0 +1,127 @@
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/helper/testtask"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func init() {
+	// The executor processes are launched from the test binary.
+	executorBinary = func() (string, error) {
+		return testtask.Path(), nil
+	}
+}
+
+// testExecutorServe serves an executor if the test binary was launched as an
+// executor process.
+func testExecutorServe() bool {
+	if os.Getenv(ExecutorHandshake.MagicCookieKey) != ExecutorHandshake.MagicCookieValue {
+		return false
+	}
+	ServeExecutor()
+	return true
+}
+
+func testExecutorLaunchArgs(t *testing.T, args ...string) (*ExecutorLaunchArgs, *DriverContext) {
+	task := &structs.Task{Name: "sleep", Resources: basicResources}
+	driverCtx := testDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+
+	launchArgs := &ExecutorLaunchArgs{
+		Cmd:       testtask.Path(),
+		Args:      args,
+		Env:       append(os.Environ(), "TEST_TASK=execute"),
+		TaskName:  task.Name,
+		AllocDir:  ctx.AllocDir,
+		Resources: task.Resources,
+	}
+	return launchArgs, driverCtx
+}
+
+func TestExecutor_LaunchOpen_Wait(t *testing.T) {
+	t.Parallel()
+	args, driverCtx := testExecutorLaunchArgs(t, "sleep", "1s", "echo", "hello")
+	defer args.AllocDir.Destroy()
+
+	e, err := launchExecutor(driverCtx, args)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Reconnect as a restarted client would
+	e2, err := openExecutor(driverCtx, e.ID())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if e2.ID() != e.ID() {
+		t.Fatalf("bad ID: %v; want %v", e2.ID(), e.ID())
+	}
+
+	resCh := make(chan bool, 1)
+	go func() {
+		resCh <- e2.Wait().Successful()
+	}()
+
+	select {
+	case ok := <-resCh:
+		if !ok {
+			t.Fatalf("task should have exited successfully")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	// The executor writes the output of the task to its log
+	stdout := filepath.Join(args.AllocDir.TaskDirs[args.TaskName], allocdir.TaskLocal, "sleep.stdout")
+	data, err := ioutil.ReadFile(stdout)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out := strings.TrimSpace(string(data)); out != "hello" {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
+func TestExecutor_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("signals are not supported on windows")
+	}
+	t.Parallel()
+	args, driverCtx := testExecutorLaunchArgs(t, "sleep", "10s")
+	defer args.AllocDir.Destroy()
+
+	e, err := launchExecutor(driverCtx, args)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := e.Stats(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if err := e.Signal(syscall.SIGKILL); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	res := e.Wait()
+	if res.Signal != int(syscall.SIGKILL) {
+		t.Fatalf("task should have been killed by the signal: %v", res)
+	}
+}
+
+func TestExecutor_Open_BadHandle(t *testing.T) {
+	driverCtx := testDriverContext("sleep")
+	if _, err := openExecutor(driverCtx, `{"ExecutorID":"foo"}`); err == nil {
+		t.Fatalf("open without reattach config should fail")
+	}
+}
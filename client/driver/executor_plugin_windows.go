@@ -0,0 +1,6 @@
+package driver
+
+import "os/exec"
+
+// No session isolation on Windows.
+func isolateExecutor(cmd *exec.Cmd) {}
@@ -0,0 +1,114 @@
+package driver
+
+import (
+	"github.com/hashicorp/nomad/client/driver/environment"
+	"github.com/hashicorp/nomad/helper/args"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// InterpolationVars returns the variables a task is interpolated with. These
+// are the environment of the task, such as ${NOMAD_PORT_http}, and the
+// ${node.unique.id}, ${node.datacenter}, ${attr.<name>} and ${meta.<key>}
+// values of the node.
+func InterpolationVars(env environment.TaskEnvironment, node *structs.Node) map[string]string {
+	vars := make(map[string]string, len(env))
+	for k, v := range env {
+		vars[k] = v
+	}
+	if node == nil {
+		return vars
+	}
+
+	vars["node.unique.id"] = node.ID
+	vars["node.unique.name"] = node.Name
+	vars["node.datacenter"] = node.Datacenter
+	for k, v := range node.Attributes {
+		vars["attr."+k] = v
+	}
+	for k, v := range node.Meta {
+		vars["meta."+k] = v
+	}
+	return vars
+}
+
+// InterpolateTask returns a copy of the task with the variables replaced in
+// its config, environment, services and meta. The task itself is left
+// unmodified.
+func InterpolateTask(task *structs.Task, vars map[string]string) *structs.Task {
+	out := new(structs.Task)
+	*out = *task
+
+	if task.Config != nil {
+		out.Config = interpolateValue(task.Config, vars).(map[string]interface{})
+	}
+	out.Env = interpolateMap(task.Env, vars)
+	out.Meta = interpolateMap(task.Meta, vars)
+
+	if task.Services != nil {
+		out.Services = make([]*structs.Service, len(task.Services))
+		for i, service := range task.Services {
+			s := new(structs.Service)
+			*s = *service
+			s.Name = args.ReplaceEnv(service.Name, vars)
+			if service.Tags != nil {
+				s.Tags = args.ParseAndReplace(service.Tags, vars)
+			}
+			out.Services[i] = s
+		}
+	}
+	return out
+}
+
+// interpolateMap returns a copy of the map with the variables replaced in its
+// values.
+func interpolateMap(m map[string]string, vars map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = args.ReplaceEnv(v, vars)
+	}
+	return out
+}
+
+// interpolateValue returns a copy of a decoded driver config value with the
+// variables replaced in all of its strings, including those of nested lists
+// and maps.
+func interpolateValue(v interface{}, vars map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return args.ReplaceEnv(v, vars)
+	case []string:
+		return args.ParseAndReplace(v, vars)
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = interpolateValue(elem, vars)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, elem := range v {
+			out[k] = interpolateValue(elem, vars)
+		}
+		return out
+	case []map[string]interface{}:
+		out := make([]map[string]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = interpolateValue(elem, vars).(map[string]interface{})
+		}
+		return out
+	case map[string]string:
+		return interpolateMap(v, vars)
+	case []map[string]string:
+		out := make([]map[string]string, len(v))
+		for i, elem := range v {
+			out[i] = interpolateMap(elem, vars)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,73 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/driver/environment"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestDriver_InterpolateTask(t *testing.T) {
+	node := mock.Node()
+	node.Meta = map[string]string{"rack": "r1"}
+	env := environment.TaskEnvironment{
+		"NOMAD_IP":        "1.2.3.4",
+		"NOMAD_PORT_http": "8080",
+	}
+	vars := InterpolationVars(env, node)
+
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"command": "${NOMAD_TASK_DIR}/bin",
+			"args":    []interface{}{"-bind", "$NOMAD_IP:$NOMAD_PORT_http"},
+			"labels": []map[string]interface{}{
+				map[string]interface{}{"node": "${node.unique.id}"},
+			},
+			"port":   8080,
+			"nested": map[string]interface{}{"dc": "${node.datacenter}"},
+		},
+		Env:  map[string]string{"KERNEL": "${attr.kernel.name}"},
+		Meta: map[string]string{"rack": "${meta.rack}"},
+		Services: []*structs.Service{
+			&structs.Service{
+				Name: "web-${meta.rack}",
+				Tags: []string{"${node.datacenter}"},
+			},
+		},
+	}
+
+	out := InterpolateTask(task, vars)
+
+	expConfig := map[string]interface{}{
+		"command": "${NOMAD_TASK_DIR}/bin",
+		"args":    []interface{}{"-bind", "1.2.3.4:8080"},
+		"labels": []map[string]interface{}{
+			map[string]interface{}{"node": node.ID},
+		},
+		"port":   8080,
+		"nested": map[string]interface{}{"dc": node.Datacenter},
+	}
+	if !reflect.DeepEqual(out.Config, expConfig) {
+		t.Fatalf("bad config: %#v; want %#v", out.Config, expConfig)
+	}
+	if out.Env["KERNEL"] != "linux" {
+		t.Fatalf("bad env: %#v", out.Env)
+	}
+	if out.Meta["rack"] != "r1" {
+		t.Fatalf("bad meta: %#v", out.Meta)
+	}
+	if s := out.Services[0]; s.Name != "web-r1" || s.Tags[0] != node.Datacenter {
+		t.Fatalf("bad service: %#v", s)
+	}
+
+	// The original task must be left unmodified
+	if task.Env["KERNEL"] != "${attr.kernel.name}" || task.Services[0].Name != "web-${meta.rack}" {
+		t.Fatalf("task was modified: %#v", task)
+	}
+	if task.Config["args"].([]interface{})[1] != "$NOMAD_IP:$NOMAD_PORT_http" {
+		t.Fatalf("task config was modified: %#v", task.Config)
+	}
+}
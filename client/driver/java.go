@@ -3,19 +3,16 @@ package driver
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
-	"path/filepath"
 	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
-	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
-	"github.com/hashicorp/nomad/client/driver/executor"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -28,15 +25,14 @@ type JavaDriver struct {
 }
 
 type JavaDriverConfig struct {
-	JvmOpts        []string `mapstructure:"jvm_options"`
-	ArtifactSource string   `mapstructure:"artifact_source"`
-	Checksum       string   `mapstructure:"checksum"`
-	Args           []string `mapstructure:"args"`
+	JarPath string   `mapstructure:"jar_path"`
+	JvmOpts []string `mapstructure:"jvm_options"`
+	Args    []string `mapstructure:"args"`
 }
 
 // javaHandle is returned from Start/Open as a handle to the PID
 type javaHandle struct {
-	cmd    executor.Executor
+	cmd    *executorClient
 	waitCh chan *cstructs.WaitResult
 	doneCh chan struct{}
 }
@@ -103,24 +99,13 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
 		return nil, err
 	}
-	taskDir, ok := ctx.AllocDir.TaskDirs[d.DriverContext.taskName]
-	if !ok {
-		return nil, fmt.Errorf("Could not find task directory for task: %v", d.DriverContext.taskName)
-	}
 
-	// Proceed to download an artifact to be executed.
-	path, err := getter.GetArtifact(
-		filepath.Join(taskDir, allocdir.TaskLocal),
-		driverConfig.ArtifactSource,
-		driverConfig.Checksum,
-		d.logger,
-	)
-	if err != nil {
-		return nil, err
+	// The jar is expected to have been placed in the task directory, for
+	// example by an artifact of the task.
+	if driverConfig.JarPath == "" {
+		return nil, fmt.Errorf("missing jar_path for Java driver")
 	}
 
-	jarName := filepath.Base(path)
-
 	// Get the environment variables.
 	envVars := TaskEnvironmentVariables(ctx, task)
 
@@ -132,28 +117,24 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 	}
 
 	// Build the argument list.
-	args = append(args, "-jar", filepath.Join(allocdir.TaskLocal, jarName))
+	args = append(args, "-jar", driverConfig.JarPath)
 	if len(driverConfig.Args) != 0 {
 		args = append(args, driverConfig.Args...)
 	}
 
-	// Setup the command
+	// Launch the command in its executor process
 	// Assumes Java is in the $PATH, but could probably be detected
-	cmd := executor.Command("java", args...)
-
-	// Populate environment variables
-	cmd.Command().Env = envVars.List()
-
-	if err := cmd.Limit(task.Resources); err != nil {
-		return nil, fmt.Errorf("failed to constrain resources: %s", err)
-	}
-
-	if err := cmd.ConfigureTaskDir(d.taskName, ctx.AllocDir); err != nil {
-		return nil, fmt.Errorf("failed to configure task directory: %v", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start source: %v", err)
+	cmd, err := launchExecutor(&d.DriverContext, &ExecutorLaunchArgs{
+		Cmd:       "java",
+		Args:      args,
+		Env:       envVars.List(),
+		TaskName:  d.taskName,
+		AllocDir:  ctx.AllocDir,
+		Resources: task.Resources,
+		Isolated:  true,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return a driver handle
@@ -168,8 +149,8 @@ func (d *JavaDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 }
 
 func (d *JavaDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
-	// Find the process
-	cmd, err := executor.OpenId(handleID)
+	// Reconnect to the executor of the task
+	cmd, err := openExecutor(&d.DriverContext, handleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", handleID, err)
 	}
@@ -186,8 +167,7 @@ func (d *JavaDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 }
 
 func (h *javaHandle) ID() string {
-	id, _ := h.cmd.ID()
-	return id
+	return h.cmd.ID()
 }
 
 func (h *javaHandle) WaitCh() chan *cstructs.WaitResult {
@@ -199,6 +179,18 @@ func (h *javaHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+func (h *javaHandle) Exec(req *cstructs.ExecRequest) (int, error) {
+	return h.cmd.Exec(req)
+}
+
+func (h *javaHandle) Signal(sig os.Signal) error {
+	return h.cmd.Signal(sig)
+}
+
+func (h *javaHandle) Stats() (*cstructs.TaskResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *javaHandle) Kill() error {
 	h.cmd.Shutdown()
 	select {
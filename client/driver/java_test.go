@@ -52,9 +52,16 @@ func TestJavaDriver_StartOpen_Wait(t *testing.T) {
 	task := &structs.Task{
 		Name: "demo-app",
 		Config: map[string]interface{}{
-			"artifact_source": "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
-			"jvm_options":     []string{"-Xmx64m", "-Xms32m"},
-			"checksum":        "sha256:58d6e8130308d32e197c5108edd4f56ddf1417408f743097c2e662df0f0b17c8",
+			"jar_path":    "local/demoapp.jar",
+			"jvm_options": []string{"-Xmx64m", "-Xms32m"},
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
+				GetterOptions: map[string]string{
+					"checksum": "sha256:58d6e8130308d32e197c5108edd4f56ddf1417408f743097c2e662df0f0b17c8",
+				},
+			},
 		},
 		Resources: basicResources,
 	}
@@ -99,8 +106,15 @@ func TestJavaDriver_Start_Wait(t *testing.T) {
 	task := &structs.Task{
 		Name: "demo-app",
 		Config: map[string]interface{}{
-			"artifact_source": "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
-			"checksum":        "sha256:58d6e8130308d32e197c5108edd4f56ddf1417408f743097c2e662df0f0b17c8",
+			"jar_path": "local/demoapp.jar",
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
+				GetterOptions: map[string]string{
+					"checksum": "sha256:58d6e8130308d32e197c5108edd4f56ddf1417408f743097c2e662df0f0b17c8",
+				},
+			},
 		},
 		Resources: basicResources,
 	}
@@ -146,7 +160,12 @@ func TestJavaDriver_Start_Kill_Wait(t *testing.T) {
 	task := &structs.Task{
 		Name: "demo-app",
 		Config: map[string]interface{}{
-			"artifact_source": "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
+			"jar_path": "local/demoapp.jar",
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: "https://dl.dropboxusercontent.com/u/47675/jar_thing/demoapp.jar",
+			},
 		},
 		Resources: basicResources,
 	}
@@ -0,0 +1,229 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/config"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// The option that enables this driver in the Config.Options map.
+	mockDriverConfigOption = "driver.mock_driver.enable"
+)
+
+// MockDriverConfig is the driver configuration of the mock driver. The
+// durations are parsed with time.ParseDuration.
+type MockDriverConfig struct {
+	// StartAfter delays the start of the task.
+	StartAfter string `mapstructure:"start_after"`
+
+	// StartErr makes starting the task fail with the error after StartAfter.
+	StartErr string `mapstructure:"start_error"`
+
+	// RunFor is how long the task runs before exiting.
+	RunFor string `mapstructure:"run_for"`
+
+	// ExitCode, ExitSignal and ExitErrMsg make up the result of the task
+	// when it exits on its own.
+	ExitCode   int    `mapstructure:"exit_code"`
+	ExitSignal int    `mapstructure:"exit_signal"`
+	ExitErrMsg string `mapstructure:"exit_err_msg"`
+
+	// KillAfter is how long the task takes to exit once it is killed.
+	KillAfter string `mapstructure:"kill_after"`
+
+	// StdoutString is written to the task's stdout log when it starts.
+	StdoutString string `mapstructure:"stdout_string"`
+}
+
+// MockDriver is a driver for testing. Its tasks don't run any process but
+// simulate one as configured by MockDriverConfig, so the client can be tested
+// end to end without external binaries.
+type MockDriver struct {
+	DriverContext
+	fingerprint.StaticFingerprinter
+}
+
+// mockDriverID is used to re-open a handle. It holds the time the task must
+// exit at along with how it exits.
+type mockDriverID struct {
+	TaskName   string
+	ExitAt     time.Time
+	ExitCode   int
+	ExitSignal int
+	ExitErrMsg string
+	KillAfter  time.Duration
+}
+
+// mockDriverHandle is returned from Start/Open as a handle to the simulated
+// task.
+type mockDriverHandle struct {
+	id       mockDriverID
+	logger   *log.Logger
+	waitCh   chan *cstructs.WaitResult
+	killCh   chan struct{}
+	killOnce sync.Once
+	doneCh   chan struct{}
+}
+
+// NewMockDriver is used to create a new mock driver
+func NewMockDriver(ctx *DriverContext) Driver {
+	return &MockDriver{DriverContext: *ctx}
+}
+
+func (d *MockDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	// The mock driver must be explicitly enabled so it isn't used by mistake.
+	if !cfg.ReadBoolDefault(mockDriverConfigOption, false) {
+		return false, nil
+	}
+	node.Attributes["driver.mock_driver"] = "1"
+	return true, nil
+}
+
+func (d *MockDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, error) {
+	var driverConfig MockDriverConfig
+	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
+		return nil, err
+	}
+	startAfter, err := parseMockDuration("start_after", driverConfig.StartAfter)
+	if err != nil {
+		return nil, err
+	}
+	runFor, err := parseMockDuration("run_for", driverConfig.RunFor)
+	if err != nil {
+		return nil, err
+	}
+	killAfter, err := parseMockDuration("kill_after", driverConfig.KillAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	time.Sleep(startAfter)
+	if driverConfig.StartErr != "" {
+		return nil, errors.New(driverConfig.StartErr)
+	}
+
+	if driverConfig.StdoutString != "" {
+		if err := writeMockStdout(ctx.AllocDir, task.Name, driverConfig.StdoutString); err != nil {
+			return nil, err
+		}
+	}
+
+	id := mockDriverID{
+		TaskName:   task.Name,
+		ExitAt:     time.Now().Add(runFor),
+		ExitCode:   driverConfig.ExitCode,
+		ExitSignal: driverConfig.ExitSignal,
+		ExitErrMsg: driverConfig.ExitErrMsg,
+		KillAfter:  killAfter,
+	}
+	return d.newHandle(id), nil
+}
+
+func (d *MockDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
+	var id mockDriverID
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(handleID, "MOCK:")), &id); err != nil {
+		return nil, fmt.Errorf("Failed to parse handle '%s': %v", handleID, err)
+	}
+	return d.newHandle(id), nil
+}
+
+func (d *MockDriver) newHandle(id mockDriverID) *mockDriverHandle {
+	h := &mockDriverHandle{
+		id:     id,
+		logger: d.logger,
+		waitCh: make(chan *cstructs.WaitResult, 1),
+		killCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *mockDriverHandle) ID() string {
+	data, err := json.Marshal(h.id)
+	if err != nil {
+		h.logger.Printf("[ERR] driver.mock_driver: failed to marshal ID to JSON: %s", err)
+	}
+	return fmt.Sprintf("MOCK:%s", string(data))
+}
+
+func (h *mockDriverHandle) WaitCh() chan *cstructs.WaitResult {
+	return h.waitCh
+}
+
+func (h *mockDriverHandle) Update(task *structs.Task) error {
+	// Update is not possible
+	return nil
+}
+
+// Kill simulates killing the task, which exits KillAfter later.
+func (h *mockDriverHandle) Kill() error {
+	h.killOnce.Do(func() { close(h.killCh) })
+	select {
+	case <-h.doneCh:
+		return nil
+	case <-time.After(h.id.KillAfter + 5*time.Second):
+		return fmt.Errorf("task %v did not exit after being killed", h.id.TaskName)
+	}
+}
+
+func (h *mockDriverHandle) run() {
+	var res *cstructs.WaitResult
+	select {
+	case <-time.After(h.id.ExitAt.Sub(time.Now())):
+		var err error
+		if h.id.ExitErrMsg != "" {
+			err = errors.New(h.id.ExitErrMsg)
+		}
+		res = cstructs.NewWaitResult(h.id.ExitCode, h.id.ExitSignal, err)
+	case <-h.killCh:
+		time.Sleep(h.id.KillAfter)
+		res = cstructs.NewWaitResult(0, 9, nil)
+	}
+	close(h.doneCh)
+	h.waitCh <- res
+	close(h.waitCh)
+}
+
+// parseMockDuration parses an optional duration of the driver config.
+func parseMockDuration(key, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", key, value, err)
+	}
+	return d, nil
+}
+
+// writeMockStdout writes the output to the task's stdout log, where the
+// executors write the output of the tasks.
+func writeMockStdout(allocDir *allocdir.AllocDir, taskName, output string) error {
+	if allocDir == nil {
+		return nil
+	}
+	taskDir, ok := allocDir.TaskDirs[taskName]
+	if !ok {
+		return fmt.Errorf("Could not find task directory for task: %v", taskName)
+	}
+	path := filepath.Join(taskDir, allocdir.TaskLocal, fmt.Sprintf("%v.stdout", taskName))
+	if err := ioutil.WriteFile(path, []byte(output), 0666); err != nil {
+		return fmt.Errorf("failed to write stdout of task %v: %v", taskName, err)
+	}
+	return nil
+}
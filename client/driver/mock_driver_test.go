@@ -0,0 +1,149 @@
+package driver
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestMockDriver_Fingerprint(t *testing.T) {
+	t.Parallel()
+	d := NewMockDriver(testDriverContext(""))
+	node := &structs.Node{
+		Attributes: make(map[string]string),
+	}
+
+	// The driver is disabled by default.
+	cfg := &config.Config{Options: map[string]string{}}
+	apply, err := d.Fingerprint(cfg, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if apply || node.Attributes["driver.mock_driver"] != "" {
+		t.Fatalf("driver incorrectly enabled")
+	}
+
+	// Enable the driver.
+	cfg.Options[mockDriverConfigOption] = "true"
+	apply, err = d.Fingerprint(cfg, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !apply || node.Attributes["driver.mock_driver"] != "1" {
+		t.Fatalf("driver not enabled")
+	}
+}
+
+func TestMockDriver_StartWait(t *testing.T) {
+	t.Parallel()
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"start_after":   "10ms",
+			"run_for":       "50ms",
+			"exit_code":     3,
+			"exit_err_msg":  "failed",
+			"stdout_string": "hello world",
+		},
+		Resources: basicResources,
+	}
+	driverCtx := testDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+	defer ctx.AllocDir.Destroy()
+
+	d := NewMockDriver(driverCtx)
+	handle, err := d.Start(ctx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	select {
+	case res := <-handle.WaitCh():
+		if res.ExitCode != 3 || res.Err == nil || res.Err.Error() != "failed" {
+			t.Fatalf("bad: %v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	path := filepath.Join(ctx.AllocDir.TaskDirs[task.Name], allocdir.TaskLocal, "web.stdout")
+	out, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(out) != "hello world" {
+		t.Fatalf("bad: %q", out)
+	}
+}
+
+func TestMockDriver_StartError(t *testing.T) {
+	t.Parallel()
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"start_error": "boom",
+		},
+		Resources: basicResources,
+	}
+	driverCtx := testDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+	defer ctx.AllocDir.Destroy()
+
+	d := NewMockDriver(driverCtx)
+	if _, err := d.Start(ctx, task); err == nil || err.Error() != "boom" {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestMockDriver_OpenKill(t *testing.T) {
+	t.Parallel()
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"run_for":    "10s",
+			"kill_after": "100ms",
+		},
+		Resources: basicResources,
+	}
+	driverCtx := testDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+	defer ctx.AllocDir.Destroy()
+
+	d := NewMockDriver(driverCtx)
+	handle, err := d.Start(ctx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Re-open the handle as a restarted client would
+	handle2, err := d.Open(ctx, handle.ID())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if handle2.ID() != handle.ID() {
+		t.Fatalf("bad: %v %v", handle2.ID(), handle.ID())
+	}
+
+	start := time.Now()
+	if err := handle2.Kill(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatalf("task exited before kill_after")
+	}
+
+	select {
+	case res := <-handle2.WaitCh():
+		if res.Signal != 9 {
+			t.Fatalf("bad: %v", res)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout")
+	}
+	handle.Kill()
+}
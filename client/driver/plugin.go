@@ -0,0 +1,481 @@
+package driver
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+const (
+	// PluginPrefix is the prefix of the names of driver plugin executables.
+	// The name of the driver is the remainder of the executable's name.
+	PluginPrefix = "nomad-driver-"
+
+	// pluginName is the name the driver is dispensed under by the plugin.
+	pluginName = "driver"
+)
+
+// PluginHandshake is used to verify that the client and a driver plugin
+// speak the same protocol.
+var PluginHandshake = plugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "NOMAD_DRIVER_PLUGIN_MAGIC_COOKIE",
+	MagicCookieValue: "7f2b3a9d1c4e4f0b8e6a5d2c9b1f3e7a",
+}
+
+func init() {
+	// Task configs are decoded into interfaces so the concrete types that
+	// may be held by them must be registered to be sent to plugins.
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register([]map[string]string{})
+	gob.Register([]map[string]int{})
+}
+
+// ServeDriverPlugin serves the driver created by the factory as a plugin. It
+// is called from the main function of a driver plugin executable and blocks
+// until the client stops the plugin.
+func ServeDriverPlugin(factory Factory) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: PluginHandshake,
+		Plugins: map[string]plugin.Plugin{
+			pluginName: &DriverPlugin{Factory: factory},
+		},
+	})
+}
+
+// DiscoverPlugins returns the paths of the driver plugin executables found in
+// dir, keyed by the name of the driver they provide.
+func DiscoverPlugins(dir string) (map[string]string, error) {
+	plugins := make(map[string]string)
+	if dir == "" {
+		return plugins, nil
+	}
+
+	entries, err := filepath.Glob(filepath.Join(dir, PluginPrefix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plugins in %q: %v", dir, err)
+	}
+	for _, path := range entries {
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(filepath.Base(path), PluginPrefix)
+		name = strings.TrimSuffix(name, ".exe")
+		if name != "" {
+			plugins[name] = path
+		}
+	}
+	return plugins, nil
+}
+
+// DriverPlugin is the plugin.Plugin that serves a task driver over RPC.
+type DriverPlugin struct {
+	// Factory creates the served driver. It is only used by the plugin.
+	Factory Factory
+}
+
+func (p *DriverPlugin) Server(*plugin.MuxBroker) (interface{}, error) {
+	return &DriverRPCServer{
+		factory: p.Factory,
+		handles: make(map[string]*pluginServedHandle),
+	}, nil
+}
+
+func (p *DriverPlugin) Client(b *plugin.MuxBroker, c *rpc.Client) (interface{}, error) {
+	return &DriverRPC{client: c}, nil
+}
+
+// PluginReattachConfig is the serializable form of the configuration used to
+// reattach to a running plugin.
+type PluginReattachConfig struct {
+	Pid      int
+	AddrNet  string
+	AddrName string
+}
+
+// NewPluginReattachConfig returns the serializable form of c.
+func NewPluginReattachConfig(c *plugin.ReattachConfig) *PluginReattachConfig {
+	return &PluginReattachConfig{
+		Pid:      c.Pid,
+		AddrNet:  c.Addr.Network(),
+		AddrName: c.Addr.String(),
+	}
+}
+
+// PluginConfig returns the configuration used by go-plugin to reattach.
+func (c *PluginReattachConfig) PluginConfig() (*plugin.ReattachConfig, error) {
+	var addr net.Addr
+	var err error
+	switch c.AddrNet {
+	case "unix", "unixgram", "unixpacket":
+		addr, err = net.ResolveUnixAddr(c.AddrNet, c.AddrName)
+	case "tcp", "tcp4", "tcp6":
+		addr, err = net.ResolveTCPAddr(c.AddrNet, c.AddrName)
+	default:
+		return nil, fmt.Errorf("unknown plugin address network %q", c.AddrNet)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &plugin.ReattachConfig{Pid: c.Pid, Addr: addr}, nil
+}
+
+// pluginDriverContext is the serializable form of a DriverContext.
+type pluginDriverContext struct {
+	TaskName string
+	Config   *config.Config
+	Node     *structs.Node
+}
+
+func newPluginDriverContext(ctx *DriverContext) *pluginDriverContext {
+	// The log output and RPC handler can't be sent to the plugin.
+	var cfg *config.Config
+	if ctx.config != nil {
+		c := *ctx.config
+		c.LogOutput = nil
+		c.RPCHandler = nil
+		cfg = &c
+	}
+	return &pluginDriverContext{
+		TaskName: ctx.taskName,
+		Config:   cfg,
+		Node:     ctx.node,
+	}
+}
+
+// DriverContext returns the DriverContext the plugin creates the driver with.
+func (c *pluginDriverContext) DriverContext(logOutput io.Writer) *DriverContext {
+	cfg := c.Config
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	cfg.LogOutput = logOutput
+	return NewDriverContext(c.TaskName, cfg, c.Node, log.New(logOutput, "", log.LstdFlags))
+}
+
+// pluginExecContext is the serializable form of an ExecContext.
+type pluginExecContext struct {
+	AllocDir *allocdir.AllocDir
+	AllocID  string
+	Alloc    *structs.Allocation
+	Node     *structs.Node
+}
+
+func newPluginExecContext(ctx *ExecContext) *pluginExecContext {
+	return &pluginExecContext{
+		AllocDir: ctx.AllocDir,
+		AllocID:  ctx.AllocID,
+		Alloc:    ctx.Alloc,
+		Node:     ctx.Node,
+	}
+}
+
+func (c *pluginExecContext) ExecContext() *ExecContext {
+	return &ExecContext{
+		AllocDir: c.AllocDir,
+		AllocID:  c.AllocID,
+		Alloc:    c.Alloc,
+		Node:     c.Node,
+	}
+}
+
+// PluginWaitResult is the serializable form of a WaitResult.
+type PluginWaitResult struct {
+	ExitCode int
+	Signal   int
+	Err      string
+}
+
+func (r *PluginWaitResult) WaitResult() *cstructs.WaitResult {
+	var err error
+	if r.Err != "" {
+		err = fmt.Errorf("%s", r.Err)
+	}
+	return cstructs.NewWaitResult(r.ExitCode, r.Signal, err)
+}
+
+type FingerprintArgs struct {
+	DriverCtx *pluginDriverContext
+	Node      *structs.Node
+}
+
+type FingerprintResponse struct {
+	Applies bool
+	Node    *structs.Node
+}
+
+type StartArgs struct {
+	DriverCtx *pluginDriverContext
+	ExecCtx   *pluginExecContext
+	Task      *structs.Task
+}
+
+type OpenArgs struct {
+	DriverCtx *pluginDriverContext
+	ExecCtx   *pluginExecContext
+	HandleID  string
+}
+
+type UpdateArgs struct {
+	HandleID string
+	Task     *structs.Task
+}
+
+// DriverRPC is the client side of a driver served by a plugin.
+type DriverRPC struct {
+	client *rpc.Client
+}
+
+func (d *DriverRPC) Fingerprint(ctx *DriverContext, node *structs.Node) (bool, error) {
+	var resp FingerprintResponse
+	args := &FingerprintArgs{DriverCtx: newPluginDriverContext(ctx), Node: node}
+	if err := d.client.Call("Plugin.Fingerprint", args, &resp); err != nil {
+		return false, err
+	}
+
+	// Apply the attributes set by the driver to the node
+	if resp.Node != nil {
+		if node.Attributes == nil {
+			node.Attributes = make(map[string]string)
+		}
+		for k, v := range resp.Node.Attributes {
+			node.Attributes[k] = v
+		}
+	}
+	return resp.Applies, nil
+}
+
+func (d *DriverRPC) Start(ctx *DriverContext, execCtx *ExecContext, task *structs.Task) (string, error) {
+	var id string
+	args := &StartArgs{
+		DriverCtx: newPluginDriverContext(ctx),
+		ExecCtx:   newPluginExecContext(execCtx),
+		Task:      task,
+	}
+	err := d.client.Call("Plugin.Start", args, &id)
+	return id, err
+}
+
+func (d *DriverRPC) Open(ctx *DriverContext, execCtx *ExecContext, handleID string) (string, error) {
+	var id string
+	args := &OpenArgs{
+		DriverCtx: newPluginDriverContext(ctx),
+		ExecCtx:   newPluginExecContext(execCtx),
+		HandleID:  handleID,
+	}
+	err := d.client.Call("Plugin.Open", args, &id)
+	return id, err
+}
+
+func (d *DriverRPC) Wait(handleID string) (*cstructs.WaitResult, error) {
+	var resp PluginWaitResult
+	if err := d.client.Call("Plugin.Wait", handleID, &resp); err != nil {
+		return nil, err
+	}
+	return resp.WaitResult(), nil
+}
+
+func (d *DriverRPC) Update(handleID string, task *structs.Task) error {
+	return d.client.Call("Plugin.Update", &UpdateArgs{HandleID: handleID, Task: task}, new(interface{}))
+}
+
+func (d *DriverRPC) Kill(handleID string) error {
+	return d.client.Call("Plugin.Kill", handleID, new(interface{}))
+}
+
+// DriverRPCServer serves a driver from within a plugin. The handles of the
+// started tasks are kept so the client can reattach to them after a restart.
+type DriverRPCServer struct {
+	factory Factory
+	handles map[string]*pluginServedHandle
+	lock    sync.Mutex
+}
+
+// pluginServedHandle is a handle served by the plugin along with the result
+// of the task once it has exited.
+type pluginServedHandle struct {
+	handle DriverHandle
+	doneCh chan struct{}
+	result *cstructs.WaitResult
+}
+
+func (s *DriverRPCServer) Fingerprint(args *FingerprintArgs, resp *FingerprintResponse) error {
+	ctx := args.DriverCtx.DriverContext(os.Stderr)
+	node := args.Node
+	if node.Attributes == nil {
+		node.Attributes = make(map[string]string)
+	}
+	applies, err := s.factory(ctx).Fingerprint(ctx.config, node)
+	if err != nil {
+		return err
+	}
+	resp.Applies = applies
+	resp.Node = node
+	return nil
+}
+
+func (s *DriverRPCServer) Start(args *StartArgs, id *string) error {
+	execCtx := args.ExecCtx.ExecContext()
+	logOutput, err := pluginLogOutput(execCtx, args.Task.Name)
+	if err != nil {
+		return err
+	}
+	d := s.factory(args.DriverCtx.DriverContext(logOutput))
+	handle, err := d.Start(execCtx, args.Task)
+	if err != nil {
+		logOutput.Close()
+		return err
+	}
+	*id = s.serve(handle, logOutput)
+	return nil
+}
+
+func (s *DriverRPCServer) Open(args *OpenArgs, id *string) error {
+	// The plugin is still serving the handle if the client was restarted
+	s.lock.Lock()
+	_, ok := s.handles[args.HandleID]
+	s.lock.Unlock()
+	if ok {
+		*id = args.HandleID
+		return nil
+	}
+
+	execCtx := args.ExecCtx.ExecContext()
+	logOutput, err := pluginLogOutput(execCtx, args.DriverCtx.TaskName)
+	if err != nil {
+		return err
+	}
+	d := s.factory(args.DriverCtx.DriverContext(logOutput))
+	handle, err := d.Open(execCtx, args.HandleID)
+	if err != nil {
+		logOutput.Close()
+		return err
+	}
+	*id = s.serve(handle, logOutput)
+	return nil
+}
+
+func (s *DriverRPCServer) Wait(id string, resp *PluginWaitResult) error {
+	h, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	<-h.doneCh
+	s.release(id, h)
+	resp.ExitCode = h.result.ExitCode
+	resp.Signal = h.result.Signal
+	if h.result.Err != nil {
+		resp.Err = h.result.Err.Error()
+	}
+	return nil
+}
+
+func (s *DriverRPCServer) Update(args *UpdateArgs, _ *interface{}) error {
+	h, err := s.lookup(args.HandleID)
+	if err != nil {
+		return err
+	}
+	return h.handle.Update(args.Task)
+}
+
+func (s *DriverRPCServer) Kill(id string, _ *interface{}) error {
+	h, err := s.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	// Nothing is left to kill once the task has exited
+	select {
+	case <-h.doneCh:
+		s.release(id, h)
+		return nil
+	default:
+	}
+	return h.handle.Kill()
+}
+
+// serve stores the handle and waits for the task to exit in the background,
+// so that the result is kept even if the client isn't waiting for it. The
+// log output of the driver is closed once the task has exited.
+func (s *DriverRPCServer) serve(handle DriverHandle, logOutput io.Closer) string {
+	h := &pluginServedHandle{handle: handle, doneCh: make(chan struct{})}
+	id := handle.ID()
+
+	s.lock.Lock()
+	s.handles[id] = h
+	s.lock.Unlock()
+
+	go func() {
+		h.result = <-handle.WaitCh()
+		if h.result == nil {
+			h.result = cstructs.NewWaitResult(-1, 0, fmt.Errorf("task handle closed"))
+		}
+		logOutput.Close()
+		close(h.doneCh)
+	}()
+	return id
+}
+
+// release stops serving the handle once its result has been handed out.
+func (s *DriverRPCServer) release(id string, h *pluginServedHandle) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.handles[id] == h {
+		delete(s.handles, id)
+	}
+}
+
+func (s *DriverRPCServer) lookup(id string) (*pluginServedHandle, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	h, ok := s.handles[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown handle %q", id)
+	}
+	return h, nil
+}
+
+// pluginLogOutput returns the file the driver serving the task logs to. The
+// plugin outlives the client when it is restarted, so it logs to the task
+// directory rather than to the client.
+func pluginLogOutput(ctx *ExecContext, taskName string) (io.WriteCloser, error) {
+	if ctx.AllocDir == nil {
+		return nopWriteCloser{os.Stderr}, nil
+	}
+	taskDir, ok := ctx.AllocDir.TaskDirs[taskName]
+	if !ok {
+		return nil, fmt.Errorf("Could not find task directory for task: %v", taskName)
+	}
+	path := filepath.Join(taskDir, "driver-plugin.out")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open plugin log file: %v", err)
+	}
+	return f, nil
+}
+
+// nopWriteCloser is a writer that is shared by other users and so must not
+// be closed.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
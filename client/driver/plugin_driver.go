@@ -0,0 +1,192 @@
+package driver
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// PluginDriver is a driver provided by an external plugin executable. Each
+// task is served by its own plugin process, which keeps running when the
+// client is restarted so that the client can reattach to it.
+type PluginDriver struct {
+	DriverContext
+	fingerprint.StaticFingerprinter
+	name string
+	path string
+}
+
+// pluginHandle is the handle of a task served by a plugin.
+type pluginHandle struct {
+	client   *plugin.Client
+	driver   *DriverRPC
+	reattach *PluginReattachConfig
+	handleID string
+	logger   *log.Logger
+	waitCh   chan *cstructs.WaitResult
+	doneCh   chan struct{}
+}
+
+// pluginHandleID is used to reattach to the plugin serving a task.
+type pluginHandleID struct {
+	Reattach *PluginReattachConfig
+	HandleID string
+}
+
+// NewPluginDriver returns the driver called name provided by the plugin
+// executable at path.
+func NewPluginDriver(name, path string, ctx *DriverContext) Driver {
+	return &PluginDriver{DriverContext: *ctx, name: name, path: path}
+}
+
+func (d *PluginDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	client := d.newClient(nil)
+	defer client.Kill()
+
+	rpc, err := d.dispense(client)
+	if err != nil {
+		return false, err
+	}
+	return rpc.Fingerprint(&d.DriverContext, node)
+}
+
+func (d *PluginDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, error) {
+	client := d.newClient(nil)
+	rpc, err := d.dispense(client)
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+
+	handleID, err := rpc.Start(&d.DriverContext, ctx, task)
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+	return d.newHandle(client, rpc, handleID), nil
+}
+
+func (d *PluginDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
+	var id pluginHandleID
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(handleID, "PLUGIN:")), &id); err != nil {
+		return nil, fmt.Errorf("Failed to parse handle '%s': %v", handleID, err)
+	}
+	if id.Reattach == nil {
+		return nil, fmt.Errorf("handle '%s' is missing the plugin reattach config", handleID)
+	}
+	reattach, err := id.Reattach.PluginConfig()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse handle '%s': %v", handleID, err)
+	}
+
+	client := d.newClient(reattach)
+	rpc, err := d.dispense(client)
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("Failed to reattach to plugin of driver '%s': %v", d.name, err)
+	}
+
+	openedID, err := rpc.Open(&d.DriverContext, ctx, id.HandleID)
+	if err != nil {
+		client.Kill()
+		return nil, err
+	}
+	return d.newHandle(client, rpc, openedID), nil
+}
+
+// newClient returns a client that launches the plugin, or reattaches to it if
+// reattach is set.
+func (d *PluginDriver) newClient(reattach *plugin.ReattachConfig) *plugin.Client {
+	clientConfig := &plugin.ClientConfig{
+		HandshakeConfig: PluginHandshake,
+		Plugins: map[string]plugin.Plugin{
+			pluginName: new(DriverPlugin),
+		},
+	}
+	if reattach != nil {
+		clientConfig.Reattach = reattach
+	} else {
+		clientConfig.Cmd = exec.Command(d.path)
+	}
+	if d.config != nil && d.config.LogOutput != nil {
+		clientConfig.Stderr = d.config.LogOutput
+	}
+	return plugin.NewClient(clientConfig)
+}
+
+// dispense returns the driver served by the plugin.
+func (d *PluginDriver) dispense(client *plugin.Client) (*DriverRPC, error) {
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to launch plugin of driver '%s': %v", d.name, err)
+	}
+	raw, err := rpcClient.Dispense(pluginName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dispense driver '%s': %v", d.name, err)
+	}
+	return raw.(*DriverRPC), nil
+}
+
+func (d *PluginDriver) newHandle(client *plugin.Client, rpc *DriverRPC, handleID string) *pluginHandle {
+	h := &pluginHandle{
+		client:   client,
+		driver:   rpc,
+		reattach: NewPluginReattachConfig(client.ReattachConfig()),
+		handleID: handleID,
+		logger:   d.logger,
+		waitCh:   make(chan *cstructs.WaitResult, 1),
+		doneCh:   make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *pluginHandle) ID() string {
+	id := pluginHandleID{
+		Reattach: h.reattach,
+		HandleID: h.handleID,
+	}
+	data, err := json.Marshal(id)
+	if err != nil {
+		h.logger.Printf("[ERR] driver.plugin: failed to marshal handle ID: %v", err)
+	}
+	return fmt.Sprintf("PLUGIN:%s", string(data))
+}
+
+func (h *pluginHandle) WaitCh() chan *cstructs.WaitResult {
+	return h.waitCh
+}
+
+func (h *pluginHandle) Update(task *structs.Task) error {
+	return h.driver.Update(h.handleID, task)
+}
+
+func (h *pluginHandle) Kill() error {
+	select {
+	case <-h.doneCh:
+		return nil
+	default:
+	}
+	return h.driver.Kill(h.handleID)
+}
+
+// run waits for the task to exit and then stops the plugin serving it.
+func (h *pluginHandle) run() {
+	res, err := h.driver.Wait(h.handleID)
+	if err != nil {
+		res = cstructs.NewWaitResult(-1, 0, fmt.Errorf("lost connection to driver plugin: %v", err))
+	}
+	close(h.doneCh)
+	h.client.Kill()
+	h.waitCh <- res
+	close(h.waitCh)
+}
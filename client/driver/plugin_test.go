@@ -0,0 +1,294 @@
+package driver
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/helper/testtask"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/mapstructure"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// testPluginServe serves the fake driver if the test binary was launched by
+// a PluginDriver.
+func testPluginServe() bool {
+	if os.Getenv(PluginHandshake.MagicCookieKey) != PluginHandshake.MagicCookieValue {
+		return false
+	}
+	ServeDriverPlugin(newFakeDriver)
+	return true
+}
+
+// fakeDriver is a driver whose tasks exit after the configured duration with
+// the configured exit code.
+type fakeDriver struct {
+	DriverContext
+	fingerprint.StaticFingerprinter
+}
+
+type fakeDriverConfig struct {
+	RunFor   string `mapstructure:"run_for"`
+	ExitCode int    `mapstructure:"exit_code"`
+}
+
+type fakeHandle struct {
+	id       string
+	exitCode int
+	waitCh   chan *cstructs.WaitResult
+	killCh   chan struct{}
+	killOnce sync.Once
+}
+
+func newFakeDriver(ctx *DriverContext) Driver {
+	return &fakeDriver{DriverContext: *ctx}
+}
+
+func (d *fakeDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	node.Attributes["driver.fake"] = "1"
+	return true, nil
+}
+
+func (d *fakeDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, error) {
+	var conf fakeDriverConfig
+	if err := mapstructure.WeakDecode(task.Config, &conf); err != nil {
+		return nil, err
+	}
+	runFor, err := time.ParseDuration(conf.RunFor)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &fakeHandle{
+		id:       structs.GenerateUUID(),
+		exitCode: conf.ExitCode,
+		waitCh:   make(chan *cstructs.WaitResult, 1),
+		killCh:   make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-time.After(runFor):
+			h.waitCh <- cstructs.NewWaitResult(h.exitCode, 0, nil)
+		case <-h.killCh:
+			h.waitCh <- cstructs.NewWaitResult(0, 9, nil)
+		}
+	}()
+	return h, nil
+}
+
+func (d *fakeDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
+	return nil, os.ErrNotExist
+}
+
+func (h *fakeHandle) ID() string                        { return h.id }
+func (h *fakeHandle) WaitCh() chan *cstructs.WaitResult { return h.waitCh }
+func (h *fakeHandle) Update(task *structs.Task) error   { return nil }
+func (h *fakeHandle) Kill() error {
+	h.killOnce.Do(func() { close(h.killCh) })
+	return nil
+}
+
+// testPluginDriverContext returns a DriverContext whose plugin directory
+// provides the fake driver.
+func testPluginDriverContext(t *testing.T, task string) (*DriverContext, func()) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := os.Symlink(testtask.Path(), filepath.Join(dir, PluginPrefix+"fake")); err != nil {
+		os.RemoveAll(dir)
+		t.Fatalf("err: %v", err)
+	}
+
+	cfg := testConfig()
+	cfg.PluginDir = dir
+	cfg.Node = &structs.Node{Attributes: make(map[string]string)}
+	return NewDriverContext(task, cfg, cfg.Node, testLogger()), func() { os.RemoveAll(dir) }
+}
+
+func TestDiscoverPlugins(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"nomad-driver-foo", "nomad-driver-bar.exe", "other"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0755); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nomad-driver-dir"), 0755); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	plugins, err := DiscoverPlugins(dir)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]string{
+		"foo": filepath.Join(dir, "nomad-driver-foo"),
+		"bar": filepath.Join(dir, "nomad-driver-bar.exe"),
+	}
+	if !reflect.DeepEqual(plugins, expected) {
+		t.Fatalf("bad: %#v", plugins)
+	}
+}
+
+func TestPluginDriver_StartWait(t *testing.T) {
+	ctx, cleanup := testPluginDriverContext(t, "web")
+	defer cleanup()
+
+	d, err := NewDriver("fake", ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := d.(*PluginDriver); !ok {
+		t.Fatalf("expected plugin driver, got %#v", d)
+	}
+
+	applies, err := d.Fingerprint(ctx.config, ctx.node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !applies || ctx.node.Attributes["driver.fake"] != "1" {
+		t.Fatalf("bad: %v %#v", applies, ctx.node.Attributes)
+	}
+
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"run_for":   "100ms",
+			"exit_code": 3,
+		},
+		Resources: basicResources,
+	}
+	execCtx := testDriverExecContext(task, ctx)
+	defer execCtx.AllocDir.Destroy()
+
+	handle, err := d.Start(execCtx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	select {
+	case res := <-handle.WaitCh():
+		if res.ExitCode != 3 || res.Err != nil {
+			t.Fatalf("bad: %v", res)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+}
+
+func TestPluginDriver_Open(t *testing.T) {
+	ctx, cleanup := testPluginDriverContext(t, "web")
+	defer cleanup()
+
+	d, err := NewDriver("fake", ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	task := &structs.Task{
+		Name: "web",
+		Config: map[string]interface{}{
+			"run_for": "10s",
+		},
+		Resources: basicResources,
+	}
+	execCtx := testDriverExecContext(task, ctx)
+	defer execCtx.AllocDir.Destroy()
+
+	handle, err := d.Start(execCtx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Reattach to the plugin as a restarted client would
+	d2, err := NewDriver("fake", ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	handle2, err := d2.Open(execCtx, handle.ID())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if handle2 == nil {
+		t.Fatalf("missing handle")
+	}
+
+	if err := handle2.Kill(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, h := range []DriverHandle{handle, handle2} {
+		select {
+		case res := <-h.WaitCh():
+			if res.Signal != 9 {
+				t.Fatalf("bad: %v", res)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout")
+		}
+	}
+}
+
+func TestPluginDriver_Open_BadHandle(t *testing.T) {
+	ctx, cleanup := testPluginDriverContext(t, "web")
+	defer cleanup()
+
+	d, err := NewDriver("fake", ctx)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := d.Open(&ExecContext{}, "PLUGIN:{}"); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+// testCloser records whether it was closed.
+type testCloser struct {
+	closed bool
+}
+
+func (c *testCloser) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestDriverRPCServer_ReleaseHandle(t *testing.T) {
+	s := &DriverRPCServer{handles: make(map[string]*pluginServedHandle)}
+	h := &fakeHandle{
+		id:     structs.GenerateUUID(),
+		waitCh: make(chan *cstructs.WaitResult, 1),
+		killCh: make(chan struct{}),
+	}
+	logOutput := &testCloser{}
+	id := s.serve(h, logOutput)
+
+	h.waitCh <- cstructs.NewWaitResult(2, 0, nil)
+	var resp PluginWaitResult
+	if err := s.Wait(id, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.ExitCode != 2 {
+		t.Fatalf("bad: %#v", resp)
+	}
+
+	// The log output is closed and the handle forgotten once the result
+	// has been handed out
+	if !logOutput.closed {
+		t.Fatalf("log output not closed")
+	}
+	if _, err := s.lookup(id); err == nil {
+		t.Fatalf("handle still served: %#v", s.handles)
+	}
+}
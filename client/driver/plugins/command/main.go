@@ -0,0 +1,132 @@
+// Command nomad-driver-command is a reference task driver plugin. It provides
+// the "command" driver, which runs the configured command on the host without
+// isolation, and serves it to the client over the driver plugin protocol.
+//
+// To use it, build it into the client's plugin directory and enable it:
+//
+//	go build -o <plugin_dir>/nomad-driver-command ./client/driver/plugins/command
+//
+//	client {
+//		options = {
+//			"driver.command.enable" = "1"
+//		}
+//	}
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/client/driver/executor"
+	"github.com/hashicorp/nomad/client/fingerprint"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/mitchellh/mapstructure"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+const (
+	// enableOption is the client option that enables the driver.
+	enableOption = "driver.command.enable"
+)
+
+func main() {
+	driver.ServeDriverPlugin(NewCommandDriver)
+}
+
+// CommandDriver runs the task's command on the host.
+type CommandDriver struct {
+	fingerprint.StaticFingerprinter
+}
+
+type CommandDriverConfig struct {
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+}
+
+type commandHandle struct {
+	cmd    executor.Executor
+	waitCh chan *cstructs.WaitResult
+	doneCh chan struct{}
+}
+
+// NewCommandDriver is the driver.Factory of the command driver.
+func NewCommandDriver(ctx *driver.DriverContext) driver.Driver {
+	return &CommandDriver{}
+}
+
+func (d *CommandDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	// The command isn't isolated so it must be explicitly enabled.
+	if !cfg.ReadBoolDefault(enableOption, false) {
+		return false, nil
+	}
+	node.Attributes["driver.command"] = "1"
+	return true, nil
+}
+
+func (d *CommandDriver) Start(ctx *driver.ExecContext, task *structs.Task) (driver.DriverHandle, error) {
+	var driverConfig CommandDriverConfig
+	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
+		return nil, err
+	}
+	if driverConfig.Command == "" {
+		return nil, fmt.Errorf("missing command for command driver")
+	}
+
+	cmd := executor.NewBasicExecutor()
+	executor.SetCommand(cmd, driverConfig.Command, driverConfig.Args)
+	cmd.Command().Env = driver.TaskEnvironmentVariables(ctx, task).List()
+	if err := cmd.ConfigureTaskDir(task.Name, ctx.AllocDir); err != nil {
+		return nil, fmt.Errorf("failed to configure task directory: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %v", err)
+	}
+
+	h := &commandHandle{
+		cmd:    cmd,
+		waitCh: make(chan *cstructs.WaitResult, 1),
+		doneCh: make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+func (d *CommandDriver) Open(ctx *driver.ExecContext, handleID string) (driver.DriverHandle, error) {
+	// The plugin keeps serving its task across client restarts, so the
+	// handles it doesn't already serve belong to other processes.
+	return nil, fmt.Errorf("command driver can't reopen task %v", handleID)
+}
+
+func (h *commandHandle) ID() string {
+	id, _ := h.cmd.ID()
+	return id
+}
+
+func (h *commandHandle) WaitCh() chan *cstructs.WaitResult {
+	return h.waitCh
+}
+
+func (h *commandHandle) Update(task *structs.Task) error {
+	// Update is not possible
+	return nil
+}
+
+func (h *commandHandle) Kill() error {
+	h.cmd.Shutdown()
+	select {
+	case <-h.doneCh:
+		return nil
+	case <-time.After(5 * time.Second):
+		return h.cmd.ForceStop()
+	}
+}
+
+func (h *commandHandle) run() {
+	res := h.cmd.Wait()
+	close(h.doneCh)
+	h.waitCh <- res
+	close(h.waitCh)
+}
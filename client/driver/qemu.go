@@ -9,12 +9,9 @@ import (
 	"strings"
 	"time"
 
-	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
-	"github.com/hashicorp/nomad/client/driver/executor"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -32,15 +29,14 @@ type QemuDriver struct {
 }
 
 type QemuDriverConfig struct {
-	ArtifactSource string           `mapstructure:"artifact_source"`
-	Checksum       string           `mapstructure:"checksum"`
-	Accelerator    string           `mapstructure:"accelerator"`
-	PortMap        []map[string]int `mapstructure:"port_map"` // A map of host port labels and to guest ports.
+	ImagePath   string           `mapstructure:"image_path"`
+	Accelerator string           `mapstructure:"accelerator"`
+	PortMap     []map[string]int `mapstructure:"port_map"` // A map of host port labels and to guest ports.
 }
 
 // qemuHandle is returned from Start/Open as a handle to the PID
 type qemuHandle struct {
-	cmd    executor.Executor
+	cmd    *executorClient
 	waitCh chan *cstructs.WaitResult
 	doneCh chan struct{}
 }
@@ -74,8 +70,8 @@ func (d *QemuDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool,
 	return true, nil
 }
 
-// Run an existing Qemu image. Start() will pull down an existing, valid Qemu
-// image and save it to the Drivers Allocation Dir
+// Run an existing Qemu image. The image is expected to have been placed in the
+// task directory, for example by an artifact of the task.
 func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, error) {
 	var driverConfig QemuDriverConfig
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
@@ -86,10 +82,9 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 		return nil, fmt.Errorf("Only one port_map block is allowed in the qemu driver config")
 	}
 
-	// Get the image source
-	source, ok := task.Config["artifact_source"]
-	if !ok || source == "" {
-		return nil, fmt.Errorf("Missing source image Qemu driver")
+	// Get the image path
+	if driverConfig.ImagePath == "" {
+		return nil, fmt.Errorf("Missing image_path for Qemu driver")
 	}
 
 	// Qemu defaults to 128M of RAM for a given VM. Instead, we force users to
@@ -104,17 +99,7 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 		return nil, fmt.Errorf("Could not find task directory for task: %v", d.DriverContext.taskName)
 	}
 
-	// Proceed to download an artifact to be executed.
-	vmPath, err := getter.GetArtifact(
-		filepath.Join(taskDir, allocdir.TaskLocal),
-		driverConfig.ArtifactSource,
-		driverConfig.Checksum,
-		d.logger,
-	)
-	if err != nil {
-		return nil, err
-	}
-
+	vmPath := filepath.Join(taskDir, driverConfig.ImagePath)
 	vmID := filepath.Base(vmPath)
 
 	// Parse configuration arguments
@@ -179,19 +164,18 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 		)
 	}
 
-	// Setup the command
-	cmd := executor.Command(args[0], args[1:]...)
-	if err := cmd.Limit(task.Resources); err != nil {
-		return nil, fmt.Errorf("failed to constrain resources: %s", err)
-	}
-
-	if err := cmd.ConfigureTaskDir(d.taskName, ctx.AllocDir); err != nil {
-		return nil, fmt.Errorf("failed to configure task directory: %v", err)
-	}
-
+	// Launch the command in its executor process
 	d.logger.Printf("[DEBUG] Starting QemuVM command: %q", strings.Join(args, " "))
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start command: %v", err)
+	cmd, err := launchExecutor(&d.DriverContext, &ExecutorLaunchArgs{
+		Cmd:       args[0],
+		Args:      args[1:],
+		TaskName:  d.taskName,
+		AllocDir:  ctx.AllocDir,
+		Resources: task.Resources,
+		Isolated:  true,
+	})
+	if err != nil {
+		return nil, err
 	}
 	d.logger.Printf("[INFO] Started new QemuVM: %s", vmID)
 
@@ -207,8 +191,8 @@ func (d *QemuDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle,
 }
 
 func (d *QemuDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
-	// Find the process
-	cmd, err := executor.OpenId(handleID)
+	// Reconnect to the executor of the task
+	cmd, err := openExecutor(&d.DriverContext, handleID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", handleID, err)
 	}
@@ -224,8 +208,7 @@ func (d *QemuDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, erro
 }
 
 func (h *qemuHandle) ID() string {
-	id, _ := h.cmd.ID()
-	return id
+	return h.cmd.ID()
 }
 
 func (h *qemuHandle) WaitCh() chan *cstructs.WaitResult {
@@ -237,6 +220,10 @@ func (h *qemuHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+func (h *qemuHandle) Stats() (*cstructs.TaskResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 // TODO: allow a 'shutdown_command' that can be executed over a ssh connection
 // to the VM
 func (h *qemuHandle) Kill() error {
@@ -40,14 +40,21 @@ func TestQemuDriver_StartOpen_Wait(t *testing.T) {
 	task := &structs.Task{
 		Name: "linux",
 		Config: map[string]interface{}{
-			"artifact_source": "https://dl.dropboxusercontent.com/u/47675/jar_thing/linux-0.2.img",
-			"checksum":        "sha256:a5e836985934c3392cbbd9b26db55a7d35a8d7ae1deb7ca559dd9c0159572544",
-			"accelerator":     "tcg",
+			"image_path":  "local/linux-0.2.img",
+			"accelerator": "tcg",
 			"port_map": []map[string]int{{
 				"main": 22,
 				"web":  8080,
 			}},
 		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: "https://dl.dropboxusercontent.com/u/47675/jar_thing/linux-0.2.img",
+				GetterOptions: map[string]string{
+					"checksum": "sha256:a5e836985934c3392cbbd9b26db55a7d35a8d7ae1deb7ca559dd9c0159572544",
+				},
+			},
+		},
 		Resources: &structs.Resources{
 			CPU:      500,
 			MemoryMB: 512,
@@ -94,11 +101,10 @@ func TestQemuDriver_RequiresMemory(t *testing.T) {
 	task := &structs.Task{
 		Name: "linux",
 		Config: map[string]interface{}{
-			"artifact_source": "https://dl.dropboxusercontent.com/u/47675/jar_thing/linux-0.2.img",
-			"accelerator":     "tcg",
-			"host_port":       "8080",
-			"guest_port":      "8081",
-			"checksum":        "sha256:a5e836985934c3392cbbd9b26db55a7d35a8d7ae1deb7ca559dd9c0159572544",
+			"image_path":  "local/linux-0.2.img",
+			"accelerator": "tcg",
+			"host_port":   "8080",
+			"guest_port":  "8081",
 			// ssh u/p would be here
 		},
 	}
@@ -2,15 +2,12 @@ package driver
 
 import (
 	"fmt"
-	"path/filepath"
+	"os"
 	"time"
 
-	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
-	"github.com/hashicorp/nomad/client/driver/executor"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -30,7 +27,7 @@ type RawExecDriver struct {
 
 // rawExecHandle is returned from Start/Open as a handle to the PID
 type rawExecHandle struct {
-	cmd    executor.Executor
+	cmd    *executorClient
 	waitCh chan *cstructs.WaitResult
 	doneCh chan struct{}
 }
@@ -58,53 +55,26 @@ func (d *RawExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandl
 	if err := mapstructure.WeakDecode(task.Config, &driverConfig); err != nil {
 		return nil, err
 	}
-	// Get the tasks local directory.
-	taskName := d.DriverContext.taskName
-	taskDir, ok := ctx.AllocDir.TaskDirs[taskName]
-	if !ok {
-		return nil, fmt.Errorf("Could not find task directory for task: %v", d.DriverContext.taskName)
-	}
-
 	// Get the command to be ran
 	command := driverConfig.Command
 	if command == "" {
 		return nil, fmt.Errorf("missing command for Raw Exec driver")
 	}
 
-	// Check if an artificat is specified and attempt to download it
-	source, ok := task.Config["artifact_source"]
-	if ok && source != "" {
-		// Proceed to download an artifact to be executed.
-		_, err := getter.GetArtifact(
-			filepath.Join(taskDir, allocdir.TaskLocal),
-			driverConfig.ArtifactSource,
-			driverConfig.Checksum,
-			d.logger,
-		)
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	// Get the environment variables.
 	envVars := TaskEnvironmentVariables(ctx, task)
 
-	// Setup the command
-	cmd := executor.NewBasicExecutor()
-	executor.SetCommand(cmd, command, driverConfig.Args)
-	if err := cmd.Limit(task.Resources); err != nil {
-		return nil, fmt.Errorf("failed to constrain resources: %s", err)
-	}
-
-	// Populate environment variables
-	cmd.Command().Env = envVars.List()
-
-	if err := cmd.ConfigureTaskDir(d.taskName, ctx.AllocDir); err != nil {
-		return nil, fmt.Errorf("failed to configure task directory: %v", err)
-	}
-
-	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start command: %v", err)
+	// Launch the command in its executor process
+	cmd, err := launchExecutor(&d.DriverContext, &ExecutorLaunchArgs{
+		Cmd:       command,
+		Args:      driverConfig.Args,
+		Env:       envVars.List(),
+		TaskName:  d.taskName,
+		AllocDir:  ctx.AllocDir,
+		Resources: task.Resources,
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return a driver handle
@@ -118,9 +88,9 @@ func (d *RawExecDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandl
 }
 
 func (d *RawExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, error) {
-	// Find the process
-	cmd := executor.NewBasicExecutor()
-	if err := cmd.Open(handleID); err != nil {
+	// Reconnect to the executor of the task
+	cmd, err := openExecutor(&d.DriverContext, handleID)
+	if err != nil {
 		return nil, fmt.Errorf("failed to open ID %v: %v", handleID, err)
 	}
 
@@ -135,8 +105,7 @@ func (d *RawExecDriver) Open(ctx *ExecContext, handleID string) (DriverHandle, e
 }
 
 func (h *rawExecHandle) ID() string {
-	id, _ := h.cmd.ID()
-	return id
+	return h.cmd.ID()
 }
 
 func (h *rawExecHandle) WaitCh() chan *cstructs.WaitResult {
@@ -148,6 +117,20 @@ func (h *rawExecHandle) Update(task *structs.Task) error {
 	return nil
 }
 
+// Exec runs the command as a plain process in the task directory, without
+// any isolation.
+func (h *rawExecHandle) Exec(req *cstructs.ExecRequest) (int, error) {
+	return h.cmd.Exec(req)
+}
+
+func (h *rawExecHandle) Signal(sig os.Signal) error {
+	return h.cmd.Signal(sig)
+}
+
+func (h *rawExecHandle) Stats() (*cstructs.TaskResourceUsage, error) {
+	return h.cmd.Stats()
+}
+
 func (h *rawExecHandle) Kill() error {
 	h.cmd.Shutdown()
 	select {
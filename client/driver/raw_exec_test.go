@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver/environment"
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/helper/testtask"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -102,9 +104,13 @@ func TestRawExecDriver_Start_Artifact_basic(t *testing.T) {
 	task := &structs.Task{
 		Name: "sleep",
 		Config: map[string]interface{}{
-			"artifact_source": fmt.Sprintf("%s/%s", ts.URL, file),
-			"command":         filepath.Join("$NOMAD_TASK_DIR", file),
-			"args":            []string{"sleep", "1s"},
+			"command": filepath.Join("$NOMAD_TASK_DIR", file),
+			"args":    []string{"sleep", "1s"},
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: fmt.Sprintf("%s/%s", ts.URL, file),
+			},
 		},
 		Resources: basicResources,
 	}
@@ -150,9 +156,13 @@ func TestRawExecDriver_Start_Artifact_expanded(t *testing.T) {
 	task := &structs.Task{
 		Name: "sleep",
 		Config: map[string]interface{}{
-			"artifact_source": fmt.Sprintf("%s/%s", ts.URL, file),
-			"command":         filepath.Join("$NOMAD_TASK_DIR", file),
-			"args":            []string{"sleep", "1s"},
+			"command": filepath.Join("$NOMAD_TASK_DIR", file),
+			"args":    []string{"sleep", "1s"},
+		},
+		Artifacts: []*structs.TaskArtifact{
+			&structs.TaskArtifact{
+				GetterSource: fmt.Sprintf("%s/%s", ts.URL, file),
+			},
 		},
 		Resources: basicResources,
 	}
@@ -283,6 +293,54 @@ func TestRawExecDriver_Start_Wait_AllocDir(t *testing.T) {
 	}
 }
 
+func TestRawExecDriver_Exec(t *testing.T) {
+	t.Parallel()
+	task := &structs.Task{
+		Name: "sleep",
+		Config: map[string]interface{}{
+			"command": testtask.Path(),
+			"args":    []string{"sleep", "2s"},
+		},
+		Resources: basicResources,
+	}
+	testtask.SetTaskEnv(task)
+
+	driverCtx := testDriverContext(task.Name)
+	ctx := testDriverExecContext(task, driverCtx)
+	defer ctx.AllocDir.Destroy()
+
+	d := NewRawExecDriver(driverCtx)
+	handle, err := d.Start(ctx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if handle == nil {
+		t.Fatalf("missing handle")
+	}
+	defer handle.Kill()
+
+	execHandle, ok := handle.(ExecHandle)
+	if !ok {
+		t.Fatalf("handle does not support exec")
+	}
+
+	var stdout, stderr bytes.Buffer
+	code, err := execHandle.Exec(&cstructs.ExecRequest{
+		Cmd:    []string{testtask.Path(), "echo", "hello"},
+		Stdout: &stdout,
+		Stderr: &stderr,
+	})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if code != 0 {
+		t.Fatalf("bad exit code: %d; stderr: %s", code, stderr.String())
+	}
+	if out := stdout.String(); out != "hello\n" {
+		t.Fatalf("bad output: %q", out)
+	}
+}
+
 func TestRawExecDriver_Start_Kill_Wait(t *testing.T) {
 	t.Parallel()
 	task := &structs.Task{
@@ -16,9 +16,9 @@ import (
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/client/driver/environment"
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
 	"github.com/hashicorp/nomad/client/fingerprint"
-	"github.com/hashicorp/nomad/helper/args"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/mitchellh/mapstructure"
 )
@@ -88,6 +88,16 @@ func (d *RktDriver) Fingerprint(cfg *config.Config, node *structs.Node) (bool, e
 	return true, nil
 }
 
+// TaskEnvironment returns the environment of the task. The task directories
+// are not currently supported, so they are cleared.
+func (d *RktDriver) TaskEnvironment(ctx *ExecContext, task *structs.Task) (environment.TaskEnvironment, error) {
+	envVars := TaskEnvironmentVariables(ctx, task)
+	envVars.ClearTaskLocalDir()
+	envVars.ClearSecretsDir()
+	envVars.ClearAllocDir()
+	return envVars, nil
+}
+
 // Run an existing Rkt image.
 func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, error) {
 	var driverConfig RktDriverConfig
@@ -126,12 +136,10 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 	var cmd_args []string
 
 	// Inject the environment variables.
-	envVars := TaskEnvironmentVariables(ctx, task)
-
-	// Clear the task directories as they are not currently supported.
-	envVars.ClearTaskLocalDir()
-	envVars.ClearAllocDir()
-
+	envVars, err := d.TaskEnvironment(ctx, task)
+	if err != nil {
+		return nil, err
+	}
 	for k, v := range envVars.Map() {
 		cmd_args = append(cmd_args, fmt.Sprintf("--set-env=%v=%v", k, v))
 	}
@@ -151,14 +159,10 @@ func (d *RktDriver) Start(ctx *ExecContext, task *structs.Task) (DriverHandle, e
 
 	// Add user passed arguments.
 	if len(driverConfig.Args) != 0 {
-		parsed := args.ParseAndReplace(driverConfig.Args, envVars.Map())
-
 		// Need to start arguments with "--"
-		if len(parsed) > 0 {
-			cmd_args = append(cmd_args, "--")
-		}
+		cmd_args = append(cmd_args, "--")
 
-		for _, arg := range parsed {
+		for _, arg := range driverConfig.Args {
 			cmd_args = append(cmd_args, fmt.Sprintf("%v", arg))
 		}
 	}
@@ -1,6 +1,9 @@
 package structs
 
-import "fmt"
+import (
+	"fmt"
+	"io"
+)
 
 // WaitResult stores the result of a Wait operation.
 type WaitResult struct {
@@ -25,3 +28,126 @@ func (r *WaitResult) String() string {
 	return fmt.Sprintf("Wait returned exit code %v, signal %v, and error %v",
 		r.ExitCode, r.Signal, r.Err)
 }
+
+// BindMount is a directory of the host mounted into the filesystem of a task.
+type BindMount struct {
+	// HostPath is the directory of the host.
+	HostPath string
+
+	// TaskPath is the path the directory is mounted at, relative to the root
+	// of the task's filesystem.
+	TaskPath string
+
+	ReadOnly bool
+}
+
+// TerminalSize is the size of a terminal in rows and columns.
+type TerminalSize struct {
+	Height uint16
+	Width  uint16
+}
+
+// ExecRequest describes a command to be run inside the isolation context of
+// an already running task.
+type ExecRequest struct {
+	// Cmd is the command and its arguments.
+	Cmd []string
+
+	// Tty controls whether the command is attached to a pseudo-terminal.
+	Tty bool
+
+	// Stdin, Stdout and Stderr are the streams of the command. Stdin may be
+	// nil. When Tty is set, all output is written to Stdout.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ResizeCh delivers terminal size changes while the command runs. It is
+	// only consulted when Tty is set.
+	ResizeCh <-chan TerminalSize
+}
+
+// Validate returns an error if the request can't be executed.
+func (r *ExecRequest) Validate() error {
+	if len(r.Cmd) == 0 || r.Cmd[0] == "" {
+		return fmt.Errorf("missing command to exec")
+	}
+	if r.Stdout == nil || r.Stderr == nil {
+		return fmt.Errorf("exec requires stdout and stderr writers")
+	}
+	return nil
+}
+
+// MemoryStats holds the memory usage of a task.
+type MemoryStats struct {
+	RSS      uint64
+	Cache    uint64
+	Swap     uint64
+	MaxUsage uint64
+
+	// Measured lists the fields that the driver measures.
+	Measured []string
+}
+
+// CpuStats holds the CPU usage of a task. Percentages are relative to a
+// single core.
+type CpuStats struct {
+	SystemMode       float64
+	UserMode         float64
+	Percent          float64
+	ThrottledPeriods uint64
+	ThrottledTime    uint64
+
+	// TotalTicks is the CPU used in MHz and ReservedPercent is the percentage
+	// of the CPU reserved by the task that this represents. Both are filled
+	// in by the client from the node's CPU frequency.
+	TotalTicks      float64
+	ReservedPercent float64
+
+	// Measured lists the fields that the driver measures.
+	Measured []string
+}
+
+// ResourceUsage holds the memory and CPU usage of a task or allocation.
+type ResourceUsage struct {
+	MemoryStats *MemoryStats
+	CpuStats    *CpuStats
+}
+
+// Add adds the usage of other to the usage.
+func (r *ResourceUsage) Add(other *ResourceUsage) {
+	if other.MemoryStats != nil {
+		if r.MemoryStats == nil {
+			r.MemoryStats = &MemoryStats{}
+		}
+		r.MemoryStats.RSS += other.MemoryStats.RSS
+		r.MemoryStats.Cache += other.MemoryStats.Cache
+		r.MemoryStats.Swap += other.MemoryStats.Swap
+		r.MemoryStats.MaxUsage += other.MemoryStats.MaxUsage
+	}
+	if other.CpuStats != nil {
+		if r.CpuStats == nil {
+			r.CpuStats = &CpuStats{}
+		}
+		r.CpuStats.SystemMode += other.CpuStats.SystemMode
+		r.CpuStats.UserMode += other.CpuStats.UserMode
+		r.CpuStats.Percent += other.CpuStats.Percent
+		r.CpuStats.ThrottledPeriods += other.CpuStats.ThrottledPeriods
+		r.CpuStats.ThrottledTime += other.CpuStats.ThrottledTime
+		r.CpuStats.TotalTicks += other.CpuStats.TotalTicks
+	}
+}
+
+// TaskResourceUsage is a sample of the resource usage of a task.
+type TaskResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Timestamp     int64 // Unix Nanosecond timestamp
+}
+
+// AllocResourceUsage is the resource usage of an allocation, which is the sum
+// of the latest samples of its tasks.
+type AllocResourceUsage struct {
+	ResourceUsage *ResourceUsage
+	Tasks         map[string]*TaskResourceUsage
+	Timestamp     int64 // Unix Nanosecond timestamp
+}
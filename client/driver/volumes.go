@@ -0,0 +1,50 @@
+package driver
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+// taskBindMounts resolves the volumes mounted by the task into the
+// directories of the host volumes backing them. A volume is mounted
+// read-only if the host volume, the task group's request or the mount asks
+// for it.
+func taskBindMounts(cfg *config.Config, ctx *ExecContext, task *structs.Task) ([]*cstructs.BindMount, error) {
+	if len(task.VolumeMounts) == 0 {
+		return nil, nil
+	}
+	if ctx.Alloc == nil || ctx.Alloc.Job == nil {
+		return nil, fmt.Errorf("allocation of task %q is missing its job", task.Name)
+	}
+	tg := ctx.Alloc.Job.LookupTaskGroup(ctx.Alloc.TaskGroup)
+	if tg == nil {
+		return nil, fmt.Errorf("task group %q not found", ctx.Alloc.TaskGroup)
+	}
+
+	mounts := make([]*cstructs.BindMount, 0, len(task.VolumeMounts))
+	for _, m := range task.VolumeMounts {
+		req, ok := tg.Volumes[m.Volume]
+		if !ok {
+			return nil, fmt.Errorf("volume %q is not requested by the task group", m.Volume)
+		}
+		if req.Type != structs.VolumeTypeHost {
+			return nil, fmt.Errorf("volume %q has unsupported type %q", m.Volume, req.Type)
+		}
+		hostVol, ok := cfg.HostVolumes[req.Source]
+		if !ok {
+			return nil, fmt.Errorf("host volume %q not found on the client", req.Source)
+		}
+
+		mounts = append(mounts, &cstructs.BindMount{
+			HostPath: hostVol.Path,
+			TaskPath: filepath.Join("/", m.Destination),
+			ReadOnly: hostVol.ReadOnly || req.ReadOnly || m.ReadOnly,
+		})
+	}
+	return mounts, nil
+}
@@ -0,0 +1,52 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+)
+
+func TestTaskBindMounts(t *testing.T) {
+	cfg := testConfig()
+	cfg.HostVolumes = map[string]*structs.ClientHostVolumeConfig{
+		"ca-certificates": &structs.ClientHostVolumeConfig{Name: "ca-certificates", Path: "/etc/ssl/certs", ReadOnly: true},
+		"data":            &structs.ClientHostVolumeConfig{Name: "data", Path: "/srv/data"},
+	}
+
+	alloc := mock.Alloc()
+	tg := alloc.Job.LookupTaskGroup(alloc.TaskGroup)
+	tg.Volumes = map[string]*structs.VolumeRequest{
+		"certs": &structs.VolumeRequest{Name: "certs", Type: structs.VolumeTypeHost, Source: "ca-certificates"},
+		"data":  &structs.VolumeRequest{Name: "data", Type: structs.VolumeTypeHost, Source: "data"},
+	}
+	ctx := &ExecContext{AllocID: alloc.ID, Alloc: alloc}
+
+	task := &structs.Task{
+		Name: "web",
+		VolumeMounts: []*structs.VolumeMount{
+			&structs.VolumeMount{Volume: "certs", Destination: "etc/ssl/certs"},
+			&structs.VolumeMount{Volume: "data", Destination: "/srv/data"},
+		},
+	}
+	mounts, err := taskBindMounts(cfg, ctx, task)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := []*cstructs.BindMount{
+		&cstructs.BindMount{HostPath: "/etc/ssl/certs", TaskPath: "/etc/ssl/certs", ReadOnly: true},
+		&cstructs.BindMount{HostPath: "/srv/data", TaskPath: "/srv/data"},
+	}
+	if !reflect.DeepEqual(mounts, expected) {
+		t.Fatalf("bad: %#v", mounts)
+	}
+
+	// Volumes missing on the client can't be mounted
+	delete(cfg.HostVolumes, "data")
+	if _, err := taskBindMounts(cfg, ctx, task); err == nil {
+		t.Fatalf("expected error for missing host volume")
+	}
+}
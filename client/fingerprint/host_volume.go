@@ -0,0 +1,50 @@
+package fingerprint
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// HostVolumeFingerprint is used to fingerprint the host volumes declared in
+// the client configuration, so that task groups requesting them can only be
+// placed on nodes providing them.
+type HostVolumeFingerprint struct {
+	StaticFingerprinter
+	logger *log.Logger
+}
+
+// NewHostVolumeFingerprint is used to create a host volume fingerprint
+func NewHostVolumeFingerprint(logger *log.Logger) Fingerprint {
+	f := &HostVolumeFingerprint{logger: logger}
+	return f
+}
+
+// Fingerprint registers each host volume as the node attribute
+// "host_volume.<name>" holding its path, and "host_volume.<name>.read_only"
+// if it can only be mounted read-only.
+func (f *HostVolumeFingerprint) Fingerprint(cfg *config.Config, node *structs.Node) (bool, error) {
+	if len(cfg.HostVolumes) == 0 {
+		return false, nil
+	}
+
+	for name, vol := range cfg.HostVolumes {
+		fi, err := os.Stat(vol.Path)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat host volume %q: %v", name, err)
+		}
+		if !fi.IsDir() {
+			return false, fmt.Errorf("path %q of host volume %q is not a directory", vol.Path, name)
+		}
+
+		key := fmt.Sprintf("host_volume.%s", name)
+		node.Attributes[key] = vol.Path
+		if vol.ReadOnly {
+			node.Attributes[key+".read_only"] = "true"
+		}
+	}
+	return true, nil
+}
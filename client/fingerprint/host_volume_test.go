@@ -0,0 +1,60 @@
+package fingerprint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/config"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHostVolumeFingerprint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	f := NewHostVolumeFingerprint(testLogger())
+	node := &structs.Node{
+		Attributes: make(map[string]string),
+	}
+
+	// Without volumes the fingerprint doesn't apply
+	ok, err := f.Fingerprint(&config.Config{}, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ok {
+		t.Fatalf("should not apply")
+	}
+
+	cfg := &config.Config{
+		HostVolumes: map[string]*structs.ClientHostVolumeConfig{
+			"data":  &structs.ClientHostVolumeConfig{Name: "data", Path: dir},
+			"certs": &structs.ClientHostVolumeConfig{Name: "certs", Path: dir, ReadOnly: true},
+		},
+	}
+	ok, err = f.Fingerprint(cfg, node)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !ok {
+		t.Fatalf("should apply")
+	}
+
+	assertNodeAttributeEquals(t, node, "host_volume.data", dir)
+	assertNodeAttributeEquals(t, node, "host_volume.certs", dir)
+	assertNodeAttributeEquals(t, node, "host_volume.certs.read_only", "true")
+	if _, ok := node.Attributes["host_volume.data.read_only"]; ok {
+		t.Fatalf("read-write volume shouldn't be marked read-only")
+	}
+
+	// Volumes must be existing directories
+	cfg.HostVolumes["data"].Path = filepath.Join(dir, "missing")
+	if _, err := f.Fingerprint(cfg, node); err == nil {
+		t.Fatalf("expected error for missing path")
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"container/list"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/nomad/client/stats"
+)
+
+// GCConfig configures when the garbage collector destroys terminal
+// allocations.
+type GCConfig struct {
+	// Interval is the interval at which the usage of the alloc dir's disk is
+	// checked.
+	Interval time.Duration
+
+	// DiskUsageThreshold and InodeUsageThreshold are the percentages of the
+	// disk space and inodes of the alloc dir's disk above which terminal
+	// allocations are destroyed.
+	DiskUsageThreshold  float64
+	InodeUsageThreshold float64
+
+	// MaxAllocs is the number of terminal allocations that are retained. Zero
+	// means no limit.
+	MaxAllocs int
+}
+
+// AllocGarbageCollector destroys the allocation directories of terminal
+// allocations, oldest first, when the disk the allocations are stored on runs
+// out of space or inodes or when too many terminal allocations are retained.
+// Otherwise they are only destroyed once the servers remove them.
+type AllocGarbageCollector struct {
+	config   *GCConfig
+	allocDir string
+	logger   *log.Logger
+
+	// collected is invoked with the runners of the allocations once they
+	// have been destroyed.
+	collected func(ar *AllocRunner)
+
+	// diskStats returns the usage of the disk of the given path.
+	diskStats func(path string) (*stats.DiskStats, error)
+
+	// allocRunners holds the terminal allocations in the order they became
+	// terminal and index holds their elements by allocation ID.
+	allocRunners *list.List
+	index        map[string]*list.Element
+	lock         sync.Mutex
+
+	shutdownCh chan struct{}
+}
+
+// NewAllocGarbageCollector returns a garbage collector of the allocations
+// stored in allocDir. collected, if set, is invoked with the runner of each
+// allocation the garbage collector destroys.
+func NewAllocGarbageCollector(logger *log.Logger, config *GCConfig, allocDir string,
+	collected func(ar *AllocRunner)) *AllocGarbageCollector {
+	return &AllocGarbageCollector{
+		config:       config,
+		allocDir:     allocDir,
+		logger:       logger,
+		collected:    collected,
+		diskStats:    stats.PathDiskStats,
+		allocRunners: list.New(),
+		index:        make(map[string]*list.Element),
+		shutdownCh:   make(chan struct{}),
+	}
+}
+
+// Run periodically destroys terminal allocations while the usage thresholds
+// are exceeded, until the garbage collector is stopped.
+func (g *AllocGarbageCollector) Run() {
+	interval := g.config.Interval
+	if interval == 0 {
+		interval = 1 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := g.keepUsageBelowThreshold(); err != nil {
+				g.logger.Printf("[ERR] client: error garbage collecting allocations: %v", err)
+			}
+		case <-g.shutdownCh:
+			return
+		}
+	}
+}
+
+// Stop stops the periodic garbage collection.
+func (g *AllocGarbageCollector) Stop() {
+	close(g.shutdownCh)
+}
+
+// MarkForCollection adds the runner of a terminal allocation to the
+// allocations that may be destroyed. Once the limit of retained allocations
+// is exceeded the oldest ones are destroyed.
+func (g *AllocGarbageCollector) MarkForCollection(ar *AllocRunner) {
+	if ar.IsDestroyed() {
+		return
+	}
+
+	g.lock.Lock()
+	id := ar.Alloc().ID
+	if _, ok := g.index[id]; ok {
+		g.lock.Unlock()
+		return
+	}
+	g.logger.Printf("[DEBUG] client: marking alloc '%s' for garbage collection", id)
+	g.index[id] = g.allocRunners.PushBack(ar)
+	exceeded := g.config.MaxAllocs > 0 && g.allocRunners.Len() > g.config.MaxAllocs
+	g.lock.Unlock()
+
+	if exceeded {
+		go func() {
+			if err := g.keepUsageBelowThreshold(); err != nil {
+				g.logger.Printf("[ERR] client: error garbage collecting allocations: %v", err)
+			}
+		}()
+	}
+}
+
+// Remove stops tracking the allocation, which is destroyed by the client.
+func (g *AllocGarbageCollector) Remove(allocID string) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if e, ok := g.index[allocID]; ok {
+		g.allocRunners.Remove(e)
+		delete(g.index, allocID)
+	}
+}
+
+// CollectAll destroys all the terminal allocations.
+func (g *AllocGarbageCollector) CollectAll() {
+	for {
+		ar := g.pop()
+		if ar == nil {
+			return
+		}
+		g.destroyAllocRunner(ar, "forced collection")
+	}
+}
+
+// keepUsageBelowThreshold destroys terminal allocations, oldest first, until
+// neither the number of retained allocations nor the usage of the disk
+// exceeds its threshold.
+func (g *AllocGarbageCollector) keepUsageBelowThreshold() error {
+	for {
+		g.lock.Lock()
+		retained := g.allocRunners.Len()
+		g.lock.Unlock()
+		if retained == 0 {
+			return nil
+		}
+
+		var reason string
+		if g.config.MaxAllocs > 0 && retained > g.config.MaxAllocs {
+			reason = fmt.Sprintf("number of terminal allocations (%d) is over the limit (%d)",
+				retained, g.config.MaxAllocs)
+		} else {
+			ds, err := g.diskStats(g.allocDir)
+			if err != nil {
+				return fmt.Errorf("failed to get the usage of the alloc dir's disk: %v", err)
+			}
+			if ds.UsedPercent > g.config.DiskUsageThreshold {
+				reason = fmt.Sprintf("disk usage of %.0f%% is over the threshold of %.0f%%",
+					ds.UsedPercent, g.config.DiskUsageThreshold)
+			} else if ds.InodesUsedPercent > g.config.InodeUsageThreshold {
+				reason = fmt.Sprintf("inode usage of %.0f%% is over the threshold of %.0f%%",
+					ds.InodesUsedPercent, g.config.InodeUsageThreshold)
+			}
+		}
+		if reason == "" {
+			return nil
+		}
+
+		ar := g.pop()
+		if ar == nil {
+			return nil
+		}
+		g.destroyAllocRunner(ar, reason)
+	}
+}
+
+// pop removes the oldest terminal allocation and returns its runner, or nil
+// if there are none.
+func (g *AllocGarbageCollector) pop() *AllocRunner {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	e := g.allocRunners.Front()
+	if e == nil {
+		return nil
+	}
+	ar := g.allocRunners.Remove(e).(*AllocRunner)
+	delete(g.index, ar.Alloc().ID)
+	return ar
+}
+
+// destroyAllocRunner destroys the allocation and waits for its directory to
+// be removed.
+func (g *AllocGarbageCollector) destroyAllocRunner(ar *AllocRunner, reason string) {
+	g.logger.Printf("[INFO] client: garbage collecting alloc '%s': %s", ar.Alloc().ID, reason)
+	ar.Destroy()
+	<-ar.WaitCh()
+	if g.collected != nil {
+		g.collected(ar)
+	}
+}
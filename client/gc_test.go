@@ -0,0 +1,156 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/client/stats"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func testGarbageCollector(config *GCConfig, usage *stats.DiskStats) *AllocGarbageCollector {
+	gc := NewAllocGarbageCollector(testLogger(), config, "/tmp", nil)
+	gc.diskStats = func(string) (*stats.DiskStats, error) {
+		return usage, nil
+	}
+	return gc
+}
+
+func testTerminalAllocRunner() *AllocRunner {
+	_, ar := testAllocRunner(false)
+	ar.alloc.ClientStatus = structs.AllocClientStatusDead
+	go ar.Run()
+	return ar
+}
+
+func waitDestroyed(t *testing.T, ar *AllocRunner) {
+	select {
+	case <-ar.WaitCh():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("alloc '%s' was not destroyed", ar.Alloc().ID)
+	}
+}
+
+func TestAllocGarbageCollector_MarkForCollection(t *testing.T) {
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70}, &stats.DiskStats{})
+	ar := testTerminalAllocRunner()
+	defer ar.Destroy()
+
+	gc.MarkForCollection(ar)
+	gc.MarkForCollection(ar)
+	if n := gc.allocRunners.Len(); n != 1 {
+		t.Fatalf("expected 1 alloc, got: %d", n)
+	}
+
+	gc.Remove(ar.Alloc().ID)
+	if n := gc.allocRunners.Len(); n != 0 {
+		t.Fatalf("expected no allocs, got: %d", n)
+	}
+
+	// Destroyed allocations are not tracked
+	ar.Destroy()
+	gc.MarkForCollection(ar)
+	if n := gc.allocRunners.Len(); n != 0 {
+		t.Fatalf("expected no allocs, got: %d", n)
+	}
+}
+
+func TestAllocGarbageCollector_MaxAllocs(t *testing.T) {
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70, MaxAllocs: 1},
+		&stats.DiskStats{})
+	ar1 := testTerminalAllocRunner()
+	defer ar1.Destroy()
+	ar2 := testTerminalAllocRunner()
+	defer ar2.Destroy()
+
+	gc.MarkForCollection(ar1)
+	gc.MarkForCollection(ar2)
+
+	// The oldest allocation is destroyed
+	waitDestroyed(t, ar1)
+	if ar2.IsDestroyed() {
+		t.Fatalf("alloc '%s' should be retained", ar2.Alloc().ID)
+	}
+}
+
+func TestAllocGarbageCollector_DiskUsage(t *testing.T) {
+	usage := &stats.DiskStats{UsedPercent: 90}
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70}, usage)
+	gc.diskStats = func(string) (*stats.DiskStats, error) {
+		// Destroying an allocation frees up enough space
+		ds := *usage
+		usage.UsedPercent = 50
+		return &ds, nil
+	}
+
+	ar1 := testTerminalAllocRunner()
+	defer ar1.Destroy()
+	ar2 := testTerminalAllocRunner()
+	defer ar2.Destroy()
+	gc.MarkForCollection(ar1)
+	gc.MarkForCollection(ar2)
+
+	if err := gc.keepUsageBelowThreshold(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	waitDestroyed(t, ar1)
+	if ar2.IsDestroyed() {
+		t.Fatalf("alloc '%s' should be retained", ar2.Alloc().ID)
+	}
+}
+
+func TestAllocGarbageCollector_InodeUsage(t *testing.T) {
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70},
+		&stats.DiskStats{InodesUsedPercent: 90})
+	ar1 := testTerminalAllocRunner()
+	defer ar1.Destroy()
+	ar2 := testTerminalAllocRunner()
+	defer ar2.Destroy()
+	gc.MarkForCollection(ar1)
+	gc.MarkForCollection(ar2)
+
+	// All the allocations are destroyed as the usage stays above the threshold
+	if err := gc.keepUsageBelowThreshold(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	waitDestroyed(t, ar1)
+	waitDestroyed(t, ar2)
+}
+
+func TestAllocGarbageCollector_Collected(t *testing.T) {
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70}, &stats.DiskStats{})
+	collected := make(chan *AllocRunner, 1)
+	gc.collected = func(ar *AllocRunner) {
+		collected <- ar
+	}
+	ar := testTerminalAllocRunner()
+	defer ar.Destroy()
+	gc.MarkForCollection(ar)
+
+	gc.CollectAll()
+	select {
+	case out := <-collected:
+		if out != ar {
+			t.Fatalf("bad: %#v", out)
+		}
+	default:
+		t.Fatalf("alloc '%s' was not reported as collected", ar.Alloc().ID)
+	}
+}
+
+func TestAllocGarbageCollector_CollectAll(t *testing.T) {
+	gc := testGarbageCollector(&GCConfig{DiskUsageThreshold: 80, InodeUsageThreshold: 70}, &stats.DiskStats{})
+	ar1 := testTerminalAllocRunner()
+	defer ar1.Destroy()
+	ar2 := testTerminalAllocRunner()
+	defer ar2.Destroy()
+	gc.MarkForCollection(ar1)
+	gc.MarkForCollection(ar2)
+
+	gc.CollectAll()
+	waitDestroyed(t, ar1)
+	waitDestroyed(t, ar2)
+	if n := gc.allocRunners.Len(); n != 0 {
+		t.Fatalf("expected no allocs, got: %d", n)
+	}
+}
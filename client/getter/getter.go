@@ -4,40 +4,94 @@ import (
 	"fmt"
 	"log"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
 	"runtime"
-	"strings"
-	"syscall"
 
 	gg "github.com/hashicorp/go-getter"
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
-func GetArtifact(destDir, source, checksum string, logger *log.Logger) (string, error) {
-	if source == "" {
-		return "", fmt.Errorf("Source url is empty in Artifact Getter")
+// GetArtifact downloads an artifact into the task directory. Archives are
+// unpacked into the destination and sources supported by go-getter, such as
+// git, hg and S3, may be used. Artifacts downloaded as a single file are made
+// executable.
+func GetArtifact(artifact *structs.TaskArtifact, taskDir string, logger *log.Logger) error {
+	if artifact.GetterSource == "" {
+		return fmt.Errorf("Source url is empty in Artifact Getter")
 	}
-	u, err := url.Parse(source)
+
+	source, err := getterURL(artifact)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	// Downloads default to the task's local directory
+	relDest := artifact.RelativeDest
+	if relDest == "" {
+		relDest = allocdir.TaskLocal
 	}
+	dest := filepath.Join(taskDir, relDest)
 
-	// if checksum is seperate, apply to source
-	if checksum != "" {
-		source = strings.Join([]string{source, fmt.Sprintf("checksum=%s", checksum)}, "?")
-		logger.Printf("[DEBUG] client.getter: Applying checksum to Artifact Source URL, new url: %s", source)
+	var mode gg.ClientMode
+	switch artifact.Mode {
+	case structs.TaskArtifactModeFile:
+		mode = gg.ClientModeFile
+	case structs.TaskArtifactModeDir:
+		mode = gg.ClientModeDir
+	default:
+		mode = gg.ClientModeAny
 	}
 
-	artifactFile := filepath.Join(destDir, path.Base(u.Path))
-	if err := gg.GetFile(artifactFile, source); err != nil {
-		return "", fmt.Errorf("Error downloading artifact: %s", err)
+	logger.Printf("[DEBUG] client.getter: downloading artifact %q to %q", artifact.GetterSource, dest)
+	client := &gg.Client{
+		Src:  source,
+		Dst:  dest,
+		Mode: mode,
+	}
+	if err := client.Get(); err != nil {
+		return fmt.Errorf("Error downloading artifact %q: %v", artifact.GetterSource, err)
 	}
 
-	// Add execution permissions to the newly downloaded artifact
-	if runtime.GOOS != "windows" {
-		if err := syscall.Chmod(artifactFile, 0755); err != nil {
-			logger.Printf("[ERR] driver.raw_exec: Error making artifact executable: %s", err)
+	// Make single file artifacts executable. Files unpacked from archives
+	// keep their permissions.
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	file := dest
+	if mode == gg.ClientModeAny {
+		u, err := url.Parse(artifact.GetterSource)
+		if err != nil {
+			return err
+		}
+		file = filepath.Join(dest, path.Base(u.Path))
+	}
+	if fi, err := os.Stat(file); err == nil && fi.Mode().IsRegular() {
+		if err := os.Chmod(file, 0755); err != nil {
+			logger.Printf("[ERR] client.getter: error making artifact %q executable: %v", file, err)
 		}
 	}
-	return artifactFile, nil
+	return nil
+}
+
+// getterURL returns the go-getter URL of the artifact with its options
+// applied as query parameters.
+func getterURL(artifact *structs.TaskArtifact) (string, error) {
+	if len(artifact.GetterOptions) == 0 {
+		return artifact.GetterSource, nil
+	}
+
+	u, err := url.Parse(artifact.GetterSource)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse source URL %q: %v", artifact.GetterSource, err)
+	}
+
+	q := u.Query()
+	for k, v := range artifact.GetterOptions {
+		q.Set(k, v)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
@@ -1,111 +1,171 @@
 package getter
 
 import (
-	"fmt"
 	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
 )
 
-func TestGetArtifact_basic(t *testing.T) {
+func testLogger() *log.Logger {
+	return log.New(os.Stderr, "", log.LstdFlags)
+}
 
-	logger := log.New(os.Stderr, "", log.LstdFlags)
+// testTaskDir returns a task directory with a local directory, as the alloc
+// dir would build it.
+func testTaskDir(t *testing.T) string {
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	if err != nil {
+		t.Fatalf("failed to make temp directory: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(taskDir, allocdir.TaskLocal), 0777); err != nil {
+		t.Fatalf("failed to make local directory: %v", err)
+	}
+	return taskDir
+}
 
-	// TODO: Use http.TestServer to serve these files from fixtures dir
-	passing := []struct {
-		Source, Checksum string
-	}{
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_darwin_amd64",
-			"sha256:66aa0f05fc0cfcf1e5ed8cc5307b5df51e33871d6b295a60e0f9f6dd573da977",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64",
-			"sha256:6f99b4c5184726e601ecb062500aeb9537862434dfe1898dbe5c68d9f50c179c",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64",
-			"md5:a9b14903a8942748e4f8474e11f795d3",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64?checksum=sha256:6f99b4c5184726e601ecb062500aeb9537862434dfe1898dbe5c68d9f50c179c",
-			"",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64",
-			"",
+// testFixtureServer serves the test fixtures over HTTP.
+func testFixtureServer(t *testing.T) *httptest.Server {
+	fixtures, err := filepath.Abs("./test-fixtures")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return httptest.NewServer(http.FileServer(http.Dir(fixtures)))
+}
+
+func TestGetArtifact_File(t *testing.T) {
+	ts := testFixtureServer(t)
+	defer ts.Close()
+	taskDir := testTaskDir(t)
+	defer os.RemoveAll(taskDir)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: ts.URL + "/test.sh",
+		GetterOptions: map[string]string{
+			"checksum": "md5:d604a220708aa59433ba410986cd4ffa",
 		},
 	}
+	if err := GetArtifact(artifact, taskDir, testLogger()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
-	for i, p := range passing {
-		destDir, err := ioutil.TempDir("", fmt.Sprintf("nomad-test-%d", i))
-		if err != nil {
-			t.Fatalf("Error in TestGetArtifact_basic makeing TempDir: %s", err)
-		}
+	// The file should be in the local directory and be executable
+	fi, err := os.Stat(filepath.Join(taskDir, allocdir.TaskLocal, "test.sh"))
+	if err != nil {
+		t.Fatalf("artifact not downloaded: %v", err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm() != 0755 {
+		t.Fatalf("artifact not executable: %v", fi.Mode())
+	}
+}
 
-		path, err := GetArtifact(destDir, p.Source, p.Checksum, logger)
-		if err != nil {
-			t.Fatalf("TestGetArtifact_basic unexpected failure here: %s", err)
-		}
+func TestGetArtifact_File_Dest(t *testing.T) {
+	ts := testFixtureServer(t)
+	defer ts.Close()
+	taskDir := testTaskDir(t)
+	defer os.RemoveAll(taskDir)
 
-		if p.Checksum != "" {
-			if ok := strings.Contains(path, p.Checksum); ok {
-				t.Fatalf("path result should not contain the checksum, got: %s", path)
-			}
-		}
+	artifact := &structs.TaskArtifact{
+		GetterSource: ts.URL + "/test.sh",
+		RelativeDest: "local/bin/run",
+		Mode:         structs.TaskArtifactModeFile,
+	}
+	if err := GetArtifact(artifact, taskDir, testLogger()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
 
-		// verify artifact exists
-		if _, err := os.Stat(path); err != nil {
-			t.Fatalf("source path error: %s", err)
-		}
+	if _, err := os.Stat(filepath.Join(taskDir, "local", "bin", "run")); err != nil {
+		t.Fatalf("artifact not downloaded: %v", err)
 	}
 }
 
-func TestGetArtifact_fails(t *testing.T) {
-
-	logger := log.New(os.Stderr, "", log.LstdFlags)
+func TestGetArtifact_InvalidChecksum(t *testing.T) {
+	ts := testFixtureServer(t)
+	defer ts.Close()
+	taskDir := testTaskDir(t)
+	defer os.RemoveAll(taskDir)
 
-	failing := []struct {
-		Source, Checksum string
-	}{
-		{
-			"",
-			"sha256:66aa0f05fc0cfcf1e5ed8cc5307b5d",
-		},
-		{
-			"/u/47675/jar_thing/hi_darwin_amd64",
-			"sha256:66aa0f05fc0cfcf1e5ed8cc5307b5d",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_darwin_amd64",
-			"sha256:66aa0f05fc0cfcf1e5ed8cc5307b5d",
-		},
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64",
-			"sha257:6f99b4c5184726e601ecb062500aeb9537862434dfe1898dbe5c68d9f50c179c",
-		},
-		// malformed checksum
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd64",
-			"6f99b4c5184726e601ecb062500aeb9537862434dfe1898dbe5c68d9f50c179c",
-		},
-		// 404
-		{
-			"https://dl.dropboxusercontent.com/u/47675/jar_thing/hi_linux_amd86",
-			"",
+	artifact := &structs.TaskArtifact{
+		GetterSource: ts.URL + "/test.sh",
+		GetterOptions: map[string]string{
+			"checksum": "md5:00000000000000000000000000000000",
 		},
 	}
-	for i, p := range failing {
-		destDir, err := ioutil.TempDir("", fmt.Sprintf("nomad-test-%d", i))
-		if err != nil {
-			t.Fatalf("Error in TestGetArtifact_basic makeing TempDir: %s", err)
+	if err := GetArtifact(artifact, taskDir, testLogger()); err == nil {
+		t.Fatalf("expected checksum error")
+	}
+}
+
+func TestGetArtifact_Archive(t *testing.T) {
+	ts := testFixtureServer(t)
+	defer ts.Close()
+	taskDir := testTaskDir(t)
+	defer os.RemoveAll(taskDir)
+
+	artifact := &structs.TaskArtifact{
+		GetterSource: ts.URL + "/archive.tar.gz",
+		RelativeDest: "local/app",
+	}
+	if err := GetArtifact(artifact, taskDir, testLogger()); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The archive should have been unpacked into the destination
+	for _, file := range []string{"foo.txt", filepath.Join("bar", "baz.txt")} {
+		if _, err := os.Stat(filepath.Join(taskDir, "local", "app", file)); err != nil {
+			t.Fatalf("file %q not unpacked: %v", file, err)
 		}
+	}
+}
+
+func TestGetArtifact_Fails(t *testing.T) {
+	ts := testFixtureServer(t)
+	defer ts.Close()
+	taskDir := testTaskDir(t)
+	defer os.RemoveAll(taskDir)
 
-		_, err = GetArtifact(destDir, p.Source, p.Checksum, logger)
+	failing := []*structs.TaskArtifact{
+		&structs.TaskArtifact{},
+		&structs.TaskArtifact{GetterSource: ts.URL + "/missing"},
+	}
+	for _, artifact := range failing {
+		err := GetArtifact(artifact, taskDir, testLogger())
 		if err == nil {
-			t.Fatalf("TestGetArtifact_basic expected failure, but got none")
+			t.Fatalf("%#v: expected failure", artifact)
 		}
 	}
 }
+
+func TestGetterURL(t *testing.T) {
+	artifact := &structs.TaskArtifact{
+		GetterSource: "git::https://github.com/hashicorp/nomad?depth=1",
+		GetterOptions: map[string]string{
+			"ref": "v0.2.3",
+		},
+	}
+	out, err := getterURL(artifact)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(out, "ref=v0.2.3") || !strings.Contains(out, "depth=1") {
+		t.Fatalf("bad: %v", out)
+	}
+
+	artifact.GetterOptions = nil
+	out, err = getterURL(artifact)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out, artifact.GetterSource) {
+		t.Fatalf("bad: %v", out)
+	}
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// prevAllocRetryIntv is minimum interval on which we retry to query the
+	// status of a previous allocation on another node. We pick a value
+	// between this and 2x this.
+	prevAllocRetryIntv = 5 * time.Second
+)
+
+// errPrevAllocStopped is returned when the allocation is destroyed while
+// waiting for its previous allocation.
+var errPrevAllocStopped = fmt.Errorf("allocation destroyed while waiting for its previous allocation")
+
+// prevAllocDir carries the ephemeral disk of a previous allocation over to
+// the allocation replacing it.
+type prevAllocDir interface {
+	// Migrate waits for the tasks of the previous allocation to stop and
+	// moves or copies its data into the built alloc dir of the replacement.
+	// It gives up once stopCh is closed.
+	Migrate(dest *allocdir.AllocDir, stopCh <-chan struct{}) error
+}
+
+// localPrevAlloc is a previous allocation that ran on this client. Its data
+// is moved into the alloc dir of the replacement.
+type localPrevAlloc struct {
+	ar *AllocRunner
+
+	// release hands the previous allocation back to the garbage collector,
+	// which must not destroy it before its data has been moved.
+	release func()
+}
+
+func (p *localPrevAlloc) Migrate(dest *allocdir.AllocDir, stopCh <-chan struct{}) error {
+	defer p.release()
+
+	select {
+	case <-p.ar.StoppedCh():
+	case <-stopCh:
+		return errPrevAllocStopped
+	}
+
+	prev := p.ar.allocDir()
+	if prev == nil {
+		return nil
+	}
+	return dest.Move(prev)
+}
+
+// remotePrevAlloc is a previous allocation that ran on another client. A
+// snapshot of its data is downloaded from that client once its tasks have
+// stopped.
+type remotePrevAlloc struct {
+	client  *Client
+	allocID string
+	logger  *log.Logger
+
+	// namespace is the namespace of the job of the allocation
+	namespace string
+}
+
+func (p *remotePrevAlloc) Migrate(dest *allocdir.AllocDir, stopCh <-chan struct{}) error {
+	node, err := p.waitStopped(stopCh)
+	if err != nil || node == nil {
+		return err
+	}
+	if node.HTTPAddr == "" {
+		return fmt.Errorf("node '%s' of previous alloc '%s' has no HTTP address", node.ID, p.allocID)
+	}
+
+	url := fmt.Sprintf("%s://%s/v1/client/allocation/%s/snapshot",
+		p.client.httpScheme, node.HTTPAddr, p.allocID)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	if token := p.client.config.ACLToken; token != "" {
+		req.Header.Set("X-Nomad-Token", token)
+	}
+	resp, err := p.client.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download the data of alloc '%s': %v", p.allocID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("failed to download the data of alloc '%s': %s (%s)", p.allocID, resp.Status, body)
+	}
+	return dest.Restore(resp.Body)
+}
+
+// waitStopped waits for the previous allocation to reach a terminal client
+// status and returns the node it ran on. It returns a nil node if there is no
+// data to migrate because the allocation has been removed.
+func (p *remotePrevAlloc) waitStopped(stopCh <-chan struct{}) (*structs.Node, error) {
+	req := structs.AllocSpecificRequest{
+		AllocID: p.allocID,
+		QueryOptions: structs.QueryOptions{
+			Region:     p.client.config.Region,
+			Namespace:  p.namespace,
+			AllowStale: true,
+			AuthToken:  p.client.config.ACLToken,
+		},
+	}
+
+	for {
+		var resp structs.SingleAllocResponse
+		err := p.client.RPC("Alloc.GetAlloc", &req, &resp)
+		if err != nil {
+			p.logger.Printf("[ERR] client: failed to query previous alloc '%s': %v", p.allocID, err)
+			select {
+			case <-time.After(p.client.retryIntv(prevAllocRetryIntv)):
+				continue
+			case <-stopCh:
+				return nil, errPrevAllocStopped
+			}
+		}
+
+		alloc := resp.Alloc
+		if alloc == nil {
+			p.logger.Printf("[WARN] client: previous alloc '%s' no longer exists, not migrating its data", p.allocID)
+			return nil, nil
+		}
+
+		node, err := p.node(alloc.NodeID)
+		if err != nil {
+			return nil, err
+		}
+		if node.Status == structs.NodeStatusDown {
+			return nil, fmt.Errorf("node '%s' of previous alloc '%s' is down", node.ID, p.allocID)
+		}
+
+		switch alloc.ClientStatus {
+		case structs.AllocClientStatusDead, structs.AllocClientStatusFailed:
+			return node, nil
+		}
+
+		// Wait for the allocation to change
+		select {
+		case <-stopCh:
+			return nil, errPrevAllocStopped
+		default:
+		}
+		req.MinQueryIndex = resp.Index
+	}
+}
+
+// node looks up the node the previous allocation ran on.
+func (p *remotePrevAlloc) node(nodeID string) (*structs.Node, error) {
+	req := structs.NodeSpecificRequest{
+		NodeID: nodeID,
+		QueryOptions: structs.QueryOptions{
+			Region:     p.client.config.Region,
+			AllowStale: true,
+			AuthToken:  p.client.config.ACLToken,
+		},
+	}
+	var resp structs.SingleNodeResponse
+	if err := p.client.RPC("Node.GetNode", &req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to query node of previous alloc '%s': %v", p.allocID, err)
+	}
+	if resp.Node == nil {
+		return nil, fmt.Errorf("node '%s' of previous alloc '%s' no longer exists", nodeID, p.allocID)
+	}
+	return resp.Node, nil
+}
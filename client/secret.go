@@ -0,0 +1,58 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// writeSecret writes the value of the secret into the task's secrets
+// directory and returns whether the content of the destination changed.
+func writeSecret(secret *structs.Secret, taskDir string, value string) (bool, error) {
+	dest := filepath.Join(taskDir, allocdir.TaskSecrets, secret.DestPath)
+	if existing, err := ioutil.ReadFile(dest); err == nil && bytes.Equal(existing, []byte(value)) {
+		return false, nil
+	}
+
+	if err := makeSecretDir(filepath.Dir(dest)); err != nil {
+		return false, fmt.Errorf("failed to create directory for secret %q: %v", secret.DestPath, err)
+	}
+
+	// Write to a temporary file first so the task never sees a partially
+	// written secret. A leftover file is removed first as WriteFile keeps
+	// the permissions of existing files.
+	tmp := dest + ".tmp"
+	os.Remove(tmp)
+	if err := ioutil.WriteFile(tmp, []byte(value), allocdir.SecretFilePerm); err != nil {
+		return false, fmt.Errorf("failed to write secret %q: %v", secret.DestPath, err)
+	}
+	if err := allocdir.DropSecretPermissions(tmp, allocdir.SecretFilePerm); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("failed to write secret %q: %v", secret.DestPath, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("failed to write secret %q: %v", secret.DestPath, err)
+	}
+	return true, nil
+}
+
+// makeSecretDir creates the directory of a secret along with its missing
+// parents, giving them to the user tasks run as.
+func makeSecretDir(dir string) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := makeSecretDir(filepath.Dir(dir)); err != nil {
+		return err
+	}
+	if err := os.Mkdir(dir, allocdir.SecretDirPerm); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return allocdir.DropSecretPermissions(dir, allocdir.SecretDirPerm)
+}
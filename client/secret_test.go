@@ -0,0 +1,68 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestWriteSecret(t *testing.T) {
+	taskDir := testTemplateDir(t)
+	defer os.RemoveAll(taskDir)
+
+	secret := &structs.Secret{Path: "secret/db", Key: "password", DestPath: "db/password"}
+	changed, err := writeSecret(secret, taskDir, "hunter2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected secret to be written")
+	}
+
+	dest := filepath.Join(taskDir, allocdir.TaskSecrets, "db", "password")
+	out, err := ioutil.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(out) != "hunter2" {
+		t.Fatalf("got %q; want %q", out, "hunter2")
+	}
+
+	// Only the owner can access the secret
+	fi, err := os.Stat(dest)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != allocdir.SecretFilePerm {
+		t.Fatalf("got %v; want %v", perm, os.FileMode(allocdir.SecretFilePerm))
+	}
+	fi, err = os.Stat(filepath.Dir(dest))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != allocdir.SecretDirPerm {
+		t.Fatalf("got %v; want %v", perm, os.FileMode(allocdir.SecretDirPerm))
+	}
+
+	// Writing the same value again isn't a change
+	changed, err = writeSecret(secret, taskDir, "hunter2")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if changed {
+		t.Fatalf("expected secret to be unchanged")
+	}
+
+	// A rotated value is a change
+	changed, err = writeSecret(secret, taskDir, "hunter3")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected secret to be changed")
+	}
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ProviderVault is the provider talking to the HTTP API of Vault or of
+	// a compatible secrets store.
+	ProviderVault = "vault"
+)
+
+// Provider reads the secrets injected into tasks from an external secrets
+// store.
+type Provider interface {
+	// Read returns the key/value pairs of the secret at the path.
+	Read(path string) (map[string]string, error)
+
+	// RenewToken renews the token the provider authenticates with and
+	// returns how long it is valid for. It returns zero if the token
+	// doesn't expire.
+	RenewToken() (time.Duration, error)
+}
+
+// Config configures the connection to the secrets store.
+type Config struct {
+	// Provider is the type of the secrets store. Defaults to Vault.
+	Provider string
+
+	// Address is the URL of the secrets store, such as
+	// https://vault.service.consul:8200.
+	Address string
+
+	// Token authenticates the client to the secrets store.
+	Token string
+
+	// Timeout bounds the requests to the secrets store.
+	Timeout time.Duration
+}
+
+// Factory creates a provider from the configuration.
+type Factory func(config *Config) (Provider, error)
+
+// BuiltinProviders contains the built in secrets providers
+var BuiltinProviders = map[string]Factory{
+	ProviderVault: NewVaultProvider,
+}
+
+// NewProvider creates the provider of the configuration.
+func NewProvider(config *Config) (Provider, error) {
+	name := config.Provider
+	if name == "" {
+		name = ProviderVault
+	}
+	factory, ok := BuiltinProviders[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown secrets provider '%s'", name)
+	}
+	return factory(config)
+}
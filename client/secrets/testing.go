@@ -0,0 +1,103 @@
+package secrets
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestServer is an in-process stand-in for a Vault-compatible secrets
+// store. It serves the secrets set on it to requests carrying its token.
+type TestServer struct {
+	// Token is the only token accepted by the server
+	Token string
+
+	// TokenTTL is the duration tokens are renewed for
+	TokenTTL time.Duration
+
+	srv      *httptest.Server
+	secrets  map[string]map[string]interface{}
+	renewals int
+	l        sync.Mutex
+}
+
+// NewTestServer starts a secrets server. It must be stopped with Stop.
+func NewTestServer() *TestServer {
+	s := &TestServer{
+		Token:    "test-token",
+		TokenTTL: time.Hour,
+		secrets:  make(map[string]map[string]interface{}),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Addr returns the URL of the server
+func (s *TestServer) Addr() string {
+	return s.srv.URL
+}
+
+// Config returns the configuration of a provider talking to the server
+func (s *TestServer) Config() *Config {
+	return &Config{
+		Provider: ProviderVault,
+		Address:  s.Addr(),
+		Token:    s.Token,
+	}
+}
+
+// Set stores the secret at the path, replacing the existing one.
+func (s *TestServer) Set(path string, data map[string]interface{}) {
+	s.l.Lock()
+	defer s.l.Unlock()
+	s.secrets[strings.Trim(path, "/")] = data
+}
+
+// Renewals returns the number of times the token was renewed
+func (s *TestServer) Renewals() int {
+	s.l.Lock()
+	defer s.l.Unlock()
+	return s.renewals
+}
+
+// Stop shuts down the server
+func (s *TestServer) Stop() {
+	s.srv.Close()
+}
+
+func (s *TestServer) handle(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get(vaultTokenHeader) != s.Token {
+		s.respond(w, 403, &vaultResponse{Errors: []string{"permission denied"}})
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(req.URL.Path, "/v1/"), "/")
+	s.l.Lock()
+	defer s.l.Unlock()
+	switch {
+	case path == "auth/token/renew-self" && req.Method == "POST":
+		s.renewals++
+		s.respond(w, 200, &vaultResponse{Auth: &vaultAuth{
+			LeaseDuration: int(s.TokenTTL / time.Second),
+			Renewable:     true,
+		}})
+	case req.Method == "GET":
+		data, ok := s.secrets[path]
+		if !ok {
+			s.respond(w, 404, &vaultResponse{Errors: []string{}})
+			return
+		}
+		s.respond(w, 200, &vaultResponse{Data: data})
+	default:
+		s.respond(w, 405, &vaultResponse{Errors: []string{"unsupported operation"}})
+	}
+}
+
+func (s *TestServer) respond(w http.ResponseWriter, code int, resp *vaultResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
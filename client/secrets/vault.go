@@ -0,0 +1,131 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// vaultTokenHeader is the header carrying the token of requests to Vault
+	vaultTokenHeader = "X-Vault-Token"
+
+	// defaultVaultTimeout bounds the requests to Vault if no timeout is
+	// configured.
+	defaultVaultTimeout = 30 * time.Second
+)
+
+// VaultProvider reads secrets from the HTTP API of Vault. The key/value
+// pairs of version 2 of the kv secrets engine, which are nested under the
+// data of the response, are unwrapped.
+type VaultProvider struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+// vaultResponse is the body of the responses of Vault
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Auth   *vaultAuth             `json:"auth"`
+	Errors []string               `json:"errors"`
+}
+
+// vaultAuth describes the token of renewals
+type vaultAuth struct {
+	LeaseDuration int  `json:"lease_duration"`
+	Renewable     bool `json:"renewable"`
+}
+
+// NewVaultProvider returns a provider talking to the Vault at the address
+// of the configuration.
+func NewVaultProvider(config *Config) (Provider, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("vault address must be set")
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("vault token must be set")
+	}
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = defaultVaultTimeout
+	}
+	return &VaultProvider{
+		address: strings.TrimSuffix(config.Address, "/"),
+		token:   config.Token,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (v *VaultProvider) Read(path string) (map[string]string, error) {
+	var resp vaultResponse
+	if err := v.do("GET", path, &resp); err != nil {
+		return nil, err
+	}
+
+	// Unwrap the kv version 2 data
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	secret := make(map[string]string, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			secret[key] = v
+		default:
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode key %q of secret %q: %v", key, path, err)
+			}
+			secret[key] = string(raw)
+		}
+	}
+	return secret, nil
+}
+
+func (v *VaultProvider) RenewToken() (time.Duration, error) {
+	var resp vaultResponse
+	if err := v.do("POST", "auth/token/renew-self", &resp); err != nil {
+		return 0, err
+	}
+	if resp.Auth == nil || !resp.Auth.Renewable {
+		return 0, nil
+	}
+	return time.Duration(resp.Auth.LeaseDuration) * time.Second, nil
+}
+
+// do sends the request to the path of the Vault API and decodes the
+// response into out.
+func (v *VaultProvider) do(method, path string, out *vaultResponse) error {
+	url := fmt.Sprintf("%s/v1/%s", v.address, strings.TrimPrefix(path, "/"))
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(vaultTokenHeader, v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach vault: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to decode response of %q: %v", path, err)
+	}
+	switch {
+	case resp.StatusCode == 404:
+		return fmt.Errorf("secret %q not found", path)
+	case resp.StatusCode != 200:
+		return fmt.Errorf("request to %q failed with status %d: %s",
+			path, resp.StatusCode, strings.Join(out.Errors, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package secrets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testVaultProvider(t *testing.T, s *TestServer) Provider {
+	p, err := NewProvider(s.Config())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	return p
+}
+
+func TestNewProvider(t *testing.T) {
+	if _, err := NewProvider(&Config{Provider: "foo"}); err == nil {
+		t.Fatalf("expected unknown provider error")
+	}
+	if _, err := NewProvider(&Config{Token: "foo"}); err == nil {
+		t.Fatalf("expected missing address error")
+	}
+	if _, err := NewProvider(&Config{Address: "http://127.0.0.1:8200"}); err == nil {
+		t.Fatalf("expected missing token error")
+	}
+
+	// Vault is the default provider
+	p, err := NewProvider(&Config{Address: "http://127.0.0.1:8200/", Token: "foo"})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if v, ok := p.(*VaultProvider); !ok || v.address != "http://127.0.0.1:8200" {
+		t.Fatalf("bad: %#v", p)
+	}
+}
+
+func TestVaultProvider_Read(t *testing.T) {
+	s := NewTestServer()
+	defer s.Stop()
+	p := testVaultProvider(t, s)
+
+	s.Set("secret/db", map[string]interface{}{
+		"password": "hunter2",
+		"port":     5432,
+	})
+	out, err := p.Read("secret/db")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	expected := map[string]string{"password": "hunter2", "port": "5432"}
+	if !reflect.DeepEqual(out, expected) {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// kv version 2 secrets are unwrapped
+	s.Set("kv/data/db", map[string]interface{}{
+		"data":     map[string]interface{}{"password": "hunter3"},
+		"metadata": map[string]interface{}{"version": 2},
+	})
+	out, err = p.Read("/kv/data/db")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out, map[string]string{"password": "hunter3"}) {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Missing secrets fail
+	if _, err := p.Read("secret/missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not found error, got: %v", err)
+	}
+}
+
+func TestVaultProvider_BadToken(t *testing.T) {
+	s := NewTestServer()
+	defer s.Stop()
+	s.Set("secret/db", map[string]interface{}{"password": "hunter2"})
+
+	conf := s.Config()
+	conf.Token = "bad"
+	p, err := NewProvider(conf)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	_, err = p.Read("secret/db")
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected permission denied, got: %v", err)
+	}
+}
+
+func TestVaultProvider_RenewToken(t *testing.T) {
+	s := NewTestServer()
+	defer s.Stop()
+	s.TokenTTL = 10 * time.Minute
+	p := testVaultProvider(t, s)
+
+	ttl, err := p.RenewToken()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ttl != 10*time.Minute {
+		t.Fatalf("bad: %v", ttl)
+	}
+	if n := s.Renewals(); n != 1 {
+		t.Fatalf("bad: %d", n)
+	}
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"os"
+	"syscall"
+)
+
+// signalLookup maps the names of the signals that can be sent to tasks to
+// their values.
+var signalLookup = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGINT":  syscall.SIGINT,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGTERM": syscall.SIGTERM,
+}
@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"time"
+)
+
+// CpuStats calculates the percentage of a core used from consecutive samples
+// of the cumulative CPU time of a process or cgroup.
+type CpuStats struct {
+	prevCpuTime float64
+	prevTime    time.Time
+}
+
+// NewCpuStats returns a CpuStats with no prior sample.
+func NewCpuStats() *CpuStats {
+	return &CpuStats{}
+}
+
+// Percent returns the percentage of a core used since the previous sample,
+// given the cumulative CPU time in nanoseconds. The first sample returns zero.
+func (c *CpuStats) Percent(cpuTime float64) float64 {
+	now := time.Now()
+	if c.prevTime.IsZero() {
+		c.prevCpuTime = cpuTime
+		c.prevTime = now
+		return 0.0
+	}
+
+	timeDelta := now.Sub(c.prevTime).Nanoseconds()
+	ret := calculatePercent(c.prevCpuTime, cpuTime, 0, float64(timeDelta), 1)
+	c.prevCpuTime = cpuTime
+	c.prevTime = now
+	return ret
+}
+
+// calculatePercent returns the percentage the delta of t1 and t2 represents
+// of the delta of total1 and total2, scaled by the number of cores.
+func calculatePercent(t1, t2, total1, total2 float64, cores int) float64 {
+	numerator := t2 - t1
+	denominator := total2 - total1
+	if numerator <= 0 || denominator <= 0 {
+		return 0.0
+	}
+	return (numerator / denominator) * float64(cores) * 100.0
+}
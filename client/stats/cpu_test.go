@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCpuStatsPercent(t *testing.T) {
+	cs := NewCpuStats()
+	if p := cs.Percent(0); p != 0 {
+		t.Fatalf("first sample should be zero: %v", p)
+	}
+
+	// Use half a core's worth of CPU time over the interval
+	interval := 100 * time.Millisecond
+	time.Sleep(interval)
+	p := cs.Percent(float64(interval.Nanoseconds()) / 2)
+	if p < 30 || p > 55 {
+		t.Fatalf("expected roughly 50%% of a core, got: %v", p)
+	}
+
+	// A counter that doesn't advance uses no CPU
+	time.Sleep(10 * time.Millisecond)
+	if p := cs.Percent(float64(interval.Nanoseconds()) / 2); p != 0 {
+		t.Fatalf("expected zero, got: %v", p)
+	}
+}
+
+func TestCalculatePercent(t *testing.T) {
+	if p := calculatePercent(10, 20, 100, 200, 2); p != 20 {
+		t.Fatalf("bad: %v", p)
+	}
+	if p := calculatePercent(20, 10, 100, 200, 1); p != 0 {
+		t.Fatalf("bad: %v", p)
+	}
+}
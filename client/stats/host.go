@@ -0,0 +1,165 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/disk"
+	"github.com/shirou/gopsutil/host"
+	"github.com/shirou/gopsutil/mem"
+)
+
+// HostStats represents resource usage stats of the host running a Nomad
+// client.
+type HostStats struct {
+	Memory    *MemoryStats
+	CPU       []*CPUStats
+	DiskStats []*DiskStats
+	Uptime    uint64
+	Timestamp int64 // Unix Nanosecond timestamp
+}
+
+// MemoryStats represents the memory usage of the host in bytes.
+type MemoryStats struct {
+	Total     uint64
+	Available uint64
+	Used      uint64
+	Free      uint64
+}
+
+// CPUStats represents the percentage of time a core of the host spent in
+// each mode since the previous sample.
+type CPUStats struct {
+	CPU    string
+	User   float64
+	System float64
+	Idle   float64
+	Total  float64
+}
+
+// DiskStats represents the usage of a mounted disk of the host.
+type DiskStats struct {
+	Device            string
+	Mountpoint        string
+	Size              uint64
+	Used              uint64
+	Available         uint64
+	UsedPercent       float64
+	InodesUsedPercent float64
+}
+
+// HostStatsCollector collects the resource usage stats of the host.
+type HostStatsCollector struct {
+	statsCalculator map[string]*hostCpuStatsCalculator
+}
+
+// NewHostStatsCollector returns a HostStatsCollector.
+func NewHostStatsCollector() *HostStatsCollector {
+	return &HostStatsCollector{
+		statsCalculator: make(map[string]*hostCpuStatsCalculator),
+	}
+}
+
+// Collect samples the resource usage of the host. CPU percentages are
+// calculated relative to the previous call, so the first call reports zero.
+func (h *HostStatsCollector) Collect() (*HostStats, error) {
+	hs := &HostStats{Timestamp: time.Now().UTC().UnixNano()}
+
+	memStats, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, err
+	}
+	hs.Memory = &MemoryStats{
+		Total:     memStats.Total,
+		Available: memStats.Available,
+		Used:      memStats.Used,
+		Free:      memStats.Free,
+	}
+
+	cpuStats, err := cpu.CPUTimes(true)
+	if err != nil {
+		return nil, err
+	}
+	for _, cpuStat := range cpuStats {
+		calculator, ok := h.statsCalculator[cpuStat.CPU]
+		if !ok {
+			calculator = &hostCpuStatsCalculator{}
+			h.statsCalculator[cpuStat.CPU] = calculator
+		}
+		hs.CPU = append(hs.CPU, calculator.calculate(cpuStat))
+	}
+
+	partitions, err := disk.DiskPartitions(false)
+	if err != nil {
+		return nil, err
+	}
+	for _, partition := range partitions {
+		usage, err := disk.DiskUsage(partition.Mountpoint)
+		if err != nil {
+			// Partitions may be unreadable, such as those of other
+			// users, so they are skipped.
+			continue
+		}
+		hs.DiskStats = append(hs.DiskStats, &DiskStats{
+			Device:            partition.Device,
+			Mountpoint:        partition.Mountpoint,
+			Size:              usage.Total,
+			Used:              usage.Used,
+			Available:         usage.Free,
+			UsedPercent:       usage.UsedPercent,
+			InodesUsedPercent: usage.InodesUsedPercent,
+		})
+	}
+
+	if info, err := host.HostInfo(); err == nil {
+		hs.Uptime = info.Uptime
+	}
+	return hs, nil
+}
+
+// PathDiskStats returns the usage of the disk the path is on.
+func PathDiskStats(path string) (*DiskStats, error) {
+	usage, err := disk.DiskUsage(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskStats{
+		Mountpoint:        usage.Path,
+		Size:              usage.Total,
+		Used:              usage.Used,
+		Available:         usage.Free,
+		UsedPercent:       usage.UsedPercent,
+		InodesUsedPercent: usage.InodesUsedPercent,
+	}, nil
+}
+
+// hostCpuStatsCalculator calculates the percentages of time a core spent in
+// each mode from consecutive samples of its cumulative times.
+type hostCpuStatsCalculator struct {
+	prevIdle   float64
+	prevUser   float64
+	prevSystem float64
+	prevBusy   float64
+	prevTotal  float64
+}
+
+func (h *hostCpuStatsCalculator) calculate(times cpu.CPUTimesStat) *CPUStats {
+	busy := times.User + times.System + times.Nice + times.Iowait + times.Irq +
+		times.Softirq + times.Steal
+	total := busy + times.Idle
+
+	stats := &CPUStats{
+		CPU:    times.CPU,
+		Idle:   calculatePercent(h.prevIdle, times.Idle, h.prevTotal, total, 1),
+		User:   calculatePercent(h.prevUser, times.User, h.prevTotal, total, 1),
+		System: calculatePercent(h.prevSystem, times.System, h.prevTotal, total, 1),
+		Total:  calculatePercent(h.prevBusy, busy, h.prevTotal, total, 1),
+	}
+
+	h.prevIdle = times.Idle
+	h.prevUser = times.User
+	h.prevSystem = times.System
+	h.prevBusy = busy
+	h.prevTotal = total
+	return stats
+}
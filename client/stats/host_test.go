@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shirou/gopsutil/cpu"
+)
+
+func TestHostStatsCollector_Collect(t *testing.T) {
+	h := NewHostStatsCollector()
+	hs, err := h.Collect()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if hs.Memory == nil || hs.Memory.Total == 0 {
+		t.Fatalf("bad memory stats: %#v", hs.Memory)
+	}
+	if len(hs.CPU) == 0 {
+		t.Fatalf("expected cpu stats")
+	}
+	if hs.Timestamp == 0 {
+		t.Fatalf("expected timestamp")
+	}
+}
+
+func TestPathDiskStats(t *testing.T) {
+	ds, err := PathDiskStats(os.TempDir())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if ds.Size == 0 {
+		t.Fatalf("bad disk stats: %#v", ds)
+	}
+	if ds.UsedPercent < 0 || ds.UsedPercent > 100 {
+		t.Fatalf("bad used percent: %v", ds.UsedPercent)
+	}
+}
+
+func TestHostCpuStatsCalculator(t *testing.T) {
+	var c hostCpuStatsCalculator
+	c.calculate(cpu.CPUTimesStat{CPU: "cpu0", User: 10, System: 10, Idle: 80})
+
+	stats := c.calculate(cpu.CPUTimesStat{CPU: "cpu0", User: 30, System: 20, Idle: 150})
+	if stats.User != 20 || stats.System != 10 || stats.Idle != 70 || stats.Total != 30 {
+		t.Fatalf("bad: %#v", stats)
+	}
+}
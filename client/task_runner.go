@@ -7,11 +7,14 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/armon/go-metrics"
 	"github.com/hashicorp/nomad/client/config"
 	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/client/getter"
 	"github.com/hashicorp/nomad/nomad/structs"
 
 	cstructs "github.com/hashicorp/nomad/client/driver/structs"
@@ -30,20 +33,42 @@ type TaskRunner struct {
 	task     *structs.Task
 	state    *structs.TaskState
 	updateCh chan *structs.Task
-	handle   driver.DriverHandle
 
-	destroy     bool
-	destroyCh   chan struct{}
-	destroyLock sync.Mutex
-	waitCh      chan struct{}
+	// handle is the handle to the running task. It is set by the Run
+	// goroutine and read by Exec and SaveState from other goroutines.
+	handle     driver.DriverHandle
+	handleLock sync.Mutex
+
+	// interpTask is the task with its runtime variables interpolated, as it
+	// was started by the driver.
+	interpTask *structs.Task
+
+	// artifactsDownloaded tracks whether the task's artifacts have been
+	// downloaded so they are only fetched once.
+	artifactsDownloaded bool
+
+	// secretEnv holds the values of the task's env var secrets as last read
+	// from the secrets provider, keyed by variable name.
+	secretEnv map[string]string
+
+	destroy      bool
+	destroyCh    chan struct{}
+	destroyLock  sync.Mutex
+	destroyEvent *structs.TaskEvent
+	waitCh       chan struct{}
+
+	// resourceUsage is the latest resource usage sample of the task.
+	resourceUsage     *cstructs.TaskResourceUsage
+	resourceUsageLock sync.RWMutex
 
 	snapshotLock sync.Mutex
 }
 
 // taskRunnerState is used to snapshot the state of the task runner
 type taskRunnerState struct {
-	Task     *structs.Task
-	HandleID string
+	Task                *structs.Task
+	HandleID            string
+	ArtifactsDownloaded bool
 }
 
 // TaskStateUpdater is used to signal that tasks state has changed.
@@ -100,6 +125,7 @@ func (r *TaskRunner) RestoreState() error {
 
 	// Restore fields
 	r.task = snap.Task
+	r.artifactsDownloaded = snap.ArtifactsDownloaded
 
 	// Restore the driver
 	if snap.HandleID != "" {
@@ -108,6 +134,18 @@ func (r *TaskRunner) RestoreState() error {
 			return err
 		}
 
+		// Read the secrets again so their rotations are detected from the
+		// values the task is running with.
+		if _, err := r.renderSecrets(); err != nil {
+			r.logger.Printf("[WARN] client: failed to read secrets of task '%s' for alloc '%s': %v",
+				r.task.Name, r.alloc.ID, err)
+		}
+
+		interpTask, err := r.interpolateTask(driver)
+		if err != nil {
+			return err
+		}
+
 		handle, err := driver.Open(r.ctx, snap.HandleID)
 
 		// In the case it fails, we relaunch the task in the Run() method.
@@ -116,7 +154,8 @@ func (r *TaskRunner) RestoreState() error {
 				r.task.Name, r.alloc.ID, err)
 			return nil
 		}
-		r.handle = handle
+		r.setHandle(handle)
+		r.interpTask = interpTask
 	}
 	return nil
 }
@@ -126,10 +165,11 @@ func (r *TaskRunner) SaveState() error {
 	r.snapshotLock.Lock()
 	defer r.snapshotLock.Unlock()
 	snap := taskRunnerState{
-		Task: r.task,
+		Task:                r.task,
+		ArtifactsDownloaded: r.artifactsDownloaded,
 	}
-	if r.handle != nil {
-		snap.HandleID = r.handle.ID()
+	if handle := r.getHandle(); handle != nil {
+		snap.HandleID = handle.ID()
 	}
 	return persistState(r.stateFilePath(), &snap)
 }
@@ -170,6 +210,20 @@ func (r *TaskRunner) setState(state string, event *structs.TaskEvent) {
 	r.updater(r.task.Name)
 }
 
+// getHandle returns the handle to the running task
+func (r *TaskRunner) getHandle() driver.DriverHandle {
+	r.handleLock.Lock()
+	defer r.handleLock.Unlock()
+	return r.handle
+}
+
+// setHandle sets the handle to the running task
+func (r *TaskRunner) setHandle(handle driver.DriverHandle) {
+	r.handleLock.Lock()
+	defer r.handleLock.Unlock()
+	r.handle = handle
+}
+
 // createDriver makes a driver for the task
 func (r *TaskRunner) createDriver() (driver.Driver, error) {
 	driverCtx := driver.NewDriverContext(r.task.Name, r.config, r.config.Node, r.logger)
@@ -182,6 +236,263 @@ func (r *TaskRunner) createDriver() (driver.Driver, error) {
 	return driver, err
 }
 
+// downloadArtifacts downloads the task's artifacts into its task directory
+// unless they have already been downloaded.
+func (r *TaskRunner) downloadArtifacts() error {
+	if r.artifactsDownloaded || len(r.task.Artifacts) == 0 {
+		return nil
+	}
+
+	taskDir, ok := r.ctx.AllocDir.TaskDirs[r.task.Name]
+	if !ok {
+		return fmt.Errorf("could not find task directory for task '%s'", r.task.Name)
+	}
+	for _, artifact := range r.task.Artifacts {
+		if err := getter.GetArtifact(artifact, taskDir, r.logger); err != nil {
+			return err
+		}
+	}
+	r.artifactsDownloaded = true
+	return nil
+}
+
+// renderTemplates renders the task's templates into its local directory and
+// returns the templates whose rendered content changed.
+func (r *TaskRunner) renderTemplates() ([]*structs.Template, error) {
+	if len(r.task.Templates) == 0 {
+		return nil, nil
+	}
+
+	taskDir, ok := r.ctx.AllocDir.TaskDirs[r.task.Name]
+	if !ok {
+		return nil, fmt.Errorf("could not find task directory for task '%s'", r.task.Name)
+	}
+	data := &templateData{
+		Env:  driver.TaskEnvironmentVariables(r.ctx, r.task).Map(),
+		Meta: r.task.Meta,
+		Node: r.config.Node,
+	}
+
+	var changed []*structs.Template
+	for _, tmpl := range r.task.Templates {
+		updated, err := renderTemplate(tmpl, taskDir, data)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			changed = append(changed, tmpl)
+		}
+	}
+	return changed, nil
+}
+
+// renderSecrets reads the task's secrets from the secrets provider, writes
+// those with a destination into the secrets directory and keeps the values
+// of the env var secrets for the driver. It returns the secrets whose value
+// changed.
+func (r *TaskRunner) renderSecrets() ([]*structs.Secret, error) {
+	if len(r.task.Secrets) == 0 {
+		return nil, nil
+	}
+
+	provider := r.config.SecretsProvider
+	if provider == nil {
+		return nil, fmt.Errorf("client has no secrets provider configured")
+	}
+	taskDir, ok := r.ctx.AllocDir.TaskDirs[r.task.Name]
+	if !ok {
+		return nil, fmt.Errorf("could not find task directory for task '%s'", r.task.Name)
+	}
+
+	// Secrets sharing a path are only read once
+	read := make(map[string]map[string]string)
+	env := make(map[string]string)
+	var changed []*structs.Secret
+	for _, secret := range r.task.Secrets {
+		data, ok := read[secret.Path]
+		if !ok {
+			var err error
+			if data, err = provider.Read(secret.Path); err != nil {
+				return nil, err
+			}
+			read[secret.Path] = data
+		}
+		value, ok := data[secret.Key]
+		if !ok {
+			return nil, fmt.Errorf("secret %q has no key %q", secret.Path, secret.Key)
+		}
+
+		if secret.EnvVar != "" {
+			env[secret.EnvVar] = value
+			if old, ok := r.secretEnv[secret.EnvVar]; !ok || old != value {
+				changed = append(changed, secret)
+			}
+			continue
+		}
+
+		updated, err := writeSecret(secret, taskDir, value)
+		if err != nil {
+			return nil, err
+		}
+		if updated {
+			changed = append(changed, secret)
+		}
+	}
+	r.secretEnv = env
+	return changed, nil
+}
+
+// prestart downloads the task's artifacts, injects its secrets and renders
+// its templates. If any fails, the event describing the failure is returned.
+func (r *TaskRunner) prestart() *structs.TaskEvent {
+	if err := r.downloadArtifacts(); err != nil {
+		r.logger.Printf("[ERR] client: failed to download artifacts of task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		return structs.NewTaskEvent(structs.TaskArtifactDownloadFailed).SetDownloadError(err)
+	}
+
+	if _, err := r.renderSecrets(); err != nil {
+		r.logger.Printf("[ERR] client: failed to inject secrets of task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		return structs.NewTaskEvent(structs.TaskSecretFailure).SetSecretError(err)
+	}
+
+	if _, err := r.renderTemplates(); err != nil {
+		r.logger.Printf("[ERR] client: failed to render templates of task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		return structs.NewTaskEvent(structs.TaskTemplateFailure).SetTemplateError(err)
+	}
+	return nil
+}
+
+// updateTemplates re-renders the templates after an update of the task and
+// applies the change mode of those that changed. Signals are sent directly,
+// while the returned event is set if the task should be restarted.
+func (r *TaskRunner) updateTemplates() *structs.TaskEvent {
+	changed, err := r.renderTemplates()
+	if err != nil {
+		r.logger.Printf("[ERR] client: failed to render templates of task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		return nil
+	}
+
+	var signals []*structs.Template
+	for _, tmpl := range changed {
+		switch tmpl.ChangeMode {
+		case structs.TemplateChangeModeNoop:
+		case structs.TemplateChangeModeSignal:
+			signals = append(signals, tmpl)
+		default:
+			reason := fmt.Sprintf("Template %q changed", tmpl.DestPath)
+			return structs.NewTaskEvent(structs.TaskRestartSignal).SetReason(reason)
+		}
+	}
+
+	// Restarts take precedence so signals are only sent if the task keeps
+	// running.
+	for _, tmpl := range signals {
+		if err := r.signalTask(tmpl.ChangeSignal); err != nil {
+			r.logger.Printf("[ERR] client: failed to signal task '%s' for alloc '%s': %v",
+				r.task.Name, r.alloc.ID, err)
+			continue
+		}
+		reason := fmt.Sprintf("Template %q changed, sent %s", tmpl.DestPath, tmpl.ChangeSignal)
+		r.setState(structs.TaskStateRunning, structs.NewTaskEvent(structs.TaskSignaling).SetReason(reason))
+	}
+	return nil
+}
+
+// updateSecrets reads the secrets again and applies the change mode of those
+// that rotated. Signals are sent directly, while the returned event is set if
+// the task should be restarted.
+func (r *TaskRunner) updateSecrets() *structs.TaskEvent {
+	changed, err := r.renderSecrets()
+	if err != nil {
+		r.logger.Printf("[ERR] client: failed to read secrets of task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		return nil
+	}
+
+	var signals []*structs.Secret
+	for _, secret := range changed {
+		switch secret.ChangeMode {
+		case structs.TemplateChangeModeNoop:
+		case structs.TemplateChangeModeSignal:
+			signals = append(signals, secret)
+		default:
+			reason := fmt.Sprintf("Secret %q changed", secret.Path)
+			return structs.NewTaskEvent(structs.TaskRestartSignal).SetReason(reason)
+		}
+	}
+
+	// Restarts take precedence so signals are only sent if the task keeps
+	// running.
+	for _, secret := range signals {
+		if err := r.signalTask(secret.ChangeSignal); err != nil {
+			r.logger.Printf("[ERR] client: failed to signal task '%s' for alloc '%s': %v",
+				r.task.Name, r.alloc.ID, err)
+			continue
+		}
+		reason := fmt.Sprintf("Secret %q changed, sent %s", secret.Path, secret.ChangeSignal)
+		r.setState(structs.TaskStateRunning, structs.NewTaskEvent(structs.TaskSignaling).SetReason(reason))
+	}
+	return nil
+}
+
+// signalTask sends the named signal to the running task.
+func (r *TaskRunner) signalTask(name string) error {
+	sig, err := parseSignal(name)
+	if err != nil {
+		return err
+	}
+
+	signalHandle, ok := r.handle.(driver.SignalHandle)
+	if !ok {
+		return fmt.Errorf("driver '%s' does not support signals", r.task.Driver)
+	}
+	return signalHandle.Signal(sig)
+}
+
+// interpolateTask returns a copy of the task with the variables of its
+// environment under the driver and of the node replaced. The env var secrets
+// are added afterwards so their values are never interpolated.
+func (r *TaskRunner) interpolateTask(d driver.Driver) (*structs.Task, error) {
+	env, err := driver.DriverTaskEnvironment(d, r.ctx, r.task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build environment of task '%s': %v", r.task.Name, err)
+	}
+	interpTask := driver.InterpolateTask(r.task, driver.InterpolationVars(env, r.config.Node))
+	if len(r.secretEnv) != 0 {
+		taskEnv := make(map[string]string, len(interpTask.Env)+len(r.secretEnv))
+		for k, v := range interpTask.Env {
+			taskEnv[k] = v
+		}
+		for k, v := range r.secretEnv {
+			taskEnv[k] = v
+		}
+		interpTask.Env = taskEnv
+	}
+	return interpTask, nil
+}
+
+// serviceAlloc returns the allocation to register the services of the
+// interpolated task with. The service IDs of the allocation are keyed by the
+// service names of the job, so they are re-keyed by the interpolated names.
+func (r *TaskRunner) serviceAlloc() *structs.Allocation {
+	alloc := new(structs.Allocation)
+	*alloc = *r.alloc
+	alloc.Services = make(map[string]string, len(r.alloc.Services))
+	for name, id := range r.alloc.Services {
+		alloc.Services[name] = id
+	}
+	for i, service := range r.task.Services {
+		if id, ok := r.alloc.Services[service.Name]; ok && i < len(r.interpTask.Services) {
+			alloc.Services[r.interpTask.Services[i].Name] = id
+		}
+	}
+	return alloc
+}
+
 // startTask is used to start the task if there is no handle
 func (r *TaskRunner) startTask() error {
 	// Create a driver
@@ -192,8 +503,19 @@ func (r *TaskRunner) startTask() error {
 		return err
 	}
 
+	// Interpolate the runtime variables so every driver gets the same view
+	// of the task.
+	interpTask, err := r.interpolateTask(driver)
+	if err != nil {
+		r.logger.Printf("[ERR] client: failed to interpolate task '%s' for alloc '%s': %v",
+			r.task.Name, r.alloc.ID, err)
+		e := structs.NewTaskEvent(structs.TaskDriverFailure).SetDriverError(err)
+		r.setState(structs.TaskStateDead, e)
+		return err
+	}
+
 	// Start the job
-	handle, err := driver.Start(r.ctx, r.task)
+	handle, err := driver.Start(r.ctx, interpTask)
 	if err != nil {
 		r.logger.Printf("[ERR] client: failed to start task '%s' for alloc '%s': %v",
 			r.task.Name, r.alloc.ID, err)
@@ -202,7 +524,8 @@ func (r *TaskRunner) startTask() error {
 		r.setState(structs.TaskStateDead, e)
 		return err
 	}
-	r.handle = handle
+	r.setHandle(handle)
+	r.interpTask = interpTask
 	r.setState(structs.TaskStateRunning, structs.NewTaskEvent(structs.TaskStarted))
 	return nil
 }
@@ -223,6 +546,24 @@ func (r *TaskRunner) run() {
 		// Start the task if not yet started or it is being forced.
 		if r.handle == nil || forceStart {
 			forceStart = false
+
+			// Download the artifacts and render the templates before
+			// starting the task, retrying according to the restart policy.
+			if event := r.prestart(); event != nil {
+				shouldRestart, when := r.restartTracker.NextRestart(-1)
+				if !shouldRestart {
+					r.setState(structs.TaskStateDead, event)
+					return
+				}
+
+				r.setState(structs.TaskStatePending, event)
+				if !r.waitRestart(when) {
+					return
+				}
+				forceStart = true
+				continue
+			}
+
 			if err := r.startTask(); err != nil {
 				return
 			}
@@ -231,10 +572,24 @@ func (r *TaskRunner) run() {
 		// Store the errors that caused use to stop waiting for updates.
 		var waitRes *cstructs.WaitResult
 		var destroyErr error
+		var restartEvent *structs.TaskEvent
 		destroyed := false
 
 		// Register the services defined by the task with Consil
-		r.consulService.Register(r.task, r.alloc)
+		interpTask, serviceAlloc := r.interpTask, r.serviceAlloc()
+		r.consulService.Register(interpTask, serviceAlloc)
+
+		// Sample the resource usage of the task while it is running
+		stopCollection := make(chan struct{})
+		go r.collectResourceUsageStats(r.handle, stopCollection)
+
+		// Read the secrets periodically to pick up their rotations
+		var secretsCh <-chan time.Time
+		var secretsTicker *time.Ticker
+		if len(r.task.Secrets) != 0 {
+			secretsTicker = time.NewTicker(r.secretsRefreshInterval())
+			secretsCh = secretsTicker.C
+		}
 
 	OUTER:
 		// Wait for updates
@@ -248,6 +603,29 @@ func (r *TaskRunner) run() {
 				if err := r.handle.Update(update); err != nil {
 					r.logger.Printf("[ERR] client: failed to update task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
 				}
+
+				// Re-render the templates and restart the task if one of
+				// them requires it.
+				if restartEvent != nil {
+					continue
+				}
+				if restartEvent = r.updateTemplates(); restartEvent != nil {
+					r.logger.Printf("[INFO] client: restarting task '%s' for alloc '%s': %s", r.task.Name, r.alloc.ID, restartEvent.Reason)
+					if err := r.handle.Kill(); err != nil {
+						r.logger.Printf("[ERR] client: failed to kill task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
+					}
+				}
+			case <-secretsCh:
+				// Restart the task if a rotated secret requires it
+				if restartEvent != nil {
+					continue
+				}
+				if restartEvent = r.updateSecrets(); restartEvent != nil {
+					r.logger.Printf("[INFO] client: restarting task '%s' for alloc '%s': %s", r.task.Name, r.alloc.ID, restartEvent.Reason)
+					if err := r.handle.Kill(); err != nil {
+						r.logger.Printf("[ERR] client: failed to kill task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, err)
+					}
+				}
 			case <-r.destroyCh:
 				// Avoid destroying twice
 				if destroyed {
@@ -263,15 +641,28 @@ func (r *TaskRunner) run() {
 			}
 		}
 
-		// De-Register the services belonging to the task from consul
-		r.consulService.Deregister(r.task, r.alloc)
+		// Stop sampling and De-Register the services belonging to the task
+		// from consul
+		close(stopCollection)
+		if secretsTicker != nil {
+			secretsTicker.Stop()
+		}
+		r.consulService.Deregister(interpTask, serviceAlloc)
 
 		// If the user destroyed the task, we do not attempt to do any restarts.
 		if destroyed {
-			r.setState(structs.TaskStateDead, structs.NewTaskEvent(structs.TaskKilled).SetKillError(destroyErr))
+			r.setState(structs.TaskStateDead, r.destroyEvent.SetKillError(destroyErr))
 			return
 		}
 
+		// Restarts caused by template or secret changes don't count against
+		// the restart policy.
+		if restartEvent != nil {
+			r.setState(structs.TaskStatePending, restartEvent)
+			forceStart = true
+			continue
+		}
+
 		// Log whether the task was successful or not.
 		if !waitRes.Successful() {
 			r.logger.Printf("[ERR] client: failed to complete task '%s' for alloc '%s': %v", r.task.Name, r.alloc.ID, waitRes)
@@ -279,9 +670,16 @@ func (r *TaskRunner) run() {
 			r.logger.Printf("[INFO] client: completed task '%s' for alloc '%s'", r.task.Name, r.alloc.ID)
 		}
 
+		// Ephemeral lifecycle hooks are run to completion, so they are not
+		// restarted once they succeed.
+		waitEvent := r.waitErrorToEvent(waitRes)
+		if r.task.IsEphemeral() && waitRes.Successful() {
+			r.setState(structs.TaskStateDead, waitEvent)
+			return
+		}
+
 		// Check if we should restart. If not mark task as dead and exit.
 		shouldRestart, when := r.restartTracker.NextRestart(waitRes.ExitCode)
-		waitEvent := r.waitErrorToEvent(waitRes)
 		if !shouldRestart {
 			r.logger.Printf("[INFO] client: Not restarting task: %v for alloc: %v ", r.task.Name, r.alloc.ID)
 			r.setState(structs.TaskStateDead, waitEvent)
@@ -289,22 +687,8 @@ func (r *TaskRunner) run() {
 		}
 
 		r.logger.Printf("[INFO] client: Restarting Task: %v", r.task.Name)
-		r.logger.Printf("[DEBUG] client: Sleeping for %v before restarting Task %v", when, r.task.Name)
 		r.setState(structs.TaskStatePending, waitEvent)
-
-		// Sleep but watch for destroy events.
-		select {
-		case <-time.After(when):
-		case <-r.destroyCh:
-		}
-
-		// Destroyed while we were waiting to restart, so abort.
-		r.destroyLock.Lock()
-		destroyed = r.destroy
-		r.destroyLock.Unlock()
-		if destroyed {
-			r.logger.Printf("[DEBUG] client: Not restarting task: %v because it's destroyed by user", r.task.Name)
-			r.setState(structs.TaskStateDead, structs.NewTaskEvent(structs.TaskKilled))
+		if !r.waitRestart(when) {
 			return
 		}
 
@@ -315,6 +699,133 @@ func (r *TaskRunner) run() {
 	return
 }
 
+// secretsRefreshInterval returns the interval at which the secrets of the
+// running task are read again.
+func (r *TaskRunner) secretsRefreshInterval() time.Duration {
+	if interval := r.config.SecretsRefreshInterval; interval > 0 {
+		return interval
+	}
+	return 5 * time.Minute
+}
+
+// collectResourceUsageStats periodically samples the resource usage of the
+// task through its handle until stopCh is closed.
+func (r *TaskRunner) collectResourceUsageStats(handle driver.DriverHandle, stopCh <-chan struct{}) {
+	statsHandle, ok := handle.(driver.StatsHandle)
+	if !ok {
+		r.logger.Printf("[DEBUG] client: driver for task '%s' in alloc '%s' doesn't support resource usage statistics",
+			r.task.Name, r.alloc.ID)
+		return
+	}
+
+	interval := r.config.StatsCollectionInterval
+	if interval == 0 {
+		interval = 1 * time.Second
+	}
+	next := time.NewTimer(0)
+	defer next.Stop()
+	for {
+		select {
+		case <-next.C:
+			ru, err := statsHandle.Stats()
+			if err != nil {
+				r.logger.Printf("[DEBUG] client: error fetching stats of task '%s' in alloc '%s': %v",
+					r.task.Name, r.alloc.ID, err)
+			} else {
+				r.setResourceUsage(ru)
+				r.emitStats(ru)
+			}
+			next.Reset(interval)
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// setResourceUsage stores the sample, deriving the CPU usage in MHz and as a
+// percentage of the task's reservation.
+func (r *TaskRunner) setResourceUsage(ru *cstructs.TaskResourceUsage) {
+	if ru.ResourceUsage != nil && ru.ResourceUsage.CpuStats != nil {
+		cs := ru.ResourceUsage.CpuStats
+		if freq, err := r.cpuFrequency(); err == nil {
+			cs.TotalTicks = cs.Percent / 100 * freq
+			cs.Measured = append(cs.Measured, "Total Ticks")
+			if res := r.task.Resources; res != nil && res.CPU > 0 {
+				cs.ReservedPercent = cs.TotalTicks / float64(res.CPU) * 100
+				cs.Measured = append(cs.Measured, "Reserved Percent")
+			}
+		}
+	}
+
+	r.resourceUsageLock.Lock()
+	r.resourceUsage = ru
+	r.resourceUsageLock.Unlock()
+}
+
+// cpuFrequency returns the frequency of a CPU core of the node in MHz.
+func (r *TaskRunner) cpuFrequency() (float64, error) {
+	node := r.config.Node
+	if node == nil {
+		return 0, fmt.Errorf("node not available")
+	}
+	return strconv.ParseFloat(node.Attributes["cpu.frequency"], 64)
+}
+
+// emitStats emits the resource usage of the task as metrics.
+func (r *TaskRunner) emitStats(ru *cstructs.TaskResourceUsage) {
+	if ru.ResourceUsage == nil {
+		return
+	}
+	prefix := []string{"client", "allocs", r.alloc.Job.Name, r.alloc.TaskGroup, r.alloc.ID, r.task.Name}
+	gauge := func(value float32, keys ...string) {
+		metrics.SetGauge(append(append([]string{}, prefix...), keys...), value)
+	}
+	if ms := ru.ResourceUsage.MemoryStats; ms != nil {
+		gauge(float32(ms.RSS), "memory", "rss")
+		gauge(float32(ms.Cache), "memory", "cache")
+		gauge(float32(ms.Swap), "memory", "swap")
+		gauge(float32(ms.MaxUsage), "memory", "max_usage")
+	}
+	if cs := ru.ResourceUsage.CpuStats; cs != nil {
+		gauge(float32(cs.Percent), "cpu", "total_percent")
+		gauge(float32(cs.SystemMode), "cpu", "system")
+		gauge(float32(cs.UserMode), "cpu", "user")
+		gauge(float32(cs.ThrottledTime), "cpu", "throttled_time")
+		gauge(float32(cs.ThrottledPeriods), "cpu", "throttled_periods")
+		gauge(float32(cs.TotalTicks), "cpu", "total_ticks")
+	}
+}
+
+// LatestResourceUsage returns the latest resource usage sample of the task,
+// or nil if none has been collected yet.
+func (r *TaskRunner) LatestResourceUsage() *cstructs.TaskResourceUsage {
+	r.resourceUsageLock.RLock()
+	defer r.resourceUsageLock.RUnlock()
+	return r.resourceUsage
+}
+
+// waitRestart sleeps before a restart while watching for destroy events. It
+// returns false if the task was destroyed while waiting, in which case the
+// task is marked as dead.
+func (r *TaskRunner) waitRestart(when time.Duration) bool {
+	r.logger.Printf("[DEBUG] client: Sleeping for %v before restarting Task %v", when, r.task.Name)
+	select {
+	case <-time.After(when):
+	case <-r.destroyCh:
+	}
+
+	// Destroyed while we were waiting to restart, so abort.
+	r.destroyLock.Lock()
+	destroyed := r.destroy
+	r.destroyLock.Unlock()
+	if destroyed {
+		r.logger.Printf("[DEBUG] client: Not restarting task: %v because it's destroyed by user", r.task.Name)
+		r.setState(structs.TaskStateDead, r.destroyEvent)
+		return false
+	}
+	return true
+}
+
 // Helper function for converting a WaitResult into a TaskTerminated event.
 func (r *TaskRunner) waitErrorToEvent(res *cstructs.WaitResult) *structs.TaskEvent {
 	return structs.NewTaskEvent(structs.TaskTerminated).
@@ -323,6 +834,20 @@ func (r *TaskRunner) waitErrorToEvent(res *cstructs.WaitResult) *structs.TaskEve
 		SetExitMessage(res.Err)
 }
 
+// Exec runs a command inside the isolation context of the running task.
+func (r *TaskRunner) Exec(req *cstructs.ExecRequest) (int, error) {
+	handle := r.getHandle()
+	if handle == nil || r.state.State != structs.TaskStateRunning {
+		return -1, fmt.Errorf("task '%s' is not running", r.task.Name)
+	}
+
+	execHandle, ok := handle.(driver.ExecHandle)
+	if !ok {
+		return -1, fmt.Errorf("driver '%s' does not support exec", r.task.Driver)
+	}
+	return execHandle.Exec(req)
+}
+
 // Update is used to update the task of the context
 func (r *TaskRunner) Update(update *structs.Task) {
 	select {
@@ -335,6 +860,12 @@ func (r *TaskRunner) Update(update *structs.Task) {
 
 // Destroy is used to indicate that the task context should be destroyed
 func (r *TaskRunner) Destroy() {
+	r.DestroyWithEvent(structs.NewTaskEvent(structs.TaskKilled))
+}
+
+// DestroyWithEvent destroys the task context and records the given event as
+// the reason the task was killed.
+func (r *TaskRunner) DestroyWithEvent(event *structs.TaskEvent) {
 	r.destroyLock.Lock()
 	defer r.destroyLock.Unlock()
 
@@ -342,5 +873,6 @@ func (r *TaskRunner) Destroy() {
 		return
 	}
 	r.destroy = true
+	r.destroyEvent = event
 	close(r.destroyCh)
 }
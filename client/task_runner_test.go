@@ -1,14 +1,21 @@
 package client
 
 import (
+	"fmt"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/hashicorp/nomad/client/allocdir"
 	"github.com/hashicorp/nomad/client/driver"
+	"github.com/hashicorp/nomad/client/secrets"
+	"github.com/hashicorp/nomad/helper/testtask"
 	"github.com/hashicorp/nomad/nomad/mock"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
@@ -40,7 +47,7 @@ func testTaskRunner(restarts bool) (*MockTaskStateUpdater, *TaskRunner) {
 	allocDir := allocdir.NewAllocDir(filepath.Join(conf.AllocDir, alloc.ID))
 	allocDir.Build([]*structs.Task{task})
 
-	ctx := driver.NewExecContext(allocDir, alloc.ID)
+	ctx := driver.NewExecContext(allocDir, alloc, conf.Node)
 	rp := structs.NewRestartPolicy(structs.JobTypeService)
 	restartTracker := newRestartTracker(rp)
 	if !restarts {
@@ -180,3 +187,296 @@ func TestTaskRunner_SaveRestoreState(t *testing.T) {
 		t.Fatalf("RestoreState() didn't open handle")
 	}
 }
+
+func TestTaskRunner_Download_Artifact(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	ts := httptest.NewServer(http.FileServer(http.Dir(filepath.Dir(testtask.Path()))))
+	defer ts.Close()
+
+	_, tr := testTaskRunner(false)
+	defer tr.ctx.AllocDir.Destroy()
+
+	file := filepath.Base(testtask.Path())
+	tr.task.Artifacts = []*structs.TaskArtifact{
+		&structs.TaskArtifact{
+			GetterSource: fmt.Sprintf("%s/%s", ts.URL, file),
+		},
+	}
+	go tr.Run()
+	defer tr.Destroy()
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if tr.state.Events[0].Type != structs.TaskStarted {
+		t.Fatalf("First Event was %v; want %v", tr.state.Events[0].Type, structs.TaskStarted)
+	}
+
+	taskDir := tr.ctx.AllocDir.TaskDirs[tr.task.Name]
+	if _, err := os.Stat(filepath.Join(taskDir, allocdir.TaskLocal, file)); err != nil {
+		t.Fatalf("artifact not downloaded: %v", err)
+	}
+}
+
+func TestTaskRunner_Download_Failed(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	_, tr := testTaskRunner(false)
+	defer tr.ctx.AllocDir.Destroy()
+
+	policy := &structs.RestartPolicy{
+		Attempts: 0,
+		Interval: 10 * time.Minute,
+		Delay:    time.Second,
+		Mode:     structs.RestartPolicyModeFail,
+	}
+	tr.restartTracker = newRestartTracker(policy)
+	tr.task.Artifacts = []*structs.TaskArtifact{
+		&structs.TaskArtifact{
+			GetterSource: ts.URL + "/missing",
+		},
+	}
+	go tr.Run()
+	defer tr.Destroy()
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if tr.state.State != structs.TaskStateDead {
+		t.Fatalf("TaskState %v; want %v", tr.state.State, structs.TaskStateDead)
+	}
+	if len(tr.state.Events) != 1 {
+		t.Fatalf("should have 1 update: %#v", tr.state.Events)
+	}
+	if e := tr.state.Events[0]; e.Type != structs.TaskArtifactDownloadFailed || e.DownloadError == "" {
+		t.Fatalf("bad event: %#v", e)
+	}
+}
+
+func TestTaskRunner_Template_Restart(t *testing.T) {
+	ctestutil.ExecCompatible(t)
+	_, tr := testTaskRunner(false)
+
+	// Change command to ensure we run for a bit
+	tr.task.Config["command"] = "/bin/sleep"
+	tr.task.Config["args"] = []string{"10"}
+	tr.task.Meta = map[string]string{"version": "1"}
+	tr.task.Templates = []*structs.Template{
+		&structs.Template{
+			EmbeddedTmpl: "version={{.Meta.version}}",
+			DestPath:     "app.conf",
+		},
+	}
+	go tr.Run()
+	defer tr.Destroy()
+	defer tr.ctx.AllocDir.Destroy()
+
+	dest := filepath.Join(tr.ctx.AllocDir.TaskDirs[tr.task.Name], allocdir.TaskLocal, "app.conf")
+	testutil.WaitForResult(func() (bool, error) {
+		out, err := ioutil.ReadFile(dest)
+		if err != nil {
+			return false, err
+		}
+		return string(out) == "version=1", fmt.Errorf("bad: %q", out)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Update the meta the template is rendered with
+	newTask := new(structs.Task)
+	*newTask = *tr.task
+	newTask.Meta = map[string]string{"version": "2"}
+	tr.Update(newTask)
+
+	// The task should be restarted with the re-rendered template
+	testutil.WaitForResult(func() (bool, error) {
+		out, err := ioutil.ReadFile(dest)
+		if err != nil {
+			return false, err
+		}
+		if string(out) != "version=2" {
+			return false, fmt.Errorf("bad: %q", out)
+		}
+
+		for _, e := range tr.state.Events {
+			if e.Type == structs.TaskRestartSignal {
+				return tr.state.State == structs.TaskStateRunning, fmt.Errorf("state: %v", tr.state.State)
+			}
+		}
+		return false, fmt.Errorf("task not restarted: %#v", tr.state.Events)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestTaskRunner_MockDriver_Restart(t *testing.T) {
+	_, tr := testTaskRunner(true)
+	tr.task.Driver = "mock_driver"
+	tr.task.Config = map[string]interface{}{
+		"run_for":   "10ms",
+		"exit_code": 1,
+	}
+	tr.restartTracker = newRestartTracker(&structs.RestartPolicy{
+		Attempts: 2,
+		Interval: time.Minute,
+		Delay:    10 * time.Millisecond,
+		Mode:     structs.RestartPolicyModeFail,
+	})
+	go tr.Run()
+	defer tr.Destroy()
+	defer tr.ctx.AllocDir.Destroy()
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	// The task is started and fails three times before giving up
+	if tr.state.State != structs.TaskStateDead {
+		t.Fatalf("TaskState %v; want %v", tr.state.State, structs.TaskStateDead)
+	}
+	var started, terminated int
+	for _, e := range tr.state.Events {
+		switch e.Type {
+		case structs.TaskStarted:
+			started++
+		case structs.TaskTerminated:
+			terminated++
+			if e.ExitCode != 1 {
+				t.Fatalf("bad: %#v", e)
+			}
+		}
+	}
+	if started != 3 || terminated != 3 {
+		t.Fatalf("bad: %#v", tr.state.Events)
+	}
+}
+
+func TestTaskRunner_MockDriver_StartError(t *testing.T) {
+	_, tr := testTaskRunner(false)
+	tr.task.Driver = "mock_driver"
+	tr.task.Config = map[string]interface{}{
+		"start_error": "boom",
+	}
+	go tr.Run()
+	defer tr.Destroy()
+	defer tr.ctx.AllocDir.Destroy()
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if tr.state.State != structs.TaskStateDead {
+		t.Fatalf("TaskState %v; want %v", tr.state.State, structs.TaskStateDead)
+	}
+	last := tr.state.Events[len(tr.state.Events)-1]
+	if last.Type != structs.TaskDriverFailure || !strings.Contains(last.DriverError, "boom") {
+		t.Fatalf("bad: %#v", last)
+	}
+}
+
+func TestTaskRunner_Secrets_Restart(t *testing.T) {
+	server := secrets.NewTestServer()
+	defer server.Stop()
+	server.Set("secret/db", map[string]interface{}{"password": "hunter2", "user": "web"})
+
+	_, tr := testTaskRunner(false)
+	provider, err := secrets.NewProvider(server.Config())
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	tr.config.SecretsProvider = provider
+	tr.config.SecretsRefreshInterval = 50 * time.Millisecond
+	tr.task.Driver = "mock_driver"
+	tr.task.Config = map[string]interface{}{
+		"run_for": "10s",
+	}
+	tr.task.Secrets = []*structs.Secret{
+		&structs.Secret{Path: "secret/db", Key: "password", DestPath: "db.pass"},
+		&structs.Secret{Path: "secret/db", Key: "user", EnvVar: "DB_USER"},
+	}
+	go tr.Run()
+	defer tr.Destroy()
+	defer tr.ctx.AllocDir.Destroy()
+
+	// The secrets are injected before the task starts
+	dest := filepath.Join(tr.ctx.AllocDir.TaskDirs[tr.task.Name], allocdir.TaskSecrets, "db.pass")
+	testutil.WaitForResult(func() (bool, error) {
+		out, err := ioutil.ReadFile(dest)
+		if err != nil {
+			return false, err
+		}
+		if string(out) != "hunter2" {
+			return false, fmt.Errorf("bad: %q", out)
+		}
+		if tr.state.State != structs.TaskStateRunning {
+			return false, fmt.Errorf("state: %v", tr.state.State)
+		}
+		return tr.interpTask.Env["DB_USER"] == "web", fmt.Errorf("bad env: %#v", tr.interpTask.Env)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+
+	// Rotate the secret
+	server.Set("secret/db", map[string]interface{}{"password": "hunter3", "user": "web2"})
+
+	// The task should be restarted with the new values
+	testutil.WaitForResult(func() (bool, error) {
+		out, err := ioutil.ReadFile(dest)
+		if err != nil {
+			return false, err
+		}
+		if string(out) != "hunter3" {
+			return false, fmt.Errorf("bad: %q", out)
+		}
+
+		for _, e := range tr.state.Events {
+			if e.Type == structs.TaskRestartSignal {
+				if tr.state.State != structs.TaskStateRunning {
+					return false, fmt.Errorf("state: %v", tr.state.State)
+				}
+				return tr.interpTask.Env["DB_USER"] == "web2", fmt.Errorf("bad env: %#v", tr.interpTask.Env)
+			}
+		}
+		return false, fmt.Errorf("task not restarted: %#v", tr.state.Events)
+	}, func(err error) {
+		t.Fatalf("err: %v", err)
+	})
+}
+
+func TestTaskRunner_Secrets_NoProvider(t *testing.T) {
+	_, tr := testTaskRunner(false)
+	tr.task.Driver = "mock_driver"
+	tr.task.Secrets = []*structs.Secret{
+		&structs.Secret{Path: "secret/db", Key: "password", DestPath: "db.pass"},
+	}
+	go tr.Run()
+	defer tr.Destroy()
+	defer tr.ctx.AllocDir.Destroy()
+
+	select {
+	case <-tr.WaitCh():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	if tr.state.State != structs.TaskStateDead {
+		t.Fatalf("TaskState %v; want %v", tr.state.State, structs.TaskStateDead)
+	}
+	if len(tr.state.Events) != 1 {
+		t.Fatalf("should have 1 update: %#v", tr.state.Events)
+	}
+	if e := tr.state.Events[0]; e.Type != structs.TaskSecretFailure || e.SecretError == "" {
+		t.Fatalf("bad event: %#v", e)
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// templateData is the data task templates are rendered with.
+type templateData struct {
+	// Env is the environment of the task, including its ports, IP, meta
+	// and the alloc directory.
+	Env map[string]string
+
+	// Meta is the meta data of the task.
+	Meta map[string]string
+
+	// Node is the node the task is running on.
+	Node *structs.Node
+}
+
+// renderTemplate renders the template into the task's local directory and
+// returns whether the content of the destination changed.
+func renderTemplate(tmpl *structs.Template, taskDir string, data *templateData) (bool, error) {
+	contents := tmpl.EmbeddedTmpl
+	if tmpl.SourcePath != "" {
+		raw, err := ioutil.ReadFile(filepath.Join(taskDir, tmpl.SourcePath))
+		if err != nil {
+			return false, fmt.Errorf("failed to read template %q: %v", tmpl.SourcePath, err)
+		}
+		contents = string(raw)
+	}
+
+	t, err := template.New(tmpl.DestPath).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{
+			"env": func(key string) string { return data.Env[key] },
+		}).
+		Parse(contents)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse template for %q: %v", tmpl.DestPath, err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return false, fmt.Errorf("failed to render template for %q: %v", tmpl.DestPath, err)
+	}
+
+	// Only write the destination if its content changed
+	dest := filepath.Join(taskDir, allocdir.TaskLocal, tmpl.DestPath)
+	if existing, err := ioutil.ReadFile(dest); err == nil && bytes.Equal(existing, buf.Bytes()) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0777); err != nil {
+		return false, fmt.Errorf("failed to create directory for %q: %v", tmpl.DestPath, err)
+	}
+
+	// Write to a temporary file first so the task never sees a partially
+	// rendered file.
+	tmp := dest + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %q: %v", tmpl.DestPath, err)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("failed to write %q: %v", tmpl.DestPath, err)
+	}
+	return true, nil
+}
+
+// parseSignal returns the signal with the given name, such as SIGHUP.
+func parseSignal(name string) (os.Signal, error) {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := signalLookup[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown signal %q", name)
+	}
+	return sig, nil
+}
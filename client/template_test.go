@@ -0,0 +1,126 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/client/allocdir"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func testTemplateDir(t *testing.T) string {
+	taskDir, err := ioutil.TempDir("", "nomad-test")
+	if err != nil {
+		t.Fatalf("failed to make temp directory: %v", err)
+	}
+	return taskDir
+}
+
+func TestRenderTemplate(t *testing.T) {
+	taskDir := testTemplateDir(t)
+	defer os.RemoveAll(taskDir)
+
+	tmpl := &structs.Template{
+		EmbeddedTmpl: `{{.Env.NOMAD_IP}}:{{env "NOMAD_PORT_http"}} {{.Meta.version}} {{index .Node.Attributes "kernel.name"}}`,
+		DestPath:     "conf/app.conf",
+	}
+	data := &templateData{
+		Env: map[string]string{
+			"NOMAD_IP":        "10.0.0.1",
+			"NOMAD_PORT_http": "8080",
+		},
+		Meta: map[string]string{"version": "1.0"},
+		Node: mock.Node(),
+	}
+
+	changed, err := renderTemplate(tmpl, taskDir, data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected template to be rendered")
+	}
+
+	dest := filepath.Join(taskDir, allocdir.TaskLocal, "conf", "app.conf")
+	out, err := ioutil.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if exp := "10.0.0.1:8080 1.0 linux"; string(out) != exp {
+		t.Fatalf("got %q; want %q", out, exp)
+	}
+
+	// Rendering the same content again isn't a change
+	changed, err = renderTemplate(tmpl, taskDir, data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if changed {
+		t.Fatalf("expected template to be unchanged")
+	}
+
+	// Changing the inputs changes the rendered file
+	data.Meta["version"] = "2.0"
+	changed, err = renderTemplate(tmpl, taskDir, data)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !changed {
+		t.Fatalf("expected template to be changed")
+	}
+}
+
+func TestRenderTemplate_Source(t *testing.T) {
+	taskDir := testTemplateDir(t)
+	defer os.RemoveAll(taskDir)
+
+	source := filepath.Join(taskDir, "app.tmpl")
+	if err := ioutil.WriteFile(source, []byte("port={{.Env.NOMAD_PORT_http}}"), 0644); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tmpl := &structs.Template{SourcePath: "app.tmpl", DestPath: "app.conf"}
+	data := &templateData{Env: map[string]string{"NOMAD_PORT_http": "8080"}}
+	if _, err := renderTemplate(tmpl, taskDir, data); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(taskDir, allocdir.TaskLocal, "app.conf"))
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if exp := "port=8080"; string(out) != exp {
+		t.Fatalf("got %q; want %q", out, exp)
+	}
+}
+
+func TestRenderTemplate_Fails(t *testing.T) {
+	taskDir := testTemplateDir(t)
+	defer os.RemoveAll(taskDir)
+
+	data := &templateData{Env: map[string]string{}}
+	failing := []*structs.Template{
+		&structs.Template{SourcePath: "missing.tmpl", DestPath: "app.conf"},
+		&structs.Template{EmbeddedTmpl: "{{.Env.NOMAD_IP", DestPath: "app.conf"},
+		&structs.Template{EmbeddedTmpl: "{{.Env.NOMAD_IP}}", DestPath: "app.conf"},
+	}
+	for _, tmpl := range failing {
+		if _, err := renderTemplate(tmpl, taskDir, data); err == nil {
+			t.Fatalf("%#v: expected failure", tmpl)
+		}
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"SIGHUP", "sighup", "HUP"} {
+		if _, err := parseSignal(name); err != nil {
+			t.Fatalf("%q: err: %v", name, err)
+		}
+	}
+	if _, err := parseSignal("SIGFOO"); err == nil {
+		t.Fatalf("expected unknown signal error")
+	}
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+)
+
+func TestACLBootstrapCommand_Implements(t *testing.T) {
+	var _ cli.Command = &ACLBootstrapCommand{}
+}
+
+func TestACLBootstrapCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &ACLBootstrapCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error bootstrapping ACLs") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestACLBootstrapCommand_Run(t *testing.T) {
+	srv, _, url := testServer(t, func(c *testutil.TestServerConfig) {
+		c.ACL = &testutil.ACLConfig{Enabled: true}
+	})
+	defer srv.Stop()
+
+	ui := new(cli.MockUi)
+	cmd := &ACLBootstrapCommand{Meta: Meta{Ui: ui}}
+
+	// Outputs the management token
+	if code := cmd.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "Secret ID") || !strings.Contains(out, "management") {
+		t.Fatalf("expected management token, got: %s", out)
+	}
+
+	// Fails once bootstrapped
+	if code := cmd.Run([]string{"-address=" + url}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error bootstrapping ACLs") {
+		t.Fatalf("expected failed bootstrap error, got: %s", out)
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type ACLPolicyApplyCommand struct {
+	Meta
+}
+
+func (c *ACLPolicyApplyCommand) Help() string {
+	helpText := `
+Usage: nomad acl-policy-apply [options] <name> <path>
+
+  Creates or updates the ACL policy with the rules of the HCL file at the
+  given path. The rules are read from stdin if the path is "-". This
+  requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Apply Options:
+
+  -description=<text>
+    Sets the description of the policy.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLPolicyApplyCommand) Synopsis() string {
+	return "Create or update an ACL policy"
+}
+
+func (c *ACLPolicyApplyCommand) Run(args []string) int {
+	var description string
+
+	flags := c.Meta.FlagSet("acl-policy-apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&description, "description", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the name and path of the policy
+	args = flags.Args()
+	if len(args) != 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name, path := args[0], args[1]
+
+	// Read the rules
+	var rules []byte
+	var err error
+	if path == "-" {
+		rules, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		rules, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading policy rules: %s", err))
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Upsert the policy
+	policy := &api.ACLPolicy{
+		Name:        name,
+		Description: description,
+		Rules:       string(rules),
+	}
+	if _, err := client.ACLPolicies().Upsert(policy, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing ACL policy: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote %q ACL policy!", name))
+	return 0
+}
@@ -0,0 +1,34 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestACLPolicyApplyCommand_Implements(t *testing.T) {
+	var _ cli.Command = &ACLPolicyApplyCommand{}
+}
+
+func TestACLPolicyApplyCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &ACLPolicyApplyCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on a missing rules file
+	if code := cmd.Run([]string{"-address=nope", "foo", "/nope/policy.hcl"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error reading policy rules") {
+		t.Fatalf("expected failed read error, got: %s", out)
+	}
+}
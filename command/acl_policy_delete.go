@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ACLPolicyDeleteCommand struct {
+	Meta
+}
+
+func (c *ACLPolicyDeleteCommand) Help() string {
+	helpText := `
+Usage: nomad acl-policy-delete [options] <name>
+
+  Deletes the ACL policy with the given name. Tokens granted the policy
+  lose its access. This requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLPolicyDeleteCommand) Synopsis() string {
+	return "Delete an ACL policy"
+}
+
+func (c *ACLPolicyDeleteCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("acl-policy-delete", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the policy name
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Delete the policy
+	if _, err := client.ACLPolicies().Delete(name, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error deleting ACL policy: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully deleted %q ACL policy!", name))
+	return 0
+}
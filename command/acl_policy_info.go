@@ -0,0 +1,70 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ACLPolicyInfoCommand struct {
+	Meta
+}
+
+func (c *ACLPolicyInfoCommand) Help() string {
+	helpText := `
+Usage: nomad acl-policy-info [options] <name>
+
+  Displays the ACL policy with the given name along with its rules.
+  Management tokens can read any policy while client tokens can only read
+  the policies they are granted.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLPolicyInfoCommand) Synopsis() string {
+	return "Display an ACL policy"
+}
+
+func (c *ACLPolicyInfoCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("acl-policy-info", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the policy name
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the policy
+	policy, _, err := client.ACLPolicies().Info(name, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying ACL policy: %s", err))
+		return 1
+	}
+
+	basic := []string{
+		fmt.Sprintf("Name|%s", policy.Name),
+		fmt.Sprintf("Description|%s", policy.Description),
+		fmt.Sprintf("CreateIndex|%d", policy.CreateIndex),
+		fmt.Sprintf("ModifyIndex|%d", policy.ModifyIndex),
+	}
+	c.Ui.Output(formatKV(basic))
+	c.Ui.Output("\n==> Rules")
+	c.Ui.Output(strings.TrimSpace(policy.Rules))
+	return 0
+}
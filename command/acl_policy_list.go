@@ -0,0 +1,69 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ACLPolicyListCommand struct {
+	Meta
+}
+
+func (c *ACLPolicyListCommand) Help() string {
+	helpText := `
+Usage: nomad acl-policy-list [options]
+
+  Displays the list of ACL policies. This requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLPolicyListCommand) Synopsis() string {
+	return "List the ACL policies"
+}
+
+func (c *ACLPolicyListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("acl-policy-list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check for extra arguments
+	args = flags.Args()
+	if len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the policies
+	policies, _, err := client.ACLPolicies().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying ACL policies: %s", err))
+		return 1
+	}
+
+	// Return nothing if no policies found
+	if len(policies) == 0 {
+		return 0
+	}
+
+	out := make([]string, len(policies)+1)
+	out[0] = "Name|Description"
+	for i, policy := range policies {
+		out[i+1] = fmt.Sprintf("%s|%s", policy.Name, policy.Description)
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/hashicorp/nomad/helper/flag-slice"
+)
+
+type ACLTokenCreateCommand struct {
+	Meta
+}
+
+func (c *ACLTokenCreateCommand) Help() string {
+	helpText := `
+Usage: nomad acl-token-create [options]
+
+  Creates an ACL token and outputs it along with its secret ID. This
+  requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Create Options:
+
+  -name=<name>
+    Sets the name of the token.
+
+  -type=<type>
+    Sets the type of the token, either "client" or "management".
+    Defaults to "client".
+
+  -policy=<name>
+    Grants the policy to the token. Can be specified multiple times.
+    Client tokens must be granted at least one policy.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLTokenCreateCommand) Synopsis() string {
+	return "Create an ACL token"
+}
+
+func (c *ACLTokenCreateCommand) Run(args []string) int {
+	var name, tokenType string
+	var policies []string
+
+	flags := c.Meta.FlagSet("acl-token-create", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&name, "name", "", "")
+	flags.StringVar(&tokenType, "type", api.ACLClientToken, "")
+	flags.Var((*sliceflag.StringFlag)(&policies), "policy", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check for extra arguments
+	args = flags.Args()
+	if len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Create the token
+	token := &api.ACLToken{
+		Name:     name,
+		Type:     tokenType,
+		Policies: policies,
+	}
+	token, _, err = client.ACLTokens().Create(token, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error creating ACL token: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(formatACLToken(token))
+	return 0
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type ACLTokenInfoCommand struct {
+	Meta
+}
+
+func (c *ACLTokenInfoCommand) Help() string {
+	helpText := `
+Usage: nomad acl-token-info [options] <accessor-id>
+
+  Displays the ACL token with the given accessor ID, including its secret
+  ID. This requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLTokenInfoCommand) Synopsis() string {
+	return "Display an ACL token"
+}
+
+func (c *ACLTokenInfoCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("acl-token-info", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the accessor ID
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	accessorID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the token
+	token, _, err := client.ACLTokens().Info(accessorID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying ACL token: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(formatACLToken(token))
+	return 0
+}
+
+// formatACLToken formats the token, including its secret ID, into aligned
+// k = v pairs.
+func formatACLToken(token *api.ACLToken) string {
+	out := []string{
+		fmt.Sprintf("Accessor ID|%s", token.AccessorID),
+		fmt.Sprintf("Secret ID|%s", token.SecretID),
+		fmt.Sprintf("Name|%s", token.Name),
+		fmt.Sprintf("Type|%s", token.Type),
+		fmt.Sprintf("Policies|%s", strings.Join(token.Policies, ",")),
+		fmt.Sprintf("Create Time|%s", token.CreateTime.Format(time.RFC3339)),
+		fmt.Sprintf("Create Index|%d", token.CreateIndex),
+		fmt.Sprintf("Modify Index|%d", token.ModifyIndex),
+	}
+	return formatKV(out)
+}
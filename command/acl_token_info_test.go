@@ -0,0 +1,115 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+)
+
+func TestACLTokenInfoCommand_Implements(t *testing.T) {
+	var _ cli.Command = &ACLTokenInfoCommand{}
+}
+
+func TestACLTokenInfoCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &ACLTokenInfoCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error querying ACL token") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestACLTokenInfoCommand_Run(t *testing.T) {
+	srv, _, url := testServer(t, func(c *testutil.TestServerConfig) {
+		c.ACL = &testutil.ACLConfig{Enabled: true}
+	})
+	defer srv.Stop()
+
+	// Bootstrap the management token
+	ui := new(cli.MockUi)
+	bootstrap := &ACLBootstrapCommand{Meta: Meta{Ui: ui}}
+	if code := bootstrap.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	root := kvValue(ui.OutputWriter.String(), "Secret ID")
+	if root == "" {
+		t.Fatalf("missing secret ID: %s", ui.OutputWriter.String())
+	}
+
+	// Write a policy
+	fh, err := ioutil.TempFile("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(fh.Name())
+	if _, err := fh.WriteString(`job { policy = "read" }`); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	fh.Close()
+
+	ui = new(cli.MockUi)
+	apply := &ACLPolicyApplyCommand{Meta: Meta{Ui: ui}}
+	if code := apply.Run([]string{"-address=" + url, "-token=" + root, "readonly", fh.Name()}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+
+	// Create a token granted the policy
+	ui = new(cli.MockUi)
+	create := &ACLTokenCreateCommand{Meta: Meta{Ui: ui}}
+	if code := create.Run([]string{"-address=" + url, "-token=" + root, "-name=reader", "-policy=readonly"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	accessor := kvValue(ui.OutputWriter.String(), "Accessor ID")
+	if accessor == "" {
+		t.Fatalf("missing accessor ID: %s", ui.OutputWriter.String())
+	}
+
+	// Query the token
+	ui = new(cli.MockUi)
+	cmd := &ACLTokenInfoCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + url, "-token=" + root, accessor}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	out := ui.OutputWriter.String()
+	if kvValue(out, "Name") != "reader" || kvValue(out, "Policies") != "readonly" {
+		t.Fatalf("bad: %s", out)
+	}
+
+	// Anonymous requests are denied
+	ui = new(cli.MockUi)
+	cmd = &ACLTokenInfoCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + url, accessor}); code != 1 {
+		t.Fatalf("expected exit 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Permission denied") {
+		t.Fatalf("expected permission denied error, got: %s", out)
+	}
+}
+
+// kvValue returns the value of the key in output formatted by formatKV.
+func kvValue(out, key string) string {
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, " = ", 2)
+		if len(parts) == 2 && strings.TrimSpace(parts[0]) == key {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ACLTokenListCommand struct {
+	Meta
+}
+
+func (c *ACLTokenListCommand) Help() string {
+	helpText := `
+Usage: nomad acl-token-list [options]
+
+  Displays the list of ACL tokens without their secret IDs. This requires
+  a management token.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLTokenListCommand) Synopsis() string {
+	return "List the ACL tokens"
+}
+
+func (c *ACLTokenListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("acl-token-list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check for extra arguments
+	args = flags.Args()
+	if len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the tokens
+	tokens, _, err := client.ACLTokens().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying ACL tokens: %s", err))
+		return 1
+	}
+
+	// Return nothing if no tokens found
+	if len(tokens) == 0 {
+		return 0
+	}
+
+	out := make([]string, len(tokens)+1)
+	out[0] = "Accessor ID|Name|Type|Policies"
+	for i, token := range tokens {
+		out[i+1] = fmt.Sprintf("%s|%s|%s|%s",
+			token.AccessorID,
+			token.Name,
+			token.Type,
+			strings.Join(token.Policies, ","))
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/helper/flag-slice"
+)
+
+type ACLTokenUpdateCommand struct {
+	Meta
+}
+
+func (c *ACLTokenUpdateCommand) Help() string {
+	helpText := `
+Usage: nomad acl-token-update [options] <accessor-id>
+
+  Updates the name, type or policies of an existing ACL token. Its secret
+  ID is unchanged. This requires a management token.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Update Options:
+
+  -name=<name>
+    Sets the name of the token.
+
+  -type=<type>
+    Sets the type of the token, either "client" or "management".
+
+  -policy=<name>
+    Grants the policy to the token, replacing its current policies. Can
+    be specified multiple times.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ACLTokenUpdateCommand) Synopsis() string {
+	return "Update an existing ACL token"
+}
+
+func (c *ACLTokenUpdateCommand) Run(args []string) int {
+	var name, tokenType string
+	var policies []string
+
+	flags := c.Meta.FlagSet("acl-token-update", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&name, "name", "", "")
+	flags.StringVar(&tokenType, "type", "", "")
+	flags.Var((*sliceflag.StringFlag)(&policies), "policy", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the accessor ID
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	accessorID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the token to only change the given fields
+	token, _, err := client.ACLTokens().Info(accessorID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying ACL token: %s", err))
+		return 1
+	}
+	if name != "" {
+		token.Name = name
+	}
+	if tokenType != "" {
+		token.Type = tokenType
+	}
+	if len(policies) != 0 {
+		token.Policies = policies
+	}
+
+	// Update the token
+	token, _, err = client.ACLTokens().Update(token, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error updating ACL token: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(formatACLToken(token))
+	return 0
+}
@@ -0,0 +1,251 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func aclEnabled(c *Config) {
+	c.ACL.Enabled = true
+}
+
+// bootstrapHTTP bootstraps the ACL system and returns the management token.
+func bootstrapHTTP(t *testing.T, s *TestServer) *structs.ACLToken {
+	req, err := http.NewRequest("PUT", "/v1/acl/bootstrap", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	respW := httptest.NewRecorder()
+	obj, err := s.Server.ACLTokenBootstrap(respW, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	assertIndex(t, respW)
+
+	token := obj.(*structs.ACLToken)
+	if !token.IsManagement() || token.SecretID == "" {
+		t.Fatalf("bad: %#v", token)
+	}
+	return token
+}
+
+func TestHTTP_ACLBootstrap(t *testing.T) {
+	httpTest(t, aclEnabled, func(s *TestServer) {
+		bootstrapHTTP(t, s)
+
+		// Bootstrapping again fails
+		req, err := http.NewRequest("PUT", "/v1/acl/bootstrap", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.ACLTokenBootstrap(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected error")
+		}
+	})
+}
+
+func TestHTTP_ACLPolicyCRUD(t *testing.T) {
+	httpTest(t, aclEnabled, func(s *TestServer) {
+		root := bootstrapHTTP(t, s)
+		policy := mock.ACLPolicy()
+
+		// Create the policy
+		req, err := http.NewRequest("PUT", "/v1/acl/policy/"+policy.Name, encodeReq(policy))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.ACLPolicySpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		// Anonymous requests are denied
+		req, err = http.NewRequest("GET", "/v1/acl/policies", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.ACLPoliciesRequest(httptest.NewRecorder(), req)
+		if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+			t.Fatalf("expected permission denied; got %v", err)
+		}
+
+		// List the policies
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW = httptest.NewRecorder()
+		obj, err := s.Server.ACLPoliciesRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if stubs := obj.([]*structs.ACLPolicyListStub); len(stubs) != 1 || stubs[0].Name != policy.Name {
+			t.Fatalf("bad: %#v", stubs)
+		}
+
+		// Get the policy
+		req, err = http.NewRequest("GET", "/v1/acl/policy/"+policy.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		obj, err = s.Server.ACLPolicySpecificRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out := obj.(*structs.ACLPolicy); out.Rules != policy.Rules {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// Delete the policy
+		req, err = http.NewRequest("DELETE", "/v1/acl/policy/"+policy.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.ACLPolicySpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		req, err = http.NewRequest("GET", "/v1/acl/policy/"+policy.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		_, err = s.Server.ACLPolicySpecificRequest(httptest.NewRecorder(), req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 404 {
+			t.Fatalf("expected 404; got %v", err)
+		}
+	})
+}
+
+func TestHTTP_ACLTokenCRUD(t *testing.T) {
+	httpTest(t, aclEnabled, func(s *TestServer) {
+		root := bootstrapHTTP(t, s)
+
+		// Create a client token
+		token := &structs.ACLToken{
+			Name:     "test",
+			Type:     structs.ACLClientToken,
+			Policies: []string{"foo"},
+		}
+		req, err := http.NewRequest("PUT", "/v1/acl/token", encodeReq(token))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW := httptest.NewRecorder()
+		obj, err := s.Server.ACLTokenCreateRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+		created := obj.(*structs.ACLToken)
+		if created.AccessorID == "" || created.SecretID == "" {
+			t.Fatalf("bad: %#v", created)
+		}
+
+		// The token can look itself up
+		req, err = http.NewRequest("GET", "/v1/acl/token/self", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", created.SecretID)
+		obj, err = s.Server.ACLTokenSpecificRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if self := obj.(*structs.ACLToken); self.AccessorID != created.AccessorID {
+			t.Fatalf("bad: %#v", self)
+		}
+
+		// Update the token
+		created.Name = "updated"
+		req, err = http.NewRequest("PUT", "/v1/acl/token/"+created.AccessorID, encodeReq(created))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		obj, err = s.Server.ACLTokenSpecificRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if updated := obj.(*structs.ACLToken); updated.Name != "updated" || updated.SecretID != created.SecretID {
+			t.Fatalf("bad: %#v", updated)
+		}
+
+		// List the tokens
+		req, err = http.NewRequest("GET", "/v1/acl/tokens", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		obj, err = s.Server.ACLTokensRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if stubs := obj.([]*structs.ACLTokenListStub); len(stubs) != 2 {
+			t.Fatalf("bad: %#v", stubs)
+		}
+
+		// Delete the token
+		req, err = http.NewRequest("DELETE", "/v1/acl/token/"+created.AccessorID, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.ACLTokenSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		req, err = http.NewRequest("GET", "/v1/acl/token/"+created.AccessorID, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		_, err = s.Server.ACLTokenSpecificRequest(httptest.NewRecorder(), req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 404 {
+			t.Fatalf("expected 404; got %v", err)
+		}
+	})
+}
+
+func TestHTTP_ACLEnforced(t *testing.T) {
+	httpTest(t, aclEnabled, func(s *TestServer) {
+		root := bootstrapHTTP(t, s)
+
+		// Endpoints served by the agent check the token
+		req, err := http.NewRequest("GET", "/v1/agent/self", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.AgentSelfRequest(httptest.NewRecorder(), req)
+		if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+			t.Fatalf("expected permission denied; got %v", err)
+		}
+
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		if _, err := s.Server.AgentSelfRequest(httptest.NewRecorder(), req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Unknown tokens are rejected with a 403
+		req, err = http.NewRequest("GET", "/v1/jobs", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		req.Header.Set("X-Nomad-Token", structs.GenerateUUID())
+		respW := httptest.NewRecorder()
+		s.Server.wrap(s.Server.JobsRequest)(respW, req)
+		if respW.Code != 403 {
+			t.Fatalf("expected 403; got %d", respW.Code)
+		}
+	})
+}
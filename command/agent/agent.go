@@ -7,10 +7,15 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/audit"
 	"github.com/hashicorp/nomad/client"
+	"github.com/hashicorp/nomad/client/secrets"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
@@ -28,6 +33,14 @@ type Agent struct {
 	server *nomad.Server
 	client *client.Client
 
+	// keyLoader holds the TLS certificate shared by the HTTP API and RPC so
+	// it can be reloaded
+	keyLoader *tlsutil.KeyLoader
+
+	// auditor records the requests served by the agent and its server. It
+	// is nil if the audit log is disabled.
+	auditor *audit.Logger
+
 	shutdown     bool
 	shutdownCh   chan struct{}
 	shutdownLock sync.Mutex
@@ -44,9 +57,13 @@ func NewAgent(config *Config, logOutput io.Writer) (*Agent, error) {
 		config:     config,
 		logger:     log.New(logOutput, "", log.LstdFlags),
 		logOutput:  logOutput,
+		keyLoader:  &tlsutil.KeyLoader{},
 		shutdownCh: make(chan struct{}),
 	}
 
+	if err := a.setupAudit(); err != nil {
+		return nil, err
+	}
 	if err := a.setupServer(); err != nil {
 		return nil, err
 	}
@@ -146,9 +163,81 @@ func (a *Agent) serverConfig() (*nomad.Config, error) {
 		conf.NodeGCThreshold = dur
 	}
 
+	if a.config.ACL != nil {
+		conf.ACLEnabled = a.config.ACL.Enabled
+	}
+
+	if a.auditor != nil {
+		conf.AuditLogger = a.auditor
+	}
+
+	if admission := a.config.Server.Admission; admission != nil {
+		conf.AdmissionConfig = &nomad.AdmissionConfig{
+			RequiredMeta:          admission.RequiredMeta,
+			RecommendedMeta:       admission.RecommendedMeta,
+			ImagePrefixes:         admission.ImagePrefixes,
+			DatacenterConstraints: make(map[string][]*structs.Constraint),
+			WebhookURL:            admission.WebhookURL,
+		}
+		for _, c := range admission.Constraints {
+			if c.Datacenter == "" {
+				return nil, fmt.Errorf("admission constraint on %q must have a datacenter", c.Attribute)
+			}
+			constraint := &structs.Constraint{
+				LTarget: c.Attribute,
+				RTarget: c.Value,
+				Operand: c.Operator,
+			}
+			if err := constraint.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid admission constraint on %q: %v", c.Attribute, err)
+			}
+			conf.AdmissionConfig.DatacenterConstraints[c.Datacenter] = append(
+				conf.AdmissionConfig.DatacenterConstraints[c.Datacenter], constraint)
+		}
+		if timeout := admission.WebhookTimeout; timeout != "" {
+			dur, err := time.ParseDuration(timeout)
+			if err != nil {
+				return nil, err
+			}
+			conf.AdmissionConfig.WebhookTimeout = dur
+		}
+	}
+
+	if tlsConf := a.rpcTLSConfig(); tlsConf != nil {
+		conf.TLSConfig = tlsConf
+		conf.RequireTLS = true
+	}
+
 	return conf, nil
 }
 
+// setupAudit is used to open the audit log if enabled
+func (a *Agent) setupAudit() error {
+	if a.config.Audit == nil || !a.config.Audit.Enabled {
+		return nil
+	}
+
+	path := a.config.Audit.Path
+	if path == "" {
+		if a.config.DataDir == "" {
+			return fmt.Errorf("audit log requires a path or a data_dir")
+		}
+		path = filepath.Join(a.config.DataDir, "audit.log")
+	}
+	auditor, err := audit.NewLogger(&audit.Config{
+		Path:         path,
+		MaxBytes:     int64(a.config.Audit.MaxBytes),
+		MaxFiles:     a.config.Audit.MaxFiles,
+		IncludeReads: a.config.Audit.IncludeReads,
+		Endpoints:    a.config.Audit.Endpoints,
+	})
+	if err != nil {
+		return err
+	}
+	a.auditor = auditor
+	return nil
+}
+
 // setupServer is used to setup the server if enabled
 func (a *Agent) setupServer() error {
 	if !a.config.Server.Enabled {
@@ -161,6 +250,11 @@ func (a *Agent) setupServer() error {
 		return fmt.Errorf("server config setup failed: %s", err)
 	}
 
+	// Setup the keyring for the gossip encryption
+	if err := a.setupKeyring(conf); err != nil {
+		return fmt.Errorf("keyring setup failed: %v", err)
+	}
+
 	// Create the server
 	server, err := nomad.NewServer(conf)
 	if err != nil {
@@ -187,12 +281,16 @@ func (a *Agent) setupClient() error {
 	}
 	conf.LogOutput = a.logOutput
 	conf.DevMode = a.config.DevMode
+	if a.config.ACL != nil {
+		conf.ACLToken = a.config.ACL.Token
+	}
 	if a.config.Region != "" {
 		conf.Region = a.config.Region
 	}
 	if a.config.DataDir != "" {
 		conf.StateDir = filepath.Join(a.config.DataDir, "client")
 		conf.AllocDir = filepath.Join(a.config.DataDir, "alloc")
+		conf.PluginDir = filepath.Join(a.config.DataDir, "plugins")
 	}
 	if a.config.Client.StateDir != "" {
 		conf.StateDir = a.config.Client.StateDir
@@ -200,6 +298,9 @@ func (a *Agent) setupClient() error {
 	if a.config.Client.AllocDir != "" {
 		conf.AllocDir = a.config.Client.AllocDir
 	}
+	if a.config.Client.PluginDir != "" {
+		conf.PluginDir = a.config.Client.PluginDir
+	}
 	conf.Servers = a.config.Client.Servers
 	if a.config.Client.NetworkInterface != "" {
 		conf.NetworkInterface = a.config.Client.NetworkInterface
@@ -208,6 +309,53 @@ func (a *Agent) setupClient() error {
 	if a.config.Client.NetworkSpeed != 0 {
 		conf.NetworkSpeed = a.config.Client.NetworkSpeed
 	}
+	if a.config.Client.GCInterval != "" {
+		dur, err := time.ParseDuration(a.config.Client.GCInterval)
+		if err != nil {
+			return fmt.Errorf("failed to parse gc_interval: %v", err)
+		}
+		conf.GCInterval = dur
+	}
+	if a.config.Client.GCDiskUsageThreshold != 0 {
+		conf.GCDiskUsageThreshold = a.config.Client.GCDiskUsageThreshold
+	}
+	if a.config.Client.GCInodeUsageThreshold != 0 {
+		conf.GCInodeUsageThreshold = a.config.Client.GCInodeUsageThreshold
+	}
+	if a.config.Client.GCMaxAllocs != 0 {
+		conf.GCMaxAllocs = a.config.Client.GCMaxAllocs
+	}
+	conf.HostVolumes = make(map[string]*structs.ClientHostVolumeConfig, len(a.config.Client.HostVolumes))
+	for _, v := range a.config.Client.HostVolumes {
+		if v.Name == "" || v.Path == "" {
+			return fmt.Errorf("host_volume %q must have a name and a path", v.Name)
+		}
+		conf.HostVolumes[v.Name] = &structs.ClientHostVolumeConfig{
+			Name:     v.Name,
+			Path:     v.Path,
+			ReadOnly: v.ReadOnly,
+		}
+	}
+
+	// Setup the secrets provider
+	if secretsConf := a.config.Client.Secrets; secretsConf != nil {
+		provider, err := secrets.NewProvider(&secrets.Config{
+			Provider: secretsConf.Provider,
+			Address:  secretsConf.Address,
+			Token:    secretsConf.Token,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to setup secrets provider: %v", err)
+		}
+		conf.SecretsProvider = provider
+		if secretsConf.RefreshInterval != "" {
+			dur, err := time.ParseDuration(secretsConf.RefreshInterval)
+			if err != nil {
+				return fmt.Errorf("failed to parse secrets refresh_interval: %v", err)
+			}
+			conf.SecretsRefreshInterval = dur
+		}
+	}
 
 	// Setup the node
 	conf.Node = new(structs.Node)
@@ -216,6 +364,11 @@ func (a *Agent) setupClient() error {
 	conf.Node.ID = a.config.Client.NodeID
 	conf.Node.Meta = a.config.Client.Meta
 	conf.Node.NodeClass = a.config.Client.NodeClass
+	conf.Node.HTTPAddr = a.clientHTTPAddr()
+
+	// Setup TLS
+	conf.TLSConfig = a.rpcTLSConfig()
+	conf.HTTPTLSConfig = a.httpTLSConfig()
 
 	// Create the client
 	client, err := client.NewClient(conf)
@@ -226,6 +379,92 @@ func (a *Agent) setupClient() error {
 	return nil
 }
 
+// clientHTTPAddr returns the address the client advertises for its HTTP API.
+// It defaults to the HTTP bind address. If the HTTP API is bound to all
+// interfaces the IP of the RPC advertise address is used instead, and no
+// address is advertised if there is none.
+func (a *Agent) clientHTTPAddr() string {
+	if a.config.AdvertiseAddrs != nil && a.config.AdvertiseAddrs.HTTP != "" {
+		return a.config.AdvertiseAddrs.HTTP
+	}
+
+	addr := a.config.BindAddr
+	if a.config.Addresses != nil && a.config.Addresses.HTTP != "" {
+		addr = a.config.Addresses.HTTP
+	}
+	if ip := net.ParseIP(addr); ip != nil && ip.IsUnspecified() {
+		addr = ""
+		if a.config.AdvertiseAddrs != nil && a.config.AdvertiseAddrs.RPC != "" {
+			if host, _, err := net.SplitHostPort(a.config.AdvertiseAddrs.RPC); err == nil {
+				addr = host
+			}
+		}
+		if addr == "" {
+			a.logger.Printf("[WARN] agent: HTTP API bound to %s is not advertisable, set advertise.http for other clients to reach it", ip)
+			return ""
+		}
+	}
+	port := 0
+	if a.config.Ports != nil {
+		port = a.config.Ports.HTTP
+	}
+	return net.JoinHostPort(addr, strconv.Itoa(port))
+}
+
+// rpcTLSConfig returns the configuration for mutual TLS of the RPCs, or nil
+// if TLS is not enabled for RPC.
+func (a *Agent) rpcTLSConfig() *tlsutil.Config {
+	t := a.config.TLSConfig
+	if t == nil || !t.EnableRPC {
+		return nil
+	}
+	return &tlsutil.Config{
+		VerifyIncoming:       true,
+		VerifyOutgoing:       true,
+		VerifyServerHostname: t.VerifyServerHostname,
+		CAFile:               t.CAFile,
+		CertFile:             t.CertFile,
+		KeyFile:              t.KeyFile,
+		KeyLoader:            a.keyLoader,
+	}
+}
+
+// httpTLSConfig returns the configuration used to serve the HTTP API over
+// HTTPS and to reach the HTTP API of other agents, or nil if TLS is not
+// enabled for HTTP.
+func (a *Agent) httpTLSConfig() *tlsutil.Config {
+	t := a.config.TLSConfig
+	if t == nil || !t.EnableHTTP {
+		return nil
+	}
+	return &tlsutil.Config{
+		VerifyIncoming:       t.VerifyHTTPSClient,
+		VerifyOutgoing:       true,
+		VerifyServerHostname: true,
+		CAFile:               t.CAFile,
+		CertFile:             t.CertFile,
+		KeyFile:              t.KeyFile,
+		KeyLoader:            a.keyLoader,
+	}
+}
+
+// ReloadTLS loads the certificate and key of the given TLS configuration.
+// Connections established after the reload use the new certificate. Enabling
+// or disabling TLS and changing the CA require a restart of the agent.
+func (a *Agent) ReloadTLS(conf *TLSConfig) error {
+	if old := a.config.TLSConfig; old == nil || (!old.EnableHTTP && !old.EnableRPC) {
+		return nil
+	}
+	if conf == nil || conf.CertFile == "" || conf.KeyFile == "" {
+		return fmt.Errorf("TLS is enabled and no cert/key pair provided")
+	}
+	if _, err := a.keyLoader.LoadKeyPair(conf.CertFile, conf.KeyFile); err != nil {
+		return err
+	}
+	a.logger.Printf("[INFO] agent: reloaded TLS certificate from %s", conf.CertFile)
+	return nil
+}
+
 // Leave is used gracefully exit. Clients will inform servers
 // of their departure so that allocations can be rescheduled.
 func (a *Agent) Leave() error {
@@ -262,6 +501,9 @@ func (a *Agent) Shutdown() error {
 			a.logger.Printf("[ERR] agent: server shutdown failed: %v", err)
 		}
 	}
+	if err := a.auditor.Close(); err != nil {
+		a.logger.Printf("[ERR] agent: closing audit log failed: %v", err)
+	}
 
 	a.logger.Println("[INFO] agent: shutdown complete")
 	a.shutdown = true
@@ -277,6 +519,56 @@ func (a *Agent) RPC(method string, args interface{}, reply interface{}) error {
 	return a.client.RPC(method, args, reply)
 }
 
+// resolveToken returns the ACL of the token with the given secret ID. A nil
+// ACL is returned if ACLs are disabled, in which case everything is allowed.
+// Agents without a server resolve the token through the servers.
+func (a *Agent) resolveToken(secretID string) (*acl.ACL, error) {
+	if a.config.ACL == nil || !a.config.ACL.Enabled {
+		return nil, nil
+	}
+	if a.server != nil {
+		return a.server.ResolveToken(secretID)
+	}
+
+	args := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     a.config.Region,
+			AllowStale: true,
+			AuthToken:  secretID,
+		},
+	}
+	var out structs.ResolveACLTokenResponse
+	if err := a.client.RPC("ACL.ResolveToken", &args, &out); err != nil {
+		return nil, err
+	}
+	return nomad.CompileACL(out.Token, out.Policies)
+}
+
+// tokenAccessor returns the accessor ID of the token with the given secret
+// ID, which identifies requests in the audit log. It is empty if ACLs are
+// disabled or the token can't be resolved.
+func (a *Agent) tokenAccessor(secretID string) string {
+	if secretID == "" || a.config.ACL == nil || !a.config.ACL.Enabled {
+		return ""
+	}
+	if a.server != nil {
+		return a.server.TokenAccessor(secretID)
+	}
+
+	args := structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:     a.config.Region,
+			AllowStale: true,
+			AuthToken:  secretID,
+		},
+	}
+	var out structs.ResolveACLTokenResponse
+	if err := a.client.RPC("ACL.ResolveToken", &args, &out); err != nil || out.Token == nil {
+		return ""
+	}
+	return out.Token.AccessorID
+}
+
 // Client returns the configured client or nil
 func (a *Agent) Client() *client.Client {
 	return a.client
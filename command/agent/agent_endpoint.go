@@ -3,7 +3,9 @@ package agent
 import (
 	"net"
 	"net/http"
+	"strings"
 
+	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/serf/serf"
 )
 
@@ -42,6 +44,12 @@ func (s *HTTPServer) AgentSelfRequest(resp http.ResponseWriter, req *http.Reques
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	// Get the member as a server
 	var member serf.Member
 	srv := s.agent.Server()
@@ -61,6 +69,13 @@ func (s *HTTPServer) AgentJoinRequest(resp http.ResponseWriter, req *http.Reques
 	if req.Method != "PUT" && req.Method != "POST" {
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	srv := s.agent.Server()
 	if srv == nil {
 		return nil, CodedError(501, ErrInvalidMethod)
@@ -86,6 +101,13 @@ func (s *HTTPServer) AgentMembersRequest(resp http.ResponseWriter, req *http.Req
 	if req.Method != "GET" {
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	srv := s.agent.Server()
 	if srv == nil {
 		return nil, CodedError(501, ErrInvalidMethod)
@@ -103,6 +125,13 @@ func (s *HTTPServer) AgentForceLeaveRequest(resp http.ResponseWriter, req *http.
 	if req.Method != "PUT" && req.Method != "POST" {
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	srv := s.agent.Server()
 	if srv == nil {
 		return nil, CodedError(501, ErrInvalidMethod)
@@ -131,6 +160,12 @@ func (s *HTTPServer) AgentServersRequest(resp http.ResponseWriter, req *http.Req
 }
 
 func (s *HTTPServer) listServers(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	client := s.agent.Client()
 	if client == nil {
 		return nil, CodedError(501, ErrInvalidMethod)
@@ -141,6 +176,12 @@ func (s *HTTPServer) listServers(resp http.ResponseWriter, req *http.Request) (i
 }
 
 func (s *HTTPServer) updateServers(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	client := s.agent.Client()
 	if client == nil {
 		return nil, CodedError(501, ErrInvalidMethod)
@@ -157,6 +198,63 @@ func (s *HTTPServer) updateServers(resp http.ResponseWriter, req *http.Request)
 	return nil, nil
 }
 
+func (s *HTTPServer) KeyringOperationRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	// The keys are secrets so listing them requires write access as well
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowAgentWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	srv := s.agent.Server()
+	if srv == nil {
+		return nil, CodedError(501, ErrInvalidMethod)
+	}
+	km := srv.KeyManager()
+
+	var sresp *serf.KeyResponse
+	var err error
+	op := strings.TrimPrefix(req.URL.Path, "/v1/agent/keyring/")
+	switch op {
+	case "list":
+		if req.Method != "GET" {
+			return nil, CodedError(405, ErrInvalidMethod)
+		}
+		sresp, err = km.ListKeys()
+	case "install", "use", "remove":
+		if req.Method != "PUT" && req.Method != "POST" {
+			return nil, CodedError(405, ErrInvalidMethod)
+		}
+		var args keyringRequest
+		if err := decodeBody(req, &args); err != nil {
+			return nil, CodedError(400, err.Error())
+		}
+		if args.Key == "" {
+			return nil, CodedError(400, "missing key")
+		}
+		switch op {
+		case "install":
+			sresp, err = km.InstallKey(args.Key)
+		case "use":
+			sresp, err = km.UseKey(args.Key)
+		case "remove":
+			sresp, err = km.RemoveKey(args.Key)
+		}
+	default:
+		return nil, CodedError(404, "resource not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := keyringResponse{
+		Messages: sresp.Messages,
+		Keys:     sresp.Keys,
+		NumNodes: sresp.NumNodes,
+	}
+	return out, nil
+}
+
 type agentSelf struct {
 	Config *Config                      `json:"config"`
 	Member Member                       `json:"member,omitempty"`
@@ -167,3 +265,13 @@ type joinResult struct {
 	NumJoined int    `json:"num_joined"`
 	Error     string `json:"error"`
 }
+
+type keyringRequest struct {
+	Key string
+}
+
+type keyringResponse struct {
+	Messages map[string]string
+	Keys     map[string]int
+	NumNodes int
+}
@@ -2,6 +2,7 @@ package agent
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -154,3 +155,50 @@ func TestHTTP_AgentSetServers(t *testing.T) {
 		}
 	})
 }
+
+func TestHTTP_AgentKeyring(t *testing.T) {
+	key1 := "tbLJg26ZJyJ9pK3qhc9jig=="
+	key2 := "4leC33rgtXKIVUr9Nr0snQ=="
+	httpTest(t, func(c *Config) {
+		c.Server.EncryptKey = key1
+	}, func(s *TestServer) {
+		// keyringOp runs a keyring operation and returns the keys
+		keyringOp := func(method, op, key string) map[string]int {
+			var body io.Reader
+			if key != "" {
+				body = encodeReq(keyringRequest{Key: key})
+			}
+			req, err := http.NewRequest(method, "/v1/agent/keyring/"+op, body)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			obj, err := s.Server.KeyringOperationRequest(httptest.NewRecorder(), req)
+			if err != nil {
+				t.Fatalf("%s err: %v", op, err)
+			}
+			return obj.(keyringResponse).Keys
+		}
+
+		if keys := keyringOp("GET", "list", ""); len(keys) != 1 || keys[key1] != 1 {
+			t.Fatalf("bad: %#v", keys)
+		}
+
+		// Rotate to the second key
+		keyringOp("PUT", "install", key2)
+		keyringOp("PUT", "use", key2)
+		keyringOp("PUT", "remove", key1)
+		if keys := keyringOp("GET", "list", ""); len(keys) != 1 || keys[key2] != 1 {
+			t.Fatalf("bad: %#v", keys)
+		}
+
+		// Unknown operations are not found
+		req, err := http.NewRequest("GET", "/v1/agent/keyring/nope", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		_, err = s.Server.KeyringOperationRequest(httptest.NewRecorder(), req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 404 {
+			t.Fatalf("expected 404; got %v", err)
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package agent
 
 import (
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"log"
 	"os"
 	"strings"
 	"sync/atomic"
@@ -197,4 +199,156 @@ func TestAgent_ServerConfig(t *testing.T) {
 	if out.BootstrapExpect != 3 {
 		t.Fatalf("should have bootstrap-expect = 3")
 	}
+
+	// Converts the admission configuration
+	conf.Server.Admission = &AdmissionConfig{
+		RequiredMeta: []string{"owner"},
+		Constraints: []*AdmissionConstraint{
+			&AdmissionConstraint{
+				Datacenter: "dc1",
+				Attribute:  "$attr.kernel.name",
+				Operator:   "=",
+				Value:      "linux",
+			},
+		},
+		WebhookURL:     "http://127.0.0.1:8080/admit",
+		WebhookTimeout: "5s",
+	}
+	out, err = a.serverConfig()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	admission := out.AdmissionConfig
+	if admission == nil || len(admission.RequiredMeta) != 1 ||
+		admission.WebhookURL != "http://127.0.0.1:8080/admit" ||
+		admission.WebhookTimeout != 5*time.Second {
+		t.Fatalf("bad: %#v", admission)
+	}
+	if c := admission.DatacenterConstraints["dc1"]; len(c) != 1 ||
+		c[0].LTarget != "$attr.kernel.name" || c[0].RTarget != "linux" || c[0].Operand != "=" {
+		t.Fatalf("bad: %#v", admission.DatacenterConstraints)
+	}
+
+	conf.Server.Admission.Constraints[0].Datacenter = ""
+	if _, err = a.serverConfig(); err == nil || !strings.Contains(err.Error(), "must have a datacenter") {
+		t.Fatalf("expected datacenter error, got: %v", err)
+	}
+}
+
+const (
+	testCA         = "../../helper/tlsutil/test-resources/ca.pem"
+	testServerCert = "../../helper/tlsutil/test-resources/server.pem"
+	testServerKey  = "../../helper/tlsutil/test-resources/server-key.pem"
+	testOtherCert  = "../../helper/tlsutil/test-resources/server-other.pem"
+	testOtherKey   = "../../helper/tlsutil/test-resources/server-other-key.pem"
+	testClientCert = "../../helper/tlsutil/test-resources/client.pem"
+	testClientKey  = "../../helper/tlsutil/test-resources/client-key.pem"
+)
+
+func tlsEnabled(c *Config) {
+	c.TLSConfig = &TLSConfig{
+		EnableHTTP:           true,
+		EnableRPC:            true,
+		VerifyServerHostname: true,
+		VerifyHTTPSClient:    true,
+		CAFile:               testCA,
+		CertFile:             testServerCert,
+		KeyFile:              testServerKey,
+	}
+}
+
+func TestAgent_TLSConfig(t *testing.T) {
+	conf := DefaultConfig()
+	a := &Agent{config: conf}
+
+	// TLS is disabled by default
+	if a.rpcTLSConfig() != nil || a.httpTLSConfig() != nil {
+		t.Fatalf("expected TLS to be disabled")
+	}
+	out, err := a.serverConfig()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if out.TLSConfig != nil || out.RequireTLS {
+		t.Fatalf("expected TLS to be disabled")
+	}
+
+	// RPCs require TLS once enabled
+	tlsEnabled(conf)
+	out, err = a.serverConfig()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if tlsConf := out.TLSConfig; tlsConf == nil || !tlsConf.VerifyIncoming ||
+		!tlsConf.VerifyOutgoing || !tlsConf.VerifyServerHostname || !out.RequireTLS {
+		t.Fatalf("bad: %#v", out.TLSConfig)
+	}
+	if tlsConf := a.httpTLSConfig(); tlsConf == nil || !tlsConf.VerifyIncoming {
+		t.Fatalf("bad: %#v", tlsConf)
+	}
+}
+
+func TestAgent_ClientHTTPAddr(t *testing.T) {
+	conf := DefaultConfig()
+	conf.Ports.HTTP = 4646
+	a := &Agent{config: conf, logger: log.New(ioutil.Discard, "", 0)}
+
+	// The bind address is advertised by default
+	conf.BindAddr = "10.0.0.1"
+	if addr := a.clientHTTPAddr(); addr != "10.0.0.1:4646" {
+		t.Fatalf("bad: %#v", addr)
+	}
+
+	// Unspecified bind addresses aren't advertised
+	conf.BindAddr = "0.0.0.0"
+	if addr := a.clientHTTPAddr(); addr != "" {
+		t.Fatalf("bad: %#v", addr)
+	}
+
+	// The IP of the RPC advertise address is used instead
+	conf.AdvertiseAddrs.RPC = "10.0.0.2:4647"
+	if addr := a.clientHTTPAddr(); addr != "10.0.0.2:4646" {
+		t.Fatalf("bad: %#v", addr)
+	}
+
+	// The HTTP advertise address takes precedence
+	conf.AdvertiseAddrs.HTTP = "10.0.0.3:8080"
+	if addr := a.clientHTTPAddr(); addr != "10.0.0.3:8080" {
+		t.Fatalf("bad: %#v", addr)
+	}
+}
+
+func TestAgent_ReloadTLS(t *testing.T) {
+	dir, agent := makeAgent(t, tlsEnabled)
+	defer os.RemoveAll(dir)
+	defer agent.Shutdown()
+
+	var out struct{}
+	if err := agent.RPC("Status.Ping", struct{}{}, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// A missing key pair is rejected
+	if err := agent.ReloadTLS(&TLSConfig{}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The new certificate is served
+	reloaded := *agent.config.TLSConfig
+	reloaded.CertFile = testOtherCert
+	reloaded.KeyFile = testOtherKey
+	if err := agent.ReloadTLS(&reloaded); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cert, err := agent.keyLoader.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if parsed.Subject.CommonName != "server.other.nomad" {
+		t.Fatalf("bad certificate: %s", parsed.Subject.CommonName)
+	}
 }
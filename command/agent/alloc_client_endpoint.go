@@ -0,0 +1,301 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	cstructs "github.com/hashicorp/nomad/client/driver/structs"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// ErrClientNotRunning is returned when a client only endpoint is hit on
+	// an agent that isn't running a client
+	ErrClientNotRunning = "Nomad client is not running"
+)
+
+// execFrame is a single message exchanged on a hijacked exec connection. The
+// caller sends Stdin, StdinClosed and TtySize frames and the agent replies
+// with Stdout and Stderr frames followed by a single Exited frame.
+type execFrame struct {
+	Stdin       []byte                 `json:",omitempty"`
+	StdinClosed bool                   `json:",omitempty"`
+	TtySize     *cstructs.TerminalSize `json:",omitempty"`
+	Stdout      []byte                 `json:",omitempty"`
+	Stderr      []byte                 `json:",omitempty"`
+	Exited      bool                   `json:",omitempty"`
+	ExitCode    int                    `json:",omitempty"`
+	Error       string                 `json:",omitempty"`
+}
+
+func (s *HTTPServer) ClientAllocRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.agent.client == nil {
+		return nil, CodedError(501, ErrClientNotRunning)
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v1/client/allocation/")
+	switch {
+	case strings.HasSuffix(path, "/exec"):
+		allocID := strings.TrimSuffix(path, "/exec")
+		return s.allocExec(resp, req, allocID)
+	case strings.HasSuffix(path, "/stats"):
+		allocID := strings.TrimSuffix(path, "/stats")
+		return s.allocStats(resp, req, allocID)
+	case strings.HasSuffix(path, "/snapshot"):
+		allocID := strings.TrimSuffix(path, "/snapshot")
+		return s.allocSnapshot(resp, req, allocID)
+	default:
+		return nil, CodedError(404, "invalid client allocation endpoint")
+	}
+}
+
+// allocStats returns the latest resource usage of the allocation, optionally
+// limited to a single task.
+func (s *HTTPServer) allocStats(resp http.ResponseWriter, req *http.Request,
+	allocID string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	task := req.URL.Query().Get("task")
+	stats, err := s.agent.client.AllocStats(allocID, task)
+	if err != nil {
+		return nil, CodedError(404, err.Error())
+	}
+	return stats, nil
+}
+
+// allocSnapshot streams a tar archive of the ephemeral disk of the allocation.
+// It is used by the client running the allocation replacing it to migrate its
+// data, which authenticates with its ACL token.
+func (s *HTTPServer) allocSnapshot(resp http.ResponseWriter, req *http.Request,
+	allocID string) (interface{}, error) {
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	// Failures before the archive is written, such as an unknown allocation,
+	// are returned as errors. Once the archive is streamed the status can no
+	// longer be changed, so the connection is aborted instead for the
+	// requesting client to notice the archive is truncated.
+	resp.Header().Set("Content-Type", "application/x-tar")
+	w := &trackingWriter{w: resp}
+	if err := s.agent.client.SnapshotAlloc(allocID, w); err != nil {
+		if !w.written {
+			return nil, CodedError(404, err.Error())
+		}
+		s.logger.Printf("[ERR] http: snapshot of alloc %q failed: %v", allocID, err)
+		abortResponse(resp)
+	}
+	return nil, nil
+}
+
+// trackingWriter is an io.Writer that records whether it was written to.
+type trackingWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	t.written = true
+	return t.w.Write(p)
+}
+
+// abortResponse closes the connection of a response that can't be
+// completed, so that the client doesn't mistake the partial body for a
+// complete one.
+func abortResponse(resp http.ResponseWriter) {
+	hj, ok := resp.(http.Hijacker)
+	if !ok {
+		return
+	}
+	if conn, _, err := hj.Hijack(); err == nil {
+		conn.Close()
+	}
+}
+
+// ClientStatsRequest returns the latest resource usage of the client's host.
+func (s *HTTPServer) ClientStatsRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.agent.client == nil {
+		return nil, CodedError(501, ErrClientNotRunning)
+	}
+	if req.Method != "GET" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowNodeRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	stats := s.agent.client.LatestHostStats()
+	if stats == nil {
+		return nil, CodedError(404, "host stats not yet available")
+	}
+	return stats, nil
+}
+
+// ClientGCRequest garbage collects all the terminal allocations on the
+// client, destroying their allocation directories.
+func (s *HTTPServer) ClientGCRequest(resp http.ResponseWriter, req *http.Request) (interface{}, error) {
+	if s.agent.client == nil {
+		return nil, CodedError(501, ErrClientNotRunning)
+	}
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowNodeWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	s.agent.client.CollectAllAllocs()
+	return nil, nil
+}
+
+// allocExec runs a command inside a task of the allocation. The connection is
+// hijacked so that the command's streams can be relayed as JSON frames for as
+// long as the command runs.
+func (s *HTTPServer) allocExec(resp http.ResponseWriter, req *http.Request,
+	allocID string) (interface{}, error) {
+	if req.Method != "PUT" && req.Method != "POST" {
+		return nil, CodedError(405, ErrInvalidMethod)
+	}
+
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowJobWrite() {
+		return nil, structs.ErrPermissionDenied
+	}
+
+	query := req.URL.Query()
+	task := query.Get("task")
+	if task == "" {
+		return nil, CodedError(400, "missing task name")
+	}
+	cmd := query["cmd"]
+	if len(cmd) == 0 {
+		return nil, CodedError(400, "missing command")
+	}
+	tty := query.Get("tty") == "true"
+
+	hj, ok := resp.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("connection does not support hijacking")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n")); err != nil {
+		return nil, nil
+	}
+
+	enc := &execEncoder{enc: json.NewEncoder(conn)}
+	stdinR, stdinW := io.Pipe()
+	resizeCh := make(chan cstructs.TerminalSize, 1)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	// Relay the incoming frames to the command. The reader is the only
+	// sender on the resize channel so it closes it once it stops, which
+	// happens when the connection is closed after the command exits.
+	go func() {
+		defer close(resizeCh)
+		defer stdinW.Close()
+		dec := json.NewDecoder(buf)
+		for {
+			var frame execFrame
+			if err := dec.Decode(&frame); err != nil {
+				return
+			}
+			if len(frame.Stdin) != 0 {
+				if _, err := stdinW.Write(frame.Stdin); err != nil {
+					return
+				}
+			}
+			if frame.TtySize != nil {
+				select {
+				case resizeCh <- *frame.TtySize:
+				case <-doneCh:
+					return
+				}
+			}
+			if frame.StdinClosed {
+				stdinW.Close()
+			}
+		}
+	}()
+
+	execReq := &cstructs.ExecRequest{
+		Cmd:      cmd,
+		Tty:      tty,
+		Stdin:    stdinR,
+		Stdout:   &execFrameWriter{enc: enc},
+		Stderr:   &execFrameWriter{enc: enc, stderr: true},
+		ResizeCh: resizeCh,
+	}
+	code, err := s.agent.client.Exec(allocID, task, execReq)
+	stdinR.Close()
+
+	exited := &execFrame{Exited: true, ExitCode: code}
+	if err != nil {
+		s.logger.Printf("[ERR] http: exec in alloc %q task %q failed: %v", allocID, task, err)
+		exited.Error = err.Error()
+	}
+	enc.Encode(exited)
+	return nil, nil
+}
+
+// execEncoder serializes frame writes from the stdout and stderr writers.
+type execEncoder struct {
+	enc *json.Encoder
+	l   sync.Mutex
+}
+
+func (e *execEncoder) Encode(frame *execFrame) error {
+	e.l.Lock()
+	defer e.l.Unlock()
+	return e.enc.Encode(frame)
+}
+
+// execFrameWriter is an io.Writer that wraps each write in an execFrame.
+type execFrameWriter struct {
+	enc    *execEncoder
+	stderr bool
+}
+
+func (w *execFrameWriter) Write(p []byte) (int, error) {
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	frame := &execFrame{Stdout: data}
+	if w.stderr {
+		frame = &execFrame{Stderr: data}
+	}
+	if err := w.enc.Encode(frame); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
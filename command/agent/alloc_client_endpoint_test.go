@@ -0,0 +1,269 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/audit"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestHTTP_ClientAllocExec_NoClient(t *testing.T) {
+	httpTest(t, func(c *Config) { c.Client.Enabled = false }, func(s *TestServer) {
+		req, err := http.NewRequest("PUT", "/v1/client/allocation/foo/exec?task=web&cmd=ls", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		_, err = s.Server.ClientAllocRequest(respW, req)
+		if err == nil {
+			t.Fatalf("expected error")
+		}
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 501 {
+			t.Fatalf("bad: %v", err)
+		}
+	})
+}
+
+func TestHTTP_ClientAllocExec_BadRequest(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		cases := []struct {
+			method string
+			url    string
+			code   int
+		}{
+			{"GET", "/v1/client/allocation/foo/exec?task=web&cmd=ls", 405},
+			{"PUT", "/v1/client/allocation/foo/exec?cmd=ls", 400},
+			{"PUT", "/v1/client/allocation/foo/exec?task=web", 400},
+			{"PUT", "/v1/client/allocation/foo/bar", 404},
+		}
+
+		for _, c := range cases {
+			req, err := http.NewRequest(c.method, c.url, nil)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			respW := httptest.NewRecorder()
+
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			if err == nil {
+				t.Fatalf("%s %s: expected error", c.method, c.url)
+			}
+			if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != c.code {
+				t.Fatalf("%s %s: bad: %v", c.method, c.url, err)
+			}
+		}
+	})
+}
+
+func TestHTTP_ClientAllocStats_BadRequest(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		cases := []struct {
+			method string
+			url    string
+			code   int
+		}{
+			{"PUT", "/v1/client/allocation/foo/stats", 405},
+			{"GET", "/v1/client/allocation/foo/stats", 404},
+			{"GET", "/v1/client/allocation/foo/stats?task=web", 404},
+		}
+
+		for _, c := range cases {
+			req, err := http.NewRequest(c.method, c.url, nil)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			respW := httptest.NewRecorder()
+
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			if err == nil {
+				t.Fatalf("%s %s: expected error", c.method, c.url)
+			}
+			if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != c.code {
+				t.Fatalf("%s %s: bad: %v", c.method, c.url, err)
+			}
+		}
+	})
+}
+
+func TestHTTP_ClientStats(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		req, err := http.NewRequest("PUT", "/v1/client/stats", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		_, err = s.Server.ClientStatsRequest(respW, req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 405 {
+			t.Fatalf("bad: %v", err)
+		}
+	})
+}
+
+func TestHTTP_ClientAllocSnapshot_BadRequest(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		cases := []struct {
+			method string
+			url    string
+			code   int
+		}{
+			{"PUT", "/v1/client/allocation/foo/snapshot", 405},
+			{"GET", "/v1/client/allocation/foo/snapshot", 404},
+		}
+
+		for _, c := range cases {
+			req, err := http.NewRequest(c.method, c.url, nil)
+			if err != nil {
+				t.Fatalf("err: %v", err)
+			}
+			respW := httptest.NewRecorder()
+
+			_, err = s.Server.ClientAllocRequest(respW, req)
+			if err == nil {
+				t.Fatalf("%s %s: expected error", c.method, c.url)
+			}
+			if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != c.code {
+				t.Fatalf("%s %s: bad: %v", c.method, c.url, err)
+			}
+		}
+	})
+}
+
+func TestHTTP_ClientAllocSnapshot_ACL(t *testing.T) {
+	httpTest(t, aclEnabled, func(s *TestServer) {
+		root := bootstrapHTTP(t, s)
+
+		// Anonymous requests can't download the data of allocations
+		req, err := http.NewRequest("GET", "/v1/client/allocation/foo/snapshot", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		s.Server.wrap(s.Server.ClientAllocRequest)(respW, req)
+		if respW.Code != 403 {
+			t.Fatalf("expected 403; got %d", respW.Code)
+		}
+
+		// The token is checked before the allocation is looked up
+		req.Header.Set("X-Nomad-Token", root.SecretID)
+		respW = httptest.NewRecorder()
+		s.Server.wrap(s.Server.ClientAllocRequest)(respW, req)
+		if respW.Code != 404 {
+			t.Fatalf("expected 404; got %d", respW.Code)
+		}
+	})
+}
+
+func TestHTTP_ClientGC(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		req, err := http.NewRequest("GET", "/v1/client/gc", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		_, err = s.Server.ClientGCRequest(respW, req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 405 {
+			t.Fatalf("bad: %v", err)
+		}
+
+		req, err = http.NewRequest("PUT", "/v1/client/gc", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+
+		if _, err := s.Server.ClientGCRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+	})
+}
+
+func TestHTTP_ClientStats_NoClient(t *testing.T) {
+	httpTest(t, func(c *Config) { c.Client.Enabled = false }, func(s *TestServer) {
+		req, err := http.NewRequest("GET", "/v1/client/stats", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+
+		_, err = s.Server.ClientStatsRequest(respW, req)
+		if coded, ok := err.(HTTPCodedError); !ok || coded.Code() != 501 {
+			t.Fatalf("bad: %v", err)
+		}
+	})
+}
+
+func TestHTTP_ClientAllocExec_Audited(t *testing.T) {
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	auditPath := filepath.Join(dir, "audit.log")
+
+	httpTest(t, func(c *Config) {
+		c.Audit = &AuditConfig{Enabled: true, Path: auditPath}
+	}, func(s *TestServer) {
+		// Exec over a real connection so that the audited response writer
+		// has to be hijacked
+		conn, err := net.Dial("tcp", s.Server.addr)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		defer conn.Close()
+
+		path := fmt.Sprintf("/v1/client/allocation/%s/exec", structs.GenerateUUID())
+		fmt.Fprintf(conn, "PUT %s?task=web&cmd=ls HTTP/1.1\r\nHost: nomad\r\nContent-Length: 0\r\n\r\n", path)
+		resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if resp.StatusCode != 200 {
+			t.Fatalf("bad: %#v", resp.Status)
+		}
+
+		// The allocation is unknown so the command fails to start
+		var frame execFrame
+		if err := json.NewDecoder(resp.Body).Decode(&frame); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if !frame.Exited || frame.Error == "" {
+			t.Fatalf("bad: %#v", frame)
+		}
+
+		// The request is audited once the handler returns, along with the
+		// RPCs of the agent
+		testutil.WaitForResult(func() (bool, error) {
+			f, err := os.Open(auditPath)
+			if err != nil {
+				return false, err
+			}
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var entry audit.Entry
+				if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+					return false, err
+				}
+				if entry.Type != audit.TypeHTTP || entry.Path != path {
+					continue
+				}
+				if entry.Status != 200 || entry.PayloadHash != "" {
+					return false, fmt.Errorf("bad: %#v", entry)
+				}
+				return true, nil
+			}
+			return false, fmt.Errorf("exec request not audited")
+		}, func(err error) {
+			t.Fatalf("err: %v", err)
+		})
+	})
+}
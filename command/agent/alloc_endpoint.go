@@ -35,6 +35,12 @@ func (s *HTTPServer) AllocSpecificRequest(resp http.ResponseWriter, req *http.Re
 		return nil, CodedError(405, ErrInvalidMethod)
 	}
 
+	if aclObj, err := s.resolveToken(req); err != nil {
+		return nil, err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return nil, structs.ErrPermissionDenied
+	}
+
 	args := structs.AllocSpecificRequest{
 		AllocID: allocID,
 	}
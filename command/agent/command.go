@@ -78,6 +78,7 @@ func (c *Command) readConfig() *Config {
 	flags.Var((*sliceflag.StringFlag)(&cmdConfig.Server.RetryJoin), "retry-join", "")
 	flags.IntVar(&cmdConfig.Server.RetryMaxAttempts, "retry-max", 0, "")
 	flags.StringVar(&cmdConfig.Server.RetryInterval, "retry-interval", "", "")
+	flags.StringVar(&cmdConfig.Server.EncryptKey, "encrypt", "", "")
 
 	// Client-only options
 	flags.StringVar(&cmdConfig.Client.StateDir, "state-dir", "", "")
@@ -520,6 +521,11 @@ func (c *Command) handleReload(config *Config) *Config {
 		// Keep the current log level
 		newConf.LogLevel = config.LogLevel
 	}
+
+	// Reload the TLS certificate
+	if err := c.agent.ReloadTLS(newConf.TLSConfig); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to reload TLS certificate: %v", err))
+	}
 	return newConf
 }
 
@@ -736,6 +742,10 @@ Server Options:
   -rejoin
     Ignore a previous leave and attempts to rejoin the cluster.
 
+  -encrypt=<key>
+    Provides the gossip encryption key. The key seeds the keyring
+    persisted in the data dir and is ignored once it exists.
+
 Client Options:
 
   -client
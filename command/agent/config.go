@@ -83,6 +83,15 @@ type Config struct {
 	// AtlasConfig is used to configure Atlas
 	Atlas *AtlasConfig `hcl:"atlas"`
 
+	// ACL is used to configure the ACL system
+	ACL *ACLConfig `hcl:"acl"`
+
+	// TLSConfig is used to configure TLS for the HTTP API and RPC
+	TLSConfig *TLSConfig `hcl:"tls"`
+
+	// Audit is used to configure the audit log
+	Audit *AuditConfig `hcl:"audit"`
+
 	// NomadConfig is used to override the default config.
 	// This is largly used for testing purposes.
 	NomadConfig *nomad.Config `hcl:"-" json:"-"`
@@ -121,6 +130,71 @@ type AtlasConfig struct {
 	Endpoint string `hcl:"endpoint"`
 }
 
+// ACLConfig is used to configure the ACL system
+type ACLConfig struct {
+	// Enabled controls if requests must carry a token granting them access.
+	// It must be set on servers and clients alike.
+	Enabled bool `hcl:"enabled"`
+
+	// Token is the secret ID of the ACL token clients authenticate their
+	// requests to the servers with. It needs node write and job read
+	// permissions.
+	Token string `hcl:"token"`
+}
+
+// TLSConfig is used to configure TLS for the HTTP API and RPC. The same
+// certificate is used for both and must be valid for server.<region>.nomad
+// on servers when verify_server_hostname is set.
+type TLSConfig struct {
+	// EnableHTTP enables TLS for the HTTP API
+	EnableHTTP bool `hcl:"http"`
+
+	// EnableRPC enables mutual TLS for the RPCs between clients and servers
+	// and among servers. Plaintext RPC connections are rejected.
+	EnableRPC bool `hcl:"rpc"`
+
+	// VerifyServerHostname requires the certificates of servers to be valid
+	// for server.<region>.nomad, so a client certificate cannot be used to
+	// impersonate a server.
+	VerifyServerHostname bool `hcl:"verify_server_hostname"`
+
+	// VerifyHTTPSClient requires HTTP API clients to present a certificate
+	// signed by the CA.
+	VerifyHTTPSClient bool `hcl:"verify_https_client"`
+
+	// CAFile is the path to the certificate authority used to verify peers
+	CAFile string `hcl:"ca_file"`
+
+	// CertFile and KeyFile are the paths to the certificate and key of the
+	// agent. They are reloaded on SIGHUP.
+	CertFile string `hcl:"cert_file"`
+	KeyFile  string `hcl:"key_file"`
+}
+
+// AuditConfig is used to configure the audit log of the write requests
+// served by the agent.
+type AuditConfig struct {
+	// Enabled turns on the audit log
+	Enabled bool `hcl:"enabled"`
+
+	// Path is the file the audit log is written to. Defaults to audit.log
+	// in the data dir.
+	Path string `hcl:"path"`
+
+	// MaxBytes is the size the file is rotated at
+	MaxBytes int `hcl:"max_bytes"`
+
+	// MaxFiles is the number of rotated files kept
+	MaxFiles int `hcl:"max_files"`
+
+	// IncludeReads records the read requests along with the writes
+	IncludeReads bool `hcl:"include_reads"`
+
+	// Endpoints restricts the audit log to the HTTP paths and RPC methods
+	// starting with one of the prefixes.
+	Endpoints []string `hcl:"endpoints"`
+}
+
 // ClientConfig is configuration specific to the client mode
 type ClientConfig struct {
 	// Enabled controls if we are a client
@@ -132,6 +206,9 @@ type ClientConfig struct {
 	// AllocDir is the directory for storing allocation data
 	AllocDir string `hcl:"alloc_dir"`
 
+	// PluginDir is the directory in which task driver plugins are discovered
+	PluginDir string `hcl:"plugin_dir"`
+
 	// Servers is a list of known server addresses. These are as "host:port"
 	Servers []string `hcl:"servers"`
 
@@ -156,6 +233,60 @@ type ClientConfig struct {
 
 	// The network link speed to use if it can not be determined dynamically.
 	NetworkSpeed int `hcl:"network_speed"`
+
+	// GCInterval is the interval at which the disk usage of the alloc dir is
+	// checked to garbage collect terminal allocations
+	GCInterval string `hcl:"gc_interval"`
+
+	// GCDiskUsageThreshold is the percentage of disk space used above which
+	// terminal allocations are garbage collected
+	GCDiskUsageThreshold float64 `hcl:"gc_disk_usage_threshold"`
+
+	// GCInodeUsageThreshold is the percentage of inodes used above which
+	// terminal allocations are garbage collected
+	GCInodeUsageThreshold float64 `hcl:"gc_inode_usage_threshold"`
+
+	// GCMaxAllocs is the number of terminal allocations retained before the
+	// oldest are garbage collected
+	GCMaxAllocs int `hcl:"gc_max_allocs"`
+
+	// HostVolumes are the directories of the host exposed to tasks as named
+	// volumes
+	HostVolumes []*HostVolumeConfig `hcl:"host_volume"`
+
+	// Secrets configures the secrets store the secrets of tasks are read
+	// from
+	Secrets *SecretsConfig `hcl:"secrets"`
+}
+
+// HostVolumeConfig is a directory of the host exposed to tasks as a named
+// volume
+type HostVolumeConfig struct {
+	// Name is the name task groups request the volume by
+	Name string `hcl:",key"`
+
+	// Path is the directory of the host backing the volume
+	Path string `hcl:"path"`
+
+	// ReadOnly prevents tasks from mounting the volume read-write
+	ReadOnly bool `hcl:"read_only"`
+}
+
+// SecretsConfig configures the secrets store of the client
+type SecretsConfig struct {
+	// Provider is the type of the secrets store. Defaults to vault.
+	Provider string `hcl:"provider"`
+
+	// Address is the URL of the secrets store
+	Address string `hcl:"address"`
+
+	// Token authenticates the client to the secrets store. It is renewed
+	// for as long as the client runs.
+	Token string `hcl:"token"`
+
+	// RefreshInterval is the interval at which the secrets of running tasks
+	// are read again to detect rotations
+	RefreshInterval string `hcl:"refresh_interval"`
 }
 
 // ServerConfig is configuration specific to the server mode
@@ -211,6 +342,47 @@ type ServerConfig struct {
 	// the cluster until an explicit join is received. If this is set to
 	// true, we ignore the leave, and rejoin the cluster on start.
 	RejoinAfterLeave bool `hcl:"rejoin_after_leave"`
+
+	// EncryptKey is the base64 encoded key used to encrypt the gossip
+	// between servers. It only seeds the keyring persisted in the data dir,
+	// which is managed with the keyring commands afterwards.
+	EncryptKey string `hcl:"encrypt" json:"-"`
+
+	// Admission configures the admission controllers jobs go through on
+	// registration.
+	Admission *AdmissionConfig `hcl:"admission"`
+}
+
+// AdmissionConfig configures the admission controllers of job
+// registrations. It must be the same on all servers.
+type AdmissionConfig struct {
+	// RequiredMeta are the meta keys every job must set
+	RequiredMeta []string `hcl:"required_meta"`
+
+	// RecommendedMeta are the meta keys jobs are warned about not setting
+	RecommendedMeta []string `hcl:"recommended_meta"`
+
+	// ImagePrefixes restricts the images of Docker tasks to the ones
+	// starting with one of the prefixes.
+	ImagePrefixes []string `hcl:"image_prefixes"`
+
+	// Constraints are added to the jobs that may run in their datacenter
+	Constraints []*AdmissionConstraint `hcl:"constraint"`
+
+	// WebhookURL is the address jobs are POSTed to for an external service
+	// to patch or reject them.
+	WebhookURL string `hcl:"webhook_url"`
+
+	// WebhookTimeout limits the duration of webhook requests
+	WebhookTimeout string `hcl:"webhook_timeout"`
+}
+
+// AdmissionConstraint is a constraint added to the jobs of a datacenter
+type AdmissionConstraint struct {
+	Datacenter string `hcl:"datacenter"`
+	Attribute  string `hcl:"attribute"`
+	Operator   string `hcl:"operator"`
+	Value      string `hcl:"value"`
 }
 
 // Telemetry is the telemetry configuration for the server
@@ -240,6 +412,7 @@ type Addresses struct {
 // different network services. Not all network services support an
 // advertise address. All are optional and default to BindAddr.
 type AdvertiseAddrs struct {
+	HTTP string `hcl:"http"`
 	RPC  string `hcl:"rpc"`
 	Serf string `hcl:"serf"`
 }
@@ -280,6 +453,9 @@ func DefaultConfig() *Config {
 		Addresses:      &Addresses{},
 		AdvertiseAddrs: &AdvertiseAddrs{},
 		Atlas:          &AtlasConfig{},
+		ACL:            &ACLConfig{},
+		TLSConfig:      &TLSConfig{},
+		Audit:          &AuditConfig{},
 		Client: &ClientConfig{
 			Enabled:      false,
 			NetworkSpeed: 100,
@@ -420,6 +596,30 @@ func (c *Config) Merge(b *Config) *Config {
 		result.Atlas = result.Atlas.Merge(b.Atlas)
 	}
 
+	// Apply the ACL configuration
+	if result.ACL == nil && b.ACL != nil {
+		aclConfig := *b.ACL
+		result.ACL = &aclConfig
+	} else if b.ACL != nil {
+		result.ACL = result.ACL.Merge(b.ACL)
+	}
+
+	// Apply the TLS configuration
+	if result.TLSConfig == nil && b.TLSConfig != nil {
+		tlsConfig := *b.TLSConfig
+		result.TLSConfig = &tlsConfig
+	} else if b.TLSConfig != nil {
+		result.TLSConfig = result.TLSConfig.Merge(b.TLSConfig)
+	}
+
+	// Apply the audit configuration
+	if result.Audit == nil && b.Audit != nil {
+		auditConfig := *b.Audit
+		result.Audit = &auditConfig
+	} else if b.Audit != nil {
+		result.Audit = result.Audit.Merge(b.Audit)
+	}
+
 	// Merge config files lists
 	result.Files = append(result.Files, b.Files...)
 
@@ -458,6 +658,17 @@ func (a *ServerConfig) Merge(b *ServerConfig) *ServerConfig {
 	if b.RejoinAfterLeave {
 		result.RejoinAfterLeave = true
 	}
+	if b.EncryptKey != "" {
+		result.EncryptKey = b.EncryptKey
+	}
+
+	// Apply the admission configuration
+	if result.Admission == nil && b.Admission != nil {
+		admission := *b.Admission
+		result.Admission = &admission
+	} else if b.Admission != nil {
+		result.Admission = result.Admission.Merge(b.Admission)
+	}
 
 	// Add the schedulers
 	result.EnabledSchedulers = append(result.EnabledSchedulers, b.EnabledSchedulers...)
@@ -488,6 +699,9 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.AllocDir != "" {
 		result.AllocDir = b.AllocDir
 	}
+	if b.PluginDir != "" {
+		result.PluginDir = b.PluginDir
+	}
 	if b.NodeID != "" {
 		result.NodeID = b.NodeID
 	}
@@ -500,6 +714,37 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	if b.NetworkSpeed != 0 {
 		result.NetworkSpeed = b.NetworkSpeed
 	}
+	if b.GCInterval != "" {
+		result.GCInterval = b.GCInterval
+	}
+	if b.GCDiskUsageThreshold != 0 {
+		result.GCDiskUsageThreshold = b.GCDiskUsageThreshold
+	}
+	if b.GCInodeUsageThreshold != 0 {
+		result.GCInodeUsageThreshold = b.GCInodeUsageThreshold
+	}
+	if b.GCMaxAllocs != 0 {
+		result.GCMaxAllocs = b.GCMaxAllocs
+	}
+
+	// Add the host volumes, replacing the volumes with the same name
+	if len(b.HostVolumes) != 0 {
+		volumes := make([]*HostVolumeConfig, 0, len(result.HostVolumes)+len(b.HostVolumes))
+		for _, v := range result.HostVolumes {
+			if !hasHostVolume(b.HostVolumes, v.Name) {
+				volumes = append(volumes, v)
+			}
+		}
+		result.HostVolumes = append(volumes, b.HostVolumes...)
+	}
+
+	// Apply the secrets configuration
+	if result.Secrets == nil && b.Secrets != nil {
+		secrets := *b.Secrets
+		result.Secrets = &secrets
+	} else if b.Secrets != nil {
+		result.Secrets = result.Secrets.Merge(b.Secrets)
+	}
 
 	// Add the servers
 	result.Servers = append(result.Servers, b.Servers...)
@@ -523,6 +768,16 @@ func (a *ClientConfig) Merge(b *ClientConfig) *ClientConfig {
 	return &result
 }
 
+// hasHostVolume returns whether the volumes include one with the given name.
+func hasHostVolume(volumes []*HostVolumeConfig, name string) bool {
+	for _, v := range volumes {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Merge is used to merge two telemetry configs together
 func (a *Telemetry) Merge(b *Telemetry) *Telemetry {
 	result := *a
@@ -575,6 +830,9 @@ func (a *Addresses) Merge(b *Addresses) *Addresses {
 func (a *AdvertiseAddrs) Merge(b *AdvertiseAddrs) *AdvertiseAddrs {
 	result := *a
 
+	if b.HTTP != "" {
+		result.HTTP = b.HTTP
+	}
 	if b.RPC != "" {
 		result.RPC = b.RPC
 	}
@@ -603,6 +861,110 @@ func (a *AtlasConfig) Merge(b *AtlasConfig) *AtlasConfig {
 	return &result
 }
 
+// Merge merges two ACL configurations together.
+func (a *ACLConfig) Merge(b *ACLConfig) *ACLConfig {
+	result := *a
+
+	if b.Enabled {
+		result.Enabled = true
+	}
+	if b.Token != "" {
+		result.Token = b.Token
+	}
+	return &result
+}
+
+// Merge merges two audit configurations together.
+func (a *AuditConfig) Merge(b *AuditConfig) *AuditConfig {
+	result := *a
+
+	if b.Enabled {
+		result.Enabled = true
+	}
+	if b.Path != "" {
+		result.Path = b.Path
+	}
+	if b.MaxBytes != 0 {
+		result.MaxBytes = b.MaxBytes
+	}
+	if b.MaxFiles != 0 {
+		result.MaxFiles = b.MaxFiles
+	}
+	if b.IncludeReads {
+		result.IncludeReads = true
+	}
+
+	// Add the endpoints
+	result.Endpoints = append(result.Endpoints, b.Endpoints...)
+	return &result
+}
+
+// Merge merges two secrets configurations together.
+func (a *SecretsConfig) Merge(b *SecretsConfig) *SecretsConfig {
+	result := *a
+
+	if b.Provider != "" {
+		result.Provider = b.Provider
+	}
+	if b.Address != "" {
+		result.Address = b.Address
+	}
+	if b.Token != "" {
+		result.Token = b.Token
+	}
+	if b.RefreshInterval != "" {
+		result.RefreshInterval = b.RefreshInterval
+	}
+	return &result
+}
+
+// Merge merges two admission configurations together.
+func (a *AdmissionConfig) Merge(b *AdmissionConfig) *AdmissionConfig {
+	result := *a
+
+	if b.WebhookURL != "" {
+		result.WebhookURL = b.WebhookURL
+	}
+	if b.WebhookTimeout != "" {
+		result.WebhookTimeout = b.WebhookTimeout
+	}
+
+	// Add the rules
+	result.RequiredMeta = append(result.RequiredMeta, b.RequiredMeta...)
+	result.RecommendedMeta = append(result.RecommendedMeta, b.RecommendedMeta...)
+	result.ImagePrefixes = append(result.ImagePrefixes, b.ImagePrefixes...)
+	result.Constraints = append(result.Constraints, b.Constraints...)
+	return &result
+}
+
+// Merge merges two TLS configurations together.
+func (t *TLSConfig) Merge(b *TLSConfig) *TLSConfig {
+	result := *t
+
+	if b.EnableHTTP {
+		result.EnableHTTP = true
+	}
+	if b.EnableRPC {
+		result.EnableRPC = true
+	}
+	if b.VerifyServerHostname {
+		result.VerifyServerHostname = true
+	}
+	if b.VerifyHTTPSClient {
+		result.VerifyHTTPSClient = true
+	}
+	if b.CAFile != "" {
+		result.CAFile = b.CAFile
+	}
+	if b.CertFile != "" {
+		result.CertFile = b.CertFile
+	}
+	if b.KeyFile != "" {
+		result.KeyFile = b.KeyFile
+	}
+	return &result
+}
+
 // LoadConfig loads the configuration at the given path, regardless if
 // its a file or directory.
 func LoadConfig(path string) (*Config, error) {
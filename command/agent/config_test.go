@@ -40,7 +40,11 @@ func TestConfig_Merge(t *testing.T) {
 			Options: map[string]string{
 				"foo": "bar",
 			},
-			NetworkSpeed: 100,
+			NetworkSpeed:          100,
+			GCInterval:            "1m",
+			GCDiskUsageThreshold:  80,
+			GCInodeUsageThreshold: 70,
+			GCMaxAllocs:           50,
 		},
 		Server: &ServerConfig{
 			Enabled:         false,
@@ -70,6 +74,22 @@ func TestConfig_Merge(t *testing.T) {
 			Join:           false,
 			Endpoint:       "foo",
 		},
+		ACL: &ACLConfig{
+			Enabled: false,
+		},
+		TLSConfig: &TLSConfig{
+			EnableHTTP:           false,
+			EnableRPC:            false,
+			VerifyServerHostname: false,
+			VerifyHTTPSClient:    false,
+			CAFile:               "ca1.pem",
+			CertFile:             "cert1.pem",
+			KeyFile:              "key1.pem",
+		},
+		Audit: &AuditConfig{
+			Enabled: false,
+			Path:    "/tmp/audit1.log",
+		},
 	}
 
 	c2 := &Config{
@@ -95,6 +115,7 @@ func TestConfig_Merge(t *testing.T) {
 			Enabled:   true,
 			StateDir:  "/tmp/state2",
 			AllocDir:  "/tmp/alloc2",
+			PluginDir: "/tmp/plugins2",
 			NodeID:    "node2",
 			NodeClass: "class2",
 			Servers:   []string{"server2"},
@@ -105,7 +126,19 @@ func TestConfig_Merge(t *testing.T) {
 				"foo": "bar",
 				"baz": "zip",
 			},
-			NetworkSpeed: 100,
+			NetworkSpeed:          100,
+			GCInterval:            "5m",
+			GCDiskUsageThreshold:  90,
+			GCInodeUsageThreshold: 80,
+			GCMaxAllocs:           100,
+			HostVolumes: []*HostVolumeConfig{
+				&HostVolumeConfig{Name: "certs", Path: "/etc/ssl/certs", ReadOnly: true},
+			},
+			Secrets: &SecretsConfig{
+				Address:         "http://127.0.0.1:8200",
+				Token:           "abc",
+				RefreshInterval: "1m",
+			},
 		},
 		Server: &ServerConfig{
 			Enabled:           true,
@@ -116,10 +149,16 @@ func TestConfig_Merge(t *testing.T) {
 			EnabledSchedulers: []string{structs.JobTypeBatch},
 			NodeGCThreshold:   "12h",
 			RejoinAfterLeave:  true,
+			EncryptKey:        "abc",
 			StartJoin:         []string{"1.1.1.1"},
 			RetryJoin:         []string{"1.1.1.1"},
 			RetryInterval:     "10s",
 			retryInterval:     time.Second * 10,
+			Admission: &AdmissionConfig{
+				RequiredMeta:  []string{"owner"},
+				ImagePrefixes: []string{"registry.example.com/"},
+				WebhookURL:    "http://127.0.0.1:8080/admit",
+			},
 		},
 		Ports: &Ports{
 			HTTP: 20000,
@@ -141,6 +180,27 @@ func TestConfig_Merge(t *testing.T) {
 			Join:           true,
 			Endpoint:       "bar",
 		},
+		ACL: &ACLConfig{
+			Enabled: true,
+			Token:   "foo",
+		},
+		TLSConfig: &TLSConfig{
+			EnableHTTP:           true,
+			EnableRPC:            true,
+			VerifyServerHostname: true,
+			VerifyHTTPSClient:    true,
+			CAFile:               "ca2.pem",
+			CertFile:             "cert2.pem",
+			KeyFile:              "key2.pem",
+		},
+		Audit: &AuditConfig{
+			Enabled:      true,
+			Path:         "/tmp/audit2.log",
+			MaxBytes:     1024,
+			MaxFiles:     3,
+			IncludeReads: true,
+			Endpoints:    []string{"/v1/job", "Job."},
+		},
 	}
 
 	result := c1.Merge(c2)
@@ -149,6 +209,53 @@ func TestConfig_Merge(t *testing.T) {
 	}
 }
 
+func TestClientConfig_Merge_HostVolumes(t *testing.T) {
+	c1 := &ClientConfig{
+		HostVolumes: []*HostVolumeConfig{
+			&HostVolumeConfig{Name: "certs", Path: "/etc/ssl/certs"},
+			&HostVolumeConfig{Name: "data", Path: "/srv/data"},
+		},
+	}
+	c2 := &ClientConfig{
+		HostVolumes: []*HostVolumeConfig{
+			&HostVolumeConfig{Name: "data", Path: "/mnt/data", ReadOnly: true},
+		},
+	}
+
+	result := c1.Merge(c2)
+	expected := []*HostVolumeConfig{
+		&HostVolumeConfig{Name: "certs", Path: "/etc/ssl/certs"},
+		&HostVolumeConfig{Name: "data", Path: "/mnt/data", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(result.HostVolumes, expected) {
+		t.Fatalf("bad: %#v", result.HostVolumes)
+	}
+}
+
+func TestAdmissionConfig_Merge(t *testing.T) {
+	a1 := &AdmissionConfig{
+		RequiredMeta:   []string{"owner"},
+		WebhookURL:     "http://127.0.0.1:8080/admit",
+		WebhookTimeout: "5s",
+	}
+	a2 := &AdmissionConfig{
+		RequiredMeta:  []string{"team"},
+		ImagePrefixes: []string{"registry.example.com/"},
+		WebhookURL:    "http://127.0.0.2:8080/admit",
+	}
+
+	result := a1.Merge(a2)
+	expected := &AdmissionConfig{
+		RequiredMeta:   []string{"owner", "team"},
+		ImagePrefixes:  []string{"registry.example.com/"},
+		WebhookURL:     "http://127.0.0.2:8080/admit",
+		WebhookTimeout: "5s",
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
 func TestConfig_LoadConfigFile(t *testing.T) {
 	// Fails if the file doesn't exist
 	if _, err := LoadConfigFile("/unicorns/leprechauns"); err == nil {
@@ -410,6 +517,7 @@ func TestConfig_LoadConfigString(t *testing.T) {
 			Enabled:   true,
 			StateDir:  "/tmp/client-state",
 			AllocDir:  "/tmp/alloc",
+			PluginDir: "/tmp/plugins",
 			Servers:   []string{"a.b.c:80", "127.0.0.1:1234"},
 			NodeID:    "xyz123",
 			NodeClass: "linux-medium-64bit",
@@ -421,7 +529,21 @@ func TestConfig_LoadConfigString(t *testing.T) {
 				"foo": "bar",
 				"baz": "zip",
 			},
-			NetworkSpeed: 100,
+			NetworkSpeed:          100,
+			GCInterval:            "5m",
+			GCDiskUsageThreshold:  90,
+			GCInodeUsageThreshold: 80,
+			GCMaxAllocs:           100,
+			HostVolumes: []*HostVolumeConfig{
+				&HostVolumeConfig{Name: "certs", Path: "/etc/ssl/certs", ReadOnly: true},
+				&HostVolumeConfig{Name: "data", Path: "/srv/data"},
+			},
+			Secrets: &SecretsConfig{
+				Provider:        "vault",
+				Address:         "https://vault.service.consul:8200",
+				Token:           "abcd",
+				RefreshInterval: "30s",
+			},
 		},
 		Server: &ServerConfig{
 			Enabled:           true,
@@ -436,6 +558,22 @@ func TestConfig_LoadConfigString(t *testing.T) {
 			RetryInterval:     "15s",
 			RejoinAfterLeave:  true,
 			RetryMaxAttempts:  3,
+			EncryptKey:        "abc",
+			Admission: &AdmissionConfig{
+				RequiredMeta:    []string{"owner"},
+				RecommendedMeta: []string{"team"},
+				ImagePrefixes:   []string{"registry.example.com/"},
+				Constraints: []*AdmissionConstraint{
+					&AdmissionConstraint{
+						Datacenter: "dc1",
+						Attribute:  "$attr.kernel.name",
+						Operator:   "=",
+						Value:      "linux",
+					},
+				},
+				WebhookURL:     "http://127.0.0.1:8080/admit",
+				WebhookTimeout: "5s",
+			},
 		},
 		Telemetry: &Telemetry{
 			StatsiteAddr:    "127.0.0.1:1234",
@@ -488,6 +626,7 @@ client {
 	enabled = true
 	state_dir = "/tmp/client-state"
 	alloc_dir = "/tmp/alloc"
+	plugin_dir = "/tmp/plugins"
 	servers = ["a.b.c:80", "127.0.0.1:1234"]
 	node_id = "xyz123"
 	node_class = "linux-medium-64bit"
@@ -500,6 +639,23 @@ client {
 		baz = "zip"
 	}
 	network_speed = 100
+	gc_interval = "5m"
+	gc_disk_usage_threshold = 90.0
+	gc_inode_usage_threshold = 80.0
+	gc_max_allocs = 100
+	host_volume "certs" {
+		path = "/etc/ssl/certs"
+		read_only = true
+	}
+	host_volume "data" {
+		path = "/srv/data"
+	}
+	secrets {
+		provider = "vault"
+		address = "https://vault.service.consul:8200"
+		token = "abcd"
+		refresh_interval = "30s"
+	}
 }
 server {
 	enabled = true
@@ -514,6 +670,20 @@ server {
 	retry_max = 3
 	retry_interval = "15s"
 	rejoin_after_leave = true
+	encrypt = "abc"
+	admission {
+		required_meta = ["owner"]
+		recommended_meta = ["team"]
+		image_prefixes = ["registry.example.com/"]
+		constraint {
+			datacenter = "dc1"
+			attribute = "$attr.kernel.name"
+			operator = "="
+			value = "linux"
+		}
+		webhook_url = "http://127.0.0.1:8080/admit"
+		webhook_timeout = "5s"
+	}
 }
 telemetry {
 	statsite_address = "127.0.0.1:1234"
@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"bufio"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,6 +13,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/audit"
 	"github.com/hashicorp/nomad/nomad/structs"
 )
 
@@ -18,6 +22,10 @@ const (
 	// ErrInvalidMethod is used if the HTTP method is not supported
 	ErrInvalidMethod = "Invalid method"
 
+	// tokenHeader is the header carrying the secret ID of the ACL token of
+	// a request
+	tokenHeader = "X-Nomad-Token"
+
 	// scadaHTTPAddr is the address associated with the
 	// HTTPServer. When populating an ACL token for a request,
 	// this is checked to switch between the ACLToken and
@@ -42,6 +50,20 @@ func NewHTTPServer(agent *Agent, config *Config, logOutput io.Writer) (*HTTPServ
 		return nil, fmt.Errorf("failed to start HTTP listener: %v", err)
 	}
 
+	// Serve HTTPS if TLS is enabled
+	if tlsConf := agent.httpTLSConfig(); tlsConf != nil {
+		if tlsConf.CertFile == "" || tlsConf.KeyFile == "" {
+			ln.Close()
+			return nil, fmt.Errorf("TLS is enabled for HTTP and no cert/key pair provided")
+		}
+		incoming, err := tlsConf.IncomingTLSConfig()
+		if err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("failed to configure HTTPS: %v", err)
+		}
+		ln = tls.NewListener(ln, incoming)
+	}
+
 	// Create the mux
 	mux := http.NewServeMux()
 
@@ -100,6 +122,16 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/allocations", s.wrap(s.AllocsRequest))
 	s.mux.HandleFunc("/v1/allocation/", s.wrap(s.AllocSpecificRequest))
 
+	s.mux.HandleFunc("/v1/client/allocation/", s.wrap(s.ClientAllocRequest))
+	s.mux.HandleFunc("/v1/client/stats", s.wrap(s.ClientStatsRequest))
+	s.mux.HandleFunc("/v1/client/gc", s.wrap(s.ClientGCRequest))
+
+	s.mux.HandleFunc("/v1/namespaces", s.wrap(s.NamespacesRequest))
+	s.mux.HandleFunc("/v1/namespace/", s.wrap(s.NamespaceSpecificRequest))
+	s.mux.HandleFunc("/v1/quotas", s.wrap(s.QuotasRequest))
+	s.mux.HandleFunc("/v1/quota/", s.wrap(s.QuotaSpecificRequest))
+	s.mux.HandleFunc("/v1/quota-usages", s.wrap(s.QuotaUsagesRequest))
+
 	s.mux.HandleFunc("/v1/evaluations", s.wrap(s.EvalsRequest))
 	s.mux.HandleFunc("/v1/evaluation/", s.wrap(s.EvalSpecificRequest))
 
@@ -108,9 +140,17 @@ func (s *HTTPServer) registerHandlers(enableDebug bool) {
 	s.mux.HandleFunc("/v1/agent/members", s.wrap(s.AgentMembersRequest))
 	s.mux.HandleFunc("/v1/agent/force-leave", s.wrap(s.AgentForceLeaveRequest))
 	s.mux.HandleFunc("/v1/agent/servers", s.wrap(s.AgentServersRequest))
+	s.mux.HandleFunc("/v1/agent/keyring/", s.wrap(s.KeyringOperationRequest))
 
 	s.mux.HandleFunc("/v1/regions", s.wrap(s.RegionListRequest))
 
+	s.mux.HandleFunc("/v1/acl/bootstrap", s.wrap(s.ACLTokenBootstrap))
+	s.mux.HandleFunc("/v1/acl/policies", s.wrap(s.ACLPoliciesRequest))
+	s.mux.HandleFunc("/v1/acl/policy/", s.wrap(s.ACLPolicySpecificRequest))
+	s.mux.HandleFunc("/v1/acl/tokens", s.wrap(s.ACLTokensRequest))
+	s.mux.HandleFunc("/v1/acl/token", s.wrap(s.ACLTokenCreateRequest))
+	s.mux.HandleFunc("/v1/acl/token/", s.wrap(s.ACLTokenSpecificRequest))
+
 	s.mux.HandleFunc("/v1/status/leader", s.wrap(s.StatusLeaderRequest))
 	s.mux.HandleFunc("/v1/status/peers", s.wrap(s.StatusPeersRequest))
 
@@ -154,6 +194,26 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 		defer func() {
 			s.logger.Printf("[DEBUG] http: Request %v (%v)", reqURL, time.Now().Sub(start))
 		}()
+
+		// Record the request in the audit log once served
+		if s.agent.auditor.Enabled(req.URL.Path, isReadMethod(req.Method)) {
+			status := &statusRecorder{ResponseWriter: resp, status: 200}
+			resp = status
+			var payload *audit.PayloadHasher
+			if req.Body != nil {
+				payload = audit.NewPayloadHasher(req.Body)
+				req.Body = payload
+			}
+			defer func() {
+				// Hijacked connections stream their payload outside of
+				// the body so it isn't hashed
+				var payloadHash string
+				if payload != nil && !status.hijacked {
+					payloadHash = payload.Sum()
+				}
+				s.auditRequest(req, status.status, payloadHash)
+			}()
+		}
 		obj, err := handler(resp, req)
 
 		// Check for an error
@@ -163,6 +223,8 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 			code := 500
 			if http, ok := err.(HTTPCodedError); ok {
 				code = http.Code()
+			} else if isACLError(err) {
+				code = 403
 			}
 			resp.WriteHeader(code)
 			resp.Write([]byte(err.Error()))
@@ -192,6 +254,80 @@ func (s *HTTPServer) wrap(handler func(resp http.ResponseWriter, req *http.Reque
 	return f
 }
 
+// isReadMethod returns whether requests with the HTTP method are reads
+func isReadMethod(method string) bool {
+	return method == "GET" || method == "HEAD"
+}
+
+// statusRecorder wraps a response writer to keep track of the status code
+// of the response. Hijacking and flushing are passed through so that
+// streaming endpoints keep working while audited.
+type statusRecorder struct {
+	http.ResponseWriter
+	status   int
+	hijacked bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("connection does not support hijacking")
+	}
+	conn, buf, err := hj.Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, buf, err
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// auditRequest records the served request in the audit log. The identity is
+// the accessor ID of the ACL token of the request, falling back to the
+// common name of the client certificate.
+func (s *HTTPServer) auditRequest(req *http.Request, status int, payloadHash string) {
+	var secretID string
+	parseToken(req, &secretID)
+	entry := &audit.Entry{
+		Type:        audit.TypeHTTP,
+		RemoteAddr:  req.RemoteAddr,
+		Identity:    s.agent.tokenAccessor(secretID),
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Status:      status,
+		PayloadHash: payloadHash,
+	}
+	if entry.Identity == "" && req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		entry.Identity = req.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	if status >= 400 {
+		entry.Error = http.StatusText(status)
+	}
+
+	if err := s.agent.auditor.Log(entry); err != nil {
+		s.logger.Printf("[ERR] http: failed to write audit log: %v", err)
+	}
+}
+
+// isACLError returns whether the error is an ACL failure. Errors returned by
+// RPCs lose their type so they are compared by message.
+func isACLError(err error) bool {
+	switch err.Error() {
+	case structs.ErrPermissionDenied.Error(), structs.ErrTokenNotFound.Error():
+		return true
+	}
+	return false
+}
+
 // decodeBody is used to decode a JSON request body
 func decodeBody(req *http.Request, out interface{}) error {
 	dec := json.NewDecoder(req.Body)
@@ -267,9 +403,40 @@ func (s *HTTPServer) parseRegion(req *http.Request, r *string) {
 	}
 }
 
+// parseNamespace is used to parse the ?namespace query param
+func parseNamespace(req *http.Request, n *string) {
+	if other := req.URL.Query().Get("namespace"); other != "" {
+		*n = other
+	}
+}
+
+// parseToken is used to parse the X-Nomad-Token header
+func parseToken(req *http.Request, token *string) {
+	if other := req.Header.Get(tokenHeader); other != "" {
+		*token = other
+	}
+}
+
 // parse is a convenience method for endpoints that need to parse multiple flags
 func (s *HTTPServer) parse(resp http.ResponseWriter, req *http.Request, r *string, b *structs.QueryOptions) bool {
 	s.parseRegion(req, r)
+	parseNamespace(req, &b.Namespace)
+	parseToken(req, &b.AuthToken)
 	parseConsistency(req, b)
 	return parseWait(resp, req, b)
 }
+
+// parseWriteRequest is used to parse the region and token of write requests
+func (s *HTTPServer) parseWriteRequest(req *http.Request, w *structs.WriteRequest) {
+	s.parseRegion(req, &w.Region)
+	parseNamespace(req, &w.Namespace)
+	parseToken(req, &w.AuthToken)
+}
+
+// resolveToken returns the ACL of the token of the request, which is nil if
+// ACLs are disabled. It is used by endpoints served by the agent itself.
+func (s *HTTPServer) resolveToken(req *http.Request) (*acl.ACL, error) {
+	var secretID string
+	parseToken(req, &secretID)
+	return s.agent.resolveToken(secretID)
+}
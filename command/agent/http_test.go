@@ -13,6 +13,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/testutil"
 )
@@ -45,6 +46,49 @@ func makeHTTPServer(t *testing.T, cb func(c *Config)) *TestServer {
 	return s
 }
 
+func TestHTTPServer_TLS(t *testing.T) {
+	s := makeHTTPServer(t, tlsEnabled)
+	defer s.Cleanup()
+
+	// httpsGet requests the agent self endpoint with the given client
+	// certificate
+	httpsGet := func(certFile, keyFile string) error {
+		conf := tlsutil.NewConfig(testCA, certFile, keyFile)
+		conf.VerifyOutgoing = true
+		conf.VerifyServerHostname = true
+		tlsConf, err := conf.OutgoingTLSConfig()
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConf}}
+		resp, err := client.Get(fmt.Sprintf("https://%s/v1/agent/self", s.Server.addr))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("bad status: %s", resp.Status)
+		}
+		return nil
+	}
+
+	// Clients must present a certificate
+	if err := httpsGet("", ""); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := httpsGet(testClientCert, testClientKey); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Plaintext requests are rejected
+	if resp, err := http.Get(fmt.Sprintf("http://%s/v1/agent/self", s.Server.addr)); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == 200 {
+			t.Fatalf("expected plaintext request to fail")
+		}
+	}
+}
+
 func TestSetIndex(t *testing.T) {
 	resp := httptest.NewRecorder()
 	setIndex(resp, 1000)
@@ -273,6 +317,47 @@ func TestParseRegion(t *testing.T) {
 	}
 }
 
+func TestParseNamespace(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/jobs", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var namespace string
+	parseNamespace(req, &namespace)
+	if namespace != "" {
+		t.Fatalf("bad %s", namespace)
+	}
+
+	req, err = http.NewRequest("GET", "/v1/jobs?namespace=foo", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parseNamespace(req, &namespace)
+	if namespace != "foo" {
+		t.Fatalf("bad %s", namespace)
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	req, err := http.NewRequest("GET", "/v1/jobs", nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	var token string
+	parseToken(req, &token)
+	if token != "" {
+		t.Fatalf("bad %s", token)
+	}
+
+	req.Header.Set("X-Nomad-Token", "foobar")
+	parseToken(req, &token)
+	if token != "foobar" {
+		t.Fatalf("bad %s", token)
+	}
+}
+
 // assertIndex tests that X-Nomad-Index is set and non-zero
 func assertIndex(t *testing.T, resp *httptest.ResponseRecorder) {
 	header := resp.Header().Get("X-Nomad-Index")
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/hashicorp/nomad/nomad"
+	"github.com/hashicorp/serf/serf"
+)
+
+const (
+	// serfKeyring is the path of the keyring file of the gossip pool,
+	// relative to the data dir of the server.
+	serfKeyring = "serf/keyring"
+)
+
+// decodeKey decodes a base64 encoded gossip encryption key and checks it is
+// a valid AES key.
+func decodeKey(key string) ([]byte, error) {
+	k, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("key must be base64 encoded: %v", err)
+	}
+	switch len(k) {
+	case 16, 24, 32:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("key size must be 16, 24 or 32 bytes; got %d", len(k))
+	}
+}
+
+// initKeyring creates a keyring file holding the given key. It fails if the
+// file already exists.
+func initKeyring(path, key string) error {
+	if _, err := decodeKey(key); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal([]string{key})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	fh, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	if _, err := fh.Write(data); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// loadKeyringFile loads the keyring file of the serf config into its
+// memberlist config. The first key of the file is used to encrypt messages.
+func loadKeyringFile(c *serf.Config) error {
+	data, err := ioutil.ReadFile(c.KeyringFile)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return fmt.Errorf("failed to parse keyring file %q: %v", c.KeyringFile, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys present in keyring file %q", c.KeyringFile)
+	}
+
+	decoded := make([][]byte, len(keys))
+	for i, key := range keys {
+		k, err := decodeKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid key in keyring file %q: %v", c.KeyringFile, err)
+		}
+		decoded[i] = k
+	}
+
+	keyring, err := memberlist.NewKeyring(decoded, decoded[0])
+	if err != nil {
+		return err
+	}
+	c.MemberlistConfig.Keyring = keyring
+	return nil
+}
+
+// setupKeyring enables the gossip encryption of the server. The keyring is
+// persisted under the data dir so installed keys survive restarts. The
+// encrypt key of the agent config only seeds a new keyring file and is
+// ignored once the file exists. Servers without a data dir keep the key in
+// memory.
+func (a *Agent) setupKeyring(conf *nomad.Config) error {
+	key := a.config.Server.EncryptKey
+	if conf.DevMode || conf.DataDir == "" {
+		if key == "" {
+			return nil
+		}
+		k, err := decodeKey(key)
+		if err != nil {
+			return fmt.Errorf("invalid encrypt key: %v", err)
+		}
+		conf.SerfConfig.MemberlistConfig.SecretKey = k
+		return nil
+	}
+
+	file := filepath.Join(conf.DataDir, serfKeyring)
+	if _, err := os.Stat(file); err == nil {
+		if key != "" {
+			a.logger.Printf("[WARN] agent: loaded keyring from %s, ignoring the encrypt key", file)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	} else if key == "" {
+		return nil
+	} else if err := initKeyring(file, key); err != nil {
+		return fmt.Errorf("failed to initialize keyring: %v", err)
+	}
+
+	conf.SerfConfig.KeyringFile = file
+	return loadKeyringFile(conf.SerfConfig)
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad"
+)
+
+func TestAgent_InitKeyring(t *testing.T) {
+	key1 := "tbLJg26ZJyJ9pK3qhc9jig=="
+	key2 := "4leC33rgtXKIVUr9Nr0snQ=="
+	expected := `["tbLJg26ZJyJ9pK3qhc9jig=="]`
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, serfKeyring)
+
+	// Invalid keys are rejected
+	if err := initKeyring(file, "nope"); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := initKeyring(file, base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	if err := initKeyring(file, key1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(content) != expected {
+		t.Fatalf("bad: %s", content)
+	}
+
+	// An existing keyring is not overwritten
+	if err := initKeyring(file, key2); err == nil {
+		t.Fatalf("expected error")
+	}
+	content, err = ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if string(content) != expected {
+		t.Fatalf("bad: %s", content)
+	}
+}
+
+func TestAgent_SetupKeyring(t *testing.T) {
+	key1 := "tbLJg26ZJyJ9pK3qhc9jig=="
+	key2 := "4leC33rgtXKIVUr9Nr0snQ=="
+
+	dir := tmpDir(t)
+	defer os.RemoveAll(dir)
+
+	conf := DefaultConfig()
+	a := &Agent{config: conf, logger: log.New(os.Stderr, "", log.LstdFlags)}
+
+	// No keyring is set up without a key
+	out := nomad.DefaultConfig()
+	out.DataDir = dir
+	if err := a.setupKeyring(out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SerfConfig.KeyringFile != "" || out.SerfConfig.MemberlistConfig.Keyring != nil {
+		t.Fatalf("expected no keyring")
+	}
+
+	// The key seeds the keyring file
+	conf.Server.EncryptKey = key1
+	if err := a.setupKeyring(out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.SerfConfig.KeyringFile != filepath.Join(dir, serfKeyring) {
+		t.Fatalf("bad: %s", out.SerfConfig.KeyringFile)
+	}
+	keyring := out.SerfConfig.MemberlistConfig.Keyring
+	k1, _ := base64.StdEncoding.DecodeString(key1)
+	if keyring == nil || !bytes.Equal(keyring.GetPrimaryKey(), k1) {
+		t.Fatalf("bad keyring: %#v", keyring)
+	}
+
+	// The keyring file takes precedence over the key
+	conf.Server.EncryptKey = key2
+	out = nomad.DefaultConfig()
+	out.DataDir = dir
+	if err := a.setupKeyring(out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	keyring = out.SerfConfig.MemberlistConfig.Keyring
+	if keyring == nil || !bytes.Equal(keyring.GetPrimaryKey(), k1) {
+		t.Fatalf("bad keyring: %#v", keyring)
+	}
+
+	// Servers without a data dir keep the key in memory
+	out = nomad.DefaultConfig()
+	out.DevMode = true
+	if err := a.setupKeyring(out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	k2, _ := base64.StdEncoding.DecodeString(key2)
+	if !bytes.Equal(out.SerfConfig.MemberlistConfig.SecretKey, k2) || out.SerfConfig.KeyringFile != "" {
+		t.Fatalf("bad: %#v", out.SerfConfig)
+	}
+}
@@ -0,0 +1,128 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHTTP_NamespaceCRUD(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		ns := mock.Namespace()
+
+		// Create the namespace
+		req, err := http.NewRequest("PUT", "/v1/namespace/"+ns.Name, encodeReq(ns))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.NamespaceSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		// List the namespaces
+		req, err = http.NewRequest("GET", "/v1/namespaces", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err := s.Server.NamespacesRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+		list := obj.([]*structs.Namespace)
+		if len(list) != 2 || list[1].Name != ns.Name {
+			t.Fatalf("bad: %#v", list)
+		}
+
+		// Query the namespace
+		req, err = http.NewRequest("GET", "/v1/namespace/"+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.NamespaceSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out := obj.(*structs.Namespace); out.Description != ns.Description {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// Register a job in the namespace
+		job := mock.Job()
+		job.Namespace = ""
+		args := structs.JobRegisterRequest{
+			Job:          job,
+			WriteRequest: structs.WriteRequest{Region: "global"},
+		}
+		req, err = http.NewRequest("PUT", "/v1/jobs?namespace="+ns.Name, encodeReq(args))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.JobsRequest(httptest.NewRecorder(), req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// The job is only visible in its namespace
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.JobSpecificRequest(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected job not found")
+		}
+		req, err = http.NewRequest("GET", "/v1/job/"+job.ID+"?namespace="+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		obj, err = s.Server.JobSpecificRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out := obj.(*structs.Job); out.Namespace != ns.Name {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// Namespaces with jobs can't be deleted
+		req, err = http.NewRequest("DELETE", "/v1/namespace/"+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.NamespaceSpecificRequest(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected error")
+		}
+
+		req, err = http.NewRequest("DELETE", "/v1/job/"+job.ID+"?namespace="+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.JobSpecificRequest(httptest.NewRecorder(), req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+
+		// Delete the namespace
+		req, err = http.NewRequest("DELETE", "/v1/namespace/"+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.NamespaceSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		req, err = http.NewRequest("GET", "/v1/namespace/"+ns.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.NamespaceSpecificRequest(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected namespace not found")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestHTTP_QuotaCRUD(t *testing.T) {
+	httpTest(t, nil, func(s *TestServer) {
+		quota := mock.QuotaSpec()
+
+		// Create the quota
+		req, err := http.NewRequest("PUT", "/v1/quota/"+quota.Name, encodeReq(quota))
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW := httptest.NewRecorder()
+		if _, err := s.Server.QuotaSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		// List the quotas
+		req, err = http.NewRequest("GET", "/v1/quotas", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err := s.Server.QuotasRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+		list := obj.([]*structs.QuotaSpec)
+		if len(list) != 1 || list[0].Name != quota.Name {
+			t.Fatalf("bad: %#v", list)
+		}
+
+		// Query the quota
+		req, err = http.NewRequest("GET", "/v1/quota/"+quota.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		obj, err = s.Server.QuotaSpecificRequest(respW, req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if out := obj.(*structs.QuotaSpec); out.Limits[0].CPU != quota.Limits[0].CPU {
+			t.Fatalf("bad: %#v", out)
+		}
+
+		// No allocations were placed yet
+		req, err = http.NewRequest("GET", "/v1/quota-usages", nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		obj, err = s.Server.QuotaUsagesRequest(httptest.NewRecorder(), req)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if usages := obj.([]*structs.QuotaUsage); len(usages) != 0 {
+			t.Fatalf("bad: %#v", usages)
+		}
+
+		// Delete the quota
+		req, err = http.NewRequest("DELETE", "/v1/quota/"+quota.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		respW = httptest.NewRecorder()
+		if _, err := s.Server.QuotaSpecificRequest(respW, req); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		assertIndex(t, respW)
+
+		req, err = http.NewRequest("GET", "/v1/quota/"+quota.Name, nil)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, err := s.Server.QuotaSpecificRequest(httptest.NewRecorder(), req); err == nil {
+			t.Fatalf("expected quota not found")
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type AllocExecCommand struct {
+	Meta
+
+	// Stdin is read for the command's input. It defaults to os.Stdin and is
+	// overridable for testing.
+	Stdin io.Reader
+}
+
+func (c *AllocExecCommand) Help() string {
+	helpText := `
+Usage: nomad alloc-exec [options] <allocation> <command> [<args>...]
+
+  Run a command inside the isolation context of a running task of an
+  allocation. The output of the command is streamed back and the exit code of
+  the command is returned. The task to run the command in must be given when
+  the allocation has more than one task.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Alloc Exec Options:
+
+  -task <name>
+    Name of the task to run the command in.
+
+  -i
+    Pass stdin to the command. Defaults to true.
+
+  -t
+    Allocate a pseudo-terminal for the command. Defaults to true if both
+    stdin and stdout are terminals.
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *AllocExecCommand) Synopsis() string {
+	return "Execute a command inside a running task"
+}
+
+func (c *AllocExecCommand) Run(args []string) int {
+	var task string
+	var stdinOpt bool
+	var ttyOpt bool
+
+	stdinTty := isTerminal(os.Stdin)
+	stdoutTty := isTerminal(os.Stdout)
+
+	flags := c.Meta.FlagSet("alloc-exec", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&task, "task", "", "")
+	flags.BoolVar(&stdinOpt, "i", true, "")
+	flags.BoolVar(&ttyOpt, "t", stdinTty && stdoutTty, "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got an allocation ID and a command
+	args = flags.Args()
+	if len(args) < 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	allocID := args[0]
+	command := args[1:]
+
+	if ttyOpt && !stdinOpt {
+		c.Ui.Error("-t requires -i to be set")
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the allocation info
+	alloc, _, err := client.Allocations().Info(allocID, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying allocation: %s", err))
+		return 1
+	}
+
+	// Determine the task to run the command in
+	if task == "" {
+		if len(alloc.TaskStates) != 1 {
+			c.Ui.Error("Allocation has more than one task, please specify one with -task")
+			return 1
+		}
+		for name := range alloc.TaskStates {
+			task = name
+		}
+	} else if _, ok := alloc.TaskStates[task]; !ok {
+		c.Ui.Error(fmt.Sprintf("Allocation %q has no task %q", alloc.ID, task))
+		return 1
+	}
+
+	var stdin io.Reader
+	if stdinOpt {
+		stdin = c.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+	}
+
+	var sizeCh <-chan api.TerminalSize
+	if ttyOpt {
+		if !stdinTty {
+			c.Ui.Error("-t requires stdin to be a terminal")
+			return 1
+		}
+
+		restore, err := makeRawTerminal(os.Stdin)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error setting up terminal: %s", err))
+			return 1
+		}
+		defer restore()
+
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		sizeCh = watchTerminalSize(os.Stdout, stopCh)
+	}
+
+	code, err := client.Allocations().Exec(alloc, task, command, ttyOpt,
+		stdin, os.Stdout, os.Stderr, sizeCh, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error executing command: %s", err))
+		return 1
+	}
+	return code
+}
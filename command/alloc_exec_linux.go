@@ -0,0 +1,80 @@
+package command
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// isTerminal returns whether the file refers to a terminal.
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	return ioctl(f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios))) == nil
+}
+
+// makeRawTerminal puts the terminal into raw mode and returns a function that
+// restores its previous state.
+func makeRawTerminal(f *os.File) (func(), error) {
+	var old syscall.Termios
+	if err := ioctl(f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&old))); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Oflag &^= syscall.OPOST
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&raw))); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		ioctl(f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&old)))
+	}, nil
+}
+
+// watchTerminalSize sends the size of the terminal on the returned channel
+// once and then each time the terminal is resized, until stopCh is closed.
+func watchTerminalSize(f *os.File, stopCh <-chan struct{}) <-chan api.TerminalSize {
+	sizeCh := make(chan api.TerminalSize, 1)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			var ws struct {
+				Row, Col, Xpixel, Ypixel uint16
+			}
+			if err := ioctl(f.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws))); err == nil {
+				select {
+				case sizeCh <- api.TerminalSize{Height: ws.Row, Width: ws.Col}:
+				case <-stopCh:
+					return
+				}
+			}
+
+			select {
+			case <-sigCh:
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return sizeCh
+}
+
+func ioctl(fd, cmd, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
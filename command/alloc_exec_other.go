@@ -0,0 +1,24 @@
+// +build !linux
+
+package command
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// Terminal handling is only supported on Linux.
+
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+func makeRawTerminal(f *os.File) (func(), error) {
+	return nil, fmt.Errorf("terminals are not supported on this platform")
+}
+
+func watchTerminalSize(f *os.File, stopCh <-chan struct{}) <-chan api.TerminalSize {
+	return nil
+}
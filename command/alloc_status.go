@@ -30,6 +30,10 @@ Alloc Status Options:
 
   -short
     Display short output. Shows only the most recent task event.
+
+  -stats
+    Display the latest resource usage of the allocation's tasks. The usage
+    is queried from the client running the allocation.
 `
 
 	return strings.TrimSpace(helpText)
@@ -40,11 +44,12 @@ func (c *AllocStatusCommand) Synopsis() string {
 }
 
 func (c *AllocStatusCommand) Run(args []string) int {
-	var short bool
+	var short, stats bool
 
 	flags := c.Meta.FlagSet("alloc-status", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
+	flags.BoolVar(&stats, "stats", false, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -95,6 +100,16 @@ func (c *AllocStatusCommand) Run(args []string) int {
 		c.taskStatus(alloc)
 	}
 
+	// Print the resource usage of the tasks
+	if stats {
+		usage, err := client.Allocations().Stats(alloc, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying allocation stats: %s", err))
+			return 1
+		}
+		c.resourceUsage(usage)
+	}
+
 	// Format the detailed status
 	c.Ui.Output("\n==> Status")
 	dumpAllocStatus(c.Ui, alloc)
@@ -127,6 +142,39 @@ func (c *AllocStatusCommand) shortTaskStatus(alloc *api.Allocation) {
 	c.Ui.Output(formatList(tasks))
 }
 
+// resourceUsage prints out the latest resource usage of each task.
+func (c *AllocStatusCommand) resourceUsage(usage *api.AllocResourceUsage) {
+	names := make([]string, 0, len(usage.Tasks))
+	for name := range usage.Tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tasks := make([]string, 0, len(names)+1)
+	tasks = append(tasks, "Task|CPU|Memory|Cache|Swap|Throttled Time")
+	for _, name := range names {
+		ru := usage.Tasks[name].ResourceUsage
+		if ru == nil {
+			continue
+		}
+		var cpu, mem, cache, swap, throttled string
+		if cs := ru.CpuStats; cs != nil {
+			cpu = fmt.Sprintf("%.0f MHz (%.2f%% of reserved)", cs.TotalTicks, cs.ReservedPercent)
+			throttled = time.Duration(cs.ThrottledTime).String()
+		}
+		if ms := ru.MemoryStats; ms != nil {
+			mem = formatBytes(ms.RSS)
+			cache = formatBytes(ms.Cache)
+			swap = formatBytes(ms.Swap)
+		}
+		tasks = append(tasks, fmt.Sprintf("%s|%s|%s|%s|%s|%s",
+			name, cpu, mem, cache, swap, throttled))
+	}
+
+	c.Ui.Output("\n==> Resource Utilization")
+	c.Ui.Output(formatList(tasks))
+}
+
 // taskStatus prints out the most recent events for each task.
 func (c *AllocStatusCommand) taskStatus(alloc *api.Allocation) {
 	for task := range c.sortedTaskStateIterator(alloc.TaskStates) {
@@ -145,6 +193,14 @@ func (c *AllocStatusCommand) taskStatus(alloc *api.Allocation) {
 				desc = event.DriverError
 			case api.TaskKilled:
 				desc = event.KillError
+			case api.TaskLifecycleFailure:
+				desc = event.LifecycleError
+			case api.TaskArtifactDownloadFailed:
+				desc = event.DownloadError
+			case api.TaskTemplateFailure:
+				desc = event.TemplateError
+			case api.TaskRestartSignal, api.TaskSignaling:
+				desc = event.Reason
 			case api.TaskTerminated:
 				var parts []string
 				parts = append(parts, fmt.Sprintf("Exit Code: %d", event.ExitCode))
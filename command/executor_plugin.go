@@ -0,0 +1,50 @@
+package command
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/nomad/client/driver"
+)
+
+type ExecutorPluginCommand struct {
+	Meta
+}
+
+func (e *ExecutorPluginCommand) Help() string {
+	helpText := `
+Usage: nomad executor <log_file>
+
+  INTERNAL ONLY
+
+  Serves the executor of a task over RPC. The client launches an executor
+  process for every task of the drivers that use executors. The executor
+  starts the task's command, isolates it, rotates its logs and waits for it to
+  exit. The process keeps running when the client is restarted so that the
+  client can reconnect to it. The executor logs to the required log_file.
+  `
+	return strings.TrimSpace(helpText)
+}
+
+func (e *ExecutorPluginCommand) Synopsis() string {
+	return "Serve the executor of a task."
+}
+
+func (e *ExecutorPluginCommand) Run(args []string) int {
+	if len(args) != 1 {
+		e.Ui.Error(e.Help())
+		return 1
+	}
+
+	logFile, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		e.Ui.Error(err.Error())
+		return 1
+	}
+	defer logFile.Close()
+	log.SetOutput(logFile)
+
+	driver.ServeExecutor()
+	return 0
+}
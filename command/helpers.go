@@ -1,6 +1,8 @@
 package command
 
 import (
+	"fmt"
+
 	"github.com/ryanuber/columnize"
 )
 
@@ -21,3 +23,18 @@ func formatList(in []string) string {
 	columnConf.Empty = "<none>"
 	return columnize.Format(in, columnConf)
 }
+
+// formatBytes returns a human readable representation of a number of bytes
+// using binary units.
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := uint64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// KeyringCommand is a Command implementation that handles querying, installing,
+// and removing gossip encryption keys from a keyring.
+type KeyringCommand struct {
+	Meta
+}
+
+func (c *KeyringCommand) Help() string {
+	helpText := `
+Usage: nomad keyring [options]
+
+  Manages encryption keys used for gossip messages between Nomad servers. Gossip
+  encryption is optional. When enabled, this command may be used to examine
+  active encryption keys in the cluster, add new keys, and remove old ones. When
+  combined, this functionality provides the ability to perform key rotation
+  cluster-wide, without disrupting the cluster.
+
+  All operations performed by this command can only be run against server nodes.
+
+  All variations of the keyring command return 0 if all nodes reply and there
+  are no errors. If any node fails to reply or reports failure, the exit code
+  will be 1.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Keyring Options:
+
+  -install=<key>
+    Install a new encryption key. This will broadcast the new key to
+    all servers of the cluster.
+
+  -list
+    List all keys currently in use within the cluster.
+
+  -remove=<key>
+    Remove the given key from the cluster. This operation may only be
+    performed on keys which are not currently the primary key.
+
+  -use=<key>
+    Change the primary encryption key, which is used to encrypt
+    messages. The key must already be installed before this operation
+    can succeed.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *KeyringCommand) Synopsis() string {
+	return "Manages gossip layer encryption keys"
+}
+
+func (c *KeyringCommand) Run(args []string) int {
+	var installKey, useKey, removeKey string
+	var listKeys bool
+
+	flags := c.Meta.FlagSet("keyring", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&installKey, "install", "", "")
+	flags.StringVar(&useKey, "use", "", "")
+	flags.StringVar(&removeKey, "remove", "", "")
+	flags.BoolVar(&listKeys, "list", false, "")
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Only accept a single argument
+	found := listKeys
+	for _, arg := range []string{installKey, useKey, removeKey} {
+		if found && len(arg) > 0 {
+			c.Ui.Error("Only a single action is allowed")
+			return 1
+		}
+		found = found || len(arg) > 0
+	}
+
+	// Fail fast if no actionable args were passed
+	if !found || len(flags.Args()) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+	agent := client.Agent()
+
+	var resp *api.KeyringResponse
+	switch {
+	case listKeys:
+		c.Ui.Output("Gathering installed encryption keys...")
+		resp, err = agent.ListKeys()
+	case installKey != "":
+		c.Ui.Output("Installing new gossip encryption key...")
+		resp, err = agent.InstallKey(installKey)
+	case useKey != "":
+		c.Ui.Output("Changing primary gossip encryption key...")
+		resp, err = agent.UseKey(useKey)
+	case removeKey != "":
+		c.Ui.Output("Removing gossip encryption key...")
+		resp, err = agent.RemoveKey(removeKey)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error: %s", err))
+		return 1
+	}
+
+	// Report the servers which failed
+	if len(resp.Messages) != 0 {
+		nodes := make([]string, 0, len(resp.Messages))
+		for node := range resp.Messages {
+			nodes = append(nodes, node)
+		}
+		sort.Strings(nodes)
+		for _, node := range nodes {
+			c.Ui.Error(fmt.Sprintf("  %s: %s", node, resp.Messages[node]))
+		}
+		return 1
+	}
+
+	if listKeys {
+		c.handleKeyResponse(resp)
+	}
+	return 0
+}
+
+// handleKeyResponse outputs the keys and the number of servers each is
+// installed on.
+func (c *KeyringCommand) handleKeyResponse(resp *api.KeyringResponse) {
+	keys := make([]string, 0, len(resp.Keys))
+	for key := range resp.Keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, len(keys)+1)
+	out[0] = "Key|Servers"
+	for i, key := range keys {
+		out[i+1] = fmt.Sprintf("%s|%d/%d", key, resp.Keys[key], resp.NumNodes)
+	}
+	c.Ui.Output(formatList(out))
+}
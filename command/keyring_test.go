@@ -0,0 +1,83 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/testutil"
+	"github.com/mitchellh/cli"
+)
+
+func TestKeyringCommand_Implements(t *testing.T) {
+	var _ cli.Command = &KeyringCommand{}
+}
+
+func TestKeyringCommand_Run(t *testing.T) {
+	key1 := "HS5lJ+XuTlYKWaeGYyG+/A=="
+	key2 := "kZyFABeAmc64UMTrm9XuKA=="
+	srv, _, url := testServer(t, func(c *testutil.TestServerConfig) {
+		c.Server.EncryptKey = key1
+	})
+	defer srv.Stop()
+
+	ui := new(cli.MockUi)
+	cmd := &KeyringCommand{Meta: Meta{Ui: ui}}
+
+	// The initial key is listed
+	if code := cmd.Run([]string{"-address=" + url, "-list"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d: %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, key1) {
+		t.Fatalf("expected key %s, got: %s", key1, out)
+	}
+
+	// Rotate to the second key
+	for _, args := range [][]string{
+		{"-install=" + key2},
+		{"-use=" + key2},
+		{"-remove=" + key1},
+	} {
+		if code := cmd.Run(append([]string{"-address=" + url}, args...)); code != 0 {
+			t.Fatalf("%v: expected exit 0, got: %d: %s", args, code, ui.ErrorWriter.String())
+		}
+	}
+
+	ui.OutputWriter.Reset()
+	if code := cmd.Run([]string{"-address=" + url, "-list"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d", code)
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, key2) || strings.Contains(out, key1) {
+		t.Fatalf("expected only key %s, got: %s", key2, out)
+	}
+}
+
+func TestKeyringCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &KeyringCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on multiple actions
+	if code := cmd.Run([]string{"-list", "-remove=foo"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Only a single action") {
+		t.Fatalf("expected single action error, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "-list"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
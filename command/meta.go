@@ -14,7 +14,12 @@ import (
 const (
 	// Names of environment variables used to supply various
 	// config options to the Nomad CLI.
-	EnvNomadAddress = "NOMAD_ADDR"
+	EnvNomadAddress    = "NOMAD_ADDR"
+	EnvNomadToken      = "NOMAD_TOKEN"
+	EnvNomadNamespace  = "NOMAD_NAMESPACE"
+	EnvNomadCACert     = "NOMAD_CA_CERT"
+	EnvNomadClientCert = "NOMAD_CLIENT_CERT"
+	EnvNomadClientKey  = "NOMAD_CLIENT_KEY"
 )
 
 // FlagSetFlags is an enum to define what flags are present in the
@@ -33,7 +38,12 @@ type Meta struct {
 	Ui cli.Ui
 
 	// These are set by the command line flags.
-	flagAddress string
+	flagAddress    string
+	flagToken      string
+	flagNamespace  string
+	flagCACert     string
+	flagClientCert string
+	flagClientKey  string
 }
 
 // FlagSet returns a FlagSet with the common flags that every
@@ -47,6 +57,11 @@ func (m *Meta) FlagSet(n string, fs FlagSetFlags) *flag.FlagSet {
 	// client connectivity options.
 	if fs&FlagSetClient != 0 {
 		f.StringVar(&m.flagAddress, "address", "", "")
+		f.StringVar(&m.flagToken, "token", "", "")
+		f.StringVar(&m.flagNamespace, "namespace", "", "")
+		f.StringVar(&m.flagCACert, "ca-cert", "", "")
+		f.StringVar(&m.flagClientCert, "client-cert", "", "")
+		f.StringVar(&m.flagClientKey, "client-key", "", "")
 	}
 
 	// Create an io.Writer that writes to our UI properly for errors.
@@ -75,6 +90,34 @@ func (m *Meta) Client() (*api.Client, error) {
 	if m.flagAddress != "" {
 		config.Address = m.flagAddress
 	}
+	if v := os.Getenv(EnvNomadToken); v != "" {
+		config.SecretID = v
+	}
+	if m.flagToken != "" {
+		config.SecretID = m.flagToken
+	}
+	if v := os.Getenv(EnvNomadNamespace); v != "" {
+		config.Namespace = v
+	}
+	if m.flagNamespace != "" {
+		config.Namespace = m.flagNamespace
+	}
+
+	// Override the TLS settings of the environment with the flags
+	if m.flagCACert != "" || m.flagClientCert != "" || m.flagClientKey != "" {
+		if config.TLSConfig == nil {
+			config.TLSConfig = &api.TLSConfig{}
+		}
+		if m.flagCACert != "" {
+			config.TLSConfig.CACert = m.flagCACert
+		}
+		if m.flagClientCert != "" {
+			config.TLSConfig.ClientCert = m.flagClientCert
+		}
+		if m.flagClientKey != "" {
+			config.TLSConfig.ClientKey = m.flagClientKey
+		}
+	}
 	return api.NewClient(config)
 }
 
@@ -85,6 +128,29 @@ func generalOptionsUsage() string {
     The address of the Nomad server.
     Overrides the NOMAD_ADDR environment variable if set.
     Default = http://127.0.0.1:4646
+
+  -token=<secret-id>
+    The secret ID of the ACL token used for the request.
+    Overrides the NOMAD_TOKEN environment variable if set.
+
+  -namespace=<namespace>
+    The namespace of the jobs, evaluations and allocations the command
+    operates on. Overrides the NOMAD_NAMESPACE environment variable if set.
+    Default = default
+
+  -ca-cert=<path>
+    Path to a PEM encoded CA certificate file used to verify the
+    certificate of the Nomad agent serving HTTPS.
+    Overrides the NOMAD_CA_CERT environment variable if set.
+
+  -client-cert=<path>
+    Path to a PEM encoded client certificate presented to Nomad agents
+    requiring client certificates. Must be set with -client-key.
+    Overrides the NOMAD_CLIENT_CERT environment variable if set.
+
+  -client-key=<path>
+    Path to the PEM encoded private key of the client certificate.
+    Overrides the NOMAD_CLIENT_KEY environment variable if set.
 `
 	return strings.TrimSpace(helpText)
 }
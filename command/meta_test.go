@@ -18,7 +18,7 @@ func TestMeta_FlagSet(t *testing.T) {
 		},
 		{
 			FlagSetClient,
-			[]string{"address"},
+			[]string{"address", "token", "namespace", "ca-cert", "client-cert", "client-key"},
 		},
 	}
 
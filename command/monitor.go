@@ -298,6 +298,9 @@ func dumpAllocStatus(ui cli.Ui, alloc *api.Allocation) {
 	for dim, num := range alloc.Metrics.DimensionExhausted {
 		ui.Output(fmt.Sprintf("  * Dimension %q exhausted on %d nodes", dim, num))
 	}
+	for _, dim := range alloc.Metrics.QuotaExhausted {
+		ui.Output(fmt.Sprintf("  * Quota limit of dimension %q exhausted", dim))
+	}
 
 	// Print scores
 	for name, score := range alloc.Metrics.Scores {
@@ -297,6 +297,7 @@ func TestMonitor_DumpAllocStatus(t *testing.T) {
 			ClassExhausted: map[string]int{
 				"web-large": 1,
 			},
+			QuotaExhausted: []string{"memory"},
 		},
 	}
 	dumpAllocStatus(ui, alloc)
@@ -325,6 +326,9 @@ func TestMonitor_DumpAllocStatus(t *testing.T) {
 	if !strings.Contains(out, `Dimension "cpu" exhausted on 1 nodes`) {
 		t.Fatalf("missing dimension exhaustion\n\n%s", out)
 	}
+	if !strings.Contains(out, `Quota limit of dimension "memory" exhausted`) {
+		t.Fatalf("missing quota exhaustion\n\n%s", out)
+	}
 	ui.OutputWriter.Reset()
 
 	// Dumping alloc status with no eligible nodes adds a warning
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+type NamespaceApplyCommand struct {
+	Meta
+}
+
+func (c *NamespaceApplyCommand) Help() string {
+	helpText := `
+Usage: nomad namespace-apply [options] <name>
+
+  Creates or updates the namespace with the given name. Jobs registered in
+  a namespace are isolated from the jobs of other namespaces. This requires
+  a management token if ACLs are enabled.
+
+General Options:
+
+  ` + generalOptionsUsage() + `
+
+Apply Options:
+
+  -description=<text>
+    Sets the description of the namespace.
+
+  -quota=<name>
+    Attaches the quota specification with the given name to the namespace.
+    The limits of the quota apply to the allocations of the namespace.
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NamespaceApplyCommand) Synopsis() string {
+	return "Create or update a namespace"
+}
+
+func (c *NamespaceApplyCommand) Run(args []string) int {
+	var description, quota string
+
+	flags := c.Meta.FlagSet("namespace-apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+	flags.StringVar(&description, "description", "", "")
+	flags.StringVar(&quota, "quota", "", "")
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the namespace name
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Register the namespace
+	ns := &api.Namespace{
+		Name:        name,
+		Description: description,
+		Quota:       quota,
+	}
+	if _, err := client.Namespaces().Register(ns, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing namespace: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote %q namespace!", name))
+	return 0
+}
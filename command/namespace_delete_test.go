@@ -0,0 +1,76 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestNamespaceDeleteCommand_Implements(t *testing.T) {
+	var _ cli.Command = &NamespaceDeleteCommand{}
+}
+
+func TestNamespaceDeleteCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &NamespaceDeleteCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error deleting namespace") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestNamespaceDeleteCommand_Run(t *testing.T) {
+	srv, _, url := testServer(t, nil)
+	defer srv.Stop()
+
+	// Create a namespace
+	ui := new(cli.MockUi)
+	apply := &NamespaceApplyCommand{Meta: Meta{Ui: ui}}
+	if code := apply.Run([]string{"-address=" + url, "-description=team a", "team-a"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+
+	// It is listed
+	ui = new(cli.MockUi)
+	list := &NamespaceListCommand{Meta: Meta{Ui: ui}}
+	if code := list.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "team-a") || !strings.Contains(out, "team a") {
+		t.Fatalf("expected namespace, got: %s", out)
+	}
+
+	// Delete it
+	ui = new(cli.MockUi)
+	cmd := &NamespaceDeleteCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + url, "team-a"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "Successfully deleted") {
+		t.Fatalf("expected deletion, got: %s", out)
+	}
+
+	// It is no longer listed
+	ui = new(cli.MockUi)
+	list = &NamespaceListCommand{Meta: Meta{Ui: ui}}
+	if code := list.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); strings.Contains(out, "team-a") {
+		t.Fatalf("expected namespace to be deleted, got: %s", out)
+	}
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type NamespaceListCommand struct {
+	Meta
+}
+
+func (c *NamespaceListCommand) Help() string {
+	helpText := `
+Usage: nomad namespace-list [options]
+
+  Displays the list of namespaces.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NamespaceListCommand) Synopsis() string {
+	return "List the namespaces"
+}
+
+func (c *NamespaceListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("namespace-list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check for extra arguments
+	args = flags.Args()
+	if len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the namespaces
+	namespaces, _, err := client.Namespaces().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying namespaces: %s", err))
+		return 1
+	}
+
+	out := make([]string, len(namespaces)+1)
+	out[0] = "Name|Description"
+	for i, ns := range namespaces {
+		out[i+1] = fmt.Sprintf("%s|%s", ns.Name, ns.Description)
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
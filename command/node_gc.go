@@ -0,0 +1,59 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type NodeGCCommand struct {
+	Meta
+}
+
+func (c *NodeGCCommand) Help() string {
+	helpText := `
+Usage: nomad node-gc [options] <node>
+
+  Garbage collects the terminal allocations on a specified node,
+  destroying their allocation directories. The request is sent
+  directly to the client running on the node.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *NodeGCCommand) Synopsis() string {
+	return "Garbage collect terminal allocations on a given node"
+}
+
+func (c *NodeGCCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("node-gc", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got a node ID
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	nodeID := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Garbage collect the node's terminal allocations
+	if err := client.Nodes().GC(nodeID, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error garbage collecting node: %s", err))
+		return 1
+	}
+	return 0
+}
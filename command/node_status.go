@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/hashicorp/nomad/api"
 )
 
 type NodeStatusCommand struct {
@@ -31,6 +33,11 @@ Node Status Options:
   -short
     Display short output. Used only when a single node is being
     queried, and drops verbose output about node allocations.
+
+  -stats
+    Display the latest resource usage of the node's host. Used only
+    when a single node is being queried. The usage is queried from
+    the client running on the node.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -40,11 +47,12 @@ func (c *NodeStatusCommand) Synopsis() string {
 }
 
 func (c *NodeStatusCommand) Run(args []string) int {
-	var short bool
+	var short, stats bool
 
 	flags := c.Meta.FlagSet("node-status", FlagSetClient)
 	flags.Usage = func() { c.Ui.Output(c.Help()) }
 	flags.BoolVar(&short, "short", false, "")
+	flags.BoolVar(&stats, "stats", false, "")
 
 	if err := flags.Parse(args); err != nil {
 		return 1
@@ -152,11 +160,56 @@ func (c *NodeStatusCommand) Run(args []string) int {
 		}
 	}
 
+	var hostStats *api.HostStats
+	if stats {
+		hostStats, err = client.Nodes().Stats(nodeID, nil)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error querying node stats: %s", err))
+			return 1
+		}
+	}
+
 	// Dump the output
 	c.Ui.Output(formatKV(basic))
 	if !short {
 		c.Ui.Output("\n### Allocations")
 		c.Ui.Output(formatList(allocs))
 	}
+	if hostStats != nil {
+		c.printHostStats(hostStats)
+	}
 	return 0
 }
+
+// printHostStats prints out the CPU, memory and disk usage of the host.
+func (c *NodeStatusCommand) printHostStats(hostStats *api.HostStats) {
+	cpus := make([]string, 0, len(hostStats.CPU)+1)
+	cpus = append(cpus, "CPU|User|System|Idle")
+	for _, cpu := range hostStats.CPU {
+		cpus = append(cpus, fmt.Sprintf("%s|%.2f%%|%.2f%%|%.2f%%",
+			cpu.CPU, cpu.User, cpu.System, cpu.Idle))
+	}
+	c.Ui.Output("\n### CPU Utilization")
+	c.Ui.Output(formatList(cpus))
+
+	if mem := hostStats.Memory; mem != nil {
+		memory := []string{
+			"Total|Available|Used|Free",
+			fmt.Sprintf("%s|%s|%s|%s", formatBytes(mem.Total),
+				formatBytes(mem.Available), formatBytes(mem.Used), formatBytes(mem.Free)),
+		}
+		c.Ui.Output("\n### Memory Utilization")
+		c.Ui.Output(formatList(memory))
+	}
+
+	disks := make([]string, 0, len(hostStats.DiskStats)+1)
+	disks = append(disks, "Device|Mountpoint|Size|Used|Available|Used %|Inodes Used %")
+	for _, disk := range hostStats.DiskStats {
+		disks = append(disks, fmt.Sprintf("%s|%s|%s|%s|%s|%.2f%%|%.2f%%",
+			disk.Device, disk.Mountpoint, formatBytes(disk.Size),
+			formatBytes(disk.Used), formatBytes(disk.Available),
+			disk.UsedPercent, disk.InodesUsedPercent))
+	}
+	c.Ui.Output("\n### Disk Utilization")
+	c.Ui.Output(formatList(disks))
+}
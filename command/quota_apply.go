@@ -0,0 +1,135 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/nomad/api"
+)
+
+type QuotaApplyCommand struct {
+	Meta
+}
+
+func (c *QuotaApplyCommand) Help() string {
+	helpText := `
+Usage: nomad quota-apply [options] <name> <path>
+
+  Creates or updates the quota specification with the limits of the HCL file
+  at the given path. The limits are read from stdin if the path is "-". This
+  requires a management token if ACLs are enabled.
+
+  The file lists the limits of each region. Zero or omitted values are
+  unlimited:
+
+      description = "Limits of batch jobs"
+
+      limit {
+        region = "global"
+        cpu    = 20000
+        memory = 40960
+        disk   = 100000
+        allocs = 50
+      }
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *QuotaApplyCommand) Synopsis() string {
+	return "Create or update a quota specification"
+}
+
+func (c *QuotaApplyCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("quota-apply", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the name and path of the quota
+	args = flags.Args()
+	if len(args) != 2 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name, path := args[0], args[1]
+
+	// Read the limits
+	var src []byte
+	var err error
+	if path == "-" {
+		src, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		src, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading quota specification: %s", err))
+		return 1
+	}
+	spec, err := parseQuotaSpec(name, string(src))
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Register the quota
+	if _, err := client.Quotas().Register(spec, nil); err != nil {
+		c.Ui.Error(fmt.Sprintf("Error writing quota specification: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("Successfully wrote %q quota specification!", name))
+	return 0
+}
+
+// quotaFile is the HCL representation of a quota specification
+type quotaFile struct {
+	Description string            `hcl:"description"`
+	Limits      []*quotaFileLimit `hcl:"limit"`
+}
+
+// quotaFileLimit is the HCL representation of the limit of a region
+type quotaFileLimit struct {
+	Region   string `hcl:"region"`
+	CPU      int    `hcl:"cpu"`
+	MemoryMB int    `hcl:"memory"`
+	DiskMB   int    `hcl:"disk"`
+	Allocs   int    `hcl:"allocs"`
+}
+
+// parseQuotaSpec parses the HCL limits of the named quota specification.
+func parseQuotaSpec(name, src string) (*api.QuotaSpec, error) {
+	var file quotaFile
+	if err := hcl.Decode(&file, src); err != nil {
+		return nil, fmt.Errorf("Failed to parse quota specification: %v", err)
+	}
+
+	spec := &api.QuotaSpec{
+		Name:        name,
+		Description: file.Description,
+	}
+	for _, limit := range file.Limits {
+		spec.Limits = append(spec.Limits, &api.QuotaLimit{
+			Region:   limit.Region,
+			CPU:      limit.CPU,
+			MemoryMB: limit.MemoryMB,
+			DiskMB:   limit.DiskMB,
+			Allocs:   limit.Allocs,
+		})
+	}
+	return spec, nil
+}
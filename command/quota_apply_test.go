@@ -0,0 +1,67 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestQuotaApplyCommand_Implements(t *testing.T) {
+	var _ cli.Command = &QuotaApplyCommand{}
+}
+
+func TestQuotaApplyCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &QuotaApplyCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on a missing specification file
+	if code := cmd.Run([]string{"-address=nope", "foo", "/nope/quota.hcl"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error reading quota specification") {
+		t.Fatalf("expected failed read error, got: %s", out)
+	}
+}
+
+func TestQuotaApplyCommand_ParseQuotaSpec(t *testing.T) {
+	src := `
+description = "Limits of batch jobs"
+
+limit {
+  region = "global"
+  cpu    = 2000
+  memory = 1024
+  allocs = 4
+}
+`
+	spec, err := parseQuotaSpec("batch", src)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if spec.Name != "batch" || spec.Description != "Limits of batch jobs" {
+		t.Fatalf("bad: %#v", spec)
+	}
+	if len(spec.Limits) != 1 {
+		t.Fatalf("bad: %#v", spec.Limits)
+	}
+	limit := spec.Limits[0]
+	if limit.Region != "global" || limit.CPU != 2000 || limit.MemoryMB != 1024 ||
+		limit.DiskMB != 0 || limit.Allocs != 4 {
+		t.Fatalf("bad: %#v", limit)
+	}
+
+	// Fails on invalid HCL
+	if _, err := parseQuotaSpec("batch", "limit {"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestQuotaDeleteCommand_Implements(t *testing.T) {
+	var _ cli.Command = &QuotaDeleteCommand{}
+}
+
+func TestQuotaDeleteCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &QuotaDeleteCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error deleting quota specification") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestQuotaDeleteCommand_Run(t *testing.T) {
+	srv, _, url := testServer(t, nil)
+	defer srv.Stop()
+
+	// Create a quota specification
+	fh, err := ioutil.TempFile("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Remove(fh.Name())
+	_, err = fh.WriteString(`
+description = "small"
+limit {
+	region = "global"
+	cpu    = 1000
+	memory = 1024
+}
+`)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	fh.Close()
+
+	ui := new(cli.MockUi)
+	apply := &QuotaApplyCommand{Meta: Meta{Ui: ui}}
+	if code := apply.Run([]string{"-address=" + url, "small", fh.Name()}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+
+	// It is listed
+	ui = new(cli.MockUi)
+	list := &QuotaListCommand{Meta: Meta{Ui: ui}}
+	if code := list.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "small") {
+		t.Fatalf("expected quota specification, got: %s", out)
+	}
+
+	// Delete it
+	ui = new(cli.MockUi)
+	cmd := &QuotaDeleteCommand{Meta: Meta{Ui: ui}}
+	if code := cmd.Run([]string{"-address=" + url, "small"}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); !strings.Contains(out, "Successfully deleted") {
+		t.Fatalf("expected deletion, got: %s", out)
+	}
+
+	// It is no longer listed
+	ui = new(cli.MockUi)
+	list = &QuotaListCommand{Meta: Meta{Ui: ui}}
+	if code := list.Run([]string{"-address=" + url}); code != 0 {
+		t.Fatalf("expected exit 0, got: %d; %s", code, ui.ErrorWriter.String())
+	}
+	if out := ui.OutputWriter.String(); strings.Contains(out, "small") {
+		t.Fatalf("expected quota specification to be deleted, got: %s", out)
+	}
+}
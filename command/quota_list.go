@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type QuotaListCommand struct {
+	Meta
+}
+
+func (c *QuotaListCommand) Help() string {
+	helpText := `
+Usage: nomad quota-list [options]
+
+  Displays the list of quota specifications.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *QuotaListCommand) Synopsis() string {
+	return "List the quota specifications"
+}
+
+func (c *QuotaListCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("quota-list", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check for extra arguments
+	args = flags.Args()
+	if len(args) != 0 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the quotas
+	quotas, _, err := client.Quotas().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying quota specifications: %s", err))
+		return 1
+	}
+
+	out := make([]string, len(quotas)+1)
+	out[0] = "Name|Description"
+	for i, quota := range quotas {
+		out[i+1] = fmt.Sprintf("%s|%s", quota.Name, quota.Description)
+	}
+	c.Ui.Output(formatList(out))
+	return 0
+}
@@ -0,0 +1,120 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+type QuotaStatusCommand struct {
+	Meta
+}
+
+func (c *QuotaStatusCommand) Help() string {
+	helpText := `
+Usage: nomad quota-status [options] <name>
+
+  Displays the limits of the quota specification with the given name and
+  the resources used by each namespace the quota is attached to. Usage is
+  only reported for the region of the queried server.
+
+General Options:
+
+  ` + generalOptionsUsage()
+	return strings.TrimSpace(helpText)
+}
+
+func (c *QuotaStatusCommand) Synopsis() string {
+	return "Display the limits and usage of a quota"
+}
+
+func (c *QuotaStatusCommand) Run(args []string) int {
+	flags := c.Meta.FlagSet("quota-status", FlagSetClient)
+	flags.Usage = func() { c.Ui.Output(c.Help()) }
+
+	if err := flags.Parse(args); err != nil {
+		return 1
+	}
+
+	// Check that we got the quota name
+	args = flags.Args()
+	if len(args) != 1 {
+		c.Ui.Error(c.Help())
+		return 1
+	}
+	name := args[0]
+
+	// Get the HTTP client
+	client, err := c.Meta.Client()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error initializing client: %s", err))
+		return 1
+	}
+
+	// Query the quota
+	quota, _, err := client.Quotas().Info(name, nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying quota specification: %s", err))
+		return 1
+	}
+
+	// Query the namespaces the quota is attached to and their usage
+	namespaces, _, err := client.Namespaces().List(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying namespaces: %s", err))
+		return 1
+	}
+	usages, _, err := client.Quotas().Usages(nil)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error querying quota usage: %s", err))
+		return 1
+	}
+
+	basic := []string{
+		fmt.Sprintf("Name|%s", quota.Name),
+		fmt.Sprintf("Description|%s", quota.Description),
+		fmt.Sprintf("CreateIndex|%d", quota.CreateIndex),
+		fmt.Sprintf("ModifyIndex|%d", quota.ModifyIndex),
+	}
+	c.Ui.Output(formatKV(basic))
+
+	limits := make([]string, len(quota.Limits)+1)
+	limits[0] = "Region|CPU (MHz)|Memory (MB)|Disk (MB)|Allocs"
+	for i, limit := range quota.Limits {
+		limits[i+1] = fmt.Sprintf("%s|%s|%s|%s|%s",
+			limit.Region,
+			formatQuotaLimit(limit.CPU),
+			formatQuotaLimit(limit.MemoryMB),
+			formatQuotaLimit(limit.DiskMB),
+			formatQuotaLimit(limit.Allocs))
+	}
+	c.Ui.Output("\n==> Limits")
+	c.Ui.Output(formatList(limits))
+
+	used := []string{"Namespace|CPU (MHz)|Memory (MB)|Disk (MB)|Allocs"}
+	for _, ns := range namespaces {
+		if ns.Quota != quota.Name {
+			continue
+		}
+		var cpu, memory, disk, allocs int
+		for _, usage := range usages {
+			if usage.Namespace == ns.Name {
+				cpu, memory, disk, allocs = usage.CPU, usage.MemoryMB, usage.DiskMB, usage.Allocs
+				break
+			}
+		}
+		used = append(used, fmt.Sprintf("%s|%d|%d|%d|%d",
+			ns.Name, cpu, memory, disk, allocs))
+	}
+	c.Ui.Output("\n==> Usage")
+	c.Ui.Output(formatList(used))
+	return 0
+}
+
+// formatQuotaLimit formats the value of a quota limit, where zero means
+// unlimited.
+func formatQuotaLimit(value int) string {
+	if value == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d", value)
+}
@@ -0,0 +1,43 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestQuotaStatusCommand_Implements(t *testing.T) {
+	var _ cli.Command = &QuotaStatusCommand{}
+}
+
+func TestQuotaStatusCommand_Fails(t *testing.T) {
+	ui := new(cli.MockUi)
+	cmd := &QuotaStatusCommand{Meta: Meta{Ui: ui}}
+
+	// Fails on misuse
+	if code := cmd.Run([]string{"some", "bad", "args"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, cmd.Help()) {
+		t.Fatalf("expected help output, got: %s", out)
+	}
+	ui.ErrorWriter.Reset()
+
+	// Fails on connection failure
+	if code := cmd.Run([]string{"-address=nope", "nope"}); code != 1 {
+		t.Fatalf("expected exit code 1, got: %d", code)
+	}
+	if out := ui.ErrorWriter.String(); !strings.Contains(out, "Error querying quota specification") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+}
+
+func TestQuotaStatusCommand_FormatQuotaLimit(t *testing.T) {
+	if out := formatQuotaLimit(0); out != "unlimited" {
+		t.Fatalf("bad: %s", out)
+	}
+	if out := formatQuotaLimit(1024); out != "1024" {
+		t.Fatalf("bad: %s", out)
+	}
+}
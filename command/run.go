@@ -104,11 +104,20 @@ func (c *RunCommand) Run(args []string) int {
 	}
 
 	// Submit the job
-	evalID, _, err := client.Jobs().Register(apiJob, nil)
+	resp, _, err := client.Jobs().RegisterWithWarnings(apiJob, nil)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error submitting job: %s", err))
 		return 1
 	}
+	evalID := resp.EvalID
+
+	// Print the warnings of the admission controllers
+	if resp.Warnings != "" {
+		c.Ui.Warn("Job Warnings:")
+		for _, warning := range strings.Split(resp.Warnings, "\n") {
+			c.Ui.Warn(fmt.Sprintf("  * %s", warning))
+		}
+	}
 
 	// Check if we should enter monitor mode
 	if detach {
@@ -1,4 +0,0 @@
-package command
-
-// No chroot on darwin.
-func (c *SpawnDaemonCommand) configureChroot() {}
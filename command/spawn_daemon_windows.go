@@ -1,7 +0,0 @@
-// build !linux !darwin
-
-package command
-
-// No isolation on Windows.
-func (c *SpawnDaemonCommand) isolateCmd() error { return nil }
-func (c *SpawnDaemonCommand) configureChroot()  {}
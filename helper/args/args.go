@@ -3,7 +3,9 @@ package args
 import "regexp"
 
 var (
-	envRe = regexp.MustCompile(`\$({[a-zA-Z0-9_]+}|[a-zA-Z0-9_]+)`)
+	// Variables in braces may contain dots and dashes, such as
+	// ${attr.kernel.name}.
+	envRe = regexp.MustCompile(`\$({[a-zA-Z0-9_.\-]+}|[a-zA-Z0-9_]+)`)
 )
 
 // ParseAndReplace takes the user supplied args and a map of environment
@@ -49,3 +49,17 @@ func TestDriverArgs_ParseAndReplaceChainedEnv(t *testing.T) {
 		t.Fatalf("ParseAndReplace(%v, %v) returned %#v; want %#v", input, envVars, act, exp)
 	}
 }
+
+func TestDriverArgs_ParseAndReplaceDottedEnv(t *testing.T) {
+	env := map[string]string{
+		"attr.kernel.name": "linux",
+		"node.unique.id":   "12345",
+	}
+	input := []string{"${attr.kernel.name}", "${node.unique.id}-$attr.kernel.name"}
+	exp := []string{"linux", "12345-$attr.kernel.name"}
+	act := ParseAndReplace(input, env)
+
+	if !reflect.DeepEqual(act, exp) {
+		t.Fatalf("ParseAndReplace(%v, %v) returned %#v; want %#v", input, env, act, exp)
+	}
+}
@@ -0,0 +1,309 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"sync"
+	"time"
+)
+
+// RegionWrapper is a function that is used to wrap a non-TLS connection and
+// returns an appropriate TLS connection or error. This takes a region as an
+// argument so the server name of the region can be verified.
+type RegionWrapper func(region string, conn net.Conn) (net.Conn, error)
+
+// Wrapper is a variant of RegionWrapper that has the region already bound.
+type Wrapper func(conn net.Conn) (net.Conn, error)
+
+// Config used to create tls.Config
+type Config struct {
+	// VerifyIncoming is used to verify the authenticity of incoming
+	// connections. This means that TCP requests are forbidden, only allowing
+	// for TLS. TLS connections must match a provided certificate authority.
+	VerifyIncoming bool
+
+	// VerifyOutgoing is used to verify the authenticity of outgoing
+	// connections. This means that TLS requests are used, and TCP requests
+	// are not made. TLS connections must match a provided certificate
+	// authority.
+	VerifyOutgoing bool
+
+	// VerifyServerHostname is used to enable hostname verification of
+	// servers. This ensures that the certificate presented is valid for
+	// server.<region>.nomad. This prevents a compromised client from being
+	// restarted as a server, and then intercepting request traffic as well
+	// as being added as a raft peer.
+	VerifyServerHostname bool
+
+	// CAFile is a path to a certificate authority file. This is used with
+	// VerifyIncoming or VerifyOutgoing to verify the TLS connection.
+	CAFile string
+
+	// CertFile is used to provide a TLS certificate that is used for serving
+	// TLS connections. Must be provided to serve TLS connections.
+	CertFile string
+
+	// KeyFile is used to provide a TLS key that is used for serving TLS
+	// connections. Must be provided to serve TLS connections.
+	KeyFile string
+
+	// KeyLoader holds the certificate so it can be reloaded without
+	// recreating the TLS configurations built from this config.
+	KeyLoader *KeyLoader
+}
+
+// NewConfig returns a config with a key loader for the given files.
+func NewConfig(caFile, certFile, keyFile string) *Config {
+	return &Config{
+		CAFile:    caFile,
+		CertFile:  certFile,
+		KeyFile:   keyFile,
+		KeyLoader: &KeyLoader{},
+	}
+}
+
+// ServerName returns the name servers of the region must have in their
+// certificates.
+func ServerName(region string) string {
+	return fmt.Sprintf("server.%s.nomad", region)
+}
+
+// ClientName returns the name clients of the region must have in their
+// certificates when they serve their HTTP API to other clients.
+func ClientName(region string) string {
+	return fmt.Sprintf("client.%s.nomad", region)
+}
+
+// AppendCA opens and parses the CA file and adds the certificates to the
+// provided CertPool.
+func (c *Config) AppendCA(pool *x509.CertPool) error {
+	if c.CAFile == "" {
+		return nil
+	}
+
+	// Read the file
+	data, err := ioutil.ReadFile(c.CAFile)
+	if err != nil {
+		return fmt.Errorf("Failed to read CA file: %v", err)
+	}
+
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("Failed to parse any CA certificates")
+	}
+
+	return nil
+}
+
+// LoadKeyPair is used to load the certificate and key files. A nil
+// certificate is returned if they are not set.
+func (c *Config) LoadKeyPair() (*tls.Certificate, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, nil
+	}
+	if c.KeyLoader == nil {
+		c.KeyLoader = &KeyLoader{}
+	}
+	return c.KeyLoader.LoadKeyPair(c.CertFile, c.KeyFile)
+}
+
+// OutgoingTLSConfig generates a TLS configuration for outgoing requests. It
+// will return a nil config if this configuration should not use TLS for
+// outgoing connections.
+func (c *Config) OutgoingTLSConfig() (*tls.Config, error) {
+	if !c.VerifyOutgoing {
+		return nil, nil
+	}
+	if c.CAFile == "" {
+		return nil, fmt.Errorf("VerifyOutgoing set, and no CA certificate provided!")
+	}
+
+	// Create the tlsConfig. Servers are verified against the CA but their
+	// hostname is only checked if VerifyServerHostname is set, see
+	// WrapTLSClient.
+	tlsConfig := &tls.Config{
+		RootCAs:            x509.NewCertPool(),
+		InsecureSkipVerify: !c.VerifyServerHostname,
+	}
+	if err := c.AppendCA(tlsConfig.RootCAs); err != nil {
+		return nil, err
+	}
+
+	// Add cert/key
+	cert, err := c.LoadKeyPair()
+	if err != nil {
+		return nil, err
+	} else if cert != nil {
+		tlsConfig.GetClientCertificate = c.KeyLoader.GetClientCertificate
+	}
+
+	return tlsConfig, nil
+}
+
+// OutgoingTLSWrapper returns a RegionWrapper based on the OutgoingTLS
+// configuration. If hostname verification is on, the wrapper will properly
+// generate the dynamic server name for the region. A nil wrapper is returned
+// if TLS is not used for outgoing connections.
+func (c *Config) OutgoingTLSWrapper() (RegionWrapper, error) {
+	// Get the TLS config
+	tlsConfig, err := c.OutgoingTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check if TLS is not enabled
+	if tlsConfig == nil {
+		return nil, nil
+	}
+
+	// Generate the wrapper based on hostname verification
+	wrapper := func(region string, conn net.Conn) (net.Conn, error) {
+		conf := tlsConfig.Clone()
+		if c.VerifyServerHostname {
+			conf.ServerName = ServerName(region)
+		}
+		return WrapTLSClient(conn, conf)
+	}
+	return wrapper, nil
+}
+
+// SpecificRegion is used to invoke a static region and turns a RegionWrapper
+// into a Wrapper type.
+func SpecificRegion(region string, tlsWrap RegionWrapper) Wrapper {
+	if tlsWrap == nil {
+		return nil
+	}
+	return func(conn net.Conn) (net.Conn, error) {
+		return tlsWrap(region, conn)
+	}
+}
+
+// WrapTLSClient wraps a net.Conn into a client tls connection, performing
+// any additional verification as needed.
+//
+// As of go 1.3, crypto/tls only supports either doing no certificate
+// verification, or doing full verification including of the peer's DNS
+// name. Certificates of servers are only checked against the CA when their
+// hostname is not verified, so this function implements that verification
+// after the handshake.
+func WrapTLSClient(conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
+	var err error
+	var tlsConn *tls.Conn
+
+	tlsConn = tls.Client(conn, tlsConfig)
+
+	// If crypto/tls is doing verification, there's no need to do
+	// our own.
+	if !tlsConfig.InsecureSkipVerify {
+		return tlsConn, nil
+	}
+
+	if err = tlsConn.Handshake(); err != nil {
+		tlsConn.Close()
+		return nil, err
+	}
+
+	// The following is lightly-modified from the doFullHandshake
+	// method in crypto/tls's handshake_client.go.
+	opts := x509.VerifyOptions{
+		Roots:         tlsConfig.RootCAs,
+		CurrentTime:   time.Now(),
+		DNSName:       "",
+		Intermediates: x509.NewCertPool(),
+	}
+
+	certs := tlsConn.ConnectionState().PeerCertificates
+	for i, cert := range certs {
+		if i == 0 {
+			continue
+		}
+		opts.Intermediates.AddCert(cert)
+	}
+
+	_, err = certs[0].Verify(opts)
+	if err != nil {
+		tlsConn.Close()
+		return nil, err
+	}
+
+	return tlsConn, err
+}
+
+// IncomingTLSConfig generates a TLS configuration for incoming requests
+func (c *Config) IncomingTLSConfig() (*tls.Config, error) {
+	// Create the tlsConfig
+	tlsConfig := &tls.Config{
+		ClientCAs:  x509.NewCertPool(),
+		ClientAuth: tls.NoClientCert,
+	}
+	if err := c.AppendCA(tlsConfig.ClientCAs); err != nil {
+		return nil, err
+	}
+
+	// Add cert/key
+	cert, err := c.LoadKeyPair()
+	if err != nil {
+		return nil, err
+	} else if cert != nil {
+		tlsConfig.GetCertificate = c.KeyLoader.GetCertificate
+	}
+
+	// Check if we require verification
+	if c.VerifyIncoming {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		if c.CAFile == "" {
+			return nil, fmt.Errorf("VerifyIncoming set, and no CA certificate provided!")
+		}
+		if cert == nil {
+			return nil, fmt.Errorf("VerifyIncoming set, and no Cert/Key pair provided!")
+		}
+	}
+
+	return tlsConfig, nil
+}
+
+// KeyLoader holds a certificate that is served by the TLS configurations
+// built from a Config. Loading a new key pair changes the certificate used
+// by new connections.
+type KeyLoader struct {
+	cacheLock   sync.Mutex
+	certificate *tls.Certificate
+}
+
+// LoadKeyPair loads the certificate and key files, replacing the certificate
+// of the loader.
+func (k *KeyLoader) LoadKeyPair(certFile, keyFile string) (*tls.Certificate, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load cert/key pair: %v", err)
+	}
+
+	k.cacheLock.Lock()
+	defer k.cacheLock.Unlock()
+	k.certificate = &cert
+	return k.certificate, nil
+}
+
+// GetCertificate returns the loaded certificate. It implements the
+// GetCertificate callback of tls.Config.
+func (k *KeyLoader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.cacheLock.Lock()
+	defer k.cacheLock.Unlock()
+	if k.certificate == nil {
+		return nil, fmt.Errorf("no certificate loaded")
+	}
+	return k.certificate, nil
+}
+
+// GetClientCertificate returns the loaded certificate. It implements the
+// GetClientCertificate callback of tls.Config.
+func (k *KeyLoader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	k.cacheLock.Lock()
+	defer k.cacheLock.Unlock()
+	if k.certificate == nil {
+		return &tls.Certificate{}, nil
+	}
+	return k.certificate, nil
+}
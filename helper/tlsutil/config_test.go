@@ -0,0 +1,245 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"testing"
+)
+
+const (
+	testCA         = "./test-resources/ca.pem"
+	testServerCert = "./test-resources/server.pem"
+	testServerKey  = "./test-resources/server-key.pem"
+	testOtherCert  = "./test-resources/server-other.pem"
+	testOtherKey   = "./test-resources/server-other-key.pem"
+	testClientCert = "./test-resources/client.pem"
+	testClientKey  = "./test-resources/client-key.pem"
+)
+
+func TestConfig_AppendCA(t *testing.T) {
+	pool := x509.NewCertPool()
+
+	conf := &Config{}
+	if err := conf.AppendCA(pool); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	conf.CAFile = "./test-resources/nope.pem"
+	if err := conf.AppendCA(pool); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	conf.CAFile = testServerKey
+	if err := conf.AppendCA(pool); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	conf.CAFile = testCA
+	if err := conf.AppendCA(pool); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(pool.Subjects()) != 1 {
+		t.Fatalf("expected the CA in the pool")
+	}
+}
+
+func TestConfig_LoadKeyPair(t *testing.T) {
+	conf := &Config{}
+	cert, err := conf.LoadKeyPair()
+	if err != nil || cert != nil {
+		t.Fatalf("expected no cert; got %v, err: %v", cert, err)
+	}
+
+	conf = &Config{CertFile: testServerCert, KeyFile: testClientKey}
+	if _, err := conf.LoadKeyPair(); err == nil {
+		t.Fatalf("expected error on mismatched key")
+	}
+
+	conf = NewConfig(testCA, testServerCert, testServerKey)
+	cert, err = conf.LoadKeyPair()
+	if err != nil || cert == nil {
+		t.Fatalf("expected cert; got %v, err: %v", cert, err)
+	}
+}
+
+func TestConfig_OutgoingTLS(t *testing.T) {
+	// No TLS without outgoing verification
+	conf := NewConfig(testCA, testClientCert, testClientKey)
+	tlsConf, err := conf.OutgoingTLSConfig()
+	if err != nil || tlsConf != nil {
+		t.Fatalf("expected no config; got %v, err: %v", tlsConf, err)
+	}
+	wrap, err := conf.OutgoingTLSWrapper()
+	if err != nil || wrap != nil {
+		t.Fatalf("expected no wrapper; err: %v", err)
+	}
+
+	// A CA is required
+	conf = &Config{VerifyOutgoing: true}
+	if _, err := conf.OutgoingTLSConfig(); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	conf = NewConfig(testCA, testClientCert, testClientKey)
+	conf.VerifyOutgoing = true
+	tlsConf, err = conf.OutgoingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !tlsConf.InsecureSkipVerify {
+		t.Fatalf("expected hostname to not be verified")
+	}
+	if tlsConf.GetClientCertificate == nil {
+		t.Fatalf("expected client certificate")
+	}
+
+	conf.VerifyServerHostname = true
+	tlsConf, err = conf.OutgoingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if tlsConf.InsecureSkipVerify {
+		t.Fatalf("expected hostname to be verified")
+	}
+}
+
+func TestConfig_IncomingTLS(t *testing.T) {
+	conf := NewConfig(testCA, testServerCert, testServerKey)
+	tlsConf, err := conf.IncomingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if tlsConf.ClientAuth != tls.NoClientCert {
+		t.Fatalf("bad client auth: %v", tlsConf.ClientAuth)
+	}
+	if tlsConf.GetCertificate == nil {
+		t.Fatalf("expected certificate")
+	}
+
+	conf.VerifyIncoming = true
+	tlsConf, err = conf.IncomingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if tlsConf.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Fatalf("bad client auth: %v", tlsConf.ClientAuth)
+	}
+
+	// Verifying clients requires a CA and a certificate
+	conf = &Config{VerifyIncoming: true, CertFile: testServerCert, KeyFile: testServerKey}
+	if _, err := conf.IncomingTLSConfig(); err == nil {
+		t.Fatalf("expected error")
+	}
+	conf = &Config{VerifyIncoming: true, CAFile: testCA}
+	if _, err := conf.IncomingTLSConfig(); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+// testHandshake runs a TLS server with the config over a pipe and returns
+// the result of wrapping the client side of the pipe.
+func testHandshake(t *testing.T, server *Config, wrap Wrapper) error {
+	serverConf, err := server.IncomingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	client, serverConn := net.Pipe()
+	errCh := make(chan error, 1)
+	go func() {
+		tlsConn := tls.Server(serverConn, serverConf)
+		err := tlsConn.Handshake()
+		if err == nil {
+			// Echo a byte so the client completes the handshake
+			buf := make([]byte, 1)
+			if _, err = io.ReadFull(tlsConn, buf); err == nil {
+				_, err = tlsConn.Write(buf)
+			}
+		}
+		serverConn.Close()
+		errCh <- err
+	}()
+
+	conn, err := wrap(client)
+	if err != nil {
+		client.Close()
+		<-errCh
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte{1}); err != nil {
+		return err
+	}
+	buf := make([]byte, 1)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func TestConfig_OutgoingTLSWrapper_VerifyServerHostname(t *testing.T) {
+	server := NewConfig(testCA, testServerCert, testServerKey)
+	server.VerifyIncoming = true
+
+	client := NewConfig(testCA, testClientCert, testClientKey)
+	client.VerifyOutgoing = true
+	client.VerifyServerHostname = true
+	wrap, err := client.OutgoingTLSWrapper()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	if err := testHandshake(t, server, SpecificRegion("global", wrap)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := testHandshake(t, server, SpecificRegion("other", wrap)); err == nil {
+		t.Fatalf("expected hostname verification to fail")
+	}
+
+	// Without hostname verification only the CA is checked
+	client.VerifyServerHostname = false
+	wrap, err = client.OutgoingTLSWrapper()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := testHandshake(t, server, SpecificRegion("other", wrap)); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestKeyLoader_Reload(t *testing.T) {
+	server := NewConfig(testCA, testServerCert, testServerKey)
+
+	client := NewConfig(testCA, testClientCert, testClientKey)
+	client.VerifyOutgoing = true
+	client.VerifyServerHostname = true
+	wrap, err := client.OutgoingTLSWrapper()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := testHandshake(t, server, SpecificRegion("other", wrap)); err == nil {
+		t.Fatalf("expected hostname verification to fail")
+	}
+
+	// Reloading the certificate is picked up by new connections
+	serverConf, err := server.IncomingTLSConfig()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, err := server.KeyLoader.LoadKeyPair(testOtherCert, testOtherKey); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	cert, err := serverConf.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	parsed, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if parsed.Subject.CommonName != "server.other.nomad" {
+		t.Fatalf("bad certificate: %s", parsed.Subject.CommonName)
+	}
+}
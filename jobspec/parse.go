@@ -210,6 +210,8 @@ func parseGroups(result *structs.Job, list *ast.ObjectList) error {
 		delete(m, "meta")
 		delete(m, "task")
 		delete(m, "restart")
+		delete(m, "ephemeral_disk")
+		delete(m, "volume")
 
 		// Default count to 1 if not specified
 		if _, ok := m["count"]; !ok {
@@ -237,6 +239,20 @@ func parseGroups(result *structs.Job, list *ast.ObjectList) error {
 			}
 		}
 
+		// Parse ephemeral disk
+		if o := listVal.Filter("ephemeral_disk"); len(o.Items) > 0 {
+			if err := parseEphemeralDisk(&g.EphemeralDisk, o); err != nil {
+				return err
+			}
+		}
+
+		// Parse volumes
+		if o := listVal.Filter("volume"); len(o.Items) > 0 {
+			if err := parseVolumes(&g.Volumes, o); err != nil {
+				return fmt.Errorf("group '%s': %s", n, err)
+			}
+		}
+
 		// Parse out meta fields. These are in HCL as a list so we need
 		// to iterate over them and merge them.
 		if metaO := listVal.Filter("meta"); len(metaO.Items) > 0 {
@@ -296,6 +312,78 @@ func parseRestartPolicy(final **structs.RestartPolicy, list *ast.ObjectList) err
 	return nil
 }
 
+func parseEphemeralDisk(final **structs.EphemeralDisk, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'ephemeral_disk' block allowed")
+	}
+
+	// Get our ephemeral disk object
+	obj := list.Items[0]
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
+		return err
+	}
+
+	// Start from the defaults so that only the size can be omitted
+	result := structs.DefaultEphemeralDisk()
+	if err := mapstructure.WeakDecode(m, result); err != nil {
+		return err
+	}
+
+	*final = result
+	return nil
+}
+
+func parseVolumes(result *map[string]*structs.VolumeRequest, list *ast.ObjectList) error {
+	list = list.Children()
+	if len(list.Items) == 0 {
+		return nil
+	}
+
+	volumes := make(map[string]*structs.VolumeRequest, len(list.Items))
+	for _, item := range list.Items {
+		n := item.Keys[0].Token.Value().(string)
+
+		// Make sure we haven't already found this
+		if _, ok := volumes[n]; ok {
+			return fmt.Errorf("volume '%s' defined more than once", n)
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return err
+		}
+
+		// Host volumes are the only type of volumes so default to them
+		v := &structs.VolumeRequest{Name: n, Type: structs.VolumeTypeHost}
+		if err := mapstructure.WeakDecode(m, v); err != nil {
+			return err
+		}
+		volumes[n] = v
+	}
+
+	*result = volumes
+	return nil
+}
+
+func parseVolumeMounts(result *[]*structs.VolumeMount, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+
+		var vm structs.VolumeMount
+		if err := mapstructure.WeakDecode(m, &vm); err != nil {
+			return err
+		}
+		*result = append(*result, &vm)
+	}
+	return nil
+}
+
 func parseConstraints(result *[]*structs.Constraint, list *ast.ObjectList) error {
 	for _, o := range list.Elem().Items {
 		var m map[string]interface{}
@@ -401,6 +489,11 @@ func parseTasks(jobName string, taskGroupName string, result *[]*structs.Task, l
 		delete(m, "service")
 		delete(m, "meta")
 		delete(m, "resources")
+		delete(m, "lifecycle")
+		delete(m, "artifact")
+		delete(m, "template")
+		delete(m, "secret")
+		delete(m, "volume_mount")
 
 		// Build the task
 		var t structs.Task
@@ -476,6 +569,41 @@ func parseTasks(jobName string, taskGroupName string, result *[]*structs.Task, l
 			t.Resources = &r
 		}
 
+		// If we have a lifecycle, then parse that
+		if o := listVal.Filter("lifecycle"); len(o.Items) > 0 {
+			if err := parseLifecycle(&t.Lifecycle, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// Parse artifacts
+		if o := listVal.Filter("artifact"); len(o.Items) > 0 {
+			if err := parseArtifacts(&t.Artifacts, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// Parse templates
+		if o := listVal.Filter("template"); len(o.Items) > 0 {
+			if err := parseTemplates(&t.Templates, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// Parse secrets
+		if o := listVal.Filter("secret"); len(o.Items) > 0 {
+			if err := parseSecrets(&t.Secrets, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
+		// Parse volume mounts
+		if o := listVal.Filter("volume_mount"); len(o.Items) > 0 {
+			if err := parseVolumeMounts(&t.VolumeMounts, o); err != nil {
+				return fmt.Errorf("task '%s': %s", t.Name, err)
+			}
+		}
+
 		*result = append(*result, &t)
 	}
 
@@ -553,6 +681,100 @@ func parseChecks(service *structs.Service, checkObjs *ast.ObjectList) error {
 	return nil
 }
 
+func parseLifecycle(final **structs.TaskLifecycleConfig, list *ast.ObjectList) error {
+	list = list.Elem()
+	if len(list.Items) > 1 {
+		return fmt.Errorf("only one 'lifecycle' block allowed per task")
+	}
+
+	// Get our lifecycle object
+	obj := list.Items[0]
+
+	var m map[string]interface{}
+	if err := hcl.DecodeObject(&m, obj.Val); err != nil {
+		return err
+	}
+
+	var result structs.TaskLifecycleConfig
+	if err := mapstructure.WeakDecode(m, &result); err != nil {
+		return err
+	}
+	*final = &result
+	return nil
+}
+
+func parseArtifacts(result *[]*structs.TaskArtifact, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		// We need this later
+		var listVal *ast.ObjectList
+		if ot, ok := o.Val.(*ast.ObjectType); ok {
+			listVal = ot.List
+		} else {
+			return fmt.Errorf("artifact should be an object")
+		}
+
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+		delete(m, "options")
+
+		var ta structs.TaskArtifact
+		if err := mapstructure.WeakDecode(m, &ta); err != nil {
+			return err
+		}
+
+		// Parse out the getter options. These are in HCL as a list so we
+		// need to iterate over them and merge them.
+		if optionsO := listVal.Filter("options"); len(optionsO.Items) > 0 {
+			for _, o := range optionsO.Elem().Items {
+				var m map[string]interface{}
+				if err := hcl.DecodeObject(&m, o.Val); err != nil {
+					return err
+				}
+				if err := mapstructure.WeakDecode(m, &ta.GetterOptions); err != nil {
+					return err
+				}
+			}
+		}
+
+		*result = append(*result, &ta)
+	}
+	return nil
+}
+
+func parseTemplates(result *[]*structs.Template, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+
+		var tmpl structs.Template
+		if err := mapstructure.WeakDecode(m, &tmpl); err != nil {
+			return err
+		}
+		*result = append(*result, &tmpl)
+	}
+	return nil
+}
+
+func parseSecrets(result *[]*structs.Secret, list *ast.ObjectList) error {
+	for _, o := range list.Elem().Items {
+		var m map[string]interface{}
+		if err := hcl.DecodeObject(&m, o.Val); err != nil {
+			return err
+		}
+
+		var secret structs.Secret
+		if err := mapstructure.WeakDecode(m, &secret); err != nil {
+			return err
+		}
+		*result = append(*result, &secret)
+	}
+	return nil
+}
+
 func parseResources(result *structs.Resources, list *ast.ObjectList) error {
 	list = list.Elem()
 	if len(list.Items) == 0 {
@@ -579,6 +801,11 @@ func parseResources(result *structs.Resources, list *ast.ObjectList) error {
 	}
 	delete(m, "network")
 
+	// Disk is shared by the tasks of a group
+	if _, ok := m["disk"]; ok {
+		return fmt.Errorf("resource: disk should be requested in the group's 'ephemeral_disk' block")
+	}
+
 	if err := mapstructure.WeakDecode(m, result); err != nil {
 		return err
 	}
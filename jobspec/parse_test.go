@@ -84,6 +84,11 @@ func TestParse(t *testing.T) {
 							RestartOnSuccess: true,
 							Mode:             "delay",
 						},
+						EphemeralDisk: &structs.EphemeralDisk{
+							Sticky:  true,
+							Migrate: true,
+							SizeMB:  150,
+						},
 						Tasks: []*structs.Task{
 							&structs.Task{
 								Name:   "binstore",
@@ -159,6 +164,12 @@ func TestParse(t *testing.T) {
 			true,
 		},
 
+		{
+			"task-disk.hcl",
+			nil,
+			true,
+		},
+
 		{
 			"default-job.hcl",
 			&structs.Job{
@@ -255,6 +266,19 @@ func TestParse(t *testing.T) {
 			false,
 		},
 
+		{
+			"job-namespace.hcl",
+			&structs.Job{
+				ID:        "foo",
+				Name:      "foo",
+				Namespace: "team-a",
+				Priority:  50,
+				Region:    "global",
+				Type:      "service",
+			},
+			false,
+		},
+
 		{
 			"task-nested-config.hcl",
 			&structs.Job{
@@ -286,6 +310,211 @@ func TestParse(t *testing.T) {
 			},
 			false,
 		},
+
+		{
+			"task-artifacts.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "web",
+								Driver: "exec",
+								Artifacts: []*structs.TaskArtifact{
+									&structs.TaskArtifact{
+										GetterSource: "http://foo.com/bar.tar.gz",
+										RelativeDest: "local/bar",
+										GetterOptions: map[string]string{
+											"checksum": "md5:ff1cc0d3432dad54d607c1505fb7245c",
+										},
+									},
+									&structs.TaskArtifact{
+										GetterSource: "http://foo.com/app",
+										RelativeDest: "local/bin/app",
+										Mode:         "file",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"task-lifecycle.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "migrate",
+								Driver: "exec",
+								Lifecycle: &structs.TaskLifecycleConfig{
+									Hook: "prestart",
+								},
+							},
+							&structs.Task{
+								Name:   "proxy",
+								Driver: "exec",
+								Lifecycle: &structs.TaskLifecycleConfig{
+									Hook:    "prestart",
+									Sidecar: true,
+								},
+							},
+							&structs.Task{
+								Name:   "web",
+								Driver: "exec",
+								Leader: true,
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"task-templates.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "web",
+								Driver: "exec",
+								Templates: []*structs.Template{
+									&structs.Template{
+										SourcePath:   "local/bar/app.conf.tpl",
+										DestPath:     "app.conf",
+										ChangeMode:   "signal",
+										ChangeSignal: "SIGHUP",
+									},
+									&structs.Template{
+										EmbeddedTmpl: "{{.Env.NOMAD_IP}}",
+										DestPath:     "ip",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"task-volumes.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Volumes: map[string]*structs.VolumeRequest{
+							"certs": &structs.VolumeRequest{
+								Name:     "certs",
+								Type:     "host",
+								Source:   "ca-certificates",
+								ReadOnly: true,
+							},
+							"data": &structs.VolumeRequest{
+								Name:   "data",
+								Type:   "host",
+								Source: "data",
+							},
+						},
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "web",
+								Driver: "exec",
+								VolumeMounts: []*structs.VolumeMount{
+									&structs.VolumeMount{
+										Volume:      "certs",
+										Destination: "/etc/ssl/certs",
+									},
+									&structs.VolumeMount{
+										Volume:      "data",
+										Destination: "/srv/data",
+										ReadOnly:    true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+
+		{
+			"task-secrets.hcl",
+			&structs.Job{
+				Region:   "global",
+				ID:       "foo",
+				Name:     "foo",
+				Type:     "service",
+				Priority: 50,
+
+				TaskGroups: []*structs.TaskGroup{
+					&structs.TaskGroup{
+						Name:  "bar",
+						Count: 1,
+						Tasks: []*structs.Task{
+							&structs.Task{
+								Name:   "web",
+								Driver: "exec",
+								Secrets: []*structs.Secret{
+									&structs.Secret{
+										Path:         "secret/db",
+										Key:          "password",
+										DestPath:     "db.pass",
+										ChangeMode:   "signal",
+										ChangeSignal: "SIGHUP",
+									},
+									&structs.Secret{
+										Path:   "secret/api",
+										Key:    "token",
+										EnvVar: "API_TOKEN",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
 	}
 
 	for _, tc := range cases {
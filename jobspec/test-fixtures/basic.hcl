@@ -38,6 +38,11 @@ job "binstore-storagelocker" {
             on_success = true
             mode = "delay"
         }
+        ephemeral_disk {
+            sticky = true
+            migrate = true
+            size = 150
+        }
         task "binstore" {
             driver = "docker"
             config {
@@ -0,0 +1,21 @@
+job "foo" {
+    group "bar" {
+        task "web" {
+            driver = "exec"
+
+            artifact {
+                source = "http://foo.com/bar.tar.gz"
+                destination = "local/bar"
+                options {
+                    checksum = "md5:ff1cc0d3432dad54d607c1505fb7245c"
+                }
+            }
+
+            artifact {
+                source = "http://foo.com/app"
+                destination = "local/bin/app"
+                mode = "file"
+            }
+        }
+    }
+}
@@ -0,0 +1,23 @@
+job "foo" {
+    group "bar" {
+        task "migrate" {
+            driver = "exec"
+            lifecycle {
+                hook = "prestart"
+            }
+        }
+
+        task "proxy" {
+            driver = "exec"
+            lifecycle {
+                hook = "prestart"
+                sidecar = true
+            }
+        }
+
+        task "web" {
+            driver = "exec"
+            leader = true
+        }
+    }
+}
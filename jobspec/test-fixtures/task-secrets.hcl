@@ -0,0 +1,21 @@
+job "foo" {
+    group "bar" {
+        task "web" {
+            driver = "exec"
+
+            secret {
+                path = "secret/db"
+                key = "password"
+                destination = "db.pass"
+                change_mode = "signal"
+                change_signal = "SIGHUP"
+            }
+
+            secret {
+                path = "secret/api"
+                key = "token"
+                env = "API_TOKEN"
+            }
+        }
+    }
+}
@@ -0,0 +1,19 @@
+job "foo" {
+    group "bar" {
+        task "web" {
+            driver = "exec"
+
+            template {
+                source = "local/bar/app.conf.tpl"
+                destination = "app.conf"
+                change_mode = "signal"
+                change_signal = "SIGHUP"
+            }
+
+            template {
+                data = "{{.Env.NOMAD_IP}}"
+                destination = "ip"
+            }
+        }
+    }
+}
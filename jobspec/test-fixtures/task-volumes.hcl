@@ -0,0 +1,28 @@
+job "foo" {
+    group "bar" {
+        volume "certs" {
+            type = "host"
+            source = "ca-certificates"
+            read_only = true
+        }
+
+        volume "data" {
+            source = "data"
+        }
+
+        task "web" {
+            driver = "exec"
+
+            volume_mount {
+                volume = "certs"
+                destination = "/etc/ssl/certs"
+            }
+
+            volume_mount {
+                volume = "data"
+                destination = "/srv/data"
+                read_only = true
+            }
+        }
+    }
+}
@@ -0,0 +1,97 @@
+package nomad
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/acl"
+	"github.com/hashicorp/nomad/nomad/state"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// ResolveToken returns the ACL of the token with the given secret ID. A nil
+// ACL is returned if ACLs are disabled, in which case everything is allowed.
+func (s *Server) ResolveToken(secretID string) (*acl.ACL, error) {
+	if !s.config.ACLEnabled {
+		return nil, nil
+	}
+
+	snap, err := s.fsm.State().Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	token, policies, err := resolveTokenPolicies(snap, secretID)
+	if err != nil {
+		return nil, err
+	}
+	return CompileACL(token, policies)
+}
+
+// TokenAccessor returns the accessor ID of the token with the given secret
+// ID, which identifies requests in the audit log. It is empty if ACLs are
+// disabled or the token doesn't exist.
+func (s *Server) TokenAccessor(secretID string) string {
+	if !s.config.ACLEnabled || secretID == "" {
+		return ""
+	}
+
+	snap, err := s.fsm.State().Snapshot()
+	if err != nil {
+		return ""
+	}
+	token, err := snap.ACLTokenBySecretID(secretID)
+	if err != nil || token == nil {
+		return ""
+	}
+	return token.AccessorID
+}
+
+// resolveTokenPolicies returns the token with the given secret ID along with
+// its policies. Requests without a secret ID are anonymous and only granted
+// the anonymous policy, if it exists.
+func resolveTokenPolicies(snap *state.StateSnapshot, secretID string) (*structs.ACLToken, []*structs.ACLPolicy, error) {
+	var token *structs.ACLToken
+	names := []string{structs.AnonymousACLPolicy}
+	if secretID != "" {
+		var err error
+		token, err = snap.ACLTokenBySecretID(secretID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if token == nil {
+			return nil, nil, structs.ErrTokenNotFound
+		}
+		names = token.Policies
+	}
+
+	var policies []*structs.ACLPolicy
+	for _, name := range names {
+		policy, err := snap.ACLPolicyByName(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Policies can be deleted while tokens still reference them
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return token, policies, nil
+}
+
+// CompileACL compiles the ACL of the token from its policies. A nil token is
+// the anonymous token.
+func CompileACL(token *structs.ACLToken, policies []*structs.ACLPolicy) (*acl.ACL, error) {
+	if token != nil && token.IsManagement() {
+		return acl.ManagementACL, nil
+	}
+
+	parsed := make([]*acl.Policy, 0, len(policies))
+	for _, policy := range policies {
+		p, err := acl.Parse(policy.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ACL policy %q: %v", policy.Name, err)
+		}
+		parsed = append(parsed, p)
+	}
+	return acl.NewACL(false, parsed), nil
+}
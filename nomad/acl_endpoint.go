@@ -0,0 +1,493 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+var (
+	// errACLDisabled is returned by the endpoints managing ACLs when ACLs
+	// are disabled.
+	errACLDisabled = fmt.Errorf("ACL support disabled")
+)
+
+// ACL endpoint is used for managing ACL policies and tokens
+type ACL struct {
+	srv *Server
+}
+
+// checkManagement returns an error unless ACLs are enabled and the secret ID
+// is of a management token.
+func (a *ACL) checkManagement(secretID string) error {
+	if !a.srv.config.ACLEnabled {
+		return errACLDisabled
+	}
+	aclObj, err := a.srv.ResolveToken(secretID)
+	if err != nil {
+		return err
+	}
+	if !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+	return nil
+}
+
+// Bootstrap is used to create the initial management token. It can only be
+// done once.
+func (a *ACL) Bootstrap(args *structs.ACLTokenBootstrapRequest, reply *structs.ACLTokenUpsertResponse) error {
+	if done, err := a.srv.forward("ACL.Bootstrap", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "bootstrap"}, time.Now())
+
+	if !a.srv.config.ACLEnabled {
+		return errACLDisabled
+	}
+
+	// Check if the ACL system was already bootstrapped
+	snap, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	index, err := snap.Index("acl_token_bootstrap")
+	if err != nil {
+		return err
+	}
+	if index != 0 {
+		return fmt.Errorf("ACL bootstrap already done (index %d)", index)
+	}
+
+	args.Token = &structs.ACLToken{
+		AccessorID: structs.GenerateUUID(),
+		SecretID:   structs.GenerateUUID(),
+		Name:       "Bootstrap Token",
+		Type:       structs.ACLManagementToken,
+		CreateTime: time.Now().UTC(),
+	}
+
+	// Commit this update via Raft
+	resp, index, err := a.srv.raftApply(structs.ACLTokenBootstrapRequestType, args)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.acl: Bootstrap failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Tokens = []*structs.ACLToken{args.Token}
+	reply.Index = index
+	return nil
+}
+
+// UpsertPolicies is used to create or update ACL policies
+func (a *ACL) UpsertPolicies(args *structs.ACLPolicyUpsertRequest, reply *structs.GenericResponse) error {
+	if done, err := a.srv.forward("ACL.UpsertPolicies", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "upsert_policies"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.Policies) == 0 {
+		return fmt.Errorf("must specify one or more policies")
+	}
+	for _, policy := range args.Policies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("policy %q invalid: %v", policy.Name, err)
+		}
+	}
+
+	// Commit this update via Raft
+	resp, index, err := a.srv.raftApply(structs.ACLPolicyUpsertRequestType, args)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.acl: UpsertPolicies failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// DeletePolicies is used to delete ACL policies
+func (a *ACL) DeletePolicies(args *structs.ACLPolicyDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := a.srv.forward("ACL.DeletePolicies", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "delete_policies"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify one or more policies to delete")
+	}
+
+	// Commit this update via Raft
+	resp, index, err := a.srv.raftApply(structs.ACLPolicyDeleteRequestType, args)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.acl: DeletePolicies failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ListPolicies is used to list the ACL policies
+func (a *ACL) ListPolicies(args *structs.ACLPolicyListRequest, reply *structs.ACLPolicyListResponse) error {
+	if done, err := a.srv.forward("ACL.ListPolicies", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "list_policies"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "acl_policy"}),
+		run: func() error {
+			// Capture all the policies
+			snap, err := a.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.ACLPolicies()
+			if err != nil {
+				return err
+			}
+
+			var policies []*structs.ACLPolicyListStub
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				policies = append(policies, raw.(*structs.ACLPolicy).Stub())
+			}
+			reply.Policies = policies
+
+			// Use the last index that affected the policy table
+			index, err := snap.Index("acl_policy")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			a.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetPolicy is used to get a specific ACL policy. Client tokens can read the
+// policies granted to them.
+func (a *ACL) GetPolicy(args *structs.ACLPolicySpecificRequest, reply *structs.SingleACLPolicyResponse) error {
+	if done, err := a.srv.forward("ACL.GetPolicy", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_policy"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		if err != structs.ErrPermissionDenied || !a.tokenHasPolicy(args.AuthToken, args.Name) {
+			return err
+		}
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{ACLPolicy: args.Name}),
+		run: func() error {
+			// Look for the policy
+			snap, err := a.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.ACLPolicyByName(args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Policy = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the policy table
+				index, err := snap.Index("acl_policy")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			a.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// tokenHasPolicy returns whether the token with the secret ID is granted the
+// policy.
+func (a *ACL) tokenHasPolicy(secretID, policy string) bool {
+	if secretID == "" {
+		return false
+	}
+	snap, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return false
+	}
+	token, err := snap.ACLTokenBySecretID(secretID)
+	if err != nil || token == nil {
+		return false
+	}
+	for _, name := range token.Policies {
+		if name == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// UpsertTokens is used to create or update ACL tokens. Tokens without an
+// accessor ID are created, while updates retain the secret ID of the token.
+func (a *ACL) UpsertTokens(args *structs.ACLTokenUpsertRequest, reply *structs.ACLTokenUpsertResponse) error {
+	if done, err := a.srv.forward("ACL.UpsertTokens", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "upsert_tokens"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.Tokens) == 0 {
+		return fmt.Errorf("must specify one or more tokens")
+	}
+	snap, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	for _, token := range args.Tokens {
+		if err := token.Validate(); err != nil {
+			return fmt.Errorf("token %q invalid: %v", token.AccessorID, err)
+		}
+
+		if token.AccessorID == "" {
+			token.AccessorID = structs.GenerateUUID()
+			token.SecretID = structs.GenerateUUID()
+			token.CreateTime = time.Now().UTC()
+			continue
+		}
+
+		existing, err := snap.ACLTokenByAccessorID(token.AccessorID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return fmt.Errorf("token %q not found", token.AccessorID)
+		}
+		token.SecretID = existing.SecretID
+		token.CreateTime = existing.CreateTime
+	}
+
+	// Commit this update via Raft
+	resp, index, err := a.srv.raftApply(structs.ACLTokenUpsertRequestType, args)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.acl: UpsertTokens failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Tokens = args.Tokens
+	reply.Index = index
+	return nil
+}
+
+// DeleteTokens is used to delete ACL tokens
+func (a *ACL) DeleteTokens(args *structs.ACLTokenDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := a.srv.forward("ACL.DeleteTokens", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "delete_tokens"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.AccessorIDs) == 0 {
+		return fmt.Errorf("must specify one or more tokens to delete")
+	}
+
+	// Commit this update via Raft
+	resp, index, err := a.srv.raftApply(structs.ACLTokenDeleteRequestType, args)
+	if err != nil {
+		a.srv.logger.Printf("[ERR] nomad.acl: DeleteTokens failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ListTokens is used to list the ACL tokens without their secret IDs
+func (a *ACL) ListTokens(args *structs.ACLTokenListRequest, reply *structs.ACLTokenListResponse) error {
+	if done, err := a.srv.forward("ACL.ListTokens", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "list_tokens"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "acl_token"}),
+		run: func() error {
+			// Capture all the tokens
+			snap, err := a.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.ACLTokens()
+			if err != nil {
+				return err
+			}
+
+			var tokens []*structs.ACLTokenListStub
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				tokens = append(tokens, raw.(*structs.ACLToken).Stub())
+			}
+			reply.Tokens = tokens
+
+			// Use the last index that affected the token table
+			index, err := snap.Index("acl_token")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			a.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// GetToken is used to get a specific ACL token by its accessor ID
+func (a *ACL) GetToken(args *structs.ACLTokenSpecificRequest, reply *structs.SingleACLTokenResponse) error {
+	if done, err := a.srv.forward("ACL.GetToken", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "get_token"}, time.Now())
+
+	if err := a.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{ACLToken: args.AccessorID}),
+		run: func() error {
+			// Look for the token
+			snap, err := a.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.ACLTokenByAccessorID(args.AccessorID)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Token = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the token table
+				index, err := snap.Index("acl_token")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			a.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return a.srv.blockingRPC(&opts)
+}
+
+// ResolveToken is used to get the token of the request along with its
+// policies. Agents use it to check the requests they serve locally and users
+// to inspect their own token.
+func (a *ACL) ResolveToken(args *structs.GenericRequest, reply *structs.ResolveACLTokenResponse) error {
+	if done, err := a.srv.forward("ACL.ResolveToken", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "acl", "resolve_token"}, time.Now())
+
+	if !a.srv.config.ACLEnabled {
+		return errACLDisabled
+	}
+
+	snap, err := a.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	reply.Token, reply.Policies, err = resolveTokenPolicies(snap, args.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	// Use the last index that affected the token table
+	index, err := snap.Index("acl_token")
+	if err != nil {
+		return err
+	}
+	reply.Index = index
+
+	// Set the query response
+	a.srv.setQueryMeta(&reply.QueryMeta)
+	return nil
+}
@@ -0,0 +1,403 @@
+package nomad
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func testACLServer(t *testing.T) *Server {
+	return testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.ACLEnabled = true
+	})
+}
+
+// bootstrapACL bootstraps the ACL system of the server and returns the
+// management token.
+func bootstrapACL(t *testing.T, s *Server) *structs.ACLToken {
+	codec := rpcClient(t, s)
+	req := &structs.ACLTokenBootstrapRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.ACLTokenUpsertResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.Bootstrap", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp.Tokens) != 1 {
+		t.Fatalf("bad: %#v", resp)
+	}
+	return resp.Tokens[0]
+}
+
+func TestACLEndpoint_Bootstrap(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	token := bootstrapACL(t, s1)
+	if !token.IsManagement() || token.SecretID == "" {
+		t.Fatalf("bad: %#v", token)
+	}
+
+	out, err := s1.fsm.State().ACLTokenBySecretID(token.SecretID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.AccessorID != token.AccessorID {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Bootstrapping again fails
+	req := &structs.ACLTokenBootstrapRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.ACLTokenUpsertResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.Bootstrap", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestACLEndpoint_Bootstrap_Disabled(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	req := &structs.ACLTokenBootstrapRequest{
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.ACLTokenUpsertResponse
+	err := msgpackrpc.CallWithCodec(codec, "ACL.Bootstrap", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("expected ACL disabled error: %v", err)
+	}
+}
+
+func TestACLEndpoint_Policies(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	root := bootstrapACL(t, s1)
+
+	// Upserting requires a management token
+	policy := mock.ACLPolicy()
+	req := &structs.ACLPolicyUpsertRequest{
+		Policies:     []*structs.ACLPolicy{policy},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertPolicies", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	req.AuthToken = root.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertPolicies", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	// Invalid rules are rejected
+	invalid := mock.ACLPolicy()
+	invalid.Rules = `job { policy = "admin" }`
+	req.Policies = []*structs.ACLPolicy{invalid}
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertPolicies", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// List the policies
+	list := &structs.ACLPolicyListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: root.SecretID},
+	}
+	var listResp structs.ACLPolicyListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.ListPolicies", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(listResp.Policies) != 1 || listResp.Policies[0].Name != policy.Name {
+		t.Fatalf("bad: %#v", listResp.Policies)
+	}
+
+	// Get the policy
+	get := &structs.ACLPolicySpecificRequest{
+		Name:         policy.Name,
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: root.SecretID},
+	}
+	var getResp structs.SingleACLPolicyResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.GetPolicy", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Policy == nil || getResp.Policy.Rules != policy.Rules {
+		t.Fatalf("bad: %#v", getResp.Policy)
+	}
+
+	// Delete the policy
+	del := &structs.ACLPolicyDeleteRequest{
+		Names:        []string{policy.Name},
+		WriteRequest: structs.WriteRequest{Region: "global", AuthToken: root.SecretID},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.DeletePolicies", del, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := s1.fsm.State().ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("policy not deleted: %#v", out)
+	}
+}
+
+func TestACLEndpoint_Tokens(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	root := bootstrapACL(t, s1)
+
+	// Create a client token
+	token := &structs.ACLToken{
+		Name:     "reader",
+		Type:     structs.ACLClientToken,
+		Policies: []string{"readonly"},
+	}
+	req := &structs.ACLTokenUpsertRequest{
+		Tokens:       []*structs.ACLToken{token},
+		WriteRequest: structs.WriteRequest{Region: "global", AuthToken: root.SecretID},
+	}
+	var resp structs.ACLTokenUpsertResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(resp.Tokens) != 1 || resp.Tokens[0].AccessorID == "" || resp.Tokens[0].SecretID == "" {
+		t.Fatalf("bad: %#v", resp.Tokens)
+	}
+	created := resp.Tokens[0]
+
+	// Updating the token retains its secret
+	update := &structs.ACLToken{
+		AccessorID: created.AccessorID,
+		Name:       "renamed",
+		Type:       structs.ACLClientToken,
+		Policies:   []string{"readonly"},
+	}
+	req.Tokens = []*structs.ACLToken{update}
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err := s1.fsm.State().ACLTokenByAccessorID(created.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.Name != "renamed" || out.SecretID != created.SecretID {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Client tokens can't manage tokens
+	req.AuthToken = created.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.UpsertTokens", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// List the tokens without their secrets
+	list := &structs.ACLTokenListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: root.SecretID},
+	}
+	var listResp structs.ACLTokenListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.ListTokens", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(listResp.Tokens) != 2 {
+		t.Fatalf("bad: %#v", listResp.Tokens)
+	}
+
+	// Resolve the token
+	resolve := &structs.GenericRequest{
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: created.SecretID},
+	}
+	var resolveResp structs.ResolveACLTokenResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.ResolveToken", resolve, &resolveResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resolveResp.Token == nil || resolveResp.Token.AccessorID != created.AccessorID {
+		t.Fatalf("bad: %#v", resolveResp.Token)
+	}
+
+	// Delete the token
+	del := &structs.ACLTokenDeleteRequest{
+		AccessorIDs:  []string{created.AccessorID},
+		WriteRequest: structs.WriteRequest{Region: "global", AuthToken: root.SecretID},
+	}
+	var delResp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "ACL.DeleteTokens", del, &delResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = s1.fsm.State().ACLTokenByAccessorID(created.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("token not deleted: %#v", out)
+	}
+}
+
+func TestACLEndpoint_JobRegister_Enforced(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	root := bootstrapACL(t, s1)
+
+	// Create a token that can only read jobs
+	policy := mock.ACLPolicy()
+	state := s1.fsm.State()
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	reader := mock.ACLToken()
+	reader.Policies = []string{policy.Name}
+	if err := state.UpsertACLTokens(1001, []*structs.ACLToken{reader}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.JobRegisterResponse
+
+	// Anonymous requests are denied without an anonymous policy
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Unknown tokens are rejected
+	req.AuthToken = structs.GenerateUUID()
+	err = msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrTokenNotFound.Error() {
+		t.Fatalf("expected token not found: %v", err)
+	}
+
+	// Read tokens can't register jobs
+	req.AuthToken = reader.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Management tokens can
+	req.AuthToken = root.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Read tokens can read the job
+	get := &structs.JobSpecificRequest{
+		JobID:        job.ID,
+		QueryOptions: structs.QueryOptions{Region: "global", AuthToken: reader.SecretID},
+	}
+	var getResp structs.SingleJobResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.GetJob", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Job == nil || getResp.Job.ID != job.ID {
+		t.Fatalf("bad: %#v", getResp.Job)
+	}
+}
+
+func TestACLEndpoint_Node_Enforced(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	root := bootstrapACL(t, s1)
+
+	// Create a token that can only read nodes
+	policy := mock.ACLPolicy()
+	state := s1.fsm.State()
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	reader := mock.ACLToken()
+	reader.Policies = []string{policy.Name}
+	if err := state.UpsertACLTokens(1001, []*structs.ACLToken{reader}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	node := mock.Node()
+	req := &structs.NodeRegisterRequest{
+		Node:         node,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.NodeUpdateResponse
+
+	// Anonymous requests are denied
+	err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Read tokens can't register nodes
+	req.AuthToken = reader.SecretID
+	err = msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Management tokens can
+	req.AuthToken = root.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "Node.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Anonymous requests can't read the node
+	get := &structs.NodeSpecificRequest{
+		NodeID:       node.ID,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.SingleNodeResponse
+	err = msgpackrpc.CallWithCodec(codec, "Node.GetNode", get, &getResp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+
+	// Read tokens can
+	get.AuthToken = reader.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "Node.GetNode", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Node == nil || getResp.Node.ID != node.ID {
+		t.Fatalf("bad: %#v", getResp.Node)
+	}
+}
+
+func TestACLEndpoint_PlanSubmit_EvalToken(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Plans for evaluations that aren't outstanding are rejected
+	plan := mock.Plan()
+	plan.EvalID = structs.GenerateUUID()
+	plan.EvalToken = structs.GenerateUUID()
+	req := &structs.PlanRequest{
+		Plan:         plan,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.PlanResponse
+	err := msgpackrpc.CallWithCodec(codec, "Plan.Submit", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied: %v", err)
+	}
+}
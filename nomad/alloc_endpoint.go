@@ -20,18 +20,25 @@ func (a *Alloc) List(args *structs.AllocListRequest, reply *structs.AllocListRes
 	}
 	defer metrics.MeasureSince([]string{"nomad", "alloc", "list"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
 		watch:     watch.NewItems(watch.Item{Table: "allocs"}),
 		run: func() error {
-			// Capture all the allocations
+			// Capture all the allocations of the namespace
 			snap, err := a.srv.fsm.State().Snapshot()
 			if err != nil {
 				return err
 			}
-			iter, err := snap.Allocs()
+			iter, err := snap.AllocsByNamespace(args.RequestNamespace())
 			if err != nil {
 				return err
 			}
@@ -69,6 +76,13 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "alloc", "get_alloc"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := a.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
@@ -85,6 +99,11 @@ func (a *Alloc) GetAlloc(args *structs.AllocSpecificRequest,
 				return err
 			}
 
+			// Hide allocations of other namespaces
+			if out != nil && out.Namespace != args.RequestNamespace() {
+				out = nil
+			}
+
 			// Setup the output
 			reply.Alloc = out
 			if out != nil {
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/hashicorp/memberlist"
+	"github.com/hashicorp/nomad/audit"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/structs"
 	"github.com/hashicorp/nomad/scheduler"
 	"github.com/hashicorp/raft"
@@ -92,6 +94,10 @@ type Config struct {
 	// RequireTLS ensures that all RPC traffic is protected with TLS
 	RequireTLS bool
 
+	// TLSConfig is used to serve RPC connections over TLS and to dial
+	// other servers with TLS. TLS is disabled if nil.
+	TLSConfig *tlsutil.Config
+
 	// SerfConfig is the configuration for the serf cluster
 	SerfConfig *serf.Config
 
@@ -176,6 +182,44 @@ type Config struct {
 	// a new leader is elected, since we no longer know the status
 	// of all the heartbeats.
 	FailoverHeartbeatTTL time.Duration
+
+	// ACLEnabled enables checking the ACL token of requests against the
+	// policies granted to it.
+	ACLEnabled bool
+
+	// AuditLogger records the RPCs served over the network. It is shared
+	// with the agent and disabled if nil.
+	AuditLogger *audit.Logger
+
+	// AdmissionConfig configures the admission controllers jobs go through
+	// on registration in addition to the built-in ones. It must be the same
+	// on all servers, as registrations are admitted by the leader.
+	AdmissionConfig *AdmissionConfig
+}
+
+// AdmissionConfig configures the optional admission controllers of job
+// registrations.
+type AdmissionConfig struct {
+	// RequiredMeta are the meta keys every job must set.
+	RequiredMeta []string
+
+	// RecommendedMeta are the meta keys jobs are warned about not setting.
+	RecommendedMeta []string
+
+	// ImagePrefixes restricts the images of Docker tasks to the ones
+	// starting with one of the prefixes, such as a registry address.
+	ImagePrefixes []string
+
+	// DatacenterConstraints are the constraints added to the jobs that
+	// may run in each datacenter.
+	DatacenterConstraints map[string][]*structs.Constraint
+
+	// WebhookURL is the address jobs are POSTed to for an external service
+	// to patch or reject them. It is disabled if empty.
+	WebhookURL string
+
+	// WebhookTimeout limits the duration of webhook requests.
+	WebhookTimeout time.Duration
 }
 
 // CheckVersion is used to check if the ProtocolVersion is valid
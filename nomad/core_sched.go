@@ -56,7 +56,8 @@ func (c *CoreScheduler) jobGC(eval *structs.Evaluation) error {
 		oldThreshold, c.srv.config.JobGCThreshold)
 
 	// Collect the allocations, evaluations and jobs to GC
-	var gcAlloc, gcEval, gcJob []string
+	var gcAlloc, gcEval []string
+	var gcJob []*structs.Job
 
 OUTER:
 	for i := iter.Next(); i != nil; i = iter.Next() {
@@ -67,7 +68,7 @@ OUTER:
 			continue
 		}
 
-		evals, err := c.snap.EvalsByJob(job.ID)
+		evals, err := c.snap.EvalsByJob(job.Namespace, job.ID)
 		if err != nil {
 			c.srv.logger.Printf("[ERR] sched.core: failed to get evals for job %s: %v", job.ID, err)
 			continue
@@ -84,7 +85,7 @@ OUTER:
 		}
 
 		// Job is eligible for garbage collection
-		gcJob = append(gcJob, job.ID)
+		gcJob = append(gcJob, job)
 	}
 
 	// Fast-path the nothing case
@@ -102,9 +103,10 @@ OUTER:
 	// Call to the leader to deregister the jobs.
 	for _, job := range gcJob {
 		req := structs.JobDeregisterRequest{
-			JobID: job,
+			JobID: job.ID,
 			WriteRequest: structs.WriteRequest{
-				Region: c.srv.config.Region,
+				Region:    c.srv.config.Region,
+				Namespace: job.Namespace,
 			},
 		}
 		var resp structs.JobDeregisterResponse
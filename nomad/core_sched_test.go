@@ -190,7 +190,7 @@ func TestCoreScheduler_JobGC(t *testing.T) {
 		}
 
 		// Should still exist
-		out, err := state.JobByID(job.ID)
+		out, err := state.JobByID(job.Namespace, job.ID)
 		if err != nil {
 			t.Fatalf("test(%s) err: %v", test.test, err)
 		}
@@ -27,6 +27,13 @@ func (e *Eval) GetEval(args *structs.EvalSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "eval", "get_eval"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
@@ -43,6 +50,11 @@ func (e *Eval) GetEval(args *structs.EvalSpecificRequest,
 				return err
 			}
 
+			// Hide evaluations of other namespaces
+			if out != nil && out.Namespace != args.RequestNamespace() {
+				out = nil
+			}
+
 			// Setup the output
 			reply.Eval = out
 			if out != nil {
@@ -228,18 +240,25 @@ func (e *Eval) List(args *structs.EvalListRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "eval", "list"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
 		watch:     watch.NewItems(watch.Item{Table: "evals"}),
 		run: func() error {
-			// Scan all the evaluations
+			// Scan all the evaluations of the namespace
 			snap, err := e.srv.fsm.State().Snapshot()
 			if err != nil {
 				return err
 			}
-			iter, err := snap.Evals()
+			iter, err := snap.EvalsByNamespace(args.RequestNamespace())
 			if err != nil {
 				return err
 			}
@@ -277,6 +296,13 @@ func (e *Eval) Allocations(args *structs.EvalSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "eval", "allocations"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := e.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
@@ -293,12 +319,12 @@ func (e *Eval) Allocations(args *structs.EvalSpecificRequest,
 				return err
 			}
 
-			// Convert to a stub
-			if len(allocs) > 0 {
-				reply.Allocations = make([]*structs.AllocListStub, 0, len(allocs))
-				for _, alloc := range allocs {
-					reply.Allocations = append(reply.Allocations, alloc.Stub())
+			// Convert to a stub, hiding allocations of other namespaces
+			for _, alloc := range allocs {
+				if alloc.Namespace != args.RequestNamespace() {
+					continue
 				}
+				reply.Allocations = append(reply.Allocations, alloc.Stub())
 			}
 
 			// Use the last index that affected the allocs table
@@ -236,7 +236,7 @@ func TestFSM_RegisterJob(t *testing.T) {
 	}
 
 	// Verify we are registered
-	job, err := fsm.State().JobByID(req.Job.ID)
+	job, err := fsm.State().JobByID(req.Job.Namespace, req.Job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -279,7 +279,7 @@ func TestFSM_DeregisterJob(t *testing.T) {
 	}
 
 	// Verify we are NOT registered
-	job, err = fsm.State().JobByID(req.Job.ID)
+	job, err = fsm.State().JobByID(req.Job.Namespace, req.Job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -364,6 +364,203 @@ func TestFSM_DeleteEval(t *testing.T) {
 	}
 }
 
+func TestFSM_ACLPolicies(t *testing.T) {
+	fsm := testFSM(t)
+
+	policy := mock.ACLPolicy()
+	req := structs.ACLPolicyUpsertRequest{
+		Policies: []*structs.ACLPolicy{policy},
+	}
+	buf, err := structs.Encode(structs.ACLPolicyUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Rules != policy.Rules {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	del := structs.ACLPolicyDeleteRequest{
+		Names: []string{policy.Name},
+	}
+	buf, err = structs.Encode(structs.ACLPolicyDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err = fsm.State().ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("policy not deleted: %#v", out)
+	}
+}
+
+func TestFSM_Namespaces(t *testing.T) {
+	fsm := testFSM(t)
+
+	ns := mock.Namespace()
+	req := structs.NamespaceUpsertRequest{
+		Namespaces: []*structs.Namespace{ns},
+	}
+	buf, err := structs.Encode(structs.NamespaceUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Description != ns.Description {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	del := structs.NamespaceDeleteRequest{
+		Names: []string{ns.Name},
+	}
+	buf, err = structs.Encode(structs.NamespaceDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err = fsm.State().NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("namespace not deleted: %#v", out)
+	}
+}
+
+func TestFSM_QuotaSpecs(t *testing.T) {
+	fsm := testFSM(t)
+
+	quota := mock.QuotaSpec()
+	req := structs.QuotaSpecUpsertRequest{
+		Quotas: []*structs.QuotaSpec{quota},
+	}
+	buf, err := structs.Encode(structs.QuotaSpecUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || !reflect.DeepEqual(out.Limits, quota.Limits) {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	del := structs.QuotaSpecDeleteRequest{
+		Names: []string{quota.Name},
+	}
+	buf, err = structs.Encode(structs.QuotaSpecDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err = fsm.State().QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("quota not deleted: %#v", out)
+	}
+}
+
+func TestFSM_ACLTokens(t *testing.T) {
+	fsm := testFSM(t)
+
+	token := mock.ACLToken()
+	req := structs.ACLTokenUpsertRequest{
+		Tokens: []*structs.ACLToken{token},
+	}
+	buf, err := structs.Encode(structs.ACLTokenUpsertRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err := fsm.State().ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.SecretID != token.SecretID {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	del := structs.ACLTokenDeleteRequest{
+		AccessorIDs: []string{token.AccessorID},
+	}
+	buf, err = structs.Encode(structs.ACLTokenDeleteRequestType, del)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	out, err = fsm.State().ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("token not deleted: %#v", out)
+	}
+}
+
+func TestFSM_ACLTokenBootstrap(t *testing.T) {
+	fsm := testFSM(t)
+
+	req := structs.ACLTokenBootstrapRequest{
+		Token: mock.ACLManagementToken(),
+	}
+	buf, err := structs.Encode(structs.ACLTokenBootstrapRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp != nil {
+		t.Fatalf("resp: %v", resp)
+	}
+
+	// A second bootstrap is rejected
+	req.Token = mock.ACLManagementToken()
+	buf, err = structs.Encode(structs.ACLTokenBootstrapRequestType, req)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp := fsm.Apply(makeLog(buf)); resp == nil {
+		t.Fatalf("expected error bootstrapping twice")
+	}
+}
+
 func TestFSM_UpsertAllocs(t *testing.T) {
 	fsm := testFSM(t)
 
@@ -513,8 +710,8 @@ func TestFSM_SnapshotRestore_Jobs(t *testing.T) {
 	// Verify the contents
 	fsm2 := testSnapshotRestore(t, fsm)
 	state2 := fsm2.State()
-	out1, _ := state2.JobByID(job1.ID)
-	out2, _ := state2.JobByID(job2.ID)
+	out1, _ := state2.JobByID(job1.Namespace, job1.ID)
+	out2, _ := state2.JobByID(job2.Namespace, job2.ID)
 	if !reflect.DeepEqual(job1, out1) {
 		t.Fatalf("bad: \n%#v\n%#v", out1, job1)
 	}
@@ -567,6 +764,89 @@ func TestFSM_SnapshotRestore_Allocs(t *testing.T) {
 	}
 }
 
+func TestFSM_SnapshotRestore_ACL(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	policy := mock.ACLPolicy()
+	state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy})
+	token := mock.ACLToken()
+	state.UpsertACLTokens(1001, []*structs.ACLToken{token})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	outPolicy, _ := state2.ACLPolicyByName(policy.Name)
+	if !reflect.DeepEqual(policy, outPolicy) {
+		t.Fatalf("bad: \n%#v\n%#v", outPolicy, policy)
+	}
+	outToken, _ := state2.ACLTokenBySecretID(token.SecretID)
+	if outToken == nil || outToken.AccessorID != token.AccessorID ||
+		!reflect.DeepEqual(outToken.Policies, token.Policies) || !outToken.CreateTime.Equal(token.CreateTime) {
+		t.Fatalf("bad: \n%#v\n%#v", outToken, token)
+	}
+}
+
+func TestFSM_SnapshotRestore_Namespaces(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	ns := mock.Namespace()
+	state.UpsertNamespaces(1000, []*structs.Namespace{ns})
+	job := mock.Job()
+	job.Namespace = ns.Name
+	state.UpsertJob(1001, job)
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out, _ := state2.NamespaceByName(ns.Name)
+	if !reflect.DeepEqual(ns, out) {
+		t.Fatalf("bad: \n%#v\n%#v", out, ns)
+	}
+	outJob, _ := state2.JobByID(ns.Name, job.ID)
+	if !reflect.DeepEqual(job, outJob) {
+		t.Fatalf("bad: \n%#v\n%#v", outJob, job)
+	}
+	def, _ := state2.NamespaceByName(structs.DefaultNamespace)
+	if def == nil {
+		t.Fatalf("missing default namespace")
+	}
+}
+
+func TestFSM_SnapshotRestore_QuotaSpecs(t *testing.T) {
+	// Add some state
+	fsm := testFSM(t)
+	state := fsm.State()
+	quota := mock.QuotaSpec()
+	state.UpsertQuotaSpecs(1000, []*structs.QuotaSpec{quota})
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	state.UpsertNamespaces(1001, []*structs.Namespace{ns})
+	alloc := mock.Alloc()
+	alloc.Namespace = ns.Name
+	state.UpsertAllocs(1002, []*structs.Allocation{alloc})
+
+	// Verify the contents
+	fsm2 := testSnapshotRestore(t, fsm)
+	state2 := fsm2.State()
+	out, _ := state2.QuotaSpecByName(quota.Name)
+	if !reflect.DeepEqual(quota, out) {
+		t.Fatalf("bad: \n%#v\n%#v", out, quota)
+	}
+	outNs, _ := state2.NamespaceByName(ns.Name)
+	if !reflect.DeepEqual(ns, outNs) {
+		t.Fatalf("bad: \n%#v\n%#v", outNs, ns)
+	}
+
+	// The usage is rebuilt from the allocations
+	usage, _ := state.QuotaUsageByNamespace(ns.Name)
+	usage2, _ := state2.QuotaUsageByNamespace(ns.Name)
+	if !reflect.DeepEqual(usage, usage2) {
+		t.Fatalf("bad: \n%#v\n%#v", usage2, usage)
+	}
+}
+
 func TestFSM_SnapshotRestore_Indexes(t *testing.T) {
 	// Add some state
 	fsm := testFSM(t)
@@ -3,6 +3,7 @@ package nomad
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -13,6 +14,19 @@ import (
 // Job endpoint is used for job interactions
 type Job struct {
 	srv *Server
+
+	// mutators and validators are the admission controllers registered
+	// jobs go through, in order.
+	mutators   []jobMutator
+	validators []jobValidator
+}
+
+// NewJobEndpoints returns the job endpoint with the admission controllers
+// of the server configuration.
+func NewJobEndpoints(s *Server) *Job {
+	j := &Job{srv: s}
+	j.mutators, j.validators = newAdmissionControllers(s.config.AdmissionConfig)
+	return j
 }
 
 // Register is used to upsert a job for scheduling
@@ -22,19 +36,36 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "register"}, time.Now())
 
+	// Check job write permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobWrite() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Validate the arguments
 	if args.Job == nil {
 		return fmt.Errorf("missing job for registration")
 	}
 
-	if err := j.checkBlacklist(args.Job); err != nil {
+	// Register the job in the namespace of the request unless it sets one
+	if args.Job.Namespace == "" {
+		args.Job.Namespace = args.RequestNamespace()
+	}
+	if err := j.checkNamespace(args.Job.Namespace); err != nil {
 		return err
 	}
 
-	// Initialize the job fields (sets defaults and any necessary init work).
-	args.Job.InitFields()
+	// Run the admission controllers, which set the defaults of the job and
+	// validate it. The blacklist is checked afterwards so that it also
+	// applies to the jobs patched by the webhook.
+	job, warnings, err := j.admit(args.Job)
+	if err != nil {
+		return err
+	}
+	args.Job = job
 
-	if err := args.Job.Validate(); err != nil {
+	if err := j.checkBlacklist(args.Job); err != nil {
 		return err
 	}
 
@@ -55,6 +86,7 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 		Priority:       args.Job.Priority,
 		Type:           args.Job.Type,
 		TriggeredBy:    structs.EvalTriggerJobRegister,
+		Namespace:      args.Job.Namespace,
 		JobID:          args.Job.ID,
 		JobModifyIndex: index,
 		Status:         structs.EvalStatusPending,
@@ -78,9 +110,47 @@ func (j *Job) Register(args *structs.JobRegisterRequest, reply *structs.JobRegis
 	reply.EvalCreateIndex = evalIndex
 	reply.JobModifyIndex = index
 	reply.Index = evalIndex
+	reply.Warnings = formatWarnings(warnings)
 	return nil
 }
 
+// admit runs the job through the mutating and then the validating
+// admission controllers. It returns the admitted job and the warnings of
+// the controllers.
+func (j *Job) admit(job *structs.Job) (*structs.Job, []error, error) {
+	var warnings []error
+	for _, mutator := range j.mutators {
+		out, w, err := mutator.Mutate(job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			j.srv.logger.Printf("[DEBUG] nomad.job: admission controller %q rejected job %q: %v",
+				mutator.Name(), job.ID, err)
+			return nil, nil, err
+		}
+		job = out
+	}
+
+	for _, validator := range j.validators {
+		w, err := validator.Validate(job)
+		warnings = append(warnings, w...)
+		if err != nil {
+			j.srv.logger.Printf("[DEBUG] nomad.job: admission controller %q rejected job %q: %v",
+				validator.Name(), job.ID, err)
+			return nil, nil, err
+		}
+	}
+	return job, warnings, nil
+}
+
+// formatWarnings joins the warnings into newline separated text.
+func formatWarnings(warnings []error) string {
+	lines := make([]string, len(warnings))
+	for i, warning := range warnings {
+		lines[i] = warning.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
 // checkBlacklist returns an error if the user has set any blacklisted field in
 // the job.
 func (j *Job) checkBlacklist(job *structs.Job) error {
@@ -91,6 +161,22 @@ func (j *Job) checkBlacklist(job *structs.Job) error {
 	return nil
 }
 
+// checkNamespace returns an error if the namespace doesn't exist.
+func (j *Job) checkNamespace(namespace string) error {
+	snap, err := j.srv.fsm.State().Snapshot()
+	if err != nil {
+		return err
+	}
+	ns, err := snap.NamespaceByName(namespace)
+	if err != nil {
+		return err
+	}
+	if ns == nil {
+		return fmt.Errorf("namespace %q not found", namespace)
+	}
+	return nil
+}
+
 // Evaluate is used to force a job for re-evaluation
 func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegisterResponse) error {
 	if done, err := j.srv.forward("Job.Evaluate", args, args, reply); done {
@@ -98,6 +184,13 @@ func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegis
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "evaluate"}, time.Now())
 
+	// Check job write permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobWrite() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Validate the arguments
 	if args.JobID == "" {
 		return fmt.Errorf("missing job ID for evaluation")
@@ -108,7 +201,7 @@ func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegis
 	if err != nil {
 		return err
 	}
-	job, err := snap.JobByID(args.JobID)
+	job, err := snap.JobByID(args.RequestNamespace(), args.JobID)
 	if err != nil {
 		return err
 	}
@@ -122,6 +215,7 @@ func (j *Job) Evaluate(args *structs.JobEvaluateRequest, reply *structs.JobRegis
 		Priority:       job.Priority,
 		Type:           job.Type,
 		TriggeredBy:    structs.EvalTriggerJobRegister,
+		Namespace:      job.Namespace,
 		JobID:          job.ID,
 		JobModifyIndex: job.ModifyIndex,
 		Status:         structs.EvalStatusPending,
@@ -153,6 +247,13 @@ func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobD
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "deregister"}, time.Now())
 
+	// Check job write permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobWrite() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Commit this update via Raft
 	_, index, err := j.srv.raftApply(structs.JobDeregisterRequestType, args)
 	if err != nil {
@@ -169,6 +270,7 @@ func (j *Job) Deregister(args *structs.JobDeregisterRequest, reply *structs.JobD
 		Priority:       structs.JobDefaultPriority,
 		Type:           structs.JobTypeService,
 		TriggeredBy:    structs.EvalTriggerJobDeregister,
+		Namespace:      args.RequestNamespace(),
 		JobID:          args.JobID,
 		JobModifyIndex: index,
 		Status:         structs.EvalStatusPending,
@@ -201,6 +303,13 @@ func (j *Job) GetJob(args *structs.JobSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "get_job"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
@@ -213,7 +322,7 @@ func (j *Job) GetJob(args *structs.JobSpecificRequest,
 			if err != nil {
 				return err
 			}
-			out, err := snap.JobByID(args.JobID)
+			out, err := snap.JobByID(args.RequestNamespace(), args.JobID)
 			if err != nil {
 				return err
 			}
@@ -246,18 +355,25 @@ func (j *Job) List(args *structs.JobListRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "list"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
 		queryMeta: &reply.QueryMeta,
 		watch:     watch.NewItems(watch.Item{Table: "jobs"}),
 		run: func() error {
-			// Capture all the jobs
+			// Capture all the jobs of the namespace
 			snap, err := j.srv.fsm.State().Snapshot()
 			if err != nil {
 				return err
 			}
-			iter, err := snap.Jobs()
+			iter, err := snap.JobsByNamespace(args.RequestNamespace())
 			if err != nil {
 				return err
 			}
@@ -295,6 +411,13 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "allocations"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Setup the blocking query
 	opts := blockingOptions{
 		queryOpts: &args.QueryOptions,
@@ -306,7 +429,7 @@ func (j *Job) Allocations(args *structs.JobSpecificRequest,
 			if err != nil {
 				return err
 			}
-			allocs, err := snap.AllocsByJob(args.JobID)
+			allocs, err := snap.AllocsByJob(args.RequestNamespace(), args.JobID)
 			if err != nil {
 				return err
 			}
@@ -342,12 +465,19 @@ func (j *Job) Evaluations(args *structs.JobSpecificRequest,
 	}
 	defer metrics.MeasureSince([]string{"nomad", "job", "evaluations"}, time.Now())
 
+	// Check job read permissions
+	if aclObj, err := j.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	// Capture the evaluations
 	snap, err := j.srv.fsm.State().Snapshot()
 	if err != nil {
 		return err
 	}
-	reply.Evaluations, err = snap.EvalsByJob(args.JobID)
+	reply.Evaluations, err = snap.EvalsByJob(args.RequestNamespace(), args.JobID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,301 @@
+package nomad
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// defaultWebhookTimeout is the timeout of admission webhook requests if
+	// none is configured.
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+// jobMutator is an admission controller that modifies jobs before they are
+// validated.
+type jobMutator interface {
+	// Name is the name of the admission controller
+	Name() string
+
+	// Mutate returns the modified job along with any warnings, or an error
+	// to reject the job.
+	Mutate(job *structs.Job) (*structs.Job, []error, error)
+}
+
+// jobValidator is an admission controller that checks jobs before they are
+// registered.
+type jobValidator interface {
+	// Name is the name of the admission controller
+	Name() string
+
+	// Validate returns the warnings of non-fatal violations, or an error to
+	// reject the job.
+	Validate(job *structs.Job) ([]error, error)
+}
+
+// newAdmissionControllers returns the mutating and validating admission
+// controllers of the configuration, in the order they run. The built-in
+// controllers always run.
+func newAdmissionControllers(config *AdmissionConfig) ([]jobMutator, []jobValidator) {
+	mutators := []jobMutator{jobCanonicalizer{}}
+	validators := []jobValidator{jobValidate{}}
+
+	if config != nil && len(config.DatacenterConstraints) != 0 {
+		mutators = append(mutators, &jobDatacenterConstraints{
+			constraints: config.DatacenterConstraints,
+		})
+	}
+	mutators = append(mutators, jobImplicitConstraints{})
+
+	if config == nil {
+		return mutators, validators
+	}
+	if config.WebhookURL != "" {
+		mutators = append(mutators, newJobWebhook(config.WebhookURL, config.WebhookTimeout))
+	}
+	if len(config.RequiredMeta) != 0 || len(config.RecommendedMeta) != 0 {
+		validators = append(validators, &jobMetaValidator{
+			required:    config.RequiredMeta,
+			recommended: config.RecommendedMeta,
+		})
+	}
+	if len(config.ImagePrefixes) != 0 {
+		validators = append(validators, &jobImageValidator{
+			prefixes: config.ImagePrefixes,
+		})
+	}
+	return mutators, validators
+}
+
+// jobCanonicalizer initializes the fields of the job that are not set.
+type jobCanonicalizer struct{}
+
+func (jobCanonicalizer) Name() string {
+	return "canonicalize"
+}
+
+func (jobCanonicalizer) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	job.InitFields()
+	return job, nil, nil
+}
+
+// jobDatacenterConstraints adds the configured constraints of the
+// datacenters the job may run in.
+type jobDatacenterConstraints struct {
+	constraints map[string][]*structs.Constraint
+}
+
+func (*jobDatacenterConstraints) Name() string {
+	return "datacenter-constraints"
+}
+
+func (m *jobDatacenterConstraints) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	for _, dc := range job.Datacenters {
+		for _, c := range m.constraints[dc] {
+			job.Constraints = addConstraint(job.Constraints, c)
+		}
+	}
+	return job, nil, nil
+}
+
+// jobImplicitConstraints adds a constraint on the drivers of the tasks to
+// each task group, so that the drivers a job requires are visible in its
+// specification.
+type jobImplicitConstraints struct{}
+
+func (jobImplicitConstraints) Name() string {
+	return "implicit-constraints"
+}
+
+func (jobImplicitConstraints) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if task.Driver == "" {
+				continue
+			}
+			tg.Constraints = addConstraint(tg.Constraints, &structs.Constraint{
+				LTarget: fmt.Sprintf("$attr.driver.%s", task.Driver),
+				RTarget: "1",
+				Operand: "=",
+			})
+		}
+	}
+	return job, nil, nil
+}
+
+// addConstraint appends a copy of the constraint unless an equal one is
+// already in the list.
+func addConstraint(constraints []*structs.Constraint, c *structs.Constraint) []*structs.Constraint {
+	for _, existing := range constraints {
+		if *existing == *c {
+			return constraints
+		}
+	}
+	out := *c
+	return append(constraints, &out)
+}
+
+// jobWebhook is an admission controller that POSTs the job to an external
+// service, which may patch or reject it. Jobs are rejected if the service
+// can't be reached.
+type jobWebhook struct {
+	url    string
+	client *http.Client
+}
+
+// newJobWebhook returns a webhook admission controller for the URL.
+func newJobWebhook(url string, timeout time.Duration) *jobWebhook {
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &jobWebhook{
+		url: url,
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: cleanhttp.DefaultTransport(),
+		},
+	}
+}
+
+func (*jobWebhook) Name() string {
+	return "webhook"
+}
+
+func (w *jobWebhook) Mutate(job *structs.Job) (*structs.Job, []error, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(&structs.JobAdmissionRequest{Job: job}); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", &buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("admission webhook failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("admission webhook failed: unexpected status %d", resp.StatusCode)
+	}
+
+	var out structs.JobAdmissionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, nil, fmt.Errorf("admission webhook failed: %v", err)
+	}
+
+	var warnings []error
+	for _, warning := range out.Warnings {
+		warnings = append(warnings, fmt.Errorf("%s", warning))
+	}
+	if !out.Allowed {
+		return nil, warnings, fmt.Errorf("job rejected by admission webhook: %s", out.Reason)
+	}
+
+	// Use the patched job, which can't move to another ID or namespace
+	if out.Job != nil {
+		if out.Job.ID != job.ID || out.Job.Namespace != job.Namespace {
+			return nil, warnings, fmt.Errorf("admission webhook may not change the ID or namespace of the job")
+		}
+		out.Job.InitFields()
+		job = out.Job
+	}
+	return job, warnings, nil
+}
+
+// jobValidate runs the validation of the job specification.
+type jobValidate struct{}
+
+func (jobValidate) Name() string {
+	return "validate"
+}
+
+func (jobValidate) Validate(job *structs.Job) ([]error, error) {
+	return nil, job.Validate()
+}
+
+// jobMetaValidator rejects jobs missing a required meta key and warns about
+// jobs missing a recommended one.
+type jobMetaValidator struct {
+	required    []string
+	recommended []string
+}
+
+func (*jobMetaValidator) Name() string {
+	return "meta"
+}
+
+func (v *jobMetaValidator) Validate(job *structs.Job) ([]error, error) {
+	var mErr multierror.Error
+	for _, key := range v.required {
+		if _, ok := job.Meta[key]; !ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Missing required meta key %q", key))
+		}
+	}
+
+	var warnings []error
+	for _, key := range v.recommended {
+		if _, ok := job.Meta[key]; !ok {
+			warnings = append(warnings, fmt.Errorf("Missing recommended meta key %q", key))
+		}
+	}
+	return warnings, mErr.ErrorOrNil()
+}
+
+// jobImageValidator rejects Docker tasks whose image doesn't start with one
+// of the allowed prefixes.
+type jobImageValidator struct {
+	prefixes []string
+}
+
+func (*jobImageValidator) Name() string {
+	return "images"
+}
+
+func (v *jobImageValidator) Validate(job *structs.Job) ([]error, error) {
+	var mErr multierror.Error
+	for _, tg := range job.TaskGroups {
+		for _, task := range tg.Tasks {
+			if task.Driver != "docker" {
+				continue
+			}
+			image, _ := task.Config["image"].(string)
+			if !v.allowed(image) {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf(
+					"Task %q image %q is not from an allowed registry", task.Name, image))
+			}
+		}
+	}
+	return nil, mErr.ErrorOrNil()
+}
+
+// allowed returns whether the image starts with one of the prefixes. A
+// prefix only matches whole components of the image name, so that the
+// registry "registry.example.com" doesn't admit images of
+// "registry.example.com.attacker.io". The tag or digest may follow a prefix
+// naming a repository.
+func (v *jobImageValidator) allowed(image string) bool {
+	for _, prefix := range v.prefixes {
+		if prefix == "" || !strings.HasPrefix(image, prefix) {
+			continue
+		}
+		if len(image) == len(prefix) || strings.HasSuffix(prefix, "/") {
+			return true
+		}
+		switch image[len(prefix)] {
+		case '/':
+			return true
+		case ':', '@':
+			// A port of the registry otherwise
+			if strings.Contains(prefix, "/") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package nomad
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestJobImplicitConstraints(t *testing.T) {
+	job := mock.Job()
+	job.TaskGroups[0].Tasks = append(job.TaskGroups[0].Tasks, &structs.Task{
+		Name:   "sidecar",
+		Driver: "exec",
+	})
+
+	out, warnings, err := jobImplicitConstraints{}.Mutate(job)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("err: %v %v", err, warnings)
+	}
+
+	// The driver shared by both tasks is only added once
+	expect := []*structs.Constraint{
+		&structs.Constraint{
+			LTarget: "$attr.driver.exec",
+			RTarget: "1",
+			Operand: "=",
+		},
+	}
+	if !reflect.DeepEqual(out.TaskGroups[0].Constraints, expect) {
+		t.Fatalf("bad: %#v", out.TaskGroups[0].Constraints)
+	}
+
+	// Mutating again doesn't duplicate the constraint
+	out, _, _ = jobImplicitConstraints{}.Mutate(out)
+	if !reflect.DeepEqual(out.TaskGroups[0].Constraints, expect) {
+		t.Fatalf("bad: %#v", out.TaskGroups[0].Constraints)
+	}
+}
+
+func TestJobDatacenterConstraints(t *testing.T) {
+	c := &structs.Constraint{
+		LTarget: "$meta.rack",
+		RTarget: "r1",
+		Operand: "=",
+	}
+	m := &jobDatacenterConstraints{
+		constraints: map[string][]*structs.Constraint{
+			"dc2": []*structs.Constraint{c},
+		},
+	}
+
+	// Jobs of other datacenters are left untouched
+	job := mock.Job()
+	out, _, err := m.Mutate(job)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out.Constraints) != 1 {
+		t.Fatalf("bad: %#v", out.Constraints)
+	}
+
+	// Jobs of the datacenter get a copy of the constraint
+	job.Datacenters = append(job.Datacenters, "dc2")
+	out, _, err = m.Mutate(job)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(out.Constraints) != 2 || !reflect.DeepEqual(out.Constraints[1], c) || out.Constraints[1] == c {
+		t.Fatalf("bad: %#v", out.Constraints)
+	}
+}
+
+func TestJobMetaValidator(t *testing.T) {
+	v := &jobMetaValidator{
+		required:    []string{"owner"},
+		recommended: []string{"team", "owner"},
+	}
+
+	job := mock.Job()
+	warnings, err := v.Validate(job)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), `"team"`) {
+		t.Fatalf("bad: %#v", warnings)
+	}
+
+	delete(job.Meta, "owner")
+	if _, err := v.Validate(job); err == nil || !strings.Contains(err.Error(), `"owner"`) {
+		t.Fatalf("expected meta error, got: %v", err)
+	}
+}
+
+func TestJobImageValidator(t *testing.T) {
+	v := &jobImageValidator{prefixes: []string{"registry.example.com/"}}
+
+	// Tasks of other drivers are not checked
+	job := mock.Job()
+	if _, err := v.Validate(job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	task := job.TaskGroups[0].Tasks[0]
+	task.Driver = "docker"
+	task.Config = map[string]interface{}{"image": "registry.example.com/web:1.0"}
+	if _, err := v.Validate(job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	task.Config["image"] = "redis:latest"
+	if _, err := v.Validate(job); err == nil || !strings.Contains(err.Error(), "not from an allowed registry") {
+		t.Fatalf("expected image error, got: %v", err)
+	}
+}
+
+func TestJobImageValidator_Allowed(t *testing.T) {
+	v := &jobImageValidator{prefixes: []string{"registry.example.com", "docker.io/library/redis"}}
+	cases := []struct {
+		image   string
+		allowed bool
+	}{
+		{"registry.example.com/web:1.0", true},
+		{"registry.example.com", true},
+		{"registry.example.com.attacker.io/web", false},
+		{"registry.example.com:5000/web", false},
+		{"docker.io/library/redis:3.2", true},
+		{"docker.io/library/redis@sha256:abcd", true},
+		{"docker.io/library/redis/extra", true},
+		{"docker.io/library/redis-evil", false},
+		{"redis", false},
+	}
+	for _, c := range cases {
+		if allowed := v.allowed(c.image); allowed != c.allowed {
+			t.Fatalf("%q: expected allowed %v", c.image, c.allowed)
+		}
+	}
+}
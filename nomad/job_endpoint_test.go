@@ -1,7 +1,11 @@
 package nomad
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,7 +41,7 @@ func TestJobEndpoint_Register(t *testing.T) {
 
 	// Check for the node in the FSM
 	state := s1.fsm.State()
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -125,7 +129,7 @@ func TestJobEndpoint_Register_Existing(t *testing.T) {
 
 	// Check for the node in the FSM
 	state := s1.fsm.State()
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -198,7 +202,7 @@ func TestJobEndpoint_Register_Batch(t *testing.T) {
 
 	// Check for the node in the FSM
 	state := s1.fsm.State()
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -233,6 +237,217 @@ func TestJobEndpoint_Register_GC_Set(t *testing.T) {
 	}
 }
 
+func TestJobEndpoint_Register_Admission(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.AdmissionConfig = &AdmissionConfig{
+			RequiredMeta:    []string{"owner"},
+			RecommendedMeta: []string{"team"},
+			DatacenterConstraints: map[string][]*structs.Constraint{
+				"dc1": []*structs.Constraint{
+					&structs.Constraint{
+						LTarget: "$meta.rack",
+						RTarget: "r1",
+						Operand: "=",
+					},
+				},
+			},
+		}
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// Jobs missing a required meta key are rejected
+	job := mock.Job()
+	delete(job.Meta, "owner")
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "Missing required meta key") {
+		t.Fatalf("expected meta error, got: %v", err)
+	}
+
+	// Jobs missing a recommended meta key are registered with a warning
+	job = mock.Job()
+	req.Job = job
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !strings.Contains(resp.Warnings, `Missing recommended meta key "team"`) {
+		t.Fatalf("bad: %#v", resp.Warnings)
+	}
+
+	// The constraint of the datacenter was added
+	out, err := s1.fsm.State().JobByID(job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected job")
+	}
+	expect := append(job.Constraints, &structs.Constraint{
+		LTarget: "$meta.rack",
+		RTarget: "r1",
+		Operand: "=",
+	})
+	if !reflect.DeepEqual(out.Constraints, expect) {
+		t.Fatalf("bad: %#v", out.Constraints)
+	}
+}
+
+func TestJobEndpoint_Register_AdmissionWebhook(t *testing.T) {
+	// Reject jobs without a meta key and patch the others
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req structs.JobAdmissionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := structs.JobAdmissionResponse{Allowed: true}
+		if _, ok := req.Job.Meta["reject"]; ok {
+			resp.Allowed = false
+			resp.Reason = "no thanks"
+		} else {
+			req.Job.Priority = 75
+			resp.Job = req.Job
+			resp.Warnings = []string{"patched priority"}
+		}
+		json.NewEncoder(w).Encode(&resp)
+	}))
+	defer ts.Close()
+
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.AdmissionConfig = &AdmissionConfig{
+			WebhookURL: ts.URL,
+		}
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	// The webhook rejects the job
+	job := mock.Job()
+	job.Meta["reject"] = "true"
+	req := &structs.JobRegisterRequest{
+		Job:          job,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.JobRegisterResponse
+	err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "no thanks") {
+		t.Fatalf("expected rejection, got: %v", err)
+	}
+
+	// The webhook patches the job
+	job = mock.Job()
+	req.Job = job
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Warnings != "patched priority" {
+		t.Fatalf("bad: %#v", resp.Warnings)
+	}
+	out, err := s1.fsm.State().JobByID(job.Namespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Priority != 75 {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestJobEndpoint_Register_Namespace(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	ns := mock.Namespace()
+	state := s1.fsm.State()
+	if err := state.UpsertNamespaces(1000, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Jobs can't be registered in unknown namespaces
+	job := mock.Job()
+	job.Namespace = ""
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			Namespace: "unknown",
+		},
+	}
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The job is registered in the namespace of the request
+	job.Namespace = ""
+	req.Namespace = ns.Name
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The same job ID can be registered in the default namespace
+	other := mock.Job()
+	other.ID = job.ID
+	req = &structs.JobRegisterRequest{
+		Job:          other,
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.JobByID(ns.Name, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Namespace != ns.Name {
+		t.Fatalf("bad: %#v", out)
+	}
+	out, err = state.JobByID(structs.DefaultNamespace, job.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Namespace != structs.DefaultNamespace {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// The evaluation is in the namespace of the job
+	eval, err := state.EvalByID(resp.EvalID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if eval == nil || eval.Namespace != structs.DefaultNamespace {
+		t.Fatalf("bad: %#v", eval)
+	}
+
+	// Each namespace only lists its own job
+	list := &structs.JobListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			Namespace: ns.Name,
+		},
+	}
+	var listResp structs.JobListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.List", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(listResp.Jobs) != 1 || listResp.Jobs[0].Namespace != ns.Name {
+		t.Fatalf("bad: %#v", listResp.Jobs)
+	}
+}
+
 func TestJobEndpoint_Evaluate(t *testing.T) {
 	s1 := testServer(t, func(c *Config) {
 		c.NumSchedulers = 0 // Prevent automatic dequeue
@@ -340,7 +555,7 @@ func TestJobEndpoint_Deregister(t *testing.T) {
 
 	// Check for the node in the FSM
 	state := s1.fsm.State()
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -415,9 +630,15 @@ func TestJobEndpoint_GetJob(t *testing.T) {
 	}
 
 	// Make a copy of the origin job and change the service name so that we can
-	// do a deep equal with the response from the GET JOB Api
+	// do a deep equal with the response from the GET JOB Api. The driver of
+	// the tasks is added as an implicit constraint.
 	j := job
 	j.TaskGroups[0].Tasks[0].Services[0].Name = "web-frontend"
+	j.TaskGroups[0].Constraints = append(j.TaskGroups[0].Constraints, &structs.Constraint{
+		LTarget: "$attr.driver.exec",
+		RTarget: "1",
+		Operand: "=",
+	})
 	for tgix, tg := range j.TaskGroups {
 		for tidx, t := range tg.Tasks {
 			for sidx, service := range t.Services {
@@ -495,7 +716,7 @@ func TestJobEndpoint_GetJob_Blocking(t *testing.T) {
 
 	// Job delete fires watches
 	time.AfterFunc(100*time.Millisecond, func() {
-		if err := state.DeleteJob(300, job2.ID); err != nil {
+		if err := state.DeleteJob(300, job2.Namespace, job2.ID); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	})
@@ -594,7 +815,7 @@ func TestJobEndpoint_ListJobs_Blocking(t *testing.T) {
 
 	// Job deletion triggers watches
 	time.AfterFunc(100*time.Millisecond, func() {
-		if err := state.DeleteJob(200, job.ID); err != nil {
+		if err := state.DeleteJob(200, job.Namespace, job.ID); err != nil {
 			t.Fatalf("err: %v", err)
 		}
 	})
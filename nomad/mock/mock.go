@@ -58,6 +58,7 @@ func Node() *structs.Node {
 func Job() *structs.Job {
 	job := &structs.Job{
 		Region:      "global",
+		Namespace:   structs.DefaultNamespace,
 		ID:          structs.GenerateUUID(),
 		Name:        "my-job",
 		Type:        structs.JobTypeService,
@@ -82,6 +83,9 @@ func Job() *structs.Job {
 					RestartOnSuccess: true,
 					Mode:             structs.RestartPolicyModeDelay,
 				},
+				EphemeralDisk: &structs.EphemeralDisk{
+					SizeMB: 150,
+				},
 				Tasks: []*structs.Task{
 					&structs.Task{
 						Name:   "web",
@@ -135,6 +139,7 @@ func Job() *structs.Job {
 func SystemJob() *structs.Job {
 	job := &structs.Job{
 		Region:      "global",
+		Namespace:   structs.DefaultNamespace,
 		ID:          structs.GenerateUUID(),
 		Name:        "my-job",
 		Type:        structs.JobTypeSystem,
@@ -159,6 +164,9 @@ func SystemJob() *structs.Job {
 					RestartOnSuccess: true,
 					Mode:             structs.RestartPolicyModeDelay,
 				},
+				EphemeralDisk: &structs.EphemeralDisk{
+					SizeMB: 150,
+				},
 				Tasks: []*structs.Task{
 					&structs.Task{
 						Name:   "web",
@@ -192,11 +200,12 @@ func SystemJob() *structs.Job {
 
 func Eval() *structs.Evaluation {
 	eval := &structs.Evaluation{
-		ID:       structs.GenerateUUID(),
-		Priority: 50,
-		Type:     structs.JobTypeService,
-		JobID:    structs.GenerateUUID(),
-		Status:   structs.EvalStatusPending,
+		ID:        structs.GenerateUUID(),
+		Namespace: structs.DefaultNamespace,
+		Priority:  50,
+		Type:      structs.JobTypeService,
+		JobID:     structs.GenerateUUID(),
+		Status:    structs.EvalStatusPending,
 	}
 	return eval
 }
@@ -210,6 +219,7 @@ func Alloc() *structs.Allocation {
 		Resources: &structs.Resources{
 			CPU:      500,
 			MemoryMB: 256,
+			DiskMB:   150,
 			Networks: []*structs.NetworkResource{
 				&structs.NetworkResource{
 					Device:        "eth0",
@@ -244,6 +254,7 @@ func Alloc() *structs.Allocation {
 		DesiredStatus: structs.AllocDesiredStatusRun,
 		ClientStatus:  structs.AllocClientStatusPending,
 	}
+	alloc.Namespace = alloc.Job.Namespace
 	alloc.JobID = alloc.Job.ID
 	return alloc
 }
@@ -257,3 +268,62 @@ func Plan() *structs.Plan {
 func PlanResult() *structs.PlanResult {
 	return &structs.PlanResult{}
 }
+
+func ACLPolicy() *structs.ACLPolicy {
+	return &structs.ACLPolicy{
+		Name:        "policy-" + structs.GenerateUUID()[:8],
+		Description: "Read jobs and nodes",
+		Rules: `
+job {
+	policy = "read"
+}
+node {
+	policy = "read"
+}
+`,
+	}
+}
+
+func Namespace() *structs.Namespace {
+	return &structs.Namespace{
+		Name:        "namespace-" + structs.GenerateUUID()[:8],
+		Description: "Isolated team namespace",
+	}
+}
+
+func QuotaSpec() *structs.QuotaSpec {
+	return &structs.QuotaSpec{
+		Name:        "quota-" + structs.GenerateUUID()[:8],
+		Description: "Limits of batch jobs",
+		Limits: []*structs.QuotaLimit{
+			&structs.QuotaLimit{
+				Region:   "global",
+				CPU:      2000,
+				MemoryMB: 1024,
+				DiskMB:   1000,
+				Allocs:   4,
+			},
+		},
+	}
+}
+
+func ACLToken() *structs.ACLToken {
+	return &structs.ACLToken{
+		AccessorID: structs.GenerateUUID(),
+		SecretID:   structs.GenerateUUID(),
+		Name:       "my cool token",
+		Type:       structs.ACLClientToken,
+		Policies:   []string{"foo", "bar"},
+		CreateTime: time.Now().UTC(),
+	}
+}
+
+func ACLManagementToken() *structs.ACLToken {
+	return &structs.ACLToken{
+		AccessorID: structs.GenerateUUID(),
+		SecretID:   structs.GenerateUUID(),
+		Name:       "management",
+		Type:       structs.ACLManagementToken,
+		CreateTime: time.Now().UTC(),
+	}
+}
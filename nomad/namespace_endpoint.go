@@ -0,0 +1,207 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+// Namespace endpoint is used for managing the namespaces jobs are
+// registered in
+type Namespace struct {
+	srv *Server
+}
+
+// checkManagement returns an error if ACLs are enabled and the secret ID is
+// not of a management token.
+func (n *Namespace) checkManagement(secretID string) error {
+	if aclObj, err := n.srv.ResolveToken(secretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+	return nil
+}
+
+// checkRead returns an error if ACLs are enabled and the secret ID can't
+// read jobs.
+func (n *Namespace) checkRead(secretID string) error {
+	if aclObj, err := n.srv.ResolveToken(secretID); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+	return nil
+}
+
+// UpsertNamespaces is used to create or update namespaces
+func (n *Namespace) UpsertNamespaces(args *structs.NamespaceUpsertRequest, reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("Namespace.UpsertNamespaces", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "namespace", "upsert_namespaces"}, time.Now())
+
+	if err := n.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.Namespaces) == 0 {
+		return fmt.Errorf("must specify one or more namespaces")
+	}
+	for _, ns := range args.Namespaces {
+		if err := ns.Validate(); err != nil {
+			return fmt.Errorf("namespace %q invalid: %v", ns.Name, err)
+		}
+	}
+
+	// Commit this update via Raft
+	resp, index, err := n.srv.raftApply(structs.NamespaceUpsertRequestType, args)
+	if err != nil {
+		n.srv.logger.Printf("[ERR] nomad.namespace: UpsertNamespaces failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// DeleteNamespaces is used to delete namespaces. Namespaces with registered
+// jobs and the default namespace can't be deleted.
+func (n *Namespace) DeleteNamespaces(args *structs.NamespaceDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := n.srv.forward("Namespace.DeleteNamespaces", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "namespace", "delete_namespaces"}, time.Now())
+
+	if err := n.checkManagement(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Validate the arguments
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify one or more namespaces to delete")
+	}
+	for _, name := range args.Names {
+		if name == structs.DefaultNamespace {
+			return fmt.Errorf("default namespace can not be deleted")
+		}
+	}
+
+	// Commit this update via Raft
+	resp, index, err := n.srv.raftApply(structs.NamespaceDeleteRequestType, args)
+	if err != nil {
+		n.srv.logger.Printf("[ERR] nomad.namespace: DeleteNamespaces failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ListNamespaces is used to list the namespaces
+func (n *Namespace) ListNamespaces(args *structs.NamespaceListRequest, reply *structs.NamespaceListResponse) error {
+	if done, err := n.srv.forward("Namespace.ListNamespaces", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "namespace", "list_namespaces"}, time.Now())
+
+	if err := n.checkRead(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "namespaces"}),
+		run: func() error {
+			// Capture all the namespaces
+			snap, err := n.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.Namespaces()
+			if err != nil {
+				return err
+			}
+
+			var namespaces []*structs.Namespace
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				namespaces = append(namespaces, raw.(*structs.Namespace))
+			}
+			reply.Namespaces = namespaces
+
+			// Use the last index that affected the namespace table
+			index, err := snap.Index("namespaces")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			n.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
+
+// GetNamespace is used to get a specific namespace
+func (n *Namespace) GetNamespace(args *structs.NamespaceSpecificRequest, reply *structs.SingleNamespaceResponse) error {
+	if done, err := n.srv.forward("Namespace.GetNamespace", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "namespace", "get_namespace"}, time.Now())
+
+	if err := n.checkRead(args.AuthToken); err != nil {
+		return err
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Namespace: args.Name}),
+		run: func() error {
+			// Look for the namespace
+			snap, err := n.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.NamespaceByName(args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Namespace = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the namespace table
+				index, err := snap.Index("namespaces")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			n.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return n.srv.blockingRPC(&opts)
+}
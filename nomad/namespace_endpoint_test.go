@@ -0,0 +1,165 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestNamespaceEndpoint_UpsertDelete(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	ns := mock.Namespace()
+	req := &structs.NamespaceUpsertRequest{
+		Namespaces:   []*structs.Namespace{ns},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.UpsertNamespaces", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	out, err := s1.fsm.State().NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Description != ns.Description {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Invalid namespaces are rejected
+	req.Namespaces = []*structs.Namespace{{Name: "not valid"}}
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.UpsertNamespaces", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The default namespace can't be deleted
+	del := &structs.NamespaceDeleteRequest{
+		Names:        []string{structs.DefaultNamespace},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.DeleteNamespaces", del, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	del.Names = []string{ns.Name}
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.DeleteNamespaces", del, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = s1.fsm.State().NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("namespace not deleted: %#v", out)
+	}
+}
+
+func TestNamespaceEndpoint_ListGet(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	ns := mock.Namespace()
+	if err := s1.fsm.State().UpsertNamespaces(1000, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	list := &structs.NamespaceListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var listResp structs.NamespaceListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.ListNamespaces", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if listResp.Index != 1000 {
+		t.Fatalf("bad index: %d", listResp.Index)
+	}
+	if len(listResp.Namespaces) != 2 ||
+		listResp.Namespaces[0].Name != structs.DefaultNamespace ||
+		listResp.Namespaces[1].Name != ns.Name {
+		t.Fatalf("bad: %#v", listResp.Namespaces)
+	}
+
+	get := &structs.NamespaceSpecificRequest{
+		Name:         ns.Name,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.SingleNamespaceResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.GetNamespace", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Namespace == nil || getResp.Namespace.Name != ns.Name || getResp.Index != 1000 {
+		t.Fatalf("bad: %#v", getResp)
+	}
+
+	// Missing namespaces return nothing
+	get.Name = "missing"
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.GetNamespace", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Namespace != nil {
+		t.Fatalf("bad: %#v", getResp.Namespace)
+	}
+}
+
+func TestNamespaceEndpoint_ACL(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	root := bootstrapACL(t, s1)
+	policy := mock.ACLPolicy()
+	token := mock.ACLToken()
+	token.Policies = []string{policy.Name}
+	state := s1.fsm.State()
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertACLTokens(1001, []*structs.ACLToken{token}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Client tokens can't manage namespaces
+	req := &structs.NamespaceUpsertRequest{
+		Namespaces: []*structs.Namespace{mock.Namespace()},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "Namespace.UpsertNamespaces", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied, got: %v", err)
+	}
+
+	// But can list them
+	list := &structs.NamespaceListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var listResp structs.NamespaceListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.ListNamespaces", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Management tokens can manage namespaces
+	req.AuthToken = root.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "Namespace.UpsertNamespaces", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
@@ -223,9 +223,147 @@ func evaluatePlan(snap *state.StateSnapshot, plan *structs.Plan) (*structs.PlanR
 			result.NodeAllocation[nodeID] = nodeAlloc
 		}
 	}
+
+	// Ensure the plan doesn't exceed the quota of a namespace. Schedulers
+	// running in parallel each track the usage of the state they planned
+	// against, so only the usage here accounts for all of their plans.
+	exceeded, err := evaluateQuotas(snap, result)
+	if err != nil {
+		return nil, err
+	}
+	if len(exceeded) != 0 {
+		// Force the scheduler to refresh the usage and plan again
+		allocIndex, err := snap.Index("allocs")
+		if err != nil {
+			return nil, err
+		}
+		quotaIndex, err := snap.Index("quota_usage")
+		if err != nil {
+			return nil, err
+		}
+		result.RefreshIndex = maxUint64(result.RefreshIndex, maxUint64(allocIndex, quotaIndex))
+		result.NodeUpdate = nil
+		result.NodeAllocation = nil
+	}
 	return result, nil
 }
 
+// evaluateQuotas returns the namespaces whose quota the result exceeds. A
+// namespace is only returned if the result raises its usage in an exhausted
+// dimension, so stopping or shrinking allocations is always allowed.
+func evaluateQuotas(snap *state.StateSnapshot, result *structs.PlanResult) ([]string, error) {
+	before := make(map[string]*structs.QuotaUsage)
+	after := make(map[string]*structs.QuotaUsage)
+	usage := func(namespace string) (*structs.QuotaUsage, error) {
+		if namespace == "" {
+			namespace = structs.DefaultNamespace
+		}
+		if u, ok := after[namespace]; ok {
+			return u, nil
+		}
+		u, err := snap.QuotaUsageByNamespace(namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quota usage of '%s': %v", namespace, err)
+		}
+		if u == nil {
+			u = &structs.QuotaUsage{Namespace: namespace}
+		}
+		before[namespace] = u
+		after[namespace] = u.Copy()
+		return after[namespace], nil
+	}
+
+	// Remove the current version of the stopped and updated allocations
+	released := make(map[string]struct{})
+	release := func(allocID string) error {
+		if _, ok := released[allocID]; ok {
+			return nil
+		}
+		released[allocID] = struct{}{}
+		existing, err := snap.AllocByID(allocID)
+		if err != nil {
+			return fmt.Errorf("failed to get alloc '%s': %v", allocID, err)
+		}
+		if existing == nil || existing.TerminalStatus() {
+			return nil
+		}
+		u, err := usage(existing.Namespace)
+		if err != nil {
+			return err
+		}
+		u.Subtract(existing.Resources)
+		return nil
+	}
+	for _, updates := range result.NodeUpdate {
+		for _, alloc := range updates {
+			if err := release(alloc.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Add the placed allocations, tracking the region of their jobs
+	regions := make(map[string]string)
+	for _, allocs := range result.NodeAllocation {
+		for _, alloc := range allocs {
+			if err := release(alloc.ID); err != nil {
+				return nil, err
+			}
+			if alloc.TerminalStatus() || alloc.Job == nil {
+				continue
+			}
+			u, err := usage(alloc.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			u.Add(alloc.Resources)
+			regions[u.Namespace] = alloc.Job.Region
+		}
+	}
+
+	var exceeded []string
+	for namespace, region := range regions {
+		ns, err := snap.NamespaceByName(namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get namespace '%s': %v", namespace, err)
+		}
+		if ns == nil || ns.Quota == "" {
+			continue
+		}
+		quota, err := snap.QuotaSpecByName(ns.Quota)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quota '%s': %v", ns.Quota, err)
+		}
+		if quota == nil {
+			continue
+		}
+		limit := quota.LimitForRegion(region)
+		if limit == nil {
+			continue
+		}
+
+		b, a := before[namespace], after[namespace]
+		for _, dimension := range limit.Exhausted(a) {
+			var raised bool
+			switch dimension {
+			case structs.QuotaDimensionCPU:
+				raised = a.CPU > b.CPU
+			case structs.QuotaDimensionMemory:
+				raised = a.MemoryMB > b.MemoryMB
+			case structs.QuotaDimensionDisk:
+				raised = a.DiskMB > b.DiskMB
+			case structs.QuotaDimensionAllocs:
+				raised = a.Allocs > b.Allocs
+			}
+			if raised {
+				exceeded = append(exceeded, namespace)
+				break
+			}
+		}
+	}
+	return exceeded, nil
+}
+
 // evaluateNodePlan is used to evalute the plan for a single node,
 // returning if the plan is valid or if an error is encountered
 func evaluateNodePlan(snap *state.StateSnapshot, plan *structs.Plan, nodeID string) (bool, error) {
@@ -250,6 +250,56 @@ func TestPlanApply_EvalPlan_Partial_AllAtOnce(t *testing.T) {
 	}
 }
 
+func TestPlanApply_EvalPlan_QuotaExceeded(t *testing.T) {
+	state := testStateStore(t)
+	node := mock.Node()
+	state.UpsertNode(1000, node)
+
+	// Limit the namespace and use most of its quota
+	quota := mock.QuotaSpec()
+	quota.Limits[0] = &structs.QuotaLimit{Region: "global", CPU: 1000}
+	state.UpsertQuotaSpecs(1001, []*structs.QuotaSpec{quota})
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	state.UpsertNamespaces(1002, []*structs.Namespace{ns})
+	existing := mock.Alloc()
+	existing.NodeID = node.ID
+	existing.Namespace = ns.Name
+	existing.Resources = &structs.Resources{CPU: 600}
+	state.UpsertAllocs(1003, []*structs.Allocation{existing})
+	snap, _ := state.Snapshot()
+
+	// Placing past the quota is rejected
+	alloc := mock.Alloc()
+	alloc.NodeID = node.ID
+	alloc.Namespace = ns.Name
+	alloc.Resources = &structs.Resources{CPU: 500}
+	plan := &structs.Plan{
+		NodeAllocation: map[string][]*structs.Allocation{
+			node.ID: []*structs.Allocation{alloc},
+		},
+	}
+	result, err := evaluatePlan(snap, plan)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(result.NodeAllocation) != 0 || result.RefreshIndex == 0 {
+		t.Fatalf("bad: %#v", result)
+	}
+
+	// Placing while stopping the existing allocation is allowed
+	plan.NodeUpdate = map[string][]*structs.Allocation{
+		node.ID: []*structs.Allocation{existing},
+	}
+	result, err = evaluatePlan(snap, plan)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if _, ok := result.NodeAllocation[node.ID]; !ok || result.RefreshIndex != 0 {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
 func TestPlanApply_EvalNodePlan_Simple(t *testing.T) {
 	state := testStateStore(t)
 	node := mock.Node()
@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/armon/go-metrics"
@@ -19,6 +20,16 @@ func (p *Plan) Submit(args *structs.PlanRequest, reply *structs.PlanResponse) er
 	}
 	defer metrics.MeasureSince([]string{"nomad", "plan", "submit"}, time.Now())
 
+	// Plans are submitted by the scheduler workers, which don't hold ACL
+	// tokens. Only the worker that dequeued the evaluation knows its token,
+	// so the plan must carry the token of the outstanding evaluation.
+	if args.Plan == nil {
+		return fmt.Errorf("missing plan")
+	}
+	if token, ok := p.srv.evalBroker.Outstanding(args.Plan.EvalID); !ok || token != args.Plan.EvalToken {
+		return structs.ErrPermissionDenied
+	}
+
 	// Submit the plan to the queue
 	future, err := p.srv.planQueue.Enqueue(args.Plan)
 	if err != nil {
@@ -0,0 +1,244 @@
+package nomad
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/nomad/watch"
+)
+
+// Quota endpoint is used for managing the quota specifications limiting the
+// resources of namespaces
+type Quota struct {
+	srv *Server
+}
+
+// UpsertQuotaSpecs is used to create or update quota specifications
+func (q *Quota) UpsertQuotaSpecs(args *structs.QuotaSpecUpsertRequest, reply *structs.GenericResponse) error {
+	if done, err := q.srv.forward("Quota.UpsertQuotaSpecs", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "quota", "upsert_quota_specs"}, time.Now())
+
+	// Check management level permissions
+	if aclObj, err := q.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if len(args.Quotas) == 0 {
+		return fmt.Errorf("must specify one or more quotas")
+	}
+	for _, quota := range args.Quotas {
+		if err := quota.Validate(); err != nil {
+			return fmt.Errorf("quota %q invalid: %v", quota.Name, err)
+		}
+	}
+
+	// Commit this update via Raft
+	resp, index, err := q.srv.raftApply(structs.QuotaSpecUpsertRequestType, args)
+	if err != nil {
+		q.srv.logger.Printf("[ERR] nomad.quota: UpsertQuotaSpecs failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// DeleteQuotaSpecs is used to delete quota specifications. Quotas attached to
+// namespaces can't be deleted.
+func (q *Quota) DeleteQuotaSpecs(args *structs.QuotaSpecDeleteRequest, reply *structs.GenericResponse) error {
+	if done, err := q.srv.forward("Quota.DeleteQuotaSpecs", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "quota", "delete_quota_specs"}, time.Now())
+
+	// Check management level permissions
+	if aclObj, err := q.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.IsManagement() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Validate the arguments
+	if len(args.Names) == 0 {
+		return fmt.Errorf("must specify one or more quotas to delete")
+	}
+
+	// Commit this update via Raft
+	resp, index, err := q.srv.raftApply(structs.QuotaSpecDeleteRequestType, args)
+	if err != nil {
+		q.srv.logger.Printf("[ERR] nomad.quota: DeleteQuotaSpecs failed: %v", err)
+		return err
+	}
+	if err, ok := resp.(error); ok && err != nil {
+		return err
+	}
+
+	reply.Index = index
+	return nil
+}
+
+// ListQuotaSpecs is used to list the quota specifications
+func (q *Quota) ListQuotaSpecs(args *structs.QuotaSpecListRequest, reply *structs.QuotaSpecListResponse) error {
+	if done, err := q.srv.forward("Quota.ListQuotaSpecs", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "quota", "list_quota_specs"}, time.Now())
+
+	if aclObj, err := q.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "quota_specs"}),
+		run: func() error {
+			// Capture all the quota specifications
+			snap, err := q.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.QuotaSpecs()
+			if err != nil {
+				return err
+			}
+
+			var quotas []*structs.QuotaSpec
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				quotas = append(quotas, raw.(*structs.QuotaSpec))
+			}
+			reply.Quotas = quotas
+
+			// Use the last index that affected the quota table
+			index, err := snap.Index("quota_specs")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			q.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return q.srv.blockingRPC(&opts)
+}
+
+// GetQuotaSpec is used to get a specific quota specification
+func (q *Quota) GetQuotaSpec(args *structs.QuotaSpecSpecificRequest, reply *structs.SingleQuotaSpecResponse) error {
+	if done, err := q.srv.forward("Quota.GetQuotaSpec", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "quota", "get_quota_spec"}, time.Now())
+
+	if aclObj, err := q.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{QuotaSpec: args.Name}),
+		run: func() error {
+			// Look for the quota specification
+			snap, err := q.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			out, err := snap.QuotaSpecByName(args.Name)
+			if err != nil {
+				return err
+			}
+
+			// Setup the output
+			reply.Quota = out
+			if out != nil {
+				reply.Index = out.ModifyIndex
+			} else {
+				// Use the last index that affected the quota table
+				index, err := snap.Index("quota_specs")
+				if err != nil {
+					return err
+				}
+				reply.Index = index
+			}
+
+			// Set the query response
+			q.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return q.srv.blockingRPC(&opts)
+}
+
+// ListQuotaUsages is used to list the resources used by the allocations of
+// each namespace in the region
+func (q *Quota) ListQuotaUsages(args *structs.QuotaUsageListRequest, reply *structs.QuotaUsageListResponse) error {
+	if done, err := q.srv.forward("Quota.ListQuotaUsages", args, args, reply); done {
+		return err
+	}
+	defer metrics.MeasureSince([]string{"nomad", "quota", "list_quota_usages"}, time.Now())
+
+	if aclObj, err := q.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowJobRead() {
+		return structs.ErrPermissionDenied
+	}
+
+	// Setup the blocking query
+	opts := blockingOptions{
+		queryOpts: &args.QueryOptions,
+		queryMeta: &reply.QueryMeta,
+		watch:     watch.NewItems(watch.Item{Table: "quota_usage"}),
+		run: func() error {
+			// Capture the usage of all the namespaces
+			snap, err := q.srv.fsm.State().Snapshot()
+			if err != nil {
+				return err
+			}
+			iter, err := snap.QuotaUsages()
+			if err != nil {
+				return err
+			}
+
+			var usages []*structs.QuotaUsage
+			for {
+				raw := iter.Next()
+				if raw == nil {
+					break
+				}
+				usages = append(usages, raw.(*structs.QuotaUsage))
+			}
+			reply.Usages = usages
+
+			// Use the last index that affected the usage table
+			index, err := snap.Index("quota_usage")
+			if err != nil {
+				return err
+			}
+			reply.Index = index
+
+			// Set the query response
+			q.srv.setQueryMeta(&reply.QueryMeta)
+			return nil
+		}}
+	return q.srv.blockingRPC(&opts)
+}
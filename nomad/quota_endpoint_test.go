@@ -0,0 +1,170 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestQuotaEndpoint_UpsertDelete(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	quota := mock.QuotaSpec()
+	req := &structs.QuotaSpecUpsertRequest{
+		Quotas:       []*structs.QuotaSpec{quota},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	var resp structs.GenericResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.UpsertQuotaSpecs", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if resp.Index == 0 {
+		t.Fatalf("bad index: %d", resp.Index)
+	}
+
+	out, err := s1.fsm.State().QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil || out.Description != quota.Description {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Invalid quotas are rejected
+	invalid := mock.QuotaSpec()
+	invalid.Limits[0].CPU = -1
+	req.Quotas = []*structs.QuotaSpec{invalid}
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.UpsertQuotaSpecs", req, &resp); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	del := &structs.QuotaSpecDeleteRequest{
+		Names:        []string{quota.Name},
+		WriteRequest: structs.WriteRequest{Region: "global"},
+	}
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.DeleteQuotaSpecs", del, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = s1.fsm.State().QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("quota not deleted: %#v", out)
+	}
+}
+
+func TestQuotaEndpoint_ListGet(t *testing.T) {
+	s1 := testServer(t, nil)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	quota := mock.QuotaSpec()
+	state := s1.fsm.State()
+	if err := state.UpsertQuotaSpecs(1000, []*structs.QuotaSpec{quota}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	list := &structs.QuotaSpecListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var listResp structs.QuotaSpecListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.ListQuotaSpecs", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if listResp.Index != 1000 {
+		t.Fatalf("bad index: %d", listResp.Index)
+	}
+	if len(listResp.Quotas) != 1 || listResp.Quotas[0].Name != quota.Name {
+		t.Fatalf("bad: %#v", listResp.Quotas)
+	}
+
+	get := &structs.QuotaSpecSpecificRequest{
+		Name:         quota.Name,
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var getResp structs.SingleQuotaSpecResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.GetQuotaSpec", get, &getResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if getResp.Quota == nil || getResp.Quota.Name != quota.Name || getResp.Index != 1000 {
+		t.Fatalf("bad: %#v", getResp)
+	}
+
+	// The usage of namespaces is tracked from their allocations
+	alloc := mock.Alloc()
+	if err := state.UpsertAllocs(1001, []*structs.Allocation{alloc}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	usages := &structs.QuotaUsageListRequest{
+		QueryOptions: structs.QueryOptions{Region: "global"},
+	}
+	var usagesResp structs.QuotaUsageListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.ListQuotaUsages", usages, &usagesResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usagesResp.Index != 1001 {
+		t.Fatalf("bad index: %d", usagesResp.Index)
+	}
+	if len(usagesResp.Usages) != 1 || usagesResp.Usages[0].CPU != alloc.Resources.CPU {
+		t.Fatalf("bad: %#v", usagesResp.Usages)
+	}
+}
+
+func TestQuotaEndpoint_ACL(t *testing.T) {
+	s1 := testACLServer(t)
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+
+	root := bootstrapACL(t, s1)
+	policy := mock.ACLPolicy()
+	token := mock.ACLToken()
+	token.Policies = []string{policy.Name}
+	state := s1.fsm.State()
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertACLTokens(1001, []*structs.ACLToken{token}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Client tokens can't manage quotas
+	req := &structs.QuotaSpecUpsertRequest{
+		Quotas: []*structs.QuotaSpec{mock.QuotaSpec()},
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.GenericResponse
+	err := msgpackrpc.CallWithCodec(codec, "Quota.UpsertQuotaSpecs", req, &resp)
+	if err == nil || err.Error() != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("expected permission denied, got: %v", err)
+	}
+
+	// But can list them
+	list := &structs.QuotaSpecListRequest{
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var listResp structs.QuotaSpecListResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.ListQuotaSpecs", list, &listResp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Management tokens can manage quotas
+	req.AuthToken = root.SecretID
+	if err := msgpackrpc.CallWithCodec(codec, "Quota.UpsertQuotaSpecs", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
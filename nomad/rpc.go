@@ -153,6 +153,9 @@ func (s *Server) handleMultiplex(conn net.Conn) {
 func (s *Server) handleNomadConn(conn net.Conn) {
 	defer conn.Close()
 	rpcCodec := NewServerCodec(conn)
+	if s.config.AuditLogger != nil {
+		rpcCodec = newAuditCodec(s, conn.RemoteAddr().String(), rpcCodec)
+	}
 	for {
 		select {
 		case <-s.shutdownCh:
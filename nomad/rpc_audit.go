@@ -0,0 +1,85 @@
+package nomad
+
+import (
+	"bytes"
+	"net/rpc"
+
+	"github.com/hashicorp/go-msgpack/codec"
+	"github.com/hashicorp/nomad/audit"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+// auditCodec wraps the codec of an RPC connection to record the requests it
+// serves in the audit log. The requests of a connection are served one at a
+// time, so the codec only tracks the current one.
+type auditCodec struct {
+	rpc.ServerCodec
+	srv        *Server
+	remoteAddr string
+
+	method string
+	args   interface{}
+}
+
+// newAuditCodec returns a codec recording the RPCs of the connection.
+func newAuditCodec(srv *Server, remoteAddr string, c rpc.ServerCodec) rpc.ServerCodec {
+	return &auditCodec{
+		ServerCodec: c,
+		srv:         srv,
+		remoteAddr:  remoteAddr,
+	}
+}
+
+func (c *auditCodec) ReadRequestHeader(r *rpc.Request) error {
+	err := c.ServerCodec.ReadRequestHeader(r)
+	c.method = r.ServiceMethod
+	c.args = nil
+	return err
+}
+
+func (c *auditCodec) ReadRequestBody(body interface{}) error {
+	err := c.ServerCodec.ReadRequestBody(body)
+	c.args = body
+	return err
+}
+
+func (c *auditCodec) WriteResponse(r *rpc.Response, body interface{}) error {
+	c.srv.auditRPC(c.remoteAddr, c.method, c.args, r.Error)
+	return c.ServerCodec.WriteResponse(r, body)
+}
+
+// auditRPC records the RPC in the audit log if it is enabled for the
+// method. Arguments that aren't requests, such as the ones of Status.Ping,
+// are treated as reads.
+func (s *Server) auditRPC(remoteAddr, method string, args interface{}, rpcErr string) {
+	logger := s.config.AuditLogger
+	read := true
+	if info, ok := args.(structs.RPCInfo); ok {
+		read = info.IsRead()
+	}
+	if !logger.Enabled(method, read) {
+		return
+	}
+
+	entry := &audit.Entry{
+		Type:       audit.TypeRPC,
+		RemoteAddr: remoteAddr,
+		Path:       method,
+		Error:      rpcErr,
+	}
+	if args != nil {
+		var buf bytes.Buffer
+		if err := codec.NewEncoder(&buf, structs.MsgpackHandle).Encode(args); err == nil {
+			entry.PayloadHash = audit.HashPayload(buf.Bytes())
+		}
+	}
+	if req, ok := args.(interface {
+		RequestAuthToken() string
+	}); ok {
+		entry.Identity = s.TokenAccessor(req.RequestAuthToken())
+	}
+
+	if err := logger.Log(entry); err != nil {
+		s.logger.Printf("[ERR] nomad.rpc: failed to write audit log: %v", err)
+	}
+}
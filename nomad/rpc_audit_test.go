@@ -0,0 +1,99 @@
+package nomad
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/net-rpc-msgpackrpc"
+	"github.com/hashicorp/nomad/audit"
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+	"github.com/hashicorp/nomad/testutil"
+)
+
+func TestRPC_Audit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nomad")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	logger, err := audit.NewLogger(&audit.Config{Path: path})
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer logger.Close()
+
+	s1 := testServer(t, func(c *Config) {
+		c.NumSchedulers = 0 // Prevent automatic dequeue
+		c.ACLEnabled = true
+		c.AuditLogger = logger
+	})
+	defer s1.Shutdown()
+	codec := rpcClient(t, s1)
+	testutil.WaitForLeader(t, s1.RPC)
+	token := bootstrapACL(t, s1)
+
+	// Register a job and read it back
+	job := mock.Job()
+	req := &structs.JobRegisterRequest{
+		Job: job,
+		WriteRequest: structs.WriteRequest{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp structs.JobRegisterResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	get := &structs.JobSpecificRequest{
+		JobID: job.ID,
+		QueryOptions: structs.QueryOptions{
+			Region:    "global",
+			AuthToken: token.SecretID,
+		},
+	}
+	var resp2 structs.SingleJobResponse
+	if err := msgpackrpc.CallWithCodec(codec, "Job.GetJob", get, &resp2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Registering without a token is denied
+	req.AuthToken = ""
+	if err := msgpackrpc.CallWithCodec(codec, "Job.Register", req, &resp); err == nil {
+		t.Fatalf("expected permission denied")
+	}
+
+	// Only the writes were recorded
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	defer f.Close()
+	var entries []*audit.Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if e.Path == "Job.Register" || e.Path == "Job.GetJob" {
+			entries = append(entries, &e)
+		}
+	}
+	if len(entries) != 2 {
+		t.Fatalf("bad: %#v", entries)
+	}
+	if e := entries[0]; e.Type != audit.TypeRPC || e.Identity != token.AccessorID ||
+		e.Error != "" || e.RemoteAddr == "" || e.PayloadHash == "" {
+		t.Fatalf("bad: %#v", e)
+	}
+	if e := entries[1]; e.Identity != "" || e.Error != structs.ErrPermissionDenied.Error() {
+		t.Fatalf("bad: %#v", e)
+	}
+}
@@ -15,7 +15,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/hashicorp/consul/tlsutil"
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/nomad/state"
 	"github.com/hashicorp/raft"
 	"github.com/hashicorp/raft-boltdb"
@@ -129,13 +129,16 @@ type Server struct {
 
 // Holds the RPC endpoints
 type endpoints struct {
-	Status *Status
-	Node   *Node
-	Job    *Job
-	Eval   *Eval
-	Plan   *Plan
-	Alloc  *Alloc
-	Region *Region
+	Status    *Status
+	Node      *Node
+	Job       *Job
+	Eval      *Eval
+	Plan      *Plan
+	Alloc     *Alloc
+	Region    *Region
+	ACL       *ACL
+	Namespace *Namespace
+	Quota     *Quota
 }
 
 // NewServer is used to construct a new Nomad server from the
@@ -166,10 +169,25 @@ func NewServer(config *Config) (*Server, error) {
 		return nil, err
 	}
 
+	// Configure TLS
+	var tlsWrap tlsutil.RegionWrapper
+	var incomingTLS *tls.Config
+	if config.TLSConfig != nil {
+		tlsWrap, err = config.TLSConfig.OutgoingTLSWrapper()
+		if err != nil {
+			return nil, err
+		}
+		incomingTLS, err = config.TLSConfig.IncomingTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Create the server
 	s := &Server{
 		config:      config,
-		connPool:    NewPool(config.LogOutput, serverRPCCache, serverMaxStreams, nil),
+		connPool:    NewPool(config.LogOutput, serverRPCCache, serverMaxStreams, tlsWrap),
+		rpcTLS:      incomingTLS,
 		logger:      logger,
 		rpcServer:   rpc.NewServer(),
 		peers:       make(map[string][]*serverParts),
@@ -182,8 +200,7 @@ func NewServer(config *Config) (*Server, error) {
 	}
 
 	// Initialize the RPC layer
-	// TODO: TLS...
-	if err := s.setupRPC(nil); err != nil {
+	if err := s.setupRPC(tlsWrap); err != nil {
 		s.Shutdown()
 		logger.Printf("[ERR] nomad: failed to start RPC layer: %s", err)
 		return nil, fmt.Errorf("Failed to start RPC layer: %v", err)
@@ -347,15 +364,18 @@ func (s *Server) Leave() error {
 }
 
 // setupRPC is used to setup the RPC listener
-func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
+func (s *Server) setupRPC(tlsWrap tlsutil.RegionWrapper) error {
 	// Create endpoints
 	s.endpoints.Status = &Status{s}
 	s.endpoints.Node = &Node{s}
-	s.endpoints.Job = &Job{s}
+	s.endpoints.Job = NewJobEndpoints(s)
 	s.endpoints.Eval = &Eval{s}
 	s.endpoints.Plan = &Plan{s}
 	s.endpoints.Alloc = &Alloc{s}
 	s.endpoints.Region = &Region{s}
+	s.endpoints.ACL = &ACL{s}
+	s.endpoints.Namespace = &Namespace{s}
+	s.endpoints.Quota = &Quota{s}
 
 	// Register the handlers
 	s.rpcServer.Register(s.endpoints.Status)
@@ -365,6 +385,9 @@ func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
 	s.rpcServer.Register(s.endpoints.Plan)
 	s.rpcServer.Register(s.endpoints.Alloc)
 	s.rpcServer.Register(s.endpoints.Region)
+	s.rpcServer.Register(s.endpoints.ACL)
+	s.rpcServer.Register(s.endpoints.Namespace)
+	s.rpcServer.Register(s.endpoints.Quota)
 
 	list, err := net.ListenTCP("tcp", s.config.RPCAddr)
 	if err != nil {
@@ -389,11 +412,10 @@ func (s *Server) setupRPC(tlsWrap tlsutil.DCWrapper) error {
 		return fmt.Errorf("RPC advertise address is not advertisable: %v", addr)
 	}
 
-	// Provide a DC specific wrapper. Raft replication is only
-	// ever done in the same datacenter, so we can provide it as a constant.
-	// wrapper := tlsutil.SpecificDC(s.config.Datacenter, tlsWrap)
-	// TODO: TLS...
-	s.raftLayer = NewRaftLayer(s.rpcAdvertise, nil)
+	// Provide a region specific wrapper. Raft replication is only
+	// ever done in the same region, so we can provide it as a constant.
+	wrapper := tlsutil.SpecificRegion(s.config.Region, tlsWrap)
+	s.raftLayer = NewRaftLayer(s.rpcAdvertise, wrapper)
 	return nil
 }
 
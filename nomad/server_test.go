@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/nomad/helper/tlsutil"
 	"github.com/hashicorp/nomad/testutil"
 )
 
@@ -119,3 +120,66 @@ func TestServer_Regions(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	})
 }
+
+// testTLSConfig returns a TLS config using the test certificates. The server
+// certificate is valid for server.global.nomad.
+func testTLSConfig() *tlsutil.Config {
+	conf := tlsutil.NewConfig(
+		"../helper/tlsutil/test-resources/ca.pem",
+		"../helper/tlsutil/test-resources/server.pem",
+		"../helper/tlsutil/test-resources/server-key.pem")
+	conf.VerifyIncoming = true
+	conf.VerifyOutgoing = true
+	conf.VerifyServerHostname = true
+	return conf
+}
+
+func TestServer_TLS(t *testing.T) {
+	s1 := testServer(t, func(c *Config) {
+		c.RequireTLS = true
+		c.TLSConfig = testTLSConfig()
+	})
+	defer s1.Shutdown()
+
+	s2 := testServer(t, func(c *Config) {
+		c.DevDisableBootstrap = true
+		c.RequireTLS = true
+		c.TLSConfig = testTLSConfig()
+	})
+	defer s2.Shutdown()
+	testJoin(t, s1, s2)
+
+	// Raft runs over TLS
+	for _, s := range []*Server{s1, s2} {
+		testutil.WaitForResult(func() (bool, error) {
+			peers, _ := s.raftPeers.Peers()
+			return len(peers) == 2, nil
+		}, func(err error) {
+			t.Fatalf("should have 2 peers")
+		})
+	}
+
+	// Plaintext RPC is rejected
+	var out struct{}
+	plain := NewPool(s1.config.LogOutput, time.Second, 1, nil)
+	defer plain.Shutdown()
+	if err := plain.RPC(s1.config.Region, s1.config.RPCAddr, 1, "Status.Ping", struct{}{}, &out); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// RPC with a certificate of the region succeeds
+	wrap, err := testTLSConfig().OutgoingTLSWrapper()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	pool := NewPool(s1.config.LogOutput, time.Second, 1, wrap)
+	defer pool.Shutdown()
+	if err := pool.RPC(s1.config.Region, s1.config.RPCAddr, 1, "Status.Ping", struct{}{}, &out); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Servers of other regions are not trusted
+	if err := pool.RPC("other", s1.config.RPCAddr, 1, "Status.Ping", struct{}{}, &out); err == nil {
+		t.Fatalf("expected hostname verification to fail")
+	}
+}
@@ -21,6 +21,11 @@ func stateStoreSchema() *memdb.DBSchema {
 		jobTableSchema,
 		evalTableSchema,
 		allocTableSchema,
+		aclPolicyTableSchema,
+		aclTokenTableSchema,
+		namespaceTableSchema,
+		quotaSpecTableSchema,
+		quotaUsageTableSchema,
 	}
 
 	// Add each of the tables
@@ -82,14 +87,31 @@ func jobTableSchema() *memdb.TableSchema {
 		Indexes: map[string]*memdb.IndexSchema{
 			// Primary index is used for job management
 			// and simple direct lookup. ID is required to be
-			// unique.
+			// unique within the namespace.
 			"id": &memdb.IndexSchema{
 				Name:         "id",
 				AllowMissing: false,
 				Unique:       true,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+						&memdb.StringFieldIndex{
+							Field:     "ID",
+							Lowercase: true,
+						},
+					},
+				},
+			},
+
+			// Namespace index is used to lookup jobs by namespace
+			"namespace": &memdb.IndexSchema{
+				Name:         "namespace",
+				AllowMissing: false,
+				Unique:       false,
 				Indexer: &memdb.StringFieldIndex{
-					Field:     "ID",
-					Lowercase: true,
+					Field: "Namespace",
 				},
 			},
 			"type": &memdb.IndexSchema{
@@ -141,14 +163,31 @@ func evalTableSchema() *memdb.TableSchema {
 				},
 			},
 
-			// Job index is used to lookup allocations by job
+			// Job index is used to lookup evaluations by job
 			"job": &memdb.IndexSchema{
 				Name:         "job",
 				AllowMissing: false,
 				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+						&memdb.StringFieldIndex{
+							Field:     "JobID",
+							Lowercase: true,
+						},
+					},
+				},
+			},
+
+			// Namespace index is used to lookup evaluations by namespace
+			"namespace": &memdb.IndexSchema{
+				Name:         "namespace",
+				AllowMissing: false,
+				Unique:       false,
 				Indexer: &memdb.StringFieldIndex{
-					Field:     "JobID",
-					Lowercase: true,
+					Field: "Namespace",
 				},
 			},
 		},
@@ -188,9 +227,26 @@ func allocTableSchema() *memdb.TableSchema {
 				Name:         "job",
 				AllowMissing: false,
 				Unique:       false,
+				Indexer: &memdb.CompoundIndex{
+					Indexes: []memdb.Indexer{
+						&memdb.StringFieldIndex{
+							Field: "Namespace",
+						},
+						&memdb.StringFieldIndex{
+							Field:     "JobID",
+							Lowercase: true,
+						},
+					},
+				},
+			},
+
+			// Namespace index is used to lookup allocations by namespace
+			"namespace": &memdb.IndexSchema{
+				Name:         "namespace",
+				AllowMissing: false,
+				Unique:       false,
 				Indexer: &memdb.StringFieldIndex{
-					Field:     "JobID",
-					Lowercase: true,
+					Field: "Namespace",
 				},
 			},
 
@@ -206,3 +262,121 @@ func allocTableSchema() *memdb.TableSchema {
 		},
 	}
 }
+
+// aclPolicyTableSchema returns the MemDB schema for the ACL policy table.
+// This table is used to store the policies granted to tokens.
+func aclPolicyTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "acl_policy",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the unique name of the policy
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+		},
+	}
+}
+
+// aclTokenTableSchema returns the MemDB schema for the ACL token table.
+// This table is used to store the tokens granting access to the APIs.
+func aclTokenTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "acl_token",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the accessor ID
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "AccessorID",
+				},
+			},
+
+			// Secret index is used to resolve the token of requests
+			"secret": &memdb.IndexSchema{
+				Name:         "secret",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "SecretID",
+				},
+			},
+		},
+	}
+}
+
+// namespaceTableSchema returns the MemDB schema for the namespace table.
+// This table is used to store the namespaces jobs are registered in.
+func namespaceTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "namespaces",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the unique name of the namespace
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+
+			// Quota index is used to find the namespaces a quota
+			// specification is attached to
+			"quota": &memdb.IndexSchema{
+				Name:         "quota",
+				AllowMissing: true,
+				Unique:       false,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Quota",
+				},
+			},
+		},
+	}
+}
+
+// quotaSpecTableSchema returns the MemDB schema for the quota specification
+// table. This table is used to store the limits of namespaces.
+func quotaSpecTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "quota_specs",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the unique name of the quota
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Name",
+				},
+			},
+		},
+	}
+}
+
+// quotaUsageTableSchema returns the MemDB schema for the quota usage table.
+// This table is used to track the resources used by the allocations of each
+// namespace. It is not persisted in snapshots but rebuilt from the
+// allocations when they are restored.
+func quotaUsageTableSchema() *memdb.TableSchema {
+	return &memdb.TableSchema{
+		Name: "quota_usage",
+		Indexes: map[string]*memdb.IndexSchema{
+			// Primary index is the namespace of the allocations
+			"id": &memdb.IndexSchema{
+				Name:         "id",
+				AllowMissing: false,
+				Unique:       true,
+				Indexer: &memdb.StringFieldIndex{
+					Field: "Namespace",
+				},
+			},
+		},
+	}
+}
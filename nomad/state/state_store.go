@@ -39,6 +39,18 @@ func NewStateStore(logOutput io.Writer) (*StateStore, error) {
 		return nil, fmt.Errorf("state store setup failed: %v", err)
 	}
 
+	// Create the default namespace, which always exists
+	txn := db.Txn(true)
+	defaultNamespace := &structs.Namespace{
+		Name:        structs.DefaultNamespace,
+		Description: "Default shared namespace",
+	}
+	if err := txn.Insert("namespaces", defaultNamespace); err != nil {
+		txn.Abort()
+		return nil, fmt.Errorf("default namespace insert failed: %v", err)
+	}
+	txn.Commit()
+
 	// Create the state store
 	s := &StateStore{
 		logger: log.New(logOutput, "", log.LstdFlags),
@@ -273,8 +285,22 @@ func (s *StateStore) UpsertJob(index uint64, job *structs.Job) error {
 	watcher.Add(watch.Item{Table: "jobs"})
 	watcher.Add(watch.Item{Job: job.ID})
 
+	// Jobs registered before namespaces existed belong to the default one
+	if job.Namespace == "" {
+		job.Namespace = structs.DefaultNamespace
+	}
+
+	// Check the namespace of the job exists
+	ns, err := txn.First("namespaces", "id", job.Namespace)
+	if err != nil {
+		return fmt.Errorf("namespace lookup failed: %v", err)
+	}
+	if ns == nil {
+		return fmt.Errorf("namespace %q not found", job.Namespace)
+	}
+
 	// Check if the job already exists
-	existing, err := txn.First("jobs", "id", job.ID)
+	existing, err := txn.First("jobs", "id", job.Namespace, job.ID)
 	if err != nil {
 		return fmt.Errorf("job lookup failed: %v", err)
 	}
@@ -302,7 +328,7 @@ func (s *StateStore) UpsertJob(index uint64, job *structs.Job) error {
 }
 
 // DeleteJob is used to deregister a job
-func (s *StateStore) DeleteJob(index uint64, jobID string) error {
+func (s *StateStore) DeleteJob(index uint64, namespace, jobID string) error {
 	txn := s.db.Txn(true)
 	defer txn.Abort()
 
@@ -311,7 +337,7 @@ func (s *StateStore) DeleteJob(index uint64, jobID string) error {
 	watcher.Add(watch.Item{Job: jobID})
 
 	// Lookup the node
-	existing, err := txn.First("jobs", "id", jobID)
+	existing, err := txn.First("jobs", "id", namespace, jobID)
 	if err != nil {
 		return fmt.Errorf("job lookup failed: %v", err)
 	}
@@ -332,11 +358,11 @@ func (s *StateStore) DeleteJob(index uint64, jobID string) error {
 	return nil
 }
 
-// JobByID is used to lookup a job by its ID
-func (s *StateStore) JobByID(id string) (*structs.Job, error) {
+// JobByID is used to lookup a job by its namespace and ID
+func (s *StateStore) JobByID(namespace, id string) (*structs.Job, error) {
 	txn := s.db.Txn(false)
 
-	existing, err := txn.First("jobs", "id", id)
+	existing, err := txn.First("jobs", "id", namespace, id)
 	if err != nil {
 		return nil, fmt.Errorf("job lookup failed: %v", err)
 	}
@@ -359,6 +385,17 @@ func (s *StateStore) Jobs() (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
+// JobsByNamespace returns an iterator over all the jobs of the namespace
+func (s *StateStore) JobsByNamespace(namespace string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("jobs", "namespace", namespace)
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
 // JobsByScheduler returns an iterator over all the jobs with the specific
 // scheduler type.
 func (s *StateStore) JobsByScheduler(schedulerType string) (memdb.ResultIterator, error) {
@@ -413,6 +450,12 @@ func (s *StateStore) nestedUpsertEval(txn *memdb.Txn, index uint64, eval *struct
 		return fmt.Errorf("eval lookup failed: %v", err)
 	}
 
+	// Evaluations created before namespaces existed belong to the default
+	// one
+	if eval.Namespace == "" {
+		eval.Namespace = structs.DefaultNamespace
+	}
+
 	// Update the indexes
 	if existing != nil {
 		eval.CreateIndex = existing.(*structs.Evaluation).CreateIndex
@@ -466,6 +509,9 @@ func (s *StateStore) DeleteEval(index uint64, evals []string, allocs []string) e
 			return fmt.Errorf("alloc delete failed: %v", err)
 		}
 		realAlloc := existing.(*structs.Allocation)
+		if err := updateQuotaUsage(txn, index, realAlloc, nil, watcher); err != nil {
+			return err
+		}
 		watcher.Add(watch.Item{Alloc: realAlloc.ID})
 		watcher.Add(watch.Item{AllocEval: realAlloc.EvalID})
 		watcher.Add(watch.Item{AllocJob: realAlloc.JobID})
@@ -500,12 +546,12 @@ func (s *StateStore) EvalByID(id string) (*structs.Evaluation, error) {
 	return nil, nil
 }
 
-// EvalsByJob returns all the evaluations by job id
-func (s *StateStore) EvalsByJob(jobID string) ([]*structs.Evaluation, error) {
+// EvalsByJob returns all the evaluations by job namespace and id
+func (s *StateStore) EvalsByJob(namespace, jobID string) ([]*structs.Evaluation, error) {
 	txn := s.db.Txn(false)
 
 	// Get an iterator over the node allocations
-	iter, err := txn.Get("evals", "job", jobID)
+	iter, err := txn.Get("evals", "job", namespace, jobID)
 	if err != nil {
 		return nil, err
 	}
@@ -533,6 +579,18 @@ func (s *StateStore) Evals() (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
+// EvalsByNamespace returns an iterator over all the evaluations of the
+// namespace
+func (s *StateStore) EvalsByNamespace(namespace string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("evals", "namespace", namespace)
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
 // UpdateAllocFromClient is used to update an allocation based on input
 // from a client. While the schedulers are the authority on the allocation for
 // most things, some updates are authoritative from the client. Specifically,
@@ -573,6 +631,11 @@ func (s *StateStore) UpdateAllocFromClient(index uint64, alloc *structs.Allocati
 	// Update the modify index
 	copyAlloc.ModifyIndex = index
 
+	// Release the quota of allocations the client stopped
+	if err := updateQuotaUsage(txn, index, exist, copyAlloc, watcher); err != nil {
+		return err
+	}
+
 	// Update the allocation
 	if err := txn.Insert("allocs", copyAlloc); err != nil {
 		return fmt.Errorf("alloc insert failed: %v", err)
@@ -604,16 +667,26 @@ func (s *StateStore) UpsertAllocs(index uint64, allocs []*structs.Allocation) er
 			return fmt.Errorf("alloc lookup failed: %v", err)
 		}
 
+		// Allocations created before namespaces existed belong to the
+		// default one
+		if alloc.Namespace == "" {
+			alloc.Namespace = structs.DefaultNamespace
+		}
+
+		var exist *structs.Allocation
 		if existing == nil {
 			alloc.CreateIndex = index
 			alloc.ModifyIndex = index
 		} else {
-			exist := existing.(*structs.Allocation)
+			exist = existing.(*structs.Allocation)
 			alloc.CreateIndex = exist.CreateIndex
 			alloc.ModifyIndex = index
 			alloc.ClientStatus = exist.ClientStatus
 			alloc.ClientDescription = exist.ClientDescription
 		}
+		if err := updateQuotaUsage(txn, index, exist, alloc, watcher); err != nil {
+			return err
+		}
 		if err := txn.Insert("allocs", alloc); err != nil {
 			return fmt.Errorf("alloc insert failed: %v", err)
 		}
@@ -670,12 +743,12 @@ func (s *StateStore) AllocsByNode(node string) ([]*structs.Allocation, error) {
 	return out, nil
 }
 
-// AllocsByJob returns all the allocations by job id
-func (s *StateStore) AllocsByJob(jobID string) ([]*structs.Allocation, error) {
+// AllocsByJob returns all the allocations by job namespace and id
+func (s *StateStore) AllocsByJob(namespace, jobID string) ([]*structs.Allocation, error) {
 	txn := s.db.Txn(false)
 
 	// Get an iterator over the node allocations
-	iter, err := txn.Get("allocs", "job", jobID)
+	iter, err := txn.Get("allocs", "job", namespace, jobID)
 	if err != nil {
 		return nil, err
 	}
@@ -724,6 +797,578 @@ func (s *StateStore) Allocs() (memdb.ResultIterator, error) {
 	return iter, nil
 }
 
+// AllocsByNamespace returns an iterator over all the allocations of the
+// namespace
+func (s *StateStore) AllocsByNamespace(namespace string) (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	iter, err := txn.Get("allocs", "namespace", namespace)
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertACLPolicies is used to create or update ACL policies
+func (s *StateStore) UpsertACLPolicies(index uint64, policies []*structs.ACLPolicy) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "acl_policy"})
+
+	for _, policy := range policies {
+		watcher.Add(watch.Item{ACLPolicy: policy.Name})
+
+		existing, err := txn.First("acl_policy", "id", policy.Name)
+		if err != nil {
+			return fmt.Errorf("policy lookup failed: %v", err)
+		}
+
+		// Setup the indexes correctly
+		if existing != nil {
+			policy.CreateIndex = existing.(*structs.ACLPolicy).CreateIndex
+			policy.ModifyIndex = index
+		} else {
+			policy.CreateIndex = index
+			policy.ModifyIndex = index
+		}
+
+		if err := txn.Insert("acl_policy", policy); err != nil {
+			return fmt.Errorf("policy insert failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// DeleteACLPolicies is used to delete ACL policies by name
+func (s *StateStore) DeleteACLPolicies(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "acl_policy"})
+
+	for _, name := range names {
+		watcher.Add(watch.Item{ACLPolicy: name})
+		existing, err := txn.First("acl_policy", "id", name)
+		if err != nil {
+			return fmt.Errorf("policy lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("policy %q not found", name)
+		}
+		if err := txn.Delete("acl_policy", existing); err != nil {
+			return fmt.Errorf("policy delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_policy", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// ACLPolicyByName is used to lookup an ACL policy by its name
+func (s *StateStore) ACLPolicyByName(name string) (*structs.ACLPolicy, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("acl_policy", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("policy lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.ACLPolicy), nil
+	}
+	return nil, nil
+}
+
+// ACLPolicies returns an iterator over all the ACL policies
+func (s *StateStore) ACLPolicies() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("acl_policy", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertACLTokens is used to create or update ACL tokens
+func (s *StateStore) UpsertACLTokens(index uint64, tokens []*structs.ACLToken) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "acl_token"})
+
+	for _, token := range tokens {
+		watcher.Add(watch.Item{ACLToken: token.AccessorID})
+		if err := s.nestedUpsertACLToken(txn, index, token); err != nil {
+			return err
+		}
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// nestedUpsertACLToken is used to nest an ACL token upsert within a
+// transaction
+func (s *StateStore) nestedUpsertACLToken(txn *memdb.Txn, index uint64, token *structs.ACLToken) error {
+	existing, err := txn.First("acl_token", "id", token.AccessorID)
+	if err != nil {
+		return fmt.Errorf("token lookup failed: %v", err)
+	}
+
+	// Setup the indexes correctly
+	if existing != nil {
+		token.CreateIndex = existing.(*structs.ACLToken).CreateIndex
+		token.ModifyIndex = index
+	} else {
+		token.CreateIndex = index
+		token.ModifyIndex = index
+	}
+
+	if err := txn.Insert("acl_token", token); err != nil {
+		return fmt.Errorf("token insert failed: %v", err)
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_token", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+	return nil
+}
+
+// DeleteACLTokens is used to delete ACL tokens by accessor ID
+func (s *StateStore) DeleteACLTokens(index uint64, accessorIDs []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "acl_token"})
+
+	for _, id := range accessorIDs {
+		watcher.Add(watch.Item{ACLToken: id})
+		existing, err := txn.First("acl_token", "id", id)
+		if err != nil {
+			return fmt.Errorf("token lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("token %q not found", id)
+		}
+		if err := txn.Delete("acl_token", existing); err != nil {
+			return fmt.Errorf("token delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_token", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// BootstrapACLTokens is used to create the initial management token. It
+// fails if the ACL system was already bootstrapped.
+func (s *StateStore) BootstrapACLTokens(index uint64, token *structs.ACLToken) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	existing, err := txn.First("index", "id", "acl_token_bootstrap")
+	if err != nil {
+		return fmt.Errorf("bootstrap lookup failed: %v", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("ACL bootstrap already done")
+	}
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "acl_token"})
+	watcher.Add(watch.Item{ACLToken: token.AccessorID})
+
+	if err := s.nestedUpsertACLToken(txn, index, token); err != nil {
+		return err
+	}
+	if err := txn.Insert("index", &IndexEntry{"acl_token_bootstrap", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// ACLTokenByAccessorID is used to lookup an ACL token by its accessor ID
+func (s *StateStore) ACLTokenByAccessorID(id string) (*structs.ACLToken, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("acl_token", "id", id)
+	if err != nil {
+		return nil, fmt.Errorf("token lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.ACLToken), nil
+	}
+	return nil, nil
+}
+
+// ACLTokenBySecretID is used to lookup an ACL token by its secret ID
+func (s *StateStore) ACLTokenBySecretID(secretID string) (*structs.ACLToken, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("acl_token", "secret", secretID)
+	if err != nil {
+		return nil, fmt.Errorf("token lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.ACLToken), nil
+	}
+	return nil, nil
+}
+
+// ACLTokens returns an iterator over all the ACL tokens
+func (s *StateStore) ACLTokens() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("acl_token", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertNamespaces is used to create or update namespaces
+func (s *StateStore) UpsertNamespaces(index uint64, namespaces []*structs.Namespace) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "namespaces"})
+
+	for _, ns := range namespaces {
+		watcher.Add(watch.Item{Namespace: ns.Name})
+
+		// Ensure the quota specification of the namespace exists
+		if ns.Quota != "" {
+			quota, err := txn.First("quota_specs", "id", ns.Quota)
+			if err != nil {
+				return fmt.Errorf("quota lookup failed: %v", err)
+			}
+			if quota == nil {
+				return fmt.Errorf("quota %q not found", ns.Quota)
+			}
+		}
+
+		existing, err := txn.First("namespaces", "id", ns.Name)
+		if err != nil {
+			return fmt.Errorf("namespace lookup failed: %v", err)
+		}
+
+		// Setup the indexes correctly
+		if existing != nil {
+			ns.CreateIndex = existing.(*structs.Namespace).CreateIndex
+			ns.ModifyIndex = index
+		} else {
+			ns.CreateIndex = index
+			ns.ModifyIndex = index
+		}
+
+		if err := txn.Insert("namespaces", ns); err != nil {
+			return fmt.Errorf("namespace insert failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"namespaces", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// DeleteNamespaces is used to delete namespaces by name. The default
+// namespace and namespaces that still have jobs can't be deleted.
+func (s *StateStore) DeleteNamespaces(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "namespaces"})
+
+	for _, name := range names {
+		if name == structs.DefaultNamespace {
+			return fmt.Errorf("default namespace can not be deleted")
+		}
+
+		watcher.Add(watch.Item{Namespace: name})
+		existing, err := txn.First("namespaces", "id", name)
+		if err != nil {
+			return fmt.Errorf("namespace lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("namespace %q not found", name)
+		}
+
+		// Refuse to orphan the jobs of the namespace
+		job, err := txn.First("jobs", "namespace", name)
+		if err != nil {
+			return fmt.Errorf("job lookup failed: %v", err)
+		}
+		if job != nil {
+			return fmt.Errorf("namespace %q has registered jobs", name)
+		}
+
+		if err := txn.Delete("namespaces", existing); err != nil {
+			return fmt.Errorf("namespace delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"namespaces", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// NamespaceByName is used to lookup a namespace by its name
+func (s *StateStore) NamespaceByName(name string) (*structs.Namespace, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("namespaces", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("namespace lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.Namespace), nil
+	}
+	return nil, nil
+}
+
+// Namespaces returns an iterator over all the namespaces
+func (s *StateStore) Namespaces() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("namespaces", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// UpsertQuotaSpecs is used to create or update quota specifications
+func (s *StateStore) UpsertQuotaSpecs(index uint64, quotas []*structs.QuotaSpec) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "quota_specs"})
+
+	for _, quota := range quotas {
+		watcher.Add(watch.Item{QuotaSpec: quota.Name})
+
+		existing, err := txn.First("quota_specs", "id", quota.Name)
+		if err != nil {
+			return fmt.Errorf("quota lookup failed: %v", err)
+		}
+
+		// Setup the indexes correctly
+		if existing != nil {
+			quota.CreateIndex = existing.(*structs.QuotaSpec).CreateIndex
+			quota.ModifyIndex = index
+		} else {
+			quota.CreateIndex = index
+			quota.ModifyIndex = index
+		}
+
+		if err := txn.Insert("quota_specs", quota); err != nil {
+			return fmt.Errorf("quota insert failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"quota_specs", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// DeleteQuotaSpecs is used to delete quota specifications by name. Quotas
+// attached to namespaces can't be deleted.
+func (s *StateStore) DeleteQuotaSpecs(index uint64, names []string) error {
+	txn := s.db.Txn(true)
+	defer txn.Abort()
+
+	watcher := watch.NewItems()
+	watcher.Add(watch.Item{Table: "quota_specs"})
+
+	for _, name := range names {
+		watcher.Add(watch.Item{QuotaSpec: name})
+		existing, err := txn.First("quota_specs", "id", name)
+		if err != nil {
+			return fmt.Errorf("quota lookup failed: %v", err)
+		}
+		if existing == nil {
+			return fmt.Errorf("quota %q not found", name)
+		}
+
+		// Refuse to leave namespaces referencing a missing quota
+		ns, err := txn.First("namespaces", "quota", name)
+		if err != nil {
+			return fmt.Errorf("namespace lookup failed: %v", err)
+		}
+		if ns != nil {
+			return fmt.Errorf("quota %q is attached to namespace %q", name, ns.(*structs.Namespace).Name)
+		}
+
+		if err := txn.Delete("quota_specs", existing); err != nil {
+			return fmt.Errorf("quota delete failed: %v", err)
+		}
+	}
+	if err := txn.Insert("index", &IndexEntry{"quota_specs", index}); err != nil {
+		return fmt.Errorf("index update failed: %v", err)
+	}
+
+	txn.Defer(func() { s.watch.notify(watcher) })
+	txn.Commit()
+	return nil
+}
+
+// QuotaSpecByName is used to lookup a quota specification by its name
+func (s *StateStore) QuotaSpecByName(name string) (*structs.QuotaSpec, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("quota_specs", "id", name)
+	if err != nil {
+		return nil, fmt.Errorf("quota lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.QuotaSpec), nil
+	}
+	return nil, nil
+}
+
+// QuotaSpecs returns an iterator over all the quota specifications
+func (s *StateStore) QuotaSpecs() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("quota_specs", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// QuotaUsageByNamespace is used to lookup the resources used by the
+// allocations of a namespace. It returns nil if the namespace never had
+// allocations.
+func (s *StateStore) QuotaUsageByNamespace(namespace string) (*structs.QuotaUsage, error) {
+	txn := s.db.Txn(false)
+
+	existing, err := txn.First("quota_usage", "id", namespace)
+	if err != nil {
+		return nil, fmt.Errorf("quota usage lookup failed: %v", err)
+	}
+
+	if existing != nil {
+		return existing.(*structs.QuotaUsage), nil
+	}
+	return nil, nil
+}
+
+// QuotaUsages returns an iterator over the usage of all the namespaces
+func (s *StateStore) QuotaUsages() (memdb.ResultIterator, error) {
+	txn := s.db.Txn(false)
+
+	// Walk the entire table
+	iter, err := txn.Get("quota_usage", "id")
+	if err != nil {
+		return nil, err
+	}
+	return iter, nil
+}
+
+// updateQuotaUsage updates the usage of the namespace of an allocation that
+// starts or stops counting against its quota. Non-terminal allocations
+// count against the quota. existing is the previous version of the
+// allocation, or nil if it is new, and alloc is nil if it is deleted.
+func updateQuotaUsage(txn *memdb.Txn, index uint64, existing, alloc *structs.Allocation, watcher watch.Items) error {
+	wasCounted := existing != nil && !existing.TerminalStatus()
+	isCounted := alloc != nil && !alloc.TerminalStatus()
+	if !wasCounted && !isCounted {
+		return nil
+	}
+
+	namespace := structs.DefaultNamespace
+	if alloc != nil {
+		namespace = alloc.Namespace
+	} else if existing.Namespace != "" {
+		namespace = existing.Namespace
+	}
+
+	raw, err := txn.First("quota_usage", "id", namespace)
+	if err != nil {
+		return fmt.Errorf("quota usage lookup failed: %v", err)
+	}
+	var usage *structs.QuotaUsage
+	if raw != nil {
+		usage = raw.(*structs.QuotaUsage).Copy()
+	} else {
+		usage = &structs.QuotaUsage{
+			Namespace:   namespace,
+			CreateIndex: index,
+		}
+	}
+
+	if wasCounted {
+		usage.Subtract(existing.Resources)
+	}
+	if isCounted {
+		usage.Add(alloc.Resources)
+	}
+	if index > usage.ModifyIndex {
+		usage.ModifyIndex = index
+	}
+
+	if err := txn.Insert("quota_usage", usage); err != nil {
+		return fmt.Errorf("quota usage insert failed: %v", err)
+	}
+
+	// Restored allocations aren't ordered by index, so only ever raise the
+	// index of the table
+	last, err := txn.First("index", "id", "quota_usage")
+	if err != nil {
+		return fmt.Errorf("index lookup failed: %v", err)
+	}
+	if last == nil || last.(*IndexEntry).Value < usage.ModifyIndex {
+		if err := txn.Insert("index", &IndexEntry{"quota_usage", usage.ModifyIndex}); err != nil {
+			return fmt.Errorf("index update failed: %v", err)
+		}
+	}
+	watcher.Add(watch.Item{Table: "quota_usage"})
+	return nil
+}
+
 // Index finds the matching index value
 func (s *StateStore) Index(name string) (uint64, error) {
 	txn := s.db.Txn(false)
@@ -788,6 +1433,9 @@ func (r *StateRestore) NodeRestore(node *structs.Node) error {
 
 // JobRestore is used to restore a job
 func (r *StateRestore) JobRestore(job *structs.Job) error {
+	if job.Namespace == "" {
+		job.Namespace = structs.DefaultNamespace
+	}
 	r.items.Add(watch.Item{Table: "jobs"})
 	r.items.Add(watch.Item{Job: job.ID})
 	if err := r.txn.Insert("jobs", job); err != nil {
@@ -798,6 +1446,9 @@ func (r *StateRestore) JobRestore(job *structs.Job) error {
 
 // EvalRestore is used to restore an evaluation
 func (r *StateRestore) EvalRestore(eval *structs.Evaluation) error {
+	if eval.Namespace == "" {
+		eval.Namespace = structs.DefaultNamespace
+	}
 	r.items.Add(watch.Item{Table: "evals"})
 	r.items.Add(watch.Item{Eval: eval.ID})
 	if err := r.txn.Insert("evals", eval); err != nil {
@@ -808,6 +1459,9 @@ func (r *StateRestore) EvalRestore(eval *structs.Evaluation) error {
 
 // AllocRestore is used to restore an allocation
 func (r *StateRestore) AllocRestore(alloc *structs.Allocation) error {
+	if alloc.Namespace == "" {
+		alloc.Namespace = structs.DefaultNamespace
+	}
 	r.items.Add(watch.Item{Table: "allocs"})
 	r.items.Add(watch.Item{Alloc: alloc.ID})
 	r.items.Add(watch.Item{AllocEval: alloc.EvalID})
@@ -816,6 +1470,48 @@ func (r *StateRestore) AllocRestore(alloc *structs.Allocation) error {
 	if err := r.txn.Insert("allocs", alloc); err != nil {
 		return fmt.Errorf("alloc insert failed: %v", err)
 	}
+
+	// The usage of quotas isn't persisted but rebuilt from the allocations
+	return updateQuotaUsage(r.txn, alloc.ModifyIndex, nil, alloc, r.items)
+}
+
+// ACLPolicyRestore is used to restore an ACL policy
+func (r *StateRestore) ACLPolicyRestore(policy *structs.ACLPolicy) error {
+	r.items.Add(watch.Item{Table: "acl_policy"})
+	r.items.Add(watch.Item{ACLPolicy: policy.Name})
+	if err := r.txn.Insert("acl_policy", policy); err != nil {
+		return fmt.Errorf("policy insert failed: %v", err)
+	}
+	return nil
+}
+
+// ACLTokenRestore is used to restore an ACL token
+func (r *StateRestore) ACLTokenRestore(token *structs.ACLToken) error {
+	r.items.Add(watch.Item{Table: "acl_token"})
+	r.items.Add(watch.Item{ACLToken: token.AccessorID})
+	if err := r.txn.Insert("acl_token", token); err != nil {
+		return fmt.Errorf("token insert failed: %v", err)
+	}
+	return nil
+}
+
+// NamespaceRestore is used to restore a namespace
+func (r *StateRestore) NamespaceRestore(ns *structs.Namespace) error {
+	r.items.Add(watch.Item{Table: "namespaces"})
+	r.items.Add(watch.Item{Namespace: ns.Name})
+	if err := r.txn.Insert("namespaces", ns); err != nil {
+		return fmt.Errorf("namespace insert failed: %v", err)
+	}
+	return nil
+}
+
+// QuotaSpecRestore is used to restore a quota specification
+func (r *StateRestore) QuotaSpecRestore(quota *structs.QuotaSpec) error {
+	r.items.Add(watch.Item{Table: "quota_specs"})
+	r.items.Add(watch.Item{QuotaSpec: quota.Name})
+	if err := r.txn.Insert("quota_specs", quota); err != nil {
+		return fmt.Errorf("quota insert failed: %v", err)
+	}
 	return nil
 }
 
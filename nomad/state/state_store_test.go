@@ -261,7 +261,7 @@ func TestStateStore_UpsertJob_Job(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -281,6 +281,67 @@ func TestStateStore_UpsertJob_Job(t *testing.T) {
 	notify.verify(t)
 }
 
+func TestStateStore_UpsertJob_Namespace(t *testing.T) {
+	state := testStateStore(t)
+	ns := mock.Namespace()
+	if err := state.UpsertNamespaces(1000, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Jobs in an unknown namespace are rejected
+	job := mock.Job()
+	job.Namespace = "unknown"
+	if err := state.UpsertJob(1001, job); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// The same job ID can be registered in different namespaces
+	job1 := mock.Job()
+	job2 := mock.Job()
+	job2.ID = job1.ID
+	job2.Namespace = ns.Name
+	if err := state.UpsertJob(1002, job1); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertJob(1003, job2); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.JobByID(ns.Name, job1.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(job2, out) {
+		t.Fatalf("bad: %#v %#v", job2, out)
+	}
+
+	iter, err := state.JobsByNamespace(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var jobs []*structs.Job
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		jobs = append(jobs, raw.(*structs.Job))
+	}
+	if len(jobs) != 1 || jobs[0] != job2 {
+		t.Fatalf("bad: %#v", jobs)
+	}
+
+	// Jobs without a namespace are registered in the default one
+	job3 := mock.Job()
+	job3.Namespace = ""
+	if err := state.UpsertJob(1004, job3); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.JobByID(structs.DefaultNamespace, job3.ID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out == nil {
+		t.Fatalf("expected job in the default namespace")
+	}
+}
+
 func TestStateStore_UpdateUpsertJob_Job(t *testing.T) {
 	state := testStateStore(t)
 	job := mock.Job()
@@ -302,7 +363,7 @@ func TestStateStore_UpdateUpsertJob_Job(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -343,12 +404,12 @@ func TestStateStore_DeleteJob_Job(t *testing.T) {
 		t.Fatalf("err: %v", err)
 	}
 
-	err = state.DeleteJob(1001, job.ID)
+	err = state.DeleteJob(1001, job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -548,7 +609,7 @@ func TestStateStore_RestoreJob(t *testing.T) {
 	}
 	restore.Commit()
 
-	out, err := state.JobByID(job.ID)
+	out, err := state.JobByID(job.Namespace, job.ID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -799,18 +860,21 @@ func TestStateStore_EvalsByJob(t *testing.T) {
 	eval2 := mock.Eval()
 	eval2.JobID = eval1.JobID
 	eval3 := mock.Eval()
+	eval4 := mock.Eval()
+	eval4.Namespace = "other"
+	eval4.JobID = eval1.JobID
 	evals := []*structs.Evaluation{eval1, eval2}
 
 	err := state.UpsertEvals(1000, evals)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
-	err = state.UpsertEvals(1001, []*structs.Evaluation{eval3})
+	err = state.UpsertEvals(1001, []*structs.Evaluation{eval3, eval4})
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.EvalsByJob(eval1.JobID)
+	out, err := state.EvalsByJob(eval1.Namespace, eval1.JobID)
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -1101,12 +1165,17 @@ func TestStateStore_AllocsByJob(t *testing.T) {
 		allocs = append(allocs, alloc)
 	}
 
-	err := state.UpsertAllocs(1000, allocs)
+	// Allocations of a job with the same ID in another namespace
+	other := mock.Alloc()
+	other.Namespace = "other"
+	other.JobID = "foo"
+
+	err := state.UpsertAllocs(1000, append([]*structs.Allocation{other}, allocs...))
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
 
-	out, err := state.AllocsByJob("foo")
+	out, err := state.AllocsByJob(structs.DefaultNamespace, "foo")
 	if err != nil {
 		t.Fatalf("err: %v", err)
 	}
@@ -1252,6 +1321,583 @@ func setupNotifyTest(state *StateStore, items ...watch.Item) notifyTest {
 	return n
 }
 
+func TestStateStore_UpsertACLPolicies(t *testing.T) {
+	state := testStateStore(t)
+	policy := mock.ACLPolicy()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "acl_policy"},
+		watch.Item{ACLPolicy: policy.Name})
+
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(policy, out) {
+		t.Fatalf("bad: %#v %#v", policy, out)
+	}
+
+	// Updating the policy retains its create index
+	update := mock.ACLPolicy()
+	update.Name = policy.Name
+	if err := state.UpsertACLPolicies(1001, []*structs.ACLPolicy{update}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	index, err := state.Index("acl_policy")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1001 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_DeleteACLPolicies(t *testing.T) {
+	state := testStateStore(t)
+	policy := mock.ACLPolicy()
+
+	if err := state.UpsertACLPolicies(1000, []*structs.ACLPolicy{policy}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "acl_policy"},
+		watch.Item{ACLPolicy: policy.Name})
+
+	if err := state.DeleteACLPolicies(1001, []string{policy.Name}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Deleting a missing policy fails
+	if err := state.DeleteACLPolicies(1002, []string{policy.Name}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertACLTokens(t *testing.T) {
+	state := testStateStore(t)
+	token := mock.ACLToken()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "acl_token"},
+		watch.Item{ACLToken: token.AccessorID})
+
+	if err := state.UpsertACLTokens(1000, []*structs.ACLToken{token}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(token, out) {
+		t.Fatalf("bad: %#v %#v", token, out)
+	}
+
+	out, err = state.ACLTokenBySecretID(token.SecretID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(token, out) {
+		t.Fatalf("bad: %#v %#v", token, out)
+	}
+
+	// Unknown secrets aren't found
+	out, err = state.ACLTokenBySecretID("not-a-token")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	iter, err := state.ACLTokens()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	count := 0
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		count++
+	}
+	if count != 1 {
+		t.Fatalf("bad: %d", count)
+	}
+
+	index, err := state.Index("acl_token")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1000 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_DeleteACLTokens(t *testing.T) {
+	state := testStateStore(t)
+	token := mock.ACLToken()
+
+	if err := state.UpsertACLTokens(1000, []*structs.ACLToken{token}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.DeleteACLTokens(1001, []string{token.AccessorID}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
+func TestStateStore_BootstrapACLTokens(t *testing.T) {
+	state := testStateStore(t)
+	token := mock.ACLManagementToken()
+
+	if err := state.BootstrapACLTokens(1000, token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(token, out) {
+		t.Fatalf("bad: %#v %#v", token, out)
+	}
+
+	// Bootstrapping again fails, even once the token is deleted
+	if err := state.DeleteACLTokens(1001, []string{token.AccessorID}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.BootstrapACLTokens(1002, mock.ACLManagementToken()); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestStateStore_RestoreACL(t *testing.T) {
+	state := testStateStore(t)
+	policy := mock.ACLPolicy()
+	token := mock.ACLToken()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "acl_policy"},
+		watch.Item{ACLPolicy: policy.Name},
+		watch.Item{Table: "acl_token"},
+		watch.Item{ACLToken: token.AccessorID})
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.ACLPolicyRestore(policy); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.ACLTokenRestore(token); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	outPolicy, err := state.ACLPolicyByName(policy.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(outPolicy, policy) {
+		t.Fatalf("Bad: %#v %#v", outPolicy, policy)
+	}
+
+	outToken, err := state.ACLTokenByAccessorID(token.AccessorID)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(outToken, token) {
+		t.Fatalf("Bad: %#v %#v", outToken, token)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertNamespaces(t *testing.T) {
+	state := testStateStore(t)
+	ns := mock.Namespace()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "namespaces"},
+		watch.Item{Namespace: ns.Name})
+
+	if err := state.UpsertNamespaces(1000, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(ns, out) {
+		t.Fatalf("bad: %#v %#v", ns, out)
+	}
+
+	// Updating the namespace retains its create index
+	update := mock.Namespace()
+	update.Name = ns.Name
+	if err := state.UpsertNamespaces(1001, []*structs.Namespace{update}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	out, err = state.NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out.CreateIndex != 1000 || out.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// The default namespace is listed along with the new one
+	iter, err := state.Namespaces()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var names []string
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		names = append(names, raw.(*structs.Namespace).Name)
+	}
+	if !reflect.DeepEqual(names, []string{structs.DefaultNamespace, ns.Name}) {
+		t.Fatalf("bad: %#v", names)
+	}
+
+	index, err := state.Index("namespaces")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1001 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_DeleteNamespaces(t *testing.T) {
+	state := testStateStore(t)
+	ns := mock.Namespace()
+	job := mock.Job()
+	job.Namespace = ns.Name
+
+	if err := state.UpsertNamespaces(1000, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertJob(1001, job); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// The default namespace can't be deleted
+	if err := state.DeleteNamespaces(1002, []string{structs.DefaultNamespace}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	// Namespaces with jobs can't be deleted
+	if err := state.DeleteNamespaces(1002, []string{ns.Name}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if err := state.DeleteJob(1003, job.Namespace, job.ID); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "namespaces"},
+		watch.Item{Namespace: ns.Name})
+
+	if err := state.DeleteNamespaces(1004, []string{ns.Name}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("bad: %#v", out)
+	}
+
+	// Deleting a missing namespace fails
+	if err := state.DeleteNamespaces(1005, []string{ns.Name}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_RestoreNamespace(t *testing.T) {
+	state := testStateStore(t)
+	ns := mock.Namespace()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "namespaces"},
+		watch.Item{Namespace: ns.Name})
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.NamespaceRestore(ns); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	out, err := state.NamespaceByName(ns.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out, ns) {
+		t.Fatalf("Bad: %#v %#v", out, ns)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_UpsertQuotaSpecs(t *testing.T) {
+	state := testStateStore(t)
+	quota := mock.QuotaSpec()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "quota_specs"},
+		watch.Item{QuotaSpec: quota.Name})
+
+	if err := state.UpsertQuotaSpecs(1000, []*structs.QuotaSpec{quota}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(quota, out) {
+		t.Fatalf("bad: %#v %#v", quota, out)
+	}
+
+	// Namespaces can only reference existing quotas
+	ns := mock.Namespace()
+	ns.Quota = "missing"
+	if err := state.UpsertNamespaces(1001, []*structs.Namespace{ns}); err == nil {
+		t.Fatalf("expected error")
+	}
+	ns.Quota = quota.Name
+	if err := state.UpsertNamespaces(1001, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	iter, err := state.QuotaSpecs()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	var quotas []*structs.QuotaSpec
+	for raw := iter.Next(); raw != nil; raw = iter.Next() {
+		quotas = append(quotas, raw.(*structs.QuotaSpec))
+	}
+	if len(quotas) != 1 || quotas[0].Name != quota.Name {
+		t.Fatalf("bad: %#v", quotas)
+	}
+
+	index, err := state.Index("quota_specs")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1000 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_DeleteQuotaSpecs(t *testing.T) {
+	state := testStateStore(t)
+	quota := mock.QuotaSpec()
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+
+	if err := state.UpsertQuotaSpecs(1000, []*structs.QuotaSpec{quota}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.UpsertNamespaces(1001, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Attached quotas can't be deleted
+	if err := state.DeleteQuotaSpecs(1002, []string{quota.Name}); err == nil {
+		t.Fatalf("expected error")
+	}
+
+	ns.Quota = ""
+	if err := state.UpsertNamespaces(1002, []*structs.Namespace{ns}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := state.DeleteQuotaSpecs(1003, []string{quota.Name}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	out, err := state.QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if out != nil {
+		t.Fatalf("quota not deleted: %#v", out)
+	}
+
+	// Missing quotas can't be deleted
+	if err := state.DeleteQuotaSpecs(1004, []string{quota.Name}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestStateStore_QuotaUsage(t *testing.T) {
+	state := testStateStore(t)
+	alloc := mock.Alloc()
+	alloc2 := mock.Alloc()
+
+	notify := setupNotifyTest(state, watch.Item{Table: "quota_usage"})
+
+	if err := state.UpsertAllocs(1000, []*structs.Allocation{alloc, alloc2}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	usage, err := state.QuotaUsageByNamespace(structs.DefaultNamespace)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usage.Allocs != 2 || usage.CPU != 1000 || usage.MemoryMB != 512 || usage.DiskMB != 300 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	// Allocations the client stopped release their resources
+	update := new(structs.Allocation)
+	*update = *alloc
+	update.ClientStatus = structs.AllocClientStatusDead
+	if err := state.UpdateAllocFromClient(1001, update); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	usage, err = state.QuotaUsageByNamespace(structs.DefaultNamespace)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usage.Allocs != 1 || usage.CPU != 500 || usage.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	// Deleting the terminal allocation doesn't change the usage
+	if err := state.DeleteEval(1002, nil, []string{alloc.ID}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	usage, err = state.QuotaUsageByNamespace(structs.DefaultNamespace)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usage.Allocs != 1 || usage.ModifyIndex != 1001 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	// Stopping the other allocation releases everything
+	stop := new(structs.Allocation)
+	*stop = *alloc2
+	stop.DesiredStatus = structs.AllocDesiredStatusStop
+	if err := state.UpsertAllocs(1003, []*structs.Allocation{stop}); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	usage, err = state.QuotaUsageByNamespace(structs.DefaultNamespace)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usage.Allocs != 0 || usage.CPU != 0 || usage.MemoryMB != 0 || usage.DiskMB != 0 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	index, err := state.Index("quota_usage")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if index != 1003 {
+		t.Fatalf("bad: %d", index)
+	}
+
+	notify.verify(t)
+}
+
+func TestStateStore_RestoreQuotaSpec(t *testing.T) {
+	state := testStateStore(t)
+	quota := mock.QuotaSpec()
+	alloc := mock.Alloc()
+
+	notify := setupNotifyTest(
+		state,
+		watch.Item{Table: "quota_specs"},
+		watch.Item{QuotaSpec: quota.Name},
+		watch.Item{Table: "quota_usage"})
+
+	restore, err := state.Restore()
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.QuotaSpecRestore(quota); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if err := restore.AllocRestore(alloc); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	restore.Commit()
+
+	out, err := state.QuotaSpecByName(quota.Name)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if !reflect.DeepEqual(out, quota) {
+		t.Fatalf("Bad: %#v %#v", out, quota)
+	}
+
+	// The usage is rebuilt from the restored allocations
+	usage, err := state.QuotaUsageByNamespace(alloc.Namespace)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if usage == nil || usage.Allocs != 1 || usage.CPU != alloc.Resources.CPU {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	notify.verify(t)
+}
+
 // notifyTestCase is used to set up and verify watch triggers.
 type notifyTestCase struct {
 	item watch.Item
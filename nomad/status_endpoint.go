@@ -53,6 +53,13 @@ func (s *Status) Peers(args *structs.GenericRequest, reply *[]string) error {
 		return err
 	}
 
+	// Check operator read permissions
+	if aclObj, err := s.srv.ResolveToken(args.AuthToken); err != nil {
+		return err
+	} else if aclObj != nil && !aclObj.AllowOperatorRead() {
+		return structs.ErrPermissionDenied
+	}
+
 	peers, err := s.srv.raftPeers.Peers()
 	if err != nil {
 		return err
@@ -0,0 +1,238 @@
+package structs
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/nomad/acl"
+)
+
+var (
+	ErrPermissionDenied = errors.New("Permission denied")
+	ErrTokenNotFound    = errors.New("ACL token not found")
+)
+
+const (
+	// ACLClientToken is the type of tokens granted the policies they list.
+	ACLClientToken = "client"
+
+	// ACLManagementToken is the type of tokens allowed to do anything,
+	// including managing ACLs.
+	ACLManagementToken = "management"
+
+	// AnonymousACLPolicy is the name of the policy applied to requests
+	// without a token. Anonymous requests are denied if it doesn't exist.
+	AnonymousACLPolicy = "anonymous"
+
+	// maxACLNameLength is the maximum length of the names of policies and
+	// tokens.
+	maxACLNameLength = 128
+
+	// maxACLPolicyDescriptionLength is the maximum length of the description
+	// of policies.
+	maxACLPolicyDescriptionLength = 256
+)
+
+var validACLPolicyName = regexp.MustCompile("^[a-zA-Z0-9-]{1,128}$")
+
+// ACLPolicy is a named set of HCL rules granting access to resources.
+type ACLPolicy struct {
+	Name        string
+	Description string
+	Rules       string
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate returns an error if the policy is invalid.
+func (p *ACLPolicy) Validate() error {
+	var mErr multierror.Error
+	if !validACLPolicyName.MatchString(p.Name) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid policy name %q", p.Name))
+	}
+	if len(p.Description) > maxACLPolicyDescriptionLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("policy description longer than %d", maxACLPolicyDescriptionLength))
+	}
+	if _, err := acl.Parse(p.Rules); err != nil {
+		mErr.Errors = append(mErr.Errors, err)
+	}
+	return mErr.ErrorOrNil()
+}
+
+// Stub returns a summary of the policy.
+func (p *ACLPolicy) Stub() *ACLPolicyListStub {
+	return &ACLPolicyListStub{
+		Name:        p.Name,
+		Description: p.Description,
+		CreateIndex: p.CreateIndex,
+		ModifyIndex: p.ModifyIndex,
+	}
+}
+
+// ACLPolicyListStub is used to list policies without their rules.
+type ACLPolicyListStub struct {
+	Name        string
+	Description string
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLToken is a token granting access to the APIs. The secret ID is sent
+// with requests while the accessor ID identifies the token without granting
+// its access.
+type ACLToken struct {
+	AccessorID string
+	SecretID   string
+	Name       string
+	Type       string
+	Policies   []string
+	CreateTime time.Time
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate returns an error if the token is invalid.
+func (t *ACLToken) Validate() error {
+	var mErr multierror.Error
+	if len(t.Name) > maxACLNameLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("token name longer than %d", maxACLNameLength))
+	}
+	switch t.Type {
+	case ACLClientToken:
+		if len(t.Policies) == 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("client token missing policies"))
+		}
+	case ACLManagementToken:
+		if len(t.Policies) != 0 {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("management token cannot be associated with policies"))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("token type must be %q or %q", ACLClientToken, ACLManagementToken))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// IsManagement returns whether the token is a management token.
+func (t *ACLToken) IsManagement() bool {
+	return t.Type == ACLManagementToken
+}
+
+// Stub returns the token without its secret ID.
+func (t *ACLToken) Stub() *ACLTokenListStub {
+	return &ACLTokenListStub{
+		AccessorID:  t.AccessorID,
+		Name:        t.Name,
+		Type:        t.Type,
+		Policies:    t.Policies,
+		CreateTime:  t.CreateTime,
+		CreateIndex: t.CreateIndex,
+		ModifyIndex: t.ModifyIndex,
+	}
+}
+
+// ACLTokenListStub is used to list tokens without their secret IDs.
+type ACLTokenListStub struct {
+	AccessorID  string
+	Name        string
+	Type        string
+	Policies    []string
+	CreateTime  time.Time
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// ACLPolicyUpsertRequest is used to create or update policies
+type ACLPolicyUpsertRequest struct {
+	Policies []*ACLPolicy
+	WriteRequest
+}
+
+// ACLPolicyDeleteRequest is used to delete policies by name
+type ACLPolicyDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// ACLPolicySpecificRequest is used to query a specific policy
+type ACLPolicySpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// ACLPolicyListRequest is used to list the policies
+type ACLPolicyListRequest struct {
+	QueryOptions
+}
+
+// SingleACLPolicyResponse is used to return a single policy
+type SingleACLPolicyResponse struct {
+	Policy *ACLPolicy
+	QueryMeta
+}
+
+// ACLPolicyListResponse is used for a list request
+type ACLPolicyListResponse struct {
+	Policies []*ACLPolicyListStub
+	QueryMeta
+}
+
+// ACLTokenUpsertRequest is used to create or update tokens. Tokens without
+// an accessor ID are created.
+type ACLTokenUpsertRequest struct {
+	Tokens []*ACLToken
+	WriteRequest
+}
+
+// ACLTokenUpsertResponse is used to return the created or updated tokens
+type ACLTokenUpsertResponse struct {
+	Tokens []*ACLToken
+	WriteMeta
+}
+
+// ACLTokenDeleteRequest is used to delete tokens by accessor ID
+type ACLTokenDeleteRequest struct {
+	AccessorIDs []string
+	WriteRequest
+}
+
+// ACLTokenBootstrapRequest is used to create the initial management token
+type ACLTokenBootstrapRequest struct {
+	Token *ACLToken
+	WriteRequest
+}
+
+// ACLTokenSpecificRequest is used to query a specific token
+type ACLTokenSpecificRequest struct {
+	AccessorID string
+	QueryOptions
+}
+
+// ACLTokenListRequest is used to list the tokens
+type ACLTokenListRequest struct {
+	QueryOptions
+}
+
+// SingleACLTokenResponse is used to return a single token
+type SingleACLTokenResponse struct {
+	Token *ACLToken
+	QueryMeta
+}
+
+// ACLTokenListResponse is used for a list request
+type ACLTokenListResponse struct {
+	Tokens []*ACLTokenListStub
+	QueryMeta
+}
+
+// ResolveACLTokenResponse is used to return the token of a request along
+// with its policies so agents can check requests they serve locally.
+type ResolveACLTokenResponse struct {
+	Token    *ACLToken
+	Policies []*ACLPolicy
+	QueryMeta
+}
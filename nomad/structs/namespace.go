@@ -0,0 +1,78 @@
+package structs
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// maxNamespaceDescriptionLength is the maximum length of the description
+	// of namespaces.
+	maxNamespaceDescriptionLength = 256
+)
+
+var validNamespaceName = regexp.MustCompile("^[a-zA-Z0-9-]{1,128}$")
+
+// Namespace allows jobs and their evaluations and allocations to be
+// isolated from other teams sharing the cluster. Job IDs are unique per
+// namespace.
+type Namespace struct {
+	Name        string
+	Description string
+
+	// Quota is the name of the quota specification limiting the resources
+	// of the namespace, if any.
+	Quota string
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate returns an error if the namespace is invalid.
+func (n *Namespace) Validate() error {
+	var mErr multierror.Error
+	if !validNamespaceName.MatchString(n.Name) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid namespace name %q", n.Name))
+	}
+	if len(n.Description) > maxNamespaceDescriptionLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("namespace description longer than %d", maxNamespaceDescriptionLength))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// NamespaceUpsertRequest is used to create or update namespaces
+type NamespaceUpsertRequest struct {
+	Namespaces []*Namespace
+	WriteRequest
+}
+
+// NamespaceDeleteRequest is used to delete namespaces by name
+type NamespaceDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// NamespaceSpecificRequest is used to query a specific namespace
+type NamespaceSpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// NamespaceListRequest is used to list the namespaces
+type NamespaceListRequest struct {
+	QueryOptions
+}
+
+// SingleNamespaceResponse is used to return a single namespace
+type SingleNamespaceResponse struct {
+	Namespace *Namespace
+	QueryMeta
+}
+
+// NamespaceListResponse is used for a list request
+type NamespaceListResponse struct {
+	Namespaces []*Namespace
+	QueryMeta
+}
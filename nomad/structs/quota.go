@@ -0,0 +1,191 @@
+package structs
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	// QuotaDimensionCPU, QuotaDimensionMemory, QuotaDimensionDisk and
+	// QuotaDimensionAllocs name the dimensions limited by quotas.
+	QuotaDimensionCPU    = "cpu"
+	QuotaDimensionMemory = "memory"
+	QuotaDimensionDisk   = "disk"
+	QuotaDimensionAllocs = "allocs"
+)
+
+// QuotaSpec limits the resources used by the allocations of the namespaces
+// it is attached to. The limits apply to each namespace separately.
+type QuotaSpec struct {
+	Name        string
+	Description string
+
+	// Limits holds the limits of each region. Regions without a limit are
+	// unlimited.
+	Limits []*QuotaLimit
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Validate returns an error if the quota specification is invalid.
+func (q *QuotaSpec) Validate() error {
+	var mErr multierror.Error
+	if !validNamespaceName.MatchString(q.Name) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("invalid quota name %q", q.Name))
+	}
+	if len(q.Description) > maxNamespaceDescriptionLength {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("quota description longer than %d", maxNamespaceDescriptionLength))
+	}
+	regions := make(map[string]struct{}, len(q.Limits))
+	for _, limit := range q.Limits {
+		if err := limit.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+			continue
+		}
+		if _, ok := regions[limit.Region]; ok {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("duplicate limit for region %q", limit.Region))
+		}
+		regions[limit.Region] = struct{}{}
+	}
+	return mErr.ErrorOrNil()
+}
+
+// LimitForRegion returns the limit of the region or nil if the region is
+// unlimited.
+func (q *QuotaSpec) LimitForRegion(region string) *QuotaLimit {
+	for _, limit := range q.Limits {
+		if limit.Region == region {
+			return limit
+		}
+	}
+	return nil
+}
+
+// QuotaLimit limits the resources of a region. Zero values are unlimited.
+type QuotaLimit struct {
+	Region   string
+	CPU      int
+	MemoryMB int
+	DiskMB   int
+	Allocs   int
+}
+
+// Validate returns an error if the limit is invalid.
+func (l *QuotaLimit) Validate() error {
+	if l.Region == "" {
+		return fmt.Errorf("quota limit must specify a region")
+	}
+	if l.CPU < 0 || l.MemoryMB < 0 || l.DiskMB < 0 || l.Allocs < 0 {
+		return fmt.Errorf("quota limit of region %q can't be negative", l.Region)
+	}
+	return nil
+}
+
+// Exhausted returns the dimensions of the limit the usage exceeds.
+func (l *QuotaLimit) Exhausted(usage *QuotaUsage) []string {
+	var dimensions []string
+	if l.CPU != 0 && usage.CPU > l.CPU {
+		dimensions = append(dimensions, QuotaDimensionCPU)
+	}
+	if l.MemoryMB != 0 && usage.MemoryMB > l.MemoryMB {
+		dimensions = append(dimensions, QuotaDimensionMemory)
+	}
+	if l.DiskMB != 0 && usage.DiskMB > l.DiskMB {
+		dimensions = append(dimensions, QuotaDimensionDisk)
+	}
+	if l.Allocs != 0 && usage.Allocs > l.Allocs {
+		dimensions = append(dimensions, QuotaDimensionAllocs)
+	}
+	return dimensions
+}
+
+// QuotaUsage tracks the resources used by the non-terminal allocations of a
+// namespace in the local region.
+type QuotaUsage struct {
+	Namespace string
+	CPU       int
+	MemoryMB  int
+	DiskMB    int
+	Allocs    int
+
+	CreateIndex uint64
+	ModifyIndex uint64
+}
+
+// Copy returns a copy of the usage.
+func (u *QuotaUsage) Copy() *QuotaUsage {
+	if u == nil {
+		return nil
+	}
+	nu := new(QuotaUsage)
+	*nu = *u
+	return nu
+}
+
+// Add adds the resources to the usage.
+func (u *QuotaUsage) Add(r *Resources) {
+	u.Allocs++
+	if r != nil {
+		u.CPU += r.CPU
+		u.MemoryMB += r.MemoryMB
+		u.DiskMB += r.DiskMB
+	}
+}
+
+// Subtract removes the resources from the usage.
+func (u *QuotaUsage) Subtract(r *Resources) {
+	u.Allocs--
+	if r != nil {
+		u.CPU -= r.CPU
+		u.MemoryMB -= r.MemoryMB
+		u.DiskMB -= r.DiskMB
+	}
+}
+
+// QuotaSpecUpsertRequest is used to create or update quota specifications
+type QuotaSpecUpsertRequest struct {
+	Quotas []*QuotaSpec
+	WriteRequest
+}
+
+// QuotaSpecDeleteRequest is used to delete quota specifications by name
+type QuotaSpecDeleteRequest struct {
+	Names []string
+	WriteRequest
+}
+
+// QuotaSpecSpecificRequest is used to query a specific quota specification
+type QuotaSpecSpecificRequest struct {
+	Name string
+	QueryOptions
+}
+
+// QuotaSpecListRequest is used to list the quota specifications
+type QuotaSpecListRequest struct {
+	QueryOptions
+}
+
+// QuotaUsageListRequest is used to list the usage of the namespaces
+type QuotaUsageListRequest struct {
+	QueryOptions
+}
+
+// SingleQuotaSpecResponse is used to return a single quota specification
+type SingleQuotaSpecResponse struct {
+	Quota *QuotaSpec
+	QueryMeta
+}
+
+// QuotaSpecListResponse is used for a list request
+type QuotaSpecListResponse struct {
+	Quotas []*QuotaSpec
+	QueryMeta
+}
+
+// QuotaUsageListResponse is used to return the usage of the namespaces
+type QuotaUsageListResponse struct {
+	Usages []*QuotaUsage
+	QueryMeta
+}
@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -36,6 +38,15 @@ const (
 	EvalDeleteRequestType
 	AllocUpdateRequestType
 	AllocClientUpdateRequestType
+	ACLPolicyUpsertRequestType
+	ACLPolicyDeleteRequestType
+	ACLTokenUpsertRequestType
+	ACLTokenDeleteRequestType
+	ACLTokenBootstrapRequestType
+	NamespaceUpsertRequestType
+	NamespaceDeleteRequestType
+	QuotaSpecUpsertRequestType
+	QuotaSpecDeleteRequestType
 )
 
 const (
@@ -47,6 +58,12 @@ const (
 	IgnoreUnknownTypeFlag MessageType = 128
 )
 
+const (
+	// DefaultNamespace is the namespace of requests and jobs that don't
+	// specify one. It always exists and can't be deleted.
+	DefaultNamespace = "default"
+)
+
 // RPCInfo is used to describe common information about query
 type RPCInfo interface {
 	RequestRegion() string
@@ -69,12 +86,27 @@ type QueryOptions struct {
 	// If set, any follower can service the request. Results
 	// may be arbitrarily stale.
 	AllowStale bool
+
+	// AuthToken is the secret ID of the ACL token used for the request
+	AuthToken string
+
+	// Namespace is the target namespace for the query
+	Namespace string
 }
 
 func (q QueryOptions) RequestRegion() string {
 	return q.Region
 }
 
+// RequestNamespace returns the namespace of the query, defaulting to the
+// default namespace.
+func (q QueryOptions) RequestNamespace() string {
+	if q.Namespace == "" {
+		return DefaultNamespace
+	}
+	return q.Namespace
+}
+
 // QueryOption only applies to reads, so always true
 func (q QueryOptions) IsRead() bool {
 	return true
@@ -84,9 +116,20 @@ func (q QueryOptions) AllowStaleRead() bool {
 	return q.AllowStale
 }
 
+// RequestAuthToken returns the secret ID of the ACL token of the query
+func (q QueryOptions) RequestAuthToken() string {
+	return q.AuthToken
+}
+
 type WriteRequest struct {
 	// The target region for this write
 	Region string
+
+	// AuthToken is the secret ID of the ACL token used for the request
+	AuthToken string
+
+	// Namespace is the target namespace for the write
+	Namespace string
 }
 
 func (w WriteRequest) RequestRegion() string {
@@ -94,6 +137,15 @@ func (w WriteRequest) RequestRegion() string {
 	return w.Region
 }
 
+// RequestNamespace returns the namespace of the write, defaulting to the
+// default namespace.
+func (w WriteRequest) RequestNamespace() string {
+	if w.Namespace == "" {
+		return DefaultNamespace
+	}
+	return w.Namespace
+}
+
 // WriteRequest only applies to writes, always false
 func (w WriteRequest) IsRead() bool {
 	return false
@@ -103,6 +155,11 @@ func (w WriteRequest) AllowStaleRead() bool {
 	return false
 }
 
+// RequestAuthToken returns the secret ID of the ACL token of the write
+func (w WriteRequest) RequestAuthToken() string {
+	return w.AuthToken
+}
+
 // QueryMeta allows a query response to include potentially
 // useful metadata about a query
 type QueryMeta struct {
@@ -297,9 +354,29 @@ type JobRegisterResponse struct {
 	EvalID          string
 	EvalCreateIndex uint64
 	JobModifyIndex  uint64
+
+	// Warnings are the newline separated warnings of the admission
+	// controllers that admitted the job.
+	Warnings string
 	QueryMeta
 }
 
+// JobAdmissionRequest is POSTed to the admission webhook with the job
+// being registered.
+type JobAdmissionRequest struct {
+	Job *Job
+}
+
+// JobAdmissionResponse is the response of the admission webhook. Jobs are
+// rejected with the Reason unless Allowed is set. A non-nil Job replaces
+// the registered job.
+type JobAdmissionResponse struct {
+	Allowed  bool
+	Reason   string
+	Warnings []string
+	Job      *Job
+}
+
 // JobDeregisterResponse is used to respond to a job deregistration
 type JobDeregisterResponse struct {
 	EvalID          string
@@ -452,6 +529,10 @@ type Node struct {
 	// Node name
 	Name string
 
+	// HTTPAddr is the address on which the Nomad client is listening for
+	// HTTP requests
+	HTTPAddr string
+
 	// Attributes is an arbitrary set of key/value
 	// data that can be used for constraints. Examples
 	// include "kernel.name=linux", "arch=386", "driver.docker=1",
@@ -715,7 +796,11 @@ type Job struct {
 	// Region is the Nomad region that handles scheduling this job
 	Region string
 
-	// ID is a unique identifier for the job per region. It can be
+	// Namespace is the namespace the job is registered in. Job IDs are
+	// unique per namespace.
+	Namespace string
+
+	// ID is a unique identifier for the job per namespace. It can be
 	// specified hierarchically like LineOfBiz/OrgName/Team/Project
 	ID string
 
@@ -869,6 +954,7 @@ func (j *Job) LookupTaskGroup(name string) *TaskGroup {
 func (j *Job) Stub() *JobListStub {
 	return &JobListStub{
 		ID:                j.ID,
+		Namespace:         j.Namespace,
 		Name:              j.Name,
 		Type:              j.Type,
 		Priority:          j.Priority,
@@ -888,6 +974,7 @@ func (j *Job) IsPeriodic() bool {
 // for the job list
 type JobListStub struct {
 	ID                string
+	Namespace         string
 	Name              string
 	Type              string
 	Priority          int
@@ -1042,6 +1129,122 @@ func NewRestartPolicy(jobType string) *RestartPolicy {
 	return nil
 }
 
+const (
+	// DefaultEphemeralDiskMB is the size of the ephemeral disk of a task
+	// group that doesn't request one.
+	DefaultEphemeralDiskMB = 300
+
+	// minEphemeralDiskMB is the smallest ephemeral disk a task group can
+	// request.
+	minEphemeralDiskMB = 10
+)
+
+// EphemeralDisk is the disk shared by the tasks of a task group, which holds
+// the alloc/data directory and the local directories of the tasks. The data
+// is lost when the allocation is replaced unless it is sticky or migrated.
+type EphemeralDisk struct {
+	// SizeMB is the disk required in MB.
+	SizeMB int `mapstructure:"size"`
+
+	// Sticky makes the scheduler prefer the node of the previous allocation
+	// when replacing it, in which case the client moves the data of the
+	// previous allocation into the new one.
+	Sticky bool
+
+	// Migrate makes the client copy the data of the previous allocation from
+	// the node it ran on when the replacement is placed on another node.
+	Migrate bool
+}
+
+// DefaultEphemeralDisk returns the ephemeral disk of a task group that
+// doesn't request one.
+func DefaultEphemeralDisk() *EphemeralDisk {
+	return &EphemeralDisk{SizeMB: DefaultEphemeralDiskMB}
+}
+
+// Validate is used to sanity check an ephemeral disk
+func (d *EphemeralDisk) Validate() error {
+	if d.SizeMB < minEphemeralDiskMB {
+		return fmt.Errorf("Ephemeral disk size must be at least %d MB, got %d", minEphemeralDiskMB, d.SizeMB)
+	}
+	return nil
+}
+
+// KeepsData returns whether the data of an allocation is carried over to the
+// allocation replacing it.
+func (d *EphemeralDisk) KeepsData() bool {
+	return d != nil && (d.Sticky || d.Migrate)
+}
+
+const (
+	// VolumeTypeHost is the type of volumes backed by a host volume declared
+	// in the configuration of the client.
+	VolumeTypeHost = "host"
+)
+
+// ClientHostVolumeConfig is a directory of the host that a client exposes to
+// tasks as a named volume.
+type ClientHostVolumeConfig struct {
+	Name     string
+	Path     string
+	ReadOnly bool
+}
+
+// VolumeRequest is a volume requested by a task group. The task group can
+// only be placed on nodes that provide the volume.
+type VolumeRequest struct {
+	// Name is the name the tasks of the group mount the volume by.
+	Name string
+
+	// Type is the type of the volume. Only host volumes are supported.
+	Type string
+
+	// Source is the name of the volume on the client.
+	Source string
+
+	// ReadOnly mounts the volume read-only in all the tasks.
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// Validate is used to sanity check a volume request
+func (v *VolumeRequest) Validate() error {
+	var mErr multierror.Error
+	if v.Type != VolumeTypeHost {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Volume %q has unsupported type %q", v.Name, v.Type))
+	}
+	if v.Source == "" {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Volume %q must specify a source", v.Name))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// VolumeMount mounts a volume of the task group into a task.
+type VolumeMount struct {
+	// Volume is the name of the volume in the task group.
+	Volume string
+
+	// Destination is the path the volume is mounted at, relative to the root
+	// of the task's filesystem.
+	Destination string
+
+	// ReadOnly mounts the volume read-only.
+	ReadOnly bool `mapstructure:"read_only"`
+}
+
+// Validate is used to sanity check a volume mount
+func (m *VolumeMount) Validate() error {
+	var mErr multierror.Error
+	if m.Volume == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing volume name"))
+	}
+	if m.Destination == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Missing destination"))
+	} else if strings.Contains(m.Destination, "..") {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Destination %q can't escape the task's filesystem", m.Destination))
+	}
+	return mErr.ErrorOrNil()
+}
+
 // TaskGroup is an atomic unit of placement. Each task group belongs to
 // a job and may contain any number of tasks. A task group support running
 // in many replicas using the same configuration..
@@ -1060,6 +1263,13 @@ type TaskGroup struct {
 	//RestartPolicy of a TaskGroup
 	RestartPolicy *RestartPolicy
 
+	// EphemeralDisk is the disk shared by the tasks of the task group
+	EphemeralDisk *EphemeralDisk
+
+	// Volumes are the volumes the tasks of the task group can mount, keyed
+	// by their name
+	Volumes map[string]*VolumeRequest
+
 	// Tasks are the collection of tasks that this task group needs to run
 	Tasks []*Task
 
@@ -1075,6 +1285,11 @@ func (tg *TaskGroup) InitFields(job *Job) {
 		tg.RestartPolicy = NewRestartPolicy(job.Type)
 	}
 
+	// Set the default ephemeral disk.
+	if tg.EphemeralDisk == nil {
+		tg.EphemeralDisk = DefaultEphemeralDisk()
+	}
+
 	for _, task := range tg.Tasks {
 		task.InitFields(job, tg)
 	}
@@ -1107,6 +1322,33 @@ func (tg *TaskGroup) Validate() error {
 		mErr.Errors = append(mErr.Errors, fmt.Errorf("Task Group %v should have a restart policy", tg.Name))
 	}
 
+	if tg.EphemeralDisk != nil {
+		if err := tg.EphemeralDisk.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
+	for name, vol := range tg.Volumes {
+		if vol.Name != name {
+			mErr.Errors = append(mErr.Errors, fmt.Errorf("Volume %q has mismatched name %q", name, vol.Name))
+		}
+		if err := vol.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+	}
+
+	// Check that the tasks only mount volumes of the task group
+	for _, task := range tg.Tasks {
+		for _, mount := range task.VolumeMounts {
+			if mount.Volume == "" {
+				continue
+			}
+			if _, ok := tg.Volumes[mount.Volume]; !ok {
+				mErr.Errors = append(mErr.Errors, fmt.Errorf("Task %q mounts undefined volume %q", task.Name, mount.Volume))
+			}
+		}
+	}
+
 	// Check for duplicate tasks
 	tasks := make(map[string]int)
 	for idx, task := range tg.Tasks {
@@ -1119,6 +1361,23 @@ func (tg *TaskGroup) Validate() error {
 		}
 	}
 
+	// Hooks are run relative to the main tasks so there must be at least one
+	mainTasks, leaderTasks := 0, 0
+	for _, task := range tg.Tasks {
+		if task.IsMain() {
+			mainTasks++
+		}
+		if task.Leader {
+			leaderTasks++
+		}
+	}
+	if len(tg.Tasks) != 0 && mainTasks == 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Task group must have at least one task without a lifecycle hook"))
+	}
+	if leaderTasks > 1 {
+		mErr.Errors = append(mErr.Errors, errors.New("Only one task may be marked as leader"))
+	}
+
 	// Validate the tasks
 	for idx, task := range tg.Tasks {
 		if err := task.Validate(); err != nil {
@@ -1269,6 +1528,269 @@ type Task struct {
 	// Meta is used to associate arbitrary metadata with this
 	// task. This is opaque to Nomad.
 	Meta map[string]string
+
+	// Lifecycle determines when the task is run relative to the main tasks
+	// of the task group. Tasks without a lifecycle are main tasks.
+	Lifecycle *TaskLifecycleConfig
+
+	// Leader marks the task as the leader of the task group. When the leader
+	// exits all other tasks of the group are killed.
+	Leader bool
+
+	// Artifacts is a list of artifacts to download into the task directory
+	// before the task is started.
+	Artifacts []*TaskArtifact
+
+	// Templates are rendered into the task's local directory before the
+	// task is started.
+	Templates []*Template
+
+	// Secrets are read from the client's secrets provider before the task
+	// is started and injected into its secrets directory or environment.
+	Secrets []*Secret
+
+	// VolumeMounts are the volumes of the task group mounted into the task.
+	VolumeMounts []*VolumeMount
+}
+
+// IsMain returns whether the task is a main task of its task group.
+func (t *Task) IsMain() bool {
+	return t.Lifecycle == nil
+}
+
+// IsEphemeral returns whether the task is a lifecycle hook that is run to
+// completion rather than kept running alongside the main tasks.
+func (t *Task) IsEphemeral() bool {
+	return t.Lifecycle != nil && !t.Lifecycle.Sidecar
+}
+
+const (
+	// TaskLifecycleHookPrestart tasks are started before the main tasks.
+	// Unless they are sidecars, the main tasks are only started once they
+	// have completed successfully.
+	TaskLifecycleHookPrestart = "prestart"
+
+	// TaskLifecycleHookPoststart tasks are started after the main tasks.
+	TaskLifecycleHookPoststart = "poststart"
+
+	// TaskLifecycleHookPoststop tasks are run after the main tasks exit.
+	TaskLifecycleHookPoststop = "poststop"
+)
+
+// TaskLifecycleConfig describes when a task is run within its task group.
+type TaskLifecycleConfig struct {
+	// Hook is the point in the lifecycle of the main tasks at which the
+	// task is started.
+	Hook string
+
+	// Sidecar tasks are kept running until the main tasks exit.
+	Sidecar bool
+}
+
+func (l *TaskLifecycleConfig) Validate() error {
+	switch l.Hook {
+	case TaskLifecycleHookPrestart, TaskLifecycleHookPoststart:
+	case TaskLifecycleHookPoststop:
+		if l.Sidecar {
+			return fmt.Errorf("Lifecycle hook %q can't be a sidecar", l.Hook)
+		}
+	case "":
+		return errors.New("Missing lifecycle hook")
+	default:
+		return fmt.Errorf("Invalid lifecycle hook %q", l.Hook)
+	}
+	return nil
+}
+
+const (
+	// TaskArtifactModeAny lets the artifact source determine whether a file
+	// or a directory is downloaded.
+	TaskArtifactModeAny = "any"
+
+	// TaskArtifactModeFile downloads the artifact to the destination file.
+	TaskArtifactModeFile = "file"
+
+	// TaskArtifactModeDir downloads the artifact into the destination
+	// directory.
+	TaskArtifactModeDir = "dir"
+)
+
+// TaskArtifact is an artifact to download before running the task.
+type TaskArtifact struct {
+	// GetterSource is the source to download the artifact from using
+	// go-getter. Archives are unpacked once downloaded.
+	GetterSource string `mapstructure:"source"`
+
+	// GetterOptions are appended to the source as query parameters, such as
+	// the checksum of the artifact or the ref to check out from git.
+	GetterOptions map[string]string `mapstructure:"options"`
+
+	// RelativeDest is the destination of the artifact relative to the task
+	// directory. It defaults to the task's local directory.
+	RelativeDest string `mapstructure:"destination"`
+
+	// Mode determines whether the destination is a file or a directory. It
+	// defaults to letting the source decide.
+	Mode string `mapstructure:"mode"`
+}
+
+func (ta *TaskArtifact) Validate() error {
+	var mErr multierror.Error
+	if ta.GetterSource == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Artifact must have a source"))
+	}
+
+	switch ta.Mode {
+	case "", TaskArtifactModeAny, TaskArtifactModeFile, TaskArtifactModeDir:
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Invalid artifact mode %q", ta.Mode))
+	}
+
+	// The destination must stay within the task directory
+	dest := filepath.Clean(ta.RelativeDest)
+	if filepath.IsAbs(dest) || dest == ".." || strings.HasPrefix(dest, ".."+string(filepath.Separator)) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Artifact destination %q escapes the task directory", ta.RelativeDest))
+	}
+	return mErr.ErrorOrNil()
+}
+
+const (
+	// TemplateChangeModeNoop leaves the task running when its template is
+	// re-rendered.
+	TemplateChangeModeNoop = "noop"
+
+	// TemplateChangeModeRestart restarts the task when its template is
+	// re-rendered.
+	TemplateChangeModeRestart = "restart"
+
+	// TemplateChangeModeSignal sends the change signal to the task when its
+	// template is re-rendered.
+	TemplateChangeModeSignal = "signal"
+)
+
+// Template is a Go template rendered into the task's local directory.
+type Template struct {
+	// SourcePath is the path of the template relative to the task
+	// directory, such as a template downloaded as an artifact.
+	SourcePath string `mapstructure:"source"`
+
+	// EmbeddedTmpl is the template itself, used when there is no source.
+	EmbeddedTmpl string `mapstructure:"data"`
+
+	// DestPath is the path of the rendered file relative to the task's
+	// local directory.
+	DestPath string `mapstructure:"destination"`
+
+	// ChangeMode is applied when the template is re-rendered with different
+	// content while the task is running. It defaults to restarting the task.
+	ChangeMode string `mapstructure:"change_mode"`
+
+	// ChangeSignal is the name of the signal sent to the task in signal
+	// change mode, such as SIGHUP.
+	ChangeSignal string `mapstructure:"change_signal"`
+}
+
+func (t *Template) Validate() error {
+	var mErr multierror.Error
+	if t.SourcePath == "" && t.EmbeddedTmpl == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Template must have a source or data"))
+	} else if t.SourcePath != "" && t.EmbeddedTmpl != "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Template can't have both a source and data"))
+	}
+
+	// The source must stay within the task directory
+	source := filepath.Clean(t.SourcePath)
+	if t.SourcePath != "" && (filepath.IsAbs(source) || source == ".." || strings.HasPrefix(source, ".."+string(filepath.Separator))) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Template source %q escapes the task directory", t.SourcePath))
+	}
+
+	// The destination must stay within the task's local directory
+	dest := filepath.Clean(t.DestPath)
+	if t.DestPath == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Template must have a destination"))
+	} else if filepath.IsAbs(dest) || dest == "." || dest == ".." || strings.HasPrefix(dest, ".."+string(filepath.Separator)) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Template destination %q escapes the local directory", t.DestPath))
+	}
+
+	switch t.ChangeMode {
+	case "", TemplateChangeModeNoop, TemplateChangeModeRestart:
+		if t.ChangeSignal != "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Template change signal requires the signal change mode"))
+		}
+	case TemplateChangeModeSignal:
+		if t.ChangeSignal == "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Template signal change mode requires a change signal"))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Invalid template change mode %q", t.ChangeMode))
+	}
+	return mErr.ErrorOrNil()
+}
+
+// Secret is a key of a secret read from the secrets provider of the client
+// and injected into the task. Secrets are re-read periodically and the
+// change mode, which takes the same values as the one of templates, is
+// applied when they rotate.
+type Secret struct {
+	// Path is the path of the secret in the secrets store.
+	Path string `mapstructure:"path"`
+
+	// Key is the key of the secret whose value is injected.
+	Key string `mapstructure:"key"`
+
+	// DestPath is the path of the file the value is written to, relative
+	// to the task's secrets directory.
+	DestPath string `mapstructure:"destination"`
+
+	// EnvVar is the environment variable the value is set in, used when
+	// there is no destination.
+	EnvVar string `mapstructure:"env"`
+
+	// ChangeMode is applied when the value changes while the task is
+	// running. It defaults to restarting the task.
+	ChangeMode string `mapstructure:"change_mode"`
+
+	// ChangeSignal is the name of the signal sent to the task in signal
+	// change mode, such as SIGHUP.
+	ChangeSignal string `mapstructure:"change_signal"`
+}
+
+func (s *Secret) Validate() error {
+	var mErr multierror.Error
+	if s.Path == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Secret must have a path"))
+	}
+	if s.Key == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Secret must have a key"))
+	}
+
+	// The value goes either to a file within the secrets directory or to
+	// the environment
+	dest := filepath.Clean(s.DestPath)
+	if s.DestPath == "" && s.EnvVar == "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Secret must have a destination or an env var"))
+	} else if s.DestPath != "" && s.EnvVar != "" {
+		mErr.Errors = append(mErr.Errors, errors.New("Secret can't have both a destination and an env var"))
+	} else if s.DestPath != "" && (filepath.IsAbs(dest) || dest == "." || dest == ".." || strings.HasPrefix(dest, ".."+string(filepath.Separator))) {
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Secret destination %q escapes the secrets directory", s.DestPath))
+	}
+
+	switch s.ChangeMode {
+	case "", TemplateChangeModeNoop, TemplateChangeModeRestart:
+		if s.ChangeSignal != "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Secret change signal requires the signal change mode"))
+		}
+	case TemplateChangeModeSignal:
+		if s.ChangeSignal == "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Secret signal change mode requires a change signal"))
+		}
+		if s.EnvVar != "" {
+			mErr.Errors = append(mErr.Errors, errors.New("Secret env vars can only be changed by a restart"))
+		}
+	default:
+		mErr.Errors = append(mErr.Errors, fmt.Errorf("Invalid secret change mode %q", s.ChangeMode))
+	}
+	return mErr.ErrorOrNil()
 }
 
 // InitFields initializes fields in the task.
@@ -1329,6 +1851,34 @@ const (
 
 	// Task Killed indicates a user has killed the task.
 	TaskKilled = "Killed"
+
+	// Task Lifecycle Failure indicates that the task was not run because a
+	// prestart task of its task group failed.
+	TaskLifecycleFailure = "Lifecycle Failure"
+
+	// Task Leader Dead indicates that the task was killed because the leader
+	// task of its task group exited.
+	TaskLeaderDead = "Leader Task Dead"
+
+	// Task Artifact Download Failed indicates that the task's artifacts could
+	// not be downloaded.
+	TaskArtifactDownloadFailed = "Failed Artifact Download"
+
+	// TaskTemplateFailure indicates that a template of the task couldn't be
+	// rendered.
+	TaskTemplateFailure = "Template Failure"
+
+	// TaskRestartSignal indicates that the task was restarted because one of
+	// its templates or secrets changed.
+	TaskRestartSignal = "Restart Signaled"
+
+	// TaskSignaling indicates that the task was sent a signal because one of
+	// its templates or secrets changed.
+	TaskSignaling = "Signaling"
+
+	// TaskSecretFailure indicates that a secret of the task couldn't be
+	// read or injected.
+	TaskSecretFailure = "Secret Failure"
 )
 
 // TaskEvent is an event that effects the state of a task and contains meta-data
@@ -1347,6 +1897,19 @@ type TaskEvent struct {
 
 	// Task Killed Fields.
 	KillError string // Error killing the task.
+
+	// Lifecycle Failure fields.
+	LifecycleError string // Error of the hook that prevented the task from running.
+
+	// Artifact Download fields.
+	DownloadError string // Error downloading artifacts.
+
+	// Template fields.
+	TemplateError string // Error rendering templates.
+	Reason        string // Reason the task was restarted or signaled.
+
+	// Secret fields.
+	SecretError string // Error reading or injecting secrets.
 }
 
 func NewTaskEvent(event string) *TaskEvent {
@@ -1387,6 +1950,39 @@ func (e *TaskEvent) SetKillError(err error) *TaskEvent {
 	return e
 }
 
+func (e *TaskEvent) SetLifecycleError(err error) *TaskEvent {
+	if err != nil {
+		e.LifecycleError = err.Error()
+	}
+	return e
+}
+
+func (e *TaskEvent) SetTemplateError(err error) *TaskEvent {
+	if err != nil {
+		e.TemplateError = err.Error()
+	}
+	return e
+}
+
+func (e *TaskEvent) SetSecretError(err error) *TaskEvent {
+	if err != nil {
+		e.SecretError = err.Error()
+	}
+	return e
+}
+
+func (e *TaskEvent) SetReason(r string) *TaskEvent {
+	e.Reason = r
+	return e
+}
+
+func (e *TaskEvent) SetDownloadError(err error) *TaskEvent {
+	if err != nil {
+		e.DownloadError = err.Error()
+	}
+	return e
+}
+
 // Validate is used to sanity check a task group
 func (t *Task) Validate() error {
 	var mErr multierror.Error
@@ -1398,6 +1994,8 @@ func (t *Task) Validate() error {
 	}
 	if t.Resources == nil {
 		mErr.Errors = append(mErr.Errors, errors.New("Missing task resources"))
+	} else if t.Resources.DiskMB > 0 {
+		mErr.Errors = append(mErr.Errors, errors.New("Task disk must be requested in the task group's ephemeral disk"))
 	}
 	for idx, constr := range t.Constraints {
 		if err := constr.Validate(); err != nil {
@@ -1411,6 +2009,43 @@ func (t *Task) Validate() error {
 			mErr.Errors = append(mErr.Errors, err)
 		}
 	}
+
+	if t.Lifecycle != nil {
+		if err := t.Lifecycle.Validate(); err != nil {
+			mErr.Errors = append(mErr.Errors, err)
+		}
+		if t.Leader {
+			mErr.Errors = append(mErr.Errors, errors.New("Lifecycle hook tasks can't be the leader"))
+		}
+	}
+
+	for idx, artifact := range t.Artifacts {
+		if err := artifact.Validate(); err != nil {
+			outer := fmt.Errorf("Artifact %d validation failed: %v", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
+	for idx, tmpl := range t.Templates {
+		if err := tmpl.Validate(); err != nil {
+			outer := fmt.Errorf("Template %d validation failed: %v", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
+	for idx, secret := range t.Secrets {
+		if err := secret.Validate(); err != nil {
+			outer := fmt.Errorf("Secret %d validation failed: %v", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
+
+	for idx, mount := range t.VolumeMounts {
+		if err := mount.Validate(); err != nil {
+			outer := fmt.Errorf("Volume mount %d validation failed: %v", idx+1, err)
+			mErr.Errors = append(mErr.Errors, outer)
+		}
+	}
 	return mErr.ErrorOrNil()
 }
 
@@ -1470,6 +2105,9 @@ type Allocation struct {
 	// ID of the allocation (UUID)
 	ID string
 
+	// Namespace is the namespace of the job of the allocation
+	Namespace string
+
 	// ID of the evaluation that generated this allocation
 	EvalID string
 
@@ -1517,6 +2155,11 @@ type Allocation struct {
 	// TaskStates stores the state of each task,
 	TaskStates map[string]*TaskState
 
+	// PreviousAllocation is the ID of the allocation this allocation
+	// replaces, if any. The ephemeral disk of the previous allocation is
+	// carried over when it is sticky or migrated.
+	PreviousAllocation string
+
 	// Raft Indexes
 	CreateIndex uint64
 	ModifyIndex uint64
@@ -1541,10 +2184,28 @@ func (a *Allocation) TerminalStatus() bool {
 	}
 }
 
+// Index returns the index of the allocation within its task group, which is
+// encoded in its name as <job>.<group>[<index>]. It returns zero if the name
+// carries no index.
+func (a *Allocation) Index() uint {
+	l := len(a.Name)
+	start := strings.LastIndex(a.Name, "[")
+	if start == -1 || l == 0 || a.Name[l-1] != ']' {
+		return 0
+	}
+
+	index, err := strconv.ParseUint(a.Name[start+1:l-1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return uint(index)
+}
+
 // Stub returns a list stub for the allocation
 func (a *Allocation) Stub() *AllocListStub {
 	return &AllocListStub{
 		ID:                 a.ID,
+		Namespace:          a.Namespace,
 		EvalID:             a.EvalID,
 		Name:               a.Name,
 		NodeID:             a.NodeID,
@@ -1592,6 +2253,7 @@ func (a *Allocation) PopulateServiceIDs() {
 // AllocListStub is used to return a subset of alloc information
 type AllocListStub struct {
 	ID                 string
+	Namespace          string
 	EvalID             string
 	Name               string
 	NodeID             string
@@ -1645,6 +2307,10 @@ type AllocMetric struct {
 	// This is to prevent creating many failed allocations for a
 	// single task group.
 	CoalescedFailures int
+
+	// QuotaExhausted is the set of quota dimensions that prevented
+	// the placement.
+	QuotaExhausted []string
 }
 
 func (a *AllocMetric) EvaluateNode() {
@@ -1683,6 +2349,10 @@ func (a *AllocMetric) ExhaustedNode(node *Node, dimension string) {
 	}
 }
 
+func (a *AllocMetric) ExhaustQuota(dimensions []string) {
+	a.QuotaExhausted = append(a.QuotaExhausted, dimensions...)
+}
+
 func (a *AllocMetric) ScoreNode(node *Node, name string, score float64) {
 	if a.Scores == nil {
 		a.Scores = make(map[string]float64)
@@ -1734,6 +2404,9 @@ type Evaluation struct {
 	// is assigned upon the creation of the evaluation.
 	ID string
 
+	// Namespace is the namespace of the job the evaluation is for
+	Namespace string
+
 	// Priority is used to control scheduling importance and if this job
 	// can preempt other jobs.
 	Priority int
@@ -1795,6 +2468,15 @@ func (e *Evaluation) TerminalStatus() bool {
 	}
 }
 
+// JobNamespace returns the namespace of the evaluated job, defaulting to the
+// default namespace for evaluations created before namespaces existed.
+func (e *Evaluation) JobNamespace() string {
+	if e.Namespace == "" {
+		return DefaultNamespace
+	}
+	return e.Namespace
+}
+
 func (e *Evaluation) GoString() string {
 	return fmt.Sprintf("<Eval '%s' JobID: '%s'>", e.ID, e.JobID)
 }
@@ -1839,6 +2521,7 @@ func (e *Evaluation) NextRollingEval(wait time.Duration) *Evaluation {
 		Priority:       e.Priority,
 		Type:           e.Type,
 		TriggeredBy:    EvalTriggerRollingUpdate,
+		Namespace:      e.Namespace,
 		JobID:          e.JobID,
 		JobModifyIndex: e.JobModifyIndex,
 		Status:         EvalStatusPending,
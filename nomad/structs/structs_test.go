@@ -188,6 +188,280 @@ func TestTask_Validate(t *testing.T) {
 	}
 }
 
+func TestTask_Validate_Disk(t *testing.T) {
+	task := &Task{
+		Name:      "web",
+		Driver:    "docker",
+		Resources: &Resources{DiskMB: 100},
+	}
+	err := task.Validate()
+	if err == nil || !strings.Contains(err.Error(), "ephemeral disk") {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestEphemeralDisk_Validate(t *testing.T) {
+	d := DefaultEphemeralDisk()
+	if err := d.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	d.SizeMB = 1
+	if err := d.Validate(); err == nil {
+		t.Fatalf("expected error for a disk of %d MB", d.SizeMB)
+	}
+}
+
+func TestTaskGroup_InitFields_EphemeralDisk(t *testing.T) {
+	job := &Job{Type: JobTypeService}
+	tg := &TaskGroup{Name: "web"}
+	tg.InitFields(job)
+	if tg.EphemeralDisk == nil || tg.EphemeralDisk.SizeMB != DefaultEphemeralDiskMB {
+		t.Fatalf("bad: %#v", tg.EphemeralDisk)
+	}
+	if tg.EphemeralDisk.KeepsData() {
+		t.Fatalf("default disk should not keep data")
+	}
+}
+
+func TestTaskGroup_Validate_Lifecycle(t *testing.T) {
+	tg := &TaskGroup{
+		Name:  "web",
+		Count: 1,
+		Tasks: []*Task{
+			&Task{
+				Name:      "migrate",
+				Driver:    "exec",
+				Resources: &Resources{},
+				Lifecycle: &TaskLifecycleConfig{Hook: TaskLifecycleHookPrestart},
+			},
+		},
+		RestartPolicy: &RestartPolicy{
+			Interval: 5 * time.Minute,
+			Delay:    10 * time.Second,
+			Attempts: 10,
+			Mode:     RestartPolicyModeDelay,
+		},
+	}
+	err := tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "at least one task without a lifecycle hook") {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks = append(tg.Tasks, &Task{
+		Name:      "web",
+		Driver:    "exec",
+		Resources: &Resources{},
+	})
+	if err := tg.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTaskGroup_Validate_Leader(t *testing.T) {
+	tg := &TaskGroup{
+		Name:  "web",
+		Count: 1,
+		Tasks: []*Task{
+			&Task{Name: "web", Driver: "exec", Resources: &Resources{}, Leader: true},
+			&Task{Name: "logs", Driver: "exec", Resources: &Resources{}},
+		},
+		RestartPolicy: &RestartPolicy{
+			Interval: 5 * time.Minute,
+			Delay:    10 * time.Second,
+			Attempts: 10,
+			Mode:     RestartPolicyModeDelay,
+		},
+	}
+	if err := tg.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks[1].Leader = true
+	err := tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "Only one task may be marked as leader") {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks[1].Lifecycle = &TaskLifecycleConfig{Hook: TaskLifecycleHookPrestart}
+	err = tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "can't be the leader") {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTaskGroup_Validate_Volumes(t *testing.T) {
+	tg := &TaskGroup{
+		Name:  "web",
+		Count: 1,
+		Volumes: map[string]*VolumeRequest{
+			"certs": &VolumeRequest{Name: "certs", Type: VolumeTypeHost, Source: "ca-certificates"},
+		},
+		Tasks: []*Task{
+			&Task{
+				Name:      "web",
+				Driver:    "exec",
+				Resources: &Resources{},
+				VolumeMounts: []*VolumeMount{
+					&VolumeMount{Volume: "certs", Destination: "/etc/ssl/certs"},
+				},
+			},
+		},
+		RestartPolicy: &RestartPolicy{
+			Interval: 5 * time.Minute,
+			Delay:    10 * time.Second,
+			Attempts: 10,
+			Mode:     RestartPolicyModeDelay,
+		},
+	}
+	if err := tg.Validate(); err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks[0].VolumeMounts[0].Volume = "data"
+	err := tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "undefined volume") {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks[0].VolumeMounts[0] = &VolumeMount{Volume: "certs", Destination: "../etc"}
+	err = tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "escape") {
+		t.Fatalf("err: %v", err)
+	}
+
+	tg.Tasks[0].VolumeMounts = nil
+	tg.Volumes["certs"].Type = "csi"
+	err = tg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "unsupported type") {
+		t.Fatalf("err: %v", err)
+	}
+}
+
+func TestTaskArtifact_Validate(t *testing.T) {
+	cases := []struct {
+		artifact *TaskArtifact
+		err      string
+	}{
+		{&TaskArtifact{GetterSource: "http://foo.com/bar.tgz"}, ""},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", RelativeDest: "local/bin", Mode: TaskArtifactModeFile}, ""},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", RelativeDest: "local/../secrets"}, ""},
+		{&TaskArtifact{}, "must have a source"},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", Mode: "foo"}, "Invalid artifact mode"},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", RelativeDest: "../foo"}, "escapes the task directory"},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", RelativeDest: "local/../../foo"}, "escapes the task directory"},
+		{&TaskArtifact{GetterSource: "http://foo.com/bar", RelativeDest: "/etc"}, "escapes the task directory"},
+	}
+
+	for _, c := range cases {
+		err := c.artifact.Validate()
+		if c.err == "" {
+			if err != nil {
+				t.Fatalf("%#v: err: %v", c.artifact, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("%#v: expected error %q, got: %v", c.artifact, c.err, err)
+		}
+	}
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	cases := []struct {
+		tmpl *Template
+		err  string
+	}{
+		{&Template{EmbeddedTmpl: "{{.Env.NOMAD_IP}}", DestPath: "app.conf"}, ""},
+		{&Template{SourcePath: "local/app.tmpl", DestPath: "conf/app.conf", ChangeMode: TemplateChangeModeNoop}, ""},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "app.conf", ChangeMode: TemplateChangeModeSignal, ChangeSignal: "SIGHUP"}, ""},
+		{&Template{DestPath: "app.conf"}, "must have a source or data"},
+		{&Template{SourcePath: "local/app.tmpl", EmbeddedTmpl: "foo", DestPath: "app.conf"}, "both a source and data"},
+		{&Template{SourcePath: "../app.tmpl", DestPath: "app.conf"}, "escapes the task directory"},
+		{&Template{EmbeddedTmpl: "foo"}, "must have a destination"},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "../app.conf"}, "escapes the local directory"},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "/etc/app.conf"}, "escapes the local directory"},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "app.conf", ChangeMode: "foo"}, "Invalid template change mode"},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "app.conf", ChangeMode: TemplateChangeModeSignal}, "requires a change signal"},
+		{&Template{EmbeddedTmpl: "foo", DestPath: "app.conf", ChangeSignal: "SIGHUP"}, "requires the signal change mode"},
+	}
+
+	for _, c := range cases {
+		err := c.tmpl.Validate()
+		if c.err == "" {
+			if err != nil {
+				t.Fatalf("%#v: err: %v", c.tmpl, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("%#v: expected error %q, got: %v", c.tmpl, c.err, err)
+		}
+	}
+}
+
+func TestSecret_Validate(t *testing.T) {
+	cases := []struct {
+		secret *Secret
+		err    string
+	}{
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass"}, ""},
+		{&Secret{Path: "secret/db", Key: "password", EnvVar: "DB_PASSWORD", ChangeMode: TemplateChangeModeNoop}, ""},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass", ChangeMode: TemplateChangeModeSignal, ChangeSignal: "SIGHUP"}, ""},
+		{&Secret{Key: "password", DestPath: "db.pass"}, "must have a path"},
+		{&Secret{Path: "secret/db", DestPath: "db.pass"}, "must have a key"},
+		{&Secret{Path: "secret/db", Key: "password"}, "must have a destination or an env var"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass", EnvVar: "DB_PASSWORD"}, "both a destination and an env var"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "../db.pass"}, "escapes the secrets directory"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "/etc/db.pass"}, "escapes the secrets directory"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass", ChangeMode: "foo"}, "Invalid secret change mode"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass", ChangeMode: TemplateChangeModeSignal}, "requires a change signal"},
+		{&Secret{Path: "secret/db", Key: "password", DestPath: "db.pass", ChangeSignal: "SIGHUP"}, "requires the signal change mode"},
+		{&Secret{Path: "secret/db", Key: "password", EnvVar: "DB_PASSWORD", ChangeMode: TemplateChangeModeSignal, ChangeSignal: "SIGHUP"}, "only be changed by a restart"},
+	}
+
+	for _, c := range cases {
+		err := c.secret.Validate()
+		if c.err == "" {
+			if err != nil {
+				t.Fatalf("%#v: err: %v", c.secret, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("%#v: expected error %q, got: %v", c.secret, c.err, err)
+		}
+	}
+}
+
+func TestTaskLifecycleConfig_Validate(t *testing.T) {
+	cases := []struct {
+		config *TaskLifecycleConfig
+		err    string
+	}{
+		{&TaskLifecycleConfig{Hook: TaskLifecycleHookPrestart}, ""},
+		{&TaskLifecycleConfig{Hook: TaskLifecycleHookPrestart, Sidecar: true}, ""},
+		{&TaskLifecycleConfig{Hook: TaskLifecycleHookPoststart, Sidecar: true}, ""},
+		{&TaskLifecycleConfig{Hook: TaskLifecycleHookPoststop}, ""},
+		{&TaskLifecycleConfig{Hook: TaskLifecycleHookPoststop, Sidecar: true}, "can't be a sidecar"},
+		{&TaskLifecycleConfig{}, "Missing lifecycle hook"},
+		{&TaskLifecycleConfig{Hook: "foo"}, "Invalid lifecycle hook"},
+	}
+
+	for _, c := range cases {
+		err := c.config.Validate()
+		if c.err == "" {
+			if err != nil {
+				t.Fatalf("%#v: err: %v", c.config, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Fatalf("%#v: expected error %q, got: %v", c.config, c.err, err)
+		}
+	}
+}
+
 func TestConstraint_Validate(t *testing.T) {
 	c := &Constraint{}
 	err := c.Validate()
@@ -575,3 +849,21 @@ func TestPeriodicConfig_NextCron(t *testing.T) {
 		}
 	}
 }
+
+func TestAllocation_Index(t *testing.T) {
+	cases := map[string]uint{
+		"example.cache[0]":  0,
+		"example.cache[12]": 12,
+		"foo[bar].baz[3]":   3,
+		"example.cache":     0,
+		"example.cache[x]":  0,
+		"":                  0,
+	}
+
+	for name, exp := range cases {
+		a := &Allocation{Name: name}
+		if idx := a.Index(); idx != exp {
+			t.Fatalf("Index() of %q returned %d; want %d", name, idx, exp)
+		}
+	}
+}
@@ -9,13 +9,17 @@ package watch
 // multiple fields does not place a watch on multiple items. Each Item
 // describes exactly one scoped watch.
 type Item struct {
+	ACLPolicy string
+	ACLToken  string
 	Alloc     string
 	AllocEval string
 	AllocJob  string
 	AllocNode string
 	Eval      string
 	Job       string
+	Namespace string
 	Node      string
+	QuotaSpec string
 	Table     string
 }
 
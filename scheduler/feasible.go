@@ -150,6 +150,72 @@ func (iter *DriverIterator) hasDrivers(option *structs.Node) bool {
 	return true
 }
 
+// HostVolumeIterator is a FeasibleIterator which returns nodes that provide
+// the host volumes requested by a task group.
+type HostVolumeIterator struct {
+	ctx     Context
+	source  FeasibleIterator
+	volumes map[string]*structs.VolumeRequest
+}
+
+// NewHostVolumeIterator creates a HostVolumeIterator from a source
+func NewHostVolumeIterator(ctx Context, source FeasibleIterator) *HostVolumeIterator {
+	iter := &HostVolumeIterator{
+		ctx:    ctx,
+		source: source,
+	}
+	return iter
+}
+
+func (iter *HostVolumeIterator) SetVolumes(volumes map[string]*structs.VolumeRequest) {
+	iter.volumes = volumes
+}
+
+func (iter *HostVolumeIterator) Next() *structs.Node {
+	for {
+		// Get the next option from the source
+		option := iter.source.Next()
+		if option == nil {
+			return nil
+		}
+
+		// Use this node if possible
+		if iter.hasVolumes(option) {
+			return option
+		}
+		iter.ctx.Metrics().FilterNode(option, "missing host volumes")
+	}
+}
+
+func (iter *HostVolumeIterator) Reset() {
+	iter.source.Reset()
+}
+
+// hasVolumes is used to check if the node provides all the host volumes of
+// the task group. Host volumes are registered as node attributes like
+// "host_volume.certs=/etc/ssl/certs", along with
+// "host_volume.certs.read_only=true" if they can only be mounted read-only.
+func (iter *HostVolumeIterator) hasVolumes(option *structs.Node) bool {
+	for _, req := range iter.volumes {
+		if req.Type != structs.VolumeTypeHost {
+			continue
+		}
+
+		key := fmt.Sprintf("host_volume.%s", req.Source)
+		if _, ok := option.Attributes[key]; !ok {
+			return false
+		}
+
+		if req.ReadOnly {
+			continue
+		}
+		if readOnly, _ := strconv.ParseBool(option.Attributes[key+".read_only"]); readOnly {
+			return false
+		}
+	}
+	return true
+}
+
 // ProposedAllocConstraintIterator is a FeasibleIterator which returns nodes that
 // match constraints that are not static such as Node attributes but are
 // effected by proposed alloc placements. Examples are distinct_hosts and
@@ -236,7 +302,7 @@ func (iter *ProposedAllocConstraintIterator) satisfiesDistinctHosts(option *stru
 		// If the job has a distinct_hosts constraint we only need an alloc
 		// collision on the JobID but if the constraint is on the TaskGroup then
 		// we need both a job and TaskGroup collision.
-		jobCollision := alloc.JobID == iter.job.ID
+		jobCollision := alloc.Namespace == iter.job.Namespace && alloc.JobID == iter.job.ID
 		taskCollision := alloc.TaskGroup == iter.tg.Name
 		if iter.jobDistinctHosts && jobCollision || jobCollision && taskCollision {
 			return false
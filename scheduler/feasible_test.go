@@ -106,6 +106,51 @@ func TestDriverIterator(t *testing.T) {
 	}
 }
 
+func TestHostVolumeIterator(t *testing.T) {
+	_, ctx := testContext(t)
+	nodes := []*structs.Node{
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+		mock.Node(),
+	}
+	static := NewStaticIterator(ctx, nodes)
+
+	nodes[0].Attributes["host_volume.certs"] = "/etc/ssl/certs"
+	nodes[0].Attributes["host_volume.data"] = "/srv/data"
+	nodes[1].Attributes["host_volume.certs"] = "/etc/ssl/certs"
+	nodes[2].Attributes["host_volume.certs"] = "/etc/ssl/certs"
+	nodes[2].Attributes["host_volume.data"] = "/srv/data"
+	nodes[2].Attributes["host_volume.data.read_only"] = "true"
+	nodes[3].Attributes["host_volume.certs"] = "/etc/ssl/certs"
+	nodes[3].Attributes["host_volume.certs.read_only"] = "true"
+	nodes[3].Attributes["host_volume.data"] = "/srv/data"
+
+	volumes := map[string]*structs.VolumeRequest{
+		"certs": &structs.VolumeRequest{
+			Name:     "certs",
+			Type:     structs.VolumeTypeHost,
+			Source:   "certs",
+			ReadOnly: true,
+		},
+		"data": &structs.VolumeRequest{
+			Name:   "data",
+			Type:   structs.VolumeTypeHost,
+			Source: "data",
+		},
+	}
+	iter := NewHostVolumeIterator(ctx, static)
+	iter.SetVolumes(volumes)
+
+	out := collectFeasible(iter)
+	if len(out) != 2 {
+		t.Fatalf("bad: %#v", out)
+	}
+	if out[0] != nodes[0] || out[1] != nodes[3] {
+		t.Fatalf("bad: %#v", out)
+	}
+}
+
 func TestConstraintIterator(t *testing.T) {
 	_, ctx := testContext(t)
 	nodes := []*structs.Node{
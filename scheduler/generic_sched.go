@@ -118,7 +118,7 @@ func (s *GenericScheduler) Process(eval *structs.Evaluation) error {
 func (s *GenericScheduler) process() (bool, error) {
 	// Lookup the Job by ID
 	var err error
-	s.job, err = s.state.JobByID(s.eval.JobID)
+	s.job, err = s.state.JobByID(s.eval.JobNamespace(), s.eval.JobID)
 	if err != nil {
 		return false, fmt.Errorf("failed to get job '%s': %v",
 			s.eval.JobID, err)
@@ -193,7 +193,7 @@ func (s *GenericScheduler) computeJobAllocs() error {
 	}
 
 	// Lookup the allocations by JobID
-	allocs, err := s.state.AllocsByJob(s.eval.JobID)
+	allocs, err := s.state.AllocsByJob(s.eval.JobNamespace(), s.eval.JobID)
 	if err != nil {
 		return fmt.Errorf("failed to get allocs for job '%s': %v",
 			s.eval.JobID, err)
@@ -218,8 +218,16 @@ func (s *GenericScheduler) computeJobAllocs() error {
 		s.plan.AppendUpdate(e.Alloc, structs.AllocDesiredStatusStop, allocNotNeeded)
 	}
 
+	// Track the quota of the namespace, crediting the allocations the
+	// plan stops
+	quota, err := newQuotaTracker(s.state, s.job)
+	if err != nil {
+		return err
+	}
+	quota.release(s.plan)
+
 	// Attempt to do the upgrades in place
-	diff.update = inplaceUpdate(s.ctx, s.eval, s.job, s.stack, diff.update)
+	diff.update = inplaceUpdate(s.ctx, s.eval, s.job, s.stack, quota, diff.update)
 
 	// Check if a rolling upgrade strategy is being used
 	limit := len(diff.update) + len(diff.migrate)
@@ -239,11 +247,11 @@ func (s *GenericScheduler) computeJobAllocs() error {
 	}
 
 	// Compute the placements
-	return s.computePlacements(diff.place)
+	return s.computePlacements(quota, diff.place)
 }
 
 // computePlacements computes placements for allocations
-func (s *GenericScheduler) computePlacements(place []allocTuple) error {
+func (s *GenericScheduler) computePlacements(quota *quotaTracker, place []allocTuple) error {
 	// Get the base nodes
 	nodes, err := readyNodesInDCs(s.state, s.job.Datacenters)
 	if err != nil {
@@ -253,6 +261,16 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 	// Update the set of placement ndoes
 	s.stack.SetNodes(nodes)
 
+	// Index the nodes so replacements of allocations with a sticky
+	// ephemeral disk can prefer the node of the previous allocation
+	nodesByID := make(map[string]*structs.Node, len(nodes))
+	for _, node := range nodes {
+		nodesByID[node.ID] = node
+	}
+
+	// Credit the allocations evicted since the quota was last released
+	quota.release(s.plan)
+
 	// Track the failed task groups so that we can coalesce
 	// the failures together to avoid creating many failed allocs.
 	failedTG := make(map[*structs.TaskGroup]*structs.Allocation)
@@ -264,14 +282,34 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 			continue
 		}
 
-		// Attempt to match the task group
-		option, size := s.stack.Select(missing.TaskGroup)
+		// Attempt to match the task group, preferring the node of the
+		// previous allocation if its ephemeral disk is sticky
+		var option *RankedNode
+		var size *structs.Resources
+		if prevNode := stickyNode(missing, nodesByID); prevNode != nil {
+			s.stack.SetNodes([]*structs.Node{prevNode})
+			option, size = s.stack.Select(missing.TaskGroup)
+			s.stack.SetNodes(nodes)
+		}
+		if option == nil {
+			option, size = s.stack.Select(missing.TaskGroup)
+		}
+
+		// Ensure the placement doesn't exceed the quota
+		var exhausted []string
+		if option != nil {
+			if exhausted = quota.place(size); len(exhausted) != 0 {
+				s.ctx.Metrics().ExhaustQuota(exhausted)
+				option = nil
+			}
+		}
 
 		// Create an allocation for this
 		alloc := &structs.Allocation{
 			ID:        structs.GenerateUUID(),
 			EvalID:    s.eval.ID,
 			Name:      missing.Name,
+			Namespace: s.job.Namespace,
 			JobID:     s.job.ID,
 			Job:       s.job,
 			TaskGroup: missing.TaskGroup.Name,
@@ -279,6 +317,11 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 			Metrics:   s.ctx.Metrics(),
 		}
 
+		// Link the allocation being replaced so its data can be carried over
+		if missing.Alloc != nil {
+			alloc.PreviousAllocation = missing.Alloc.ID
+		}
+
 		// Set fields based on if we found an allocation option
 		if option != nil {
 			// Generate the service ids for the tasks which this allocation is going
@@ -294,6 +337,9 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 		} else {
 			alloc.DesiredStatus = structs.AllocDesiredStatusFailed
 			alloc.DesiredDescription = "failed to find a node for placement"
+			if len(exhausted) != 0 {
+				alloc.DesiredDescription = allocQuotaExhausted
+			}
 			alloc.ClientStatus = structs.AllocClientStatusFailed
 			alloc.TaskStates = initTaskState(missing.TaskGroup, structs.TaskStateDead)
 			s.plan.AppendFailed(alloc)
@@ -302,3 +348,13 @@ func (s *GenericScheduler) computePlacements(place []allocTuple) error {
 	}
 	return nil
 }
+
+// stickyNode returns the node of the allocation being replaced if the task
+// group's ephemeral disk is sticky and the node is still a candidate for
+// placements.
+func stickyNode(missing allocTuple, nodesByID map[string]*structs.Node) *structs.Node {
+	if missing.Alloc == nil || missing.TaskGroup.EphemeralDisk == nil || !missing.TaskGroup.EphemeralDisk.Sticky {
+		return nil
+	}
+	return nodesByID[missing.Alloc.NodeID]
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -52,7 +53,7 @@ func TestServiceSched_JobRegister(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure all allocations placed
@@ -110,7 +111,7 @@ func TestServiceSched_JobRegister_AllocFail(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure all allocations placed
@@ -126,6 +127,81 @@ func TestServiceSched_JobRegister_AllocFail(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobRegister_QuotaExhausted(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Limit the namespace to four allocations
+	quota := mock.QuotaSpec()
+	quota.Limits[0] = &structs.QuotaLimit{Region: "global", Allocs: 4}
+	noErr(t, h.State.UpsertQuotaSpecs(h.NextIndex(), []*structs.QuotaSpec{quota}))
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	noErr(t, h.State.UpsertNamespaces(h.NextIndex(), []*structs.Namespace{ns}))
+
+	// Create a job
+	job := mock.Job()
+	job.Namespace = ns.Name
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	// Create a mock evaluation to register the job
+	eval := &structs.Evaluation{
+		ID:          structs.GenerateUUID(),
+		Namespace:   ns.Name,
+		Priority:    job.Priority,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation
+	err := h.Process(NewServiceScheduler, eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Ensure the plan only allocated up to the quota
+	var planned []*structs.Allocation
+	for _, allocList := range plan.NodeAllocation {
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 4 {
+		t.Fatalf("bad: %#v", plan)
+	}
+
+	// Ensure the remaining placements failed on the quota
+	if len(plan.FailedAllocs) != 1 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	failed := plan.FailedAllocs[0]
+	if failed.DesiredDescription != allocQuotaExhausted {
+		t.Fatalf("bad: %#v", failed)
+	}
+	if failed.Metrics.CoalescedFailures != 5 ||
+		!reflect.DeepEqual(failed.Metrics.QuotaExhausted, []string{structs.QuotaDimensionAllocs}) {
+		t.Fatalf("bad: %#v", failed.Metrics)
+	}
+
+	// Ensure the usage was tracked
+	usage, err := h.State.QuotaUsageByNamespace(ns.Name)
+	noErr(t, err)
+	if usage.Allocs != 4 {
+		t.Fatalf("bad: %#v", usage)
+	}
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
 func TestServiceSched_JobModify(t *testing.T) {
 	h := NewHarness(t)
 
@@ -212,7 +288,7 @@ func TestServiceSched_JobModify(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure all allocations placed
@@ -224,6 +300,84 @@ func TestServiceSched_JobModify(t *testing.T) {
 	h.AssertEvalStatus(t, structs.EvalStatusComplete)
 }
 
+func TestServiceSched_JobModify_StickyDisk(t *testing.T) {
+	h := NewHarness(t)
+
+	// Create some nodes
+	var nodes []*structs.Node
+	for i := 0; i < 10; i++ {
+		node := mock.Node()
+		nodes = append(nodes, node)
+		noErr(t, h.State.UpsertNode(h.NextIndex(), node))
+	}
+
+	// Generate a fake job with a sticky ephemeral disk and allocations
+	job := mock.Job()
+	job.TaskGroups[0].EphemeralDisk.Sticky = true
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job))
+
+	allocs := make(map[string]*structs.Allocation)
+	var allocList []*structs.Allocation
+	for i := 0; i < 10; i++ {
+		alloc := mock.Alloc()
+		alloc.Job = job
+		alloc.JobID = job.ID
+		alloc.NodeID = nodes[i].ID
+		alloc.Name = fmt.Sprintf("my-job.web[%d]", i)
+		allocs[alloc.ID] = alloc
+		allocList = append(allocList, alloc)
+	}
+	noErr(t, h.State.UpsertAllocs(h.NextIndex(), allocList))
+
+	// Update the job, such that it cannot be done in-place
+	job2 := mock.Job()
+	job2.ID = job.ID
+	job2.TaskGroups[0].EphemeralDisk.Sticky = true
+	job2.TaskGroups[0].Tasks[0].Config["command"] = "/bin/other"
+	noErr(t, h.State.UpsertJob(h.NextIndex(), job2))
+
+	// Create a mock evaluation to deal with the update
+	eval := &structs.Evaluation{
+		ID:          structs.GenerateUUID(),
+		Priority:    50,
+		TriggeredBy: structs.EvalTriggerJobRegister,
+		JobID:       job.ID,
+	}
+
+	// Process the evaluation
+	err := h.Process(NewServiceScheduler, eval)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+
+	// Ensure a single plan
+	if len(h.Plans) != 1 {
+		t.Fatalf("bad: %#v", h.Plans)
+	}
+	plan := h.Plans[0]
+
+	// Ensure the replacements were placed on the nodes of the allocations
+	// they replace
+	var planned []*structs.Allocation
+	for _, allocList := range plan.NodeAllocation {
+		planned = append(planned, allocList...)
+	}
+	if len(planned) != 10 {
+		t.Fatalf("bad: %#v", plan)
+	}
+	for _, alloc := range planned {
+		prev, ok := allocs[alloc.PreviousAllocation]
+		if !ok {
+			t.Fatalf("alloc %q doesn't link the alloc it replaces: %q", alloc.ID, alloc.PreviousAllocation)
+		}
+		if alloc.NodeID != prev.NodeID {
+			t.Fatalf("alloc %q placed on node %q; want %q", alloc.ID, alloc.NodeID, prev.NodeID)
+		}
+	}
+
+	h.AssertEvalStatus(t, structs.EvalStatusComplete)
+}
+
 func TestServiceSched_JobModify_Rolling(t *testing.T) {
 	h := NewHarness(t)
 
@@ -400,7 +554,7 @@ func TestServiceSched_JobModify_InPlace(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure all allocations placed
@@ -461,7 +615,7 @@ func TestServiceSched_JobDeregister(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure no remaining allocations
@@ -538,7 +692,7 @@ func TestServiceSched_NodeDrain(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure all allocations placed
@@ -584,7 +738,7 @@ func TestServiceSched_RetryLimit(t *testing.T) {
 	}
 
 	// Lookup the allocations by JobID
-	out, err := h.State.AllocsByJob(job.ID)
+	out, err := h.State.AllocsByJob(job.Namespace, job.ID)
 	noErr(t, err)
 
 	// Ensure no allocations placed
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+const (
+	// allocQuotaExhausted is the status used when a placement would exceed
+	// the quota of the namespace of the job
+	allocQuotaExhausted = "quota exhausted"
+)
+
+// quotaTracker enforces the quota of the namespace of a job while its
+// allocations are placed or updated in-place. It starts from the usage of
+// the namespace in the state and accounts for the allocations the plan
+// stops, updates and places. A nil tracker allows every placement.
+type quotaTracker struct {
+	limit *structs.QuotaLimit
+	usage *structs.QuotaUsage
+
+	// released holds the IDs of the stopped allocations already removed
+	// from the usage
+	released map[string]struct{}
+}
+
+// newQuotaTracker returns the tracker of the namespace of the job, or nil if
+// the job was deregistered or the namespace has no limit in the region of
+// the job.
+func newQuotaTracker(state State, job *structs.Job) (*quotaTracker, error) {
+	if job == nil {
+		return nil, nil
+	}
+	ns, err := state.NamespaceByName(job.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace '%s': %v", job.Namespace, err)
+	}
+	if ns == nil || ns.Quota == "" {
+		return nil, nil
+	}
+
+	quota, err := state.QuotaSpecByName(ns.Quota)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota '%s': %v", ns.Quota, err)
+	}
+	if quota == nil {
+		return nil, nil
+	}
+	limit := quota.LimitForRegion(job.Region)
+	if limit == nil {
+		return nil, nil
+	}
+
+	usage, err := state.QuotaUsageByNamespace(job.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota usage of '%s': %v", job.Namespace, err)
+	}
+	if usage == nil {
+		usage = &structs.QuotaUsage{Namespace: job.Namespace}
+	}
+	return &quotaTracker{
+		limit:    limit,
+		usage:    usage.Copy(),
+		released: make(map[string]struct{}),
+	}, nil
+}
+
+// release removes the allocations the plan stops from the usage. It can be
+// called again as the plan grows; allocations are only released once.
+func (q *quotaTracker) release(plan *structs.Plan) {
+	if q == nil {
+		return
+	}
+	for _, updates := range plan.NodeUpdate {
+		for _, alloc := range updates {
+			if _, ok := q.released[alloc.ID]; ok {
+				continue
+			}
+			q.released[alloc.ID] = struct{}{}
+			q.usage.Subtract(alloc.Resources)
+		}
+	}
+}
+
+// place adds an allocation of the given size to the usage. If that exceeds
+// the limit, the usage is left untouched and the exhausted dimensions are
+// returned.
+func (q *quotaTracker) place(size *structs.Resources) []string {
+	if q == nil {
+		return nil
+	}
+	usage := q.usage.Copy()
+	usage.Add(size)
+	if exhausted := q.limit.Exhausted(usage); len(exhausted) != 0 {
+		return exhausted
+	}
+	q.usage = usage
+	return nil
+}
+
+// update replaces the resources of an existing allocation updated in-place
+// with the given size. If that exceeds the limit, the usage is left
+// untouched and the exhausted dimensions are returned.
+func (q *quotaTracker) update(existing *structs.Allocation, size *structs.Resources) []string {
+	if q == nil {
+		return nil
+	}
+	usage := q.usage.Copy()
+	usage.Subtract(existing.Resources)
+	usage.Add(size)
+	if exhausted := q.limit.Exhausted(usage); len(exhausted) != 0 {
+		return exhausted
+	}
+	q.usage = usage
+	return nil
+}
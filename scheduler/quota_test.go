@@ -0,0 +1,103 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/nomad/nomad/mock"
+	"github.com/hashicorp/nomad/nomad/structs"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	h := NewHarness(t)
+
+	// Namespaces without a quota are unlimited
+	job := mock.Job()
+	tracker, err := newQuotaTracker(h.State, job)
+	noErr(t, err)
+	if tracker != nil {
+		t.Fatalf("bad: %#v", tracker)
+	}
+	if exhausted := tracker.place(&structs.Resources{CPU: 100000}); exhausted != nil {
+		t.Fatalf("bad: %#v", exhausted)
+	}
+
+	quota := mock.QuotaSpec()
+	quota.Limits[0] = &structs.QuotaLimit{Region: "global", CPU: 1000}
+	noErr(t, h.State.UpsertQuotaSpecs(h.NextIndex(), []*structs.QuotaSpec{quota}))
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	noErr(t, h.State.UpsertNamespaces(h.NextIndex(), []*structs.Namespace{ns}))
+	job.Namespace = ns.Name
+
+	// Regions without a limit are unlimited
+	job.Region = "other"
+	tracker, err = newQuotaTracker(h.State, job)
+	noErr(t, err)
+	if tracker != nil {
+		t.Fatalf("bad: %#v", tracker)
+	}
+
+	// Existing allocations count against the quota
+	alloc := mock.Alloc()
+	alloc.Namespace = ns.Name
+	alloc.Resources = &structs.Resources{CPU: 600}
+	noErr(t, h.State.UpsertAllocs(h.NextIndex(), []*structs.Allocation{alloc}))
+
+	job.Region = "global"
+	tracker, err = newQuotaTracker(h.State, job)
+	noErr(t, err)
+	exhausted := tracker.place(&structs.Resources{CPU: 500})
+	if !reflect.DeepEqual(exhausted, []string{structs.QuotaDimensionCPU}) {
+		t.Fatalf("bad: %#v", exhausted)
+	}
+
+	// Stopping the allocation frees its resources
+	plan := &structs.Plan{NodeUpdate: make(map[string][]*structs.Allocation)}
+	plan.AppendUpdate(alloc, structs.AllocDesiredStatusStop, allocNotNeeded)
+	tracker.release(plan)
+	tracker.release(plan)
+	for i := 0; i < 2; i++ {
+		if exhausted := tracker.place(&structs.Resources{CPU: 500}); exhausted != nil {
+			t.Fatalf("bad: %#v", exhausted)
+		}
+	}
+	if exhausted := tracker.place(&structs.Resources{CPU: 1}); exhausted == nil {
+		t.Fatalf("expected the quota to be exhausted")
+	}
+}
+
+func TestQuotaTracker_Update(t *testing.T) {
+	h := NewHarness(t)
+
+	quota := mock.QuotaSpec()
+	quota.Limits[0] = &structs.QuotaLimit{Region: "global", CPU: 1000}
+	noErr(t, h.State.UpsertQuotaSpecs(h.NextIndex(), []*structs.QuotaSpec{quota}))
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	noErr(t, h.State.UpsertNamespaces(h.NextIndex(), []*structs.Namespace{ns}))
+	job := mock.Job()
+	job.Namespace = ns.Name
+
+	alloc := mock.Alloc()
+	alloc.Namespace = ns.Name
+	alloc.Resources = &structs.Resources{CPU: 600}
+	noErr(t, h.State.UpsertAllocs(h.NextIndex(), []*structs.Allocation{alloc}))
+
+	tracker, err := newQuotaTracker(h.State, job)
+	noErr(t, err)
+
+	// Growing the allocation past the quota is refused
+	exhausted := tracker.update(alloc, &structs.Resources{CPU: 1100})
+	if !reflect.DeepEqual(exhausted, []string{structs.QuotaDimensionCPU}) {
+		t.Fatalf("bad: %#v", exhausted)
+	}
+
+	// Only the difference counts against the quota
+	if exhausted := tracker.update(alloc, &structs.Resources{CPU: 900}); exhausted != nil {
+		t.Fatalf("bad: %#v", exhausted)
+	}
+	if exhausted := tracker.place(&structs.Resources{CPU: 200}); exhausted == nil {
+		t.Fatalf("expected the quota to be exhausted")
+	}
+}
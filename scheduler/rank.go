@@ -136,6 +136,9 @@ type BinPackIterator struct {
 	evict    bool
 	priority int
 	tasks    []*structs.Task
+
+	// diskMB is the ephemeral disk shared by the tasks
+	diskMB int
 }
 
 // NewBinPackIterator returns a BinPackIterator which tries to fit tasks
@@ -158,6 +161,16 @@ func (iter *BinPackIterator) SetTasks(tasks []*structs.Task) {
 	iter.tasks = tasks
 }
 
+// SetTaskGroup sets the tasks to fit along with the ephemeral disk of their
+// task group.
+func (iter *BinPackIterator) SetTaskGroup(tg *structs.TaskGroup) {
+	iter.tasks = tg.Tasks
+	iter.diskMB = 0
+	if tg.EphemeralDisk != nil {
+		iter.diskMB = tg.EphemeralDisk.SizeMB
+	}
+}
+
 func (iter *BinPackIterator) Next() *RankedNode {
 OUTER:
 	for {
@@ -182,7 +195,7 @@ OUTER:
 		netIdx.AddAllocs(proposed)
 
 		// Assign the resources for each task
-		total := new(structs.Resources)
+		total := &structs.Resources{DiskMB: iter.diskMB}
 		for _, task := range iter.tasks {
 			taskResources := task.Resources.Copy()
 
@@ -241,25 +254,28 @@ func (iter *BinPackIterator) Reset() {
 // along side other allocations from this job. This is used to help distribute
 // load across the cluster.
 type JobAntiAffinityIterator struct {
-	ctx     Context
-	source  RankIterator
-	penalty float64
-	jobID   string
+	ctx       Context
+	source    RankIterator
+	penalty   float64
+	namespace string
+	jobID     string
 }
 
 // NewJobAntiAffinityIterator is used to create a JobAntiAffinityIterator that
 // applies the given penalty for co-placement with allocs from this job.
-func NewJobAntiAffinityIterator(ctx Context, source RankIterator, penalty float64, jobID string) *JobAntiAffinityIterator {
+func NewJobAntiAffinityIterator(ctx Context, source RankIterator, penalty float64, namespace, jobID string) *JobAntiAffinityIterator {
 	iter := &JobAntiAffinityIterator{
-		ctx:     ctx,
-		source:  source,
-		penalty: penalty,
-		jobID:   jobID,
+		ctx:       ctx,
+		source:    source,
+		penalty:   penalty,
+		namespace: namespace,
+		jobID:     jobID,
 	}
 	return iter
 }
 
-func (iter *JobAntiAffinityIterator) SetJob(jobID string) {
+func (iter *JobAntiAffinityIterator) SetJob(namespace, jobID string) {
+	iter.namespace = namespace
 	iter.jobID = jobID
 }
 
@@ -282,7 +298,7 @@ func (iter *JobAntiAffinityIterator) Next() *RankedNode {
 		// Determine the number of collisions
 		collisions := 0
 		for _, alloc := range proposed {
-			if alloc.JobID == iter.jobID {
+			if alloc.Namespace == iter.namespace && alloc.JobID == iter.jobID {
 				collisions += 1
 			}
 		}
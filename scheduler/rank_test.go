@@ -345,21 +345,29 @@ func TestJobAntiAffinity_PlannedAlloc(t *testing.T) {
 	plan := ctx.Plan()
 	plan.NodeAllocation[nodes[0].Node.ID] = []*structs.Allocation{
 		&structs.Allocation{
-			JobID: "foo",
+			Namespace: structs.DefaultNamespace,
+			JobID:     "foo",
 		},
 		&structs.Allocation{
-			JobID: "foo",
+			Namespace: structs.DefaultNamespace,
+			JobID:     "foo",
 		},
 	}
 
-	// Add a planned alloc to node2 that half fills it
+	// Add a planned alloc to node2 that half fills it. The job with the
+	// same ID in another namespace doesn't collide.
 	plan.NodeAllocation[nodes[1].Node.ID] = []*structs.Allocation{
 		&structs.Allocation{
-			JobID: "bar",
+			Namespace: structs.DefaultNamespace,
+			JobID:     "bar",
+		},
+		&structs.Allocation{
+			Namespace: "other",
+			JobID:     "foo",
 		},
 	}
 
-	binp := NewJobAntiAffinityIterator(ctx, static, 5.0, "foo")
+	binp := NewJobAntiAffinityIterator(ctx, static, 5.0, structs.DefaultNamespace, "foo")
 
 	out := collectRanked(binp)
 	if len(out) != 2 {
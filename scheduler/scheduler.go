@@ -57,8 +57,8 @@ type State interface {
 	// The type of each result is *structs.Node
 	Nodes() (memdb.ResultIterator, error)
 
-	// AllocsByJob returns the allocations by JobID
-	AllocsByJob(jobID string) ([]*structs.Allocation, error)
+	// AllocsByJob returns the allocations by job namespace and JobID
+	AllocsByJob(namespace, jobID string) ([]*structs.Allocation, error)
 
 	// AllocsByNode returns all the allocations by node
 	AllocsByNode(node string) ([]*structs.Allocation, error)
@@ -66,8 +66,18 @@ type State interface {
 	// GetNodeByID is used to lookup a node by ID
 	NodeByID(nodeID string) (*structs.Node, error)
 
-	// GetJobByID is used to lookup a job by ID
-	JobByID(id string) (*structs.Job, error)
+	// GetJobByID is used to lookup a job by namespace and ID
+	JobByID(namespace, id string) (*structs.Job, error)
+
+	// NamespaceByName is used to lookup a namespace by name
+	NamespaceByName(name string) (*structs.Namespace, error)
+
+	// QuotaSpecByName is used to lookup a quota specification by name
+	QuotaSpecByName(name string) (*structs.QuotaSpec, error)
+
+	// QuotaUsageByNamespace is used to lookup the resources used by the
+	// allocations of a namespace
+	QuotaUsageByNamespace(namespace string) (*structs.QuotaUsage, error)
 }
 
 // Planner interface is used to submit a task allocation plan.
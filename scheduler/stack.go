@@ -40,6 +40,7 @@ type GenericStack struct {
 	source                  *StaticIterator
 	jobConstraint           *ConstraintIterator
 	taskGroupDrivers        *DriverIterator
+	taskGroupHostVolumes    *HostVolumeIterator
 	taskGroupConstraint     *ConstraintIterator
 	proposedAllocConstraint *ProposedAllocConstraintIterator
 	binPack                 *BinPackIterator
@@ -67,8 +68,11 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	// Filter on task group drivers first as they are faster
 	s.taskGroupDrivers = NewDriverIterator(ctx, s.jobConstraint, nil)
 
+	// Filter on the host volumes of the task group
+	s.taskGroupHostVolumes = NewHostVolumeIterator(ctx, s.taskGroupDrivers)
+
 	// Filter on task group constraints second
-	s.taskGroupConstraint = NewConstraintIterator(ctx, s.taskGroupDrivers, nil)
+	s.taskGroupConstraint = NewConstraintIterator(ctx, s.taskGroupHostVolumes, nil)
 
 	// Filter on constraints that are affected by propsed allocations.
 	s.proposedAllocConstraint = NewProposedAllocConstraintIterator(ctx, s.taskGroupConstraint)
@@ -89,7 +93,7 @@ func NewGenericStack(batch bool, ctx Context) *GenericStack {
 	if batch {
 		penalty = batchJobAntiAffinityPenalty
 	}
-	s.jobAntiAff = NewJobAntiAffinityIterator(ctx, s.binPack, penalty, "")
+	s.jobAntiAff = NewJobAntiAffinityIterator(ctx, s.binPack, penalty, "", "")
 
 	// Apply a limit function. This is to avoid scanning *every* possible node.
 	s.limit = NewLimitIterator(ctx, s.jobAntiAff, 2)
@@ -125,7 +129,7 @@ func (s *GenericStack) SetJob(job *structs.Job) {
 	s.jobConstraint.SetConstraints(job.Constraints)
 	s.proposedAllocConstraint.SetJob(job)
 	s.binPack.SetPriority(job.Priority)
-	s.jobAntiAff.SetJob(job.ID)
+	s.jobAntiAff.SetJob(job.Namespace, job.ID)
 }
 
 func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Resources) {
@@ -139,9 +143,10 @@ func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Reso
 
 	// Update the parameters of iterators
 	s.taskGroupDrivers.SetDrivers(tgConstr.drivers)
+	s.taskGroupHostVolumes.SetVolumes(tg.Volumes)
 	s.taskGroupConstraint.SetConstraints(tgConstr.constraints)
 	s.proposedAllocConstraint.SetTaskGroup(tg)
-	s.binPack.SetTasks(tg.Tasks)
+	s.binPack.SetTaskGroup(tg)
 
 	// Find the node with the max score
 	option := s.maxScore.Next()
@@ -161,12 +166,13 @@ func (s *GenericStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Reso
 // SystemStack is the Stack used for the System scheduler. It is designed to
 // attempt to make placements on all nodes.
 type SystemStack struct {
-	ctx                 Context
-	source              *StaticIterator
-	jobConstraint       *ConstraintIterator
-	taskGroupDrivers    *DriverIterator
-	taskGroupConstraint *ConstraintIterator
-	binPack             *BinPackIterator
+	ctx                  Context
+	source               *StaticIterator
+	jobConstraint        *ConstraintIterator
+	taskGroupDrivers     *DriverIterator
+	taskGroupHostVolumes *HostVolumeIterator
+	taskGroupConstraint  *ConstraintIterator
+	binPack              *BinPackIterator
 }
 
 // NewSystemStack constructs a stack used for selecting service placements
@@ -184,8 +190,11 @@ func NewSystemStack(ctx Context) *SystemStack {
 	// Filter on task group drivers first as they are faster
 	s.taskGroupDrivers = NewDriverIterator(ctx, s.jobConstraint, nil)
 
+	// Filter on the host volumes of the task group
+	s.taskGroupHostVolumes = NewHostVolumeIterator(ctx, s.taskGroupDrivers)
+
 	// Filter on task group constraints second
-	s.taskGroupConstraint = NewConstraintIterator(ctx, s.taskGroupDrivers, nil)
+	s.taskGroupConstraint = NewConstraintIterator(ctx, s.taskGroupHostVolumes, nil)
 
 	// Upgrade from feasible to rank iterator
 	rankSource := NewFeasibleRankIterator(ctx, s.taskGroupConstraint)
@@ -218,8 +227,9 @@ func (s *SystemStack) Select(tg *structs.TaskGroup) (*RankedNode, *structs.Resou
 
 	// Update the parameters of iterators
 	s.taskGroupDrivers.SetDrivers(tgConstr.drivers)
+	s.taskGroupHostVolumes.SetVolumes(tg.Volumes)
 	s.taskGroupConstraint.SetConstraints(tgConstr.constraints)
-	s.binPack.SetTasks(tg.Tasks)
+	s.binPack.SetTaskGroup(tg)
 
 	// Get the next option that satisfies the constraints.
 	option := s.binPack.Next()
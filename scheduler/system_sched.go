@@ -78,7 +78,7 @@ func (s *SystemScheduler) Process(eval *structs.Evaluation) error {
 func (s *SystemScheduler) process() (bool, error) {
 	// Lookup the Job by ID
 	var err error
-	s.job, err = s.state.JobByID(s.eval.JobID)
+	s.job, err = s.state.JobByID(s.eval.JobNamespace(), s.eval.JobID)
 	if err != nil {
 		return false, fmt.Errorf("failed to get job '%s': %v",
 			s.eval.JobID, err)
@@ -155,7 +155,7 @@ func (s *SystemScheduler) process() (bool, error) {
 // existing allocations and node status to update the allocations.
 func (s *SystemScheduler) computeJobAllocs() error {
 	// Lookup the allocations by JobID
-	allocs, err := s.state.AllocsByJob(s.eval.JobID)
+	allocs, err := s.state.AllocsByJob(s.eval.JobNamespace(), s.eval.JobID)
 	if err != nil {
 		return fmt.Errorf("failed to get allocs for job '%s': %v",
 			s.eval.JobID, err)
@@ -180,8 +180,16 @@ func (s *SystemScheduler) computeJobAllocs() error {
 		s.plan.AppendUpdate(e.Alloc, structs.AllocDesiredStatusStop, allocNotNeeded)
 	}
 
+	// Track the quota of the namespace, crediting the allocations the
+	// plan stops
+	quota, err := newQuotaTracker(s.state, s.job)
+	if err != nil {
+		return err
+	}
+	quota.release(s.plan)
+
 	// Attempt to do the upgrades in place
-	diff.update = inplaceUpdate(s.ctx, s.eval, s.job, s.stack, diff.update)
+	diff.update = inplaceUpdate(s.ctx, s.eval, s.job, s.stack, quota, diff.update)
 
 	// Check if a rolling upgrade strategy is being used
 	limit := len(diff.update)
@@ -198,11 +206,11 @@ func (s *SystemScheduler) computeJobAllocs() error {
 	}
 
 	// Compute the placements
-	return s.computePlacements(diff.place)
+	return s.computePlacements(quota, diff.place)
 }
 
 // computePlacements computes placements for allocations
-func (s *SystemScheduler) computePlacements(place []allocTuple) error {
+func (s *SystemScheduler) computePlacements(quota *quotaTracker, place []allocTuple) error {
 	nodeByID := make(map[string]*structs.Node, len(s.nodes))
 	for _, node := range s.nodes {
 		nodeByID[node.ID] = node
@@ -212,6 +220,9 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 	// the failures together to avoid creating many failed allocs.
 	failedTG := make(map[*structs.TaskGroup]*structs.Allocation)
 
+	// Credit the allocations evicted since the quota was last released
+	quota.release(s.plan)
+
 	nodes := make([]*structs.Node, 1)
 	for _, missing := range place {
 		node, ok := nodeByID[missing.Alloc.NodeID]
@@ -226,6 +237,15 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 		// Attempt to match the task group
 		option, size := s.stack.Select(missing.TaskGroup)
 
+		// Ensure the placement doesn't exceed the quota
+		var exhausted []string
+		if option != nil {
+			if exhausted = quota.place(size); len(exhausted) != 0 {
+				s.ctx.Metrics().ExhaustQuota(exhausted)
+				option = nil
+			}
+		}
+
 		if option == nil {
 			// Check if this task group has already failed
 			if alloc, ok := failedTG[missing.TaskGroup]; ok {
@@ -239,6 +259,7 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 			ID:        structs.GenerateUUID(),
 			EvalID:    s.eval.ID,
 			Name:      missing.Name,
+			Namespace: s.job.Namespace,
 			JobID:     s.job.ID,
 			Job:       s.job,
 			TaskGroup: missing.TaskGroup.Name,
@@ -246,6 +267,11 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 			Metrics:   s.ctx.Metrics(),
 		}
 
+		// Link the allocation being replaced so its data can be carried over
+		if missing.Alloc.ID != "" {
+			alloc.PreviousAllocation = missing.Alloc.ID
+		}
+
 		// Set fields based on if we found an allocation option
 		if option != nil {
 			// Generate the service ids for the tasks that this allocation is going
@@ -261,6 +287,9 @@ func (s *SystemScheduler) computePlacements(place []allocTuple) error {
 		} else {
 			alloc.DesiredStatus = structs.AllocDesiredStatusFailed
 			alloc.DesiredDescription = "failed to find a node for placement"
+			if len(exhausted) != 0 {
+				alloc.DesiredDescription = allocQuotaExhausted
+			}
 			alloc.ClientStatus = structs.AllocClientStatusFailed
 			alloc.TaskStates = initTaskState(missing.TaskGroup, structs.TaskStateDead)
 			s.plan.AppendFailed(alloc)
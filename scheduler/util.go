@@ -315,7 +315,7 @@ func setStatus(logger *log.Logger, planner Planner, eval, nextEval *structs.Eval
 
 // inplaceUpdate attempts to update allocations in-place where possible.
 func inplaceUpdate(ctx Context, eval *structs.Evaluation, job *structs.Job,
-	stack Stack, updates []allocTuple) []allocTuple {
+	stack Stack, quota *quotaTracker, updates []allocTuple) []allocTuple {
 
 	n := len(updates)
 	inplace := 0
@@ -362,6 +362,12 @@ func inplaceUpdate(ctx Context, eval *structs.Evaluation, job *structs.Job,
 			continue
 		}
 
+		// Skip if the updated resources exceed the quota, the allocation
+		// is then replaced and the placement checked against the quota
+		if exhausted := quota.update(update.Alloc, size); len(exhausted) != 0 {
+			continue
+		}
+
 		// Restore the network offers from the existing allocation.
 		// We do not allow network resources (reserved/dynamic ports)
 		// to be updated. This is guarded in taskUpdated, so we can
@@ -424,7 +430,8 @@ type tgConstrainTuple struct {
 	// The set of required drivers within the task group.
 	drivers map[string]struct{}
 
-	// The combined resources of all tasks within the task group.
+	// The combined resources of all tasks within the task group, including
+	// its ephemeral disk.
 	size *structs.Resources
 }
 
@@ -437,6 +444,10 @@ func taskGroupConstraints(tg *structs.TaskGroup) tgConstrainTuple {
 		size:        new(structs.Resources),
 	}
 
+	if tg.EphemeralDisk != nil {
+		c.size.DiskMB += tg.EphemeralDisk.SizeMB
+	}
+
 	c.constraints = append(c.constraints, tg.Constraints...)
 	for _, task := range tg.Tasks {
 		c.drivers[task.Driver] = struct{}{}
@@ -473,7 +473,7 @@ func TestInplaceUpdate_ChangedTaskGroup(t *testing.T) {
 	stack := NewGenericStack(false, ctx)
 
 	// Do the inplace update.
-	unplaced := inplaceUpdate(ctx, eval, job, stack, updates)
+	unplaced := inplaceUpdate(ctx, eval, job, stack, nil, updates)
 
 	if len(unplaced) != 1 {
 		t.Fatal("inplaceUpdate incorrectly did an inplace update")
@@ -518,7 +518,7 @@ func TestInplaceUpdate_NoMatch(t *testing.T) {
 	stack := NewGenericStack(false, ctx)
 
 	// Do the inplace update.
-	unplaced := inplaceUpdate(ctx, eval, job, stack, updates)
+	unplaced := inplaceUpdate(ctx, eval, job, stack, nil, updates)
 
 	if len(unplaced) != 1 {
 		t.Fatal("inplaceUpdate incorrectly did an inplace update")
@@ -589,7 +589,7 @@ func TestInplaceUpdate_Success(t *testing.T) {
 	stack.SetJob(job)
 
 	// Do the inplace update.
-	unplaced := inplaceUpdate(ctx, eval, job, stack, updates)
+	unplaced := inplaceUpdate(ctx, eval, job, stack, nil, updates)
 
 	if len(unplaced) != 0 {
 		t.Fatal("inplaceUpdate did not do an inplace update")
@@ -617,6 +617,64 @@ func TestInplaceUpdate_Success(t *testing.T) {
 	}
 }
 
+func TestInplaceUpdate_QuotaExhausted(t *testing.T) {
+	state, ctx := testContext(t)
+	eval := mock.Eval()
+	job := mock.Job()
+
+	node := mock.Node()
+	noErr(t, state.UpsertNode(1000, node))
+
+	// Limit the namespace of the job
+	quota := mock.QuotaSpec()
+	quota.Limits[0] = &structs.QuotaLimit{Region: "global", CPU: 1000}
+	noErr(t, state.UpsertQuotaSpecs(1001, []*structs.QuotaSpec{quota}))
+	ns := mock.Namespace()
+	ns.Quota = quota.Name
+	noErr(t, state.UpsertNamespaces(1002, []*structs.Namespace{ns}))
+	job.Namespace = ns.Name
+
+	// Register an alloc
+	alloc := &structs.Allocation{
+		ID:        structs.GenerateUUID(),
+		EvalID:    eval.ID,
+		NodeID:    node.ID,
+		Namespace: ns.Name,
+		JobID:     job.ID,
+		Job:       job,
+		TaskGroup: job.TaskGroups[0].Name,
+		Resources: &structs.Resources{
+			CPU:      500,
+			MemoryMB: 256,
+		},
+		DesiredStatus: structs.AllocDesiredStatusRun,
+	}
+	alloc.TaskResources = map[string]*structs.Resources{"web": alloc.Resources}
+	noErr(t, state.UpsertAllocs(1003, []*structs.Allocation{alloc}))
+
+	// Create a new task group that fits the node but not the quota
+	tg := &structs.TaskGroup{}
+	*tg = *job.TaskGroups[0]
+	tg.Tasks[0].Resources = &structs.Resources{CPU: 1500}
+
+	updates := []allocTuple{{Alloc: alloc, TaskGroup: tg}}
+	stack := NewGenericStack(false, ctx)
+	stack.SetJob(job)
+	tracker, err := newQuotaTracker(state, job)
+	noErr(t, err)
+
+	// Do the inplace update.
+	unplaced := inplaceUpdate(ctx, eval, job, stack, tracker, updates)
+
+	if len(unplaced) != 1 {
+		t.Fatal("inplaceUpdate incorrectly did an inplace update")
+	}
+
+	if len(ctx.plan.NodeAllocation) != 0 {
+		t.Fatal("inplaceUpdate incorrectly did an inplace update")
+	}
+}
+
 func TestEvictAndPlace_LimitGreaterThanAllocs(t *testing.T) {
 	_, ctx := testContext(t)
 	allocs := []allocTuple{
@@ -650,6 +708,9 @@ func TestTaskGroupConstraints(t *testing.T) {
 		Name:        "web",
 		Count:       10,
 		Constraints: []*structs.Constraint{constr},
+		EphemeralDisk: &structs.EphemeralDisk{
+			SizeMB: 100,
+		},
 		Tasks: []*structs.Task{
 			&structs.Task{
 				Driver: "exec",
@@ -676,6 +737,7 @@ func TestTaskGroupConstraints(t *testing.T) {
 	expSize := &structs.Resources{
 		CPU:      1000,
 		MemoryMB: 512,
+		DiskMB:   100,
 	}
 
 	actConstrains := taskGroupConstraints(tg)
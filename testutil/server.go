@@ -39,6 +39,7 @@ type TestServerConfig struct {
 	Ports             *PortsConfig  `json:"ports,omitempty"`
 	Server            *ServerConfig `json:"server,omitempty"`
 	Client            *ClientConfig `json:"client,omitempty"`
+	ACL               *ACLConfig    `json:"acl,omitempty"`
 	DevMode           bool          `json:"-"`
 	Stdout, Stderr    io.Writer     `json:"-"`
 }
@@ -52,12 +53,19 @@ type PortsConfig struct {
 
 // ServerConfig is used to configure the nomad server.
 type ServerConfig struct {
-	Enabled         bool `json:"enabled"`
-	BootstrapExpect int  `json:"bootstrap_expect"`
+	Enabled         bool   `json:"enabled"`
+	BootstrapExpect int    `json:"bootstrap_expect"`
+	EncryptKey      string `json:"encrypt,omitempty"`
 }
 
 // ClientConfig is used to configure the client
 type ClientConfig struct {
+	Enabled bool              `json:"enabled"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ACLConfig is used to configure ACLs
+type ACLConfig struct {
 	Enabled bool `json:"enabled"`
 }
 
@@ -204,6 +212,12 @@ func (s *TestServer) waitForAPI() {
 			return false, err
 		}
 		defer resp.Body.Close()
+
+		// Anonymous requests are denied once the agent is up if ACLs are
+		// enabled
+		if s.aclDenied(resp) {
+			return true, nil
+		}
 		if err := s.requireOK(resp); err != nil {
 			return false, err
 		}
@@ -225,6 +239,12 @@ func (s *TestServer) waitForLeader() {
 			return false, err
 		}
 		defer resp.Body.Close()
+
+		// Anonymous requests are only checked by the leader if ACLs are
+		// enabled
+		if s.aclDenied(resp) {
+			return true, nil
+		}
 		if err := s.requireOK(resp); err != nil {
 			return false, err
 		}
@@ -240,6 +260,11 @@ func (s *TestServer) waitForLeader() {
 	})
 }
 
+// aclDenied returns whether the request was denied by the ACL system.
+func (s *TestServer) aclDenied(resp *http.Response) bool {
+	return s.Config.ACL != nil && s.Config.ACL.Enabled && resp.StatusCode == 403
+}
+
 // url is a helper function which takes a relative URL and
 // makes it into a proper URL against the local Nomad server.
 func (s *TestServer) url(path string) string {
@@ -4,6 +4,19 @@ module CommandHelpers
     <<EOF
 * `-address=<addr>`: The address of the Nomad server. Overrides the `NOMAD_ADDR`
   environment variable if set. Defaults to `http://127.0.0.1:4646`.
+* `-token=<secret-id>`: The secret ID of the [ACL token](/docs/http/acl.html)
+  used for the request. Overrides the `NOMAD_TOKEN` environment variable if set.
+* `-namespace=<namespace>`: The [namespace](/docs/http/namespaces.html) of the
+  jobs, evaluations and allocations the command operates on. Overrides the
+  `NOMAD_NAMESPACE` environment variable if set. Defaults to `default`.
+* `-ca-cert=<path>`: Path to a PEM encoded CA certificate file used to verify
+  the certificate of the Nomad agent serving HTTPS. Overrides the
+  `NOMAD_CA_CERT` environment variable if set.
+* `-client-cert=<path>`: Path to a PEM encoded client certificate presented to
+  Nomad agents requiring client certificates. Must be set with `-client-key`.
+  Overrides the `NOMAD_CLIENT_CERT` environment variable if set.
+* `-client-key=<path>`: Path to the PEM encoded private key of the client
+  certificate. Overrides the `NOMAD_CLIENT_KEY` environment variable if set.
 EOF
   end
 end